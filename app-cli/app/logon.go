@@ -51,6 +51,18 @@ var LogonGrammar = []cli.Option{
 		Description: "logon.expiration",
 		EnvVar:      "EGO_LOGON_EXPIRATION",
 	},
+	{
+		LongName:    "refresh",
+		ShortName:   "r",
+		OptionType:  cli.BooleanType,
+		Description: "logon.refresh",
+	},
+	{
+		LongName:    "code",
+		ShortName:   "c",
+		OptionType:  cli.StringType,
+		Description: "logon.code",
+	},
 }
 
 // Logon handles the logon subcommand. This accepts a username and
@@ -61,7 +73,11 @@ var LogonGrammar = []cli.Option{
 //
 // If the user credentials are valid and a token is returned, it is
 // stored in the user's active profile where it can be accessed by
-// other Ego commands as needed.
+// other Ego commands as needed. If the server also returns a refresh
+// token, it is stored so the --refresh option can later be used to
+// get a new token without the username and password. If the user is enrolled
+// for one-time codes, the code is given with the --code option or the user is
+// prompted for it when the server reports that it is required.
 func Logon(c *cli.Context) error {
 	var (
 		err error
@@ -86,15 +102,23 @@ func Logon(c *cli.Context) error {
 		return err
 	}
 
+	// If a refresh token is to be used, it replaces the username and password.
+	refresh := ""
+	if c.Boolean("refresh") {
+		if refresh = settings.Get(defs.LogonRefreshSetting); refresh == "" {
+			return errors.ErrInvalidRefreshToken
+		}
+	}
+
 	// Get the username. If not supplied by the user, prompt until provided.
 	user, _ := c.String("username")
-	for user == "" {
+	for user == "" && refresh == "" {
 		user = ui.Prompt("Username: ")
 	}
 
 	// Get the password. If not supplied by the user, prompt until provided.
 	pass, _ := c.String("password")
-	for pass == "" {
+	for pass == "" && refresh == "" {
 		pass = ui.PromptPassword(i18n.L("password.prompt"))
 	}
 
@@ -104,7 +128,11 @@ func Logon(c *cli.Context) error {
 		"url": url})
 
 	// Turn logon server address and endpoint into full URL.
-	url = strings.TrimSuffix(url, "/") + defs.ServicesLogonPath
+	if refresh != "" {
+		url = strings.TrimSuffix(url, "/") + defs.ServicesRefreshPath
+	} else {
+		url = strings.TrimSuffix(url, "/") + defs.ServicesLogonPath
+	}
 
 	// Create a new client, set it's attribute for basic authentication, and
 	// generate a request. The request is made using the logon agent info.
@@ -119,55 +147,26 @@ func Logon(c *cli.Context) error {
 		restClient.SetTLSClientConfig(tlsConf)
 	}
 
-	retryCount := 5
-	for retryCount >= 0 {
-		retryCount--
+	code, _ := c.String("code")
 
-		req := restClient.NewRequest()
-		req.Body = defs.Credentials{Username: user, Password: pass, Expiration: expiration}
-
-		if ui.IsActive(ui.RestLogger) {
-			// Use a fake password payload for the REST logging so we don't expose the password
-			b, _ := json.MarshalIndent(defs.Credentials{
-				Username:   user,
-				Password:   "********",
-				Expiration: expiration}, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
-
-			ui.Log(ui.RestLogger, "logon.request", ui.A{
-				"body": string(b)})
-		}
-
-		req.Header.Set("Accept", defs.JSONMediaType)
-		rest.AddAgent(req, defs.LogonAgent)
-
-		r, err = req.Post(url)
-		if err != nil {
-			// This is a gross hack, but I don't yet know how to determine the
-			// specific error value.
-			if !strings.Contains(err.Error(), "auto redirect is disabled") {
-				ui.Log(ui.RestLogger, "logon.post.error", ui.A{
-					"url":   url,
-					"error": err})
+	for {
+		r, url, err = postLogon(restClient, url, defs.Credentials{
+			Username:   user,
+			Password:   pass,
+			Expiration: expiration,
+			Refresh:    refresh,
+			Code:       code,
+		})
 
-				return errors.New(err)
-			}
+		// If the password was valid but the user must also provide a one-time code,
+		// prompt for it and try again.
+		if err != nil || r.StatusCode() != http.StatusUnauthorized || code != "" || r.Header().Get(defs.OneTimeCodeHeader) == "" {
+			break
 		}
 
-		if r.StatusCode() == http.StatusMovedPermanently {
-			url = r.Header().Get("Location")
-			if url != "" {
-				ui.Log(ui.RestLogger, "logon.redirecting", ui.A{
-					"url": url})
-
-				continue
-			}
+		for code == "" {
+			code = ui.Prompt(i18n.L("code.prompt"))
 		}
-
-		ui.Log(ui.RestLogger, "logon.post.status", ui.A{
-			"url":    url,
-			"status": r.StatusCode()})
-
-		break
 	}
 
 	// If the call was successful and the server responded with Success, remove any trailing
@@ -193,6 +192,9 @@ func Logon(c *cli.Context) error {
 		switch r.StatusCode() {
 		case http.StatusUnauthorized:
 			err = errors.ErrInvalidCredentials
+			if refresh != "" {
+				err = errors.ErrInvalidRefreshToken
+			}
 
 		case http.StatusForbidden:
 			err = errors.ErrNoPermission
@@ -212,6 +214,72 @@ func Logon(c *cli.Context) error {
 	return err
 }
 
+// postLogon sends the logon request to the server, following any redirects to a
+// different authority server. The response and the final URL are returned.
+func postLogon(restClient *resty.Client, url string, credentials defs.Credentials) (*resty.Response, string, error) {
+	var (
+		err error
+		r   *resty.Response
+	)
+
+	retryCount := 5
+	for retryCount >= 0 {
+		retryCount--
+
+		req := restClient.NewRequest()
+		req.Body = credentials
+
+		if ui.IsActive(ui.RestLogger) {
+			// Use a fake password payload for the REST logging so we don't expose the password
+			// or the refresh token.
+			masked := defs.Credentials{Username: credentials.Username, Password: "********", Expiration: credentials.Expiration}
+			if credentials.Refresh != "" {
+				masked.Password = ""
+				masked.Refresh = "********"
+			}
+
+			b, _ := json.MarshalIndent(masked, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+
+			ui.Log(ui.RestLogger, "logon.request", ui.A{
+				"body": string(b)})
+		}
+
+		req.Header.Set("Accept", defs.JSONMediaType)
+		rest.AddAgent(req, defs.LogonAgent)
+
+		r, err = req.Post(url)
+		if err != nil {
+			// This is a gross hack, but I don't yet know how to determine the
+			// specific error value.
+			if !strings.Contains(err.Error(), "auto redirect is disabled") {
+				ui.Log(ui.RestLogger, "logon.post.error", ui.A{
+					"url":   url,
+					"error": err})
+
+				return r, url, errors.New(err)
+			}
+		}
+
+		if r.StatusCode() == http.StatusMovedPermanently {
+			url = r.Header().Get("Location")
+			if url != "" {
+				ui.Log(ui.RestLogger, "logon.redirecting", ui.A{
+					"url": url})
+
+				continue
+			}
+		}
+
+		ui.Log(ui.RestLogger, "logon.post.status", ui.A{
+			"url":    url,
+			"status": r.StatusCode()})
+
+		break
+	}
+
+	return r, url, err
+}
+
 func storeLogonToken(r *resty.Response, user string) error {
 	payload := defs.LogonResponse{}
 
@@ -228,8 +296,13 @@ func storeLogonToken(r *resty.Response, user string) error {
 			"body": string(b)})
 	}
 
+	if user == "" {
+		user = payload.Identity
+	}
+
 	settings.Set(defs.LogonTokenSetting, payload.Token)
 	settings.Set(defs.LogonTokenExpirationSetting, payload.Expiration)
+	settings.Set(defs.LogonRefreshSetting, payload.Refresh)
 
 	err := settings.Save()
 	if err == nil {
@@ -48,6 +48,7 @@ var sequenceMux sync.Mutex
 const (
 	AppLogger = iota
 	AssetLogger
+	AuditLogger
 	AuthLogger
 	ByteCodeLogger
 	CacheLogger
@@ -84,6 +85,7 @@ type logger struct {
 var loggers []logger = []logger{
 	{"APP", false},
 	{"ASSET", false},
+	{"AUDIT", false},
 	{"AUTH", false},
 	{"BYTECODE", false},
 	{"CACHE", false},
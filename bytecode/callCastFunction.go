@@ -30,6 +30,17 @@ func callTypeCast(function *data.Type, args []interface{}, c *Context) error {
 				return c.error(err)
 			}
 
+		case defs.TimeWeekdayTypeName:
+			if day, err := data.Int(args[0]); err == nil {
+				if day < 0 || day > 6 {
+					return c.error(errors.ErrInvalidValue).Context(day)
+				}
+
+				return c.push(time.Weekday(day))
+			} else {
+				return c.error(err)
+			}
+
 		default:
 			return c.error(errors.ErrInvalidFunctionTypeCall).Context(function.TypeString())
 		}
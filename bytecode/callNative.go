@@ -2,16 +2,14 @@ package bytecode
 
 import (
 	"fmt"
-	"path/filepath"
 	"reflect"
-	"strings"
 	"time"
 
-	"github.com/tucats/ego/app-cli/settings"
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
 	"github.com/tucats/ego/i18n"
+	"github.com/tucats/ego/util"
 )
 
 // Make a call to a native (Go) function. The function value is found in the function
@@ -87,7 +85,9 @@ func convertToNative(function *data.Function, functionArguments []interface{}) (
 			// If this argument has a formal parameter definition and it is a sandboxed filename,
 			// then apply the sandbox prefix if enabled.
 			if argumentIndex < len(function.Declaration.Parameters) && function.Declaration.Parameters[argumentIndex].Sandboxed {
-				str = sandboxName(str)
+				if str, err = util.SandboxPath(str); err != nil {
+					return nil, err
+				}
 			}
 
 			nativeArgs[argumentIndex] = str
@@ -165,6 +165,9 @@ func makeNativePackageTypeArgument(t *data.Type, functionArgument interface{}, a
 
 			case defs.TimeMonthTypeName:
 				functionArgument = time.Month(actual)
+
+			case defs.TimeWeekdayTypeName:
+				functionArgument = time.Weekday(actual)
 			}
 
 		default:
@@ -490,16 +493,3 @@ func CallDirect(fn interface{}, args ...interface{}) (interface{}, error) {
 
 	return list, nil
 }
-
-// Utility function used to sandbox names used as paraemters to native functions.
-func sandboxName(path string) string {
-	if sandboxPrefix := settings.Get(defs.SandboxPathSetting); sandboxPrefix != "" {
-		if strings.HasPrefix(path, sandboxPrefix) {
-			return path
-		}
-
-		return filepath.Join(sandboxPrefix, path)
-	}
-
-	return path
-}
@@ -0,0 +1,58 @@
+package bytecode
+
+import (
+	"context"
+
+	"github.com/tucats/ego/errors"
+)
+
+// SetCancelContext binds the execution of the bytecode context to a native Go
+// context. When the Go context is cancelled or its deadline passes, the bytecode
+// stops running and returns the context error. A nil context removes the binding.
+func (c *Context) SetCancelContext(ctx context.Context) *Context {
+	c.cancelContext = ctx
+	c.cancelled = nil
+
+	if ctx != nil {
+		c.cancelled = ctx.Done()
+	}
+
+	return c
+}
+
+// CancelContext returns the native Go context the bytecode context is bound to.
+// If there is no binding, this is the background context, which is never cancelled.
+func (c *Context) CancelContext() context.Context {
+	if c.cancelContext == nil {
+		return context.Background()
+	}
+
+	return c.cancelContext
+}
+
+// ContextError converts the error state of a native Go context to the equivalent
+// Ego error. The result is nil if the context is not done.
+func ContextError(ctx context.Context) error {
+	switch ctx.Err() {
+	case nil:
+		return nil
+
+	case context.DeadlineExceeded:
+		return errors.ErrContextDeadline
+
+	default:
+		return errors.ErrContextCanceled
+	}
+}
+
+// isCancelled returns the Ego error for the bound context if it has been cancelled.
+// This is checked before each instruction, so it does not block.
+func (c *Context) isCancelled() error {
+	select {
+	case <-c.cancelled:
+		return ContextError(c.cancelContext)
+
+	default:
+		return nil
+	}
+}
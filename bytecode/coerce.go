@@ -121,9 +121,15 @@ func coerceStruct(value interface{}, t *data.Type) (interface{}, error) {
 	}
 
 	// Verify that all the fields in the type are found in the object; if not,
-	// create a zero-value for that type.
+	// create a zero-value for that type. The private fields of a type from a
+	// builtin package are included, so the native state they hold is kept.
+	fields := map[string]bool{}
+	for _, fieldName := range structValue.FieldNames(true) {
+		fields[fieldName] = true
+	}
+
 	for _, fieldName := range t.FieldNames() {
-		if _, found := structValue.Get(fieldName); !found {
+		if !fields[fieldName] {
 			fieldType, _ := t.Field(fieldName)
 			structValue.SetAlways(fieldName, data.InstanceOfType(fieldType))
 		}
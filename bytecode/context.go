@@ -1,6 +1,7 @@
 package bytecode
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -90,6 +91,8 @@ type Context struct {
 	argCountDelta        int
 	deferThisSize        int
 	deferSymbols         *symbols.SymbolTable
+	cancelContext        context.Context
+	cancelled            <-chan struct{}
 	threadID             int32
 	fullSymbolScope      bool
 	running              bool
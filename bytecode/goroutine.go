@@ -95,7 +95,9 @@ func GoRoutine(fx interface{}, parentCtx *Context, args data.List) {
 	functionSymbols := symbols.NewChildSymbolTable("Go routine ", parentSymbols.SharedParent()).Boundary(false)
 
 	// Run the bytecode in a new context. This will be a child of the parent context.
-	ctx := NewContext(functionSymbols, callCode)
+	// The go routine is stopped when the parent context is cancelled, such as when
+	// the client of a service request disconnects.
+	ctx := NewContext(functionSymbols, callCode).SetCancelContext(parentCtx.cancelContext)
 
 	if ui.IsActive(ui.GoRoutineLogger) {
 		ui.Log(ui.GoRoutineLogger, "go.native",
@@ -129,8 +131,9 @@ func GoRoutine(fx interface{}, parentCtx *Context, args data.List) {
 	// program.
 	goRoutineCompletion.Done()
 
-	// If we had an error in the go routine, stop the invoking context execution.
-	if err != nil && !err.Is(errors.ErrStop) {
+	// If we had an error in the go routine, stop the invoking context execution. A
+	// go routine that stopped because its context was cancelled is not an error.
+	if err != nil && !err.Is(errors.ErrStop) && !err.Is(errors.ErrContextCanceled) && !err.Is(errors.ErrContextDeadline) {
 		ui.Log(ui.GoRoutineLogger, "go.exit.error",
 			"thread", ctx.threadID,
 			"name", fName,
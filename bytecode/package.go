@@ -104,8 +104,11 @@ func importByteCode(c *Context, i interface{}) error {
 		if symV, found := pkg.Get(data.SymbolsMDKey); found {
 			sym := symV.(*symbols.SymbolTable)
 			sym.SetPackage(name)
-			// Must make a clone of the symbol table since packages are shared
-			c.symbols = sym.Clone(c.symbols)
+			// Must make a clone of the symbol table since packages are shared.
+			// If the table it is added to is shared with go routines, so is the
+			// clone, since symbols declared after the import are stored in it.
+			shared := c.symbols.IsShared()
+			c.symbols = sym.Clone(c.symbols).Shared(shared)
 		}
 	}
 
@@ -45,6 +45,18 @@ func (c *Context) RunFromAddress(addr int) error {
 
 	// Loop over the bytecodes and run.
 	for c.running && c.programCounter < len(c.bc.instructions) {
+		// If the context is bound to a native context that has been cancelled,
+		// stop running.
+		if c.cancelled != nil {
+			if err = c.isCancelled(); err != nil {
+				ui.Log(ui.TraceLogger, "trace.return",
+					"thread", c.threadID,
+					"error", err)
+
+				return errors.New(err)
+			}
+		}
+
 		i := c.bc.instructions[c.programCounter]
 		if c.Tracing() {
 			traceInstruction(c, i)
@@ -27,14 +27,16 @@ const (
 	AuthCache
 	UserCache
 	TokenCache
+	PermissionCache
 )
 
 // Map the cache classes to a string representation for easier logging.
 var cacheClass = map[int]string{
-	DSNCache:   "DSN   ",
-	AuthCache:  "Auth  ",
-	UserCache:  "User  ",
-	TokenCache: "Token ",
+	DSNCache:        "DSN   ",
+	AuthCache:       "Auth  ",
+	UserCache:       "User  ",
+	TokenCache:      "Token ",
+	PermissionCache: "Perm  ",
 }
 
 // active is a flag indicating if caching is active or not.
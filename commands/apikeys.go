@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/cli"
+	"github.com/tucats/ego/app-cli/tables"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/i18n"
+	"github.com/tucats/ego/runtime/rest"
+)
+
+// ListAPIKeys lists the API keys on the running server. If the --user option is
+// given, only the keys for that user are listed.
+func ListAPIKeys(c *cli.Context) error {
+	keys := defs.APIKeyCollection{}
+	url := rest.URLBuilder(defs.AdminAPIKeysPath)
+
+	if user, found := c.String("user"); found {
+		url.Parameter("user", user)
+	}
+
+	err := rest.Exchange(url.String(), http.MethodGet, nil, &keys, defs.AdminAgent, defs.APIKeysMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	return displayAPIKeys(keys)
+}
+
+// CreateAPIKey creates a new API key on the running server. The text of the key is
+// displayed once; it cannot be retrieved from the server again.
+func CreateAPIKey(c *cli.Context) error {
+	key := defs.APIKey{}
+	response := defs.APIKeyResponse{}
+
+	key.User, _ = c.String("user")
+	key.Description, _ = c.String("description")
+	key.Expires, _ = c.String("expires")
+	key.Permissions, _ = c.StringList("permissions")
+	key.Allow, _ = c.StringList("allow")
+
+	err := rest.Exchange(defs.AdminAPIKeysPath, http.MethodPost, key, &response, defs.AdminAgent, defs.APIKeyMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if ui.OutputFormat != ui.TextFormat {
+		return commandOutput(response)
+	}
+
+	ui.Say("msg.apikey.created", map[string]interface{}{
+		"id":  response.Item.ID,
+		"key": response.Key,
+	})
+
+	return nil
+}
+
+// RevokeAPIKey revokes the API key with the ID given as a parameter, so it can no
+// longer be used.
+func RevokeAPIKey(c *cli.Context) error {
+	keys := defs.APIKeyCollection{}
+	url := rest.URLBuilder(defs.AdminAPIKeysIDPath, c.Parameter(0)).String()
+
+	err := rest.Exchange(url, http.MethodDelete, nil, &keys, defs.AdminAgent, defs.APIKeysMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if ui.OutputFormat == ui.TextFormat {
+		ui.Say("msg.apikey.revoked", map[string]interface{}{"count": keys.Count})
+	}
+
+	return displayAPIKeys(keys)
+}
+
+// displayAPIKeys displays a list of API keys.
+func displayAPIKeys(keys defs.APIKeyCollection) error {
+	if ui.OutputFormat != ui.TextFormat {
+		return commandOutput(keys)
+	}
+
+	if len(keys.Items) == 0 {
+		return nil
+	}
+
+	t, err := tables.New([]string{i18n.L("ID"), i18n.L("User"), i18n.L("Permissions"), i18n.L("Expires"), i18n.L("Allow"), i18n.L("Description")})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys.Items {
+		err = t.AddRowItems(key.ID, key.User, strings.Join(key.Permissions, ", "), key.Expires, strings.Join(key.Allow, ", "), key.Description)
+		if err != nil {
+			return err
+		}
+	}
+
+	t.SetPagination(0, 0)
+
+	return t.Print(ui.TextFormat)
+}
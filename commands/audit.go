@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/cli"
+	"github.com/tucats/ego/app-cli/tables"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/i18n"
+	"github.com/tucats/ego/runtime/rest"
+)
+
+// ShowAudit displays the entries in the audit log of the running server. The
+// entries can be filtered by event, user, session number and time, and the
+// number of entries displayed can be limited.
+func ShowAudit(c *cli.Context) error {
+	entries := defs.AuditCollection{}
+	url := rest.URLBuilder(defs.AdminAuditPath)
+
+	for _, name := range []string{"event", "user", "since", "until"} {
+		if value, found := c.String(name); found {
+			url.Parameter(name, value)
+		}
+	}
+
+	for _, name := range []string{"session", "limit"} {
+		if value, found := c.Integer(name); found {
+			url.Parameter(name, value)
+		}
+	}
+
+	err := rest.Exchange(url.String(), http.MethodGet, nil, &entries, defs.AdminAgent, defs.AuditMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if ui.OutputFormat != ui.TextFormat {
+		return commandOutput(entries)
+	}
+
+	if !entries.Verified {
+		ui.Say("msg.audit.broken", map[string]interface{}{"seq": entries.Broken})
+	}
+
+	if len(entries.Items) == 0 {
+		return nil
+	}
+
+	t, err := tables.New([]string{i18n.L("Seq"), i18n.L("Time"), i18n.L("Event"), i18n.L("Session"), i18n.L("User"), i18n.L("Details")})
+	if err != nil {
+		return err
+	}
+
+	_ = t.SetAlignment(0, tables.AlignmentRight)
+	_ = t.SetAlignment(3, tables.AlignmentRight)
+
+	for _, entry := range entries.Items {
+		session := ""
+		if entry.Session > 0 {
+			session = strconv.Itoa(entry.Session)
+		}
+
+		if err = t.AddRowItems(entry.Sequence, entry.Time, entry.Event, session, entry.User, auditDetails(entry.Args)); err != nil {
+			return err
+		}
+	}
+
+	t.SetPagination(0, 0)
+
+	return t.Print(ui.TextFormat)
+}
+
+// auditDetails formats the arguments of an audit log entry as a list of key=value
+// pairs, sorted by key.
+func auditDetails(args map[string]interface{}) string {
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		value := args[key]
+		if list, ok := value.([]interface{}); ok {
+			items := make([]string, len(list))
+			for j, item := range list {
+				items[j] = fmt.Sprintf("%v", item)
+			}
+
+			value = "[" + strings.Join(items, ", ") + "]"
+		}
+
+		parts[i] = fmt.Sprintf("%s=%v", key, value)
+	}
+
+	return strings.Join(parts, " ")
+}
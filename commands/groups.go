@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"net/http"
+
+	"github.com/tucats/ego/app-cli/cli"
+	"github.com/tucats/ego/app-cli/tables"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/i18n"
+	"github.com/tucats/ego/runtime/rest"
+)
+
+// AddGroup is used to add a new group to the security database of the
+// running server.
+func AddGroup(c *cli.Context) error {
+	payload := defs.Group{Name: itemName(c, "group.prompt")}
+	payload.Description, _ = c.String("description")
+	payload.Members, _ = c.StringList("members")
+	payload.Roles, _ = c.StringList("roles")
+
+	resp := defs.GroupResponse{}
+
+	err := rest.Exchange(defs.AdminGroupsPath, http.MethodPost, payload, &resp, defs.AdminAgent, defs.GroupMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	displayGroup(&resp.Group, "created")
+
+	return nil
+}
+
+// UpdateGroup is used to modify an existing group on the server. Members and
+// roles are added to the group, or removed if the name starts with "-".
+func UpdateGroup(c *cli.Context) error {
+	payload := defs.Group{Name: itemName(c, "group.prompt")}
+	payload.Description, _ = c.String("description")
+	payload.Members, _ = c.StringList("members")
+	payload.Roles, _ = c.StringList("roles")
+
+	resp := defs.GroupResponse{}
+	url := rest.URLBuilder(defs.AdminGroupsNamePath, payload.Name)
+
+	err := rest.Exchange(url.String(), http.MethodPatch, payload, &resp, defs.AdminAgent, defs.GroupMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	displayGroup(&resp.Group, "updated")
+
+	return nil
+}
+
+// ShowGroup is used to fetch and display the information for a single group.
+func ShowGroup(c *cli.Context) error {
+	resp := defs.GroupResponse{}
+	url := rest.URLBuilder(defs.AdminGroupsNamePath, itemName(c, "group.prompt"))
+
+	err := rest.Exchange(url.String(), http.MethodGet, nil, &resp, defs.AdminAgent, defs.GroupMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	displayGroup(&resp.Group, "")
+
+	return nil
+}
+
+// DeleteGroup is used to delete a group from the security database of the
+// running server.
+func DeleteGroup(c *cli.Context) error {
+	name := itemName(c, "group.prompt")
+	resp := defs.GroupResponse{}
+	url := rest.URLBuilder(defs.AdminGroupsNamePath, name)
+
+	err := rest.Exchange(url.String(), http.MethodDelete, nil, &resp, defs.AdminAgent, defs.GroupMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if ui.OutputFormat == ui.TextFormat {
+		ui.Say("msg.group.deleted", map[string]interface{}{"name": name})
+	} else {
+		_ = commandOutput(resp)
+	}
+
+	return nil
+}
+
+// ListGroups lists all the groups in the security database of the running server.
+func ListGroups(c *cli.Context) error {
+	groups := defs.GroupCollection{}
+
+	err := rest.Exchange(defs.AdminGroupsPath, http.MethodGet, nil, &groups, defs.AdminAgent, defs.GroupsMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if ui.OutputFormat != ui.TextFormat {
+		return commandOutput(groups)
+	}
+
+	t, err := tables.New([]string{i18n.L("Group"), i18n.L("Members"), i18n.L("Roles"), i18n.L("Description")})
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups.Items {
+		if err = t.AddRowItems(group.Name, joinNames(group.Members), joinNames(group.Roles), group.Description); err != nil {
+			return err
+		}
+	}
+
+	t.SetPagination(0, 0)
+
+	return t.Print(ui.TextFormat)
+}
+
+// Display a single group (with an action word) to show the result
+// of an update, create, etc. of a group.
+func displayGroup(group *defs.Group, action string) {
+	if ui.OutputFormat != ui.TextFormat {
+		_ = commandOutput(group)
+
+		return
+	}
+
+	if action != "" {
+		ui.Say("msg.group.show", map[string]interface{}{
+			"action": action,
+			"name":   group.Name,
+		})
+	}
+
+	t, _ := tables.New([]string{i18n.L("Field"), i18n.L("Value")})
+
+	_ = t.AddRowItems("Name", group.Name)
+	_ = t.AddRowItems("ID", group.ID)
+	_ = t.AddRowItems("Description", group.Description)
+	_ = t.AddRowItems("Members", joinNames(group.Members))
+	_ = t.AddRowItems("Roles", joinNames(group.Roles))
+
+	t.Print(ui.TextFormat)
+}
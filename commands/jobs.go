@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"net/http"
+
+	"github.com/tucats/ego/app-cli/cli"
+	"github.com/tucats/ego/app-cli/tables"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/i18n"
+	"github.com/tucats/ego/runtime/rest"
+)
+
+// ListJobs lists the scheduled jobs on the running server, with the status of the
+// most recent run of each job.
+func ListJobs(c *cli.Context) error {
+	jobs := defs.JobCollection{}
+
+	err := rest.Exchange(defs.AdminJobsPath, http.MethodGet, nil, &jobs, defs.AdminAgent, defs.JobsMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	return displayJobs(jobs)
+}
+
+// JobHistory displays the most recent runs of a job on the running server.
+func JobHistory(c *cli.Context) error {
+	jobs := defs.JobCollection{}
+	url := rest.URLBuilder(defs.AdminJobsNamePath, itemName(c, "job.prompt")).String()
+
+	err := rest.Exchange(url, http.MethodGet, nil, &jobs, defs.AdminAgent, defs.JobsMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if ui.OutputFormat != ui.TextFormat {
+		return commandOutput(jobs)
+	}
+
+	t, err := tables.New([]string{i18n.L("Start"), i18n.L("Duration"), i18n.L("Status"), i18n.L("Trigger"), i18n.L("Error")})
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs.Items {
+		for _, run := range job.History {
+			if err = t.AddRowItems(run.Start, run.Duration, run.Status, run.Trigger, run.Error); err != nil {
+				return err
+			}
+		}
+	}
+
+	t.SetPagination(0, 0)
+
+	return t.Print(ui.TextFormat)
+}
+
+// RunJob starts a job on the running server now. If the --wait option is given, the
+// command waits for the job to end, and reports if it failed.
+func RunJob(c *cli.Context) error {
+	jobs := defs.JobCollection{}
+	name := itemName(c, "job.prompt")
+	url := rest.URLBuilder(defs.AdminJobsNamePath, name)
+
+	wait := c.Boolean("wait")
+	if wait {
+		url.Parameter("wait")
+	}
+
+	err := rest.Exchange(url.String(), http.MethodPost, nil, &jobs, defs.AdminAgent, defs.JobsMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if ui.OutputFormat == ui.TextFormat {
+		ui.Say("msg.job.started", map[string]interface{}{"name": name})
+	}
+
+	if err = displayJobs(jobs); err != nil {
+		return err
+	}
+
+	if wait && len(jobs.Items) > 0 {
+		if last := jobs.Items[0].Last; last != nil && last.Status == defs.JobFailed {
+			return errors.ErrJobFailed.Context(last.Error)
+		}
+	}
+
+	return nil
+}
+
+// PauseJob stops a job on the running server from being run on its schedule.
+func PauseJob(c *cli.Context) error {
+	return setJobPaused(c, true)
+}
+
+// ResumeJob resumes running a paused job on the running server on its schedule.
+func ResumeJob(c *cli.Context) error {
+	return setJobPaused(c, false)
+}
+
+// setJobPaused sets whether a job on the running server is paused.
+func setJobPaused(c *cli.Context, paused bool) error {
+	jobs := defs.JobCollection{}
+	job := defs.Job{Name: itemName(c, "job.prompt"), Paused: paused}
+	url := rest.URLBuilder(defs.AdminJobsNamePath, job.Name).String()
+
+	err := rest.Exchange(url, http.MethodPatch, job, &jobs, defs.AdminAgent, defs.JobsMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	return displayJobs(jobs)
+}
+
+// displayJobs displays a list of jobs, with the status of the most recent run of
+// each job.
+func displayJobs(jobs defs.JobCollection) error {
+	if ui.OutputFormat != ui.TextFormat {
+		return commandOutput(jobs)
+	}
+
+	if len(jobs.Items) == 0 {
+		return nil
+	}
+
+	t, err := tables.New([]string{i18n.L("Name"), i18n.L("Schedule"), i18n.L("State"), i18n.L("Next"), i18n.L("Last"), i18n.L("Status")})
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs.Items {
+		state := i18n.L("job.idle")
+		if job.Running {
+			state = i18n.L("job.running")
+		} else if job.Paused {
+			state = i18n.L("job.paused")
+		}
+
+		last, status := "", ""
+		if job.Last != nil {
+			last, status = job.Last.Start, job.Last.Status
+		}
+
+		if err = t.AddRowItems(job.Name, job.Schedule, state, job.Next, last, status); err != nil {
+			return err
+		}
+	}
+
+	t.SetPagination(0, 0)
+
+	return t.Print(ui.TextFormat)
+}
@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/cli"
+	"github.com/tucats/ego/app-cli/tables"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/i18n"
+	"github.com/tucats/ego/runtime/rest"
+)
+
+// AddRole is used to add a new role to the security database of the
+// running server.
+func AddRole(c *cli.Context) error {
+	payload := defs.Role{Name: itemName(c, "role.prompt")}
+	payload.Description, _ = c.String("description")
+	payload.Permissions, _ = c.StringList("permissions")
+	payload.Inherits, _ = c.StringList("inherits")
+
+	resp := defs.RoleResponse{}
+
+	err := rest.Exchange(defs.AdminRolesPath, http.MethodPost, payload, &resp, defs.AdminAgent, defs.RoleMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	displayRole(&resp.Role, "created")
+
+	return nil
+}
+
+// UpdateRole is used to modify an existing role on the server. Permissions and
+// inherited roles are added to the role, or removed if the name starts with "-".
+func UpdateRole(c *cli.Context) error {
+	payload := defs.Role{Name: itemName(c, "role.prompt")}
+	payload.Description, _ = c.String("description")
+	payload.Permissions, _ = c.StringList("permissions")
+	payload.Inherits, _ = c.StringList("inherits")
+
+	resp := defs.RoleResponse{}
+	url := rest.URLBuilder(defs.AdminRolesNamePath, payload.Name)
+
+	err := rest.Exchange(url.String(), http.MethodPatch, payload, &resp, defs.AdminAgent, defs.RoleMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	displayRole(&resp.Role, "updated")
+
+	return nil
+}
+
+// ShowRole is used to fetch and display the information for a single role.
+func ShowRole(c *cli.Context) error {
+	resp := defs.RoleResponse{}
+	url := rest.URLBuilder(defs.AdminRolesNamePath, itemName(c, "role.prompt"))
+
+	err := rest.Exchange(url.String(), http.MethodGet, nil, &resp, defs.AdminAgent, defs.RoleMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	displayRole(&resp.Role, "")
+
+	return nil
+}
+
+// DeleteRole is used to delete a role from the security database of the
+// running server.
+func DeleteRole(c *cli.Context) error {
+	name := itemName(c, "role.prompt")
+	resp := defs.RoleResponse{}
+	url := rest.URLBuilder(defs.AdminRolesNamePath, name)
+
+	err := rest.Exchange(url.String(), http.MethodDelete, nil, &resp, defs.AdminAgent, defs.RoleMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if ui.OutputFormat == ui.TextFormat {
+		ui.Say("msg.role.deleted", map[string]interface{}{"name": name})
+	} else {
+		_ = commandOutput(resp)
+	}
+
+	return nil
+}
+
+// ListRoles lists all the roles in the security database of the running server.
+func ListRoles(c *cli.Context) error {
+	roles := defs.RoleCollection{}
+
+	err := rest.Exchange(defs.AdminRolesPath, http.MethodGet, nil, &roles, defs.AdminAgent, defs.RolesMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if ui.OutputFormat != ui.TextFormat {
+		return commandOutput(roles)
+	}
+
+	t, err := tables.New([]string{i18n.L("Role"), i18n.L("Permissions"), i18n.L("Inherits"), i18n.L("Description")})
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles.Items {
+		if err = t.AddRowItems(role.Name, joinNames(role.Permissions), joinNames(role.Inherits), role.Description); err != nil {
+			return err
+		}
+	}
+
+	t.SetPagination(0, 0)
+
+	return t.Print(ui.TextFormat)
+}
+
+// Display a single role (with an action word) to show the result
+// of an update, create, etc. of a role.
+func displayRole(role *defs.Role, action string) {
+	if ui.OutputFormat != ui.TextFormat {
+		_ = commandOutput(role)
+
+		return
+	}
+
+	if action != "" {
+		ui.Say("msg.role.show", map[string]interface{}{
+			"action": action,
+			"name":   role.Name,
+		})
+	}
+
+	t, _ := tables.New([]string{i18n.L("Field"), i18n.L("Value")})
+
+	_ = t.AddRowItems("Name", role.Name)
+	_ = t.AddRowItems("ID", role.ID)
+	_ = t.AddRowItems("Description", role.Description)
+	_ = t.AddRowItems("Permissions", joinNames(role.Permissions))
+	_ = t.AddRowItems("Inherits", joinNames(role.Inherits))
+
+	t.Print(ui.TextFormat)
+}
+
+// itemName gets the name of the role or group for a command, which can be
+// given as the command parameter or the --name option. If it was not given, the
+// user is prompted for it using the given prompt message.
+func itemName(c *cli.Context, prompt string) string {
+	name, _ := c.String("name")
+
+	if c.ParameterCount() == 1 && name == "" {
+		name = c.Parameter(0)
+	}
+
+	for name == "" {
+		name = ui.Prompt(i18n.L(prompt))
+	}
+
+	return name
+}
+
+// joinNames formats a list of names for display, using "." for an empty list.
+func joinNames(list []string) string {
+	if len(list) == 0 {
+		return "."
+	}
+
+	return strings.Join(list, ", ")
+}
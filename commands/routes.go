@@ -6,10 +6,15 @@ import (
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/server/admin"
+	"github.com/tucats/ego/server/admin/apikeys"
 	"github.com/tucats/ego/server/admin/caches"
+	"github.com/tucats/ego/server/admin/roles"
+	"github.com/tucats/ego/server/admin/sessions"
 	"github.com/tucats/ego/server/admin/users"
 	"github.com/tucats/ego/server/assets"
 	"github.com/tucats/ego/server/dsns"
+	"github.com/tucats/ego/server/jobs"
+	"github.com/tucats/ego/server/secrets"
 	"github.com/tucats/ego/server/server"
 	"github.com/tucats/ego/server/tables"
 	xui "github.com/tucats/ego/server/ui"
@@ -18,6 +23,7 @@ import (
 
 const (
 	nameParameter = "{{name}}"
+	idParameter   = "{{id}}"
 )
 
 func defineStaticRoutes() *server.Router {
@@ -70,6 +76,164 @@ func defineStaticRoutes() *server.Router {
 		Class(server.AdminRequestCounter).
 		Permissions("admin_users")
 
+	// Enroll a user for one-time codes
+	router.New(defs.AdminUsersPath+nameParameter+"/totp", users.EnrollTOTPHandler, http.MethodPost).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users")
+
+	// Remove the enrollment of a user for one-time codes
+	router.New(defs.AdminUsersPath+nameParameter+"/totp", users.ResetTOTPHandler, http.MethodDelete).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users")
+
+	// List roles
+	router.New(defs.AdminRolesPath, roles.ListRolesHandler, http.MethodGet).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users", "admin_read")
+
+	// Get a specific role
+	router.New(defs.AdminRolesPath+nameParameter, roles.GetRoleHandler, http.MethodGet).
+		Authentication(true, true).
+		AcceptMedia(defs.RoleMediaType).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users", "admin_read")
+
+	// Create a new role
+	router.New(defs.AdminRolesPath, roles.CreateRoleHandler, http.MethodPost).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users")
+
+	// Modify a specific role
+	router.New(defs.AdminRolesPath+nameParameter, roles.UpdateRoleHandler, http.MethodPatch).
+		Authentication(true, true).
+		AcceptMedia(defs.RoleMediaType).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users")
+
+	// Delete an existing role
+	router.New(defs.AdminRolesPath+nameParameter, roles.DeleteRoleHandler, http.MethodDelete).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users")
+
+	// List groups
+	router.New(defs.AdminGroupsPath, roles.ListGroupsHandler, http.MethodGet).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users", "admin_read")
+
+	// Get a specific group
+	router.New(defs.AdminGroupsPath+nameParameter, roles.GetGroupHandler, http.MethodGet).
+		Authentication(true, true).
+		AcceptMedia(defs.GroupMediaType).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users", "admin_read")
+
+	// Create a new group
+	router.New(defs.AdminGroupsPath, roles.CreateGroupHandler, http.MethodPost).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users")
+
+	// Modify a specific group
+	router.New(defs.AdminGroupsPath+nameParameter, roles.UpdateGroupHandler, http.MethodPatch).
+		Authentication(true, true).
+		AcceptMedia(defs.GroupMediaType).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users")
+
+	// Delete an existing group
+	router.New(defs.AdminGroupsPath+nameParameter, roles.DeleteGroupHandler, http.MethodDelete).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users")
+
+	// List the active sessions
+	router.New(defs.AdminSessionsPath, sessions.ListSessionsHandler, http.MethodGet).
+		Authentication(true, true).
+		Parameter("user", util.StringParameterType).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users", "admin_read")
+
+	// Revoke a specific session
+	router.New(defs.AdminSessionsPath+idParameter, sessions.RevokeSessionHandler, http.MethodDelete).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users")
+
+	// Revoke all the sessions for a user
+	router.New(defs.AdminSessionsPath, sessions.RevokeSessionsHandler, http.MethodDelete).
+		Authentication(true, true).
+		Parameter("user", util.StringParameterType).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users")
+
+	// List the API keys
+	router.New(defs.AdminAPIKeysPath, apikeys.ListAPIKeysHandler, http.MethodGet).
+		Authentication(true, true).
+		Parameter("user", util.StringParameterType).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users", "admin_read")
+
+	// Create an API key
+	router.New(defs.AdminAPIKeysPath, apikeys.CreateAPIKeyHandler, http.MethodPost).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users")
+
+	// Revoke a specific API key
+	router.New(defs.AdminAPIKeysPath+idParameter, apikeys.RevokeAPIKeyHandler, http.MethodDelete).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_users")
+
+	// List the secrets
+	router.New(defs.AdminSecretsPath, secrets.ListSecretsHandler, http.MethodGet).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_secrets", "admin_read")
+
+	// Set a specific secret
+	router.New(defs.AdminSecretsPath+nameParameter, secrets.SetSecretHandler, http.MethodPut).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_secrets")
+
+	// Delete a specific secret
+	router.New(defs.AdminSecretsPath+nameParameter, secrets.DeleteSecretHandler, http.MethodDelete).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_secrets")
+
+	// List the scheduled jobs
+	router.New(defs.AdminJobsPath, jobs.ListJobsHandler, http.MethodGet).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_server", "admin_read")
+
+	// Get a specific job and its recent runs
+	router.New(defs.AdminJobsPath+nameParameter, jobs.GetJobHandler, http.MethodGet).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_server", "admin_read")
+
+	// Run a specific job now
+	router.New(defs.AdminJobsPath+nameParameter, jobs.RunJobHandler, http.MethodPost).
+		Authentication(true, true).
+		Parameter("wait", util.FlagParameterType).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_server")
+
+	// Pause or resume a specific job
+	router.New(defs.AdminJobsPath+nameParameter, jobs.UpdateJobHandler, http.MethodPatch).
+		Authentication(true, true).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_server")
+
 	// Get the status of the server cache.
 	router.New(defs.AdminCachesPath, caches.GetCacheHandler, http.MethodGet).
 		Authentication(true, true).
@@ -107,6 +271,18 @@ func defineStaticRoutes() *server.Router {
 		Class(server.AdminRequestCounter).
 		Permissions("admin_server")
 
+	// Query the audit log
+	router.New(defs.AdminAuditPath, admin.GetAuditHandler, http.MethodGet).
+		Authentication(true, true).
+		Parameter("event", util.StringParameterType).
+		Parameter("user", util.StringParameterType).
+		Parameter("session", util.IntParameterType).
+		Parameter("since", util.StringParameterType).
+		Parameter("until", util.StringParameterType).
+		Parameter("limit", util.IntParameterType).
+		Class(server.AdminRequestCounter).
+		Permissions("admin_server")
+
 	// Simplest possible "are you there" endpoint.
 	router.New(defs.AdminHeartbeatPath, admin.HeartbeatHandler, http.MethodGet).
 		LightWeight(true).
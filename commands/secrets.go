@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"net/http"
+
+	"github.com/tucats/ego/app-cli/cli"
+	"github.com/tucats/ego/app-cli/tables"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/i18n"
+	"github.com/tucats/ego/runtime/rest"
+)
+
+// SetSecret creates or replaces a secret on the running server. If the value is not
+// given on the command line, it is prompted for without being echoed. The value is
+// never displayed.
+func SetSecret(c *cli.Context) error {
+	secret := defs.Secret{Name: itemName(c, "secret.prompt")}
+	secrets := defs.SecretCollection{}
+
+	value, valueSpecified := c.String("value")
+	if !valueSpecified {
+		for value == "" {
+			value = ui.PromptPassword(i18n.L("secret.value.prompt"))
+		}
+	}
+
+	secret.Value = value
+	secret.Description, _ = c.String("description")
+	secret.Users, _ = c.StringList("users")
+	secret.Roles, _ = c.StringList("roles")
+
+	url := rest.URLBuilder(defs.AdminSecretsNamePath, secret.Name).String()
+
+	err := rest.Exchange(url, http.MethodPut, secret, &secrets, defs.AdminAgent, defs.SecretsMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if ui.OutputFormat == ui.TextFormat {
+		ui.Say("msg.secret.set", map[string]interface{}{"name": secret.Name})
+	}
+
+	return displaySecrets(secrets)
+}
+
+// ListSecrets lists the secrets on the running server, and who can read them.
+func ListSecrets(c *cli.Context) error {
+	secrets := defs.SecretCollection{}
+
+	err := rest.Exchange(defs.AdminSecretsPath, http.MethodGet, nil, &secrets, defs.AdminAgent, defs.SecretsMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	return displaySecrets(secrets)
+}
+
+// DeleteSecret deletes a secret from the running server.
+func DeleteSecret(c *cli.Context) error {
+	secrets := defs.SecretCollection{}
+	url := rest.URLBuilder(defs.AdminSecretsNamePath, itemName(c, "secret.prompt")).String()
+
+	err := rest.Exchange(url, http.MethodDelete, nil, &secrets, defs.AdminAgent, defs.SecretsMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if ui.OutputFormat == ui.TextFormat {
+		ui.Say("msg.secret.deleted", map[string]interface{}{"count": secrets.Count})
+	}
+
+	return displaySecrets(secrets)
+}
+
+// displaySecrets displays a list of secrets. The values are never included.
+func displaySecrets(secrets defs.SecretCollection) error {
+	if ui.OutputFormat != ui.TextFormat {
+		return commandOutput(secrets)
+	}
+
+	if len(secrets.Items) == 0 {
+		return nil
+	}
+
+	t, err := tables.New([]string{i18n.L("Name"), i18n.L("Users"), i18n.L("Roles"), i18n.L("Modified"), i18n.L("Description")})
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range secrets.Items {
+		err = t.AddRowItems(secret.Name, joinNames(secret.Users), joinNames(secret.Roles), secret.Modified, secret.Description)
+		if err != nil {
+			return err
+		}
+	}
+
+	t.SetPagination(0, 0)
+
+	return t.Print(ui.TextFormat)
+}
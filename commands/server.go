@@ -25,10 +25,15 @@ import (
 	"github.com/tucats/ego/errors"
 	"github.com/tucats/ego/runtime/profile"
 	"github.com/tucats/ego/runtime/rest"
+	"github.com/tucats/ego/server/audit"
 	"github.com/tucats/ego/server/auth"
 	"github.com/tucats/ego/server/dsns"
+	"github.com/tucats/ego/server/jobs"
+	"github.com/tucats/ego/server/secrets"
 	"github.com/tucats/ego/server/server"
 	"github.com/tucats/ego/server/services"
+	"github.com/tucats/ego/server/tables/changes"
+	"github.com/tucats/ego/server/tables/policies"
 	"github.com/tucats/ego/symbols"
 	"github.com/tucats/ego/util"
 )
@@ -81,6 +86,7 @@ func RunServer(c *cli.Context) error {
 		}
 
 		ui.Active(ui.ServerLogger, true)
+		ui.Active(ui.AuditLogger, true)
 
 		if fn, ok := c.String("log-file"); ok {
 			if err := ui.OpenLogFile(fn, true); err != nil {
@@ -112,6 +118,11 @@ func RunServer(c *cli.Context) error {
 	// include options that contain a token
 	dumpConfigToLog()
 
+	// Open the audit log of security-relevant actions.
+	if err := audit.Open(settings.Get(defs.ServerAuditFileSetting)); err != nil {
+		return err
+	}
+
 	// Configure the authentication subsystem, and load the user
 	// database (if specified).
 	if err := auth.Initialize(c); err != nil {
@@ -123,6 +134,11 @@ func RunServer(c *cli.Context) error {
 		return err
 	}
 
+	// Configure the secrets store used by services.
+	if err := secrets.Initialize(c); err != nil {
+		return err
+	}
+
 	// Create a router and define the static routes (those not depending on scanning the file system).
 	router, err := setupServerRouter(err, debugPath)
 	if err != nil {
@@ -169,6 +185,18 @@ func RunServer(c *cli.Context) error {
 	go server.LogMemoryStatistics()
 	go server.LogRequestCounts()
 
+	// Create the tables for the change logs, and start sending the changes made
+	// to tables with change logs to any webhooks in the configuration.
+	changes.Start()
+
+	// Create the table for the row-level security policies.
+	policies.Start()
+
+	// Find the job programs and start running them on their schedules.
+	if err := jobs.Initialize(filepath.Join(server.PathRoot, "jobs")); err != nil {
+		return err
+	}
+
 	// Dump out the route table if requested.
 	router.Dump()
 
@@ -504,6 +532,13 @@ func defineNativeAdminHandlers(router *server.Router) {
 			AcceptMedia(defs.JSONMediaType, defs.TextMediaType)
 	}
 
+	if _, status := router.FindRoute(http.MethodPost, defs.ServicesRefreshPath); status != http.StatusOK {
+		router.New(defs.ServicesRefreshPath, server.RefreshHandler, http.MethodPost).
+			Authentication(false, false).
+			Class(server.ServiceRequestCounter).
+			AcceptMedia(defs.JSONMediaType, defs.TextMediaType)
+	}
+
 	if _, status := router.FindRoute(http.MethodPost, defs.ServicesDownPath); status != http.StatusOK {
 		router.New(defs.ServicesDownPath, server.DownHandler, http.MethodPost).
 			Authentication(true, true).
@@ -525,6 +560,32 @@ func defineNativeAdminHandlers(router *server.Router) {
 			Class(server.ServiceRequestCounter).
 			AcceptMedia(defs.JSONMediaType)
 	}
+
+	if _, status := router.FindRoute(http.MethodGet, defs.JWKSPath); status != http.StatusOK {
+		router.New(defs.JWKSPath, server.JWKSHandler, http.MethodGet).
+			Authentication(false, false).
+			Class(server.ServiceRequestCounter).
+			AcceptMedia(defs.JSONMediaType)
+	}
+
+	// If there is an OpenID Connect identity provider, add the endpoints used to
+	// log in with the provider.
+	if auth.OIDCEnabled() {
+		router.New(defs.ServicesOIDCLoginPath, server.OIDCLoginHandler, http.MethodGet).
+			Authentication(false, false).
+			Class(server.ServiceRequestCounter)
+
+		router.New(defs.ServicesOIDCCallbackPath, server.OIDCCallbackHandler, http.MethodGet).
+			Authentication(false, false).
+			Class(server.ServiceRequestCounter).
+			Parameter("code", "string").
+			Parameter("state", "string").
+			Parameter("error", "string").
+			Parameter("error_description", "string").
+			Parameter("iss", "string").
+			Parameter("scope", "string").
+			Parameter("session_state", "string")
+	}
 }
 
 // Determine the context root for the server, which is based on the
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"net/http"
+
+	"github.com/tucats/ego/app-cli/cli"
+	"github.com/tucats/ego/app-cli/tables"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/i18n"
+	"github.com/tucats/ego/runtime/rest"
+)
+
+// ListSessions lists the active sessions on the running server. If the --user
+// option is given, only the sessions for that user are listed.
+func ListSessions(c *cli.Context) error {
+	sessions := defs.SessionCollection{}
+	url := rest.URLBuilder(defs.AdminSessionsPath)
+
+	if user, found := c.String("user"); found {
+		url.Parameter("user", user)
+	}
+
+	err := rest.Exchange(url.String(), http.MethodGet, nil, &sessions, defs.AdminAgent, defs.SessionsMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	return displaySessions(sessions)
+}
+
+// RevokeSession revokes the token for a session on the running server, so it can
+// no longer be used. The session is identified by the ID given as a parameter, or
+// all the sessions for the user given by the --user option are revoked.
+func RevokeSession(c *cli.Context) error {
+	var (
+		sessions = defs.SessionCollection{}
+		url      string
+	)
+
+	user, _ := c.String("user")
+
+	switch {
+	case c.ParameterCount() == 1 && user == "":
+		url = rest.URLBuilder(defs.AdminSessionsIDPath, c.Parameter(0)).String()
+
+	case c.ParameterCount() == 0 && user != "":
+		url = rest.URLBuilder(defs.AdminSessionsPath).Parameter("user", user).String()
+
+	default:
+		return errors.ErrInvalidRequest
+	}
+
+	err := rest.Exchange(url, http.MethodDelete, nil, &sessions, defs.AdminAgent, defs.SessionsMediaType)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if ui.OutputFormat == ui.TextFormat {
+		ui.Say("msg.session.revoked", map[string]interface{}{"count": sessions.Count})
+	}
+
+	return displaySessions(sessions)
+}
+
+// displaySessions displays a list of sessions.
+func displaySessions(sessions defs.SessionCollection) error {
+	if ui.OutputFormat != ui.TextFormat {
+		return commandOutput(sessions)
+	}
+
+	if len(sessions.Items) == 0 {
+		return nil
+	}
+
+	t, err := tables.New([]string{i18n.L("ID"), i18n.L("User"), i18n.L("Address"), i18n.L("Issued"), i18n.L("Expires"), i18n.L("Refresh")})
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions.Items {
+		if err = t.AddRowItems(session.ID, session.Name, session.Address, session.Issued, session.Expires, session.RefreshExpires); err != nil {
+			return err
+		}
+	}
+
+	t.SetPagination(0, 0)
+
+	return t.Print(ui.TextFormat)
+}
@@ -0,0 +1,317 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/cli"
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/runtime/rest"
+)
+
+const (
+	csvFormat    = "csv"
+	ndjsonFormat = "ndjson"
+
+	defaultImportBatchSize = 1000
+)
+
+// TableImport reads a CSV or JSON Lines file and inserts each row into the table. The
+// file is sent to the server in batches of rows, each of which is committed by the
+// server as a separate transaction, and the progress is reported after each batch.
+func TableImport(c *cli.Context) error {
+	table := c.Parameter(0)
+	fileName := c.Parameter(1)
+
+	format, err := bulkFormat(c, fileName)
+	if err != nil {
+		return err
+	}
+
+	batchSize := defaultImportBatchSize
+	if n, found := c.Integer("batch"); found && n > 0 {
+		batchSize = n
+	}
+
+	maxErrors, _ := c.Integer("max-errors")
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	defer file.Close()
+
+	var next func() ([]byte, int, error)
+
+	if format == csvFormat {
+		next, err = csvBatches(file, batchSize)
+		if err != nil {
+			return err
+		}
+	} else {
+		next = ndjsonBatches(file, batchSize)
+	}
+
+	result := defs.DBImportResponse{}
+	rowsSent := 0
+
+	for {
+		batch, count, err := next()
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			break
+		}
+
+		url := rest.URLBuilder(tableRowsPath(c, table)...)
+		url.Parameter(defs.BatchParameterName, batchSize)
+		url.Parameter(defs.MaxErrorsParameterName, maxErrors-len(result.Errors))
+
+		if mapping, found := c.StringList("map"); found {
+			url.Parameter(defs.MapParameterName, toInterfaces(mapping)...)
+		}
+
+		mediaType := defs.CSVMediaType
+		if format == ndjsonFormat {
+			mediaType = defs.NDJSONMediaType
+		}
+
+		resp := defs.DBImportResponse{}
+
+		err = rest.Exchange(url.String(), http.MethodPut, batch, &resp, defs.TableAgent, defs.ImportMediaType, mediaType)
+		if err != nil && resp.Status == 0 {
+			return errors.New(err)
+		}
+
+		// The row numbers in the errors are relative to the batch, so adjust them to be
+		// relative to the file.
+		for _, item := range resp.Errors {
+			item.Row += rowsSent
+			result.Errors = append(result.Errors, item)
+		}
+
+		result.Count += resp.Count
+		result.Batches += resp.Batches
+		result.Status = resp.Status
+		result.Message = resp.Message
+		rowsSent += count
+
+		if resp.Status > http.StatusOK {
+			break
+		}
+
+		if ui.OutputFormat == ui.TextFormat {
+			ui.Say("msg.table.import.progress", map[string]interface{}{
+				"count": result.Count,
+				"name":  table,
+			})
+		}
+	}
+
+	if ui.OutputFormat != ui.TextFormat {
+		return commandOutput(result)
+	}
+
+	for _, item := range result.Errors {
+		ui.Say("msg.table.import.error", map[string]interface{}{
+			"row":   item.Row,
+			"error": item.Message,
+		})
+	}
+
+	if result.Status > http.StatusOK {
+		return errors.Message(result.Message)
+	}
+
+	ui.Say("msg.table.imported", map[string]interface{}{
+		"count":  result.Count,
+		"name":   table,
+		"errors": len(result.Errors),
+	})
+
+	return nil
+}
+
+// TableExport writes the rows of a table to a file, or to the console if no file is
+// given, as CSV or JSON Lines data. The rows are streamed from the server directly to
+// the output.
+func TableExport(c *cli.Context) error {
+	table := c.Parameter(0)
+	fileName := c.Parameter(1)
+
+	format, err := bulkFormat(c, fileName)
+	if err != nil {
+		return err
+	}
+
+	url := rest.URLBuilder(tableRowsPath(c, table)...)
+
+	if columns, ok := c.StringList("columns"); ok {
+		url.Parameter(defs.ColumnParameterName, toInterfaces(columns)...)
+	}
+
+	if order, ok := c.StringList("order-by"); ok {
+		url.Parameter(defs.SortParameterName, toInterfaces(order)...)
+	}
+
+	if filter, ok := c.StringList("filter"); ok {
+		f := makeFilter(filter)
+		if strings.HasPrefix(f, filterParseError) {
+			return errors.Message(strings.TrimPrefix(f, filterParseError))
+		}
+
+		url.Parameter(defs.FilterParameterName, f)
+	}
+
+	mediaType := defs.CSVMediaType
+	if format == ndjsonFormat {
+		mediaType = defs.NDJSONMediaType
+	}
+
+	var output io.Writer = os.Stdout
+
+	if fileName != "" {
+		file, err := os.Create(fileName)
+		if err != nil {
+			return errors.New(err)
+		}
+
+		defer file.Close()
+
+		output = file
+	}
+
+	if err = rest.Stream(url.String(), http.MethodGet, output, defs.TableAgent, mediaType); err != nil {
+		return errors.New(err)
+	}
+
+	if fileName != "" {
+		ui.Say("msg.table.exported", map[string]interface{}{
+			"name": table,
+			"file": fileName,
+		})
+	}
+
+	return nil
+}
+
+// bulkFormat determines the format of the import or export data. This is the value of
+// the --format option if given, or is based on the file extension. CSV is the default.
+func bulkFormat(c *cli.Context, fileName string) (string, error) {
+	if format, found := c.Keyword("format"); found {
+		return []string{csvFormat, ndjsonFormat}[format], nil
+	}
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".json", ".jsonl", ".ndjson":
+		return ndjsonFormat, nil
+
+	case "", ".csv", ".txt":
+		return csvFormat, nil
+	}
+
+	return "", errors.ErrInvalidKeyword.Context(filepath.Ext(fileName))
+}
+
+// tableRowsPath returns the URL parts for the rows of a table, using the --dsn option or
+// the default data source if one was given.
+func tableRowsPath(c *cli.Context, table string) []interface{} {
+	path := []interface{}{defs.TablesRowsPath, table}
+
+	if dsn := settings.Get(defs.DefaultDataSourceSetting); dsn != "" {
+		path = []interface{}{defs.DSNTablesRowsPath, dsn, table}
+	}
+
+	if dsn, found := c.String("dsn"); found {
+		path = []interface{}{defs.DSNTablesRowsPath, dsn, table}
+	} else if settings.GetBool(defs.TableAutoparseDSN) && strings.Contains(table, ".") {
+		parts := strings.SplitN(table, ".", 2)
+		path = []interface{}{defs.DSNTablesRowsPath, parts[0], parts[1]}
+	}
+
+	return path
+}
+
+// csvBatches returns a function that reads the next batch of records from a CSV file. Each
+// batch is returned as CSV text that starts with the header record from the file, along
+// with the number of records in the batch. At the end of the file, the count is zero.
+func csvBatches(file io.Reader, batchSize int) (func() ([]byte, int, error), error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	return func() ([]byte, int, error) {
+		var buffer bytes.Buffer
+
+		writer := csv.NewWriter(&buffer)
+		_ = writer.Write(header)
+
+		count := 0
+
+		for count < batchSize {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return nil, 0, errors.New(err)
+			}
+
+			_ = writer.Write(record)
+			count++
+		}
+
+		writer.Flush()
+
+		return buffer.Bytes(), count, nil
+	}, nil
+}
+
+// ndjsonBatches returns a function that reads the next batch of lines from a JSON Lines
+// file, along with the number of lines in the batch. At the end of the file, the count
+// is zero.
+func ndjsonBatches(file io.Reader, batchSize int) func() ([]byte, int, error) {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	return func() ([]byte, int, error) {
+		var buffer bytes.Buffer
+
+		count := 0
+
+		for count < batchSize && scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			buffer.WriteString(line)
+			buffer.WriteRune('\n')
+
+			count++
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, 0, errors.New(err)
+		}
+
+		return buffer.Bytes(), count, nil
+	}
+}
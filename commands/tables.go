@@ -2,6 +2,7 @@ package commands
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"sort"
@@ -149,7 +150,8 @@ func TableShow(c *cli.Context) error {
 }
 
 // Given a column info response object, format it as text output that shows the
-// columns of the associated table and their properties.
+// columns of the associated table and their properties. If the table has a primary
+// key, secondary indexes, or foreign keys, these are shown after the columns.
 func formatColumnPropertiesAsText(resp defs.TableColumnsInfo) {
 	t, _ := tables.New([]string{
 		i18n.L("Name"),
@@ -157,13 +159,21 @@ func formatColumnPropertiesAsText(resp defs.TableColumnsInfo) {
 		i18n.L("Size"),
 		i18n.L("Nullable"),
 		i18n.L("Unique"),
+		i18n.L("Primary"),
+		i18n.L("Default"),
 	})
 	_ = t.SetOrderBy(i18n.L("Name"))
 	_ = t.SetAlignment(2, tables.AlignmentRight)
 
+	keys := map[string]bool{}
+	for _, name := range resp.PrimaryKey {
+		keys[name] = true
+	}
+
 	for _, row := range resp.Columns {
 		nullable := "default"
 		unique := "default"
+		primary := "no"
 
 		if row.Nullable.Specified {
 			if row.Nullable.Value {
@@ -181,10 +191,54 @@ func formatColumnPropertiesAsText(resp defs.TableColumnsInfo) {
 			}
 		}
 
-		_ = t.AddRowItems(row.Name, row.Type, row.Size, nullable, unique)
+		if keys[row.Name] {
+			primary = "yes"
+		}
+
+		_ = t.AddRowItems(row.Name, row.Type, row.Size, nullable, unique, primary, row.Default)
 	}
 
 	t.Print(ui.OutputFormat)
+
+	if len(resp.Indexes) > 0 {
+		fmt.Println()
+
+		t, _ = tables.New([]string{
+			i18n.L("Index"),
+			i18n.L("Columns"),
+			i18n.L("Unique"),
+		})
+		_ = t.SetOrderBy(i18n.L("Index"))
+
+		for _, index := range resp.Indexes {
+			unique := "no"
+			if index.Unique {
+				unique = "yes"
+			}
+
+			_ = t.AddRowItems(index.Name, strings.Join(index.Columns, ", "), unique)
+		}
+
+		t.Print(ui.OutputFormat)
+	}
+
+	if len(resp.ForeignKeys) > 0 {
+		fmt.Println()
+
+		t, _ = tables.New([]string{
+			i18n.L("Foreign.key"),
+			i18n.L("Columns"),
+			i18n.L("References"),
+			i18n.L("On.delete"),
+		})
+
+		for _, key := range resp.ForeignKeys {
+			references := key.Table + "(" + strings.Join(key.References, ", ") + ")"
+			_ = t.AddRowItems(key.Name, strings.Join(key.Columns, ", "), references, key.OnDelete)
+		}
+
+		t.Print(ui.OutputFormat)
+	}
 }
 
 func TableDrop(c *cli.Context) error {
@@ -462,14 +516,14 @@ func TableCreate(c *cli.Context) error {
 	// If the user specified a file with a JSON payload, use that to seed the
 	// field definitions map. We will also look for command line parameters to
 	// extend or modify the field list.
-	fields, err := loadJSONFieldDefinitions(c)
+	fields, definition, err := loadJSONFieldDefinitions(c)
 	if err != nil {
 		return err
 	}
 
 	// Continue to define fields for the table we're creating using the command
 	// line parmaters.
-	err = loadCommandlineFieldDefinitions(c, fields)
+	err = loadCommandlineFieldDefinitions(c, fields, &definition)
 	if err != nil {
 		return err
 	}
@@ -477,6 +531,15 @@ func TableCreate(c *cli.Context) error {
 	// Convert the map to an array of fields to use as a JSON payload for the response.
 	payload := createTablePayload(fields)
 
+	// If there are no constraints, the payload is just the array of columns. Otherwise,
+	// send the full table definition.
+	var body interface{} = payload
+
+	if len(definition.PrimaryKey) > 0 || len(definition.Indexes) > 0 || len(definition.ForeignKeys) > 0 {
+		definition.Columns = payload
+		body = definition
+	}
+
 	urlString := rest.URLBuilder(defs.TablesNamePath, table).String()
 	if dsn := settings.Get(defs.DefaultDataSourceSetting); dsn != "" {
 		urlString = rest.URLBuilder(defs.DSNTablesNamePath, dsn, table).String()
@@ -492,7 +555,7 @@ func TableCreate(c *cli.Context) error {
 	}
 
 	// Send the array to the server
-	err = rest.Exchange(urlString, http.MethodPut, payload, &resp, defs.TableAgent)
+	err = rest.Exchange(urlString, http.MethodPut, body, &resp, defs.TableAgent)
 	if err == nil {
 		if resp.Status > http.StatusOK {
 			err = errors.Message(resp.Message)
@@ -530,7 +593,10 @@ func createTablePayload(fields map[string]defs.DBColumn) []defs.DBColumn {
 	return payload
 }
 
-func loadCommandlineFieldDefinitions(c *cli.Context, fields map[string]defs.DBColumn) error {
+// loadCommandlineFieldDefinitions adds the column definitions from the command line
+// parameters to the field definitions map. Any column marked as "primary" is added
+// to the primary key in the table definition.
+func loadCommandlineFieldDefinitions(c *cli.Context, fields map[string]defs.DBColumn, definition *defs.DBTableDefinition) error {
 	defined := map[string]bool{}
 
 	for i := 1; i < 999; i++ {
@@ -591,6 +657,25 @@ func loadCommandlineFieldDefinitions(c *cli.Context, fields map[string]defs.DBCo
 			case "nonnullable", "nonnull":
 				columnInfo.Nullable = defs.BoolValue{Specified: true, Value: false}
 
+			case "primary", "key":
+				definition.PrimaryKey = append(definition.PrimaryKey, columnName)
+
+			case "default":
+				if !t.IsNext(tokenizer.AssignToken) {
+					return errors.ErrInvalidColumnDefinition.Context(columnDefText)
+				}
+
+				value := t.Next()
+				if value.Spelling() == "-" || value.Spelling() == "+" {
+					value = t.NewToken(tokenizer.ValueTokenClass, value.Spelling()+t.Next().Spelling())
+				}
+
+				if value.IsString() {
+					columnInfo.Default = "'" + value.Spelling() + "'"
+				} else {
+					columnInfo.Default = value.Spelling()
+				}
+
 			default:
 				return errors.ErrInvalidKeyword.Context(flag)
 			}
@@ -606,32 +691,41 @@ func loadCommandlineFieldDefinitions(c *cli.Context, fields map[string]defs.DBCo
 }
 
 // loadJSONFieldDefinitions reads a JSON file containing field definitions and creates
-// the initial field definitions map. If the file is not specified, this just returns
-// an empty map, which can then be extended by the caller.
-func loadJSONFieldDefinitions(c *cli.Context) (map[string]defs.DBColumn, error) {
+// the initial field definitions map. The file can contain an array of column definitions,
+// or a table definition object that also describes keys and indexes. If the file is not
+// specified, this just returns an empty map, which can then be extended by the caller.
+func loadJSONFieldDefinitions(c *cli.Context) (map[string]defs.DBColumn, defs.DBTableDefinition, error) {
 	fields := map[string]defs.DBColumn{}
-	payload := make([]defs.DBColumn, 0)
+	definition := defs.DBTableDefinition{}
 
 	if c.WasFound("file") {
 		fn, _ := c.String("file")
 
 		b, err := os.ReadFile(fn)
 		if err != nil {
-			return fields, errors.New(err)
+			return fields, definition, errors.New(err)
 		}
 
-		if err = json.Unmarshal(b, &payload); err != nil {
-			return fields, errors.New(err)
+		if strings.HasPrefix(strings.TrimSpace(string(b)), "[") {
+			err = json.Unmarshal(b, &definition.Columns)
+		} else {
+			err = json.Unmarshal(b, &definition)
+		}
+
+		if err != nil {
+			return fields, definition, errors.New(err)
 		}
 
 		// Move the info read in to a map so we can replace fields from
 		// the command line if specified.
-		for _, field := range payload {
+		for _, field := range definition.Columns {
 			fields[field.Name] = field
 		}
+
+		definition.Columns = nil
 	}
 
-	return fields, nil
+	return fields, definition, nil
 }
 
 func TableUpdate(c *cli.Context) error {
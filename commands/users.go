@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/tucats/ego/app-cli/cli"
@@ -60,13 +61,21 @@ func AddUser(c *cli.Context) error {
 	return err
 }
 
-// Update is used to modify an existing user on the server.
+// Update is used to modify an existing user on the server. The user can also be
+// enrolled for one-time codes as a second logon factor, or have the enrollment
+// removed.
 func UpdateUser(c *cli.Context) error {
 	var err error
 
 	user, _ := c.String("username")
 	pass, _ := c.String("password")
 	permissions, _ := c.StringList("permissions")
+	enroll := c.Boolean("enroll-totp")
+	reset := c.Boolean("reset-totp")
+
+	if enroll && reset {
+		return errors.ErrInvalidRequest.Context("--enroll-totp, --reset-totp")
+	}
 
 	if c.ParameterCount() == 1 {
 		if user == "" {
@@ -80,21 +89,74 @@ func UpdateUser(c *cli.Context) error {
 		user = ui.Prompt(i18n.L("username.prompt"))
 	}
 
-	payload := defs.User{
-		Name:        user,
-		Password:    pass,
-		Permissions: permissions,
-	}
-	resp := defs.UserResponse{}
+	// If only the enrollment for one-time codes is changed, there is no update
+	// to the user record itself.
+	if pass != "" || len(permissions) > 0 || (!enroll && !reset) {
+		payload := defs.User{
+			Name:        user,
+			Password:    pass,
+			Permissions: permissions,
+		}
+		resp := defs.UserResponse{}
+
+		err = rest.Exchange(defs.AdminUsersPath+user, http.MethodPatch, payload, &resp, defs.AdminAgent, defs.UserMediaType)
+		if err != nil {
+			return errors.New(err)
+		}
 
-	err = rest.Exchange(defs.AdminUsersPath+user, http.MethodPatch, payload, &resp, defs.AdminAgent, defs.UserMediaType)
-	if err == nil {
 		displayUser(&resp.User, "updated")
-	} else {
-		err = errors.New(err)
 	}
 
-	return err
+	if enroll || reset {
+		method := http.MethodPost
+		if reset {
+			method = http.MethodDelete
+		}
+
+		resp := defs.TOTPEnrollment{}
+
+		err = rest.Exchange(fmt.Sprintf(defs.AdminUsersTOTPPath, user), method, nil, &resp, defs.AdminAgent, defs.TOTPMediaType)
+		if err != nil {
+			return errors.New(err)
+		}
+
+		displayEnrollment(resp)
+	}
+
+	return nil
+}
+
+// displayEnrollment displays the result of enrolling a user for one-time codes,
+// or removing the enrollment. When a user is enrolled, the provisioning URI for
+// an authenticator app and the recovery codes are displayed.
+func displayEnrollment(enrollment defs.TOTPEnrollment) {
+	if ui.OutputFormat != ui.TextFormat {
+		_ = commandOutput(enrollment)
+
+		return
+	}
+
+	if enrollment.URI == "" {
+		ui.Say("msg.user.totp.reset", map[string]interface{}{
+			"user": enrollment.Name,
+		})
+
+		return
+	}
+
+	ui.Say("msg.user.totp.enrolled", map[string]interface{}{
+		"user": enrollment.Name,
+		"uri":  enrollment.URI,
+	})
+
+	t, _ := tables.New([]string{i18n.L("Recovery")})
+
+	for _, code := range enrollment.Recovery {
+		_ = t.AddRowItems(code)
+	}
+
+	t.SetPagination(0, 0)
+	t.Print(ui.TextFormat)
 }
 
 // Show is used to fetch and display the user information for a single user.
@@ -536,18 +536,23 @@ func (c *Compiler) AutoImport(all bool, s *symbols.SymbolTable) error {
 
 		// Add the list of packages that live in the runtime package tree.
 		for _, name := range []string{
+			"atomic",
 			"base64",
 			"cipher",
+			"context",
+			"csv",
 			"db",
 			"errors",
 			"exec",
 			"filepath",
+			"http",
 			"io",
 			"json",
 			"math",
 			"os",
 			"reflect",
 			"rest",
+			"secrets",
 			"sort",
 			"strconv",
 			"strings",
@@ -555,6 +560,8 @@ func (c *Compiler) AutoImport(all bool, s *symbols.SymbolTable) error {
 			"time",
 			"util",
 			"uuid",
+			"xml",
+			"yaml",
 		} {
 			uniqueNames[name] = true
 		}
@@ -35,6 +35,7 @@ const (
 	PassDirective         = "pass"
 	ProfileDirective      = "profile"
 	ResponseDirective     = "response"
+	ScheduleDirective     = "schedule"
 	RespHeaderDirective   = "respheader"
 	SerializeDirective    = "serialize"
 	StatusDirective       = "status"
@@ -123,6 +124,9 @@ func (c *Compiler) compileDirective() error {
 	case ResponseDirective:
 		return c.responseDirective()
 
+	case ScheduleDirective:
+		return c.scheduleDirective()
+
 	case SerializeDirective:
 		return c.serializeDirective()
 
@@ -241,7 +245,23 @@ func (c *Compiler) endpointDirective() error {
 	return nil
 }
 
-// Generate the call to the main program, and the exit code.
+// Identify the schedule for a job program run by the server, such
+// as "0 2 * * *".
+func (c *Compiler) scheduleDirective() error {
+	if c.t.EndofStatement() {
+		return c.error(errors.ErrInvalidSchedule)
+	}
+
+	schedule := c.t.Next()
+	if !schedule.IsString() {
+		return c.error(errors.ErrInvalidSchedule)
+	}
+
+	// We do no work here, this text is processed when the server
+	// finds the jobs to run.
+	return nil
+}
+
 func (c *Compiler) entrypointDirective() error {
 	if c.t.EndofStatement() {
 		return c.error(errors.ErrMissingFunctionName)
@@ -21,6 +21,9 @@ var nativePackageNames = map[string]string{
 	"os/exec":       "exec",
 	"encode/base64": "base64",
 	"encode/json":   "json",
+	"encoding/csv":  "csv",
+	"encoding/xml":  "xml",
+	"sync/atomic":   "atomic",
 }
 
 // compileImport handles the import statement.
@@ -311,8 +311,16 @@ func (c *Compiler) parseStructFieldTypes(t *data.Type) (*data.Type, error) {
 			return data.UndefinedType, err
 		}
 
+		// If the type is followed by a string, it is the Go-style tag
+		// for the field(s), such as `json:"name,omitempty"`.
+		tag := ""
+		if c.t.Peek(1).IsString() {
+			tag = c.t.Next().Spelling()
+		}
+
 		for _, fieldName := range fieldNames {
 			t.DefineField(fieldName, fieldType)
+			t.SetFieldTag(fieldName, tag)
 		}
 
 		c.t.IsNext(tokenizer.CommaToken)
@@ -400,6 +408,7 @@ func embedType(newType *data.Type, embeddedType *data.Type) {
 		for _, fieldName := range fieldNames {
 			fieldType, _ := baseType.Field(fieldName)
 			newType.DefineField(fieldName, fieldType)
+			newType.SetFieldTag(fieldName, baseType.FieldTag(fieldName))
 		}
 	}
 }
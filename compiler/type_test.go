@@ -43,6 +43,13 @@ func TestCompileTypeSpec(t *testing.T) {
 				data.Field{Name: "age", Type: data.IntType},
 			),
 		},
+		{
+			name: "struct { name string `json:\"full_name\"`, age int \"db:\\\"age\\\"\" }",
+			want: data.StructureType(
+				data.Field{Name: "name", Type: data.StringType},
+				data.Field{Name: "age", Type: data.IntType},
+			).SetFieldTag("name", `json:"full_name"`).SetFieldTag("age", `db:"age"`),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
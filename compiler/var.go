@@ -54,6 +54,12 @@ func (c *Compiler) compileVar() error {
 			return c.varUserType(names)
 		}
 
+		// A pointer to a user or package type, such as *sync.Cond, is
+		// also created from the type named after the pointer token.
+		if kind.IsPointer() && kind.BaseType().IsUndefined() {
+			return c.varUserType(names)
+		}
+
 		// We got a defined type, so emit the model and store it
 		// in each symbol. However, if there's an "=" next, it
 		// means the user has supplied the model (initial value).
@@ -20,6 +20,7 @@ type Channel struct {
 	isOpen  bool
 	count   int
 	id      string
+	signal  bool
 }
 
 // Create a mew instance of an Ego channel. The size passed indicates
@@ -45,6 +46,17 @@ func NewChannel(size int) *Channel {
 	return c
 }
 
+// NewSignalChannel creates a channel that is used only to signal an event, by
+// closing the channel. Unlike other channels, receiving from a signal channel
+// that is closed is not an error; as in Go, the receive completes immediately
+// with a nil value. This allows any number of receivers to wait for the event.
+func NewSignalChannel() *Channel {
+	c := NewChannel(1)
+	c.signal = true
+
+	return c
+}
+
 // Send transmits an arbitrary data object through the channel, if it
 // is open. We must verify that the chanel is open before using it. It
 // is important to put the logging message out brefore re-locking the
@@ -73,6 +85,30 @@ func (c *Channel) Send(datum interface{}) error {
 	return errors.ErrChannelNotOpen
 }
 
+// TrySend transmits a data object through the channel only if it can be done
+// without waiting. The result is false if the channel is not open or its buffer
+// is full, in which case the object is discarded. This is used for events such
+// as timer ticks, where a receiver that is not keeping up should miss an event
+// rather than block the sender.
+func (c *Channel) TrySend(datum interface{}) bool {
+	if c == nil || !c.IsOpen() {
+		return false
+	}
+
+	select {
+	case c.channel <- datum:
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		c.count++
+
+		return true
+
+	default:
+		return false
+	}
+}
+
 // Receive accepts an arbitrary data object through the channel, waiting
 // if there is no information available yet. If it's not open, we also
 // check to see if the messages have all been drained by looking at the
@@ -86,15 +122,22 @@ func (c *Channel) Receive() (interface{}, error) {
 		"name", c.String())
 
 	if !c.IsOpen() && c.count == 0 {
+		if c.signal {
+			return nil, nil
+		}
+
 		return nil, errors.ErrChannelNotOpen
 	}
 
-	datum := <-c.channel
+	datum, ok := <-c.channel
 
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	c.count--
+	// If the channel was closed while waiting, there is no item to count.
+	if ok {
+		c.count--
+	}
 
 	return datum, nil
 }
@@ -45,6 +45,7 @@ const (
 	SizeMDName        = "Size"
 	StaticMDName      = "Static"
 	SymbolsMDName     = "Symbols"
+	TagsMDName        = "Tags"
 	TextMDName        = "Text"
 	TypeMDName        = "Type"
 	EmbedMDName       = "Embed"
@@ -19,6 +19,12 @@ func Sanitize(v interface{}) interface{} {
 		return v.data
 
 	case *Struct:
+		// A structure with field tags is returned as-is, so its own JSON
+		// marshaller can apply the tags to the field names and values.
+		if v.typeDef.HasFieldTags() {
+			return v
+		}
+
 		return v.fields
 
 	case *Map:
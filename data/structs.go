@@ -487,8 +487,8 @@ func (s *Struct) Set(name string, value interface{}) error {
 		}
 	}
 
-	if s.typeDef.fields != nil {
-		if t, ok := s.typeDef.fields[name]; ok {
+	if fields := s.typeDef.BaseType().fields; fields != nil {
+		if t, ok := fields[name]; ok {
 			// Does it have to match already?
 			if s.strictTypeChecks && !IsType(value, t) {
 				return errors.ErrInvalidType.Context(TypeOf(value).String())
@@ -760,19 +760,45 @@ func (s *Struct) MarshalJSON() ([]byte, error) {
 	// Need to use the sorted list of names so results are deterministic,
 	// as opposed to ranging over the fields directly.
 	keys := s.FieldNames(false)
-	for i, k := range keys {
-		if i > 0 {
-			b.WriteString(",")
-		}
+	count := 0
 
+	for _, k := range keys {
 		v := s.GetAlways(k)
 
+		// If the field has a json tag, it can rename the field, omit it
+		// entirely, omit it when it has an empty value, or encode the
+		// value as a JSON string.
+		name, options := k, []string{}
+		if tag, found := s.typeDef.FieldTagValue(k, "json"); found {
+			if tag == "-" {
+				continue
+			}
+
+			if name, options = ParseTagValue(tag); name == "" {
+				name = k
+			}
+		}
+
+		if hasTagOption(options, "omitempty") && IsEmptyValue(v) {
+			continue
+		}
+
 		jsonBytes, err := json.Marshal(v)
 		if err != nil {
 			return nil, errors.New(err)
 		}
 
-		b.WriteString(fmt.Sprintf(`"%s":%s`, k, string(jsonBytes)))
+		if hasTagOption(options, "string") && isStringableValue(v) {
+			jsonBytes, _ = json.Marshal(string(jsonBytes))
+		}
+
+		if count > 0 {
+			b.WriteString(",")
+		}
+
+		count++
+
+		b.WriteString(fmt.Sprintf(`"%s":%s`, name, string(jsonBytes)))
 	}
 
 	b.WriteString("}")
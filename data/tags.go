@@ -0,0 +1,135 @@
+package data
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/ui"
+)
+
+// SetFieldTag stores the tag string for a field of a structure type. The
+// tag uses the same format as a Go struct field tag, such as the string
+// `json:"name,omitempty" db:"column"`. An empty tag removes any existing
+// tag from the field.
+func (t *Type) SetFieldTag(name, tag string) *Type {
+	if t == nil {
+		ui.Log(ui.InternalLogger, "runtime.type.nil.write")
+
+		return nil
+	}
+
+	if t.kind == TypeKind {
+		t.BaseType().SetFieldTag(name, tag)
+
+		return t
+	}
+
+	if tag == "" {
+		delete(t.tags, name)
+
+		return t
+	}
+
+	if t.tags == nil {
+		t.tags = map[string]string{}
+	}
+
+	t.tags[name] = tag
+
+	return t
+}
+
+// FieldTag returns the tag string for a field of a structure type. If the
+// type is a user type based on a structure, the tag is read from the base
+// type. The result is an empty string if the field has no tag.
+func (t *Type) FieldTag(name string) string {
+	if t == nil {
+		return ""
+	}
+
+	if t.kind == TypeKind && t.valueType != nil {
+		return t.valueType.FieldTag(name)
+	}
+
+	return t.tags[name]
+}
+
+// HasFieldTags returns true if any field of the structure type has a tag.
+func (t *Type) HasFieldTags() bool {
+	if t == nil {
+		return false
+	}
+
+	if t.kind == TypeKind && t.valueType != nil {
+		return t.valueType.HasFieldTags()
+	}
+
+	return len(t.tags) > 0
+}
+
+// FieldTagValue returns the value associated with a key in the tag of a
+// structure field, such as the column name from a tag of `db:"column"`.
+// The boolean result is false if the tag does not contain the key.
+func (t *Type) FieldTagValue(name, key string) (string, bool) {
+	return reflect.StructTag(t.FieldTag(name)).Lookup(key)
+}
+
+// ParseTagValue splits the value of a tag key into the name and the list of
+// options that follow it. For example, the value "id,omitempty,string" has a
+// name of "id" and the options "omitempty" and "string".
+func ParseTagValue(value string) (string, []string) {
+	parts := strings.Split(value, ",")
+
+	return parts[0], parts[1:]
+}
+
+// hasTagOption returns true if the list of tag options contains the option.
+func hasTagOption(options []string, option string) bool {
+	for _, item := range options {
+		if item == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsEmptyValue returns true if the value is considered empty for the purposes
+// of the "omitempty" tag option. This is a nil value, a false boolean, a zero
+// numeric value, an empty string, or an empty array or map.
+func IsEmptyValue(v interface{}) bool {
+	switch actual := v.(type) {
+	case nil:
+		return true
+
+	case bool:
+		return !actual
+
+	case string:
+		return actual == ""
+
+	case *Array:
+		return actual == nil || actual.Len() == 0
+
+	case *Map:
+		return actual == nil || len(actual.Keys()) == 0
+	}
+
+	if IsNumeric(v) {
+		return Float64OrZero(v) == 0
+	}
+
+	return false
+}
+
+// isStringableValue returns true if the value can be encoded as a JSON string
+// by the "string" json tag option. This only applies to numeric, boolean, and
+// string values.
+func isStringableValue(v interface{}) bool {
+	switch v.(type) {
+	case bool, string:
+		return true
+	}
+
+	return IsNumeric(v)
+}
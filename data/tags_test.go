@@ -0,0 +1,88 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestType_FieldTag(t *testing.T) {
+	base := StructureType(
+		Field{Name: "Name", Type: StringType},
+		Field{Name: "Age", Type: IntType},
+	).SetFieldTag("Name", `json:"name,omitempty" db:"full_name"`)
+
+	userType := TypeDefinition("Person", base)
+
+	if !userType.HasFieldTags() {
+		t.Errorf("HasFieldTags() = false, want true")
+	}
+
+	if got := userType.FieldTag("Name"); got != `json:"name,omitempty" db:"full_name"` {
+		t.Errorf("FieldTag() = %q", got)
+	}
+
+	if got := userType.FieldTag("Age"); got != "" {
+		t.Errorf("FieldTag() of untagged field = %q, want empty", got)
+	}
+
+	if got, found := userType.FieldTagValue("Name", "db"); !found || got != "full_name" {
+		t.Errorf("FieldTagValue() = %q, %v", got, found)
+	}
+
+	if _, found := userType.FieldTagValue("Name", "xml"); found {
+		t.Errorf("FieldTagValue() found a missing key")
+	}
+
+	name, options := ParseTagValue("name,omitempty,string")
+	if name != "name" || !reflect.DeepEqual(options, []string{"omitempty", "string"}) {
+		t.Errorf("ParseTagValue() = %q, %v", name, options)
+	}
+
+	if got := base.String(); got != `struct{Name string "json:\"name,omitempty\" db:\"full_name\"", Age int}` {
+		t.Errorf("String() = %s", got)
+	}
+}
+
+func TestStruct_MarshalJSONTags(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		want   string
+	}{
+		{
+			name:   "renamed and string fields",
+			values: map[string]interface{}{"Name": "Tom", "Age": 55, "Secret": "x", "ID": 42},
+			want:   `{"age":55,"id":"42","name":"Tom"}`,
+		},
+		{
+			name:   "omitted empty field",
+			values: map[string]interface{}{"Name": "Tom", "ID": 7},
+			want:   `{"id":"7","name":"Tom"}`,
+		},
+	}
+
+	personType := TypeDefinition("Person", StructureType(
+		Field{Name: "Name", Type: StringType},
+		Field{Name: "Age", Type: IntType},
+		Field{Name: "Secret", Type: StringType},
+		Field{Name: "ID", Type: IntType},
+	).SetFieldTag("Name", `json:"name"`).
+		SetFieldTag("Age", `json:"age,omitempty"`).
+		SetFieldTag("Secret", `json:"-"`).
+		SetFieldTag("ID", `json:"id,string"`))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStructOfTypeFromMap(personType, tt.values)
+
+			b, err := s.MarshalJSON()
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+
+			if string(b) != tt.want {
+				t.Errorf("MarshalJSON() = %s, want %s", string(b), tt.want)
+			}
+		})
+	}
+}
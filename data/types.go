@@ -3,6 +3,7 @@ package data
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -196,6 +197,7 @@ type Type struct {
 	embeddedTypes   map[string]embeddedType
 	functions       map[string]Function
 	fieldOrder      []string
+	tags            map[string]string
 	format          func(value interface{}) string
 	keyType         *Type
 	valueType       *Type
@@ -688,6 +690,11 @@ func structTypeString(t Type) string {
 			b.WriteString(k)
 			b.WriteString(" ")
 			b.WriteString(t.fields[k].String())
+
+			if tag := t.tags[k]; tag != "" {
+				b.WriteString(" ")
+				b.WriteString(strconv.Quote(tag))
+			}
 		}
 
 		b.WriteString("}")
@@ -999,6 +1006,10 @@ func (t *Type) Embed(name string, embedType *Type) *Type {
 	for _, embeddedFieldName := range et.fields {
 		t.fieldOrder = append(t.fieldOrder, et.name)
 		t.fields[embeddedFieldName] = embedType.fields[embeddedFieldName]
+
+		if tag := embedType.FieldTag(embeddedFieldName); tag != "" {
+			t.SetFieldTag(embeddedFieldName, tag)
+		}
 	}
 
 	// Copy all the methods from the embedded type to the parent type.
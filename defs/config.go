@@ -22,6 +22,10 @@ const (
 	// as rest calls.
 	LogonTokenSetting = LogonKeyPrefix + "token"
 
+	// The refresh token from the last ego logon command, if the server issues
+	// refresh tokens. It is used to get a new token when the token expires.
+	LogonRefreshSetting = LogonKeyPrefix + "refresh"
+
 	// Stores the expiration date from the last login. This can be
 	// used to detect an expired token and provide a better message
 	// to the client user than "not authorized".
@@ -231,6 +235,77 @@ const (
 	// values. The default is every 180 seconds (3 minutes).
 	AuthCacheScanSetting = ServerKeyPrefix + "auth.cache.scan"
 
+	// The issuer URL of an OpenID Connect identity provider. If specified, users
+	// can log in to the server using the identity provider. The provider settings
+	// are found using the discovery document at the issuer URL.
+	ServerOIDCIssuerSetting = ServerKeyPrefix + "oidc.issuer"
+
+	// The client ID this server is registered with at the identity provider.
+	ServerOIDCClientSetting = ServerKeyPrefix + "oidc.client"
+
+	// The client secret this server is registered with at the identity provider.
+	// This is not needed if the provider accepts public clients using PKCE.
+	ServerOIDCSecretSetting = ServerKeyPrefix + "oidc.secret"
+
+	// The URL the identity provider redirects to after a login. If not specified,
+	// the callback endpoint of the server handling the login request is used.
+	ServerOIDCRedirectSetting = ServerKeyPrefix + "oidc.redirect"
+
+	// A space-separated list of the scopes requested from the identity provider.
+	// The default is "openid profile email".
+	ServerOIDCScopesSetting = ServerKeyPrefix + "oidc.scopes"
+
+	// The name of the identity token claim that contains the Ego user name. The
+	// default is "sub", the subject assigned by the identity provider.
+	ServerOIDCUserClaimSetting = ServerKeyPrefix + "oidc.user.claim"
+
+	// The name of the identity token claim that contains the list of groups or
+	// roles the user has at the identity provider. The default is "groups".
+	ServerOIDCGroupsClaimSetting = ServerKeyPrefix + "oidc.groups.claim"
+
+	// The mapping of identity provider groups to Ego permissions. This is a list
+	// of "group=permission,permission" entries, separated by semicolons. Users
+	// that log in with the identity provider are always given "logon".
+	ServerOIDCPermissionsSetting = ServerKeyPrefix + "oidc.permissions"
+
+	// The distinguished name used to search the LDAP directory for users when
+	// the user data is an "ldap://" or "ldaps://" URL. If not specified, the
+	// search uses an anonymous bind.
+	ServerLDAPBindDNSetting = ServerKeyPrefix + "ldap.bind.dn"
+
+	// The password for the distinguished name used to search the LDAP directory.
+	ServerLDAPBindPasswordSetting = ServerKeyPrefix + "ldap.bind.password"
+
+	// The LDAP attribute that contains the user name. The default is "uid".
+	ServerLDAPUserAttributeSetting = ServerKeyPrefix + "ldap.user.attribute"
+
+	// The LDAP attribute of a user that lists the groups the user is a member
+	// of. The default is "memberOf".
+	ServerLDAPGroupAttributeSetting = ServerKeyPrefix + "ldap.group.attribute"
+
+	// The mapping of LDAP groups to Ego permissions. This is a list of entries
+	// of the form "group=permission,permission", separated by semicolons. The
+	// group is the common name of the group, and "*" applies to all users.
+	ServerLDAPPermissionsSetting = ServerKeyPrefix + "ldap.permissions"
+
+	// How long a user read from the LDAP directory is cached, such as "10m".
+	// The default is "5m".
+	ServerLDAPCacheSetting = ServerKeyPrefix + "ldap.cache"
+
+	// The path of the file that stores the local users, roles, groups, sessions
+	// and API keys when the users are in an LDAP directory. If not specified,
+	// these are only kept in memory.
+	ServerLDAPStoreSetting = ServerKeyPrefix + "ldap.store"
+
+	// If "false", the certificate of an "ldaps://" directory server is not
+	// verified. The default is to verify it.
+	ServerLDAPTLSVerifySetting = ServerKeyPrefix + "ldap.tls.verify"
+
+	// The path of a PEM file with the certificate authorities trusted to sign
+	// the certificate of an "ldaps://" directory server. If not specified, the
+	// system certificate authorities are used.
+	ServerLDAPTLSCASetting = ServerKeyPrefix + "ldap.tls.ca"
+
 	// If true, when REST logging is enabled, the server log itself will be
 	// logged as a respomse payload to the /log service request. This is
 	// normally off and should only be enable when debugging logging.
@@ -299,6 +374,14 @@ const (
 	// If true, the insert of a row _must_ specify all values in the table.
 	TableServerPartialInsertError = ServerDatabaseKeyPrefix + "partial.insert.error"
 
+	// The default number of rows inserted by a bulk import before the
+	// transaction is committed. If not specified, 1000 rows are used.
+	TablesServerImportBatchSetting = ServerDatabaseKeyPrefix + "import.batch"
+
+	// A comma-separated list of URLs that are sent each batch of changes made to
+	// tables that have a change log.
+	TablesServerWebhooksSetting = ServerDatabaseKeyPrefix + "changes.webhooks"
+
 	// The key string used to encrypt authentication tokens.
 	ServerTokenKeySetting = ServerKeyPrefix + "token.key"
 
@@ -306,6 +389,61 @@ const (
 	// expired. Examples are "15m" or "24h".
 	ServerTokenExpirationSetting = ServerKeyPrefix + "token.expiration"
 
+	// The format of the tokens issued by the server. The default is "ego", an
+	// encrypted token only an Ego server can read. If set to "jwt", the server
+	// issues signed JSON Web Tokens that other services can validate.
+	ServerTokenFormatSetting = ServerKeyPrefix + "token.format"
+
+	// The algorithm used to sign JWT tokens, which is one of "HS256", "RS256"
+	// or "EdDSA". The default is "HS256".
+	ServerTokenAlgorithmSetting = ServerKeyPrefix + "token.alg"
+
+	// The issuer ("iss" claim) of JWT tokens issued by the server. The default
+	// is "ego".
+	ServerTokenIssuerSetting = ServerKeyPrefix + "token.issuer"
+
+	// How often a new key is created to sign JWT tokens, such as "720h". Older
+	// keys are kept until the tokens they signed have expired. If not given,
+	// the key is only replaced when the signing algorithm changes.
+	ServerTokenRotationSetting = ServerKeyPrefix + "token.rotation"
+
+	// How long a refresh token issued with a token can be used to get a new
+	// token, such as "720h". If not given, refresh tokens are not issued.
+	ServerTokenRefreshSetting = ServerKeyPrefix + "token.refresh"
+
+	// The encrypted set of keys used to sign JWT tokens. This is maintained by
+	// the server.
+	ServerTokenKeysSetting = ServerKeyPrefix + "token.keys"
+
+	// The minimum length of a password set for a user. The default is zero,
+	// which means there is no minimum length.
+	ServerPasswordLengthSetting = ServerKeyPrefix + "password.length"
+
+	// The number of character classes (lowercase, uppercase, digits, and other
+	// characters) that a password must contain. The default is zero.
+	ServerPasswordClassesSetting = ServerKeyPrefix + "password.classes"
+
+	// The number of previous passwords for a user that cannot be used again
+	// as the new password. The default is zero.
+	ServerPasswordHistorySetting = ServerKeyPrefix + "password.history"
+
+	// The number of consecutive failed logons after which a user account is
+	// locked. The default is zero, which means accounts are never locked.
+	ServerLockoutCountSetting = ServerKeyPrefix + "lockout.count"
+
+	// How long a user account stays locked after too many failed logons, such
+	// as "30m". The default is "15m".
+	ServerLockoutDurationSetting = ServerKeyPrefix + "lockout.duration"
+
+	// The path of the audit log file of security-relevant actions. The default
+	// is "ego-server-audit.json" in the same directory as the server log.
+	ServerAuditFileSetting = ServerKeyPrefix + "audit.file"
+
+	// The name of the user that scheduled jobs run as. The secrets a job can
+	// read are those this user can read. If not given, jobs do not run as any
+	// user.
+	ServerJobsUserSetting = ServerKeyPrefix + "jobs.user"
+
 	// A string indicating the default logging to be assigned to a server
 	// that is started without an explicit --log setting.
 	ServerDefaultLogSetting = ServerKeyPrefix + "default.logging"
@@ -341,6 +479,7 @@ var ValidSettings map[string]bool = map[string]bool{
 	LogonServerSetting:              true,
 	LogonTokenSetting:               false,
 	LogonTokenExpirationSetting:     false,
+	LogonRefreshSetting:             false,
 	DefaultCredentialSetting:        true,
 	LogonSuperuserSetting:           true,
 	LogonUserdataSetting:            true,
@@ -351,6 +490,36 @@ var ValidSettings map[string]bool = map[string]bool{
 	TablesServerDatabaseSSLMode:     true,
 	ServerTokenKeySetting:           true,
 	ServerTokenExpirationSetting:    true,
+	ServerTokenFormatSetting:        true,
+	ServerTokenAlgorithmSetting:     true,
+	ServerTokenIssuerSetting:        true,
+	ServerTokenRotationSetting:      true,
+	ServerTokenRefreshSetting:       true,
+	ServerTokenKeysSetting:          false,
+	ServerPasswordLengthSetting:     true,
+	ServerPasswordClassesSetting:    true,
+	ServerPasswordHistorySetting:    true,
+	ServerLockoutCountSetting:       true,
+	ServerLockoutDurationSetting:    true,
+	ServerAuditFileSetting:          true,
+	ServerJobsUserSetting:           true,
+	ServerOIDCIssuerSetting:         true,
+	ServerOIDCClientSetting:         true,
+	ServerOIDCSecretSetting:         true,
+	ServerOIDCRedirectSetting:       true,
+	ServerOIDCScopesSetting:         true,
+	ServerOIDCUserClaimSetting:      true,
+	ServerOIDCGroupsClaimSetting:    true,
+	ServerOIDCPermissionsSetting:    true,
+	ServerLDAPBindDNSetting:         true,
+	ServerLDAPBindPasswordSetting:   true,
+	ServerLDAPUserAttributeSetting:  true,
+	ServerLDAPGroupAttributeSetting: true,
+	ServerLDAPPermissionsSetting:    true,
+	ServerLDAPCacheSetting:          true,
+	ServerLDAPStoreSetting:          true,
+	ServerLDAPTLSVerifySetting:      true,
+	ServerLDAPTLSCASetting:          true,
 	ThrowUncheckedErrorsSetting:     true,
 	FullStackTraceSetting:           true,
 	LogTimestampFormat:              true,
@@ -367,6 +536,8 @@ var ValidSettings map[string]bool = map[string]bool{
 	TablesServerEmptyRowsetError:    true,
 	ServerDefaultLogSetting:         true,
 	TableServerPartialInsertError:   true,
+	TablesServerImportBatchSetting:  true,
+	TablesServerWebhooksSetting:     true,
 	SymbolTableAllocationSetting:    true,
 	ExecPermittedSetting:            true,
 	OptimizerSetting:                true,
@@ -391,7 +562,11 @@ var ValidSettings map[string]bool = map[string]bool{
 // category, only those that contains keys or other secure information.
 var RestrictedSettings map[string]bool = map[string]bool{
 	ServerTokenKeySetting:           true,
+	ServerTokenKeysSetting:          true,
+	ServerOIDCSecretSetting:         true,
+	ServerLDAPBindPasswordSetting:   true,
 	LogonTokenSetting:               true,
+	LogonRefreshSetting:             true,
 	LogonUserdataKeySetting:         true,
 	TablesServerDatabaseCredentials: true,
 	TablesServerDatabase:            true,
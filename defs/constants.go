@@ -103,5 +103,6 @@ const (
 const (
 	TimeDurationTypeName = "time.Duration"
 	TimeMonthTypeName    = "time.Month"
+	TimeWeekdayTypeName  = "time.Weekday"
 	TimeLocationTypeName = "time.Location"
 )
@@ -104,6 +104,62 @@ type DBColumn struct {
 
 	// True if the value in this column must be unique.
 	Unique BoolValue `json:"unique"`
+
+	// The default value for this column, expressed as a SQL literal or one of
+	// the CURRENT_DATE, CURRENT_TIME, or CURRENT_TIMESTAMP keywords. If empty,
+	// the column has no default value.
+	Default string `json:"default,omitempty"`
+}
+
+// DBIndex describes a secondary index on a table. An index can span more
+// than one column, in which case the order of the columns is significant.
+type DBIndex struct {
+	// The name of the index.
+	Name string `json:"name"`
+
+	// The names of the columns in the index, in index order.
+	Columns []string `json:"columns"`
+
+	// True if the index enforces uniqueness of the combined column values.
+	Unique bool `json:"unique,omitempty"`
+}
+
+// DBForeignKey describes a foreign key constraint, where one or more columns
+// in the table must match the values of columns in another table.
+type DBForeignKey struct {
+	// The name of the constraint. This is optional when creating a table.
+	Name string `json:"name,omitempty"`
+
+	// The names of the columns in this table that make up the key.
+	Columns []string `json:"columns"`
+
+	// The name of the referenced table.
+	Table string `json:"table"`
+
+	// The names of the columns in the referenced table, in the same order
+	// as the Columns array.
+	References []string `json:"references"`
+
+	// The action taken when a referenced row is deleted. This is one of
+	// "no action", "restrict", "cascade", "set null", or "set default".
+	OnDelete string `json:"onDelete,omitempty"`
+}
+
+// DBTableDefinition is the payload used to create a table when more than
+// the column definitions are needed. The table create endpoint also accepts
+// a simple array of DBColumn objects.
+type DBTableDefinition struct {
+	// An array of column descriptors, one for each column in the table.
+	Columns []DBColumn `json:"columns"`
+
+	// The names of the columns that make up the primary key, if any.
+	PrimaryKey []string `json:"primaryKey,omitempty"`
+
+	// The secondary indexes to create for the table.
+	Indexes []DBIndex `json:"indexes,omitempty"`
+
+	// The foreign key constraints for the table.
+	ForeignKeys []DBForeignKey `json:"foreignKeys,omitempty"`
 }
 
 type DBRowSet struct {
@@ -166,6 +222,15 @@ type TableColumnsInfo struct {
 	// An array of column descriptors, one for each column in the table.
 	Columns []DBColumn `json:"columns"`
 
+	// The names of the columns that make up the primary key, if any.
+	PrimaryKey []string `json:"primaryKey,omitempty"`
+
+	// The secondary indexes defined for the table.
+	Indexes []DBIndex `json:"indexes,omitempty"`
+
+	// The foreign key constraints defined for the table.
+	ForeignKeys []DBForeignKey `json:"foreignKeys,omitempty"`
+
 	// The number of columns in the Columns array.
 	Count int `json:"count"`
 
@@ -190,6 +255,127 @@ type DBRowCount struct {
 	Message string `json:"msg"`
 }
 
+// DBImportError describes a single row of a bulk import that could not be
+// stored in the table.
+type DBImportError struct {
+	// The row number in the import data, where the first data row is 1.
+	Row int `json:"row"`
+
+	// The text of the error for this row.
+	Message string `json:"msg"`
+}
+
+// DBImportResponse is the response to a bulk import of rows into a table.
+type DBImportResponse struct {
+	// The description of the server and request.
+	ServerInfo `json:"server"`
+
+	// The number of rows stored in the table.
+	Count int `json:"count"`
+
+	// The number of transactions committed to store the rows.
+	Batches int `json:"batches"`
+
+	// The rows that could not be stored, if any.
+	Errors []DBImportError `json:"errors,omitempty"`
+
+	// Copy of the HTTP status value
+	Status int `json:"status"`
+
+	// Any error message text
+	Message string `json:"msg"`
+}
+
+// DBPolicy describes a row-level security policy for a table. The filter is an
+// expression in the same form as the filter parameter of a rows request, and is
+// added to the filter of every read, update or delete of the table by a user who
+// is not an administrator.
+type DBPolicy struct {
+	// The name of the policy, which is unique for the table.
+	Name string `json:"name"`
+
+	// The operations ("read", "update" or "delete") the policy applies to. If
+	// empty, the policy applies to all of them.
+	Operations []string `json:"operations,omitempty"`
+
+	// The filter expression. This can contain {{user}}, {{id}} and {{permissions}}
+	// to refer to the name, UUID and permissions list of the user making the request.
+	Filter string `json:"filter"`
+}
+
+// DBPolicyList is the list of row-level security policies for a table.
+type DBPolicyList struct {
+	// The description of the server and request.
+	ServerInfo `json:"server"`
+
+	// The table the policies apply to.
+	Table string `json:"table"`
+
+	// The policies defined for the table.
+	Policies []DBPolicy `json:"policies"`
+
+	// The number of policies in the list.
+	Count int `json:"count"`
+
+	// Copy of the HTTP status value
+	Status int `json:"status"`
+
+	// Any error message text
+	Message string `json:"msg,omitempty"`
+}
+
+// DBChange describes a single change made to a table that has a change log. The
+// sequence number increases for each change recorded on the server, so it can be
+// used to read the changes made after a given change.
+type DBChange struct {
+	// The sequence number of the change.
+	Sequence int64 `json:"seq"`
+
+	// The table that was changed, including the schema name.
+	Table string `json:"table"`
+
+	// The operation ("insert", "update" or "delete") that changed the table.
+	Operation string `json:"operation"`
+
+	// The user that made the change.
+	User string `json:"user"`
+
+	// The values of the row before the change. This is omitted for an insert.
+	Before map[string]interface{} `json:"before,omitempty"`
+
+	// The values of the row after the change. This is omitted for a delete.
+	After map[string]interface{} `json:"after,omitempty"`
+
+	// The time the change was made, in RFC3339 format.
+	Timestamp string `json:"timestamp"`
+}
+
+// DBChangeSet is a list of changes made to a table, or to all tables when sent to a
+// webhook.
+type DBChangeSet struct {
+	// The description of the server and request.
+	ServerInfo `json:"server"`
+
+	// The table the changes apply to. This is empty when sent to a webhook.
+	Table string `json:"table,omitempty"`
+
+	// The changes, in the order they were made.
+	Changes []DBChange `json:"changes"`
+
+	// The number of changes in the list.
+	Count int `json:"count"`
+
+	// The sequence number to use as the "since" parameter to read the next
+	// changes.
+	Next int64 `json:"next"`
+
+	// Copy of the HTTP status value
+	Status int `json:"status"`
+
+	// Any error message text
+	Message string `json:"msg,omitempty"`
+}
+
 type Credentials struct {
 	// The username as a plain-text string
 	Username string `json:"username"`
@@ -202,6 +388,14 @@ type Credentials struct {
 	// this may or may not be honored by the server; the reply
 	// will indicate the actual expiration.
 	Expiration string `json:"expiration,omitempty"`
+
+	// A refresh token from an earlier logon, used instead of the username
+	// and password to get a new token.
+	Refresh string `json:"refresh,omitempty"`
+
+	// The one-time code for a user enrolled for a second logon factor, or
+	// one of the user's recovery codes.
+	Code string `json:"code,omitempty"`
 }
 
 type PermissionObject struct {
@@ -366,6 +560,278 @@ type User struct {
 	Permissions []string `json:"permissions,omitempty"`
 }
 
+// Role describes a named set of permissions in the user database. A role can
+// inherit the permissions of other roles.
+type Role struct {
+	// The name of the role.
+	Name string `json:"name"`
+
+	// A UUID for this specific role instance.
+	ID uuid.UUID `json:"id,omitempty"`
+
+	// A description of the role.
+	Description string `json:"description,omitempty"`
+
+	// A string array of the names of the permissions granted by this role.
+	Permissions []string `json:"permissions,omitempty"`
+
+	// A string array of the names of the roles whose permissions are also
+	// granted by this role.
+	Inherits []string `json:"inherits,omitempty"`
+}
+
+// Group describes a named group of users in the user database. Each member
+// of the group is granted the permissions of the roles assigned to the group.
+type Group struct {
+	// The name of the group.
+	Name string `json:"name"`
+
+	// A UUID for this specific group instance.
+	ID uuid.UUID `json:"id,omitempty"`
+
+	// A description of the group.
+	Description string `json:"description,omitempty"`
+
+	// A string array of the names of the users who are members of the group.
+	Members []string `json:"members,omitempty"`
+
+	// A string array of the names of the roles assigned to the group.
+	Roles []string `json:"roles,omitempty"`
+}
+
+// LogonSession describes a token issued by the server to a user. The session
+// is identified by the ID of the token, and is used to revoke the token before
+// it expires. The times are stored as RFC 3339 strings.
+type LogonSession struct {
+	// The unique ID of the token.
+	ID uuid.UUID `json:"id"`
+
+	// The name of the user the token was issued to.
+	Name string `json:"name"`
+
+	// The ID of the first session in a chain of sessions created using
+	// refresh tokens. All sessions in the chain share the family ID.
+	Family uuid.UUID `json:"family"`
+
+	// The network address of the client that requested the token.
+	Address string `json:"address,omitempty"`
+
+	// The time the token was issued.
+	Issued string `json:"issued"`
+
+	// The time the token expires.
+	Expires string `json:"expires"`
+
+	// The hash of the refresh token issued with the token, if any. This is
+	// never returned to a client.
+	Refresh string `json:"refresh,omitempty"`
+
+	// The time the refresh token expires.
+	RefreshExpires string `json:"refreshExpires,omitempty"`
+
+	// True if the refresh token has already been used to get a new token.
+	Refreshed bool `json:"refreshed,omitempty"`
+
+	// True if the token has been revoked.
+	Revoked bool `json:"revoked,omitempty"`
+}
+
+// APIKey describes a long-lived key used by a program to access the server on
+// behalf of a user. The key can only use the permissions listed, which must be
+// granted to the user. The key itself is never stored; only its hash is kept.
+// The times are stored as RFC 3339 strings.
+type APIKey struct {
+	// The unique ID of the key, which is also part of the key text.
+	ID uuid.UUID `json:"id"`
+
+	// The name of the user the key acts for.
+	User string `json:"user"`
+
+	// A description of what the key is used for.
+	Description string `json:"description,omitempty"`
+
+	// The hash of the secret part of the key. This is never returned to a
+	// client.
+	Hash string `json:"hash,omitempty"`
+
+	// The permissions the key can use.
+	Permissions []string `json:"permissions,omitempty"`
+
+	// The time the key was created.
+	Created string `json:"created,omitempty"`
+
+	// The time the key expires. If empty, the key does not expire. When a key
+	// is created, this can also be a duration such as "720h".
+	Expires string `json:"expires,omitempty"`
+
+	// The network addresses or CIDR ranges the key can be used from. If empty,
+	// the key can be used from any address.
+	Allow []string `json:"allow,omitempty"`
+}
+
+// Secret is a named value, such as a password or API key, stored by the server
+// for use by services. The value is encrypted using the server token key, and is
+// never returned to a client. A secret can only be read by the users listed, or
+// by users that hold one of the roles listed. The time is stored as an RFC 3339
+// string.
+type Secret struct {
+	// The name of the secret.
+	Name string `json:"name"`
+
+	// The encrypted value of the secret. When a secret is set, this is the
+	// plain text of the value.
+	Value string `json:"value,omitempty"`
+
+	// A description of what the secret is used for.
+	Description string `json:"description,omitempty"`
+
+	// The names of the users that can read the secret.
+	Users []string `json:"users,omitempty"`
+
+	// The names of the roles whose users can read the secret.
+	Roles []string `json:"roles,omitempty"`
+
+	// The time the secret was last set.
+	Modified string `json:"modified,omitempty"`
+}
+
+// Job is a program in the lib/jobs directory that the server runs on a schedule
+// given by its @schedule directive. The times are stored as RFC 3339 strings.
+type Job struct {
+	// The name of the job, which is the name of the program file without the
+	// ".ego" extension.
+	Name string `json:"name"`
+
+	// The cron-style schedule for the job, such as "0 2 * * *".
+	Schedule string `json:"schedule"`
+
+	// True if the job is not run on its schedule. A paused job can still be
+	// run on request.
+	Paused bool `json:"paused"`
+
+	// True if the job is running now.
+	Running bool `json:"running"`
+
+	// The next time the job is scheduled to run.
+	Next string `json:"next,omitempty"`
+
+	// The most recent run of the job, if any.
+	Last *JobRun `json:"last,omitempty"`
+
+	// The most recent runs of the job, newest first. This is only returned
+	// when a single job is read.
+	History []JobRun `json:"history,omitempty"`
+}
+
+// JobRun describes a single run of a job. The status is "success", "failed", or
+// "skipped" if the job was still running from a previous start.
+type JobRun struct {
+	// The time the run started.
+	Start string `json:"start"`
+
+	// How long the run took, such as "1.5s".
+	Duration string `json:"duration,omitempty"`
+
+	// The status of the run.
+	Status string `json:"status"`
+
+	// What started the run. This is "schedule", or the name of the user that
+	// requested the run.
+	Trigger string `json:"trigger"`
+
+	// The error that ended the run, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// The values of the status of a job run.
+const (
+	JobSuccess = "success"
+	JobFailed  = "failed"
+	JobSkipped = "skipped"
+)
+
+// AuditEntry is a single entry in the audit log of security-relevant actions. The
+// hash of each entry includes the hash of the previous entry, so an entry that is
+// changed or removed can be detected. The time is stored as an RFC 3339 string.
+type AuditEntry struct {
+	// The time the action occurred.
+	Time string `json:"time"`
+
+	// The sequence number of the entry, starting at one.
+	Sequence int `json:"seq"`
+
+	// The kind of action, such as "logon.success" or "user.update".
+	Event string `json:"event"`
+
+	// The server session number of the request, if any.
+	Session int `json:"session,omitempty"`
+
+	// The name of the user performing the action, or logging on.
+	User string `json:"user,omitempty"`
+
+	// Additional information that describes the action.
+	Args map[string]interface{} `json:"args,omitempty"`
+
+	// The hash of the previous entry in the log.
+	Previous string `json:"prev"`
+
+	// The hash of this entry, including the hash of the previous entry.
+	Hash string `json:"hash"`
+}
+
+// PasswordState describes the state of a user's password used to enforce the
+// password policies. It records the failed logons used to lock the account,
+// and the hashes of the previous passwords. The time is an RFC 3339 string.
+type PasswordState struct {
+	// The name of the user.
+	Name string `json:"name"`
+
+	// The number of consecutive failed logons for the user.
+	Failures int `json:"failures,omitempty"`
+
+	// The time until which the account is locked, if any.
+	LockedUntil string `json:"lockedUntil,omitempty"`
+
+	// The hashes of the previous passwords for the user, most recent first.
+	History []string `json:"history,omitempty"`
+
+	// The base32 secret used to generate time-based one-time codes, if the user
+	// is enrolled for a second logon factor.
+	TOTP string `json:"totp,omitempty"`
+
+	// The time step of the last one-time code used, so a code cannot be used
+	// twice.
+	TOTPStep int `json:"totpStep,omitempty"`
+
+	// The hashes of the unused recovery codes, each of which can be used once
+	// instead of a one-time code.
+	Recovery []string `json:"recovery,omitempty"`
+
+	// The permissions mapped from the identity provider groups of the user when
+	// the user last logged in with the provider. They are kept apart from the
+	// permissions granted to the user.
+	Provider []string `json:"provider,omitempty"`
+}
+
+// TOTPEnrollment is the response when a user is enrolled for time-based one-time
+// codes. The recovery codes are only returned when the user is enrolled.
+type TOTPEnrollment struct {
+	ServerInfo `json:"server"`
+
+	// The name of the user.
+	Name string `json:"name"`
+
+	// The provisioning URI, which is usually displayed as a QR code to be read
+	// by an authenticator app.
+	URI string `json:"uri,omitempty"`
+
+	// The recovery codes for the user.
+	Recovery []string `json:"recovery,omitempty"`
+
+	// The HTTP status of the response.
+	Status int `json:"status"`
+}
+
 // BaseCollection is a component of any collection type returned
 // as a response.
 type BaseCollection struct {
@@ -409,6 +875,111 @@ type UserResponse struct {
 	Message string `json:"msg"`
 }
 
+// RoleCollection is a collection of Role response objects.
+type RoleCollection struct {
+	BaseCollection
+
+	// Array of each role's information.
+	Items []Role `json:"items"`
+}
+
+// RoleResponse is the representation of a single role returned from
+// the server.
+type RoleResponse struct {
+	ServerInfo `json:"server"`
+	Role
+
+	// Copy of the HTTP status value
+	Status int `json:"status"`
+
+	// Any error message text
+	Message string `json:"msg"`
+}
+
+// GroupCollection is a collection of Group response objects.
+type GroupCollection struct {
+	BaseCollection
+
+	// Array of each group's information.
+	Items []Group `json:"items"`
+}
+
+// GroupResponse is the representation of a single group returned from
+// the server.
+type GroupResponse struct {
+	ServerInfo `json:"server"`
+	Group
+
+	// Copy of the HTTP status value
+	Status int `json:"status"`
+
+	// Any error message text
+	Message string `json:"msg"`
+}
+
+// APIKeyCollection is a collection of APIKey response objects.
+type APIKeyCollection struct {
+	BaseCollection
+
+	// Array of each API key's information.
+	Items []APIKey `json:"items"`
+}
+
+// SecretCollection is a collection of Secret response objects. The values of
+// the secrets are never included.
+type SecretCollection struct {
+	BaseCollection
+
+	// Array of each secret's information.
+	Items []Secret `json:"items"`
+}
+
+// JobCollection is a collection of Job response objects.
+type JobCollection struct {
+	BaseCollection
+
+	// Array of each job's information.
+	Items []Job `json:"items"`
+}
+
+// APIKeyResponse is the response when an API key is created. This is the only
+// time the text of the key is returned.
+type APIKeyResponse struct {
+	ServerInfo `json:"server"`
+
+	// The text of the key, used in the X-API-Key header of a request.
+	Key string `json:"key"`
+
+	// The information about the key.
+	Item APIKey `json:"item"`
+
+	// The HTTP status of the response.
+	Status int `json:"status"`
+}
+
+// SessionCollection is a collection of LogonSession response objects.
+type SessionCollection struct {
+	BaseCollection
+
+	// Array of each session's information.
+	Items []LogonSession `json:"items"`
+}
+
+// AuditCollection is a collection of audit log entries. It also reports if the hash
+// chain of the entire audit log is intact.
+type AuditCollection struct {
+	BaseCollection
+
+	// True if the hash of every entry in the audit log is valid.
+	Verified bool `json:"verified"`
+
+	// The sequence number of the first entry whose hash is not valid, if any.
+	Broken int `json:"broken,omitempty"`
+
+	// Array of each entry that matches the query.
+	Items []AuditEntry `json:"items"`
+}
+
 // ServerStatus describes the state of a running server. A json version
 // of this information is the contents of the pid file.
 type ServerStatus struct {
@@ -442,6 +1013,10 @@ type LogonResponse struct {
 
 	// The username associated with the token.
 	Identity string `json:"identity"`
+
+	// The refresh token that can be used to get a new token, if the server
+	// issues refresh tokens.
+	Refresh string `json:"refresh,omitempty"`
 }
 
 type DSNResponse struct {
@@ -11,6 +11,12 @@ const (
 	LimitParameterName     = "limit"
 	RowCountParameterName  = "rowcounts"
 	AbstractParameterName  = "abstract"
+	BatchParameterName     = "batch"
+	MapParameterName       = "map"
+	MaxErrorsParameterName = "maxerrors"
+	NameParameterName      = "name"
+	SinceParameterName     = "since"
+	WaitParameterName      = "wait"
 	PermissionsPseudoTable = "@permissions"
 	SQLPseudoTable         = "@sql"
 )
@@ -22,6 +28,20 @@ const (
 	AdminUsersPath            = "/admin/users/"
 	AdminMemoryPath           = "/admin/memory"
 	AdminUsersNamePath        = AdminUsersPath + "%s"
+	AdminUsersTOTPPath        = AdminUsersPath + "%s/totp"
+	AdminRolesPath            = "/admin/roles/"
+	AdminRolesNamePath        = AdminRolesPath + "%s"
+	AdminGroupsPath           = "/admin/groups/"
+	AdminGroupsNamePath       = AdminGroupsPath + "%s"
+	AdminSessionsPath         = "/admin/sessions/"
+	AdminSessionsIDPath       = AdminSessionsPath + "%s"
+	AdminAPIKeysPath          = "/admin/apikeys/"
+	AdminAPIKeysIDPath        = AdminAPIKeysPath + "%s"
+	AdminAuditPath            = "/admin/audit/"
+	AdminSecretsPath          = "/admin/secrets/"
+	AdminSecretsNamePath      = AdminSecretsPath + "%s"
+	AdminJobsPath             = "/admin/jobs/"
+	AdminJobsNamePath         = AdminJobsPath + "%s"
 	AssetsPath                = "/assets/"
 	DSNPath                   = "/dsns/"
 	DSNNamePath               = DSNPath + "{{dsn}}/"
@@ -34,6 +54,10 @@ const (
 	ServicesLogonPath         = ServicesPath + "admin/logon/"
 	ServicesLogLinesPath      = ServicesPath + "admin/log"
 	ServicesAuthenticatePath  = ServicesPath + "admin/authenticate"
+	ServicesOIDCLoginPath     = ServicesPath + "admin/oidc/login"
+	ServicesOIDCCallbackPath  = ServicesPath + "admin/oidc/callback"
+	ServicesRefreshPath       = ServicesPath + "admin/refresh"
+	JWKSPath                  = "/.well-known/jwks.json"
 	ServicesUpPath            = ServicesPath + "up/"
 	TablesPath                = "/tables/"
 	TablesNamePath            = TablesPath + "%s"
@@ -41,6 +65,8 @@ const (
 	TablesSQLPath             = TablesPath + SQLPseudoTable
 	TablesPermissionsPath     = TablesPath + PermissionsPseudoTable
 	TablesNamePermissionsPath = TablesPath + "{{table}}/permissions"
+	TablesNamePoliciesPath    = TablesPath + "{{table}}/policies"
+	TablesNameChangesPath     = TablesPath + "{{table}}/changes"
 )
 
 var TableColumnTypeNames []string = []string{
@@ -59,9 +85,11 @@ var TableColumnTypeNames []string = []string{
 }
 
 const (
-	TextMediaType = "application/text"
-	JSONMediaType = "application/json"
-	HTMLMediaType = "application/html"
+	TextMediaType   = "application/text"
+	JSONMediaType   = "application/json"
+	HTMLMediaType   = "application/html"
+	CSVMediaType    = "text/csv"
+	NDJSONMediaType = "application/x-ndjson"
 
 	EgoMediaType            = "application/vnd.ego."
 	SQLStatementsMediaType  = EgoMediaType + "sql+json"
@@ -77,10 +105,26 @@ const (
 	DSNListPermsMediaType   = EgoMediaType + "dsn.permissions.list+json"
 	DSNListMediaType        = EgoMediaType + "dsns+json"
 	UsersMediaType          = EgoMediaType + "users+json"
+	RoleMediaType           = EgoMediaType + "role+json"
+	RolesMediaType          = EgoMediaType + "roles+json"
+	GroupMediaType          = EgoMediaType + "group+json"
+	GroupsMediaType         = EgoMediaType + "groups+json"
+	SessionsMediaType       = EgoMediaType + "sessions+json"
+	TOTPMediaType           = EgoMediaType + "totp+json"
+	APIKeyMediaType         = EgoMediaType + "apikey+json"
+	APIKeysMediaType        = EgoMediaType + "apikeys+json"
+	AuditMediaType          = EgoMediaType + "audit+json"
+	SecretMediaType         = EgoMediaType + "secret+json"
+	SecretsMediaType        = EgoMediaType + "secrets+json"
+	JobMediaType            = EgoMediaType + "job+json"
+	JobsMediaType           = EgoMediaType + "jobs+json"
 	LogStatusMediaType      = EgoMediaType + "log.status+json"
 	LogLinesMediaType       = EgoMediaType + "log.lines+json"
 	CacheMediaType          = EgoMediaType + "cache+json"
 	MemoryMediaType         = EgoMediaType + "memory+json"
+	ImportMediaType         = EgoMediaType + "import+json"
+	PoliciesMediaType       = EgoMediaType + "policies+json"
+	ChangesMediaType        = EgoMediaType + "changes+json"
 )
 
 const (
@@ -94,6 +138,8 @@ const (
 	ContentTypeHeader       = "Content-Type"
 	AuthenticateHeader      = "Www-Authenticate"
 	EgoServerInstanceHeader = "X-Ego-Server"
+	OneTimeCodeHeader       = "X-Ego-Code"
+	APIKeyHeader            = "X-API-Key"
 )
 
 // InstanceID is the UUID of the current Server Instance.
@@ -89,6 +89,11 @@ const (
 	// This contains the name of the superuser, if one is defined, in an Ego service.
 	SuperUserVariable = ReadonlyVariablePrefix + "superuser"
 
+	// This contains the permissions an Ego service can use on behalf of the user, when
+	// the request was authenticated with an API key. The permissions are limited to
+	// the scope of the key.
+	PermissionsVariable = InvisiblePrefix + "permissions"
+
 	// This contains the password provided as part of Basic REST authentication, in
 	// an Ego service.
 	PasswordVariable = ReadonlyVariablePrefix + "password"
@@ -97,6 +102,10 @@ const (
 	// in an Ego service.
 	TokenVariable = ReadonlyVariablePrefix + "token"
 
+	// This contains the context of the REST request, in an Ego service. The context
+	// is cancelled when the client disconnects.
+	RequestContextVariable = ReadonlyVariablePrefix + "context"
+
 	// This indicates if the bearer tokenw as valid an unexpired, in an Ego service.
 	TokenValidVariable = ReadonlyVariablePrefix + "token_valid"
 
@@ -27,8 +27,11 @@ var ErrCannotDeleteActiveProfile = Message("cannot.delete.profile")
 var ErrCertificateParseError = Message("cert.parse")
 var ErrChannelNotOpen = Message("channel.not.open")
 var ErrChildTimeout = Message("child.timeout")
+var ErrCodeRequired = Message("code.required")
 var ErrColumnCount = Message("column.count")
 var ErrConditionalBool = Message("conditional.bool")
+var ErrContextCanceled = Message("context.canceled")
+var ErrContextDeadline = Message("context.deadline")
 var ErrDatabaseClientClosed = Message("db.closed")
 var ErrDeferOutsideFunction = Message("defer.outside")
 var ErrDivisionByZero = Message("div.zero")
@@ -41,11 +44,13 @@ var ErrFunctionAlreadyExists = Message("func.exists")
 var ErrFunctionReturnedVoid = Message("func.void")
 var ErrGeneric = Message("general")
 var ErrHTTP = Message("http")
+var ErrHTTPServerRunning = Message("http.server.running")
 var ErrImmutableArray = Message("immutable.array")
 var ErrImmutableMap = Message("immutable.map")
 var ErrImportNotCached = Message("import.not.found")
 var ErrInitializerCount = Message("initializer.count")
 var ErrInternalCompiler = Message("compiler")
+var ErrInvalidAlgorithm = Message("algorithm")
 var ErrInvalidArgumnetList = Message("arg.list")
 var ErrInvalidAuthenticationType = Message("auth.type")
 var ErrInvalidAuto = Message("invalid.auto")
@@ -63,13 +68,16 @@ var ErrInvalidColumnName = Message("column.name")
 var ErrInvalidColumnNumber = Message("column.number")
 var ErrInvalidColumnWidth = Message("column.width")
 var ErrInvalidConfigName = Message("profile.name")
+var ErrInvalidContext = Message("context.invalid")
 var ErrInvalidConstant = Message("constant")
 var ErrInvalidCredentials = Message("credentials")
 var ErrInvalidDebugCommand = Message("debugger.cmd")
 var ErrInvalidDirective = Message("directive")
 var ErrInvalidDuration = Message("invalid.duration")
+var ErrInvalidEncoding = Message("encoding")
 var ErrInvalidEndPointString = Message("endpoint")
 var ErrInvalidField = Message("field.for.type")
+var ErrInvalidFieldCount = Message("db.field.count")
 var ErrInvalidFileMode = Message("file.mode")
 var ErrInvalidFilter = Message("invalid.filter")
 var ErrInvalidFloatValue = Message("float.value")
@@ -82,7 +90,10 @@ var ErrInvalidIdentifier = Message("identifier")
 var ErrInvalidImport = Message("import")
 var ErrInvalidInstruction = Message("instruction")
 var ErrInvalidInteger = Message("integer.value")
+var ErrInvalidJSONPath = Message("json.path")
+var ErrInvalidKey = Message("key.invalid")
 var ErrInvalidKeyword = Message("keyword.option")
+var ErrInvalidJWT = Message("jwt.invalid")
 var ErrInvalidLineNumber = Message("line.number")
 var ErrInvalidList = Message("list")
 var ErrInvalidLoggerName = Message("logger.name")
@@ -92,11 +103,14 @@ var ErrInvalidMediaType = Message("media.type")
 var ErrInvalidOperand = Message("operand")
 var ErrInvalidOutputFormat = Message("format.type")
 var ErrInvalidLogFormat = Message("log.format.type")
+var ErrInvalidPEM = Message("pem")
 var ErrInvalidPackageName = Message("package.name")
 var ErrInvalidPermission = Message("permission.name")
 var ErrInvalidPointerType = Message("pointer.type")
+var ErrInvalidPolicy = Message("db.policy")
 var ErrInvalidProfileAction = Message("profile.action")
 var ErrInvalidRange = Message("range")
+var ErrInvalidRefreshToken = Message("refresh.token")
 var ErrInvalidResultSetType = Message("db.result.type")
 var ErrInvalidReturnTypeList = Message("return.list")
 var ErrInvalidRune = Message("rune.value")
@@ -109,6 +123,7 @@ var ErrInvalidRomanRange = Message("roman.range")
 var ErrInvalidRowNumber = Message("row.number")
 var ErrInvalidRowSet = Message("db.rowset")
 var ErrInvalidSandboxPath = Message("sandbox.path")
+var ErrInvalidSchedule = Message("schedule.invalid")
 var ErrInvalidScopeLevel = Message("scope.invalid")
 var ErrInvalidSliceIndex = Message("slice.index")
 var ErrInvalidSpacing = Message("spacing")
@@ -131,6 +146,13 @@ var ErrInvalidValue = Message("value")
 var ErrInvalidVarType = Message("var.type")
 var ErrInvalidVariableArguments = Message("var.args")
 var ErrInvalidfileIdentifier = Message("file.id")
+var ErrJobExit = Message("job.exit")
+var ErrJobFailed = Message("job.failed")
+var ErrJWTAlgorithm = Message("jwt.algorithm")
+var ErrJWTClaim = Message("jwt.claim")
+var ErrJWTKey = Message("jwt.key")
+var ErrJWTSignature = Message("jwt.signature")
+var ErrLDAP = Message("ldap.error")
 var ErrLoggerConflict = Message("logger.conflict")
 var ErrLogonEndpoint = Message("logon.endpoint")
 var ErrLoopBody = Message("for.body")
@@ -157,6 +179,7 @@ var ErrMissingOptionValue = Message("option.value")
 var ErrMissingOutputType = Message("format.type")
 var ErrMissingPackageName = Message("package.name")
 var ErrMissingPackageStatement = Message("package.stmt")
+var ErrMissingPolicyName = Message("db.policy.name")
 var ErrMissingParameterList = Message("function.list")
 var ErrMissingParenthesis = Message("parens")
 var ErrMissingPrintItems = Message("print.items")
@@ -176,11 +199,17 @@ var ErrNoLogonServer = Message("logon.server")
 var ErrNoMainPackage = Message("no.main.package")
 var ErrNoPermission = Message("no.permission")
 var ErrNoPrivilegeForOperation = Message("privilege")
+var ErrNoSuchAPIKey = Message("apikey.not.found")
 var ErrNoSuchAsset = Message("asset")
 var ErrNoSuchDebugService = Message("debug.service")
 var ErrNoSuchDSN = Message("dsn.not.found")
+var ErrNoSuchGroup = Message("group.not.found")
+var ErrNoSuchJob = Message("job.not.found")
 var ErrNoSuchProfile = Message("profile.not.found")
 var ErrNoSuchProfileKey = Message("profile.key")
+var ErrNoSuchRole = Message("role.not.found")
+var ErrNoSuchSecret = Message("secret.not.found")
+var ErrNoSuchSession = Message("session.not.found")
 var ErrNoSuchTXSymbol = Message("tx.not.found")
 var ErrNoSuchUser = Message("user.not.found")
 var ErrNoSymbolTable = Message("no.symbol.table")
@@ -193,9 +222,17 @@ var ErrNotFound = Message("not.found")
 var ErrNotJSONLog = Message("not.json.log")
 var ErrNotLocalServer = Message("server.not.local")
 var ErrNotValidJSONLog = Message("not.json.log.valid")
+var ErrOIDCLocalUser = Message("oidc.local.user")
+var ErrOIDCNotConfigured = Message("oidc.config")
+var ErrOIDCProvider = Message("oidc.provider")
+var ErrOIDCState = Message("oidc.state")
+var ErrOIDCUser = Message("oidc.user")
 var ErrOpcodeAlreadyDefined = Message("opcode.defined")
 var ErrPackageRedefinition = Message("package.exists")
 var ErrPanic = Message("panic")
+var ErrPasswordClasses = Message("password.classes")
+var ErrPasswordLength = Message("password.length")
+var ErrPasswordReused = Message("password.reused")
 var ErrReadOnly = Message("readonly")
 var ErrReadOnlyAddressable = Message("readonly.addressable")
 var ErrReadOnlyValue = Message("readonly.write")
@@ -203,6 +240,8 @@ var ErrRequiredNotFound = Message("option.required")
 var ErrReservedProfileSetting = Message("reserved.name")
 var ErrRestClientClosed = Message("rest.closed")
 var ErrReturnValueCount = Message("func.return.count")
+var ErrRoleCycle = Message("role.cycle")
+var ErrRoleInUse = Message("role.in.use")
 var ErrServerAlreadyRunning = Message("server.running")
 var ErrServerError = Message("server.error")
 var ErrSQLInjection = Message("sql.injection")
@@ -6,18 +6,22 @@ require (
 	github.com/DmitriyVTitov/size v1.5.0
 	github.com/brandenc40/romannumeral v1.1.5
 	github.com/chzyer/readline v1.5.1
-	github.com/google/uuid v1.3.0
+	github.com/go-asn1-ber/asn1-ber v1.5.5
+	github.com/go-ldap/ldap/v3 v3.4.6
+	github.com/google/uuid v1.3.1
 	github.com/lib/pq v1.10.7
 	github.com/mattn/go-sqlite3 v1.14.16
 	github.com/stretchr/testify v1.9.0
+	golang.org/x/crypto v0.31.0
 	golang.org/x/term v0.27.0
 	gopkg.in/resty.v1 v1.12.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/Azure/go-ntlmssp v0.0.0-20221128193559-754e69321358 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/net v0.33.0 // indirect
 	golang.org/x/sys v0.28.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
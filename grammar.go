@@ -642,6 +642,97 @@ var TableGrammar = []cli.Option{
 			},
 		},
 	},
+	{
+		LongName:      "import",
+		Aliases:       []string{"load"},
+		Description:   "ego.table.import",
+		OptionType:    cli.Subcommand,
+		Action:        commands.TableImport,
+		ExpectedParms: 2,
+		ParmDesc:      "parm.table.import",
+		Value: []cli.Option{
+			{
+				LongName:    "dsn",
+				ShortName:   "d",
+				Aliases:     []string{"ds", "datasource"},
+				Description: "dsn",
+				OptionType:  cli.StringType,
+			},
+			{
+				LongName:    "format",
+				Description: "table.import.format",
+				OptionType:  cli.KeywordType,
+				Keywords:    []string{"csv", "ndjson"},
+			},
+			{
+				LongName:    "batch",
+				ShortName:   "b",
+				Aliases:     []string{"batch-size"},
+				Description: "table.import.batch",
+				OptionType:  cli.IntType,
+			},
+			{
+				LongName:    "map",
+				ShortName:   "m",
+				Aliases:     []string{"mapping"},
+				Description: "table.import.map",
+				OptionType:  cli.StringListType,
+			},
+			{
+				LongName:    "max-errors",
+				ShortName:   "e",
+				Aliases:     []string{"errors"},
+				Description: "table.import.max.errors",
+				OptionType:  cli.IntType,
+			},
+		},
+	},
+	{
+		LongName:      "export",
+		Aliases:       []string{"dump", "unload"},
+		Description:   "ego.table.export",
+		OptionType:    cli.Subcommand,
+		Action:        commands.TableExport,
+		ExpectedParms: -2,
+		MinParams:     1,
+		ParmDesc:      "parm.table.export",
+		Value: []cli.Option{
+			{
+				LongName:    "dsn",
+				ShortName:   "d",
+				Aliases:     []string{"ds", "datasource"},
+				Description: "dsn",
+				OptionType:  cli.StringType,
+			},
+			{
+				LongName:    "format",
+				Description: "table.export.format",
+				OptionType:  cli.KeywordType,
+				Keywords:    []string{"csv", "ndjson"},
+			},
+			{
+				LongName:    "columns",
+				ShortName:   "c",
+				Aliases:     []string{"column"},
+				Description: "table.read.columns",
+				OptionType:  cli.StringListType,
+			},
+			{
+				LongName:    "order-by",
+				ShortName:   "o",
+				Aliases:     []string{"sort", "order"},
+				Description: "table.read.order.by",
+				OptionType:  cli.StringListType,
+			},
+			{
+				LongName:    "filter",
+				ShortName:   "f",
+				Aliases:     []string{"where"},
+				Description: "filter",
+				OptionType:  cli.StringListType,
+			},
+		},
+	},
 }
 
 var ServerShowUserGrammar = []cli.Option{
@@ -686,6 +777,38 @@ var ServerUserGrammar = []cli.Option{
 	},
 }
 
+var ServerUpdateUserGrammar = []cli.Option{
+	{
+		LongName:    "username",
+		ShortName:   "u",
+		Description: "server.user.user",
+		OptionType:  cli.StringType,
+		Private:     true,
+	},
+	{
+		LongName:    "password",
+		ShortName:   "p",
+		Description: "server.user.pass",
+		OptionType:  cli.StringType,
+	},
+	{
+		LongName:    "permissions",
+		Aliases:     []string{"permission"},
+		Description: "server.user.perms",
+		OptionType:  cli.StringListType,
+	},
+	{
+		LongName:    "enroll-totp",
+		Description: "server.user.enroll.totp",
+		OptionType:  cli.BooleanType,
+	},
+	{
+		LongName:    "reset-totp",
+		Description: "server.user.reset.totp",
+		OptionType:  cli.BooleanType,
+	},
+}
+
 var ServerListUsersGrammar = []cli.Option{
 	{
 		LongName:    "id",
@@ -725,7 +848,7 @@ var UserGrammar = []cli.Option{
 		ParmDesc:      "username",
 		ExpectedParms: -1,
 		Action:        commands.UpdateUser,
-		Value:         ServerUserGrammar,
+		Value:         ServerUpdateUserGrammar,
 	},
 	{
 		LongName:      "show",
@@ -755,6 +878,372 @@ var UserGrammar = []cli.Option{
 	},
 }
 
+var ServerRoleGrammar = []cli.Option{
+	{
+		LongName:    "name",
+		ShortName:   "n",
+		Description: "server.role.name",
+		OptionType:  cli.StringType,
+		Private:     true,
+	},
+	{
+		LongName:    "description",
+		ShortName:   "d",
+		Description: "server.role.desc",
+		OptionType:  cli.StringType,
+	},
+	{
+		LongName:    "permissions",
+		Aliases:     []string{"permission"},
+		Description: "server.role.perms",
+		OptionType:  cli.StringListType,
+	},
+	{
+		LongName:    "inherits",
+		Aliases:     []string{"inherit"},
+		Description: "server.role.inherits",
+		OptionType:  cli.StringListType,
+	},
+}
+
+var ServerGroupGrammar = []cli.Option{
+	{
+		LongName:    "name",
+		ShortName:   "n",
+		Description: "server.group.name",
+		OptionType:  cli.StringType,
+		Private:     true,
+	},
+	{
+		LongName:    "description",
+		ShortName:   "d",
+		Description: "server.group.desc",
+		OptionType:  cli.StringType,
+	},
+	{
+		LongName:    "members",
+		Aliases:     []string{"member"},
+		Description: "server.group.members",
+		OptionType:  cli.StringListType,
+	},
+	{
+		LongName:    "roles",
+		Aliases:     []string{"role"},
+		Description: "server.group.roles",
+		OptionType:  cli.StringListType,
+	},
+}
+
+// RoleGrammar contains the grammar for SERVER ROLES subcommands.
+var RoleGrammar = []cli.Option{
+	{
+		LongName:      "create",
+		Description:   "ego.server.role.create",
+		Aliases:       []string{"add"},
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.role.name",
+		ExpectedParms: -1,
+		Action:        commands.AddRole,
+		Value:         ServerRoleGrammar,
+	},
+	{
+		LongName:      "update",
+		Description:   "ego.server.role.update",
+		Aliases:       []string{"modify", "alter"},
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.role.name",
+		ExpectedParms: -1,
+		Action:        commands.UpdateRole,
+		Value:         ServerRoleGrammar,
+	},
+	{
+		LongName:      "show",
+		Description:   "ego.server.role.show",
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.role.name",
+		ExpectedParms: -1,
+		Action:        commands.ShowRole,
+		Value:         ServerRoleGrammar[:1],
+	},
+	{
+		LongName:      "delete",
+		Description:   "ego.server.role.delete",
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.role.name",
+		ExpectedParms: -1,
+		Action:        commands.DeleteRole,
+		Value:         ServerRoleGrammar[:1],
+	},
+	{
+		LongName:    "list",
+		Description: "ego.server.role.list",
+		OptionType:  cli.Subcommand,
+		Action:      commands.ListRoles,
+		DefaultVerb: true,
+	},
+}
+
+// GroupGrammar contains the grammar for SERVER GROUPS subcommands.
+var GroupGrammar = []cli.Option{
+	{
+		LongName:      "create",
+		Description:   "ego.server.group.create",
+		Aliases:       []string{"add"},
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.group.name",
+		ExpectedParms: -1,
+		Action:        commands.AddGroup,
+		Value:         ServerGroupGrammar,
+	},
+	{
+		LongName:      "update",
+		Description:   "ego.server.group.update",
+		Aliases:       []string{"modify", "alter"},
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.group.name",
+		ExpectedParms: -1,
+		Action:        commands.UpdateGroup,
+		Value:         ServerGroupGrammar,
+	},
+	{
+		LongName:      "show",
+		Description:   "ego.server.group.show",
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.group.name",
+		ExpectedParms: -1,
+		Action:        commands.ShowGroup,
+		Value:         ServerGroupGrammar[:1],
+	},
+	{
+		LongName:      "delete",
+		Description:   "ego.server.group.delete",
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.group.name",
+		ExpectedParms: -1,
+		Action:        commands.DeleteGroup,
+		Value:         ServerGroupGrammar[:1],
+	},
+	{
+		LongName:    "list",
+		Description: "ego.server.group.list",
+		OptionType:  cli.Subcommand,
+		Action:      commands.ListGroups,
+		DefaultVerb: true,
+	},
+}
+
+// SessionGrammar contains the grammar for SERVER SESSIONS subcommands.
+var SessionGrammar = []cli.Option{
+	{
+		LongName:    "list",
+		Description: "ego.server.session.list",
+		OptionType:  cli.Subcommand,
+		Action:      commands.ListSessions,
+		DefaultVerb: true,
+		Value: []cli.Option{
+			{
+				LongName:    "user",
+				ShortName:   "u",
+				Description: "server.session.user",
+				OptionType:  cli.StringType,
+			},
+		},
+	},
+	{
+		LongName:      "revoke",
+		Description:   "ego.server.session.revoke",
+		Aliases:       []string{"delete"},
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.session.id",
+		ExpectedParms: -1,
+		Action:        commands.RevokeSession,
+		Value: []cli.Option{
+			{
+				LongName:    "user",
+				ShortName:   "u",
+				Description: "server.session.revoke.user",
+				OptionType:  cli.StringType,
+			},
+		},
+	},
+}
+
+// APIKeyGrammar contains the grammar for SERVER APIKEYS subcommands.
+var APIKeyGrammar = []cli.Option{
+	{
+		LongName:    "list",
+		Description: "ego.server.apikey.list",
+		OptionType:  cli.Subcommand,
+		Action:      commands.ListAPIKeys,
+		DefaultVerb: true,
+		Value: []cli.Option{
+			{
+				LongName:    "user",
+				ShortName:   "u",
+				Description: "server.apikey.list.user",
+				OptionType:  cli.StringType,
+			},
+		},
+	},
+	{
+		LongName:    "create",
+		Description: "ego.server.apikey.create",
+		Aliases:     []string{"add"},
+		OptionType:  cli.Subcommand,
+		Action:      commands.CreateAPIKey,
+		Value: []cli.Option{
+			{
+				LongName:    "user",
+				ShortName:   "u",
+				Description: "server.apikey.user",
+				OptionType:  cli.StringType,
+				Required:    true,
+			},
+			{
+				LongName:    "permissions",
+				ShortName:   "p",
+				Description: "server.apikey.permissions",
+				OptionType:  cli.StringListType,
+				Required:    true,
+			},
+			{
+				LongName:    "expires",
+				ShortName:   "e",
+				Description: "server.apikey.expires",
+				OptionType:  cli.StringType,
+			},
+			{
+				LongName:    "allow",
+				ShortName:   "a",
+				Description: "server.apikey.allow",
+				OptionType:  cli.StringListType,
+			},
+			{
+				LongName:    "description",
+				ShortName:   "d",
+				Description: "server.apikey.description",
+				OptionType:  cli.StringType,
+			},
+		},
+	},
+	{
+		LongName:      "revoke",
+		Description:   "ego.server.apikey.revoke",
+		Aliases:       []string{"delete"},
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.apikey.id",
+		ExpectedParms: 1,
+		Action:        commands.RevokeAPIKey,
+	},
+}
+
+// SecretsGrammar contains the grammar for SERVER SECRETS subcommands.
+var SecretsGrammar = []cli.Option{
+	{
+		LongName:    "list",
+		Description: "ego.server.secret.list",
+		OptionType:  cli.Subcommand,
+		Action:      commands.ListSecrets,
+		DefaultVerb: true,
+	},
+	{
+		LongName:      "set",
+		Description:   "ego.server.secret.set",
+		Aliases:       []string{"create", "add"},
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.secret.name",
+		ExpectedParms: -1,
+		Action:        commands.SetSecret,
+		Value: []cli.Option{
+			{
+				LongName:    "value",
+				Description: "server.secret.value",
+				OptionType:  cli.StringType,
+			},
+			{
+				LongName:    "users",
+				ShortName:   "u",
+				Description: "server.secret.users",
+				OptionType:  cli.StringListType,
+			},
+			{
+				LongName:    "roles",
+				ShortName:   "r",
+				Description: "server.secret.roles",
+				OptionType:  cli.StringListType,
+			},
+			{
+				LongName:    "description",
+				ShortName:   "d",
+				Description: "server.secret.description",
+				OptionType:  cli.StringType,
+			},
+		},
+	},
+	{
+		LongName:      "delete",
+		Description:   "ego.server.secret.delete",
+		Aliases:       []string{"remove"},
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.secret.name",
+		ExpectedParms: -1,
+		Action:        commands.DeleteSecret,
+	},
+}
+
+// JobsGrammar contains the grammar for SERVER JOBS subcommands.
+var JobsGrammar = []cli.Option{
+	{
+		LongName:    "list",
+		Description: "ego.server.job.list",
+		OptionType:  cli.Subcommand,
+		Action:      commands.ListJobs,
+		DefaultVerb: true,
+	},
+	{
+		LongName:      "history",
+		Aliases:       []string{"show"},
+		Description:   "ego.server.job.history",
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.job.name",
+		ExpectedParms: -1,
+		Action:        commands.JobHistory,
+	},
+	{
+		LongName:      "run",
+		Aliases:       []string{"start"},
+		Description:   "ego.server.job.run",
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.job.name",
+		ExpectedParms: -1,
+		Action:        commands.RunJob,
+		Value: []cli.Option{
+			{
+				LongName:    "wait",
+				ShortName:   "w",
+				Description: "server.job.wait",
+				OptionType:  cli.BooleanType,
+			},
+		},
+	},
+	{
+		LongName:      "pause",
+		Description:   "ego.server.job.pause",
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.job.name",
+		ExpectedParms: -1,
+		Action:        commands.PauseJob,
+	},
+	{
+		LongName:      "resume",
+		Description:   "ego.server.job.resume",
+		OptionType:    cli.Subcommand,
+		ParmDesc:      "parm.job.name",
+		ExpectedParms: -1,
+		Action:        commands.ResumeJob,
+	},
+}
+
 // CachesGrammar defines the grammar for the SERVER CACHES subcommands.
 var CachesGrammar = []cli.Option{
 	{
@@ -883,6 +1372,90 @@ var ServerGrammar = []cli.Option{
 		OptionType:  cli.Subcommand,
 		Value:       UserGrammar,
 	},
+	{
+		LongName:    "roles",
+		Aliases:     []string{"role"},
+		Description: "ego.server.roles",
+		OptionType:  cli.Subcommand,
+		Value:       RoleGrammar,
+	},
+	{
+		LongName:    "groups",
+		Aliases:     []string{"group"},
+		Description: "ego.server.groups",
+		OptionType:  cli.Subcommand,
+		Value:       GroupGrammar,
+	},
+	{
+		LongName:    "sessions",
+		Aliases:     []string{"session"},
+		Description: "ego.server.sessions",
+		OptionType:  cli.Subcommand,
+		Value:       SessionGrammar,
+	},
+	{
+		LongName:    "apikeys",
+		Aliases:     []string{"apikey"},
+		Description: "ego.server.apikeys",
+		OptionType:  cli.Subcommand,
+		Value:       APIKeyGrammar,
+	},
+	{
+		LongName:    "secrets",
+		Aliases:     []string{"secret"},
+		Description: "ego.server.secrets",
+		OptionType:  cli.Subcommand,
+		Value:       SecretsGrammar,
+	},
+	{
+		LongName:    "jobs",
+		Aliases:     []string{"job"},
+		Description: "ego.server.jobs",
+		OptionType:  cli.Subcommand,
+		Value:       JobsGrammar,
+	},
+	{
+		LongName:    "audit",
+		Description: "ego.server.audit",
+		OptionType:  cli.Subcommand,
+		Action:      commands.ShowAudit,
+		Value: []cli.Option{
+			{
+				LongName:    "event",
+				ShortName:   "e",
+				Description: "server.audit.event",
+				OptionType:  cli.StringType,
+			},
+			{
+				LongName:    "user",
+				ShortName:   "u",
+				Description: "server.audit.user",
+				OptionType:  cli.StringType,
+			},
+			{
+				LongName:    "session",
+				ShortName:   "s",
+				Description: "server.audit.session",
+				OptionType:  cli.IntType,
+			},
+			{
+				LongName:    "since",
+				Description: "server.audit.since",
+				OptionType:  cli.StringType,
+			},
+			{
+				LongName:    "until",
+				Description: "server.audit.until",
+				OptionType:  cli.StringType,
+			},
+			{
+				LongName:    "limit",
+				ShortName:   "l",
+				Description: "server.audit.limit",
+				OptionType:  cli.IntType,
+			},
+		},
+	},
 	{
 		LongName:    "memory",
 		Description: "ego.server.memory",
@@ -0,0 +1,113 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+
+	"github.com/tucats/ego/errors"
+)
+
+// Key is a single public key from a JSON Web Key Set. Only the members needed
+// to describe RSA, elliptic curve and Ed25519 public keys are supported.
+type Key struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// KeySet is a JSON Web Key Set, as published at the "jwks_uri" of an issuer.
+type KeySet struct {
+	Keys []Key `json:"keys"`
+}
+
+// Find returns the key with the given key ID. If the ID is empty and the set
+// has only one key, that key is returned.
+func (s KeySet) Find(id string) (Key, bool) {
+	for _, key := range s.Keys {
+		if key.KeyID == id {
+			return key, true
+		}
+	}
+
+	if id == "" && len(s.Keys) == 1 {
+		return s.Keys[0], true
+	}
+
+	return Key{}, false
+}
+
+// PublicKey converts the key to the matching public key type from the Go
+// crypto packages.
+func (k Key) PublicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, errors.ErrJWTKey.Context(k.KeyID)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.ErrJWTKey.Context(k.Curve)
+		}
+
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		b, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || k.Curve != "Ed25519" || len(b) != ed25519.PublicKeySize {
+			return nil, errors.ErrJWTKey.Context(k.KeyID)
+		}
+
+		return ed25519.PublicKey(b), nil
+	}
+
+	return nil, errors.ErrJWTKey.Context(k.KeyType)
+}
+
+// decodeInt decodes a base64url encoded big-endian integer value.
+func decodeInt(text string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(text)
+	if err != nil || len(b) == 0 {
+		return nil, errors.ErrJWTKey.Context(text)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
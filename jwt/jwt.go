@@ -0,0 +1,250 @@
+// Package jwt supports JSON Web Tokens (RFC 7519), used by Ego for tokens issued
+// by an external identity provider and for the tokens the server itself issues
+// when configured to use JWTs. A token is parsed into its header and claims, and
+// the signature is verified using a shared secret or a public key from a JSON Web
+// Key Set (RFC 7517) published by the issuer of the token.
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/tucats/ego/errors"
+)
+
+// Header is the JOSE header of a token, which describes how it was signed.
+type Header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	Type      string `json:"typ,omitempty"`
+}
+
+// Claims is the set of claims in the payload of a token.
+type Claims map[string]interface{}
+
+// Token is a parsed JSON Web Token.
+type Token struct {
+	Header    Header
+	Claims    Claims
+	signed    string
+	signature []byte
+}
+
+// Parse splits a compact serialized token into its header, claims and signature.
+// The signature is not verified; use Verify for that.
+func Parse(text string) (*Token, error) {
+	parts := strings.Split(strings.TrimSpace(text), ".")
+	if len(parts) != 3 {
+		return nil, errors.ErrInvalidJWT.Context("segments")
+	}
+
+	t := &Token{signed: parts[0] + "." + parts[1]}
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err == nil {
+		err = json.Unmarshal(b, &t.Header)
+	}
+
+	if err != nil {
+		return nil, errors.ErrInvalidJWT.Context("header")
+	}
+
+	b, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err == nil {
+		decoder := json.NewDecoder(strings.NewReader(string(b)))
+		decoder.UseNumber()
+		err = decoder.Decode(&t.Claims)
+	}
+
+	if err != nil {
+		return nil, errors.ErrInvalidJWT.Context("claims")
+	}
+
+	if t.signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, errors.ErrInvalidJWT.Context("signature")
+	}
+
+	return t, nil
+}
+
+// IsJWT reports if the text has the form of a compact serialized token. This is
+// used to tell a JWT from other kinds of bearer tokens without parsing it.
+func IsJWT(text string) bool {
+	return strings.Count(text, ".") == 2 && strings.HasPrefix(text, "eyJ")
+}
+
+// Verify checks the signature of the token using the given key, which must be a
+// []byte secret for the HMAC algorithms, or a public key of the type required by
+// the signing algorithm in the token header. Unsigned tokens (algorithm "none")
+// are never accepted.
+func (t *Token) Verify(key crypto.PublicKey) error {
+	hash, ok := hashes[t.Header.Algorithm]
+	if !ok {
+		return errors.ErrJWTAlgorithm.Context(t.Header.Algorithm)
+	}
+
+	valid := false
+
+	switch pub := key.(type) {
+	case []byte:
+		if strings.HasPrefix(t.Header.Algorithm, "HS") {
+			mac := hmac.New(hash.New, pub)
+			_, _ = mac.Write([]byte(t.signed))
+			valid = hmac.Equal(mac.Sum(nil), t.signature)
+		}
+
+	case *rsa.PublicKey:
+		if strings.HasPrefix(t.Header.Algorithm, "RS") {
+			valid = rsa.VerifyPKCS1v15(pub, hash, digest(hash, t.signed), t.signature) == nil
+		} else if strings.HasPrefix(t.Header.Algorithm, "PS") {
+			valid = rsa.VerifyPSS(pub, hash, digest(hash, t.signed), t.signature, nil) == nil
+		}
+
+	case *ecdsa.PublicKey:
+		// The signature is the R and S values concatenated, each the size of the curve.
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if strings.HasPrefix(t.Header.Algorithm, "ES") && len(t.signature) == 2*size {
+			r := new(big.Int).SetBytes(t.signature[:size])
+			s := new(big.Int).SetBytes(t.signature[size:])
+			valid = ecdsa.Verify(pub, digest(hash, t.signed), r, s)
+		}
+
+	case ed25519.PublicKey:
+		if t.Header.Algorithm == "EdDSA" {
+			valid = ed25519.Verify(pub, []byte(t.signed), t.signature)
+		}
+
+	default:
+		return errors.ErrJWTKey.Context(t.Header.KeyID)
+	}
+
+	if !valid {
+		return errors.ErrJWTSignature
+	}
+
+	return nil
+}
+
+// Validate checks the registered claims of the token. The issuer must match, the
+// audience must include the given audience, and the current time must be within
+// the validity period of the token, allowing for the given clock skew. An empty
+// issuer or audience is not checked.
+func (t *Token) Validate(issuer, audience string, skew time.Duration) error {
+	now := time.Now()
+
+	if issuer != "" && t.Claims.String("iss") != issuer {
+		return errors.ErrJWTClaim.Context("iss")
+	}
+
+	if audience != "" && !t.Claims.HasAudience(audience) {
+		return errors.ErrJWTClaim.Context("aud")
+	}
+
+	if exp, found := t.Claims.Time("exp"); !found || now.After(exp.Add(skew)) {
+		return errors.ErrExpiredToken
+	}
+
+	if nbf, found := t.Claims.Time("nbf"); found && now.Add(skew).Before(nbf) {
+		return errors.ErrJWTClaim.Context("nbf")
+	}
+
+	return nil
+}
+
+// String returns the value of a string claim, or an empty string if the claim is
+// not present or is not a string.
+func (c Claims) String(name string) string {
+	if s, ok := c[name].(string); ok {
+		return s
+	}
+
+	return ""
+}
+
+// Strings returns the values of a claim that can be a single string or an array
+// of strings, such as "aud" or a list of groups.
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+
+	case []interface{}:
+		result := make([]string, 0, len(v))
+
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+
+		return result
+	}
+
+	return nil
+}
+
+// Time returns the value of a claim that is a numeric date, expressed in seconds
+// since the epoch.
+func (c Claims) Time(name string) (time.Time, bool) {
+	switch v := c[name].(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return time.Unix(int64(f), 0), true
+		}
+
+	case float64:
+		return time.Unix(int64(v), 0), true
+
+	case int64:
+		return time.Unix(v, 0), true
+
+	case int:
+		return time.Unix(int64(v), 0), true
+	}
+
+	return time.Time{}, false
+}
+
+// HasAudience reports if the audience claim of the token includes the given value.
+func (c Claims) HasAudience(audience string) bool {
+	for _, aud := range c.Strings("aud") {
+		if aud == audience {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hashes maps each supported signing algorithm to the hash function it uses.
+var hashes = map[string]crypto.Hash{
+	"HS256": crypto.SHA256,
+	"HS384": crypto.SHA384,
+	"HS512": crypto.SHA512,
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"PS256": crypto.SHA256,
+	"PS384": crypto.SHA384,
+	"PS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+	"EdDSA": crypto.Hash(0),
+}
+
+// digest returns the hash of the signed part of a token.
+func digest(hash crypto.Hash, signed string) []byte {
+	h := hash.New()
+	_, _ = h.Write([]byte(signed))
+
+	return h.Sum(nil)
+}
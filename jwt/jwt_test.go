@@ -0,0 +1,173 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tucats/ego/errors"
+)
+
+// sign creates a token for the tests, signed with the given private key.
+func sign(t *testing.T, alg, kid string, claims Claims, key crypto.PrivateKey) string {
+	text, err := Sign(alg, kid, claims, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return text
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	set := KeySet{}
+
+	for kid, key := range map[string]crypto.PublicKey{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey, "ed": edKey.Public()} {
+		jwk, err := NewKey(kid, "", key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Round trip the key through JSON, as it is when it is published.
+		b, _ := json.Marshal(jwk)
+		jwk = Key{}
+		_ = json.Unmarshal(b, &jwk)
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	claims := Claims{"sub": "tom", "iss": "https://idp", "aud": []string{"ego", "other"}, "exp": time.Now().Add(time.Hour).Unix()}
+
+	tests := []struct {
+		alg string
+		kid string
+		key crypto.PrivateKey
+	}{
+		{"RS256", "rsa", rsaKey},
+		{"PS384", "rsa", rsaKey},
+		{"ES256", "ec", ecKey},
+		{"EdDSA", "ed", edKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			text := sign(t, tt.alg, tt.kid, claims, tt.key)
+			if !IsJWT(text) {
+				t.Fatalf("IsJWT() = false")
+			}
+
+			token, err := Parse(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			key, found := set.Find(token.Header.KeyID)
+			if !found {
+				t.Fatalf("Find(%s) failed", token.Header.KeyID)
+			}
+
+			public, err := key.PublicKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := token.Verify(public); err != nil {
+				t.Errorf("Verify() = %v", err)
+			}
+
+			if err := token.Validate("https://idp", "ego", 0); err != nil {
+				t.Errorf("Validate() = %v", err)
+			}
+
+			// A token with the claims from another token must not verify.
+			parts := strings.Split(text, ".")
+			other := strings.Split(sign(t, tt.alg, tt.kid, Claims{"sub": "root"}, tt.key), ".")
+
+			forged, err := Parse(parts[0] + "." + other[1] + "." + parts[2])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := forged.Verify(public); !errors.Equals(err, errors.ErrJWTSignature) {
+				t.Errorf("Verify() of changed token = %v", err)
+			}
+		})
+	}
+}
+
+func TestVerify_HMAC(t *testing.T) {
+	secret := []byte("a shared secret")
+	text := sign(t, "HS256", "hs", Claims{"sub": "tom", "exp": time.Now().Add(time.Minute).Unix()}, secret)
+
+	token, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := token.Verify(secret); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+
+	if err := token.Verify([]byte("another secret")); !errors.Equals(err, errors.ErrJWTSignature) {
+		t.Errorf("Verify() with wrong secret = %v", err)
+	}
+
+	// An HMAC key cannot be used with a public key algorithm, or the reverse.
+	if _, err := Sign("RS256", "hs", Claims{}, secret); !errors.Equals(err, errors.ErrJWTKey) {
+		t.Errorf("Sign() with wrong key type = %v", err)
+	}
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	if err := token.Verify(&rsaKey.PublicKey); !errors.Equals(err, errors.ErrJWTSignature) {
+		t.Errorf("Verify() with public key = %v", err)
+	}
+}
+
+func TestVerify_Errors(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	text := sign(t, "RS256", "", Claims{"exp": time.Now().Add(-time.Hour).Unix(), "aud": "ego"}, key)
+
+	token, err := Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := token.Verify(&other.PublicKey); !errors.Equals(err, errors.ErrJWTSignature) {
+		t.Errorf("Verify() with wrong key = %v", err)
+	}
+
+	if err := token.Validate("", "ego", 0); !errors.Equals(err, errors.ErrExpiredToken) {
+		t.Errorf("Validate() of expired token = %v", err)
+	}
+
+	if err := token.Validate("", "ego", 2*time.Hour); err != nil {
+		t.Errorf("Validate() with clock skew = %v", err)
+	}
+
+	if err := token.Validate("", "other", 2*time.Hour); !errors.Equals(err, errors.ErrJWTClaim) {
+		t.Errorf("Validate() with wrong audience = %v", err)
+	}
+
+	token.Header.Algorithm = "none"
+	if err := token.Verify(&key.PublicKey); !errors.Equals(err, errors.ErrJWTAlgorithm) {
+		t.Errorf("Verify() of unsigned token = %v", err)
+	}
+
+	for _, text := range []string{"", "a.b", "!!.e30.", "e30.!!.", "e30.e30.!!"} {
+		if _, err := Parse(text); !errors.Equals(err, errors.ErrInvalidJWT) {
+			t.Errorf("Parse(%q) = %v", text, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/tucats/ego/errors"
+)
+
+// Sign creates a compact serialized token with the given claims, signed using the
+// algorithm and private key. The key must be a []byte secret for the HMAC algorithms
+// (HS256, HS384 and HS512), or the matching RSA, ECDSA or Ed25519 private key for
+// the other algorithms. The key ID is stored in the token header so the key used to
+// verify the token can be found when keys are rotated.
+func Sign(algorithm, kid string, claims Claims, key crypto.PrivateKey) (string, error) {
+	hash, ok := hashes[algorithm]
+	if !ok {
+		return "", errors.ErrJWTAlgorithm.Context(algorithm)
+	}
+
+	header, err := json.Marshal(Header{Algorithm: algorithm, KeyID: kid, Type: "JWT"})
+	if err != nil {
+		return "", errors.New(err)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", errors.New(err)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+
+	switch k := key.(type) {
+	case []byte:
+		if !strings.HasPrefix(algorithm, "HS") {
+			return "", errors.ErrJWTKey.Context(kid)
+		}
+
+		mac := hmac.New(hash.New, k)
+		_, _ = mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+
+	case *rsa.PrivateKey:
+		if strings.HasPrefix(algorithm, "RS") {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, k, hash, digest(hash, signed))
+		} else if strings.HasPrefix(algorithm, "PS") {
+			signature, err = rsa.SignPSS(rand.Reader, k, hash, digest(hash, signed), nil)
+		} else {
+			return "", errors.ErrJWTKey.Context(kid)
+		}
+
+	case *ecdsa.PrivateKey:
+		if !strings.HasPrefix(algorithm, "ES") {
+			return "", errors.ErrJWTKey.Context(kid)
+		}
+
+		var r, s *big.Int
+
+		if r, s, err = ecdsa.Sign(rand.Reader, k, digest(hash, signed)); err == nil {
+			size := (k.Curve.Params().BitSize + 7) / 8
+			signature = append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...)
+		}
+
+	case ed25519.PrivateKey:
+		if algorithm != "EdDSA" {
+			return "", errors.ErrJWTKey.Context(kid)
+		}
+
+		signature = ed25519.Sign(k, []byte(signed))
+
+	default:
+		return "", errors.ErrJWTKey.Context(kid)
+	}
+
+	if err != nil {
+		return "", errors.New(err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// NewKey creates the JSON Web Key for a public key, so it can be published in a
+// key set.
+func NewKey(kid, algorithm string, key crypto.PublicKey) (Key, error) {
+	result := Key{KeyID: kid, Algorithm: algorithm, Use: "sig"}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		result.KeyType = "RSA"
+		result.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		result.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		result.KeyType = "EC"
+		result.Curve = k.Curve.Params().Name
+		result.X = base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size)))
+		result.Y = base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size)))
+
+	case ed25519.PublicKey:
+		result.KeyType = "OKP"
+		result.Curve = "Ed25519"
+		result.X = base64.RawURLEncoding.EncodeToString(k)
+
+	default:
+		return result, errors.ErrJWTKey.Context(kid)
+	}
+
+	return result, nil
+}
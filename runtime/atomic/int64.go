@@ -0,0 +1,137 @@
+package atomic
+
+import (
+	"sync"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+// int64Lock serializes the operations on int64 values. An Ego variable is not
+// stored as a native int64, so the operations cannot use the Go sync/atomic
+// functions directly. Every operation on any address takes this lock, so the
+// operations are atomic with respect to each other, in any go routine.
+var int64Lock sync.Mutex
+
+// addInt64 implements the atomic.AddInt64() function, which adds the delta to the
+// value at the address and returns the new value.
+func addInt64(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	addr, err := int64Address(args.Get(0), "AddInt64")
+	if err != nil {
+		return nil, err
+	}
+
+	delta, err := data.Int64(args.Get(1))
+	if err != nil {
+		return nil, errors.New(err).In("AddInt64")
+	}
+
+	int64Lock.Lock()
+	defer int64Lock.Unlock()
+
+	value := data.Int64OrZero(*addr) + delta
+	*addr = value
+
+	return value, nil
+}
+
+// loadInt64 implements the atomic.LoadInt64() function, which returns the value at
+// the address.
+func loadInt64(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	addr, err := int64Address(args.Get(0), "LoadInt64")
+	if err != nil {
+		return nil, err
+	}
+
+	int64Lock.Lock()
+	defer int64Lock.Unlock()
+
+	return data.Int64OrZero(*addr), nil
+}
+
+// storeInt64 implements the atomic.StoreInt64() function, which stores the value
+// at the address.
+func storeInt64(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	addr, err := int64Address(args.Get(0), "StoreInt64")
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := data.Int64(args.Get(1))
+	if err != nil {
+		return nil, errors.New(err).In("StoreInt64")
+	}
+
+	int64Lock.Lock()
+	defer int64Lock.Unlock()
+
+	*addr = value
+
+	return nil, nil
+}
+
+// swapInt64 implements the atomic.SwapInt64() function, which stores the new value
+// at the address and returns the previous value.
+func swapInt64(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	addr, err := int64Address(args.Get(0), "SwapInt64")
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := data.Int64(args.Get(1))
+	if err != nil {
+		return nil, errors.New(err).In("SwapInt64")
+	}
+
+	int64Lock.Lock()
+	defer int64Lock.Unlock()
+
+	old := data.Int64OrZero(*addr)
+	*addr = value
+
+	return old, nil
+}
+
+// compareAndSwapInt64 implements the atomic.CompareAndSwapInt64() function. If the
+// value at the address is equal to the old value, it is replaced by the new value
+// and the result is true. Otherwise, the value is not changed and the result is
+// false.
+func compareAndSwapInt64(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	addr, err := int64Address(args.Get(0), "CompareAndSwapInt64")
+	if err != nil {
+		return nil, err
+	}
+
+	old, err := data.Int64(args.Get(1))
+	if err != nil {
+		return nil, errors.New(err).In("CompareAndSwapInt64")
+	}
+
+	value, err := data.Int64(args.Get(2))
+	if err != nil {
+		return nil, errors.New(err).In("CompareAndSwapInt64")
+	}
+
+	int64Lock.Lock()
+	defer int64Lock.Unlock()
+
+	if data.Int64OrZero(*addr) != old {
+		return false, nil
+	}
+
+	*addr = value
+
+	return true, nil
+}
+
+// int64Address returns the address passed to one of the int64 functions, which
+// must be a pointer to a variable, such as &count.
+func int64Address(v interface{}, name string) (*interface{}, error) {
+	addr, ok := v.(*interface{})
+	if !ok || addr == nil {
+		return nil, errors.ErrNotAPointer.In(name).Context(data.TypeOf(v).String())
+	}
+
+	return addr, nil
+}
@@ -0,0 +1,57 @@
+package atomic
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/tucats/ego/data"
+)
+
+func TestAddInt64(t *testing.T) {
+	var (
+		count interface{} = int64(0)
+		wg    sync.WaitGroup
+	)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				_, _ = addInt64(nil, data.NewList(&count, 1))
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if v, _ := loadInt64(nil, data.NewList(&count)); v != int64(1000) {
+		t.Errorf("LoadInt64() = %v, want 1000", v)
+	}
+}
+
+func TestInt64Functions(t *testing.T) {
+	var value interface{} = 5
+
+	if v, _ := swapInt64(nil, data.NewList(&value, 7)); v != int64(5) || value != int64(7) {
+		t.Errorf("SwapInt64() = %v, value %v", v, value)
+	}
+
+	if ok, _ := compareAndSwapInt64(nil, data.NewList(&value, 1, 2)); ok != false || value != int64(7) {
+		t.Errorf("CompareAndSwapInt64() with wrong old value = %v, value %v", ok, value)
+	}
+
+	if ok, _ := compareAndSwapInt64(nil, data.NewList(&value, 7, 2)); ok != true || value != int64(2) {
+		t.Errorf("CompareAndSwapInt64() = %v, value %v", ok, value)
+	}
+
+	if _, err := storeInt64(nil, data.NewList(&value, 11)); err != nil || value != int64(11) {
+		t.Errorf("StoreInt64() error = %v, value %v", err, value)
+	}
+
+	if _, err := addInt64(nil, data.NewList(value, 1)); err == nil {
+		t.Errorf("AddInt64() expected error for value that is not a pointer")
+	}
+}
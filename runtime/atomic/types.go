@@ -0,0 +1,175 @@
+package atomic
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/symbols"
+)
+
+var valueType *data.Type
+
+var initLock sync.Mutex
+
+// Initialize creates the "atomic" package and defines its functions and the Value
+// type. The Value type is a native type, so a Value is always a pointer to a new
+// Go atomic.Value, and copies of it (including arguments to go routines) all refer
+// to the same value.
+func Initialize(s *symbols.SymbolTable) {
+	initLock.Lock()
+	defer initLock.Unlock()
+
+	if valueType == nil {
+		valueType = data.TypeDefinition("Value", data.StructureType()).
+			SetNativeName("atomic.Value").
+			SetPackage("atomic").
+			SetNew(func() interface{} {
+				return new(atomic.Value)
+			})
+
+		valueType.DefineFunction("Load",
+			&data.Declaration{
+				Name:    "Load",
+				Type:    valueType,
+				Returns: []*data.Type{data.InterfaceType},
+			}, load)
+
+		valueType.DefineFunction("Store",
+			&data.Declaration{
+				Name: "Store",
+				Type: valueType,
+				Parameters: []data.Parameter{
+					{
+						Name: "value",
+						Type: data.InterfaceType,
+					},
+				},
+			}, store)
+
+		valueType.DefineFunction("Swap",
+			&data.Declaration{
+				Name: "Swap",
+				Type: valueType,
+				Parameters: []data.Parameter{
+					{
+						Name: "new",
+						Type: data.InterfaceType,
+					},
+				},
+				Returns: []*data.Type{data.InterfaceType},
+			}, swap)
+
+		valueType.DefineFunction("CompareAndSwap",
+			&data.Declaration{
+				Name: "CompareAndSwap",
+				Type: valueType,
+				Parameters: []data.Parameter{
+					{
+						Name: "old",
+						Type: data.InterfaceType,
+					},
+					{
+						Name: "new",
+						Type: data.InterfaceType,
+					},
+				},
+				Returns: []*data.Type{data.BoolType},
+			}, compareAndSwap)
+	}
+
+	if _, found := s.Root().Get("atomic"); !found {
+		newpkg := data.NewPackageFromMap("atomic", map[string]interface{}{
+			"AddInt64": data.Function{
+				Declaration: &data.Declaration{
+					Name: "AddInt64",
+					Parameters: []data.Parameter{
+						{
+							Name: "addr",
+							Type: data.PointerType(data.Int64Type),
+						},
+						{
+							Name: "delta",
+							Type: data.Int64Type,
+						},
+					},
+					Returns: []*data.Type{data.Int64Type},
+				},
+				Value: addInt64,
+			},
+			"LoadInt64": data.Function{
+				Declaration: &data.Declaration{
+					Name: "LoadInt64",
+					Parameters: []data.Parameter{
+						{
+							Name: "addr",
+							Type: data.PointerType(data.Int64Type),
+						},
+					},
+					Returns: []*data.Type{data.Int64Type},
+				},
+				Value: loadInt64,
+			},
+			"StoreInt64": data.Function{
+				Declaration: &data.Declaration{
+					Name: "StoreInt64",
+					Parameters: []data.Parameter{
+						{
+							Name: "addr",
+							Type: data.PointerType(data.Int64Type),
+						},
+						{
+							Name: "value",
+							Type: data.Int64Type,
+						},
+					},
+				},
+				Value: storeInt64,
+			},
+			"SwapInt64": data.Function{
+				Declaration: &data.Declaration{
+					Name: "SwapInt64",
+					Parameters: []data.Parameter{
+						{
+							Name: "addr",
+							Type: data.PointerType(data.Int64Type),
+						},
+						{
+							Name: "new",
+							Type: data.Int64Type,
+						},
+					},
+					Returns: []*data.Type{data.Int64Type},
+				},
+				Value: swapInt64,
+			},
+			"CompareAndSwapInt64": data.Function{
+				Declaration: &data.Declaration{
+					Name: "CompareAndSwapInt64",
+					Parameters: []data.Parameter{
+						{
+							Name: "addr",
+							Type: data.PointerType(data.Int64Type),
+						},
+						{
+							Name: "old",
+							Type: data.Int64Type,
+						},
+						{
+							Name: "new",
+							Type: data.Int64Type,
+						},
+					},
+					Returns: []*data.Type{data.BoolType},
+				},
+				Value: compareAndSwapInt64,
+			},
+			"Value": valueType,
+		})
+
+		pkg, _ := bytecode.GetPackage(newpkg.Name)
+		pkg.Merge(newpkg)
+		s.Root().SetAlways(newpkg.Name, newpkg)
+	}
+}
@@ -0,0 +1,125 @@
+package atomic
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+// valueLock serializes the check that a value being stored has the same type as
+// the value already stored, with the store itself. The Go atomic.Value panics if
+// the types do not match, so the check must be done before the value is stored.
+var valueLock sync.Mutex
+
+// load implements the Value.Load() method, which returns the value most recently
+// stored, or nil if no value has been stored.
+func load(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	v, err := getValue(s, "Load")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Load(), nil
+}
+
+// store implements the Value.Store() method. All the values stored in a Value must
+// have the same type, and the value cannot be nil.
+func store(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	v, err := getValue(s, "Store")
+	if err != nil {
+		return nil, err
+	}
+
+	valueLock.Lock()
+	defer valueLock.Unlock()
+
+	if err := checkValue(v, args.Get(0), "Store"); err != nil {
+		return nil, err
+	}
+
+	v.Store(args.Get(0))
+
+	return nil, nil
+}
+
+// swap implements the Value.Swap() method, which stores the new value and returns
+// the previous value, or nil if no value had been stored.
+func swap(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	v, err := getValue(s, "Swap")
+	if err != nil {
+		return nil, err
+	}
+
+	valueLock.Lock()
+	defer valueLock.Unlock()
+
+	if err := checkValue(v, args.Get(0), "Swap"); err != nil {
+		return nil, err
+	}
+
+	return v.Swap(args.Get(0)), nil
+}
+
+// compareAndSwap implements the Value.CompareAndSwap() method. If the value stored
+// is equal to the old value, it is replaced by the new value and the result is true.
+func compareAndSwap(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	v, err := getValue(s, "CompareAndSwap")
+	if err != nil {
+		return nil, err
+	}
+
+	valueLock.Lock()
+	defer valueLock.Unlock()
+
+	if err := checkValue(v, args.Get(1), "CompareAndSwap"); err != nil {
+		return nil, err
+	}
+
+	old := args.Get(0)
+	if old != nil && !reflect.TypeOf(old).Comparable() {
+		return nil, errors.ErrInvalidType.In("CompareAndSwap").Context(data.TypeOf(old).String())
+	}
+
+	return v.CompareAndSwap(old, args.Get(1)), nil
+}
+
+// checkValue returns an error if a value cannot be stored in the Value, because it
+// is nil or has a different type than the value already stored.
+func checkValue(v *atomic.Value, value interface{}, name string) error {
+	if value == nil {
+		return errors.ErrInvalidValue.In(name).Context(defs.NilTypeString)
+	}
+
+	if current := v.Load(); current != nil && reflect.TypeOf(current) != reflect.TypeOf(value) {
+		return errors.ErrInvalidVarType.In(name).Context(data.TypeOf(value).String())
+	}
+
+	return nil
+}
+
+// getValue returns the receiver of a Value method. If the method was called using
+// a pointer to the value, the pointer is dereferenced.
+func getValue(s *symbols.SymbolTable, name string) (*atomic.Value, error) {
+	v, _ := s.Get(defs.ThisVariable)
+
+	for {
+		p, ok := v.(*interface{})
+		if !ok || p == nil {
+			break
+		}
+
+		v = *p
+	}
+
+	value, ok := v.(*atomic.Value)
+	if !ok {
+		return nil, errors.ErrInvalidThis.In(name)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,47 @@
+package atomic
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/symbols"
+)
+
+func TestValue(t *testing.T) {
+	s := symbols.NewSymbolTable("atomic test")
+	s.SetAlways(defs.ThisVariable, new(atomic.Value))
+
+	if v, _ := load(s, data.NewList()); v != nil {
+		t.Errorf("Load() of empty value = %v", v)
+	}
+
+	if _, err := store(s, data.NewList("a")); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	if old, _ := swap(s, data.NewList("b")); old != "a" {
+		t.Errorf("Swap() = %v, want a", old)
+	}
+
+	if ok, _ := compareAndSwap(s, data.NewList("a", "c")); ok != false {
+		t.Errorf("CompareAndSwap() with wrong old value = %v", ok)
+	}
+
+	if ok, _ := compareAndSwap(s, data.NewList("b", "c")); ok != true {
+		t.Errorf("CompareAndSwap() = %v", ok)
+	}
+
+	if v, _ := load(s, data.NewList()); v != "c" {
+		t.Errorf("Load() = %v, want c", v)
+	}
+
+	if _, err := store(s, data.NewList(42)); err == nil {
+		t.Errorf("Store() expected error for value of a different type")
+	}
+
+	if _, err := store(s, data.NewList(nil)); err == nil {
+		t.Errorf("Store() expected error for nil value")
+	}
+}
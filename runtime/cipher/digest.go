@@ -0,0 +1,124 @@
+package cipher
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	gohash "hash"
+	"strings"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+// The encodings of binary values, such as hashes and signatures, returned by the
+// cipher functions. The base64 encoding is the same as the base64 package uses.
+const (
+	hexEncoding    = "hex"
+	base64Encoding = "base64"
+)
+
+// The hash algorithms that can be used with cipher.HMAC().
+var hashAlgorithms = map[string]func() gohash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// digest returns a function that implements one of the cipher hash functions, such
+// as cipher.SHA256(). The function hashes a string, and returns the hash encoded as
+// hexadecimal, or as base64 if the optional second argument is "base64".
+func digest(algorithm string) func(*symbols.SymbolTable, data.List) (interface{}, error) {
+	return func(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+		h := hashAlgorithms[algorithm]()
+		_, _ = h.Write([]byte(data.String(args.Get(0))))
+
+		return encodeBytes(h.Sum(nil), args, 1)
+	}
+}
+
+// hmacHash implements the cipher.HMAC() function. The first argument is the name
+// of the hash algorithm, such as "sha256", followed by the key and the data. The
+// result is encoded as hexadecimal, or base64 if the optional fourth argument is
+// "base64".
+func hmacHash(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	algorithm := strings.ToLower(data.String(args.Get(0)))
+
+	newHash, found := hashAlgorithms[algorithm]
+	if !found {
+		return nil, errors.ErrInvalidAlgorithm.In("HMAC").Context(algorithm)
+	}
+
+	h := hmac.New(newHash, []byte(data.String(args.Get(1))))
+	_, _ = h.Write([]byte(data.String(args.Get(2))))
+
+	return encodeBytes(h.Sum(nil), args, 3)
+}
+
+// equal implements the cipher.Equal() function. It compares two strings in a time
+// that does not depend on how much of the strings match, so it can be used to check
+// hashes, signatures, and tokens without revealing anything about the expected value.
+func equal(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	a := []byte(data.String(args.Get(0)))
+	b := []byte(data.String(args.Get(1)))
+
+	return subtle.ConstantTimeCompare(a, b) == 1, nil
+}
+
+// encodeBytes encodes a binary value as a string, using the encoding named by the
+// optional argument at the given position. The default is hexadecimal.
+func encodeBytes(b []byte, args data.List, position int) (string, error) {
+	encoding := hexEncoding
+	if args.Len() > position {
+		encoding = strings.ToLower(data.String(args.Get(position)))
+	}
+
+	switch encoding {
+	case hexEncoding:
+		return hex.EncodeToString(b), nil
+
+	case base64Encoding:
+		return base64.StdEncoding.EncodeToString(b), nil
+
+	default:
+		return "", errors.ErrInvalidEncoding.Context(encoding)
+	}
+}
+
+// decodeBytes decodes a string encoded by encodeBytes, using the encoding named by
+// the optional argument at the given position.
+func decodeBytes(text string, args data.List, position int) ([]byte, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	encoding := hexEncoding
+	if args.Len() > position {
+		encoding = strings.ToLower(data.String(args.Get(position)))
+	}
+
+	switch encoding {
+	case hexEncoding:
+		b, err = hex.DecodeString(text)
+
+	case base64Encoding:
+		b, err = base64.StdEncoding.DecodeString(text)
+
+	default:
+		return nil, errors.ErrInvalidEncoding.Context(encoding)
+	}
+
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,108 @@
+package cipher
+
+import (
+	"testing"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+func Test_digest(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		args      data.List
+		want      string
+		wantErr   error
+	}{
+		{"md5", "md5", data.NewList("foo"), "acbd18db4cc2f85cedef654fccc4a4d8", nil},
+		{"sha1", "sha1", data.NewList("abc"), "a9993e364706816aba3e25717850c26c9cd0d89d", nil},
+		{"sha256", "sha256", data.NewList("abc"), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", nil},
+		{"sha512", "sha512", data.NewList(""), "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e", nil},
+		{"hex encoding", "sha1", data.NewList("abc", "HEX"), "a9993e364706816aba3e25717850c26c9cd0d89d", nil},
+		{"base64 encoding", "sha256", data.NewList("abc", "base64"), "ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=", nil},
+		{"invalid encoding", "md5", data.NewList("abc", "octal"), "", errors.ErrInvalidEncoding},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := digest(tt.algorithm)(symbols.NewRootSymbolTable("testing"), tt.args)
+			if tt.wantErr != nil {
+				if !errors.Equals(err, tt.wantErr) {
+					t.Errorf("digest() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("digest() unexpected error %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("digest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hmacHash(t *testing.T) {
+	// Test case 2 from RFC 4231, and the matching MD5 and SHA-1 cases from RFC 2202.
+	tests := []struct {
+		name    string
+		args    data.List
+		want    string
+		wantErr error
+	}{
+		{"sha256", data.NewList("sha256", "Jefe", "what do ya want for nothing?"), "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", nil},
+		{"sha512", data.NewList("SHA512", "Jefe", "what do ya want for nothing?"), "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737", nil},
+		{"sha1", data.NewList("sha1", "Jefe", "what do ya want for nothing?"), "effcdf6ae5eb2fa2d27416d5f184df9c259a7c79", nil},
+		{"md5", data.NewList("md5", "Jefe", "what do ya want for nothing?"), "750c783e6ab0b503eaa86e310a5db738", nil},
+		{"base64 encoding", data.NewList("sha256", "Jefe", "what do ya want for nothing?", "base64"), "W9zBRr9gdU5qBCQmCJV1x1oAPwidJzmDnexYuWTsOEM=", nil},
+		{"unknown algorithm", data.NewList("sha3", "key", "text"), "", errors.ErrInvalidAlgorithm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hmacHash(symbols.NewRootSymbolTable("testing"), tt.args)
+			if tt.wantErr != nil {
+				if !errors.Equals(err, tt.wantErr) {
+					t.Errorf("hmacHash() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("hmacHash() unexpected error %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("hmacHash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_equal(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"equal", "abc123", "abc123", true},
+		{"different", "abc123", "abc124", false},
+		{"different length", "abc", "abc123", false},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := equal(symbols.NewRootSymbolTable("testing"), data.NewList(tt.a, tt.b))
+			if got != tt.want {
+				t.Errorf("equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
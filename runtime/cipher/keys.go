@@ -0,0 +1,399 @@
+package cipher
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/jwt"
+	"github.com/tucats/ego/util"
+)
+
+const (
+	// JWTFormat is the token format setting value that selects JWT tokens.
+	JWTFormat = "jwt"
+
+	// The default signing algorithm and issuer for JWT tokens.
+	defaultAlgorithm = "HS256"
+	defaultIssuer    = "ego"
+)
+
+// signingKey is a key used to sign the JWT tokens issued by the server. The
+// key material is the HMAC secret, or the private key in PKCS #8 form, encoded
+// in base64. A key that has been replaced by a newer key is retired, but is kept
+// so it can validate tokens it signed until they expire.
+type signingKey struct {
+	ID        string    `json:"kid"`
+	Algorithm string    `json:"alg"`
+	Key       string    `json:"key"`
+	Created   time.Time `json:"created"`
+	Retired   time.Time `json:"retired"`
+}
+
+var (
+	keyLock    sync.Mutex
+	keyring    []signingKey
+	keyringSet string
+)
+
+// TokenPermissions is used to find the permissions of a user, to be stored in
+// the JWT tokens issued for the user. This is set by the server, since the
+// cipher package does not have access to the user database.
+var TokenPermissions func(user string) []string
+
+// newJWT creates a signed JWT containing the information in the token, using the
+// current signing key.
+func newJWT(t authToken) (interface{}, error) {
+	key, err := currentKey()
+	if err != nil {
+		return nil, err
+	}
+
+	private, err := key.privateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.Claims{
+		"iss": issuer(),
+		"sub": t.Name,
+		"iat": time.Now().Unix(),
+		"exp": t.Expires.Unix(),
+		"jti": t.TokenID.String(),
+	}
+
+	if t.Data != "" {
+		claims["ego.data"] = t.Data
+	}
+
+	if t.AuthID != uuid.Nil {
+		claims["ego.instance"] = t.AuthID.String()
+	}
+
+	if TokenPermissions != nil {
+		claims["ego.permissions"] = TokenPermissions(t.Name)
+	}
+
+	return jwt.Sign(key.Algorithm, key.ID, claims, private)
+}
+
+// decodeJWT converts a JWT issued by this server to a token object. The token
+// must be signed by one of the server's signing keys.
+func decodeJWT(text string) (authToken, error) {
+	t := authToken{}
+
+	token, err := jwt.Parse(text)
+	if err == nil && token.Claims.String("iss") != issuer() {
+		err = errors.ErrJWTClaim.Context("iss")
+	}
+
+	if err == nil {
+		key, found := findKey(token.Header.KeyID)
+		if !found || key.Algorithm != token.Header.Algorithm {
+			err = errors.ErrJWTKey.Context(token.Header.KeyID)
+		} else {
+			var public crypto.PublicKey
+
+			if public, err = key.publicKey(); err == nil {
+				err = token.Verify(public)
+			}
+		}
+	}
+
+	if err != nil {
+		ui.Log(ui.AuthLogger, "auth.invalid.jwt",
+			"error", err)
+
+		return t, err
+	}
+
+	t.Name = token.Claims.String("sub")
+	t.Data = token.Claims.String("ego.data")
+	t.TokenID, _ = uuid.Parse(token.Claims.String("jti"))
+	t.AuthID, _ = uuid.Parse(token.Claims.String("ego.instance"))
+	t.Expires, _ = token.Claims.Time("exp")
+
+	return t, nil
+}
+
+// IsServerJWT reports if the text is a JWT issued by this server, as opposed to a
+// JWT issued by an identity provider. The signature of the token is not checked.
+func IsServerJWT(text string) bool {
+	if !jwt.IsJWT(text) {
+		return false
+	}
+
+	token, err := jwt.Parse(text)
+
+	return err == nil && token.Claims.String("iss") == issuer()
+}
+
+// PublicKeys returns the set of public keys that can be used to validate the JWT
+// tokens issued by this server, for publishing at the JWKS endpoint. Keys for the
+// HMAC algorithms are secret, so they are never included.
+func PublicKeys() jwt.KeySet {
+	result := jwt.KeySet{Keys: []jwt.Key{}}
+
+	keyLock.Lock()
+	defer keyLock.Unlock()
+
+	loadKeys()
+
+	for _, key := range keyring {
+		if strings.HasPrefix(key.Algorithm, "HS") {
+			continue
+		}
+
+		public, err := key.publicKey()
+		if err != nil {
+			continue
+		}
+
+		if jwk, err := jwt.NewKey(key.ID, key.Algorithm, public); err == nil {
+			result.Keys = append(result.Keys, jwk)
+		}
+	}
+
+	return result
+}
+
+// currentKey returns the key used to sign new tokens. A new key is created if
+// there is no key yet, the signing algorithm setting has changed, or the key is
+// older than the rotation interval. Retired keys are removed from the keyring once
+// every token they could have signed has expired.
+func currentKey() (signingKey, error) {
+	algorithm := settings.Get(defs.ServerTokenAlgorithmSetting)
+	if algorithm == "" {
+		algorithm = defaultAlgorithm
+	}
+
+	keyLock.Lock()
+	defer keyLock.Unlock()
+
+	loadKeys()
+
+	now := time.Now()
+	changed := false
+
+	// Remove the retired keys that are no longer needed.
+	maxDuration, err := util.ParseDuration(settings.Get(defs.ServerTokenExpirationSetting))
+	if err != nil || maxDuration == 0 {
+		maxDuration = 24 * time.Hour
+	}
+
+	keys := []signingKey{}
+
+	for _, key := range keyring {
+		if !key.Retired.IsZero() && now.After(key.Retired.Add(maxDuration)) {
+			changed = true
+
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	keyring = keys
+
+	// The current key is the last key in the keyring. Decide if it must be replaced.
+	var current *signingKey
+
+	if len(keyring) > 0 && keyring[len(keyring)-1].Retired.IsZero() {
+		current = &keyring[len(keyring)-1]
+
+		rotation, err := util.ParseDuration(settings.Get(defs.ServerTokenRotationSetting))
+		expired := err == nil && rotation > 0 && now.After(current.Created.Add(rotation))
+
+		if current.Algorithm != algorithm || expired {
+			current.Retired = now
+			current = nil
+		}
+	}
+
+	if current == nil {
+		key, err := newSigningKey(algorithm)
+		if err != nil {
+			return key, err
+		}
+
+		ui.Log(ui.AuthLogger, "auth.new.key",
+			"id", key.ID,
+			"algorithm", key.Algorithm)
+
+		keyring = append(keyring, key)
+		current = &keyring[len(keyring)-1]
+		changed = true
+	}
+
+	if changed {
+		saveKeys()
+	}
+
+	return *current, nil
+}
+
+// findKey returns the signing key with the given key ID.
+func findKey(id string) (signingKey, bool) {
+	keyLock.Lock()
+	defer keyLock.Unlock()
+
+	loadKeys()
+
+	for _, key := range keyring {
+		if key.ID == id {
+			return key, true
+		}
+	}
+
+	return signingKey{}, false
+}
+
+// newSigningKey creates a new random key for the given signing algorithm.
+func newSigningKey(algorithm string) (signingKey, error) {
+	var (
+		private interface{}
+		b       []byte
+		err     error
+	)
+
+	switch algorithm {
+	case "HS256", "HS384", "HS512":
+		b = make([]byte, 64)
+		_, err = rand.Read(b)
+
+	case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+
+	case "ES256":
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	case "EdDSA":
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+
+	default:
+		return signingKey{}, errors.ErrJWTAlgorithm.Context(algorithm)
+	}
+
+	if err == nil && private != nil {
+		b, err = x509.MarshalPKCS8PrivateKey(private)
+	}
+
+	if err != nil {
+		return signingKey{}, errors.New(err)
+	}
+
+	return signingKey{
+		ID:        uuid.New().String(),
+		Algorithm: algorithm,
+		Key:       base64.StdEncoding.EncodeToString(b),
+		Created:   time.Now(),
+	}, nil
+}
+
+// privateKey returns the key used to sign tokens, which is a []byte secret for the
+// HMAC algorithms.
+func (k signingKey) privateKey() (crypto.PrivateKey, error) {
+	b, err := base64.StdEncoding.DecodeString(k.Key)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	if strings.HasPrefix(k.Algorithm, "HS") {
+		return b, nil
+	}
+
+	private, err := x509.ParsePKCS8PrivateKey(b)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	return private, nil
+}
+
+// publicKey returns the key used to validate tokens, which is a []byte secret for the
+// HMAC algorithms.
+func (k signingKey) publicKey() (crypto.PublicKey, error) {
+	private, err := k.privateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if signer, ok := private.(crypto.Signer); ok {
+		return signer.Public(), nil
+	}
+
+	return private, nil
+}
+
+// loadKeys reads the keyring from the settings, if it has changed since it was last
+// read. The caller must hold the key lock.
+func loadKeys() {
+	text := settings.Get(defs.ServerTokenKeysSetting)
+	if keyring != nil && text == keyringSet {
+		return
+	}
+
+	keyring = []signingKey{}
+	keyringSet = text
+
+	if text == "" {
+		return
+	}
+
+	b, err := hex.DecodeString(text)
+	if err == nil {
+		var j string
+
+		if j, err = util.Decrypt(string(b), getTokenKey()); err == nil {
+			err = json.Unmarshal([]byte(j), &keyring)
+		}
+	}
+
+	if err != nil {
+		ui.Log(ui.AuthLogger, "auth.invalid.keys",
+			"error", err)
+	}
+}
+
+// saveKeys stores the keyring in the settings, encrypted using the token key. The
+// caller must hold the key lock.
+func saveKeys() {
+	b, _ := json.Marshal(keyring)
+
+	text, err := util.Encrypt(string(b), getTokenKey())
+	if err != nil {
+		ui.Log(ui.AuthLogger, "auth.invalid.keys",
+			"error", err)
+
+		return
+	}
+
+	keyringSet = hex.EncodeToString([]byte(text))
+
+	settings.Set(defs.ServerTokenKeysSetting, keyringSet)
+	_ = settings.Save()
+}
+
+// issuer returns the issuer of the JWT tokens issued by this server.
+func issuer() string {
+	if name := settings.Get(defs.ServerTokenIssuerSetting); name != "" {
+		return name
+	}
+
+	return defaultIssuer
+}
@@ -0,0 +1,58 @@
+package cipher
+
+import (
+	"strings"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The password hashing algorithms.
+const (
+	bcryptAlgorithm = "bcrypt"
+	argon2Algorithm = "argon2"
+)
+
+// hashPassword implements the cipher.HashPassword() function. It hashes a password
+// using bcrypt, or argon2id if the optional second argument is "argon2". A random
+// salt is used, so hashing the same password twice gives different results. Use
+// cipher.CheckPassword() to test if a password matches the hash.
+func hashPassword(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	password := []byte(data.String(args.Get(0)))
+
+	algorithm := bcryptAlgorithm
+	if args.Len() > 1 {
+		algorithm = strings.ToLower(data.String(args.Get(1)))
+	}
+
+	switch algorithm {
+	case bcryptAlgorithm:
+		b, err := bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
+		if err != nil {
+			return nil, errors.New(err).In("HashPassword")
+		}
+
+		return string(b), nil
+
+	case argon2Algorithm:
+		hash, err := util.HashPassword(string(password))
+		if err != nil {
+			return nil, errors.New(err).In("HashPassword")
+		}
+
+		return hash, nil
+
+	default:
+		return nil, errors.ErrInvalidAlgorithm.In("HashPassword").Context(algorithm)
+	}
+}
+
+// checkPassword implements the cipher.CheckPassword() function. It returns true if
+// the password matches a hash created by cipher.HashPassword(). The algorithm is
+// found from the hash. A hash that is not valid never matches.
+func checkPassword(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	return util.CheckPassword(data.String(args.Get(0)), data.String(args.Get(1))), nil
+}
@@ -0,0 +1,86 @@
+package cipher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+func Test_hashPassword(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   data.List
+		prefix string
+	}{
+		{"default", data.NewList("hunter2"), "$2a$"},
+		{"bcrypt", data.NewList("hunter2", "bcrypt"), "$2a$"},
+		{"argon2", data.NewList("hunter2", "ARGON2"), "$argon2id$v=19$m=65536,t=3,p=4$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := symbols.NewRootSymbolTable("testing")
+
+			hash, err := hashPassword(s, tt.args)
+			if err != nil {
+				t.Fatalf("hashPassword() unexpected error %v", err)
+			}
+
+			if !strings.HasPrefix(data.String(hash), tt.prefix) {
+				t.Errorf("hashPassword() = %v, want prefix %v", hash, tt.prefix)
+			}
+
+			// A second hash of the same password uses a different salt.
+			if again, _ := hashPassword(s, tt.args); again == hash {
+				t.Errorf("hashPassword() returned the same hash twice")
+			}
+
+			if ok, _ := checkPassword(s, data.NewList("hunter2", hash)); ok != true {
+				t.Errorf("checkPassword() of correct password = %v", ok)
+			}
+
+			if ok, _ := checkPassword(s, data.NewList("hunter3", hash)); ok != false {
+				t.Errorf("checkPassword() of wrong password = %v", ok)
+			}
+		})
+	}
+
+	if _, err := hashPassword(symbols.NewRootSymbolTable("testing"), data.NewList("x", "md5")); !errors.Equals(err, errors.ErrInvalidAlgorithm) {
+		t.Errorf("hashPassword() error = %v, want %v", err, errors.ErrInvalidAlgorithm)
+	}
+}
+
+func Test_checkPassword(t *testing.T) {
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		// Hashes of "password", including the argon2id example from the reference
+		// implementation.
+		{"bcrypt", "$2a$04$JU8pNfPaciKRB1MwVRJO6OF3rTawj/ASHdDahydwJ/zvhJPw67E.6", true},
+		{"bcrypt modified", "$2a$04$JU8pNfPaciKRB1MwVRJO6OF3rTawj/ASHdDahydwJ/zvhJPw67E.7", false},
+		{"argon2", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", true},
+		{"argon2 wrong version", "$argon2id$v=16$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", false},
+		{"argon2 missing field", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ", false},
+		{"argon2 bad parameters", "$argon2id$v=19$m=65536,t=2,p=0$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", false},
+		{"argon2 memory too large", "$argon2id$v=19$m=4294967295,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", false},
+		{"argon2 time too large", "$argon2id$v=19$m=65536,t=4294967295,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", false},
+		{"argon2 threads too large", "$argon2id$v=19$m=65536,t=2,p=255$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", false},
+		{"argon2 zero time", "$argon2id$v=19$m=65536,t=0,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", false},
+		{"not a hash", "password", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := checkPassword(symbols.NewRootSymbolTable("testing"), data.NewList("password", tt.hash))
+			if got != tt.want {
+				t.Errorf("checkPassword() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
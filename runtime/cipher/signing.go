@@ -0,0 +1,193 @@
+package cipher
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+// The key algorithms that can be used for signatures.
+const (
+	ed25519Algorithm = "ed25519"
+	rsaAlgorithm     = "rsa"
+
+	// The default size in bits of an RSA key.
+	defaultRSABits = 2048
+)
+
+// The PEM block types of keys.
+const (
+	privateKeyBlock = "PRIVATE KEY"
+	publicKeyBlock  = "PUBLIC KEY"
+)
+
+// generateKey implements the cipher.GenerateKey() function. It creates a new key pair
+// for the "ed25519" or "rsa" algorithm. For RSA, an optional second argument gives the
+// size of the key in bits. The result is the private key in PKCS #8 form and the public
+// key in PKIX form, each encoded as PEM text.
+func generateKey(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var (
+		private crypto.Signer
+		err     error
+	)
+
+	algorithm := strings.ToLower(data.String(args.Get(0)))
+
+	switch algorithm {
+	case ed25519Algorithm:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+
+	case rsaAlgorithm:
+		bits := defaultRSABits
+		if args.Len() > 1 {
+			if bits, err = data.Int(args.Get(1)); err != nil {
+				return nil, errors.New(err).In("GenerateKey")
+			}
+		}
+
+		private, err = rsa.GenerateKey(rand.Reader, bits)
+
+	default:
+		return nil, errors.ErrInvalidAlgorithm.In("GenerateKey").Context(algorithm)
+	}
+
+	if err != nil {
+		return nil, errors.New(err).In("GenerateKey")
+	}
+
+	privateBytes, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, errors.New(err).In("GenerateKey")
+	}
+
+	publicBytes, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, errors.New(err).In("GenerateKey")
+	}
+
+	return data.NewList(
+		string(pem.EncodeToMemory(&pem.Block{Type: privateKeyBlock, Bytes: privateBytes})),
+		string(pem.EncodeToMemory(&pem.Block{Type: publicKeyBlock, Bytes: publicBytes})),
+	), nil
+}
+
+// sign implements the cipher.Sign() function. It signs a string using a private key
+// created by cipher.GenerateKey(). Ed25519 keys create an Ed25519 signature, and RSA
+// keys create a PKCS #1 v1.5 signature of the SHA-256 hash of the string. The result
+// is encoded as hexadecimal, or base64 if the optional third argument is "base64".
+func sign(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var signature []byte
+
+	key, err := parseKey(data.String(args.Get(0)), privateKeyBlock)
+	if err != nil {
+		return nil, errors.New(err).In("Sign")
+	}
+
+	text := []byte(data.String(args.Get(1)))
+
+	switch private := key.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(private, text)
+
+	case *rsa.PrivateKey:
+		sum := sha256.Sum256(text)
+
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, sum[:]); err != nil {
+			return nil, errors.New(err).In("Sign")
+		}
+
+	default:
+		return nil, errors.ErrInvalidKey.In("Sign").Context(fmt.Sprintf("%T", key))
+	}
+
+	return encodeBytes(signature, args, 2)
+}
+
+// verify implements the cipher.Verify() function. It returns true if a signature
+// created by cipher.Sign() is valid for the string, using the public key that matches
+// the private key used to sign it. The signature is hexadecimal, or base64 if the
+// optional fourth argument is "base64".
+func verify(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	key, err := parseKey(data.String(args.Get(0)), publicKeyBlock)
+	if err != nil {
+		return nil, errors.New(err).In("Verify")
+	}
+
+	text := []byte(data.String(args.Get(1)))
+
+	signature, err := decodeBytes(data.String(args.Get(2)), args, 3)
+	if err != nil {
+		return nil, errors.New(err).In("Verify")
+	}
+
+	switch public := key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(public, text, signature), nil
+
+	case *rsa.PublicKey:
+		sum := sha256.Sum256(text)
+
+		return rsa.VerifyPKCS1v15(public, crypto.SHA256, sum[:], signature) == nil, nil
+
+	default:
+		return nil, errors.ErrInvalidKey.In("Verify").Context(fmt.Sprintf("%T", key))
+	}
+}
+
+// parseKey parses a private or public key from PEM text, depending on the block type.
+func parseKey(text, blockType string) (interface{}, error) {
+	var (
+		key interface{}
+		err error
+	)
+
+	block, _ := pem.Decode([]byte(text))
+	if block == nil || block.Type != blockType {
+		return nil, errors.ErrInvalidKey.Context(blockType)
+	}
+
+	if blockType == privateKeyBlock {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+
+	if err != nil {
+		return nil, errors.ErrInvalidKey.Context(err.Error())
+	}
+
+	return key, nil
+}
+
+// encodePEM implements the cipher.EncodePEM() function, which encodes a string as a
+// PEM block of the given type, such as "CERTIFICATE".
+func encodePEM(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	block := &pem.Block{
+		Type:  data.String(args.Get(0)),
+		Bytes: []byte(data.String(args.Get(1))),
+	}
+
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+// decodePEM implements the cipher.DecodePEM() function. It returns the type and the
+// decoded contents of the first PEM block in the text. It is an error if there is no
+// PEM block in the text.
+func decodePEM(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	block, _ := pem.Decode([]byte(data.String(args.Get(0))))
+	if block == nil {
+		return nil, errors.ErrInvalidPEM.In("DecodePEM")
+	}
+
+	return data.NewList(block.Type, string(block.Bytes)), nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/egostrings"
 	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/jwt"
 	"github.com/tucats/ego/symbols"
 	"github.com/tucats/ego/util"
 )
@@ -35,7 +36,6 @@ func validate(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 	var (
 		err       error
 		reportErr bool
-		t         = authToken{}
 	)
 
 	if args.Len() > 1 {
@@ -45,30 +45,7 @@ func validate(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 		}
 	}
 
-	// Take the token value, and decode the hex string.
-	b, err := hex.DecodeString(data.String(args.Get(0)))
-	if err != nil {
-		ui.Log(ui.AuthLogger, "auth.invalid.encoding",
-			"error", err)
-
-		if reportErr {
-			return false, errors.New(err)
-		}
-
-		return false, nil
-	}
-
-	// Decrypt the token into a json string
-	key := getTokenKey()
-
-	j, err := util.Decrypt(string(b), key)
-	if err != nil || len(j) == 0 {
-		ui.Log(ui.AuthLogger, "auth.invalid.decryption",
-			"error", err)
-
-		err = errors.ErrInvalidTokenEncryption.In("Validate")
-	}
-
+	t, err := decode(data.String(args.Get(0)))
 	if err != nil {
 		if reportErr {
 			return false, errors.New(err)
@@ -77,17 +54,6 @@ func validate(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 		return false, nil
 	}
 
-	if err = json.Unmarshal([]byte(j), &t); err != nil {
-		ui.Log(ui.AuthLogger, "auth.invalid.json",
-			"error", err)
-
-		if reportErr {
-			return false, errors.New(err)
-		}
-
-		return false, nil
-	}
-
 	// Has the expiration passed?
 	d := time.Since(t.Expires)
 	if d.Seconds() > 0 {
@@ -115,40 +81,8 @@ func validate(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 
 // extract extracts the data from a token and returns it as a struct.
 func extract(s *symbols.SymbolTable, args data.List) (interface{}, error) {
-	var (
-		err error
-		t   = authToken{}
-	)
-
-	// Take the token value, and decode the hex string.
-	b, err := hex.DecodeString(data.String(args.Get(0)))
+	t, err := decode(data.String(args.Get(0)))
 	if err != nil {
-		ui.Log(ui.AuthLogger, "auth.invalid.encoding",
-			"error", err)
-
-		return nil, errors.New(err)
-	}
-
-	// Decrypt the token into a json string. We use the token key stored in
-	// the preferences data. If there isn't one, generate a new random key.
-	key := getTokenKey()
-
-	j, err := util.Decrypt(string(b), key)
-	if err != nil {
-		ui.Log(ui.AuthLogger, "auth.invalid.decrypt",
-			"error", err)
-
-		return nil, errors.New(err)
-	}
-
-	if len(j) == 0 {
-		return nil, errors.ErrInvalidTokenEncryption.In("Extract")
-	}
-
-	if err = json.Unmarshal([]byte(j), &t); err != nil {
-		ui.Log(ui.AuthLogger, "auth.invalid.json",
-			"error", err)
-
 		return nil, errors.New(err)
 	}
 
@@ -181,6 +115,51 @@ func extract(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 	return data.NewStructFromMap(r), err
 }
 
+// decode converts the text of a token to a token object. The token can be an
+// encrypted Ego token or a JWT issued by this server. The expiration of the
+// token is not checked.
+func decode(text string) (authToken, error) {
+	t := authToken{}
+
+	if jwt.IsJWT(text) {
+		return decodeJWT(text)
+	}
+
+	// Take the token value, and decode the hex string.
+	b, err := hex.DecodeString(text)
+	if err != nil {
+		ui.Log(ui.AuthLogger, "auth.invalid.encoding",
+			"error", err)
+
+		return t, err
+	}
+
+	// Decrypt the token into a json string. We use the token key stored in
+	// the preferences data. If there isn't one, generate a new random key.
+	j, err := util.Decrypt(string(b), getTokenKey())
+	if err != nil || len(j) == 0 {
+		ui.Log(ui.AuthLogger, "auth.invalid.decryption",
+			"error", err)
+
+		return t, errors.ErrInvalidTokenEncryption
+	}
+
+	if err = json.Unmarshal([]byte(j), &t); err != nil {
+		ui.Log(ui.AuthLogger, "auth.invalid.json",
+			"error", err)
+	}
+
+	return t, err
+}
+
+// TokenInfo returns the unique ID, the user name, and the expiration time of a
+// token issued by this server. The expiration of the token is not checked.
+func TokenInfo(text string) (uuid.UUID, string, time.Time, error) {
+	t, err := decode(text)
+
+	return t.TokenID, t.Name, t.Expires, err
+}
+
 // newToken creates a new token with a username and a data payload.
 func newToken(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 	var (
@@ -255,6 +234,12 @@ func newToken(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 		"user", t.Name,
 		"expires", util.FormatDuration(time.Until(t.Expires), true))
 
+	// If the server is configured to issue JWTs, sign the token as a JWT
+	// instead of encrypting it.
+	if strings.EqualFold(settings.Get(defs.ServerTokenFormatSetting), JWTFormat) {
+		return newJWT(t)
+	}
+
 	// Make the token into a json string
 	b, err := json.Marshal(t)
 	if err != nil {
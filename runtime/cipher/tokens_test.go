@@ -1,9 +1,15 @@
 package cipher
 
 import (
+	"encoding/base64"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/tucats/ego/app-cli/settings"
 	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/jwt"
 	"github.com/tucats/ego/symbols"
 )
 
@@ -61,3 +67,82 @@ func TestTokens(t *testing.T) {
 		}
 	}
 }
+
+func TestTokens_JWT(t *testing.T) {
+	settings.SetDefault(defs.ServerTokenFormatSetting, JWTFormat)
+
+	defer func() {
+		settings.SetDefault(defs.ServerTokenFormatSetting, "")
+		settings.SetDefault(defs.ServerTokenAlgorithmSetting, "")
+		settings.SetDefault(defs.ServerTokenKeysSetting, "")
+	}()
+
+	s := symbols.NewSymbolTable("test")
+	tokens := map[string]string{}
+
+	for _, algorithm := range []string{"HS256", "RS256", "EdDSA"} {
+		settings.SetDefault(defs.ServerTokenAlgorithmSetting, algorithm)
+
+		token, err := newToken(s, data.NewList("user", "data"))
+		if err != nil {
+			t.Fatalf("newToken(%s) failed: %v", algorithm, err)
+		}
+
+		text := data.String(token)
+		if !jwt.IsJWT(text) || !IsServerJWT(text) {
+			t.Fatalf("newToken(%s) did not return a JWT: %s", algorithm, text)
+		}
+
+		parsed, _ := jwt.Parse(text)
+		if parsed.Header.Algorithm != algorithm || parsed.Claims.String("sub") != "user" || parsed.Claims.String("jti") == "" {
+			t.Errorf("newToken(%s) claims = %v", algorithm, parsed.Claims)
+		}
+
+		tokens[algorithm] = text
+	}
+
+	// Tokens signed by the keys that were replaced when the algorithm changed are
+	// still valid.
+	for algorithm, text := range tokens {
+		if valid, err := validate(s, data.NewList(text, true)); err != nil || !data.BoolOrFalse(valid) {
+			t.Errorf("validate(%s) = %v, %v", algorithm, valid, err)
+		}
+
+		extracted, err := extract(s, data.NewList(text))
+		if err != nil {
+			t.Fatalf("extract(%s) failed: %v", algorithm, err)
+		}
+
+		if name, _ := extracted.(*data.Struct).Get("Name"); name != "user" {
+			t.Errorf("extract(%s) name = %v", algorithm, name)
+		}
+	}
+
+	// Only the public keys are published, so there is no key for HS256.
+	keys := PublicKeys()
+	if len(keys.Keys) != 2 {
+		t.Errorf("PublicKeys() = %v", keys)
+	}
+
+	// A token with a changed payload is not valid.
+	parts := strings.Split(tokens["RS256"], ".")
+	forged := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"ego","sub":"admin","exp":9999999999}`)) + "." + parts[2]
+
+	if valid, _ := validate(s, data.NewList(forged)); data.BoolOrFalse(valid) {
+		t.Error("validate() accepted a forged token")
+	}
+
+	// Once a retired key is no longer needed, it is removed and the tokens it signed
+	// are no longer valid.
+	keyLock.Lock()
+	keyring[0].Retired = time.Now().Add(-48 * time.Hour)
+	keyLock.Unlock()
+
+	if _, err := currentKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	if valid, _ := validate(s, data.NewList(tokens["HS256"])); data.BoolOrFalse(valid) {
+		t.Error("validate() accepted a token signed by a removed key")
+	}
+}
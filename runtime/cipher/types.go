@@ -127,6 +127,252 @@ func Initialize(s *symbols.SymbolTable) {
 				},
 				Value: hash,
 			},
+			"MD5": data.Function{
+				Declaration: &data.Declaration{
+					Name: "MD5",
+					Parameters: []data.Parameter{
+						{
+							Name: "text",
+							Type: data.StringType,
+						},
+						{
+							Name: "encoding",
+							Type: data.StringType,
+						},
+					},
+					Returns:  []*data.Type{data.StringType},
+					ArgCount: data.Range{1, 2},
+				},
+				Value: digest("md5"),
+			},
+			"SHA1": data.Function{
+				Declaration: &data.Declaration{
+					Name: "SHA1",
+					Parameters: []data.Parameter{
+						{
+							Name: "text",
+							Type: data.StringType,
+						},
+						{
+							Name: "encoding",
+							Type: data.StringType,
+						},
+					},
+					Returns:  []*data.Type{data.StringType},
+					ArgCount: data.Range{1, 2},
+				},
+				Value: digest("sha1"),
+			},
+			"SHA256": data.Function{
+				Declaration: &data.Declaration{
+					Name: "SHA256",
+					Parameters: []data.Parameter{
+						{
+							Name: "text",
+							Type: data.StringType,
+						},
+						{
+							Name: "encoding",
+							Type: data.StringType,
+						},
+					},
+					Returns:  []*data.Type{data.StringType},
+					ArgCount: data.Range{1, 2},
+				},
+				Value: digest("sha256"),
+			},
+			"SHA512": data.Function{
+				Declaration: &data.Declaration{
+					Name: "SHA512",
+					Parameters: []data.Parameter{
+						{
+							Name: "text",
+							Type: data.StringType,
+						},
+						{
+							Name: "encoding",
+							Type: data.StringType,
+						},
+					},
+					Returns:  []*data.Type{data.StringType},
+					ArgCount: data.Range{1, 2},
+				},
+				Value: digest("sha512"),
+			},
+			"HMAC": data.Function{
+				Declaration: &data.Declaration{
+					Name: "HMAC",
+					Parameters: []data.Parameter{
+						{
+							Name: "algorithm",
+							Type: data.StringType,
+						},
+						{
+							Name: "key",
+							Type: data.StringType,
+						},
+						{
+							Name: "text",
+							Type: data.StringType,
+						},
+						{
+							Name: "encoding",
+							Type: data.StringType,
+						},
+					},
+					Returns:  []*data.Type{data.StringType},
+					ArgCount: data.Range{3, 4},
+				},
+				Value: hmacHash,
+			},
+			"Equal": data.Function{
+				Declaration: &data.Declaration{
+					Name: "Equal",
+					Parameters: []data.Parameter{
+						{
+							Name: "a",
+							Type: data.StringType,
+						},
+						{
+							Name: "b",
+							Type: data.StringType,
+						},
+					},
+					Returns: []*data.Type{data.BoolType},
+				},
+				Value: equal,
+			},
+			"HashPassword": data.Function{
+				Declaration: &data.Declaration{
+					Name: "HashPassword",
+					Parameters: []data.Parameter{
+						{
+							Name: "password",
+							Type: data.StringType,
+						},
+						{
+							Name: "algorithm",
+							Type: data.StringType,
+						},
+					},
+					Returns:  []*data.Type{data.StringType},
+					ArgCount: data.Range{1, 2},
+				},
+				Value: hashPassword,
+			},
+			"CheckPassword": data.Function{
+				Declaration: &data.Declaration{
+					Name: "CheckPassword",
+					Parameters: []data.Parameter{
+						{
+							Name: "password",
+							Type: data.StringType,
+						},
+						{
+							Name: "hash",
+							Type: data.StringType,
+						},
+					},
+					Returns: []*data.Type{data.BoolType},
+				},
+				Value: checkPassword,
+			},
+			"GenerateKey": data.Function{
+				Declaration: &data.Declaration{
+					Name: "GenerateKey",
+					Parameters: []data.Parameter{
+						{
+							Name: "algorithm",
+							Type: data.StringType,
+						},
+						{
+							Name: "bits",
+							Type: data.IntType,
+						},
+					},
+					Returns:  []*data.Type{data.StringType, data.StringType},
+					ArgCount: data.Range{1, 2},
+				},
+				Value: generateKey,
+			},
+			"Sign": data.Function{
+				Declaration: &data.Declaration{
+					Name: "Sign",
+					Parameters: []data.Parameter{
+						{
+							Name: "privateKey",
+							Type: data.StringType,
+						},
+						{
+							Name: "text",
+							Type: data.StringType,
+						},
+						{
+							Name: "encoding",
+							Type: data.StringType,
+						},
+					},
+					Returns:  []*data.Type{data.StringType},
+					ArgCount: data.Range{2, 3},
+				},
+				Value: sign,
+			},
+			"Verify": data.Function{
+				Declaration: &data.Declaration{
+					Name: "Verify",
+					Parameters: []data.Parameter{
+						{
+							Name: "publicKey",
+							Type: data.StringType,
+						},
+						{
+							Name: "text",
+							Type: data.StringType,
+						},
+						{
+							Name: "signature",
+							Type: data.StringType,
+						},
+						{
+							Name: "encoding",
+							Type: data.StringType,
+						},
+					},
+					Returns:  []*data.Type{data.BoolType},
+					ArgCount: data.Range{3, 4},
+				},
+				Value: verify,
+			},
+			"EncodePEM": data.Function{
+				Declaration: &data.Declaration{
+					Name: "EncodePEM",
+					Parameters: []data.Parameter{
+						{
+							Name: "blockType",
+							Type: data.StringType,
+						},
+						{
+							Name: "data",
+							Type: data.StringType,
+						},
+					},
+					Returns: []*data.Type{data.StringType},
+				},
+				Value: encodePEM,
+			},
+			"DecodePEM": data.Function{
+				Declaration: &data.Declaration{
+					Name: "DecodePEM",
+					Parameters: []data.Parameter{
+						{
+							Name: "text",
+							Type: data.StringType,
+						},
+					},
+					Returns: []*data.Type{data.StringType, data.StringType},
+				},
+				Value: decodePEM,
+			},
 			"Random": data.Function{
 				Declaration: &data.Declaration{
 					Name: "Random",
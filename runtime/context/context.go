@@ -0,0 +1,258 @@
+package context
+
+import (
+	"context"
+	"time"
+
+	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	egotime "github.com/tucats/ego/runtime/time"
+	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
+)
+
+// valueKey is the type used for keys stored in a context by WithValue(). Using
+// a private type prevents collisions with keys set by native Go code.
+type valueKey struct {
+	key interface{}
+}
+
+// New creates an Ego context.Context value for a native Go context.
+func New(ctx context.Context) *data.Struct {
+	if contextType == nil {
+		Initialize(&symbols.RootSymbolTable)
+	}
+
+	result := data.NewStruct(contextType).FromBuiltinPackage()
+	result.SetAlways(contextFieldName, ctx)
+	result.SetAlways(doneFieldName, nil)
+	result.SetReadonly(true)
+
+	return result
+}
+
+// Value returns the native Go context for an Ego context.Context value. The
+// boolean result is false if the value is not a context.
+func Value(v interface{}) (context.Context, bool) {
+	if pointer, ok := v.(*interface{}); ok && pointer != nil {
+		v = *pointer
+	}
+
+	s, ok := v.(*data.Struct)
+	if !ok || s == nil {
+		return nil, false
+	}
+
+	ctx, ok := s.GetAlways(contextFieldName).(context.Context)
+
+	return ctx, ok
+}
+
+// Background implements context.Background(), which returns a context that
+// is never cancelled and has no deadline or values.
+func background(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	return New(context.Background()), nil
+}
+
+// WithCancel implements context.WithCancel(parent), which returns a new context
+// and a function that cancels it.
+func withCancel(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	parent, err := parentContext(args.Get(0))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+
+	return data.NewList(New(ctx), cancelFunction(cancel)), nil
+}
+
+// WithTimeout implements context.WithTimeout(parent, timeout), which returns a new
+// context that is cancelled when the timeout has elapsed, and a function that
+// cancels it. The timeout can be a time.Duration, a duration string such as "5s",
+// or an integer number of milliseconds.
+func withTimeout(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	parent, err := parentContext(args.Get(0))
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := duration(args.Get(1))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(parent, timeout)
+
+	return data.NewList(New(ctx), cancelFunction(cancel)), nil
+}
+
+// WithDeadline implements context.WithDeadline(parent, t), which returns a new
+// context that is cancelled at the given time.Time, and a function that cancels it.
+func withDeadline(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	parent, err := parentContext(args.Get(0))
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, err := data.GetNativeTime(args.Get(1))
+	if err != nil {
+		return nil, errors.ErrArgumentType.In("WithDeadline").Context(args.Get(1))
+	}
+
+	ctx, cancel := context.WithDeadline(parent, *deadline)
+
+	return data.NewList(New(ctx), cancelFunction(cancel)), nil
+}
+
+// WithValue implements context.WithValue(parent, key, value), which returns a
+// new context that includes the key and value.
+func withValue(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	parent, err := parentContext(args.Get(0))
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Get(1) == nil {
+		return nil, errors.ErrNilPointerReference.In("WithValue")
+	}
+
+	return New(context.WithValue(parent, valueKey{key: args.Get(1)}, args.Get(2))), nil
+}
+
+// Done implements the Done() method of a context, which returns a channel that is
+// closed when the context is cancelled. Receiving from the channel blocks until
+// then. The channel is created the first time it is needed and shared by all
+// callers.
+func done(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	this, ctx, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	initLock.Lock()
+	defer initLock.Unlock()
+
+	if ch, ok := this.GetAlways(doneFieldName).(*data.Channel); ok {
+		return ch, nil
+	}
+
+	ch := data.NewSignalChannel()
+	this.SetAlways(doneFieldName, ch)
+
+	// A context that can never be cancelled, such as the background context,
+	// returns a channel that is never closed.
+	if ctx.Done() != nil {
+		go func() {
+			<-ctx.Done()
+			ch.Close()
+		}()
+	}
+
+	return ch, nil
+}
+
+// Err implements the Err() method of a context, which returns nil if the context
+// is not done, or an error describing why it was cancelled.
+func contextErr(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	_, ctx, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytecode.ContextError(ctx), nil
+}
+
+// Deadline implements the Deadline() method of a context, which returns the time
+// the context will be cancelled, and a flag indicating if there is a deadline.
+func deadline(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	_, ctx, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := ctx.Deadline()
+
+	return data.NewList(data.NewStruct(egotime.GetTimeType(s)).SetNative(t), ok), nil
+}
+
+// Value implements the Value(key) method of a context, which returns the value
+// stored with the key by WithValue(), or nil if there is no such value.
+func value(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	_, ctx, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.Value(valueKey{key: args.Get(0)}), nil
+}
+
+// cancelFunction creates an Ego function value that calls a native cancel function.
+func cancelFunction(cancel context.CancelFunc) data.Function {
+	return data.Function{
+		Declaration: &data.Declaration{
+			Name:    "cancel",
+			Returns: []*data.Type{data.VoidType},
+		},
+		Value: func(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+			cancel()
+
+			return nil, nil
+		},
+	}
+}
+
+// parentContext returns the native Go context for the parent context argument.
+func parentContext(v interface{}) (context.Context, error) {
+	if ctx, ok := Value(v); ok {
+		return ctx, nil
+	}
+
+	return nil, errors.ErrInvalidContext.Context(data.TypeOf(v).String())
+}
+
+// duration converts a timeout argument to a native duration.
+func duration(v interface{}) (time.Duration, error) {
+	switch actual := v.(type) {
+	case string:
+		d, err := util.ParseDuration(actual)
+		if err != nil {
+			return 0, errors.New(err)
+		}
+
+		return d, nil
+
+	case int, int32, int64:
+		return time.Duration(data.Int64OrZero(actual)) * time.Millisecond, nil
+	}
+
+	d, err := data.GetNativeDuration(v)
+	if err != nil {
+		return 0, errors.ErrArgumentType.Context(data.TypeOf(v).String())
+	}
+
+	return *d, nil
+}
+
+// getThis returns the receiver of a context method, and the native Go context
+// it contains.
+func getThis(s *symbols.SymbolTable) (*data.Struct, context.Context, error) {
+	v, ok := s.Get(defs.ThisVariable)
+	if !ok {
+		return nil, nil, errors.ErrNoFunctionReceiver
+	}
+
+	this, ok := v.(*data.Struct)
+	if !ok {
+		return nil, nil, errors.ErrInvalidContext
+	}
+
+	ctx, ok := this.GetAlways(contextFieldName).(context.Context)
+	if !ok {
+		return nil, nil, errors.ErrInvalidContext
+	}
+
+	return this, ctx, nil
+}
@@ -0,0 +1,117 @@
+package context
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+func TestWithCancel(t *testing.T) {
+	s := symbols.NewSymbolTable("test")
+	Initialize(s)
+
+	result, err := withCancel(s, data.NewList(New(context.Background())))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	list := result.(data.List)
+	ctx := list.Get(0).(*data.Struct)
+	cancel := list.Get(1).(data.Function)
+
+	s.SetAlways(defs.ThisVariable, ctx)
+
+	if e, _ := contextErr(s, data.NewList()); e != nil {
+		t.Errorf("Unexpected context error before cancel: %v", e)
+	}
+
+	ch, _ := done(s, data.NewList())
+
+	_, _ = cancel.Value.(func(*symbols.SymbolTable, data.List) (interface{}, error))(s, data.NewList())
+
+	if _, err := ch.(*data.Channel).Receive(); err != nil {
+		t.Errorf("Unexpected error receiving from done channel: %v", err)
+	}
+
+	e, _ := contextErr(s, data.NewList())
+	if !errors.Equals(e.(error), errors.ErrContextCanceled) {
+		t.Errorf("Expected %v, got %v", errors.ErrContextCanceled, e)
+	}
+}
+
+func TestWithValue(t *testing.T) {
+	s := symbols.NewSymbolTable("test")
+	Initialize(s)
+
+	result, err := withValue(s, data.NewList(New(context.Background()), "user", "admin"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	s.SetAlways(defs.ThisVariable, result)
+
+	if v, _ := value(s, data.NewList("user")); v != "admin" {
+		t.Errorf("Expected admin, got %v", v)
+	}
+
+	if v, _ := value(s, data.NewList("role")); v != nil {
+		t.Errorf("Expected nil, got %v", v)
+	}
+
+	if _, err := withValue(s, data.NewList("not a context", "user", "admin")); !errors.Equals(err, errors.ErrInvalidContext) {
+		t.Errorf("Expected %v, got %v", errors.ErrInvalidContext, err)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name:  "duration string",
+			value: "1m30s",
+			want:  90 * time.Second,
+		},
+		{
+			name:  "milliseconds",
+			value: 250,
+			want:  250 * time.Millisecond,
+		},
+		{
+			name:  "native duration",
+			value: 5 * time.Second,
+			want:  5 * time.Second,
+		},
+		{
+			name:    "invalid string",
+			value:   "soon",
+			wantErr: true,
+		},
+		{
+			name:    "invalid type",
+			value:   true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := duration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("duration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("duration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package context
+
+import (
+	"sync"
+
+	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/compiler"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/symbols"
+)
+
+// context.Context type specification. The native Go context is stored in the
+// ctx field, and the done field holds the Ego channel returned by Done(), which
+// is created when it is first needed.
+const contextTypeSpec = `
+type Context struct {
+	ctx		interface{},
+	done	interface{},
+}`
+
+const (
+	contextFieldName = "ctx"
+	doneFieldName    = "done"
+)
+
+var contextType *data.Type
+var cancelFuncType *data.Type
+var initLock sync.Mutex
+
+func Initialize(s *symbols.SymbolTable) {
+	initLock.Lock()
+	defer initLock.Unlock()
+
+	if contextType == nil {
+		t, _ := compiler.CompileTypeSpec(contextTypeSpec, nil)
+
+		t.DefineFunction("Done", &data.Declaration{
+			Name:    "Done",
+			Type:    t,
+			Returns: []*data.Type{data.ChanType},
+		}, done)
+
+		t.DefineFunction("Err", &data.Declaration{
+			Name:    "Err",
+			Type:    t,
+			Returns: []*data.Type{data.ErrorType},
+		}, contextErr)
+
+		t.DefineFunction("Deadline", &data.Declaration{
+			Name:    "Deadline",
+			Type:    t,
+			Returns: []*data.Type{data.InterfaceType, data.BoolType},
+		}, deadline)
+
+		t.DefineFunction("Value", &data.Declaration{
+			Name: "Value",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "key",
+					Type: data.InterfaceType,
+				},
+			},
+			Returns: []*data.Type{data.InterfaceType},
+		}, value)
+
+		contextType = t.SetPackage("context")
+
+		cancelFuncType = data.FunctionType(&data.Function{
+			Declaration: &data.Declaration{
+				Name:    "CancelFunc",
+				Returns: []*data.Type{data.VoidType},
+			},
+		})
+	}
+
+	if _, found := s.Root().Get("context"); !found {
+		newpkg := data.NewPackageFromMap("context", map[string]interface{}{
+			"Background": data.Function{
+				Declaration: &data.Declaration{
+					Name:    "Background",
+					Returns: []*data.Type{contextType},
+				},
+				Value: background,
+			},
+			"WithCancel": data.Function{
+				Declaration: &data.Declaration{
+					Name: "WithCancel",
+					Parameters: []data.Parameter{
+						{
+							Name: "parent",
+							Type: contextType,
+						},
+					},
+					Returns: []*data.Type{contextType, cancelFuncType},
+				},
+				Value: withCancel,
+			},
+			"WithTimeout": data.Function{
+				Declaration: &data.Declaration{
+					Name: "WithTimeout",
+					Parameters: []data.Parameter{
+						{
+							Name: "parent",
+							Type: contextType,
+						},
+						{
+							Name: "timeout",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{contextType, cancelFuncType},
+				},
+				Value: withTimeout,
+			},
+			"WithDeadline": data.Function{
+				Declaration: &data.Declaration{
+					Name: "WithDeadline",
+					Parameters: []data.Parameter{
+						{
+							Name: "parent",
+							Type: contextType,
+						},
+						{
+							Name: "deadline",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{contextType, cancelFuncType},
+				},
+				Value: withDeadline,
+			},
+			"WithValue": data.Function{
+				Declaration: &data.Declaration{
+					Name: "WithValue",
+					Parameters: []data.Parameter{
+						{
+							Name: "parent",
+							Type: contextType,
+						},
+						{
+							Name: "key",
+							Type: data.InterfaceType,
+						},
+						{
+							Name: "value",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{contextType},
+				},
+				Value: withValue,
+			},
+			"Context": contextType,
+		})
+
+		pkg, _ := bytecode.GetPackage(newpkg.Name)
+		pkg.Merge(newpkg)
+		s.Root().SetAlways(newpkg.Name, newpkg)
+	}
+}
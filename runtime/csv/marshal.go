@@ -0,0 +1,341 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+// marshal writes CSV text from an array of structures, maps, or records, using
+// the default options of a writer. See encodeRecords() for how the values are
+// converted to records.
+func marshal(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var text []byte
+
+	records, err := encodeRecords(args.Get(0))
+	if err == nil {
+		var b strings.Builder
+
+		for _, record := range records {
+			b.WriteString(formatRecord(record, ',', false))
+			b.WriteString("\n")
+		}
+
+		text = []byte(b.String())
+	} else {
+		err = errors.New(err).In("Marshal")
+	}
+
+	return data.NewList(data.NewArray(data.ByteType, 0).Append(text), err), err
+}
+
+// unmarshal reads a byte array or string as CSV text, using the default options
+// of a reader. If there is no model, the result is an array of the records. If
+// there is a pointer to a model array, the records are stored in it. See
+// storeRecords() for how the records are converted to the type of the array.
+func unmarshal(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var text []byte
+
+	if a, ok := args.Get(0).(*data.Array); ok && a.Type().Kind() == data.ByteKind {
+		text = a.GetBytes()
+	} else {
+		text = []byte(data.String(args.Get(0)))
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(text)).ReadAll()
+	if err != nil {
+		err = errors.New(err).In("Unmarshal")
+
+		return data.NewList(nil, err), err
+	}
+
+	if args.Len() < 2 {
+		return data.NewList(recordsArray(records), nil), nil
+	}
+
+	if err := storeRecords(records, args.Get(1)); err != nil {
+		return data.NewList(errors.New(err).In("Unmarshal")), nil
+	}
+
+	return data.NewList(nil), nil
+}
+
+// encodeRecords converts an array of values to a list of records. For an array of
+// structures, the first record is a header with the names of the fields, and each
+// structure is written as a record of the field values. The csv tags of the
+// structure type can rename a field, or omit it with "-". For an array of maps,
+// the header has the keys of the first map, and each map is written as a record
+// of the values for those keys. An array of arrays is written as is.
+func encodeRecords(v interface{}) ([][]string, error) {
+	if pointer, ok := v.(*interface{}); ok && pointer != nil {
+		v = *pointer
+	}
+
+	a, ok := v.(*data.Array)
+	if !ok {
+		return nil, errors.ErrInvalidType.Context(data.TypeOf(v).String())
+	}
+
+	if a.Len() == 0 {
+		return nil, nil
+	}
+
+	first, _ := a.Get(0)
+	records := [][]string{}
+
+	switch item := first.(type) {
+	case *data.Struct:
+		fields, header := structColumns(item)
+		records = append(records, header)
+
+		for i := 0; i < a.Len(); i++ {
+			element, _ := a.Get(i)
+
+			s, ok := element.(*data.Struct)
+			if !ok {
+				return nil, errors.ErrInvalidType.Context(data.TypeOf(element).String())
+			}
+
+			record := make([]string, len(fields))
+			for n, field := range fields {
+				record[n] = fieldString(s.GetAlways(field))
+			}
+
+			records = append(records, record)
+		}
+
+	case *data.Map:
+		keys := item.Keys()
+		header := make([]string, len(keys))
+
+		for n, key := range keys {
+			header[n] = data.String(key)
+		}
+
+		records = append(records, header)
+
+		for i := 0; i < a.Len(); i++ {
+			element, _ := a.Get(i)
+
+			m, ok := element.(*data.Map)
+			if !ok {
+				return nil, errors.ErrInvalidType.Context(data.TypeOf(element).String())
+			}
+
+			record := make([]string, len(keys))
+			for n, key := range keys {
+				value, _, _ := m.Get(key)
+				record[n] = fieldString(value)
+			}
+
+			records = append(records, record)
+		}
+
+	case *data.Array:
+		for i := 0; i < a.Len(); i++ {
+			element, _ := a.Get(i)
+			records = append(records, stringList(element))
+		}
+
+	default:
+		return nil, errors.ErrInvalidType.Context(data.TypeOf(first).String())
+	}
+
+	return records, nil
+}
+
+// storeRecords converts a list of records to the element type of the array that
+// the value points to, and stores the resulting array. For an array of structures
+// or maps, the first record is a header with the column names. Each structure
+// field is read from the column with the name in its csv tag, or with the same
+// name as the field, ignoring case. Each map has the column names as keys. An
+// array of interfaces is read as an array of map[string]string. An array of
+// arrays is read as is, including the first record.
+func storeRecords(records [][]string, v interface{}) error {
+	pointer, ok := v.(*interface{})
+	if !ok || pointer == nil {
+		return errors.ErrInvalidPointerType
+	}
+
+	target, ok := (*pointer).(*data.Array)
+	if !ok {
+		return errors.ErrInvalidType.Context(data.TypeOf(*pointer).String())
+	}
+
+	elementType := target.Type()
+
+	if _, ok := data.InstanceOfType(elementType).(*data.Array); ok {
+		*pointer = recordsArray(records)
+
+		return nil
+	}
+
+	if elementType.IsInterface() {
+		elementType = data.MapType(data.StringType, data.StringType)
+	}
+
+	if len(records) == 0 {
+		*pointer = data.NewArray(elementType, 0)
+
+		return nil
+	}
+
+	header, rows := records[0], records[1:]
+	result := data.NewArray(elementType, len(rows))
+
+	for i, row := range rows {
+		element := data.InstanceOfType(elementType)
+
+		switch item := element.(type) {
+		case *data.Struct:
+			if err := storeStruct(item, header, row); err != nil {
+				return err
+			}
+
+		case *data.Map:
+			if err := storeMap(item, header, row); err != nil {
+				return err
+			}
+
+		default:
+			return errors.ErrInvalidType.Context(elementType.String())
+		}
+
+		if err := result.Set(i, element); err != nil {
+			return err
+		}
+	}
+
+	*pointer = result
+
+	return nil
+}
+
+// storeStruct stores the values of a record in the fields of a structure, using
+// the header to find the column for each field. Empty values are not stored, so
+// the field keeps its zero value.
+func storeStruct(item *data.Struct, header, row []string) error {
+	fields, names := structColumns(item)
+
+	for n, field := range fields {
+		column := columnIndex(header, names[n])
+		if column < 0 || column >= len(row) || row[column] == "" {
+			continue
+		}
+
+		value, err := data.Coerce(row[column], item.GetAlways(field))
+		if err != nil {
+			return err
+		}
+
+		if err := item.Set(field, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// storeMap stores the values of a record in a map, using the column names in the
+// header as the keys.
+func storeMap(item *data.Map, header, row []string) error {
+	for column, name := range header {
+		if column >= len(row) {
+			break
+		}
+
+		key, err := data.Coerce(name, data.InstanceOfType(item.KeyType()))
+		if err != nil {
+			return err
+		}
+
+		var value interface{} = row[column]
+
+		if !item.ElementType().IsInterface() {
+			if value, err = data.Coerce(value, data.InstanceOfType(item.ElementType())); err != nil {
+				return err
+			}
+		}
+
+		if _, err := item.Set(key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// structColumns returns the list of fields of a structure that are read or
+// written, and the list of their column names.
+func structColumns(s *data.Struct) ([]string, []string) {
+	fields := []string{}
+	names := []string{}
+	t := s.Type()
+
+	for _, field := range s.FieldNames(false) {
+		name := field
+
+		if tag, found := t.FieldTagValue(field, "csv"); found {
+			if tag == "-" {
+				continue
+			}
+
+			if tagName, _ := data.ParseTagValue(tag); tagName != "" {
+				name = tagName
+			}
+		}
+
+		fields = append(fields, field)
+		names = append(names, name)
+	}
+
+	return fields, names
+}
+
+// columnIndex returns the position of the named column in the header. A column
+// with the exact name is preferred over one that differs only by case. If there
+// is no such column, the result is -1.
+func columnIndex(header []string, name string) int {
+	for n, column := range header {
+		if column == name {
+			return n
+		}
+	}
+
+	for n, column := range header {
+		if strings.EqualFold(column, name) {
+			return n
+		}
+	}
+
+	return -1
+}
+
+// fieldString returns the text of a value written as a field of a record.
+func fieldString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+
+	return data.String(v)
+}
+
+// recordArray converts a record to an Ego array of strings.
+func recordArray(record []string) *data.Array {
+	return data.NewArrayFromStrings(record...)
+}
+
+// recordsArray converts a list of records to an Ego array of string arrays.
+func recordsArray(records [][]string) *data.Array {
+	result := data.NewArray(data.ArrayType(data.StringType), len(records))
+
+	for i, record := range records {
+		_ = result.Set(i, recordArray(record))
+	}
+
+	return result
+}
@@ -0,0 +1,142 @@
+package csv
+
+import (
+	"testing"
+
+	"github.com/tucats/ego/data"
+)
+
+// personType returns a named structure type with csv tags.
+func personType() *data.Type {
+	base := data.StructureType(
+		data.Field{Name: "Name", Type: data.StringType},
+		data.Field{Name: "Age", Type: data.IntType},
+		data.Field{Name: "Secret", Type: data.StringType},
+	)
+	base.SetFieldTag("Name", `csv:"name"`)
+	base.SetFieldTag("Secret", `csv:"-"`)
+
+	return data.TypeDefinition("Person", base)
+}
+
+func newPerson(name string, age int) *data.Struct {
+	p := data.NewStruct(personType())
+	_ = p.Set("Name", name)
+	_ = p.Set("Age", age)
+	_ = p.Set("Secret", "xyzzy")
+
+	return p
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantCSV string
+		wantErr bool
+	}{
+		{
+			name:    "array of structs",
+			value:   data.NewArrayFromInterfaces(data.InterfaceType, newPerson("Tom", 55), newPerson("Smith, Mary", 42)),
+			wantCSV: "name,Age\nTom,55\n\"Smith, Mary\",42\n",
+		},
+		{
+			name: "array of maps",
+			value: data.NewArrayFromInterfaces(data.InterfaceType,
+				data.NewMapFromMap(map[string]string{"b": "2", "a": "1"}),
+				data.NewMapFromMap(map[string]string{"a": "say \"hi\""}),
+			),
+			wantCSV: "a,b\n1,2\n\"say \"\"hi\"\"\",\n",
+		},
+		{
+			name: "array of records",
+			value: data.NewArrayFromInterfaces(data.InterfaceType,
+				data.NewArrayFromStrings("x", "y"),
+				data.NewArrayFromStrings("1", " 2"),
+			),
+			wantCSV: "x,y\n1,\" 2\"\n",
+		},
+		{
+			name:    "not an array",
+			value:   "text",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := marshal(nil, data.NewList(tt.value))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("marshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			b := result.(data.List).Get(0).(*data.Array).GetBytes()
+			if string(b) != tt.wantCSV {
+				t.Errorf("marshal() = %q, want %q", string(b), tt.wantCSV)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	text := "NAME,Age,Secret\nTom,55,x\nMary,,y\n"
+
+	// Without a model, the result is the array of records.
+	result, err := unmarshal(nil, data.NewList(text))
+	if err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+
+	records := result.(data.List).Get(0).(*data.Array)
+	if records.Len() != 3 {
+		t.Errorf("unmarshal() = %v, want 3 records", records)
+	}
+
+	// An array of structures uses the header to find the fields.
+	var model interface{} = data.NewArray(personType(), 0)
+
+	result, err = unmarshal(nil, data.NewList(text, &model))
+	if err != nil || result.(data.List).Get(0) != nil {
+		t.Fatalf("unmarshal() error = %v, %v", err, result)
+	}
+
+	people := model.(*data.Array)
+	if people.Len() != 2 {
+		t.Fatalf("unmarshal() = %v, want 2 structs", people)
+	}
+
+	first, _ := people.Get(0)
+	second, _ := people.Get(1)
+
+	if p := first.(*data.Struct); p.GetAlways("Name") != "Tom" || p.GetAlways("Age") != 55 || p.GetAlways("Secret") != "" {
+		t.Errorf("unmarshal() first = %v", p)
+	}
+
+	if p := second.(*data.Struct); p.GetAlways("Name") != "Mary" || p.GetAlways("Age") != 0 {
+		t.Errorf("unmarshal() second = %v", p)
+	}
+
+	// An array of maps uses the header for the keys.
+	model = data.NewArray(data.MapType(data.StringType, data.StringType), 0)
+
+	if _, err = unmarshal(nil, data.NewList(text, &model)); err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+
+	element, _ := model.(*data.Array).Get(1)
+	if v, _, _ := element.(*data.Map).Get("Secret"); v != "y" {
+		t.Errorf("unmarshal() map = %v", element)
+	}
+
+	// A value that cannot be converted to the field type is an error.
+	model = data.NewArray(personType(), 0)
+
+	result, _ = unmarshal(nil, data.NewList("name,Age\nTom,old\n", &model))
+	if result.(data.List).Get(0) == nil {
+		t.Errorf("unmarshal() expected error for invalid integer")
+	}
+}
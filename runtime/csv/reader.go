@@ -0,0 +1,167 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	egoio "github.com/tucats/ego/runtime/io"
+	"github.com/tucats/ego/symbols"
+)
+
+// newReader implements the csv.NewReader() function. The source of the text is
+// a string, a byte array, a file opened with io.Open(), or an os.File value. The fields of the
+// reader set the options used to read the records, and can be changed before
+// the first record is read.
+func newReader(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	source, err := sourceReader(args.Get(0))
+	if err != nil {
+		return nil, err.In("NewReader")
+	}
+
+	r := data.NewStruct(readerType).FromBuiltinPackage()
+	r.SetAlways(commaFieldName, ",")
+	r.SetAlways(sourceFieldName, source)
+	r.SetAlways(readerFieldName, nil)
+
+	return r, nil
+}
+
+// sourceReader returns a native reader for the source of the text.
+func sourceReader(v interface{}) (io.Reader, *errors.Error) {
+	switch actual := v.(type) {
+	case string:
+		return strings.NewReader(actual), nil
+
+	case *data.Array:
+		if actual.Type().Kind() == data.ByteKind {
+			return bytes.NewReader(actual.GetBytes()), nil
+		}
+
+	default:
+		if f, ok := egoio.NativeFile(v); ok {
+			return f, nil
+		}
+	}
+
+	return nil, errors.ErrArgumentType.Context(data.TypeOf(v).String())
+}
+
+// read implements the Read() method, which returns the next record. When there
+// are no more records, the error is io.EOF.
+func read(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	r, err := getReader(s)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := r.Read()
+	if err != nil {
+		return data.NewList(nil, errors.New(err).In("Read")), nil
+	}
+
+	return data.NewList(recordArray(record), nil), nil
+}
+
+// readAll implements the ReadAll() method, which returns all the remaining records.
+func readAll(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	r, err := getReader(s)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return data.NewList(nil, errors.New(err).In("ReadAll")), nil
+	}
+
+	return data.NewList(recordsArray(records), nil), nil
+}
+
+// decode implements the Decode() method, which reads all the remaining records
+// and stores them in the array the argument points to. See decodeRecords() for
+// how the records are converted to the type of the array.
+func decode(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	r, err := getReader(s)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := r.ReadAll()
+	if err == nil {
+		err = storeRecords(records, args.Get(0))
+	}
+
+	if err != nil {
+		return errors.New(err).In("Decode"), nil
+	}
+
+	return nil, nil
+}
+
+// getReader returns the native CSV reader for the receiver, creating it if this
+// is the first read. The options in the fields of the receiver are applied to the
+// native reader before each read.
+func getReader(s *symbols.SymbolTable) (*csv.Reader, error) {
+	this, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	r, ok := this.GetAlways(readerFieldName).(*csv.Reader)
+	if !ok {
+		source, ok := this.GetAlways(sourceFieldName).(io.Reader)
+		if !ok {
+			return nil, errors.ErrInvalidThis.Context(this.TypeString())
+		}
+
+		r = csv.NewReader(source)
+		this.SetAlways(readerFieldName, r)
+	}
+
+	r.Comma = delimiter(this.GetAlways(commaFieldName))
+	r.Comment, _ = utf8.DecodeRuneInString(data.String(this.GetAlways(commentFieldName)))
+	r.FieldsPerRecord = data.IntOrZero(this.GetAlways(fieldsPerRecordFieldName))
+	r.LazyQuotes = data.BoolOrFalse(this.GetAlways(lazyQuotesFieldName))
+	r.TrimLeadingSpace = data.BoolOrFalse(this.GetAlways(trimLeadingSpaceFieldName))
+
+	// An empty comment string means there are no comment lines.
+	if r.Comment == utf8.RuneError {
+		r.Comment = 0
+	}
+
+	return r, nil
+}
+
+// delimiter returns the field delimiter from the Comma field of a reader or
+// writer. If the field is empty, the delimiter is a comma.
+func delimiter(v interface{}) rune {
+	text := data.String(v)
+	if text == "" {
+		return ','
+	}
+
+	ch, _ := utf8.DecodeRuneInString(text)
+
+	return ch
+}
+
+// getThis returns the receiver of a reader or writer method.
+func getThis(s *symbols.SymbolTable) (*data.Struct, error) {
+	v, ok := s.Get(defs.ThisVariable)
+	if !ok {
+		return nil, errors.ErrNoFunctionReceiver
+	}
+
+	this, ok := v.(*data.Struct)
+	if !ok {
+		return nil, errors.ErrInvalidThis.Context(data.TypeOf(v).String())
+	}
+
+	return this, nil
+}
@@ -0,0 +1,120 @@
+package csv
+
+import (
+	"testing"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/symbols"
+)
+
+func TestReader(t *testing.T) {
+	s := symbols.NewSymbolTable("csv test")
+	Initialize(s)
+
+	v, err := newReader(s, data.NewList("# comment\na; 'b;c'\n1; 2\n"))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	r := v.(*data.Struct)
+	_ = r.Set(commaFieldName, ";")
+	_ = r.Set(commentFieldName, "#")
+	_ = r.Set(trimLeadingSpaceFieldName, true)
+
+	s.SetAlways(defs.ThisVariable, r)
+
+	result, _ := read(s, data.NewList())
+	if record := result.(data.List).Get(0); data.String(record) != `["a", "'b", "c'"]` {
+		t.Errorf("Read() = %v", record)
+	}
+
+	result, _ = readAll(s, data.NewList())
+	if records := result.(data.List).Get(0); data.String(records) != `[["1", "2"]]` {
+		t.Errorf("ReadAll() = %v", records)
+	}
+
+	result, _ = read(s, data.NewList())
+	if e := result.(data.List).Get(1); e == nil || e.(error).Error() == "" {
+		t.Errorf("Read() at end of text returned %v", e)
+	}
+
+	if _, err := newReader(s, data.NewList(42)); err == nil {
+		t.Errorf("NewReader() expected error for invalid source")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	s := symbols.NewSymbolTable("csv test")
+	Initialize(s)
+
+	v, err := newWriter(s, data.NewList())
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+
+	w := v.(*data.Struct)
+	_ = w.Set(commaFieldName, "\t")
+	_ = w.Set(quoteAllFieldName, true)
+	_ = w.Set(useCRLFFieldName, true)
+
+	s.SetAlways(defs.ThisVariable, w)
+
+	if e, _ := write(s, data.NewList(data.NewArrayFromStrings("a", "b\"c"))); e != nil {
+		t.Fatalf("Write() error = %v", e)
+	}
+
+	people := data.NewArrayFromInterfaces(data.InterfaceType, newPerson("Tom", 55))
+	if e, _ := encode(s, data.NewList(people)); e != nil {
+		t.Fatalf("Encode() error = %v", e)
+	}
+
+	want := "\"a\"\t\"b\"\"c\"\r\n\"name\"\t\"Age\"\r\n\"Tom\"\t\"55\"\r\n"
+	if text, _ := writerString(s, data.NewList()); text != want {
+		t.Errorf("String() = %q, want %q", text, want)
+	}
+}
+
+func TestFormatRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		record   []string
+		comma    rune
+		quoteAll bool
+		want     string
+	}{
+		{
+			name:   "plain fields",
+			record: []string{"a", "b"},
+			comma:  ',',
+			want:   "a,b",
+		},
+		{
+			name:   "field with delimiter",
+			record: []string{"a;b", "c,d"},
+			comma:  ';',
+			want:   `"a;b";c,d`,
+		},
+		{
+			name:   "field with line break",
+			record: []string{"a\nb"},
+			comma:  ',',
+			want:   "\"a\nb\"",
+		},
+		{
+			name:     "quote all fields",
+			record:   []string{"a", ""},
+			comma:    ',',
+			quoteAll: true,
+			want:     `"a",""`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatRecord(tt.record, tt.comma, tt.quoteAll); got != tt.want {
+				t.Errorf("formatRecord() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
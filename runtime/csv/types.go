@@ -0,0 +1,204 @@
+package csv
+
+import (
+	"sync"
+
+	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/compiler"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/symbols"
+)
+
+// csv.Reader type specification. The exported fields are the options used to
+// read the records, and can be changed before any record is read. The source
+// field holds the native reader for the text, and the reader field holds the
+// native CSV reader created when the first record is read.
+const readerTypeSpec = `
+type Reader struct {
+	Comma            string
+	Comment          string
+	FieldsPerRecord  int
+	LazyQuotes       bool
+	TrimLeadingSpace bool
+	source           interface{}
+	reader           interface{}
+}`
+
+// csv.Writer type specification. The exported fields are the options used to
+// write the records. The file field holds the native file the records are
+// written to, and the buffer field holds the text written if there is no file.
+const writerTypeSpec = `
+type Writer struct {
+	Comma    string
+	QuoteAll bool
+	UseCRLF  bool
+	file     interface{}
+	buffer   interface{}
+}`
+
+const (
+	commaFieldName            = "Comma"
+	commentFieldName          = "Comment"
+	fieldsPerRecordFieldName  = "FieldsPerRecord"
+	lazyQuotesFieldName       = "LazyQuotes"
+	trimLeadingSpaceFieldName = "TrimLeadingSpace"
+	quoteAllFieldName         = "QuoteAll"
+	useCRLFFieldName          = "UseCRLF"
+	sourceFieldName           = "source"
+	readerFieldName           = "reader"
+	fileFieldName             = "file"
+	bufferFieldName           = "buffer"
+)
+
+var readerType *data.Type
+var writerType *data.Type
+var initLock sync.Mutex
+
+func Initialize(s *symbols.SymbolTable) {
+	initLock.Lock()
+	defer initLock.Unlock()
+
+	if readerType == nil {
+		t, _ := compiler.CompileTypeSpec(readerTypeSpec, nil)
+
+		t.DefineFunction("Read", &data.Declaration{
+			Name:    "Read",
+			Type:    t,
+			Returns: []*data.Type{data.ArrayType(data.StringType), data.ErrorType},
+		}, read)
+
+		t.DefineFunction("ReadAll", &data.Declaration{
+			Name:    "ReadAll",
+			Type:    t,
+			Returns: []*data.Type{data.ArrayType(data.ArrayType(data.StringType)), data.ErrorType},
+		}, readAll)
+
+		t.DefineFunction("Decode", &data.Declaration{
+			Name: "Decode",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "value",
+					Type: data.PointerType(data.InterfaceType),
+				},
+			},
+			Returns: []*data.Type{data.ErrorType},
+		}, decode)
+
+		readerType = t.SetPackage("csv")
+
+		t, _ = compiler.CompileTypeSpec(writerTypeSpec, nil)
+
+		t.DefineFunction("Write", &data.Declaration{
+			Name: "Write",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "record",
+					Type: data.ArrayType(data.StringType),
+				},
+			},
+			Returns: []*data.Type{data.ErrorType},
+		}, write)
+
+		t.DefineFunction("WriteAll", &data.Declaration{
+			Name: "WriteAll",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "records",
+					Type: data.ArrayType(data.ArrayType(data.StringType)),
+				},
+			},
+			Returns: []*data.Type{data.ErrorType},
+		}, writeAll)
+
+		t.DefineFunction("Encode", &data.Declaration{
+			Name: "Encode",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "value",
+					Type: data.InterfaceType,
+				},
+			},
+			Returns: []*data.Type{data.ErrorType},
+		}, encode)
+
+		t.DefineFunction("String", &data.Declaration{
+			Name:    "String",
+			Type:    t,
+			Returns: []*data.Type{data.StringType},
+		}, writerString)
+
+		writerType = t.SetPackage("csv")
+	}
+
+	if _, found := s.Root().Get("csv"); !found {
+		newpkg := data.NewPackageFromMap("csv", map[string]interface{}{
+			"NewReader": data.Function{
+				Declaration: &data.Declaration{
+					Name: "NewReader",
+					Parameters: []data.Parameter{
+						{
+							Name: "source",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{data.PointerType(readerType)},
+				},
+				Value: newReader,
+			},
+			"NewWriter": data.Function{
+				Declaration: &data.Declaration{
+					Name: "NewWriter",
+					Parameters: []data.Parameter{
+						{
+							Name: "file",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns:  []*data.Type{data.PointerType(writerType)},
+					ArgCount: data.Range{0, 1},
+				},
+				Value: newWriter,
+			},
+			"Marshal": data.Function{
+				Declaration: &data.Declaration{
+					Name: "Marshal",
+					Parameters: []data.Parameter{
+						{
+							Name: "any",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{data.ArrayType(data.ByteType), data.ErrorType},
+				},
+				Value: marshal,
+			},
+			"Unmarshal": data.Function{
+				Declaration: &data.Declaration{
+					Name: "Unmarshal",
+					Parameters: []data.Parameter{
+						{
+							Name: "data",
+							Type: data.ArrayType(data.ByteType),
+						},
+						{
+							Name: "value",
+							Type: data.PointerType(data.InterfaceType),
+						},
+					},
+					Returns: []*data.Type{data.ErrorType},
+				},
+				Value: unmarshal,
+			},
+			"Reader": readerType,
+			"Writer": writerType,
+		})
+
+		pkg, _ := bytecode.GetPackage(newpkg.Name)
+		pkg.Merge(newpkg)
+		s.Root().SetAlways(newpkg.Name, newpkg)
+	}
+}
@@ -0,0 +1,200 @@
+package csv
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	egoio "github.com/tucats/ego/runtime/io"
+	"github.com/tucats/ego/symbols"
+)
+
+// newWriter implements the csv.NewWriter() function. If there is a file opened
+// with io.Open() or os.Create(), the records are written to the file. Otherwise, the records
+// are kept in the writer, and can be read using the String() method.
+func newWriter(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	w := data.NewStruct(writerType).FromBuiltinPackage()
+	w.SetAlways(commaFieldName, ",")
+	w.SetAlways(bufferFieldName, &bytes.Buffer{})
+	w.SetAlways(fileFieldName, nil)
+
+	if args.Len() > 0 {
+		file, ok := egoio.NativeFile(args.Get(0))
+		if !ok {
+			return nil, errors.ErrInvalidfileIdentifier.In("NewWriter")
+		}
+
+		w.SetAlways(fileFieldName, file)
+	}
+
+	return w, nil
+}
+
+// write implements the Write() method, which writes a single record.
+func write(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	this, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeRecords(this, [][]string{stringList(args.Get(0))}); err != nil {
+		return errors.New(err).In("Write"), nil
+	}
+
+	return nil, nil
+}
+
+// writeAll implements the WriteAll() method, which writes an array of records.
+func writeAll(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	this, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	records := [][]string{}
+
+	if a, ok := args.Get(0).(*data.Array); ok {
+		for i := 0; i < a.Len(); i++ {
+			v, _ := a.Get(i)
+			records = append(records, stringList(v))
+		}
+	}
+
+	if err := writeRecords(this, records); err != nil {
+		return errors.New(err).In("WriteAll"), nil
+	}
+
+	return nil, nil
+}
+
+// encode implements the Encode() method, which writes an array of structures,
+// maps, or records. See encodeRecords() for how the values are converted to
+// records.
+func encode(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	this, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := encodeRecords(args.Get(0))
+	if err == nil {
+		err = writeRecords(this, records)
+	}
+
+	if err != nil {
+		return errors.New(err).In("Encode"), nil
+	}
+
+	return nil, nil
+}
+
+// writerString implements the String() method, which returns the text written
+// by a writer that does not have a file.
+func writerString(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	this, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if buffer, ok := this.GetAlways(bufferFieldName).(*bytes.Buffer); ok {
+		return buffer.String(), nil
+	}
+
+	return "", nil
+}
+
+// writeRecords formats the records using the options in the fields of the writer,
+// and writes them to the file or buffer of the writer.
+func writeRecords(this *data.Struct, records [][]string) error {
+	var text strings.Builder
+
+	comma := delimiter(this.GetAlways(commaFieldName))
+	quoteAll := data.BoolOrFalse(this.GetAlways(quoteAllFieldName))
+
+	lineEnd := "\n"
+	if data.BoolOrFalse(this.GetAlways(useCRLFFieldName)) {
+		lineEnd = "\r\n"
+	}
+
+	for _, record := range records {
+		text.WriteString(formatRecord(record, comma, quoteAll))
+		text.WriteString(lineEnd)
+	}
+
+	if file, ok := this.GetAlways(fileFieldName).(*os.File); ok && file != nil {
+		_, err := file.WriteString(text.String())
+
+		return err
+	}
+
+	if buffer, ok := this.GetAlways(bufferFieldName).(*bytes.Buffer); ok {
+		buffer.WriteString(text.String())
+
+		return nil
+	}
+
+	return errors.ErrInvalidThis.Context(this.TypeString())
+}
+
+// formatRecord returns the text of a record, with the fields separated by the
+// delimiter. A field is quoted if it contains the delimiter, a quote, or a line
+// break, if it starts with a space, or if the quoteAll flag is set. A quote in
+// a quoted field is written as two quotes.
+func formatRecord(record []string, comma rune, quoteAll bool) string {
+	var text strings.Builder
+
+	for n, field := range record {
+		if n > 0 {
+			text.WriteRune(comma)
+		}
+
+		if !quoteAll && !fieldNeedsQuotes(field, comma) {
+			text.WriteString(field)
+
+			continue
+		}
+
+		text.WriteString(`"`)
+		text.WriteString(strings.ReplaceAll(field, `"`, `""`))
+		text.WriteString(`"`)
+	}
+
+	return text.String()
+}
+
+// fieldNeedsQuotes returns true if the field must be quoted so it can be read
+// back as the same value.
+func fieldNeedsQuotes(field string, comma rune) bool {
+	if field == "" {
+		return false
+	}
+
+	if field == `\.` || strings.ContainsRune(field, comma) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+
+	ch, _ := utf8.DecodeRuneInString(field)
+
+	return unicode.IsSpace(ch)
+}
+
+// stringList converts an Ego array to a list of strings.
+func stringList(v interface{}) []string {
+	a, ok := v.(*data.Array)
+	if !ok {
+		return nil
+	}
+
+	result := make([]string, a.Len())
+
+	for i := range result {
+		element, _ := a.Get(i)
+		result[i] = data.String(element)
+	}
+
+	return result
+}
@@ -6,6 +6,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"net/url"
 	"strings"
@@ -14,6 +15,7 @@ import (
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
+	egocontext "github.com/tucats/ego/runtime/context"
 	"github.com/tucats/ego/symbols"
 
 	// Blank imports to make sure we link in the database drivers.
@@ -67,17 +69,66 @@ func newConnection(s *symbols.SymbolTable, args data.List) (interface{}, error)
 // of structs, where the struct members are the same as the result set column names. When
 // not true, the result set is an array of arrays, where the inner array contains the
 // column data in the order of the result set, but with no labels, etc.
+//
+// An optional second argument is a structure type (or a value of that type) used as the
+// model for each row. The columns are stored in the fields of the model, using the db
+// tags of the fields to map column names to field names.
 func asStructures(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var model *data.Type
+
 	if _, _, err := client(s); err != nil {
 		return nil, err
 	}
 
+	if args.Len() > 1 {
+		if t, ok := args.Get(1).(*data.Type); ok {
+			model = t
+		} else {
+			model = data.TypeOf(args.Get(1))
+		}
+
+		if model.BaseType().Kind() != data.StructKind {
+			return nil, errors.ErrInvalidType.Context(model.String())
+		}
+	}
+
 	this := getThis(s)
 	this.SetAlways(asStructFieldName, data.BoolOrFalse(args.Get(0)))
+	this.SetAlways(modelFieldName, model)
+
+	return this, nil
+}
+
+// setContext implements the Context() db function. This binds the client to a context.Context
+// value, so queries and statements run by the client are cancelled when the context is
+// cancelled or its deadline passes. A nil context removes the binding.
+func setContext(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	if _, _, err := client(s); err != nil {
+		return nil, err
+	}
+
+	if args.Get(0) != nil {
+		if _, ok := egocontext.Value(args.Get(0)); !ok {
+			return nil, errors.ErrInvalidContext.In("Context")
+		}
+	}
+
+	this := getThis(s)
+	this.SetAlways(contextFieldName, args.Get(0))
 
 	return this, nil
 }
 
+// clientContext returns the native context the client is bound to, or the background
+// context if there is no binding.
+func clientContext(this *data.Struct) context.Context {
+	if ctx, ok := egocontext.Value(this.GetAlways(contextFieldName)); ok {
+		return ctx
+	}
+
+	return context.Background()
+}
+
 // closeConnection closes the database connection, frees up any resources held, and resets the
 // handle contents to prevent re-using the connection.
 func closeConnection(s *symbols.SymbolTable, args data.List) (interface{}, error) {
@@ -97,6 +148,8 @@ func closeConnection(s *symbols.SymbolTable, args data.List) (interface{}, error
 	this.SetAlways(constrFieldName, "")
 	this.SetAlways(transactionFieldName, nil)
 	this.SetAlways(asStructFieldName, false)
+	this.SetAlways(modelFieldName, nil)
+	this.SetAlways(contextFieldName, nil)
 	this.SetAlways(rowCountFieldName, -1)
 
 	if err != nil {
@@ -30,17 +30,18 @@ func query(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 	this.SetAlways(rowCountFieldName, -1)
 
 	query := data.String(args.Get(0))
+	ctx := clientContext(getThis(s))
 
 	if tx == nil {
 		ui.Log(ui.DBLogger, "db.query.rows",
 			"sql", query)
 
-		rows, e2 = db.Query(query, args.Elements()[1:args.Len()]...)
+		rows, e2 = db.QueryContext(ctx, query, args.Elements()[1:args.Len()]...)
 	} else {
 		ui.Log(ui.DBLogger, "db.tx.query.rows",
 			"sql", query)
 
-		rows, e2 = tx.Query(query, args.Elements()[1:]...)
+		rows, e2 = tx.QueryContext(ctx, query, args.Elements()[1:]...)
 	}
 
 	if e2 != nil {
@@ -78,17 +79,18 @@ func queryResult(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 	this.SetAlways(rowCountFieldName, -1)
 
 	query := data.String(args.Get(0))
+	ctx := clientContext(getThis(s))
 
 	if tx == nil {
 		ui.Log(ui.DBLogger, "db.query.rows",
 			"sql", query)
 
-		rows, e2 = db.Query(query, args.Elements()[1:]...)
+		rows, e2 = db.QueryContext(ctx, query, args.Elements()[1:]...)
 	} else {
 		ui.Log(ui.DBLogger, "db.tx.query.rows",
 			"sql", query)
 
-		rows, e2 = tx.Query(query, args.Elements()[1:]...)
+		rows, e2 = tx.QueryContext(ctx, query, args.Elements()[1:]...)
 	}
 
 	if rows != nil {
@@ -117,7 +119,7 @@ func queryResult(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 			return nil, errors.New(err)
 		}
 
-		if asStruct {
+		if asStruct && modelType(this) == nil {
 			rowMap := map[string]interface{}{}
 
 			for i, v := range columns {
@@ -132,7 +134,7 @@ func queryResult(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 
 	size := len(arrayResult)
 
-	if asStruct {
+	if asStruct && modelType(this) == nil {
 		size = len(mapResult)
 	}
 
@@ -153,7 +155,16 @@ func queryResult(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 	this.SetAlways(rowCountFieldName, size)
 	r := data.NewArray(data.InterfaceType, size)
 
-	if asStruct {
+	if model := modelType(this); asStruct && model != nil {
+		for i, v := range arrayResult {
+			row, err := newRowStruct(model, columns, v)
+			if err != nil {
+				return data.NewList(nil, err), err
+			}
+
+			r.SetAlways(i, row)
+		}
+	} else if asStruct {
 		for i, v := range mapResult {
 			r.SetAlways(i, data.NewStructFromMap(v))
 		}
@@ -185,17 +196,18 @@ func execute(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 	}
 
 	query := data.String(args.Get(0))
+	ctx := clientContext(getThis(s))
 
 	if tx == nil {
 		ui.Log(ui.DBLogger, "db.exec",
 			"sql", query)
 
-		sqlResult, err = db.Exec(query, args.Elements()[1:]...)
+		sqlResult, err = db.ExecContext(ctx, query, args.Elements()[1:]...)
 	} else {
 		ui.Log(ui.DBLogger, "db.tx.exec",
 			"sql", query)
 
-		sqlResult, err = tx.Exec(query, args.Elements()[1:]...)
+		sqlResult, err = tx.ExecContext(ctx, query, args.Elements()[1:]...)
 	}
 
 	if err != nil {
@@ -92,6 +92,22 @@ func rowsScan(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 		return data.NewList(nil, errors.New(err)), errors.New(err)
 	}
 
+	// If a pointer to a structure was passed, the columns are stored in the
+	// fields of that structure.
+	if args.Len() > 0 {
+		if target, ok := scanTarget(args.Get(0)); ok {
+			err := storeRow(target, columns, rowValues)
+
+			return data.NewList(target, err), err
+		}
+	}
+
+	if model := modelType(db); asStruct && model != nil {
+		row, err := newRowStruct(model, columns, rowValues)
+
+		return data.NewList(row, err), err
+	}
+
 	if asStruct {
 		rowMap := map[string]interface{}{}
 
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+)
+
+// modelType returns the structure type set as the row model for the database
+// client by AsStruct(), or nil if there is no model.
+func modelType(client *data.Struct) *data.Type {
+	if t, ok := client.GetAlways(modelFieldName).(*data.Type); ok {
+		return t
+	}
+
+	return nil
+}
+
+// scanTarget returns the structure that a pointer argument to Scan() refers to.
+func scanTarget(arg interface{}) (*data.Struct, bool) {
+	if pointer, ok := arg.(*interface{}); ok && pointer != nil {
+		arg = *pointer
+	}
+
+	target, ok := arg.(*data.Struct)
+
+	return target, ok
+}
+
+// newRowStruct creates a new structure of the model type, and stores the column
+// values of a row in its fields.
+func newRowStruct(model *data.Type, columns []string, values []interface{}) (*data.Struct, error) {
+	row := data.NewStruct(model)
+
+	return row, storeRow(row, columns, values)
+}
+
+// storeRow stores the column values of a row in the fields of a structure. A
+// field with a db tag is stored from the column named in the tag, and a field
+// tagged with "-" is never stored. Other fields are stored from the column of the
+// same name. Column names are not case-sensitive, and columns without a matching
+// field, or with a null value, are ignored.
+func storeRow(target *data.Struct, columns []string, values []interface{}) error {
+	t := target.Type().BaseType()
+	fields := map[string]string{}
+
+	for _, name := range t.FieldNames() {
+		column := name
+
+		if tag, found := t.FieldTagValue(name, "db"); found {
+			if tag == "-" {
+				continue
+			}
+
+			if tagName, _ := data.ParseTagValue(tag); tagName != "" {
+				column = tagName
+			}
+		}
+
+		fields[strings.ToLower(column)] = name
+	}
+
+	for i, column := range columns {
+		name, found := fields[strings.ToLower(column)]
+		if !found || values[i] == nil {
+			continue
+		}
+
+		value := values[i]
+		if b, ok := value.([]byte); ok {
+			value = string(b)
+		}
+
+		if fieldType, err := t.Field(name); err == nil {
+			switch fieldType.Kind() {
+			case data.InterfaceKind:
+				// No conversion needed.
+
+			case data.StringKind:
+				value = data.String(value)
+
+			default:
+				if value, err = data.Coerce(value, data.InstanceOfType(fieldType)); err != nil {
+					return errors.New(err).Context(column)
+				}
+			}
+		}
+
+		if err := target.Set(name, value); err != nil {
+			return errors.New(err).Context(column)
+		}
+	}
+
+	return nil
+}
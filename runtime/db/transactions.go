@@ -26,7 +26,7 @@ func begin(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 		this := getThis(s)
 
 		if tx == nil {
-			tx, e2 = d.Begin()
+			tx, e2 = d.BeginTx(clientContext(this), nil)
 			if e2 == nil {
 				this.SetAlways(transactionFieldName, tx)
 			}
@@ -17,9 +17,11 @@ const dbTypeSpec = `
 type Client struct {
 	client 		interface{},
 	asStruct 	bool,
+	model 		interface{},
 	rowCount 	int,
 	transaction	interface{},
 	constr 		string,
+	context 	interface{},
 }`
 
 // db.Rows type specification.
@@ -32,10 +34,12 @@ const dbRowsTypeSpec = `type Rows struct {
 const (
 	clientFieldName      = "client"
 	constrFieldName      = "Constr"
+	contextFieldName     = "context"
 	dbFieldName          = "db"
 	rowCountFieldName    = "Rowcount"
 	rowsFieldName        = "rows"
 	asStructFieldName    = "asStruct"
+	modelFieldName       = "model"
 	transactionFieldName = "transaction"
 )
 
@@ -143,10 +147,27 @@ func Initialize(s *symbols.SymbolTable) {
 					Name: "flag",
 					Type: data.BoolType,
 				},
+				{
+					Name: "model",
+					Type: data.InterfaceType,
+				},
 			},
-			Returns: []*data.Type{data.VoidType},
+			Returns:  []*data.Type{data.VoidType},
+			ArgCount: data.Range{1, 2},
 		}, asStructures)
 
+		t.DefineFunction("Context", &data.Declaration{
+			Name: "Context",
+			Type: data.PointerType(t),
+			Parameters: []data.Parameter{
+				{
+					Name: "ctx",
+					Type: data.InterfaceType,
+				},
+			},
+			Returns: []*data.Type{data.PointerType(t)},
+		}, setContext)
+
 		clientType = t.SetPackage("db")
 	}
 
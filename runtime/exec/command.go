@@ -1,6 +1,7 @@
 package exec
 
 import (
+	"context"
 	"os/exec"
 
 	"github.com/tucats/ego/app-cli/settings"
@@ -8,6 +9,7 @@ import (
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
 	"github.com/tucats/ego/fork"
+	egocontext "github.com/tucats/ego/runtime/context"
 	"github.com/tucats/ego/symbols"
 )
 
@@ -20,17 +22,46 @@ func newCommand(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 		return nil, errors.ErrNoPrivilegeForOperation.In("Run")
 	}
 
+	return makeCommand(nil, args.Elements()), nil
+}
+
+// newCommandContext implements exec.CommandContext(), which is like exec.Command()
+// but the command is bound to a context.Context value. If the context is cancelled
+// or its deadline passes before the command completes, the process is killed.
+func newCommandContext(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	// Check to see if we're even allowed to do this.
+	if !settings.GetBool(defs.ExecPermittedSetting) {
+		return nil, errors.ErrNoPrivilegeForOperation.In("Run")
+	}
+
+	ctx, ok := egocontext.Value(args.Get(0))
+	if !ok {
+		return nil, errors.ErrInvalidContext.In("CommandContext")
+	}
+
+	return makeCommand(ctx, args.Elements()[1:]), nil
+}
+
+// makeCommand creates the Ego instance of exec.Cmd for the command and its
+// arguments. If the context is not nil, the command is bound to it.
+func makeCommand(ctx context.Context, args []interface{}) *data.Struct {
 	// Let's build the Ego instance of exec.Cmd
 	result := data.NewStruct(commandTypeDef).FromBuiltinPackage()
 
-	strArray := make([]string, args.Len())
-	for n, v := range args.Elements() {
+	strArray := make([]string, len(args))
+	for n, v := range args {
 		strArray[n] = data.String(v)
 	}
 
 	strArray = fork.MungeArguments(strArray...)
 
-	cmd := exec.Command(strArray[0], strArray[1:]...)
+	var cmd *exec.Cmd
+
+	if ctx != nil {
+		cmd = exec.CommandContext(ctx, strArray[0], strArray[1:]...)
+	} else {
+		cmd = exec.Command(strArray[0], strArray[1:]...)
+	}
 
 	// Store the native structure, and the path from the rsulting command object
 	result.SetAlways("cmd", cmd)
@@ -44,7 +75,7 @@ func newCommand(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 
 	_ = result.Set("Args", a)
 
-	return result, nil
+	return result
 }
 
 // lookPath implements the exec.LookPath() function.
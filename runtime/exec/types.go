@@ -69,6 +69,28 @@ func Initialize(s *symbols.SymbolTable) {
 				},
 				Value: newCommand,
 			},
+			"CommandContext": data.Function{
+				Declaration: &data.Declaration{
+					Name: "CommandContext",
+					Parameters: []data.Parameter{
+						{
+							Name: "ctx",
+							Type: data.InterfaceType,
+						},
+						{
+							Name: "commandText",
+							Type: data.StringType,
+						},
+						{
+							Name: "argument",
+							Type: data.StringType,
+						},
+					},
+					Returns:  []*data.Type{commandTypeDef},
+					Variadic: true,
+				},
+				Value: newCommandContext,
+			},
 			"LookPath": data.Function{
 				Declaration: &data.Declaration{
 					Name: "LookPath",
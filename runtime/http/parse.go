@@ -0,0 +1,86 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+)
+
+// Parameters returns the query parameters of a request as an Ego map. Each
+// parameter name maps to an array of the values given for it in the URL.
+func Parameters(query map[string][]string) *data.Map {
+	parameters := map[string]interface{}{}
+
+	for k, v := range query {
+		values := make([]interface{}, 0)
+		for _, vs := range v {
+			values = append(values, vs)
+		}
+
+		parameters[k] = data.NewArrayFromInterfaces(data.InterfaceType, values...)
+	}
+
+	return data.NewMapFromMap(parameters)
+}
+
+// Headers returns the headers of a request as an Ego map. Each header name maps
+// to the list of values given for it. The authorization header is omitted. The
+// boolean result is true if the Accept header asks for a JSON response.
+func Headers(header map[string][]string) (*data.Map, bool) {
+	headers := map[string]interface{}{}
+	isJSON := false
+
+	for name, values := range header {
+		if strings.ToLower(name) != "authorization" {
+			valueList := []interface{}{}
+
+			for _, value := range values {
+				valueList = append(valueList, value)
+
+				if strings.EqualFold(name, "Accept") && strings.Contains(value, defs.JSONMediaType) {
+					isJSON = true
+				}
+			}
+
+			headers[name] = valueList
+		}
+	}
+
+	return data.NewMapFromMap(headers), isJSON
+}
+
+// URLParts uses an endpoint pattern to create a map describing each field in the
+// path of a URL. A pattern field of the form {{name}} stores the matching path
+// field as a string value with that name. Any other pattern field is stored as
+// a boolean value indicating if the path has the same field.
+func URLParts(pattern, path string) map[string]interface{} {
+	m := map[string]interface{}{}
+	path = strings.TrimPrefix(strings.TrimSuffix(path, "/"), "/")
+	segments := strings.Split(path, "?")
+	pathSegment := strings.TrimPrefix(strings.TrimSuffix(segments[0], "/"), "/")
+	pathParts := strings.Split(pathSegment, "/")
+	patternParts := strings.Split(strings.TrimPrefix(strings.TrimSuffix(pattern, "/"), "/"), "/")
+
+	for index, part := range patternParts {
+		// if this part of the pattern is a named value, make it part
+		// of the result with a string value.
+		if strings.HasPrefix(part, "{{") && strings.HasSuffix(part, "}}") {
+			key := strings.TrimPrefix(strings.TrimSuffix(part, "}}"), "{{")
+
+			if index < len(pathParts) {
+				m[key] = pathParts[index]
+			} else {
+				m[key] = ""
+			}
+		} else {
+			if index >= len(pathParts) {
+				m[part] = false
+			} else {
+				m[part] = (part == pathParts[index])
+			}
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+// newResponseWriter creates the response object passed to a handler. The default
+// status is success.
+func newResponseWriter(isJSON bool) *data.Struct {
+	w := data.NewStruct(responseWriterType).FromBuiltinPackage()
+
+	w.SetAlways(statusFieldName, http.StatusOK)
+	w.SetAlways(bufferFieldName, "")
+	w.SetAlways(headersFieldName, data.NewMap(data.StringType, data.ArrayType(data.StringType)))
+	w.SetAlways(jsonFieldName, isJSON)
+
+	return w
+}
+
+// writeStatus implements the WriteStatus() function, which sets the status of
+// the response.
+func writeStatus(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	w, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := data.Int(args.Get(0))
+	if err != nil {
+		return nil, errors.New(err).In("WriteStatus")
+	}
+
+	w.SetAlways(statusFieldName, status)
+
+	return nil, nil
+}
+
+// write implements the Write() function. If the client asked for a JSON response,
+// the item is the value sent as JSON. Otherwise, the item is formatted as text and
+// added to the response buffer.
+func write(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	w, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	item, _ := data.UnWrap(args.Get(0))
+
+	if data.BoolOrFalse(w.GetAlways(jsonFieldName)) {
+		w.SetAlways(responseFieldName, item)
+
+		return nil, nil
+	}
+
+	text := data.FormatUnquoted(item)
+
+	if b, ok := item.(*data.Array); ok {
+		if bs := b.GetBytes(); bs != nil {
+			text = string(bs)
+		}
+	}
+
+	writeBuffer(w, text+"\n")
+
+	return nil, nil
+}
+
+// writeHeader implements the WriteHeader() function. If the header already has
+// values, the value is added to the list of values for the header.
+func writeHeader(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	w, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, ok := w.GetAlways(headersFieldName).(*data.Map)
+	if !ok {
+		return nil, errors.ErrInvalidType.In("WriteHeader").Context(headersFieldName)
+	}
+
+	name := data.String(args.Get(0))
+	value := data.String(args.Get(1))
+
+	if v, found, _ := headers.Get(name); found {
+		if values, ok := v.(*data.Array); ok {
+			values.Append(value)
+
+			return nil, nil
+		}
+	}
+
+	_, err = headers.Set(name, data.NewArrayFromStrings(value))
+
+	return nil, err
+}
+
+// writeMessage implements the WriteMessage() function. If the client asked for
+// a JSON response, the message is sent as a JSON object with a single "message"
+// field. Otherwise, the message is added to the response buffer.
+func writeMessage(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	w, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := data.String(args.Get(0))
+
+	if data.BoolOrFalse(w.GetAlways(jsonFieldName)) {
+		w.SetAlways(responseFieldName, data.NewMapFromMap(map[string]interface{}{"message": msg}))
+	} else {
+		writeBuffer(w, msg+"\n")
+	}
+
+	return nil, nil
+}
+
+// writeJSON implements the WriteJSON() function, which adds the JSON text of the
+// item to the response buffer.
+func writeJSON(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	w, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	item, _ := data.UnWrap(args.Get(0))
+
+	b, err := json.Marshal(data.Sanitize(item))
+	if err != nil {
+		return nil, errors.New(err).In("WriteJSON")
+	}
+
+	writeBuffer(w, string(b))
+
+	return nil, nil
+}
+
+// writeBuffer adds text to the response buffer.
+func writeBuffer(w *data.Struct, text string) {
+	w.SetAlways(bufferFieldName, data.String(w.GetAlways(bufferFieldName))+text)
+}
+
+// sendResponse writes the status, headers, and body from the response object to
+// the native response writer, after the handler has completed.
+func sendResponse(w *data.Struct, rw http.ResponseWriter) {
+	if headers, ok := w.GetAlways(headersFieldName).(*data.Map); ok {
+		for _, key := range headers.Keys() {
+			name := data.String(key)
+			v, _, _ := headers.Get(key)
+
+			if values, ok := v.(*data.Array); ok {
+				for _, value := range values.BaseArray() {
+					rw.Header().Add(name, data.String(value))
+				}
+			}
+		}
+	}
+
+	body := []byte(data.String(w.GetAlways(bufferFieldName)))
+
+	if response, _ := data.UnWrap(w.GetAlways(responseFieldName)); response != nil {
+		body, _ = json.Marshal(data.Sanitize(response))
+
+		rw.Header().Set(defs.ContentTypeHeader, defs.JSONMediaType)
+	}
+
+	rw.WriteHeader(data.IntOrZero(w.GetAlways(statusFieldName)))
+	_, _ = rw.Write(body)
+}
+
+// getThis returns the response object that is the receiver of a function call.
+func getThis(s *symbols.SymbolTable) (*data.Struct, error) {
+	v, ok := s.Get(defs.ThisVariable)
+	if !ok {
+		return nil, errors.ErrNoFunctionReceiver
+	}
+
+	w, ok := v.(*data.Struct)
+	if !ok {
+		return nil, errors.ErrInvalidThis.Context(data.TypeOf(v).String())
+	}
+
+	return w, nil
+}
@@ -0,0 +1,332 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	egocontext "github.com/tucats/ego/runtime/context"
+	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
+)
+
+// The default time allowed for active requests to complete when the server is
+// shut down.
+const defaultShutdownTimeout = 5 * time.Second
+
+// route is a handler function registered for an endpoint pattern. The symbol
+// table is the global scope of the caller of HandleFunc(), which is the parent
+// scope of each call to the handler.
+type route struct {
+	pattern string
+	handler interface{}
+	symbols *symbols.SymbolTable
+}
+
+var (
+	routes     []*route
+	server     *http.Server
+	stopped    chan struct{}
+	serverLock sync.Mutex
+)
+
+// handleFunc implements the HandleFunc() function. This registers a function that
+// is called to handle requests for the endpoint pattern. The function is called
+// with a ResponseWriter and a Request. A pattern field of the form {{name}} matches
+// any value in the URL path, which is stored in the URL.Parts map of the request.
+// A pattern that ends in "/" also matches any path that starts with the pattern.
+func handleFunc(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	pattern := data.String(args.Get(0))
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, errors.ErrInvalidEndPointString.In("HandleFunc").Context(pattern)
+	}
+
+	handler := args.Get(1)
+
+	switch handler.(type) {
+	case *bytecode.ByteCode, data.Function:
+	default:
+		return nil, errors.ErrArgumentType.In("HandleFunc").Context(data.TypeOf(handler).String())
+	}
+
+	// The handler is called from the global scope of the caller, above any function
+	// scope boundaries, so the request symbols are visible to the handler and to
+	// the methods it calls. Requests are handled concurrently, so the global scope
+	// is shared, as it is for a go routine.
+	parent := s
+	for parent != nil && !parent.IsBoundary() {
+		parent = parent.Parent()
+	}
+
+	if parent = parent.FindNextScope(); parent == nil {
+		parent = s.Parent()
+	}
+
+	parent.Shared(true)
+
+	serverLock.Lock()
+	defer serverLock.Unlock()
+
+	for _, r := range routes {
+		if r.pattern == pattern {
+			r.handler = handler
+			r.symbols = parent
+
+			return nil, nil
+		}
+	}
+
+	routes = append(routes, &route{
+		pattern: pattern,
+		handler: handler,
+		symbols: parent,
+	})
+
+	return nil, nil
+}
+
+// listenAndServe implements the ListenAndServe() function. This listens on the
+// address and serves requests using the registered handlers. It does not return
+// until the server is shut down, in which case the result is nil.
+func listenAndServe(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	serverLock.Lock()
+
+	if server != nil {
+		serverLock.Unlock()
+
+		return errors.ErrHTTPServerRunning.In("ListenAndServe"), nil
+	}
+
+	srv := &http.Server{
+		Addr:    data.String(args.Get(0)),
+		Handler: http.HandlerFunc(serveHTTP),
+	}
+
+	done := make(chan struct{})
+	server = srv
+	stopped = done
+
+	serverLock.Unlock()
+
+	err := srv.ListenAndServe()
+
+	// If the server was shut down, wait for the active requests to complete.
+	if err == http.ErrServerClosed {
+		<-done
+
+		return nil, nil
+	}
+
+	serverLock.Lock()
+	server = nil
+	serverLock.Unlock()
+
+	return errors.New(err).In("ListenAndServe"), nil
+}
+
+// shutdown implements the Shutdown() function. The server stops accepting new
+// requests, and ListenAndServe() returns when the active requests are complete,
+// or the optional timeout expires. The timeout is a duration string or a number
+// of milliseconds. Because it does not wait for the active requests, Shutdown()
+// can be called from a handler.
+func shutdown(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	timeout := defaultShutdownTimeout
+
+	if args.Len() > 0 {
+		var err error
+
+		if text, ok := args.Get(0).(string); ok {
+			timeout, err = util.ParseDuration(text)
+		} else {
+			var ms int
+
+			ms, err = data.Int(args.Get(0))
+			timeout = time.Duration(ms) * time.Millisecond
+		}
+
+		if err != nil {
+			return errors.ErrInvalidDuration.In("Shutdown").Context(args.Get(0)), nil
+		}
+	}
+
+	serverLock.Lock()
+	defer serverLock.Unlock()
+
+	if server == nil {
+		return nil, nil
+	}
+
+	srv, done := server, stopped
+	server = nil
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		_ = srv.Shutdown(ctx)
+
+		close(done)
+	}()
+
+	return nil, nil
+}
+
+// serveHTTP handles each request made to the server by calling the handler for
+// the route that matches the request path. The response object is written to
+// the client after the handler returns.
+func serveHTTP(w http.ResponseWriter, r *http.Request) {
+	rt, parts := findRoute(r.URL.Path)
+	if rt == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	request, isJSON := newRequest(r, rt.pattern, parts)
+	writer := newResponseWriter(isJSON)
+
+	// Call the handler function in a new context, whose symbol table is a child
+	// of the global scope of the caller of HandleFunc(). The handler is stopped
+	// if the client disconnects.
+	callCode := bytecode.New("http " + rt.pattern).Literal(true)
+	callCode.Emit(bytecode.Push, rt.handler)
+	callCode.Emit(bytecode.Push, writer)
+	callCode.Emit(bytecode.Push, request)
+	callCode.Emit(bytecode.Call, 2)
+
+	handlerSymbols := symbols.NewChildSymbolTable("http handler "+rt.pattern, rt.symbols).Boundary(false)
+	handlerSymbols.SetAlways(defs.RequestContextVariable, egocontext.New(r.Context()))
+
+	// If the global scope is a package scope, the table for the request must be
+	// part of the same package so the search for symbols from within a function
+	// stops at the request table instead of skipping past it.
+	handlerSymbols.SetPackage(rt.symbols.Package())
+
+	ctx := bytecode.NewContext(handlerSymbols, callCode).SetCancelContext(r.Context())
+
+	if err := ctx.Run(); err != nil && !errors.Equals(err, errors.ErrStop) {
+		http.Error(w, "Error: "+err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	sendResponse(writer, w)
+}
+
+// newRequest creates the request object passed to a handler. The boolean result
+// is true if the client asked for a JSON response.
+func newRequest(r *http.Request, pattern string, parts map[string]interface{}) (*data.Struct, bool) {
+	headers, isJSON := Headers(r.Header)
+
+	media := "text"
+	if isJSON {
+		media = "json"
+	}
+
+	body := bytes.Buffer{}
+	_, _ = body.ReadFrom(r.Body)
+
+	request := data.NewStruct(packageRequestType()).FromBuiltinPackage()
+	request.SetAlways("Method", r.Method)
+	request.SetAlways("URL", data.NewStructFromMap(map[string]interface{}{
+		"Path":  r.URL.Path,
+		"Parts": data.NewMapFromMap(parts),
+	}))
+	request.SetAlways("Endpoint", pattern)
+	request.SetAlways("Media", media)
+	request.SetAlways("Headers", headers)
+	request.SetAlways("Parameters", Parameters(r.URL.Query()))
+	request.SetAlways("Authentication", "none")
+	request.SetAlways("Authenticated", false)
+	request.SetAlways("IsAdmin", false)
+	request.SetAlways("Username", "")
+	request.SetAlways("Body", body.String())
+
+	return request, isJSON
+}
+
+// packageRequestType returns the type of the request object. The http package in
+// the Ego library declares the same Request type, along with its methods such as
+// Context(). If the library package has been imported, its type is used so those
+// methods can be called by the handler.
+func packageRequestType() *data.Type {
+	if pkg, found := bytecode.GetPackage("http"); found {
+		if v, found := pkg.Get("Request"); found {
+			if t, ok := v.(*data.Type); ok {
+				return t
+			}
+		}
+	}
+
+	return requestType
+}
+
+// findRoute returns the route whose pattern best matches the path, and the map of
+// the fields of the path described by the pattern. If no route matches, the route
+// is nil.
+func findRoute(path string) (*route, map[string]interface{}) {
+	var (
+		best      *route
+		bestParts map[string]interface{}
+		bestCount = -1
+	)
+
+	serverLock.Lock()
+	defer serverLock.Unlock()
+
+	for _, r := range routes {
+		parts, count := r.match(path)
+		if count > bestCount {
+			best, bestParts, bestCount = r, parts, count
+		}
+	}
+
+	return best, bestParts
+}
+
+// match determines if the route pattern matches the path. If it does, the result
+// is the map of the path fields and the number of fields in the pattern, so the
+// most specific pattern can be chosen. If it does not match, the count is -1.
+func (r *route) match(path string) (map[string]interface{}, int) {
+	patternFields := fields(r.pattern)
+	pathFields := fields(path)
+
+	if len(pathFields) < len(patternFields) {
+		return nil, -1
+	}
+
+	if len(pathFields) > len(patternFields) && !strings.HasSuffix(r.pattern, "/") {
+		return nil, -1
+	}
+
+	if len(patternFields) == 0 {
+		return map[string]interface{}{}, 0
+	}
+
+	parts := URLParts(r.pattern, path)
+
+	for _, field := range patternFields {
+		if matched, ok := parts[field].(bool); ok && !matched {
+			return nil, -1
+		}
+	}
+
+	return parts, len(patternFields)
+}
+
+// fields returns the list of fields in a URL path.
+func fields(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
@@ -0,0 +1,294 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/symbols"
+)
+
+// testHandler is a native handler function that greets the name from the URL,
+// or writes a message if the URL has no name.
+func testHandler(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	w := args.Get(0).(*data.Struct)
+	r := args.Get(1).(*data.Struct)
+
+	s.SetAlways(defs.ThisVariable, w)
+
+	url := r.GetAlways("URL").(*data.Struct)
+	parts := url.GetAlways("Parts").(*data.Map)
+
+	name, found, _ := parts.Get("name")
+	if !found {
+		return writeMessage(s, data.NewList("no name"))
+	}
+
+	if _, err := writeHeader(s, data.NewList("X-Test", "yes")); err != nil {
+		return nil, err
+	}
+
+	if _, err := writeStatus(s, data.NewList(http.StatusCreated)); err != nil {
+		return nil, err
+	}
+
+	return write(s, data.NewList("hello "+data.String(name)+" "+data.String(r.GetAlways("Body"))))
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	s := symbols.NewSymbolTable("http test")
+	Initialize(s)
+
+	routes = nil
+
+	handler := data.Function{
+		Declaration: &data.Declaration{
+			Name: "handler",
+			Parameters: []data.Parameter{
+				{Name: "w", Type: data.InterfaceType},
+				{Name: "r", Type: data.InterfaceType},
+			},
+		},
+		Value: testHandler,
+	}
+
+	for _, pattern := range []string{"/hello/{{name}}", "/info/"} {
+		if _, err := handleFunc(s, data.NewList(pattern, handler)); err != nil {
+			t.Fatalf("HandleFunc() error: %v", err)
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(serveHTTP))
+}
+
+func TestServeHTTP(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/hello/world", "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Post() error: %v", err)
+	}
+
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	if resp.Header.Get("X-Test") != "yes" {
+		t.Errorf("Expected X-Test header, got %v", resp.Header)
+	}
+
+	if string(b) != "hello world payload\n" {
+		t.Errorf("Unexpected body %q", string(b))
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/info/x/y", nil)
+	req.Header.Set("Accept", defs.JSONMediaType)
+
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+
+	b, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.Header.Get(defs.ContentTypeHeader) != defs.JSONMediaType || string(b) != `{"message":"no name"}` {
+		t.Errorf("Unexpected JSON response %q, %v", string(b), resp.Header)
+	}
+
+	resp, err = http.Get(server.URL + "/missing")
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestServeHTTPConcurrent(t *testing.T) {
+	const requests = 200
+
+	// The handler is registered from a function, whose global scope holds the count.
+	global := symbols.NewSymbolTable("http test")
+	global.SetAlways("count", 0)
+
+	s := symbols.NewChildSymbolTable("main", global).Boundary(true)
+	Initialize(s)
+
+	routes = nil
+
+	// The handler reads the global count, and increments it while holding a lock,
+	// as an Ego handler would using a sync.Mutex.
+	var lock sync.Mutex
+
+	handler := data.Function{
+		Declaration: &data.Declaration{
+			Name: "handler",
+			Parameters: []data.Parameter{
+				{Name: "w", Type: data.InterfaceType},
+				{Name: "r", Type: data.InterfaceType},
+			},
+		},
+		Value: func(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+			s.SetAlways(defs.ThisVariable, args.Get(0))
+
+			v, _ := s.Get("count")
+
+			lock.Lock()
+			count, _ := s.Get("count")
+			err := s.Set("count", data.IntOrZero(count)+1)
+			lock.Unlock()
+
+			if err != nil {
+				return nil, err
+			}
+
+			return write(s, data.NewList(data.String(v)))
+		},
+	}
+
+	if _, err := handleFunc(s, data.NewList("/count", handler)); err != nil {
+		t.Fatalf("HandleFunc() error: %v", err)
+	}
+
+	if routes[0].symbols != global || !global.IsShared() {
+		t.Fatal("HandleFunc() did not share the global scope of the handler")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(serveHTTP))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			resp, err := http.Get(server.URL + "/count")
+			if err != nil {
+				t.Errorf("Get() error: %v", err)
+
+				return
+			}
+
+			_, _ = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}()
+	}
+
+	wg.Wait()
+
+	if count, _ := global.Get("count"); data.IntOrZero(count) != requests {
+		t.Errorf("Expected count %d, got %v", requests, count)
+	}
+}
+
+func TestRouteMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    int
+	}{
+		{
+			name:    "exact path",
+			pattern: "/hooks",
+			path:    "/hooks",
+			want:    1,
+		},
+		{
+			name:    "longer path",
+			pattern: "/hooks",
+			path:    "/hooks/github",
+			want:    -1,
+		},
+		{
+			name:    "subtree path",
+			pattern: "/hooks/",
+			path:    "/hooks/github",
+			want:    1,
+		},
+		{
+			name:    "named field",
+			pattern: "/hooks/{{source}}",
+			path:    "/hooks/github",
+			want:    2,
+		},
+		{
+			name:    "different path",
+			pattern: "/hooks/{{source}}",
+			path:    "/events/github",
+			want:    -1,
+		},
+		{
+			name:    "root pattern",
+			pattern: "/",
+			path:    "/anything",
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &route{pattern: tt.pattern}
+
+			if _, got := r.match(tt.path); got != tt.want {
+				t.Errorf("match() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	s := symbols.NewSymbolTable("http test")
+	Initialize(s)
+
+	result := make(chan interface{})
+
+	go func() {
+		v, _ := listenAndServe(s, data.NewList("127.0.0.1:0"))
+		result <- v
+	}()
+
+	// Wait for the server to start.
+	for i := 0; i < 100; i++ {
+		serverLock.Lock()
+		running := server != nil
+		serverLock.Unlock()
+
+		if running {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := shutdown(s, data.NewList("1s")); err != nil {
+		t.Fatalf("Shutdown() error: %v", err)
+	}
+
+	select {
+	case v := <-result:
+		if v != nil {
+			t.Errorf("ListenAndServe() returned %v", v)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Errorf("ListenAndServe() did not return after Shutdown()")
+	}
+}
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"sync"
+
+	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/compiler"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/symbols"
+)
+
+// http.Request type specification. This has the same fields as the request
+// object passed to a service handler by the Ego server.
+const requestTypeSpec = `
+type Request struct {
+	Method         string
+	URL            struct {
+						Path  string
+						Parts map[string]interface{}
+					}
+	Endpoint       string
+	Media          string
+	Headers        map[string][]string
+	Parameters     map[string][]string
+	Authentication string
+	Authenticated  bool
+	IsAdmin        bool
+	Username       string
+	Body           string
+}`
+
+// http.ResponseWriter type specification. This has the same fields as the
+// response object passed to a service handler by the Ego server. The json
+// field is true if the client asked for a JSON response, and the response
+// field holds the value to be sent as JSON.
+const responseWriterTypeSpec = `
+type ResponseWriter struct {
+	Status   int
+	Buffer   string
+	Headers  map[string][]string
+	json     bool
+	response interface{}
+}`
+
+const (
+	statusFieldName   = "Status"
+	bufferFieldName   = "Buffer"
+	headersFieldName  = "Headers"
+	jsonFieldName     = "json"
+	responseFieldName = "response"
+)
+
+var requestType *data.Type
+var responseWriterType *data.Type
+var initLock sync.Mutex
+
+func Initialize(s *symbols.SymbolTable) {
+	initLock.Lock()
+	defer initLock.Unlock()
+
+	if requestType == nil {
+		rt, _ := compiler.CompileTypeSpec(requestTypeSpec, nil)
+		requestType = rt.SetPackage("http")
+
+		t, _ := compiler.CompileTypeSpec(responseWriterTypeSpec, nil)
+
+		t.DefineFunction("WriteStatus", &data.Declaration{
+			Name: "WriteStatus",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "status",
+					Type: data.IntType,
+				},
+			},
+		}, writeStatus)
+
+		t.DefineFunction("Write", &data.Declaration{
+			Name: "Write",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "item",
+					Type: data.InterfaceType,
+				},
+			},
+		}, write)
+
+		t.DefineFunction("WriteHeader", &data.Declaration{
+			Name: "WriteHeader",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "name",
+					Type: data.StringType,
+				},
+				{
+					Name: "item",
+					Type: data.StringType,
+				},
+			},
+		}, writeHeader)
+
+		t.DefineFunction("WriteMessage", &data.Declaration{
+			Name: "WriteMessage",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "msg",
+					Type: data.StringType,
+				},
+			},
+		}, writeMessage)
+
+		t.DefineFunction("WriteJSON", &data.Declaration{
+			Name: "WriteJSON",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "i",
+					Type: data.InterfaceType,
+				},
+			},
+		}, writeJSON)
+
+		responseWriterType = t.SetPackage("http")
+	}
+
+	if _, found := s.Root().Get("http"); !found {
+		newpkg := data.NewPackageFromMap("http", map[string]interface{}{
+			"HandleFunc": data.Function{
+				Declaration: &data.Declaration{
+					Name: "HandleFunc",
+					Parameters: []data.Parameter{
+						{
+							Name: "pattern",
+							Type: data.StringType,
+						},
+						{
+							Name: "handler",
+							Type: data.InterfaceType,
+						},
+					},
+				},
+				Value: handleFunc,
+			},
+			"ListenAndServe": data.Function{
+				Declaration: &data.Declaration{
+					Name: "ListenAndServe",
+					Parameters: []data.Parameter{
+						{
+							Name: "addr",
+							Type: data.StringType,
+						},
+					},
+					Returns: []*data.Type{data.ErrorType},
+				},
+				Value: listenAndServe,
+			},
+			"Shutdown": data.Function{
+				Declaration: &data.Declaration{
+					Name: "Shutdown",
+					Parameters: []data.Parameter{
+						{
+							Name: "timeout",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns:  []*data.Type{data.ErrorType},
+					ArgCount: data.Range{0, 1},
+				},
+				Value: shutdown,
+			},
+			"Request":        requestType,
+			"ResponseWriter": responseWriterType,
+		})
+
+		pkg, _ := bytecode.GetPackage(newpkg.Name)
+		pkg.Merge(newpkg)
+		s.Root().SetAlways(newpkg.Name, newpkg)
+	}
+}
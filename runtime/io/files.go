@@ -66,6 +66,30 @@ func getThis(s *symbols.SymbolTable) *data.Struct {
 	return this
 }
 
+// NativeFile returns the native file for an Ego file value, which is either
+// a file opened with io.Open() or an os.File value. The boolean result is
+// false if the value is not an open file. This is used by other packages
+// that read or write files, such as json and csv.
+func NativeFile(v interface{}) (*os.File, bool) {
+	if pointer, ok := v.(*interface{}); ok && pointer != nil {
+		v = *pointer
+	}
+
+	switch actual := v.(type) {
+	case *os.File:
+		return actual, actual != nil
+
+	case *data.Struct:
+		if data.BoolOrFalse(actual.GetAlways(validFieldName)) {
+			f, ok := actual.GetAlways(fileFieldName).(*os.File)
+
+			return f, ok && f != nil
+		}
+	}
+
+	return nil, false
+}
+
 // Helper function that gets the file handle for a all to a
 // handle-based function.
 func getFile(fn string, s *symbols.SymbolTable) (*os.File, error) {
@@ -5,12 +5,11 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/tucats/ego/app-cli/settings"
 	"github.com/tucats/ego/data"
-	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
 	"github.com/tucats/ego/runtime/time"
 	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
 )
 
 // expand expands a list of file or path names into a list of files.
@@ -22,7 +21,6 @@ func expand(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 		ext = data.String(args.Get(1))
 	}
 
-	path = sandboxName(path)
 	list, err := ExpandPath(path, ext)
 
 	// Rewrap as an Ego array
@@ -41,7 +39,10 @@ func expand(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 func ExpandPath(path, ext string) ([]string, error) {
 	names := []string{}
 
-	path = sandboxName(path)
+	path, err := util.SandboxPath(path)
+	if err != nil {
+		return names, err
+	}
 
 	// Can we read this as a directory?
 	fi, err := os.ReadDir(path)
@@ -88,7 +89,12 @@ func readDirectory(s *symbols.SymbolTable, args data.List) (interface{}, error)
 	path := data.String(args.Get(0))
 	result := data.NewArray(entryType, 0)
 
-	path = sandboxName(path)
+	path, err := util.SandboxPath(path)
+	if err != nil {
+		err = errors.New(err).In("ReadDir")
+
+		return data.NewList(nil, err), err
+	}
 
 	files, err := os.ReadDir(path)
 	if err != nil {
@@ -114,15 +120,3 @@ func readDirectory(s *symbols.SymbolTable, args data.List) (interface{}, error)
 
 	return data.NewList(result, nil), nil
 }
-
-func sandboxName(path string) string {
-	if sandboxPrefix := settings.Get(defs.SandboxPathSetting); sandboxPrefix != "" {
-		if strings.HasPrefix(path, sandboxPrefix) {
-			return path
-		}
-
-		return filepath.Join(sandboxPrefix, path)
-	}
-
-	return path
-}
@@ -21,7 +21,11 @@ func openFile(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 		modeValue             = "input"
 	)
 
-	fname, err := filepath.Abs(sandboxName(data.String(args.Get(0))))
+	fname, err := util.SandboxPath(data.String(args.Get(0)))
+	if err == nil {
+		fname, err = filepath.Abs(fname)
+	}
+
 	if err != nil {
 		err = errors.New(err).In("ReadDir")
 
@@ -37,13 +37,7 @@ func readFile(symbols *symbols.SymbolTable, args data.List) (interface{}, error)
 
 	// If the result is a map or an array, convert ot the Ego version
 	// of a map or array.
-	if m, ok := v.(map[string]interface{}); ok {
-		v = data.NewMapFromMap(m)
-	} else if a, ok := v.([]interface{}); ok {
-		v = data.NewArrayFromInterfaces(data.InterfaceType, a...)
-	}
-
-	return data.NewList(v, nil), nil
+	return data.NewList(egoValue(v), nil), nil
 }
 
 func writeFile(symbols *symbols.SymbolTable, args data.List) (interface{}, error) {
@@ -88,3 +88,34 @@ func TestMarshal_Elements(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshal_Tags(t *testing.T) {
+	itemType := data.TypeDefinition("Item", data.StructureType(
+		data.Field{Name: "Name", Type: data.StringType},
+		data.Field{Name: "Count", Type: data.IntType},
+		data.Field{Name: "Note", Type: data.StringType},
+	).SetFieldTag("Name", `json:"item_name"`).
+		SetFieldTag("Count", `json:"count,string"`).
+		SetFieldTag("Note", `json:"-"`))
+
+	item := data.NewStruct(itemType)
+	pointer := interface{}(item)
+
+	text := `{"item_name":"bolt","count":"12","Note":"ignored"}`
+
+	if _, err := unmarshal(nil, data.NewList(text, &pointer)); err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+
+	if got := item.GetAlways("Name"); got != "bolt" {
+		t.Errorf("unmarshal() Name = %v, want bolt", got)
+	}
+
+	if got := item.GetAlways("Count"); got != 12 {
+		t.Errorf("unmarshal() Count = %#v, want 12", got)
+	}
+
+	if got := item.GetAlways("Note"); got != "" {
+		t.Errorf("unmarshal() Note = %v, want empty", got)
+	}
+}
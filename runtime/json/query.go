@@ -0,0 +1,893 @@
+package json
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+// The kinds of selectors that can appear in a JSONPath segment.
+const (
+	nameSelector = iota
+	wildcardSelector
+	indexSelector
+	sliceSelector
+	filterSelector
+)
+
+// segment is a single step of a JSONPath expression, such as ".name" or "[0]".
+// A recursive segment, written with "..", applies its selectors to a node and to
+// all of its descendants.
+type segment struct {
+	recursive bool
+	selectors []selector
+}
+
+// selector chooses the children of a node. A segment can have several selectors,
+// written as a list in brackets such as "[0,2]" or "['a','b']".
+type selector struct {
+	kind   int
+	name   string
+	index  int
+	slice  [3]*int
+	filter expression
+}
+
+// expression is a filter expression, written as "[?(...)]". The test() function
+// returns true if the current node passes the filter. The value() function returns
+// the value of the expression, and false if it has no value, such as a path that
+// does not select any node.
+type expression interface {
+	test(current, root interface{}) bool
+	value(current, root interface{}) (interface{}, bool)
+}
+
+// pathExpr is a path in a filter expression, relative to the current node ("@")
+// or to the root value ("$").
+type pathExpr struct {
+	relative bool
+	segments []segment
+}
+
+// literalExpr is a string, number, boolean, or null value in a filter expression.
+type literalExpr struct {
+	v interface{}
+}
+
+// compareExpr compares two values in a filter expression.
+type compareExpr struct {
+	operator    string
+	left, right expression
+}
+
+// logicalExpr combines two filter expressions with "&&" or "||".
+type logicalExpr struct {
+	operator    string
+	left, right expression
+}
+
+// notExpr negates a filter expression.
+type notExpr struct {
+	e expression
+}
+
+// query implements the json.Query() function. This evaluates a JSONPath expression
+// such as "$.items[?(@.price > 10)].name" over a value made of maps, arrays, and
+// structures, such as the result of Unmarshal(). The result is an array of the
+// values that the expression selects, in the order they are found.
+func query(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	path := data.String(args.Get(1))
+
+	segments, err := parseQuery(path)
+	if err != nil {
+		return data.NewList(nil, errors.New(err).In("Query")), nil
+	}
+
+	root := args.Get(0)
+	if pointer, ok := root.(*interface{}); ok && pointer != nil {
+		root = *pointer
+	}
+
+	nodes := evaluate(segments, root, root)
+	result := data.NewArray(data.InterfaceType, len(nodes))
+
+	for i, node := range nodes {
+		_ = result.Set(i, egoValue(node))
+	}
+
+	return data.NewList(result, nil), nil
+}
+
+// parseQuery parses a JSONPath expression, which must start with "$".
+func parseQuery(path string) ([]segment, error) {
+	p := &pathParser{text: strings.TrimSpace(path)}
+
+	if !p.consume("$") {
+		return nil, errors.ErrInvalidJSONPath.Context(path)
+	}
+
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.text) {
+		return nil, errors.ErrInvalidJSONPath.Context(p.text[p.pos:])
+	}
+
+	return segments, nil
+}
+
+// evaluate applies the segments of a path to a node, and returns the list of
+// nodes that are selected.
+func evaluate(segments []segment, current, root interface{}) []interface{} {
+	nodes := []interface{}{current}
+
+	for _, seg := range segments {
+		next := []interface{}{}
+
+		for _, node := range nodes {
+			candidates := []interface{}{node}
+			if seg.recursive {
+				candidates = descendants(node, candidates)
+			}
+
+			for _, candidate := range candidates {
+				for _, sel := range seg.selectors {
+					next = append(next, sel.apply(candidate, root)...)
+				}
+			}
+		}
+
+		nodes = next
+	}
+
+	return nodes
+}
+
+// apply returns the children of the node chosen by the selector.
+func (sel selector) apply(node, root interface{}) []interface{} {
+	switch sel.kind {
+	case nameSelector:
+		if v, found := member(node, sel.name); found {
+			return []interface{}{v}
+		}
+
+	case wildcardSelector:
+		return children(node)
+
+	case indexSelector:
+		if list, ok := elements(node); ok {
+			i := sel.index
+			if i < 0 {
+				i += len(list)
+			}
+
+			if i >= 0 && i < len(list) {
+				return []interface{}{list[i]}
+			}
+		}
+
+	case sliceSelector:
+		if list, ok := elements(node); ok {
+			result := []interface{}{}
+			for _, i := range sliceIndices(len(list), sel.slice) {
+				result = append(result, list[i])
+			}
+
+			return result
+		}
+
+	case filterSelector:
+		result := []interface{}{}
+
+		for _, child := range children(node) {
+			if sel.filter.test(child, root) {
+				result = append(result, child)
+			}
+		}
+
+		return result
+	}
+
+	return nil
+}
+
+// sliceIndices returns the indexes of an array of the given length that are
+// selected by a slice with optional start, end, and step values. As in Python,
+// negative start and end values count from the end of the array.
+func sliceIndices(length int, slice [3]*int) []int {
+	step := 1
+	if slice[2] != nil {
+		step = *slice[2]
+	}
+
+	if step == 0 {
+		return nil
+	}
+
+	bound := func(p *int, defaultValue, low, high int) int {
+		if p == nil {
+			return defaultValue
+		}
+
+		i := *p
+		if i < 0 {
+			i += length
+		}
+
+		if i < low {
+			return low
+		}
+
+		if i > high {
+			return high
+		}
+
+		return i
+	}
+
+	result := []int{}
+
+	if step > 0 {
+		start := bound(slice[0], 0, 0, length)
+		end := bound(slice[1], length, 0, length)
+
+		for i := start; i < end; i += step {
+			result = append(result, i)
+		}
+	} else {
+		start := bound(slice[0], length-1, -1, length-1)
+		end := bound(slice[1], -1, -1, length-1)
+
+		for i := start; i > end; i += step {
+			result = append(result, i)
+		}
+	}
+
+	return result
+}
+
+// member returns the value of the named member of a map or structure.
+func member(node interface{}, name string) (interface{}, bool) {
+	switch actual := node.(type) {
+	case *data.Map:
+		v, found, err := actual.Get(name)
+
+		return v, found && err == nil
+
+	case *data.Struct:
+		return actual.Get(name)
+
+	case map[string]interface{}:
+		v, found := actual[name]
+
+		return v, found
+	}
+
+	return nil, false
+}
+
+// children returns the members of a map or structure in key order, or the
+// elements of an array.
+func children(node interface{}) []interface{} {
+	result := []interface{}{}
+
+	switch actual := node.(type) {
+	case *data.Map:
+		for _, key := range actual.Keys() {
+			v, _, _ := actual.Get(key)
+			result = append(result, v)
+		}
+
+	case *data.Struct:
+		for _, name := range actual.FieldNames(false) {
+			result = append(result, actual.GetAlways(name))
+		}
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(actual))
+		for key := range actual {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			result = append(result, actual[key])
+		}
+
+	default:
+		result, _ = elements(node)
+	}
+
+	return result
+}
+
+// elements returns the elements of an array. The boolean result is false if
+// the node is not an array.
+func elements(node interface{}) ([]interface{}, bool) {
+	switch actual := node.(type) {
+	case *data.Array:
+		result := make([]interface{}, actual.Len())
+		for i := range result {
+			result[i], _ = actual.Get(i)
+		}
+
+		return result, true
+
+	case []interface{}:
+		return actual, true
+	}
+
+	return nil, false
+}
+
+// descendants appends all the descendants of a node to the list, with each
+// node before its own children.
+func descendants(node interface{}, list []interface{}) []interface{} {
+	for _, child := range children(node) {
+		list = append(list, child)
+		list = descendants(child, list)
+	}
+
+	return list
+}
+
+func (e pathExpr) test(current, root interface{}) bool {
+	start := root
+	if e.relative {
+		start = current
+	}
+
+	return len(evaluate(e.segments, start, root)) > 0
+}
+
+func (e pathExpr) value(current, root interface{}) (interface{}, bool) {
+	start := root
+	if e.relative {
+		start = current
+	}
+
+	nodes := evaluate(e.segments, start, root)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+
+	return nodes[0], true
+}
+
+func (e literalExpr) test(current, root interface{}) bool {
+	if b, ok := e.v.(bool); ok {
+		return b
+	}
+
+	return e.v != nil
+}
+
+func (e literalExpr) value(current, root interface{}) (interface{}, bool) {
+	return e.v, true
+}
+
+func (e compareExpr) test(current, root interface{}) bool {
+	left, leftFound := e.left.value(current, root)
+	right, rightFound := e.right.value(current, root)
+
+	// A path that does not select a single node only equals another such path.
+	if !leftFound || !rightFound {
+		equal := !leftFound && !rightFound
+
+		switch e.operator {
+		case "==", "<=", ">=":
+			return equal
+		case "!=":
+			return !equal
+		}
+
+		return false
+	}
+
+	var order int
+
+	switch {
+	case data.IsNumeric(left) && data.IsNumeric(right):
+		l, r := data.Float64OrZero(left), data.Float64OrZero(right)
+		if l < r {
+			order = -1
+		} else if l > r {
+			order = 1
+		}
+
+	case isString(left) && isString(right):
+		order = strings.Compare(left.(string), right.(string))
+
+	default:
+		// Other values, such as booleans and null, can only be compared
+		// for equality.
+		equal := isScalar(left) && isScalar(right) && left == right
+
+		switch e.operator {
+		case "==", "<=", ">=":
+			return equal
+		case "!=":
+			return !equal
+		}
+
+		return false
+	}
+
+	switch e.operator {
+	case "==":
+		return order == 0
+	case "!=":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	}
+
+	return false
+}
+
+func (e compareExpr) value(current, root interface{}) (interface{}, bool) {
+	return e.test(current, root), true
+}
+
+func (e logicalExpr) test(current, root interface{}) bool {
+	if e.operator == "&&" {
+		return e.left.test(current, root) && e.right.test(current, root)
+	}
+
+	return e.left.test(current, root) || e.right.test(current, root)
+}
+
+func (e logicalExpr) value(current, root interface{}) (interface{}, bool) {
+	return e.test(current, root), true
+}
+
+func (e notExpr) test(current, root interface{}) bool {
+	return !e.e.test(current, root)
+}
+
+func (e notExpr) value(current, root interface{}) (interface{}, bool) {
+	return e.test(current, root), true
+}
+
+func isString(v interface{}) bool {
+	_, ok := v.(string)
+
+	return ok
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case nil, bool, string:
+		return true
+	}
+
+	return data.IsNumeric(v)
+}
+
+// pathParser reads a JSONPath expression from its text.
+type pathParser struct {
+	text string
+	pos  int
+}
+
+// parseSegments reads the segments of a path that follow the "$" or "@" that
+// starts it. In a filter expression, the path ends at the first character that
+// cannot start a segment.
+func (p *pathParser) parseSegments() ([]segment, error) {
+	segments := []segment{}
+
+	for p.pos < len(p.text) {
+		var (
+			seg segment
+			err error
+		)
+
+		switch {
+		case p.consume(".."):
+			seg, err = p.parseMember(true)
+
+		case p.consume("."):
+			seg, err = p.parseMember(false)
+
+		case p.peek() == '[':
+			seg.selectors, err = p.parseBracket()
+
+		default:
+			return segments, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		segments = append(segments, seg)
+	}
+
+	return segments, nil
+}
+
+// parseMember reads the member name, "*", or bracketed selectors that follow a
+// "." or "..".
+func (p *pathParser) parseMember(recursive bool) (segment, error) {
+	seg := segment{recursive: recursive}
+
+	if p.consume("*") {
+		seg.selectors = []selector{{kind: wildcardSelector}}
+
+		return seg, nil
+	}
+
+	if recursive && p.peek() == '[' {
+		selectors, err := p.parseBracket()
+		seg.selectors = selectors
+
+		return seg, err
+	}
+
+	start := p.pos
+	for p.pos < len(p.text) && isNameChar(p.text[p.pos]) {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return seg, p.syntaxError()
+	}
+
+	seg.selectors = []selector{{kind: nameSelector, name: p.text[start:p.pos]}}
+
+	return seg, nil
+}
+
+// parseBracket reads a list of selectors in brackets. Each selector is a quoted
+// member name, an index, a slice, or "*". A filter expression must be the only
+// selector in the brackets.
+func (p *pathParser) parseBracket() ([]selector, error) {
+	selectors := []selector{}
+
+	p.consume("[")
+	p.skipSpace()
+
+	if p.consume("?") {
+		p.skipSpace()
+
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+
+		if !p.consume("]") {
+			return nil, p.syntaxError()
+		}
+
+		return []selector{{kind: filterSelector, filter: e}}, nil
+	}
+
+	for {
+		p.skipSpace()
+
+		var sel selector
+
+		switch ch := p.peek(); {
+		case ch == '*':
+			p.pos++
+			sel.kind = wildcardSelector
+
+		case ch == '\'' || ch == '"':
+			text, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+
+			sel.kind = nameSelector
+			sel.name = text
+
+		default:
+			var err error
+
+			if sel, err = p.parseIndexOrSlice(); err != nil {
+				return nil, err
+			}
+		}
+
+		selectors = append(selectors, sel)
+
+		p.skipSpace()
+
+		if p.consume("]") {
+			return selectors, nil
+		}
+
+		if !p.consume(",") {
+			return nil, p.syntaxError()
+		}
+	}
+}
+
+// parseIndexOrSlice reads an array index, or a slice of the form start:end:step
+// where each part is optional.
+func (p *pathParser) parseIndexOrSlice() (selector, error) {
+	var (
+		sel   selector
+		parts [3]*int
+		count int
+	)
+
+	for count < 3 {
+		p.skipSpace()
+
+		if n, ok := p.parseInteger(); ok {
+			parts[count] = &n
+		}
+
+		count++
+
+		p.skipSpace()
+
+		if !p.consume(":") {
+			break
+		}
+	}
+
+	if count == 1 {
+		if parts[0] == nil {
+			return sel, p.syntaxError()
+		}
+
+		sel.kind = indexSelector
+		sel.index = *parts[0]
+
+		return sel, nil
+	}
+
+	sel.kind = sliceSelector
+	sel.slice = parts
+
+	return sel, nil
+}
+
+// parseInteger reads an optionally signed integer.
+func (p *pathParser) parseInteger() (int, bool) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+
+	for p.pos < len(p.text) && p.text[p.pos] >= '0' && p.text[p.pos] <= '9' {
+		p.pos++
+	}
+
+	n, err := strconv.Atoi(p.text[start:p.pos])
+	if err != nil {
+		p.pos = start
+
+		return 0, false
+	}
+
+	return n, true
+}
+
+// parseOr reads a filter expression, which is one or more expressions joined
+// by "||".
+func (p *pathParser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.skipSpace(); p.consume("||"); p.skipSpace() {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = logicalExpr{operator: "||", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseAnd reads one or more comparisons joined by "&&".
+func (p *pathParser) parseAnd() (expression, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.skipSpace(); p.consume("&&"); p.skipSpace() {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+
+		left = logicalExpr{operator: "&&", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseComparison reads a value, optionally followed by a comparison operator
+// and a second value.
+func (p *pathParser) parseComparison() (expression, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+
+	for _, operator := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(operator) {
+			right, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+
+			return compareExpr{operator: operator, left: left, right: right}, nil
+		}
+	}
+
+	return left, nil
+}
+
+// parsePrimary reads a path, a literal value, a negated value, or an expression
+// in parentheses.
+func (p *pathParser) parsePrimary() (expression, error) {
+	p.skipSpace()
+
+	switch ch := p.peek(); {
+	case ch == '!':
+		p.pos++
+
+		e, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notExpr{e: e}, nil
+
+	case ch == '(':
+		p.pos++
+
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+
+		if !p.consume(")") {
+			return nil, p.syntaxError()
+		}
+
+		return e, nil
+
+	case ch == '@' || ch == '$':
+		p.pos++
+
+		segments, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+
+		return pathExpr{relative: ch == '@', segments: segments}, nil
+
+	case ch == '\'' || ch == '"':
+		text, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+
+		return literalExpr{v: text}, nil
+
+	case ch == '-' || (ch >= '0' && ch <= '9'):
+		start := p.pos
+		for p.pos < len(p.text) && strings.IndexByte("+-.0123456789eE", p.text[p.pos]) >= 0 {
+			p.pos++
+		}
+
+		f, err := strconv.ParseFloat(p.text[start:p.pos], 64)
+		if err != nil {
+			p.pos = start
+
+			return nil, p.syntaxError()
+		}
+
+		return literalExpr{v: f}, nil
+	}
+
+	for text, v := range map[string]interface{}{"true": true, "false": false, "null": nil} {
+		if p.consume(text) {
+			return literalExpr{v: v}, nil
+		}
+	}
+
+	return nil, p.syntaxError()
+}
+
+// parseString reads a string in single or double quotes. A backslash escapes
+// the character that follows it.
+func (p *pathParser) parseString() (string, error) {
+	var text strings.Builder
+
+	quote := p.text[p.pos]
+	p.pos++
+
+	for p.pos < len(p.text) {
+		ch := p.text[p.pos]
+		p.pos++
+
+		switch ch {
+		case quote:
+			return text.String(), nil
+
+		case '\\':
+			if p.pos < len(p.text) {
+				text.WriteByte(p.text[p.pos])
+				p.pos++
+			}
+
+		default:
+			text.WriteByte(ch)
+		}
+	}
+
+	return "", p.syntaxError()
+}
+
+// consume skips past the text if it is next in the path, and returns true if
+// it was found.
+func (p *pathParser) consume(text string) bool {
+	if strings.HasPrefix(p.text[p.pos:], text) {
+		p.pos += len(text)
+
+		return true
+	}
+
+	return false
+}
+
+// peek returns the next character in the path, or zero at the end of the path.
+func (p *pathParser) peek() byte {
+	if p.pos < len(p.text) {
+		return p.text[p.pos]
+	}
+
+	return 0
+}
+
+func (p *pathParser) skipSpace() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// syntaxError returns an error that shows the remaining text of the path.
+func (p *pathParser) syntaxError() error {
+	if p.pos >= len(p.text) {
+		return errors.ErrInvalidJSONPath.Context(p.text)
+	}
+
+	return errors.ErrInvalidJSONPath.Context(p.text[p.pos:])
+}
+
+// isNameChar returns true if the character can be part of a member name that
+// follows a ".".
+func isNameChar(ch byte) bool {
+	return ch == '_' || ch >= 0x80 ||
+		(ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
@@ -0,0 +1,134 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/tucats/ego/data"
+)
+
+const storeJSON = `{
+	"store": {
+		"name": "corner",
+		"items": [
+			{"name": "bolt", "price": 5, "tags": ["small"]},
+			{"name": "drill", "price": 45.5, "stock": 0},
+			{"name": "saw", "price": 20, "tags": ["large", "sharp"]}
+		]
+	}
+}`
+
+func TestQuery(t *testing.T) {
+	value, err := unmarshal(nil, data.NewList(storeJSON))
+	if err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+
+	store := value.(data.List).Get(0)
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "member names",
+			path: "$.store.name",
+			want: `["corner"]`,
+		},
+		{
+			name: "bracket name and index",
+			path: "$['store'].items[0].name",
+			want: `["bolt"]`,
+		},
+		{
+			name: "negative index",
+			path: "$.store.items[-1].name",
+			want: `["saw"]`,
+		},
+		{
+			name: "wildcard",
+			path: "$.store.items[*].name",
+			want: `["bolt", "drill", "saw"]`,
+		},
+		{
+			name: "slice",
+			path: "$.store.items[1:].name",
+			want: `["drill", "saw"]`,
+		},
+		{
+			name: "reverse slice",
+			path: "$.store.items[::-1].name",
+			want: `["saw", "drill", "bolt"]`,
+		},
+		{
+			name: "union of indexes",
+			path: "$.store.items[0,2].name",
+			want: `["bolt", "saw"]`,
+		},
+		{
+			name: "recursive descent",
+			path: "$..tags[0]",
+			want: `["small", "large"]`,
+		},
+		{
+			name: "filter comparison",
+			path: "$.store.items[?(@.price > 10)].name",
+			want: `["drill", "saw"]`,
+		},
+		{
+			name: "filter with logical operators",
+			path: "$.store.items[?(@.price < 30 && !(@.name == 'bolt'))].name",
+			want: `["saw"]`,
+		},
+		{
+			name: "filter existence",
+			path: "$.store.items[?(@.stock)].name",
+			want: `["drill"]`,
+		},
+		{
+			name: "filter comparing to root",
+			path: "$.store.items[?(@.price == $.store.items[0].price)].name",
+			want: `["bolt"]`,
+		},
+		{
+			name: "no match",
+			path: "$.store.missing",
+			want: `[]`,
+		},
+		{
+			name:    "missing root",
+			path:    "store.name",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated bracket",
+			path:    "$.store.items[0",
+			wantErr: true,
+		},
+		{
+			name:    "invalid filter",
+			path:    "$.store.items[?(@.price >)]",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := query(nil, data.NewList(store, tt.path))
+			list := result.(data.List)
+
+			if e := list.Get(1); (e != nil) != tt.wantErr {
+				t.Fatalf("query() error = %v, wantErr %v", e, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got := data.String(list.Get(0)); got != tt.want {
+				t.Errorf("query() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	egoio "github.com/tucats/ego/runtime/io"
+	"github.com/tucats/ego/symbols"
+)
+
+// newDecoder implements the json.NewDecoder() function. The source of the JSON
+// text is a file opened with io.Open(), an os.File value, a string, or a byte
+// array. Values are read from the source one at a time, so a file can contain
+// a stream of JSON values, such as a JSON Lines file, of any size.
+func newDecoder(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var source io.Reader
+
+	switch actual := args.Get(0).(type) {
+	case string:
+		source = strings.NewReader(actual)
+
+	case *data.Array:
+		if actual.Type().Kind() == data.ByteKind {
+			source = bytes.NewReader(actual.GetBytes())
+		}
+	}
+
+	if source == nil {
+		f, ok := egoio.NativeFile(args.Get(0))
+		if !ok {
+			return nil, errors.ErrArgumentType.In("NewDecoder").Context(data.TypeOf(args.Get(0)).String())
+		}
+
+		source = f
+	}
+
+	d := data.NewStruct(decoderType).FromBuiltinPackage()
+	d.SetAlways(decoderFieldName, json.NewDecoder(source))
+
+	return d, nil
+}
+
+// decode implements the Decode() method, which reads the next JSON value and
+// stores it in the value the argument points to, in the same way as Unmarshal().
+// When there are no more values, the error is io.EOF.
+func decode(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var decodedValue interface{}
+
+	dec, err := getDecoder(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dec.Decode(&decodedValue); err != nil {
+		return data.NewList(errors.New(err).In("Decode")), nil
+	}
+
+	pointer, ok := args.Get(0).(*interface{})
+	if !ok {
+		return data.NewList(errors.ErrInvalidPointerType.In("Decode")), nil
+	}
+
+	return RemapDecodedValue(decodedValue, pointer, "json")
+}
+
+// more implements the More() method, which returns true if there is another
+// value in the current array or object, or in the source.
+func more(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	dec, err := getDecoder(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return dec.More(), nil
+}
+
+// token implements the Token() method, which returns the next JSON token. This is
+// a string for the delimiters "[", "]", "{", and "}", or the value of a string,
+// number, boolean, or null. Commas and colons are not returned. When there are no
+// more tokens, the error is io.EOF.
+func token(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	dec, err := getDecoder(s)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := dec.Token()
+	if err != nil {
+		return data.NewList(nil, errors.New(err).In("Token")), nil
+	}
+
+	if delim, ok := t.(json.Delim); ok {
+		t = delim.String()
+	}
+
+	return data.NewList(t, nil), nil
+}
+
+// newEncoder implements the json.NewEncoder() function. If there is a file opened
+// with io.Open() or os.Create(), the JSON text is written to the file. Otherwise,
+// the text is kept in the encoder, and can be read using the String() method.
+func newEncoder(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var (
+		buffer *bytes.Buffer
+		target io.Writer
+	)
+
+	if args.Len() > 0 {
+		f, ok := egoio.NativeFile(args.Get(0))
+		if !ok {
+			return nil, errors.ErrInvalidfileIdentifier.In("NewEncoder")
+		}
+
+		target = f
+	} else {
+		buffer = &bytes.Buffer{}
+		target = buffer
+	}
+
+	e := data.NewStruct(encoderType).FromBuiltinPackage()
+	e.SetAlways(encoderFieldName, json.NewEncoder(target))
+	e.SetAlways(bufferFieldName, buffer)
+
+	return e, nil
+}
+
+// encode implements the Encode() method, which writes the JSON text for a value,
+// followed by a newline.
+func encode(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	enc, _, err := getEncoder(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(data.Sanitize(args.Get(0))); err != nil {
+		return errors.New(err).In("Encode"), nil
+	}
+
+	return nil, nil
+}
+
+// setIndent implements the SetIndent() method. Each value written after this
+// call is formatted in the same way as MarshalIndent().
+func setIndent(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	enc, _, err := getEncoder(s)
+	if err != nil {
+		return nil, err
+	}
+
+	enc.SetIndent(data.String(args.Get(0)), data.String(args.Get(1)))
+
+	return nil, nil
+}
+
+// setEscapeHTML implements the SetEscapeHTML() method. If the argument is false,
+// the characters "<", ">", and "&" in strings are written as is, instead of being
+// escaped.
+func setEscapeHTML(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	enc, _, err := getEncoder(s)
+	if err != nil {
+		return nil, err
+	}
+
+	enc.SetEscapeHTML(data.BoolOrFalse(args.Get(0)))
+
+	return nil, nil
+}
+
+// encoderString implements the String() method, which returns the text written
+// by an encoder that does not have a file.
+func encoderString(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	_, buffer, err := getEncoder(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if buffer == nil {
+		return "", nil
+	}
+
+	return buffer.String(), nil
+}
+
+// getDecoder returns the native decoder for the receiver of a Decoder method.
+func getDecoder(s *symbols.SymbolTable) (*json.Decoder, error) {
+	this, err := getThis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	dec, ok := this.GetAlways(decoderFieldName).(*json.Decoder)
+	if !ok {
+		return nil, errors.ErrInvalidThis.Context(this.TypeString())
+	}
+
+	return dec, nil
+}
+
+// getEncoder returns the native encoder for the receiver of an Encoder method,
+// and the buffer it writes to if it does not have a file.
+func getEncoder(s *symbols.SymbolTable) (*json.Encoder, *bytes.Buffer, error) {
+	this, err := getThis(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	enc, ok := this.GetAlways(encoderFieldName).(*json.Encoder)
+	if !ok {
+		return nil, nil, errors.ErrInvalidThis.Context(this.TypeString())
+	}
+
+	buffer, _ := this.GetAlways(bufferFieldName).(*bytes.Buffer)
+
+	return enc, buffer, nil
+}
+
+// getThis returns the receiver of a Decoder or Encoder method.
+func getThis(s *symbols.SymbolTable) (*data.Struct, error) {
+	v, ok := s.Get(defs.ThisVariable)
+	if !ok {
+		return nil, errors.ErrNoFunctionReceiver
+	}
+
+	this, ok := v.(*data.Struct)
+	if !ok {
+		return nil, errors.ErrInvalidThis.Context(data.TypeOf(v).String())
+	}
+
+	return this, nil
+}
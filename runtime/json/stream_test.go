@@ -0,0 +1,119 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/symbols"
+)
+
+func TestDecoder(t *testing.T) {
+	s := symbols.NewSymbolTable("json test")
+	Initialize(s)
+
+	v, err := newDecoder(s, data.NewList("{\"a\": 1}\n{\"a\": [true, \"x\"]}\n"))
+	if err != nil {
+		t.Fatalf("NewDecoder() error = %v", err)
+	}
+
+	s.SetAlways(defs.ThisVariable, v)
+
+	count := 0
+
+	for {
+		if more, _ := more(s, data.NewList()); more != true {
+			break
+		}
+
+		var value interface{}
+
+		if result, _ := decode(s, data.NewList(&value)); result.(data.List).Get(0) != nil {
+			t.Fatalf("Decode() error = %v", result)
+		}
+
+		if _, ok := value.(*data.Map); !ok {
+			t.Fatalf("Decode() returned %T, want map", value)
+		}
+
+		count++
+	}
+
+	if count != 2 {
+		t.Errorf("Decode() read %d values, want 2", count)
+	}
+
+	var value interface{}
+
+	if result, _ := decode(s, data.NewList(&value)); result.(data.List).Get(0) == nil {
+		t.Errorf("Decode() at end of text expected error")
+	}
+
+	if _, err := newDecoder(s, data.NewList(42)); err == nil {
+		t.Errorf("NewDecoder() expected error for invalid source")
+	}
+}
+
+func TestDecoderToken(t *testing.T) {
+	s := symbols.NewSymbolTable("json test")
+	Initialize(s)
+
+	v, _ := newDecoder(s, data.NewList(`{"a": [1, null]}`))
+	s.SetAlways(defs.ThisVariable, v)
+
+	tokens := []interface{}{}
+
+	for {
+		result, _ := token(s, data.NewList())
+		list := result.(data.List)
+
+		if list.Get(1) != nil {
+			break
+		}
+
+		tokens = append(tokens, list.Get(0))
+	}
+
+	want := []interface{}{"{", "a", "[", float64(1), nil, "]", "}"}
+	if len(tokens) != len(want) {
+		t.Fatalf("Token() = %v, want %v", tokens, want)
+	}
+
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Errorf("Token() %d = %v, want %v", i, tokens[i], want[i])
+		}
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	s := symbols.NewSymbolTable("json test")
+	Initialize(s)
+
+	v, err := newEncoder(s, data.NewList())
+	if err != nil {
+		t.Fatalf("NewEncoder() error = %v", err)
+	}
+
+	s.SetAlways(defs.ThisVariable, v)
+
+	if e, _ := encode(s, data.NewList(data.NewArrayFromInterfaces(data.IntType, 1, 2))); e != nil {
+		t.Fatalf("Encode() error = %v", e)
+	}
+
+	_, _ = setIndent(s, data.NewList("", "  "))
+	_, _ = setEscapeHTML(s, data.NewList(false))
+
+	if e, _ := encode(s, data.NewList(data.NewMapFromMap(map[string]interface{}{"a": "<b>"}))); e != nil {
+		t.Fatalf("Encode() error = %v", e)
+	}
+
+	want := "[1,2]\n{\n  \"a\": \"<b>\"\n}\n"
+	if text, _ := encoderString(s, data.NewList()); text != want {
+		t.Errorf("String() = %q, want %q", text, want)
+	}
+
+	if _, err := newEncoder(s, data.NewList("not a file")); err == nil {
+		t.Errorf("NewEncoder() expected error for invalid file")
+	}
+}
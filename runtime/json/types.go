@@ -4,18 +4,164 @@ import (
 	"sync"
 
 	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/compiler"
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/symbols"
 )
 
+// json.Decoder type specification. The decoder field holds the native JSON
+// decoder that reads values from the source.
+const decoderTypeSpec = `
+type Decoder struct {
+	decoder interface{}
+}`
+
+// json.Encoder type specification. The encoder field holds the native JSON
+// encoder, and the buffer field holds the text written if there is no file.
+const encoderTypeSpec = `
+type Encoder struct {
+	encoder interface{}
+	buffer  interface{}
+}`
+
+const (
+	decoderFieldName = "decoder"
+	encoderFieldName = "encoder"
+	bufferFieldName  = "buffer"
+)
+
+var decoderType *data.Type
+var encoderType *data.Type
 var initLock sync.Mutex
 
 func Initialize(s *symbols.SymbolTable) {
 	initLock.Lock()
 	defer initLock.Unlock()
 
+	if decoderType == nil {
+		t, _ := compiler.CompileTypeSpec(decoderTypeSpec, nil)
+
+		t.DefineFunction("Decode", &data.Declaration{
+			Name: "Decode",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "value",
+					Type: data.PointerType(data.InterfaceType),
+				},
+			},
+			Returns: []*data.Type{data.ErrorType},
+		}, decode)
+
+		t.DefineFunction("More", &data.Declaration{
+			Name:    "More",
+			Type:    t,
+			Returns: []*data.Type{data.BoolType},
+		}, more)
+
+		t.DefineFunction("Token", &data.Declaration{
+			Name:    "Token",
+			Type:    t,
+			Returns: []*data.Type{data.InterfaceType, data.ErrorType},
+		}, token)
+
+		decoderType = t.SetPackage("json")
+
+		t, _ = compiler.CompileTypeSpec(encoderTypeSpec, nil)
+
+		t.DefineFunction("Encode", &data.Declaration{
+			Name: "Encode",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "value",
+					Type: data.InterfaceType,
+				},
+			},
+			Returns: []*data.Type{data.ErrorType},
+		}, encode)
+
+		t.DefineFunction("SetIndent", &data.Declaration{
+			Name: "SetIndent",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "prefix",
+					Type: data.StringType,
+				},
+				{
+					Name: "indent",
+					Type: data.StringType,
+				},
+			},
+		}, setIndent)
+
+		t.DefineFunction("SetEscapeHTML", &data.Declaration{
+			Name: "SetEscapeHTML",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "on",
+					Type: data.BoolType,
+				},
+			},
+		}, setEscapeHTML)
+
+		t.DefineFunction("String", &data.Declaration{
+			Name:    "String",
+			Type:    t,
+			Returns: []*data.Type{data.StringType},
+		}, encoderString)
+
+		encoderType = t.SetPackage("json")
+	}
+
 	if _, found := s.Root().Get("json"); !found {
 		newpkg := data.NewPackageFromMap("json", map[string]interface{}{
+			"NewDecoder": data.Function{
+				Declaration: &data.Declaration{
+					Name: "NewDecoder",
+					Parameters: []data.Parameter{
+						{
+							Name: "source",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{data.PointerType(decoderType)},
+				},
+				Value: newDecoder,
+			},
+			"NewEncoder": data.Function{
+				Declaration: &data.Declaration{
+					Name: "NewEncoder",
+					Parameters: []data.Parameter{
+						{
+							Name: "file",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns:  []*data.Type{data.PointerType(encoderType)},
+					ArgCount: data.Range{0, 1},
+				},
+				Value: newEncoder,
+			},
+			"Query": data.Function{
+				Declaration: &data.Declaration{
+					Name: "Query",
+					Parameters: []data.Parameter{
+						{
+							Name: "value",
+							Type: data.InterfaceType,
+						},
+						{
+							Name: "path",
+							Type: data.StringType,
+						},
+					},
+					Returns: []*data.Type{data.ArrayType(data.InterfaceType), data.ErrorType},
+				},
+				Value: query,
+			},
 			"WriteFile": data.Function{
 				Declaration: &data.Declaration{
 					Name: "WriteFile",
@@ -97,6 +243,8 @@ func Initialize(s *symbols.SymbolTable) {
 				},
 				Value: unmarshal,
 			},
+			"Decoder": decoderType,
+			"Encoder": encoderType,
 		})
 
 		pkg, _ := bytecode.GetPackage(newpkg.Name)
@@ -6,6 +6,7 @@ import (
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/errors"
 	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
 )
 
 // unmarshal reads a byte array or string as JSON data.
@@ -32,15 +33,7 @@ func unmarshal(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 
 	// If there is no model, assume a generic return value is okay
 	if args.Len() < 2 {
-		// Hang on, if the result is a map, then Ego won't be able to use it,
-		// so convert that to an EgoMap. Same for an array.
-		if m, ok := decodedValue.(map[string]interface{}); ok {
-			decodedValue = data.NewMapFromMap(m)
-		} else if a, ok := decodedValue.([]interface{}); ok {
-			decodedValue = data.NewArrayFromInterfaces(data.InterfaceType, a...)
-		}
-
-		return data.NewList(decodedValue, err), err
+		return data.NewList(egoValue(decodedValue), err), err
 	}
 
 	// There is a model, so do some mapping if possible.
@@ -49,14 +42,15 @@ func unmarshal(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 		return data.NewList(errors.ErrInvalidPointerType), nil
 	}
 
-	return remapDecodedValue(decodedValue, pointer)
+	return RemapDecodedValue(decodedValue, pointer, "json")
 }
 
 // When decoding a JSON value into an Ego object by pointer, we must remap the actual data item to a suitable
 // Ego object and write it via the interface pointer provided by the caller. Basically, this handles the fact
 // that the data value and the pointer are both abstract interface types, but we have to do the conversions
-// using the underlying real value.
-func remapDecodedValue(decodedValue interface{}, destinationPointer *interface{}) (interface{}, error) {
+// using the underlying real value. The tag is the key used to read structure field tags, so other packages
+// that decode text into the same generic maps and arrays, such as yaml, can share this conversion.
+func RemapDecodedValue(decodedValue interface{}, destinationPointer *interface{}, tag string) (interface{}, error) {
 	var err error
 
 	destination := *destinationPointer
@@ -67,7 +61,7 @@ func remapDecodedValue(decodedValue interface{}, destinationPointer *interface{}
 		// If we are writing to a struct, the JSON data has to be a map. Use the map keys as struct field
 		// names and attempt to write the values to the structure.
 		if m, ok := decodedValue.(map[string]interface{}); ok {
-			for k, v := range m {
+			for k, v := range taggedFields(target.Type(), m, tag) {
 				if err = target.Set(k, v); err != nil {
 					err = errors.New(err).In("Unmarshal")
 
@@ -92,7 +86,7 @@ func remapDecodedValue(decodedValue interface{}, destinationPointer *interface{}
 					return nil, err
 				}
 
-				v2 := v
+				v2 := egoValue(v)
 
 				if !target.ElementType().IsInterface() {
 					v2, err = data.Coerce(v, data.InstanceOfType(target.ElementType()))
@@ -120,10 +114,12 @@ func remapDecodedValue(decodedValue interface{}, destinationPointer *interface{}
 			target.SetSize(len(m))
 
 			for k, v := range m {
-				if target.Type().Kind() == data.StructKind {
+				if target.Type().BaseType().Kind() == data.StructKind {
 					if mm, ok := v.(map[string]interface{}); ok {
-						v = data.NewStructOfTypeFromMap(target.Type(), mm)
+						v = data.NewStructOfTypeFromMap(target.Type(), taggedFields(target.Type(), mm, tag))
 					}
+				} else {
+					v = egoValue(v)
 				}
 
 				if err = target.Set(k, v); err != nil {
@@ -138,6 +134,12 @@ func remapDecodedValue(decodedValue interface{}, destinationPointer *interface{}
 
 		return data.NewList(nil), nil
 
+	case nil, data.Interface:
+		// If the destination is an empty interface, it can hold any decoded value.
+		*destinationPointer = egoValue(decodedValue)
+
+		return data.NewList(nil), nil
+
 	default:
 		// Not a complex type, so convert the abstrct value to a suitable Ego type.
 		v, err := data.Coerce(decodedValue, target)
@@ -159,3 +161,87 @@ func remapDecodedValue(decodedValue interface{}, destinationPointer *interface{}
 		return data.NewList(err), err
 	}
 }
+
+// taggedFields converts a map of decoded JSON values to a map of structure field
+// values, using the tags of the structure type with the given key. A field tagged with a name
+// is read from the JSON value of that name, and a field tagged with "-" is never
+// read. If the tag has the "string" option, the field value is decoded from the
+// JSON string that contains it. Values that are not mapped by a tag are stored
+// in the field of the same name.
+func taggedFields(t *data.Type, m map[string]interface{}, key string) map[string]interface{} {
+	if !t.HasFieldTags() {
+		return m
+	}
+
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+
+	for _, field := range t.BaseType().FieldNames() {
+		tag, found := t.FieldTagValue(field, key)
+		if !found {
+			continue
+		}
+
+		name, options := data.ParseTagValue(tag)
+		if name == "" {
+			name = field
+		}
+
+		// Remove any value stored using the field name. The field can only
+		// be read from the tagged name, if it has one.
+		if name != field || tag == "-" {
+			delete(result, field)
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		v, found := m[name]
+		if !found {
+			continue
+		}
+
+		delete(result, name)
+
+		if text, ok := v.(string); ok && util.InList("string", options...) {
+			var decoded interface{}
+
+			if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+				v = decoded
+			}
+		}
+
+		result[field] = v
+	}
+
+	return result
+}
+
+// egoValue converts a generic decoded value to a value Ego can use. Maps and
+// arrays are converted to the Ego versions of the map and array, including any
+// maps and arrays they contain.
+func egoValue(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case map[string]interface{}:
+		result := data.NewMap(data.StringType, data.InterfaceType)
+		for key, value := range actual {
+			_, _ = result.Set(key, egoValue(value))
+		}
+
+		return result
+
+	case []interface{}:
+		result := data.NewArray(data.InterfaceType, len(actual))
+		for i, value := range actual {
+			_ = result.Set(i, egoValue(value))
+		}
+
+		return result
+
+	default:
+		return v
+	}
+}
@@ -4,15 +4,12 @@ import (
 	"io/fs"
 	"math"
 	"os"
-	"path/filepath"
-	"strings"
 
-	"github.com/tucats/ego/app-cli/settings"
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/data"
-	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
 	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
 )
 
 // readFile implements os.REadFile() which reads a file contents into a
@@ -23,7 +20,10 @@ func readFile(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 		return ui.Prompt(""), nil
 	}
 
-	name = sandboxName(name)
+	name, err := util.SandboxPath(name)
+	if err != nil {
+		return nil, errors.New(err).In("ReadFile")
+	}
 
 	content, err := os.ReadFile(name)
 	if err != nil {
@@ -37,7 +37,10 @@ func readFile(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 
 // writeFile implements os.Writefile() writes a byte array (or string) to a file.
 func writeFile(s *symbols.SymbolTable, args data.List) (interface{}, error) {
-	fileName := sandboxName(data.String(args.Get(0)))
+	fileName, err := util.SandboxPath(data.String(args.Get(0)))
+	if err != nil {
+		return nil, errors.New(err).In("WriteFile")
+	}
 
 	// The file mode must be a valid uint32 value.
 	modeArg, err := args.GetInt(1)
@@ -94,15 +97,3 @@ func changeMode(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 
 	return nil, nil
 }
-
-func sandboxName(path string) string {
-	if sandboxPrefix := settings.Get(defs.SandboxPathSetting); sandboxPrefix != "" {
-		if strings.HasPrefix(path, sandboxPrefix) {
-			return path
-		}
-
-		return filepath.Join(sandboxPrefix, path)
-	}
-
-	return path
-}
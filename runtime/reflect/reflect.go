@@ -89,6 +89,10 @@ func describe(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 		m[data.MembersMDName] = s.FieldNamesArray(true)
 		m[data.PackageMDName] = s.PackageName()
 
+		if tags := fieldTags(s.Type()); tags != nil {
+			m[data.TagsMDName] = tags
+		}
+
 		return data.NewStructOfTypeFromMap(reflectionType, m), nil
 	}
 
@@ -112,6 +116,10 @@ func describe(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 			r[data.NameMDName] = t.Name()
 		}
 
+		if tags := fieldTags(t); tags != nil {
+			r[data.TagsMDName] = tags
+		}
+
 		functionList := t.FunctionNames()
 		if t.BaseType() != nil && t.BaseType().Kind() == data.InterfaceKind {
 			functionList = append(functionList, t.BaseType().FunctionNames()...)
@@ -381,3 +389,21 @@ func makeDeclaration(fd *data.Declaration) *data.Struct {
 
 	return data.NewStructOfTypeFromMap(funcDeclType, declaration)
 }
+
+// fieldTags returns a map of the field names of a structure type to their tag
+// strings. The result is nil if none of the fields have a tag.
+func fieldTags(t *data.Type) *data.Map {
+	if !t.HasFieldTags() {
+		return nil
+	}
+
+	tags := data.NewMap(data.StringType, data.StringType)
+
+	for _, name := range t.BaseType().FieldNames() {
+		if tag := t.FieldTag(name); tag != "" {
+			_, _ = tags.Set(name, tag)
+		}
+	}
+
+	return tags
+}
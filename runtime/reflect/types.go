@@ -36,6 +36,7 @@ type Reflection struct {
 	Builtins bool
 	Basetype string
 	Members []string
+	Tags map[string]string
 	Size int
 	Error error
 	Text string
@@ -5,6 +5,7 @@ const (
 	// Field names for runtime types.
 	baseURLFieldName   = "baseURL"
 	clientFieldName    = "client"
+	contextFieldName   = "context"
 	headersFieldName   = "Headers"
 	mediaTypeFieldName = "Mediatype"
 	responseFieldName  = "Response"
@@ -12,6 +13,18 @@ const (
 	verifyFieldName    = "verify"
 )
 
+// Field names for the rest.Request type.
+const (
+	bodyFieldName    = "Body"
+	cookiesFieldName = "Cookies"
+	filesFieldName   = "Files"
+	formFieldName    = "Form"
+	methodFieldName  = "Method"
+	outputFieldName  = "Output"
+	queryFieldName   = "Query"
+	urlFieldName     = "URL"
+)
+
 // Map key names for parsing a URL.
 const (
 	urlSchemeElement   = "urlScheme"
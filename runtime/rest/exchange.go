@@ -58,7 +58,11 @@ func Exchange(endpoint, method string, body interface{}, response interface{}, a
 	// Add the agen type to the request.
 	AddAgent(r, agentType)
 
-	if body != nil {
+	// A byte array body is sent as-is, using the media type supplied by the caller. Any
+	// other body is sent as JSON.
+	if b, ok := body.([]byte); ok {
+		r.SetBody(b)
+	} else if body != nil {
 		b, err := json.MarshalIndent(body, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
 		if err != nil {
 			return errors.New(err)
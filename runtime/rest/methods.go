@@ -36,6 +36,8 @@ func doGet(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 	url := applyBaseURL(data.String(args.Get(0)), this)
 	r := client.NewRequest()
 
+	applyContext(r, this)
+
 	isJSON := false
 
 	if media := this.GetAlways(mediaTypeFieldName); media != nil {
@@ -134,6 +136,9 @@ func doPost(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 	}
 
 	r := client.NewRequest()
+
+	applyContext(r, this)
+
 	switch actual := body.(type) {
 	default:
 		r.Body = actual
@@ -229,6 +234,9 @@ func doDelete(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 	}
 
 	r := client.NewRequest().SetBody(body)
+
+	applyContext(r, this)
+
 	isJSON := false
 
 	if media := this.GetAlways(mediaTypeFieldName); media != nil {
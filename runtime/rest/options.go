@@ -0,0 +1,145 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
+	"gopkg.in/resty.v1"
+)
+
+// Default wait times between retries of a failed request, when Retry() is called
+// without them.
+const (
+	defaultRetryWait    = 100 * time.Millisecond
+	defaultRetryMaxWait = 2 * time.Second
+)
+
+// setHeader implements the Header() function. This sets a header that is sent with
+// every request made by the client. An empty value removes the header.
+func setHeader(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	client, err := getClient(s)
+	if err != nil {
+		return nil, err
+	}
+
+	name := data.String(args.Get(0))
+	value := data.String(args.Get(1))
+
+	if value == "" {
+		client.Header.Del(name)
+	} else {
+		client.SetHeader(name, value)
+	}
+
+	return getThis(s), nil
+}
+
+// setQuery implements the Query() function. This sets a query parameter that is
+// added to the URL of every request made by the client.
+func setQuery(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	client, err := getClient(s)
+	if err != nil {
+		return nil, err
+	}
+
+	client.SetQueryParam(data.String(args.Get(0)), data.String(args.Get(1)))
+
+	return getThis(s), nil
+}
+
+// setTimeout implements the Timeout() function. This sets the maximum time a request
+// made by the client can take, including reading the response. The timeout can be a
+// time.Duration value or a duration string such as "30s". A zero duration means there
+// is no timeout.
+func setTimeout(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	client, err := getClient(s)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := durationValue(args.Get(0))
+	if err != nil {
+		return nil, errors.New(err).In("Timeout")
+	}
+
+	client.SetTimeout(timeout)
+
+	return getThis(s), nil
+}
+
+// setRetry implements the Retry() function. This sets the number of times a request
+// is retried if it fails to connect, or the server responds with a status that
+// indicates a temporary failure (429 or any 5xx status). The wait between retries
+// starts at the optional wait duration, and doubles with each attempt, up to the
+// optional maximum wait duration. A count of zero disables retries.
+func setRetry(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	client, err := getClient(s)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := data.Int(args.Get(0))
+	if err != nil || count < 0 {
+		return nil, errors.ErrInvalidInteger.In("Retry").Context(args.Get(0))
+	}
+
+	wait := defaultRetryWait
+	maxWait := defaultRetryMaxWait
+
+	if args.Len() > 1 {
+		if wait, err = durationValue(args.Get(1)); err != nil {
+			return nil, errors.New(err).In("Retry")
+		}
+	}
+
+	if args.Len() > 2 {
+		if maxWait, err = durationValue(args.Get(2)); err != nil {
+			return nil, errors.New(err).In("Retry")
+		}
+	}
+
+	if maxWait < wait {
+		maxWait = wait
+	}
+
+	client.SetRetryCount(count).
+		SetRetryWaitTime(wait).
+		SetRetryMaxWaitTime(maxWait)
+
+	if len(client.RetryConditions) == 0 {
+		client.AddRetryCondition(retryStatus)
+	}
+
+	return getThis(s), nil
+}
+
+// retryStatus is the retry condition for a client. A request is retried when the
+// server responds with a status that indicates the failure is temporary.
+func retryStatus(r *resty.Response) (bool, error) {
+	if r == nil {
+		return false, nil
+	}
+
+	status := r.StatusCode()
+
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError, nil
+}
+
+// durationValue converts a duration argument to a native duration. The argument
+// can be a time.Duration value or a duration string.
+func durationValue(v interface{}) (time.Duration, error) {
+	if text, ok := v.(string); ok {
+		return util.ParseDuration(text)
+	}
+
+	d, err := data.GetNativeDuration(v)
+	if err != nil {
+		return 0, errors.ErrInvalidDuration.Context(data.TypeOf(v).String())
+	}
+
+	return *d, nil
+}
@@ -0,0 +1,290 @@
+package rest
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
+	"gopkg.in/resty.v1"
+)
+
+// requestOptions describes the optional parts of a request made with the Do()
+// function, taken from the fields of a rest.Request value.
+type requestOptions struct {
+	headers map[string]string
+	query   map[string]string
+	cookies map[string]string
+	form    map[string]string
+	files   map[string]string
+	output  string
+}
+
+// doPut implements the Put() rest function.
+func doPut(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	return sendRequest(s, http.MethodPut, data.String(args.Get(0)), args.Get(1), nil)
+}
+
+// doPatch implements the Patch() rest function.
+func doPatch(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	return sendRequest(s, http.MethodPatch, data.String(args.Get(0)), args.Get(1), nil)
+}
+
+// doHead implements the Head() rest function. There is no response body, so the
+// result is an empty string. The response headers are stored in the client.
+func doHead(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	return sendRequest(s, http.MethodHead, data.String(args.Get(0)), nil, nil)
+}
+
+// doOptions implements the Options() rest function.
+func doOptions(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	return sendRequest(s, http.MethodOptions, data.String(args.Get(0)), nil, nil)
+}
+
+// doRequest implements the Do() rest function. The argument is a rest.Request value
+// that describes the method, URL, headers, query parameters, cookies, and body of the
+// request. If the request has files, the body is sent as multipart form data. If the
+// request has an output file name, the response body is written to the file as it is
+// received.
+func doRequest(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	req, ok := args.Get(0).(*data.Struct)
+	if !ok {
+		return nil, errors.ErrArgumentType.In("Do").Context(data.TypeOf(args.Get(0)).String())
+	}
+
+	method := strings.ToUpper(data.String(req.GetAlways(methodFieldName)))
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	url := data.String(req.GetAlways(urlFieldName))
+	if url == "" {
+		return nil, errors.ErrInvalidURL.In("Do")
+	}
+
+	options := &requestOptions{
+		headers: stringMap(req.GetAlways(headersFieldName)),
+		query:   stringMap(req.GetAlways(queryFieldName)),
+		cookies: stringMap(req.GetAlways(cookiesFieldName)),
+		form:    stringMap(req.GetAlways(formFieldName)),
+		files:   stringMap(req.GetAlways(filesFieldName)),
+		output:  data.String(req.GetAlways(outputFieldName)),
+	}
+
+	return sendRequest(s, method, url, req.GetAlways(bodyFieldName), options)
+}
+
+// doDownload implements the Download() rest function. The response body of a GET
+// request is written to the named file as it is received, so it does not need to
+// fit in memory. If the request is not successful, the file is removed and the
+// result is an error.
+func doDownload(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	fileName := data.String(args.Get(1))
+	if fileName == "" {
+		return nil, errors.ErrInvalidValue.In("Download").Context("file name")
+	}
+
+	fileName, err := util.SandboxPath(fileName)
+	if err != nil {
+		return nil, errors.New(err).In("Download")
+	}
+
+	if _, err := sendRequest(s, http.MethodGet, data.String(args.Get(0)), nil, &requestOptions{output: fileName}); err != nil {
+		return nil, err
+	}
+
+	this := getThis(s)
+
+	status := data.IntOrZero(this.GetAlways(statusFieldName))
+	if status < http.StatusOK || status > 299 {
+		_ = os.Remove(fileName)
+
+		return nil, mapStatusToError(status, data.String(args.Get(0)))
+	}
+
+	return nil, nil
+}
+
+// sendRequest sends a request using the client's settings, and stores the status,
+// headers, cookies, and response body in the client. The result is the response
+// body, which is decoded from JSON if the media type of the client is JSON.
+func sendRequest(s *symbols.SymbolTable, method, url string, body interface{}, options *requestOptions) (interface{}, error) {
+	client, err := getClient(s)
+	if err != nil {
+		return nil, err
+	}
+
+	this := getThis(s)
+
+	client.SetRedirectPolicy(resty.FlexibleRedirectPolicy(MaxRedirectCount))
+
+	if !data.BoolOrFalse(this.GetAlways(verifyFieldName)) {
+		client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	}
+
+	url = applyBaseURL(url, this)
+	r := client.NewRequest()
+
+	applyContext(r, this)
+
+	isJSON := false
+
+	if media := this.GetAlways(mediaTypeFieldName); media != nil {
+		ms := data.String(media)
+		isJSON = strings.Contains(ms, defs.JSONMediaType)
+
+		r.Header.Set("Accept", ms)
+
+		if body != nil {
+			r.Header.Set("Content-Type", ms)
+		}
+	}
+
+	multipart := false
+
+	if options != nil {
+		if multipart, err = applyOptions(r, options); err != nil {
+			return nil, err
+		}
+	}
+
+	// A multipart request sends the form values and files as the body, so any
+	// other body is ignored.
+	if body != nil && !multipart {
+		if isJSON {
+			if _, ok := body.(string); !ok {
+				b, err := json.Marshal(body)
+				if err != nil {
+					return nil, errors.New(err)
+				}
+
+				body = string(b)
+			}
+		}
+
+		r.SetBody(body)
+		r.SetContentLength(true)
+	}
+
+	AddAgent(r, defs.ClientAgent)
+	logRequest(r, method, url)
+
+	response, e2 := r.Execute(method, url)
+	if e2 != nil {
+		this.SetAlways(statusFieldName, http.StatusServiceUnavailable)
+
+		return nil, errors.New(e2)
+	}
+
+	logResponse(response)
+
+	return storeResponseBody(this, response, isJSON)
+}
+
+// applyOptions adds the headers, query parameters, cookies, form values and files
+// from the options to the request. The result is true if the request is sent as
+// multipart form data.
+func applyOptions(r *resty.Request, options *requestOptions) (bool, error) {
+	for name, value := range options.headers {
+		r.SetHeader(name, value)
+	}
+
+	if len(options.query) > 0 {
+		r.SetQueryParams(options.query)
+	}
+
+	for name, value := range options.cookies {
+		r.Header.Add("Cookie", (&http.Cookie{Name: name, Value: value}).String())
+	}
+
+	if len(options.files) > 0 {
+		files := map[string]string{}
+
+		for field, fileName := range options.files {
+			path, err := util.SandboxPath(fileName)
+			if err != nil {
+				return false, err
+			}
+
+			if _, err := os.Stat(path); err != nil {
+				return false, errors.New(err).Context(fileName)
+			}
+
+			files[field] = path
+		}
+
+		// Let resty set the multipart content type, which includes the boundary.
+		r.Header.Del("Content-Type")
+		r.SetFiles(files)
+	}
+
+	if len(options.form) > 0 {
+		if len(options.files) == 0 {
+			r.Header.Del("Content-Type")
+		}
+
+		r.SetFormData(options.form)
+	}
+
+	if options.output != "" {
+		path, err := util.SandboxPath(options.output)
+		if err != nil {
+			return false, err
+		}
+
+		r.SetOutput(path)
+	}
+
+	return len(options.files) > 0 || len(options.form) > 0, nil
+}
+
+// storeResponseBody stores the status, headers, cookies, and body of a response in
+// the client, and returns the body. If the client's media type is JSON and the body
+// contains JSON, the result is the decoded Ego value.
+func storeResponseBody(this *data.Struct, response *resty.Response, isJSON bool) (interface{}, error) {
+	var err error
+
+	status := response.StatusCode()
+	this.SetAlways("cookies", fetchCookies(response))
+	this.SetAlways(statusFieldName, status)
+	this.SetAlways(headersFieldName, headerMap(response))
+	rb := string(response.Body())
+
+	if isJSON && (strings.HasPrefix(rb, "{") || strings.HasPrefix(rb, "[")) {
+		var jsonResponse interface{}
+
+		if err = json.Unmarshal([]byte(rb), &jsonResponse); err != nil {
+			err = errors.New(err)
+		}
+
+		jsonResponse = makeEgoTypeFromBody(jsonResponse)
+		this.SetAlways(responseFieldName, jsonResponse)
+
+		return jsonResponse, err
+	}
+
+	this.SetAlways(responseFieldName, rb)
+
+	return rb, nil
+}
+
+// stringMap converts an Ego map value to a native map of strings. Any other
+// value results in an empty map.
+func stringMap(v interface{}) map[string]string {
+	result := map[string]string{}
+
+	if m, ok := v.(*data.Map); ok && m != nil {
+		for _, key := range m.Keys() {
+			value, _, _ := m.Get(key)
+			result[data.String(key)] = data.String(value)
+		}
+	}
+
+	return result
+}
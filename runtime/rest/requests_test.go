@@ -0,0 +1,226 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/symbols"
+)
+
+// echoHandler responds with a JSON description of the request it received.
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	info := map[string]interface{}{
+		"method": r.Method,
+		"header": r.Header.Get("X-Test"),
+		"query":  r.URL.Query().Get("q"),
+	}
+
+	if c, err := r.Cookie("session"); err == nil {
+		info["cookie"] = c.Value
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(1 << 20); err == nil {
+			info["form"] = r.FormValue("name")
+
+			if f, _, err := r.FormFile("upload"); err == nil {
+				b, _ := io.ReadAll(f)
+				info["file"] = string(b)
+			}
+		}
+	} else {
+		b, _ := io.ReadAll(r.Body)
+		info["body"] = string(b)
+	}
+
+	w.Header().Set("Content-Type", defs.JSONMediaType)
+	_ = json.NewEncoder(w).Encode(info)
+}
+
+func newTestClient(t *testing.T) (*symbols.SymbolTable, *data.Struct) {
+	s := symbols.NewSymbolTable("rest test")
+	Initialize(s)
+
+	v, err := New(s, data.NewList())
+	if err != nil {
+		t.Fatalf("Unexpected error creating client: %v", err)
+	}
+
+	client := v.(*data.Struct)
+	client.SetAlways(mediaTypeFieldName, defs.JSONMediaType)
+	s.SetAlways(defs.ThisVariable, client)
+
+	return s, client
+}
+
+func responseMap(t *testing.T, v interface{}) *data.Map {
+	m, ok := v.(*data.Map)
+	if !ok {
+		t.Fatalf("Expected map response, got %#v", v)
+	}
+
+	return m
+}
+
+func mapValue(m *data.Map, key string) string {
+	v, _, _ := m.Get(key)
+
+	return data.String(v)
+}
+
+func TestRequests_Methods(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(echoHandler))
+	defer server.Close()
+
+	s, client := newTestClient(t)
+
+	v, err := doPut(s, data.NewList(server.URL, data.NewMapFromMap(map[string]interface{}{"id": 1})))
+	if err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+
+	m := responseMap(t, v)
+	if mapValue(m, "method") != http.MethodPut || mapValue(m, "body") != `{"id":1}` {
+		t.Errorf("Put() unexpected response: %v", m)
+	}
+
+	if v, err = doPatch(s, data.NewList(server.URL, `{"id":2}`)); err != nil {
+		t.Fatalf("Patch() error: %v", err)
+	}
+
+	m = responseMap(t, v)
+	if mapValue(m, "method") != http.MethodPatch || mapValue(m, "body") != `{"id":2}` {
+		t.Errorf("Patch() unexpected response: %v", m)
+	}
+
+	if v, err = doHead(s, data.NewList(server.URL)); err != nil {
+		t.Fatalf("Head() error: %v", err)
+	}
+
+	if v != "" || data.IntOrZero(client.GetAlways(statusFieldName)) != http.StatusOK {
+		t.Errorf("Head() unexpected response: %v", v)
+	}
+}
+
+func TestRequests_Do(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(echoHandler))
+	defer server.Close()
+
+	s, _ := newTestClient(t)
+
+	req := data.NewStruct(requestType)
+	_ = req.Set(methodFieldName, "post")
+	_ = req.Set(urlFieldName, server.URL)
+	_ = req.Set(headersFieldName, data.NewMapFromMap(map[string]string{"X-Test": "header"}))
+	_ = req.Set(queryFieldName, data.NewMapFromMap(map[string]string{"q": "search"}))
+	_ = req.Set(cookiesFieldName, data.NewMapFromMap(map[string]string{"session": "abc"}))
+	_ = req.Set(bodyFieldName, "payload")
+
+	v, err := doRequest(s, data.NewList(req))
+	if err != nil {
+		t.Fatalf("Do() error: %v", err)
+	}
+
+	m := responseMap(t, v)
+	for key, want := range map[string]string{
+		"method": http.MethodPost,
+		"header": "header",
+		"query":  "search",
+		"cookie": "abc",
+		"body":   "payload",
+	} {
+		if got := mapValue(m, key); got != want {
+			t.Errorf("Do() %s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestRequests_Multipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(echoHandler))
+	defer server.Close()
+
+	s, _ := newTestClient(t)
+
+	fileName := filepath.Join(t.TempDir(), "upload.txt")
+	if err := os.WriteFile(fileName, []byte("file contents"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	req := data.NewStruct(requestType)
+	_ = req.Set(methodFieldName, http.MethodPost)
+	_ = req.Set(urlFieldName, server.URL)
+	_ = req.Set(formFieldName, data.NewMapFromMap(map[string]string{"name": "report"}))
+	_ = req.Set(filesFieldName, data.NewMapFromMap(map[string]string{"upload": fileName}))
+
+	v, err := doRequest(s, data.NewList(req))
+	if err != nil {
+		t.Fatalf("Do() error: %v", err)
+	}
+
+	m := responseMap(t, v)
+	if mapValue(m, "form") != "report" || mapValue(m, "file") != "file contents" {
+		t.Errorf("Do() multipart unexpected response: %v", m)
+	}
+}
+
+func TestRequests_DownloadAndRetry(t *testing.T) {
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("downloaded data"))
+	}))
+	defer server.Close()
+
+	s, _ := newTestClient(t)
+
+	if _, err := setRetry(s, data.NewList(3, "1ms", "5ms")); err != nil {
+		t.Fatalf("Retry() error: %v", err)
+	}
+
+	fileName := filepath.Join(t.TempDir(), "download.txt")
+	if _, err := doDownload(s, data.NewList(server.URL, fileName)); err != nil {
+		t.Fatalf("Download() error: %v", err)
+	}
+
+	b, err := os.ReadFile(fileName)
+	if err != nil || string(b) != "downloaded data" {
+		t.Errorf("Download() file contents = %q, %v", string(b), err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRequests_DownloadFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	s, _ := newTestClient(t)
+
+	fileName := filepath.Join(t.TempDir(), "missing.txt")
+	if _, err := doDownload(s, data.NewList(server.URL, fileName)); err == nil {
+		t.Errorf("Download() expected an error")
+	}
+
+	if _, err := os.Stat(fileName); err == nil {
+		t.Errorf("Download() left a file after a failed request")
+	}
+}
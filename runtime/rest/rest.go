@@ -10,6 +10,7 @@ import (
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/runtime/context"
 	"github.com/tucats/ego/symbols"
 	"gopkg.in/resty.v1"
 )
@@ -101,6 +102,39 @@ func setMedia(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 	return this, nil
 }
 
+// setContext implements the Context() function. This binds the client to a context.Context
+// value, so requests made by the client are cancelled when the context is cancelled or its
+// deadline passes. A nil context removes the binding.
+func setContext(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	if _, err := getClient(s); err != nil {
+		return nil, err
+	}
+
+	this := getThis(s)
+
+	if args.Get(0) == nil {
+		this.SetAlways(contextFieldName, nil)
+
+		return this, nil
+	}
+
+	if _, ok := context.Value(args.Get(0)); !ok {
+		return nil, errors.ErrInvalidContext.In("Context")
+	}
+
+	this.SetAlways(contextFieldName, args.Get(0))
+
+	return this, nil
+}
+
+// applyContext sets the native context for a request, if the client is bound to a
+// context.Context value.
+func applyContext(r *resty.Request, this *data.Struct) {
+	if ctx, ok := context.Value(this.GetAlways(contextFieldName)); ok {
+		r.SetContext(ctx)
+	}
+}
+
 // fetchCookies extracts the cookies from the response, and format them as an Ego array
 // of structs.
 func fetchCookies(r *resty.Response) *data.Array {
@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+)
+
+// Stream is a variation of Exchange that copies the body of a successful response to
+// the writer as it is received, instead of decoding it as JSON. This is used by client
+// operations that can return very large results, such as a table export. If the
+// response is not successful, the error message from the response is returned.
+func Stream(endpoint, method string, w io.Writer, agentType string, mediaTypes ...string) error {
+	if settings.GetBool(defs.InsecureClientSetting) {
+		ui.Log(ui.RestLogger, "rest.allow.insecure")
+		AllowInsecure(true)
+	}
+
+	url := applyDefaultServer(endpoint)
+
+	ui.Log(ui.RestLogger, "rest.method",
+		"method", strings.ToUpper(method),
+		"url", url)
+
+	client, err := newClient(endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	r := client.NewRequest().SetDoNotParseResponse(true)
+
+	applyMediaTypes(mediaTypes, r)
+	AddAgent(r, agentType)
+
+	restResponse, err := r.Execute(method, url)
+	if err != nil {
+		ui.Log(ui.RestLogger, "rest.error",
+			"error", err)
+
+		return errors.New(err)
+	}
+
+	body := restResponse.RawBody()
+	defer body.Close()
+
+	status := restResponse.StatusCode()
+
+	ui.Log(ui.RestLogger, "rest.status",
+		"status", status)
+
+	if status != http.StatusOK {
+		errorResponse := map[string]interface{}{}
+
+		if b, err := io.ReadAll(body); err == nil && json.Unmarshal(b, &errorResponse) == nil {
+			if msg, found := errorResponse["msg"]; found {
+				return errors.Message(data.String(msg))
+			}
+		}
+
+		return mapStatusToError(status, url)
+	}
+
+	if _, err = io.Copy(w, body); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
@@ -19,9 +19,25 @@ type Client struct {
 	Status 		int,
 	verify 		bool,
 	Headers 	map[string]string,
+	context 	interface{},
+}`
+
+// rest.Request type specification, used with the Do() function.
+const requestTypeSpec = `
+type Request struct {
+	Method 		string,
+	URL 		string,
+	Headers 	map[string]string,
+	Query 		map[string]string,
+	Cookies 	map[string]string,
+	Body 		interface{},
+	Form 		map[string]string,
+	Files 		map[string]string,
+	Output 		string,
 }`
 
 var restType *data.Type
+var requestType *data.Type
 var initLock sync.Mutex
 
 func Initialize(s *symbols.SymbolTable) {
@@ -31,6 +47,9 @@ func Initialize(s *symbols.SymbolTable) {
 	if restType == nil {
 		restType, _ = compiler.CompileTypeSpec(restTypeSpec, nil)
 
+		rt, _ := compiler.CompileTypeSpec(requestTypeSpec, nil)
+		requestType = rt.SetPackage("rest")
+
 		t, _ := compiler.CompileTypeSpec(restTypeSpec, nil)
 
 		t.DefineFunctions(map[string]data.Function{
@@ -101,6 +120,207 @@ func Initialize(s *symbols.SymbolTable) {
 				Value: doDelete,
 			},
 
+			"Put": {
+				Declaration: &data.Declaration{
+					Name: "Put",
+					Type: t,
+					Parameters: []data.Parameter{
+						{
+							Name: "endpoint",
+							Type: data.StringType,
+						},
+						{
+							Name: "body",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{
+						data.InterfaceType,
+					},
+					ArgCount: data.Range{1, 2},
+				},
+				Value: doPut,
+			},
+
+			"Patch": {
+				Declaration: &data.Declaration{
+					Name: "Patch",
+					Type: t,
+					Parameters: []data.Parameter{
+						{
+							Name: "endpoint",
+							Type: data.StringType,
+						},
+						{
+							Name: "body",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{
+						data.InterfaceType,
+					},
+					ArgCount: data.Range{1, 2},
+				},
+				Value: doPatch,
+			},
+
+			"Head": {
+				Declaration: &data.Declaration{
+					Name: "Head",
+					Type: t,
+					Parameters: []data.Parameter{
+						{
+							Name: "endpoint",
+							Type: data.StringType,
+						},
+					},
+					Returns: []*data.Type{
+						data.InterfaceType,
+					},
+				},
+				Value: doHead,
+			},
+
+			"Options": {
+				Declaration: &data.Declaration{
+					Name: "Options",
+					Type: t,
+					Parameters: []data.Parameter{
+						{
+							Name: "endpoint",
+							Type: data.StringType,
+						},
+					},
+					Returns: []*data.Type{
+						data.InterfaceType,
+					},
+				},
+				Value: doOptions,
+			},
+
+			"Do": {
+				Declaration: &data.Declaration{
+					Name: "Do",
+					Type: t,
+					Parameters: []data.Parameter{
+						{
+							Name: "request",
+							Type: requestType,
+						},
+					},
+					Returns: []*data.Type{
+						data.InterfaceType,
+					},
+				},
+				Value: doRequest,
+			},
+
+			"Download": {
+				Declaration: &data.Declaration{
+					Name: "Download",
+					Type: t,
+					Parameters: []data.Parameter{
+						{
+							Name: "endpoint",
+							Type: data.StringType,
+						},
+						{
+							Name: "fileName",
+							Type: data.StringType,
+						},
+					},
+					Returns: []*data.Type{
+						data.ErrorType,
+					},
+				},
+				Value: doDownload,
+			},
+
+			"Header": {
+				Declaration: &data.Declaration{
+					Name: "Header",
+					Type: t,
+					Parameters: []data.Parameter{
+						{
+							Name: "name",
+							Type: data.StringType,
+						},
+						{
+							Name: "value",
+							Type: data.StringType,
+						},
+					},
+					Returns: []*data.Type{
+						t,
+					},
+				},
+				Value: setHeader,
+			},
+
+			"Query": {
+				Declaration: &data.Declaration{
+					Name: "Query",
+					Type: t,
+					Parameters: []data.Parameter{
+						{
+							Name: "name",
+							Type: data.StringType,
+						},
+						{
+							Name: "value",
+							Type: data.StringType,
+						},
+					},
+					Returns: []*data.Type{
+						t,
+					},
+				},
+				Value: setQuery,
+			},
+
+			"Timeout": {
+				Declaration: &data.Declaration{
+					Name: "Timeout",
+					Type: t,
+					Parameters: []data.Parameter{
+						{
+							Name: "timeout",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{
+						t,
+					},
+				},
+				Value: setTimeout,
+			},
+
+			"Retry": {
+				Declaration: &data.Declaration{
+					Name: "Retry",
+					Type: t,
+					Parameters: []data.Parameter{
+						{
+							Name: "count",
+							Type: data.IntType,
+						},
+						{
+							Name: "wait",
+							Type: data.InterfaceType,
+						},
+						{
+							Name: "maxWait",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{
+						t,
+					},
+					ArgCount: data.Range{1, 3},
+				},
+				Value: setRetry,
+			},
+
 			"Base": {
 				Declaration: &data.Declaration{
 					Name: "Base",
@@ -118,6 +338,23 @@ func Initialize(s *symbols.SymbolTable) {
 				Value: setBase,
 			},
 
+			"Context": {
+				Declaration: &data.Declaration{
+					Name: "Context",
+					Type: t,
+					Parameters: []data.Parameter{
+						{
+							Name: "ctx",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{
+						t,
+					},
+				},
+				Value: setContext,
+			},
+
 			"Debug": {
 				Declaration: &data.Declaration{
 					Name: "Debug",
@@ -278,7 +515,8 @@ func Initialize(s *symbols.SymbolTable) {
 				},
 				Value: ParseURL,
 			},
-			"Client": restType,
+			"Client":  restType,
+			"Request": requestType,
 		})
 
 		pkg, _ := bytecode.GetPackage(newpkg.Name)
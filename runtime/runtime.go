@@ -9,13 +9,17 @@ import (
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/compiler"
 	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/runtime/atomic"
 	"github.com/tucats/ego/runtime/base64"
 	"github.com/tucats/ego/runtime/cipher"
+	"github.com/tucats/ego/runtime/context"
+	"github.com/tucats/ego/runtime/csv"
 	"github.com/tucats/ego/runtime/db"
 	"github.com/tucats/ego/runtime/errors"
 	"github.com/tucats/ego/runtime/exec"
 	"github.com/tucats/ego/runtime/filepath"
 	"github.com/tucats/ego/runtime/fmt"
+	"github.com/tucats/ego/runtime/http"
 	"github.com/tucats/ego/runtime/i18n"
 	"github.com/tucats/ego/runtime/io"
 	"github.com/tucats/ego/runtime/json"
@@ -24,6 +28,7 @@ import (
 	"github.com/tucats/ego/runtime/profile"
 	"github.com/tucats/ego/runtime/reflect"
 	"github.com/tucats/ego/runtime/rest"
+	"github.com/tucats/ego/runtime/secrets"
 	"github.com/tucats/ego/runtime/sort"
 	"github.com/tucats/ego/runtime/strconv"
 	"github.com/tucats/ego/runtime/strings"
@@ -32,6 +37,8 @@ import (
 	"github.com/tucats/ego/runtime/time"
 	"github.com/tucats/ego/runtime/util"
 	"github.com/tucats/ego/runtime/uuid"
+	"github.com/tucats/ego/runtime/xml"
+	"github.com/tucats/ego/runtime/yaml"
 	"github.com/tucats/ego/symbols"
 )
 
@@ -42,13 +49,17 @@ func AddPackages(s *symbols.SymbolTable) {
 		"name", s.Name,
 		"id", s.ID())
 
+	atomic.Initialize(s)
 	base64.Initialize(s)
 	cipher.Initialize(s)
+	context.Initialize(s)
+	csv.Initialize(s)
 	db.Initialize(s)
 	errors.Initialize(s)
 	exec.Initialize(s)
 	filepath.Initialize(s)
 	fmt.Initialize(s)
+	http.Initialize(s)
 	i18n.Initialize(s)
 	io.Initialize(s)
 	json.Initialize(s)
@@ -57,6 +68,7 @@ func AddPackages(s *symbols.SymbolTable) {
 	profile.Initialize(s)
 	reflect.Initialize(s)
 	rest.Initialize(s)
+	secrets.Initialize(s)
 	sort.Initialize(s)
 	strconv.Initialize(s)
 	strings.Initialize(s)
@@ -65,6 +77,8 @@ func AddPackages(s *symbols.SymbolTable) {
 	time.Initialize(s)
 	util.Initialize(s)
 	uuid.Initialize(s)
+	xml.Initialize(s)
+	yaml.Initialize(s)
 }
 
 // AddPackages adds in the pre-defined package receivers for things like the
@@ -76,10 +90,16 @@ func AddPackage(name string, s *symbols.SymbolTable) {
 		"id", s.ID())
 
 	switch name {
+	case "atomic":
+		atomic.Initialize(s)
 	case "base64":
 		base64.Initialize(s)
 	case "cipher":
 		cipher.Initialize(s)
+	case "context":
+		context.Initialize(s)
+	case "csv":
+		csv.Initialize(s)
 	case "db":
 		db.Initialize(s)
 	case "errors":
@@ -90,6 +110,8 @@ func AddPackage(name string, s *symbols.SymbolTable) {
 		filepath.Initialize(s)
 	case "fmt":
 		fmt.Initialize(s)
+	case "http":
+		http.Initialize(s)
 	case "i18n":
 		i18n.Initialize(s)
 	case "io":
@@ -106,6 +128,8 @@ func AddPackage(name string, s *symbols.SymbolTable) {
 		reflect.Initialize(s)
 	case "rest":
 		rest.Initialize(s)
+	case "secrets":
+		secrets.Initialize(s)
 	case "sort":
 		sort.Initialize(s)
 	case "strconv":
@@ -122,6 +146,10 @@ func AddPackage(name string, s *symbols.SymbolTable) {
 		util.Initialize(s)
 	case "uuid":
 		uuid.Initialize(s)
+	case "xml":
+		xml.Initialize(s)
+	case "yaml":
+		yaml.Initialize(s)
 	}
 }
 
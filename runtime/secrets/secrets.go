@@ -0,0 +1,46 @@
+// Package secrets implements the secrets runtime package, which lets a service
+// read the secret values stored by the server for it. The package can only be
+// used by services running in a server.
+package secrets
+
+import (
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+// Reader returns the value of a named secret for a user. It is set by the server
+// when the secrets store is initialized, and is nil otherwise.
+var Reader func(user, name string) (string, error)
+
+// getSecret implements the secrets.Get() function. The secret is read on behalf of
+// the user that made the service request.
+func getSecret(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	name := data.String(args.Get(0))
+
+	mode := ""
+	if v, found := s.Get(defs.ModeVariable); found {
+		mode = data.String(v)
+	}
+
+	if mode != "server" || Reader == nil {
+		err := errors.ErrNotAService.In("Get")
+
+		return data.NewList("", err), err
+	}
+
+	user := ""
+	if v, found := s.Get("_user"); found {
+		user = data.String(v)
+	}
+
+	value, err := Reader(user, name)
+	if err != nil {
+		err = errors.New(err).In("Get")
+
+		return data.NewList("", err), err
+	}
+
+	return data.NewList(value, nil), nil
+}
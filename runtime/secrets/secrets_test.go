@@ -0,0 +1,55 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+func Test_getSecret(t *testing.T) {
+	savedReader := Reader
+
+	defer func() {
+		Reader = savedReader
+	}()
+
+	Reader = func(user, name string) (string, error) {
+		if user == "joe" && name == "db" {
+			return "hunter2", nil
+		}
+
+		return "", errors.ErrNoPermission.Context(name)
+	}
+
+	tests := []struct {
+		name    string
+		mode    string
+		user    string
+		want    string
+		wantErr bool
+	}{
+		{"server user", "server", "joe", "hunter2", false},
+		{"other user", "server", "bob", "", true},
+		{"not a server", "run", "joe", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := symbols.NewSymbolTable("test")
+			s.SetAlways(defs.ModeVariable, tt.mode)
+			s.SetAlways("_user", tt.user)
+
+			result, err := getSecret(s, data.NewList("db"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := data.String(result.(data.List).Get(0)); got != tt.want {
+				t.Errorf("getSecret() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
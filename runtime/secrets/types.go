@@ -0,0 +1,38 @@
+package secrets
+
+import (
+	"sync"
+
+	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/symbols"
+)
+
+var initLock sync.Mutex
+
+func Initialize(s *symbols.SymbolTable) {
+	initLock.Lock()
+	defer initLock.Unlock()
+
+	if _, found := s.Root().Get("secrets"); !found {
+		newpkg := data.NewPackageFromMap("secrets", map[string]interface{}{
+			"Get": data.Function{
+				Declaration: &data.Declaration{
+					Name: "Get",
+					Parameters: []data.Parameter{
+						{
+							Name: "name",
+							Type: data.StringType,
+						},
+					},
+					Returns: []*data.Type{data.StringType, data.ErrorType},
+				},
+				Value: getSecret,
+			},
+		})
+
+		pkg, _ := bytecode.GetPackage(newpkg.Name)
+		pkg.Merge(newpkg)
+		s.Root().SetAlways(newpkg.Name, newpkg)
+	}
+}
@@ -0,0 +1,29 @@
+package sync
+
+import (
+	"sync"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+// newCond implements the sync.NewCond() function. The argument is the Mutex or
+// RWMutex that must be locked when the Wait() method is called. The Cond value
+// unlocks it while waiting, and locks it again before Wait() returns.
+func newCond(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	v := args.Get(0)
+	if p, ok := v.(*interface{}); ok && p != nil {
+		v = *p
+	}
+
+	switch lock := v.(type) {
+	case *sync.Mutex:
+		return sync.NewCond(lock), nil
+
+	case *sync.RWMutex:
+		return sync.NewCond(lock), nil
+	}
+
+	return nil, errors.ErrArgumentType.In("NewCond").Context(data.TypeOf(v).String())
+}
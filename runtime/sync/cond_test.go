@@ -0,0 +1,34 @@
+package sync
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/tucats/ego/data"
+)
+
+func TestNewCond(t *testing.T) {
+	mutex := new(sync.Mutex)
+
+	// The lock can be passed as the value, or as a pointer to the variable.
+	var variable interface{} = mutex
+
+	for _, arg := range []interface{}{mutex, &variable, new(sync.RWMutex)} {
+		v, err := newCond(nil, data.NewList(arg))
+		if err != nil {
+			t.Fatalf("NewCond(%T) error = %v", arg, err)
+		}
+
+		if _, ok := v.(*sync.Cond); !ok {
+			t.Errorf("NewCond(%T) returned %T", arg, v)
+		}
+	}
+
+	if v, _ := newCond(nil, data.NewList(mutex)); v.(*sync.Cond).L != mutex {
+		t.Errorf("NewCond() did not use the mutex")
+	}
+
+	if _, err := newCond(nil, data.NewList("lock")); err == nil {
+		t.Errorf("NewCond() expected error for invalid lock")
+	}
+}
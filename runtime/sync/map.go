@@ -0,0 +1,34 @@
+package sync
+
+import (
+	"sync"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+// mapRange implements the Map.Range() method. The function is called for each key
+// and value in the map, until it returns false. Values stored or deleted by other
+// go routines while Range() is running may or may not be seen by the function.
+func mapRange(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var err error
+
+	m, ok := getThis(s).(*sync.Map)
+	if !ok {
+		return nil, errors.ErrInvalidThis.In("Range")
+	}
+
+	m.Range(func(key, value interface{}) bool {
+		var result interface{}
+
+		result, err = callFunction(s, "Range", args.Get(0), key, value)
+		if err != nil {
+			return false
+		}
+
+		return data.BoolOrFalse(result)
+	})
+
+	return nil, err
+}
@@ -0,0 +1,68 @@
+package sync
+
+import (
+	"sync"
+
+	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+// onceDo implements the Once.Do() method. The function is called only the first
+// time Do() is called for the Once value, even if it is called from several go
+// routines at the same time. Every other call waits until the first call returns.
+func onceDo(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var err error
+
+	once, ok := getThis(s).(*sync.Once)
+	if !ok {
+		return nil, errors.ErrInvalidThis.In("Do")
+	}
+
+	once.Do(func() {
+		_, err = callFunction(s, "Do", args.Get(0))
+	})
+
+	return nil, err
+}
+
+// callFunction runs an Ego function value with the given arguments, using a child
+// of the symbol table of the caller so the function can see the caller's variables.
+// The result is the value returned by the function.
+func callFunction(s *symbols.SymbolTable, name string, v interface{}, args ...interface{}) (interface{}, error) {
+	fn, ok := v.(*bytecode.ByteCode)
+	if !ok {
+		return nil, errors.ErrArgumentType.In(name).Context(data.TypeOf(v).String())
+	}
+
+	if fn.Name() == "" {
+		fn.SetName(defs.Anon)
+	}
+
+	callSymbols := symbols.NewChildSymbolTable("sync "+name, s)
+	callSymbols.SetAlways(defs.ArgumentListVariable, data.NewArrayFromInterfaces(data.InterfaceType, args...))
+
+	ctx := bytecode.NewContext(callSymbols, fn)
+	if err := ctx.Run(); err != nil {
+		return nil, err
+	}
+
+	return ctx.Result(), nil
+}
+
+// getThis returns the receiver of a method. If the method was called using a
+// pointer to the value, the pointer is dereferenced.
+func getThis(s *symbols.SymbolTable) interface{} {
+	v, _ := s.Get(defs.ThisVariable)
+
+	for {
+		p, ok := v.(*interface{})
+		if !ok || p == nil {
+			return v
+		}
+
+		v = *p
+	}
+}
@@ -11,6 +11,9 @@ import (
 var waitGroupType *data.Type
 var mutextType *data.Type
 var rwMutexType *data.Type
+var onceType *data.Type
+var condType *data.Type
+var mapType *data.Type
 
 var initLock sync.Mutex
 
@@ -93,21 +96,178 @@ func Initialize(s *symbols.SymbolTable) {
 				Type: rwMutexType,
 			}, nil)
 
-		mutextType.DefineNativeFunction("TryLock",
+		rwMutexType.DefineNativeFunction("TryLock",
 			&data.Declaration{
 				Name:    "TryLock",
-				Type:    data.BoolType,
+				Type:    rwMutexType,
 				Returns: []*data.Type{data.BoolType},
 			}, nil)
 
-		mutextType.DefineNativeFunction("RTryLock",
+		rwMutexType.DefineNativeFunction("TryRLock",
 			&data.Declaration{
-				Name:    "RTryLock",
-				Type:    data.BoolType,
+				Name:    "TryRLock",
+				Type:    rwMutexType,
 				Returns: []*data.Type{data.BoolType},
 			}, nil)
 	}
 
+	if onceType == nil {
+		onceType = data.TypeDefinition("Once", data.StructureType()).
+			SetNativeName("sync.Once").
+			SetPackage("sync").
+			SetNew(func() interface{} {
+				return new(sync.Once)
+			})
+
+		// Do() is not a native function, because the function it calls is Ego
+		// bytecode that must run with the caller's symbol table.
+		onceType.DefineFunction("Do",
+			&data.Declaration{
+				Name:  "Do",
+				Type:  onceType,
+				Scope: true,
+				Parameters: []data.Parameter{
+					{
+						Name: "f",
+						Type: data.FunctionType(&data.Function{
+							Declaration: &data.Declaration{},
+						}),
+					},
+				},
+			}, onceDo)
+	}
+
+	if condType == nil {
+		condType = data.TypeDefinition("Cond", data.StructureType()).
+			SetNativeName("sync.Cond").
+			SetPackage("sync")
+
+		condType.DefineNativeFunction("Wait",
+			&data.Declaration{
+				Name: "Wait",
+				Type: condType,
+			}, nil)
+
+		condType.DefineNativeFunction("Signal",
+			&data.Declaration{
+				Name: "Signal",
+				Type: condType,
+			}, nil)
+
+		condType.DefineNativeFunction("Broadcast",
+			&data.Declaration{
+				Name: "Broadcast",
+				Type: condType,
+			}, nil)
+	}
+
+	if mapType == nil {
+		mapType = data.TypeDefinition("Map", data.StructureType()).
+			SetNativeName("sync.Map").
+			SetPackage("sync").
+			SetNew(func() interface{} {
+				return new(sync.Map)
+			})
+
+		mapType.DefineNativeFunction("Load",
+			&data.Declaration{
+				Name: "Load",
+				Type: mapType,
+				Parameters: []data.Parameter{
+					{
+						Name: "key",
+						Type: data.InterfaceType,
+					},
+				},
+				Returns: []*data.Type{data.InterfaceType, data.BoolType},
+			}, nil)
+
+		mapType.DefineNativeFunction("Store",
+			&data.Declaration{
+				Name: "Store",
+				Type: mapType,
+				Parameters: []data.Parameter{
+					{
+						Name: "key",
+						Type: data.InterfaceType,
+					},
+					{
+						Name: "value",
+						Type: data.InterfaceType,
+					},
+				},
+			}, nil)
+
+		mapType.DefineNativeFunction("LoadOrStore",
+			&data.Declaration{
+				Name: "LoadOrStore",
+				Type: mapType,
+				Parameters: []data.Parameter{
+					{
+						Name: "key",
+						Type: data.InterfaceType,
+					},
+					{
+						Name: "value",
+						Type: data.InterfaceType,
+					},
+				},
+				Returns: []*data.Type{data.InterfaceType, data.BoolType},
+			}, nil)
+
+		mapType.DefineNativeFunction("LoadAndDelete",
+			&data.Declaration{
+				Name: "LoadAndDelete",
+				Type: mapType,
+				Parameters: []data.Parameter{
+					{
+						Name: "key",
+						Type: data.InterfaceType,
+					},
+				},
+				Returns: []*data.Type{data.InterfaceType, data.BoolType},
+			}, nil)
+
+		mapType.DefineNativeFunction("Delete",
+			&data.Declaration{
+				Name: "Delete",
+				Type: mapType,
+				Parameters: []data.Parameter{
+					{
+						Name: "key",
+						Type: data.InterfaceType,
+					},
+				},
+			}, nil)
+
+		mapType.DefineFunction("Range",
+			&data.Declaration{
+				Name:  "Range",
+				Type:  mapType,
+				Scope: true,
+				Parameters: []data.Parameter{
+					{
+						Name: "f",
+						Type: data.FunctionType(&data.Function{
+							Declaration: &data.Declaration{
+								Parameters: []data.Parameter{
+									{
+										Name: "key",
+										Type: data.InterfaceType,
+									},
+									{
+										Name: "value",
+										Type: data.InterfaceType,
+									},
+								},
+								Returns: []*data.Type{data.BoolType},
+							},
+						}),
+					},
+				},
+			}, mapRange)
+	}
+
 	if waitGroupType == nil {
 		waitGroupType = data.TypeDefinition("WaitGroup", data.StructureType()).
 			SetNativeName("sync.WaitGroup").
@@ -145,6 +305,23 @@ func Initialize(s *symbols.SymbolTable) {
 		newpkg := data.NewPackageFromMap("sync", map[string]interface{}{
 			"WaitGroup": waitGroupType,
 			"Mutex":     mutextType,
+			"RWMutex":   rwMutexType,
+			"Once":      onceType,
+			"Cond":      condType,
+			"Map":       mapType,
+			"NewCond": data.Function{
+				Declaration: &data.Declaration{
+					Name: "NewCond",
+					Parameters: []data.Parameter{
+						{
+							Name: "l",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{data.PointerType(condType)},
+				},
+				Value: newCond,
+			},
 		})
 
 		pkg, _ := bytecode.GetPackage(newpkg.Name)
@@ -0,0 +1,132 @@
+package time
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+// ticker is the native state of a Ticker value. The Go ticker's channel is read
+// by a go routine that sends each tick to the Ego channel, until the ticker is
+// stopped.
+type ticker struct {
+	ticker *time.Ticker
+	stop   chan struct{}
+	once   sync.Once
+}
+
+// newTicker implements the time.NewTicker() function. The current time is sent to
+// the channel in the C field of the ticker each time the duration passes. If the
+// receiver does not keep up, ticks are dropped. The duration must be positive.
+func newTicker(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	d, err := getDurationArgument(args.Get(0), "NewTicker")
+	if err != nil {
+		return nil, err
+	}
+
+	if d <= 0 {
+		return nil, errors.ErrInvalidValue.In("NewTicker").Context(d.String())
+	}
+
+	return makeTicker(d), nil
+}
+
+// tick implements the time.Tick() function, which returns the channel of a ticker
+// that is never stopped. If the duration is not positive, the result is nil.
+func tick(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	d, err := getDurationArgument(args.Get(0), "Tick")
+	if err != nil {
+		return nil, err
+	}
+
+	if d <= 0 {
+		return nil, nil
+	}
+
+	return makeTicker(d).GetAlways(channelFieldName), nil
+}
+
+// tickerStop implements the Ticker.Stop() method. No more ticks are sent after the
+// ticker is stopped. The channel is not closed.
+func tickerStop(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	t, err := getTicker(s)
+	if err != nil {
+		return nil, err
+	}
+
+	t.ticker.Stop()
+	t.once.Do(func() {
+		close(t.stop)
+	})
+
+	return nil, nil
+}
+
+// tickerReset implements the Ticker.Reset() method, which changes the period of
+// the ticker to the duration. A stopped ticker cannot be restarted.
+func tickerReset(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	t, err := getTicker(s)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := getDurationArgument(args.Get(0), "Reset")
+	if err != nil {
+		return nil, err
+	}
+
+	if d <= 0 {
+		return nil, errors.ErrInvalidValue.In("Reset").Context(d.String())
+	}
+
+	t.ticker.Reset(d)
+
+	return nil, nil
+}
+
+// makeTicker creates a Ticker value, and starts the go routine that sends each
+// tick to its channel.
+func makeTicker(d time.Duration) *data.Struct {
+	ch := data.NewChannel(1)
+	t := &ticker{
+		ticker: time.NewTicker(d),
+		stop:   make(chan struct{}),
+	}
+
+	go func() {
+		for {
+			select {
+			case now := <-t.ticker.C:
+				ch.TrySend(now)
+
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+
+	result := data.NewStruct(tickerType).FromBuiltinPackage()
+	result.SetAlways(channelFieldName, ch)
+	result.SetAlways(tickerFieldName, t)
+
+	return result
+}
+
+// getTicker returns the native state for the receiver of a Ticker method.
+func getTicker(s *symbols.SymbolTable) (*ticker, error) {
+	if v, found := s.Get(defs.ThisVariable); found {
+		if this, ok := v.(*data.Struct); ok {
+			if t, ok := this.GetAlways(tickerFieldName).(*ticker); ok {
+				return t, nil
+			}
+		}
+
+		return nil, errors.ErrInvalidThis.Context(data.TypeOf(v).String())
+	}
+
+	return nil, errors.ErrNoFunctionReceiver
+}
@@ -0,0 +1,166 @@
+package time
+
+import (
+	"time"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
+)
+
+// newTimer implements the time.NewTimer() function. The current time is sent to the
+// channel in the C field of the timer when the duration has passed.
+func newTimer(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	d, err := getDurationArgument(args.Get(0), "NewTimer")
+	if err != nil {
+		return nil, err
+	}
+
+	return makeTimer(d), nil
+}
+
+// after implements the time.After() function, which returns a channel that receives
+// the current time when the duration has passed.
+func after(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	d, err := getDurationArgument(args.Get(0), "After")
+	if err != nil {
+		return nil, err
+	}
+
+	return makeTimer(d).GetAlways(channelFieldName), nil
+}
+
+// afterFunc implements the time.AfterFunc() function. When the duration has passed,
+// the function is called in its own go routine. The timer that is returned can be
+// used to stop the call before it happens; its C field is nil.
+func afterFunc(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	d, err := getDurationArgument(args.Get(0), "AfterFunc")
+	if err != nil {
+		return nil, err
+	}
+
+	fn := args.Get(1)
+
+	switch fn.(type) {
+	case *bytecode.ByteCode, data.Function:
+	default:
+		return nil, errors.ErrArgumentType.In("AfterFunc").Context(data.TypeOf(fn).String())
+	}
+
+	// The function runs in the same scope as a go routine started by the caller,
+	// so it can use the global symbols of the program.
+	parent := s.SharedParent()
+	if parent == nil {
+		parent = s.Parent()
+	}
+
+	timer := data.NewStruct(timerType).FromBuiltinPackage()
+	timer.SetAlways(timerFieldName, time.AfterFunc(d, func() {
+		callFunction(parent, fn)
+	}))
+
+	return timer, nil
+}
+
+// timerStop implements the Timer.Stop() method. The result is true if the call
+// stopped the timer, or false if the timer had already expired or been stopped.
+func timerStop(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	timer, err := getTimer(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return timer.Stop(), nil
+}
+
+// timerReset implements the Timer.Reset() method, which changes the timer to expire
+// after the duration. The result is true if the timer had been active.
+func timerReset(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	timer, err := getTimer(s)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := getDurationArgument(args.Get(0), "Reset")
+	if err != nil {
+		return nil, err
+	}
+
+	return timer.Reset(d), nil
+}
+
+// makeTimer creates a Timer value whose channel receives the current time when
+// the duration has passed.
+func makeTimer(d time.Duration) *data.Struct {
+	ch := data.NewChannel(1)
+
+	timer := data.NewStruct(timerType).FromBuiltinPackage()
+	timer.SetAlways(channelFieldName, ch)
+	timer.SetAlways(timerFieldName, time.AfterFunc(d, func() {
+		ch.TrySend(time.Now())
+	}))
+
+	return timer
+}
+
+// callFunction calls an Ego function with no arguments, using a new symbol table
+// whose parent is the given table. An error is logged, since there is no caller
+// to return it to.
+func callFunction(parent *symbols.SymbolTable, fn interface{}) {
+	callCode := bytecode.New("time.AfterFunc").Literal(true)
+	callCode.Emit(bytecode.Push, fn)
+	callCode.Emit(bytecode.Call, 0)
+
+	callSymbols := symbols.NewChildSymbolTable("time.AfterFunc", parent).Boundary(false)
+	callSymbols.SetPackage(parent.Package())
+
+	ctx := bytecode.NewContext(callSymbols, callCode)
+	if err := ctx.Run(); err != nil && !errors.Equals(err, errors.ErrStop) {
+		ui.Log(ui.GoRoutineLogger, "go.afterfunc.error",
+			"error", err)
+	}
+}
+
+// getTimer returns the native timer for the receiver of a Timer method.
+func getTimer(s *symbols.SymbolTable) (*time.Timer, error) {
+	if v, found := s.Get(defs.ThisVariable); found {
+		if this, ok := v.(*data.Struct); ok {
+			if timer, ok := this.GetAlways(timerFieldName).(*time.Timer); ok {
+				return timer, nil
+			}
+		}
+
+		return nil, errors.ErrInvalidThis.Context(data.TypeOf(v).String())
+	}
+
+	return nil, errors.ErrNoFunctionReceiver
+}
+
+// getDurationArgument converts an argument to a duration. The argument can be a
+// Duration value, an integer number of nanoseconds such as 5*time.Second, or a
+// string such as "1m30s".
+func getDurationArgument(v interface{}, name string) (time.Duration, error) {
+	if d, err := data.GetNativeDuration(v); err == nil {
+		return *d, nil
+	}
+
+	if text, ok := v.(string); ok {
+		d, err := util.ParseDuration(text)
+		if err != nil {
+			return 0, errors.ErrInvalidDuration.In(name).Context(text)
+		}
+
+		return d, nil
+	}
+
+	n, err := data.Int64(v)
+	if err != nil {
+		return 0, errors.ErrInvalidDuration.In(name).Context(data.Format(v))
+	}
+
+	return time.Duration(n), nil
+}
@@ -0,0 +1,116 @@
+package time
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/symbols"
+)
+
+func TestGetDurationArgument(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     interface{}
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name: "duration value",
+			arg:  5 * time.Second,
+			want: 5 * time.Second,
+		},
+		{
+			name: "nanoseconds",
+			arg:  1500,
+			want: 1500 * time.Nanosecond,
+		},
+		{
+			name: "duration string",
+			arg:  "1m30s",
+			want: 90 * time.Second,
+		},
+		{
+			name:    "invalid string",
+			arg:     "soon",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getDurationArgument(tt.arg, "test")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getDurationArgument() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("getDurationArgument() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimer(t *testing.T) {
+	s := symbols.NewRootSymbolTable("timer test")
+	Initialize(s)
+
+	v, err := newTimer(s, data.NewList(time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewTimer() error = %v", err)
+	}
+
+	timer := v.(*data.Struct)
+	ch := timer.GetAlways(channelFieldName).(*data.Channel)
+
+	if _, err := ch.Receive(); err != nil {
+		t.Fatalf("Timer channel receive error = %v", err)
+	}
+
+	s.SetAlways(defs.ThisVariable, timer)
+
+	if stopped, _ := timerStop(s, data.NewList()); stopped != false {
+		t.Errorf("Stop() of expired timer = %v", stopped)
+	}
+
+	if active, _ := timerReset(s, data.NewList(time.Hour)); active != false {
+		t.Errorf("Reset() of expired timer = %v", active)
+	}
+
+	if stopped, _ := timerStop(s, data.NewList()); stopped != true {
+		t.Errorf("Stop() of active timer = %v", stopped)
+	}
+}
+
+func TestTicker(t *testing.T) {
+	s := symbols.NewRootSymbolTable("ticker test")
+	Initialize(s)
+
+	if _, err := newTicker(s, data.NewList(0)); err == nil {
+		t.Errorf("NewTicker(0) expected error")
+	}
+
+	v, err := newTicker(s, data.NewList(time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewTicker() error = %v", err)
+	}
+
+	ticker := v.(*data.Struct)
+	ch := ticker.GetAlways(channelFieldName).(*data.Channel)
+
+	for i := 0; i < 3; i++ {
+		if _, err := ch.Receive(); err != nil {
+			t.Fatalf("Ticker channel receive error = %v", err)
+		}
+	}
+
+	s.SetAlways(defs.ThisVariable, ticker)
+
+	// Stopping a ticker more than once is not an error.
+	for i := 0; i < 2; i++ {
+		if _, err := tickerStop(s, data.NewList()); err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	}
+}
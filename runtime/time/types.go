@@ -5,15 +5,41 @@ import (
 	"time"
 
 	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/compiler"
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/symbols"
 )
 
+// time.Timer type specification. The C field is the channel that receives the
+// time when the timer expires, and the timer field holds the native timer.
+const timerTypeSpec = `
+type Timer struct {
+	C     chan
+	timer interface{}
+}`
+
+// time.Ticker type specification. The C field is the channel that receives the
+// time of each tick, and the ticker field holds the native ticker state.
+const tickerTypeSpec = `
+type Ticker struct {
+	C      chan
+	ticker interface{}
+}`
+
+const (
+	channelFieldName = "C"
+	timerFieldName   = "timer"
+	tickerFieldName  = "ticker"
+)
+
 var timeType *data.Type
 var durationType *data.Type
 var locationType *data.Type
 var monthType *data.Type
+var weekdayType *data.Type
+var timerType *data.Type
+var tickerType *data.Type
 var initLock sync.Mutex
 
 // Initialize creates the "time" package and defines it's functions and the default
@@ -35,6 +61,17 @@ func Initialize(s *symbols.SymbolTable) {
 				Returns: []*data.Type{data.StringType},
 			}, nil)
 
+		weekdayType = data.TypeDefinition("Weekday", data.StructureType()).
+			SetNativeName(defs.TimeWeekdayTypeName).
+			SetPackage("time")
+
+		weekdayType.DefineNativeFunction("String",
+			&data.Declaration{
+				Name:    "String",
+				Type:    weekdayType,
+				Returns: []*data.Type{data.StringType},
+			}, nil)
+
 		locationType = data.TypeDefinition("Location", data.PointerType(data.StructureType())).
 			SetNativeName(defs.TimeLocationTypeName).
 			SetPackage("time")
@@ -139,6 +176,25 @@ func Initialize(s *symbols.SymbolTable) {
 				},
 				Returns: []*data.Type{data.BoolType},
 			}, nil).
+			DefineNativeFunction("AddDate", &data.Declaration{
+				Name: "AddDate",
+				Type: timeType,
+				Parameters: []data.Parameter{
+					{
+						Name: "years",
+						Type: data.IntType,
+					},
+					{
+						Name: "months",
+						Type: data.IntType,
+					},
+					{
+						Name: "days",
+						Type: data.IntType,
+					},
+				},
+				Returns: []*data.Type{timeType},
+			}, nil).
 			DefineNativeFunction("Before", &data.Declaration{
 				Name: "Before",
 				Type: timeType,
@@ -182,6 +238,22 @@ func Initialize(s *symbols.SymbolTable) {
 				Type:    timeType,
 				Returns: []*data.Type{data.IntType},
 			}, nil).
+			DefineNativeFunction("In", &data.Declaration{
+				Name: "In",
+				Type: timeType,
+				Parameters: []data.Parameter{
+					{
+						Name: "loc",
+						Type: data.PointerType(locationType),
+					},
+				},
+				Returns: []*data.Type{timeType},
+			}, nil).
+			DefineNativeFunction("ISOWeek", &data.Declaration{
+				Name:    "ISOWeek",
+				Type:    timeType,
+				Returns: []*data.Type{data.IntType, data.IntType},
+			}, nil).
 			DefineNativeFunction("Month", &data.Declaration{
 				Name:    "Month",
 				Type:    timeType,
@@ -192,6 +264,17 @@ func Initialize(s *symbols.SymbolTable) {
 				Type:    timeType,
 				Returns: []*data.Type{data.StringType},
 			}, nil).
+			DefineNativeFunction("Round", &data.Declaration{
+				Name: "Round",
+				Type: timeType,
+				Parameters: []data.Parameter{
+					{
+						Name: "d",
+						Type: durationType,
+					},
+				},
+				Returns: []*data.Type{timeType},
+			}, nil).
 			DefineNativeFunction("Sub", &data.Declaration{
 				Name: "Sub",
 				Type: timeType,
@@ -202,7 +285,70 @@ func Initialize(s *symbols.SymbolTable) {
 					},
 				},
 				Returns: []*data.Type{durationType},
+			}, nil).
+			DefineNativeFunction("Truncate", &data.Declaration{
+				Name: "Truncate",
+				Type: timeType,
+				Parameters: []data.Parameter{
+					{
+						Name: "d",
+						Type: durationType,
+					},
+				},
+				Returns: []*data.Type{timeType},
+			}, nil).
+			DefineNativeFunction("Weekday", &data.Declaration{
+				Name:    "Weekday",
+				Type:    timeType,
+				Returns: []*data.Type{weekdayType},
+			}, nil).
+			DefineNativeFunction("YearDay", &data.Declaration{
+				Name:    "YearDay",
+				Type:    timeType,
+				Returns: []*data.Type{data.IntType},
 			}, nil)
+
+		t, _ := compiler.CompileTypeSpec(timerTypeSpec, nil)
+
+		t.DefineFunction("Stop", &data.Declaration{
+			Name:    "Stop",
+			Type:    t,
+			Returns: []*data.Type{data.BoolType},
+		}, timerStop)
+
+		t.DefineFunction("Reset", &data.Declaration{
+			Name: "Reset",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "d",
+					Type: durationType,
+				},
+			},
+			Returns: []*data.Type{data.BoolType},
+		}, timerReset)
+
+		timerType = t.SetPackage("time")
+
+		t, _ = compiler.CompileTypeSpec(tickerTypeSpec, nil)
+
+		t.DefineFunction("Stop", &data.Declaration{
+			Name: "Stop",
+			Type: t,
+		}, tickerStop)
+
+		t.DefineFunction("Reset", &data.Declaration{
+			Name: "Reset",
+			Type: t,
+			Parameters: []data.Parameter{
+				{
+					Name: "d",
+					Type: durationType,
+				},
+			},
+		}, tickerReset)
+
+		tickerType = t.SetPackage("time")
 	}
 
 	if _, found := s.Root().Get("time"); !found {
@@ -338,6 +484,32 @@ func Initialize(s *symbols.SymbolTable) {
 				},
 				Value: parseDuration,
 			},
+			"NewTicker": data.Function{
+				Declaration: &data.Declaration{
+					Name: "NewTicker",
+					Parameters: []data.Parameter{
+						{
+							Name: "d",
+							Type: durationType,
+						},
+					},
+					Returns: []*data.Type{data.PointerType(tickerType)},
+				},
+				Value: newTicker,
+			},
+			"NewTimer": data.Function{
+				Declaration: &data.Declaration{
+					Name: "NewTimer",
+					Parameters: []data.Parameter{
+						{
+							Name: "d",
+							Type: durationType,
+						},
+					},
+					Returns: []*data.Type{data.PointerType(timerType)},
+				},
+				Value: newTimer,
+			},
 			"Since": data.Function{
 				Declaration: &data.Declaration{
 					Name: "Since",
@@ -365,10 +537,58 @@ func Initialize(s *symbols.SymbolTable) {
 				Value:    time.Sleep,
 				IsNative: true,
 			},
+			"After": data.Function{
+				Declaration: &data.Declaration{
+					Name: "After",
+					Parameters: []data.Parameter{
+						{
+							Name: "d",
+							Type: durationType,
+						},
+					},
+					Returns: []*data.Type{data.ChanType},
+				},
+				Value: after,
+			},
+			"AfterFunc": data.Function{
+				Declaration: &data.Declaration{
+					Name: "AfterFunc",
+					Parameters: []data.Parameter{
+						{
+							Name: "d",
+							Type: durationType,
+						},
+						{
+							Name: "f",
+							Type: data.FunctionType(&data.Function{
+								Declaration: &data.Declaration{},
+							}),
+						},
+					},
+					Returns: []*data.Type{data.PointerType(timerType)},
+				},
+				Value: afterFunc,
+			},
+			"Tick": data.Function{
+				Declaration: &data.Declaration{
+					Name: "Tick",
+					Parameters: []data.Parameter{
+						{
+							Name: "d",
+							Type: durationType,
+						},
+					},
+					Returns: []*data.Type{data.ChanType},
+				},
+				Value: tick,
+			},
 			"Time":     timeType,
 			"Duration": durationType,
 			"Location": locationType,
 			"Month":    monthType,
+			"Weekday":  weekdayType,
+			"Timer":    timerType,
+			"Ticker":   tickerType,
 		})
 
 		pkg, _ := bytecode.GetPackage(newpkg.Name)
@@ -0,0 +1,238 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
+)
+
+const (
+	// The name of the document element for a value that is not a value of a
+	// structure type, such as a map or an array.
+	rootElementName = "root"
+
+	// The name of the element for each member of an array document that is not
+	// a value of a structure type.
+	itemElementName = "item"
+
+	// The key used to store the text of an element that also has attributes or
+	// child elements when it is decoded as a map.
+	textKey = "#text"
+)
+
+// marshal writes an XML document from arbitrary data.
+func marshal(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	return encodeDocument("Marshal", args.Get(0), "", "")
+}
+
+// marshalIndent writes an XML document from arbitrary data, with each element
+// on a new line that starts with the prefix and is indented by the indent string.
+func marshalIndent(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	return encodeDocument("MarshalIndent", args.Get(0), data.String(args.Get(1)), data.String(args.Get(2)))
+}
+
+// encodeDocument writes a value as an XML document and returns the list of the
+// resulting byte array and any error. A value of a structure type is written as
+// an element named for the type, and any other value is written as an element
+// named "root". The members of an array are written as elements inside a "root"
+// element.
+func encodeDocument(name string, v interface{}, prefix, indent string) (interface{}, error) {
+	var buffer bytes.Buffer
+
+	enc := xml.NewEncoder(&buffer)
+	enc.Indent(prefix, indent)
+
+	err := encodeRoot(enc, v)
+	if err == nil {
+		err = enc.Flush()
+	}
+
+	if err != nil {
+		err = errors.New(err).In(name)
+	}
+
+	return data.NewList(data.NewArray(data.ByteType, 0).Append(buffer.Bytes()), err), err
+}
+
+// encodeRoot writes the document element for a value.
+func encodeRoot(enc *xml.Encoder, v interface{}) error {
+	if pointer, ok := v.(*interface{}); ok && pointer != nil {
+		v = *pointer
+	}
+
+	array, ok := v.(*data.Array)
+	if !ok {
+		return encodeElement(enc, elementName(v, rootElementName), v)
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: rootElementName}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for i := 0; i < array.Len(); i++ {
+		element, _ := array.Get(i)
+		if err := encodeElement(enc, elementName(element, itemElementName), element); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+// encodeElement writes a value as an element with the given name. The fields of
+// a structure and the members of a map are written as child elements. The members
+// of an array are written as repeated elements with the same name. Any other value
+// is written as the text of the element.
+func encodeElement(enc *xml.Encoder, name string, v interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch actual := v.(type) {
+	case *data.Struct:
+		return encodeStruct(enc, start, actual)
+
+	case *data.Map:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+
+		for _, key := range actual.Keys() {
+			value, _, _ := actual.Get(key)
+			if err := encodeElement(enc, data.String(key), value); err != nil {
+				return err
+			}
+		}
+
+		return enc.EncodeToken(start.End())
+
+	case *data.Array:
+		for i := 0; i < actual.Len(); i++ {
+			element, _ := actual.Get(i)
+			if err := encodeElement(enc, name, element); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case *interface{}:
+		if actual != nil {
+			return encodeElement(enc, name, *actual)
+		}
+
+		return encodeElement(enc, name, nil)
+
+	case nil:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+
+		return enc.EncodeToken(start.End())
+
+	default:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+
+		if err := enc.EncodeToken(xml.CharData(data.String(actual))); err != nil {
+			return err
+		}
+
+		return enc.EncodeToken(start.End())
+	}
+}
+
+// encodeStruct writes the fields of a structure as the child elements of an
+// element. The xml tags of the structure type can rename a field, omit it entirely
+// with "-", omit it when it has an empty value with the "omitempty" option, write
+// it as an attribute with the "attr" option, or write it as the text of the element
+// with the "chardata" option.
+func encodeStruct(enc *xml.Encoder, start xml.StartElement, v *data.Struct) error {
+	var (
+		text     string
+		children []string
+		names    = map[string]string{}
+	)
+
+	t := v.Type()
+
+	for _, field := range v.FieldNames(false) {
+		name, options, ok := fieldTag(t, field)
+		if !ok {
+			continue
+		}
+
+		value := v.GetAlways(field)
+		if util.InList("omitempty", options...) && data.IsEmptyValue(value) {
+			continue
+		}
+
+		switch {
+		case util.InList("attr", options...):
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: data.String(value)})
+
+		case util.InList("chardata", options...):
+			text = text + data.String(value)
+
+		default:
+			children = append(children, field)
+			names[field] = name
+		}
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if text != "" {
+		if err := enc.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range children {
+		if err := encodeElement(enc, names[field], v.GetAlways(field)); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+// fieldTag returns the element name and the list of tag options for a field of
+// a structure type. The boolean result is false if the field is tagged with "-",
+// and is not read or written.
+func fieldTag(t *data.Type, field string) (string, []string, bool) {
+	tag, found := t.FieldTagValue(field, "xml")
+	if !found {
+		return field, nil, true
+	}
+
+	if tag == "-" {
+		return "", nil, false
+	}
+
+	name, options := data.ParseTagValue(tag)
+	if name == "" {
+		name = field
+	}
+
+	return name, options, true
+}
+
+// elementName returns the name of the element for a value. A value of a named
+// structure type uses the name of the type, and any other value uses the default
+// name.
+func elementName(v interface{}, defaultName string) string {
+	if s, ok := v.(*data.Struct); ok {
+		if t := s.Type(); t.IsTypeDefinition() && t.Name() != "" {
+			return t.Name()
+		}
+	}
+
+	return defaultName
+}
@@ -0,0 +1,149 @@
+package xml
+
+import (
+	"testing"
+
+	"github.com/tucats/ego/data"
+)
+
+// personType returns a named structure type with xml tags.
+func personType() *data.Type {
+	base := data.StructureType(
+		data.Field{Name: "ID", Type: data.IntType},
+		data.Field{Name: "Name", Type: data.StringType},
+		data.Field{Name: "Tags", Type: data.ArrayType(data.StringType)},
+		data.Field{Name: "Secret", Type: data.StringType},
+	)
+	base.SetFieldTag("ID", `xml:"id,attr"`)
+	base.SetFieldTag("Name", `xml:"name"`)
+	base.SetFieldTag("Tags", `xml:"tag,omitempty"`)
+	base.SetFieldTag("Secret", `xml:"-"`)
+
+	return data.TypeDefinition("Person", base)
+}
+
+func TestMarshal(t *testing.T) {
+	person := data.NewStruct(personType())
+	_ = person.Set("ID", 7)
+	_ = person.Set("Name", "Tom & Jerry")
+	_ = person.Set("Tags", data.NewArrayFromInterfaces(data.StringType, "a", "b"))
+	_ = person.Set("Secret", "xyzzy")
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantXML string
+	}{
+		{
+			name:    "scalar value",
+			value:   42,
+			wantXML: `<root>42</root>`,
+		},
+		{
+			name: "map value",
+			value: data.NewMapFromMap(map[string]interface{}{
+				"b": true,
+				"a": "text",
+			}),
+			wantXML: `<root><a>text</a><b>true</b></root>`,
+		},
+		{
+			name:    "array value",
+			value:   data.NewArrayFromInterfaces(data.IntType, 1, 2),
+			wantXML: `<root><item>1</item><item>2</item></root>`,
+		},
+		{
+			name:    "tagged struct value",
+			value:   person,
+			wantXML: `<Person id="7"><name>Tom &amp; Jerry</name><tag>a</tag><tag>b</tag></Person>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := marshal(nil, data.NewList(tt.value))
+			if err != nil {
+				t.Fatalf("marshal() error = %v", err)
+			}
+
+			b := result.(data.List).Get(0).(*data.Array).GetBytes()
+			if string(b) != tt.wantXML {
+				t.Errorf("marshal() = %s, want %s", string(b), tt.wantXML)
+			}
+		})
+	}
+}
+
+func TestMarshalIndent(t *testing.T) {
+	value := data.NewMapFromMap(map[string]interface{}{"a": 1})
+
+	result, err := marshalIndent(nil, data.NewList(value, "", "  "))
+	if err != nil {
+		t.Fatalf("marshalIndent() error = %v", err)
+	}
+
+	want := "<root>\n  <a>1</a>\n</root>"
+	if b := result.(data.List).Get(0).(*data.Array).GetBytes(); string(b) != want {
+		t.Errorf("marshalIndent() = %q, want %q", string(b), want)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	text := `<Person id="7"><name>Tom</name><tag>a</tag><tag>b</tag><Secret>x</Secret></Person>`
+
+	// Without a model, the result is a map of the attributes and elements.
+	result, err := unmarshal(nil, data.NewList(text))
+	if err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+
+	m, ok := result.(data.List).Get(0).(*data.Map)
+	if !ok {
+		t.Fatalf("unmarshal() returned %T, want map", result.(data.List).Get(0))
+	}
+
+	if v, _, _ := m.Get("id"); v != "7" {
+		t.Errorf("unmarshal() id = %v, want 7", v)
+	}
+
+	if v, _, _ := m.Get("tag"); data.String(v) != `["a", "b"]` {
+		t.Errorf("unmarshal() tag = %v, want [\"a\", \"b\"]", v)
+	}
+
+	// With a model, the values are converted to the field types.
+	var model interface{} = data.NewStruct(personType())
+
+	result, err = unmarshal(nil, data.NewList(text, &model))
+	if err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+
+	if e := result.(data.List).Get(0); e != nil {
+		t.Fatalf("unmarshal() returned error %v", e)
+	}
+
+	person := model.(*data.Struct)
+	if person.GetAlways("ID") != 7 || person.GetAlways("Name") != "Tom" || person.GetAlways("Secret") != "" {
+		t.Errorf("unmarshal() = %v", person)
+	}
+
+	if tags := person.GetAlways("Tags").(*data.Array); tags.Len() != 2 {
+		t.Errorf("unmarshal() tags = %v", tags)
+	}
+
+	// An array is read from the repeated child elements.
+	model = data.NewArray(data.IntType, 0)
+
+	if _, err = unmarshal(nil, data.NewList(`<root><item>1</item><item>2</item></root>`, &model)); err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+
+	if a := model.(*data.Array); a.Len() != 2 || data.String(a) != "[1, 2]" {
+		t.Errorf("unmarshal() array = %v", a)
+	}
+
+	// A malformed document is an error.
+	if _, err = unmarshal(nil, data.NewList(`<root>`)); err == nil {
+		t.Errorf("unmarshal() expected error for malformed document")
+	}
+}
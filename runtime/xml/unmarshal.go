@@ -0,0 +1,259 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
+)
+
+// unmarshal reads a byte array or string as an XML document. The name of the
+// document element is not used. If there is no model, the result is the content
+// of the document element, which is a map of the attributes and child elements,
+// or a string if the element only contains text. If there is a pointer to a model
+// value, the content is converted to the type of the model.
+func unmarshal(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var text []byte
+
+	if a, ok := args.Get(0).(*data.Array); ok && a.Type().Kind() == data.ByteKind {
+		text = a.GetBytes()
+	} else {
+		text = []byte(data.String(args.Get(0)))
+	}
+
+	decodedValue, err := decodeDocument(text)
+	if err != nil {
+		err = errors.New(err).In("Unmarshal")
+
+		return data.NewList(nil, err), err
+	}
+
+	if args.Len() < 2 {
+		return data.NewList(egoValue(decodedValue), nil), nil
+	}
+
+	pointer, ok := args.Get(1).(*interface{})
+	if !ok {
+		return data.NewList(errors.ErrInvalidPointerType), nil
+	}
+
+	// An array is read from the repeated child elements of the document element.
+	if _, ok := (*pointer).(*data.Array); ok {
+		if m, ok := decodedValue.(map[string]interface{}); ok && len(m) == 1 {
+			for _, v := range m {
+				decodedValue = v
+			}
+		}
+	}
+
+	v, err := convert(decodedValue, *pointer)
+	if err != nil {
+		return data.NewList(errors.New(err).In("Unmarshal")), nil
+	}
+
+	*pointer = v
+
+	return data.NewList(nil), nil
+}
+
+// decodeDocument reads the document element of an XML document.
+func decodeDocument(text []byte) (interface{}, error) {
+	dec := xml.NewDecoder(bytes.NewReader(text))
+
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return decodeElement(dec, start)
+		}
+	}
+}
+
+// decodeElement reads the content of an element. An element that only contains
+// text is decoded as a string. Otherwise, the element is decoded as a map of the
+// attribute and child element names to their values. A child element that is
+// repeated is stored as an array of values, and any text in the element is stored
+// using the "#text" key.
+func decodeElement(dec *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	var text strings.Builder
+
+	m := map[string]interface{}{}
+
+	for _, attr := range start.Attr {
+		m[attr.Name.Local] = attr.Value
+	}
+
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch actual := token.(type) {
+		case xml.StartElement:
+			v, err := decodeElement(dec, actual)
+			if err != nil {
+				return nil, err
+			}
+
+			name := actual.Name.Local
+
+			if existing, found := m[name]; !found {
+				m[name] = v
+			} else if list, ok := existing.([]interface{}); ok {
+				m[name] = append(list, v)
+			} else {
+				m[name] = []interface{}{existing, v}
+			}
+
+		case xml.CharData:
+			text.Write(actual)
+
+		case xml.EndElement:
+			if len(m) == 0 {
+				return text.String(), nil
+			}
+
+			if s := strings.TrimSpace(text.String()); s != "" {
+				m[textKey] = s
+			}
+
+			return m, nil
+		}
+	}
+}
+
+// convert converts a decoded value to the type of the model value. The fields of
+// a structure are read from the attributes and child elements named by the xml
+// tags of the structure type.
+func convert(decoded interface{}, model interface{}) (interface{}, error) {
+	switch target := model.(type) {
+	case *data.Struct:
+		m, ok := decoded.(map[string]interface{})
+		if !ok {
+			// An empty element is a structure with no fields set.
+			if text, ok := decoded.(string); ok && strings.TrimSpace(text) == "" {
+				return target, nil
+			}
+
+			return nil, errors.ErrInvalidType.Context(data.TypeOf(model).String())
+		}
+
+		t := target.Type()
+
+		for _, field := range target.FieldNames(false) {
+			name, options, ok := fieldTag(t, field)
+			if !ok {
+				continue
+			}
+
+			if util.InList("chardata", options...) {
+				name = textKey
+			}
+
+			v, found := m[name]
+			if !found {
+				continue
+			}
+
+			value, err := convert(v, target.GetAlways(field))
+			if err != nil {
+				return nil, err
+			}
+
+			if err := target.Set(field, value); err != nil {
+				return nil, err
+			}
+		}
+
+		return target, nil
+
+	case *data.Map:
+		m, ok := decoded.(map[string]interface{})
+		if !ok {
+			return nil, errors.ErrInvalidType.Context(data.TypeOf(model).String())
+		}
+
+		for k, v := range m {
+			key, err := data.Coerce(k, data.InstanceOfType(target.KeyType()))
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := convert(v, data.InstanceOfType(target.ElementType()))
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := target.Set(key, value); err != nil {
+				return nil, err
+			}
+		}
+
+		return target, nil
+
+	case *data.Array:
+		list, ok := decoded.([]interface{})
+		if !ok {
+			list = []interface{}{decoded}
+		}
+
+		result := data.NewArray(target.Type(), len(list))
+
+		for i, v := range list {
+			value, err := convert(v, data.InstanceOfType(target.Type()))
+			if err != nil {
+				return nil, err
+			}
+
+			if err := result.Set(i, value); err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+
+	case nil, data.Interface:
+		return egoValue(decoded), nil
+
+	default:
+		return data.Coerce(decoded, model)
+	}
+}
+
+// egoValue converts a decoded value to an Ego value. Maps and arrays are converted
+// to the Ego versions of the map and array, including the values they contain.
+func egoValue(decoded interface{}) interface{} {
+	switch actual := decoded.(type) {
+	case map[string]interface{}:
+		result := data.NewMap(data.StringType, data.InterfaceType)
+		for k, v := range actual {
+			_, _ = result.Set(k, egoValue(v))
+		}
+
+		return result
+
+	case []interface{}:
+		result := data.NewArray(data.InterfaceType, len(actual))
+		for i, v := range actual {
+			_ = result.Set(i, egoValue(v))
+		}
+
+		return result
+
+	default:
+		return decoded
+	}
+}
@@ -0,0 +1,121 @@
+package yaml
+
+import (
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
+	"gopkg.in/yaml.v3"
+)
+
+// marshal writes a YAML document from arbitrary data.
+func marshal(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var yamlBuffer []byte
+
+	node, err := encode(args.Get(0))
+	if err == nil {
+		yamlBuffer, err = yaml.Marshal(node)
+	}
+
+	if err != nil {
+		err = errors.New(err).In("Marshal")
+	}
+
+	return data.NewList(data.NewArray(data.ByteType, 0).Append(yamlBuffer), err), err
+}
+
+// encode converts an Ego value to a YAML document node. Structures are written
+// as mappings with the fields in the order they are declared, and the yaml tags
+// of the structure type can rename a field, omit it entirely with "-", or omit
+// it when it has an empty value with the "omitempty" option. Maps are written as
+// mappings in key order, and arrays are written as sequences.
+func encode(v interface{}) (*yaml.Node, error) {
+	switch actual := v.(type) {
+	case *data.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		t := actual.Type()
+
+		for _, field := range actual.FieldNames(false) {
+			name, value := field, actual.GetAlways(field)
+
+			if tag, found := t.FieldTagValue(field, "yaml"); found {
+				if tag == "-" {
+					continue
+				}
+
+				tagName, options := data.ParseTagValue(tag)
+				if tagName != "" {
+					name = tagName
+				}
+
+				if util.InList("omitempty", options...) && data.IsEmptyValue(value) {
+					continue
+				}
+			}
+
+			if err := appendPair(node, name, value); err != nil {
+				return nil, err
+			}
+		}
+
+		return node, nil
+
+	case *data.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+
+		for _, key := range actual.Keys() {
+			value, _, _ := actual.Get(key)
+			if err := appendPair(node, key, value); err != nil {
+				return nil, err
+			}
+		}
+
+		return node, nil
+
+	case *data.Array:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+
+		for i := 0; i < actual.Len(); i++ {
+			element, _ := actual.Get(i)
+
+			child, err := encode(element)
+			if err != nil {
+				return nil, err
+			}
+
+			node.Content = append(node.Content, child)
+		}
+
+		return node, nil
+
+	case *interface{}:
+		if actual != nil {
+			return encode(*actual)
+		}
+
+		return encode(nil)
+
+	default:
+		node := &yaml.Node{}
+		err := node.Encode(data.Sanitize(actual))
+
+		return node, err
+	}
+}
+
+// appendPair adds the nodes for a key and its value to a mapping node.
+func appendPair(node *yaml.Node, key, value interface{}) error {
+	keyNode, err := encode(key)
+	if err != nil {
+		return err
+	}
+
+	valueNode, err := encode(value)
+	if err != nil {
+		return err
+	}
+
+	node.Content = append(node.Content, keyNode, valueNode)
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package yaml
+
+import (
+	"testing"
+
+	"github.com/tucats/ego/data"
+)
+
+func TestMarshal(t *testing.T) {
+	tagged := data.StructureType(
+		data.Field{Name: "Name", Type: data.StringType},
+		data.Field{Name: "Age", Type: data.IntType},
+		data.Field{Name: "Secret", Type: data.StringType},
+		data.Field{Name: "Nickname", Type: data.StringType},
+	)
+	tagged.SetFieldTag("Name", `yaml:"name"`)
+	tagged.SetFieldTag("Secret", `yaml:"-"`)
+	tagged.SetFieldTag("Nickname", `yaml:"nick,omitempty"`)
+
+	person := data.NewStruct(tagged)
+	_ = person.Set("Name", "Tom")
+	_ = person.Set("Age", 55)
+	_ = person.Set("Secret", "xyzzy")
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		wantYAML string
+	}{
+		{
+			name:     "string value",
+			value:    "hello",
+			wantYAML: "hello\n",
+		},
+		{
+			name:     "integer value",
+			value:    42,
+			wantYAML: "42\n",
+		},
+		{
+			name:     "array value",
+			value:    data.NewArrayFromInterfaces(data.IntType, 1, 2, 3),
+			wantYAML: "- 1\n- 2\n- 3\n",
+		},
+		{
+			name: "map value",
+			value: data.NewMapFromMap(map[string]interface{}{
+				"b": true,
+				"a": "text",
+			}),
+			wantYAML: "a: text\nb: true\n",
+		},
+		{
+			name:     "tagged struct value",
+			value:    person,
+			wantYAML: "name: Tom\nAge: 55\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := marshal(nil, data.NewList(tt.value))
+			if err != nil {
+				t.Fatalf("marshal() error = %v", err)
+			}
+
+			b := result.(data.List).Get(0).(*data.Array).GetBytes()
+			if string(b) != tt.wantYAML {
+				t.Errorf("marshal() = %q, want %q", string(b), tt.wantYAML)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	text := "name: Tom\nAge: 55\ntags:\n  - a\n  - b\n"
+
+	result, err := unmarshal(nil, data.NewList(text))
+	if err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+
+	m, ok := result.(data.List).Get(0).(*data.Map)
+	if !ok {
+		t.Fatalf("unmarshal() returned %T, want map", result.(data.List).Get(0))
+	}
+
+	if v, _, _ := m.Get("name"); v != "Tom" {
+		t.Errorf("unmarshal() name = %v, want Tom", v)
+	}
+
+	tagged := data.StructureType(
+		data.Field{Name: "Name", Type: data.StringType},
+		data.Field{Name: "Age", Type: data.IntType},
+	)
+	tagged.SetFieldTag("Name", `yaml:"name"`)
+
+	var model interface{} = data.NewStruct(tagged)
+
+	result, err = unmarshal(nil, data.NewList("name: Tom\nAge: 55\n", &model))
+	if err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+
+	if e := result.(data.List).Get(0); e != nil {
+		t.Fatalf("unmarshal() returned error %v", e)
+	}
+
+	person := model.(*data.Struct)
+	if person.GetAlways("Name") != "Tom" || person.GetAlways("Age") != 55 {
+		t.Errorf("unmarshal() = %v", person)
+	}
+}
@@ -0,0 +1,55 @@
+package yaml
+
+import (
+	"sync"
+
+	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/symbols"
+)
+
+var initLock sync.Mutex
+
+func Initialize(s *symbols.SymbolTable) {
+	initLock.Lock()
+	defer initLock.Unlock()
+
+	if _, found := s.Root().Get("yaml"); !found {
+		newpkg := data.NewPackageFromMap("yaml", map[string]interface{}{
+			"Marshal": data.Function{
+				Declaration: &data.Declaration{
+					Name: "Marshal",
+					Parameters: []data.Parameter{
+						{
+							Name: "any",
+							Type: data.InterfaceType,
+						},
+					},
+					Returns: []*data.Type{data.ArrayType(data.ByteType), data.ErrorType},
+				},
+				Value: marshal,
+			},
+			"Unmarshal": data.Function{
+				Declaration: &data.Declaration{
+					Name: "Unmarshal",
+					Parameters: []data.Parameter{
+						{
+							Name: "data",
+							Type: data.ArrayType(data.ByteType),
+						},
+						{
+							Name: "value",
+							Type: data.PointerType(data.InterfaceType),
+						},
+					},
+					Returns: []*data.Type{data.ErrorType},
+				},
+				Value: unmarshal,
+			},
+		})
+
+		pkg, _ := bytecode.GetPackage(newpkg.Name)
+		pkg.Merge(newpkg)
+		s.Root().SetAlways(newpkg.Name, newpkg)
+	}
+}
@@ -0,0 +1,84 @@
+package yaml
+
+import (
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/runtime/json"
+	"github.com/tucats/ego/symbols"
+	"gopkg.in/yaml.v3"
+)
+
+// unmarshal reads a byte array or string as YAML data. If there is a pointer
+// to a model value, the data is stored in it using the same conversions as the
+// json package, with the yaml tags of a structure type naming the fields.
+func unmarshal(s *symbols.SymbolTable, args data.List) (interface{}, error) {
+	var (
+		decodedValue interface{}
+		err          error
+	)
+
+	if a, ok := args.Get(0).(*data.Array); ok && a.Type().Kind() == data.ByteKind {
+		err = yaml.Unmarshal(a.GetBytes(), &decodedValue)
+	} else {
+		err = yaml.Unmarshal([]byte(data.String(args.Get(0))), &decodedValue)
+	}
+
+	if err != nil {
+		err = errors.New(err).In("Unmarshal")
+
+		return data.NewList(nil, err), err
+	}
+
+	decodedValue = normalize(decodedValue)
+
+	// If there is no model, convert a map or array to the Ego version of a
+	// map or array.
+	if args.Len() < 2 {
+		if m, ok := decodedValue.(map[string]interface{}); ok {
+			decodedValue = data.NewMapFromMap(m)
+		} else if a, ok := decodedValue.([]interface{}); ok {
+			decodedValue = data.NewArrayFromInterfaces(data.InterfaceType, a...)
+		}
+
+		return data.NewList(decodedValue, nil), nil
+	}
+
+	pointer, ok := args.Get(1).(*interface{})
+	if !ok {
+		return data.NewList(errors.ErrInvalidPointerType), nil
+	}
+
+	return json.RemapDecodedValue(decodedValue, pointer, "yaml")
+}
+
+// normalize converts the decoded YAML data to the same form as decoded JSON data.
+// A YAML mapping with keys that are not strings is decoded as a map with interface
+// keys, so these are converted to maps with string keys.
+func normalize(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(actual))
+		for key, value := range actual {
+			result[data.String(key)] = normalize(value)
+		}
+
+		return result
+
+	case map[string]interface{}:
+		for key, value := range actual {
+			actual[key] = normalize(value)
+		}
+
+		return actual
+
+	case []interface{}:
+		for i, value := range actual {
+			actual[i] = normalize(value)
+		}
+
+		return actual
+
+	default:
+		return v
+	}
+}
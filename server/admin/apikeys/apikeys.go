@@ -0,0 +1,128 @@
+// Package apikeys contains the handlers for the admin endpoints that manage the API
+// keys used by programs to access the server on behalf of a user. A key can only use
+// a subset of the user's permissions, and can be limited to a set of addresses.
+package apikeys
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/auth"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/util"
+)
+
+// ListAPIKeysHandler is the handler for the GET method on the API keys endpoint. It
+// returns the API keys, optionally only those for the user given by the "user"
+// parameter. The text of the keys is never returned.
+func ListAPIKeysHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	user := ""
+	if v, found := session.Parameters["user"]; found && len(v) > 0 {
+		user = v[0]
+	}
+
+	return writeAPIKeys(session, w, auth.APIKeys(user))
+}
+
+// CreateAPIKeyHandler is the handler for the POST method on the API keys endpoint. The
+// payload describes the user, permissions, expiration and allowed addresses of the new
+// key. The response contains the text of the key, which cannot be retrieved again.
+func CreateAPIKeyHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	request := defs.APIKey{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ui.Log(ui.RestLogger, "rest.bad.payload", ui.A{
+			"session": session.ID,
+			"error":   err})
+
+		return util.ErrorResponse(w, session.ID, errors.ErrInvalidRequest.Error(), http.StatusBadRequest)
+	}
+
+	text, key, err := auth.CreateAPIKey(request)
+	if err != nil {
+		status := http.StatusInternalServerError
+
+		switch {
+		case errors.Equals(err, errors.ErrNoSuchUser):
+			status = http.StatusNotFound
+
+		case errors.Equals(err, errors.ErrInvalidRequest), errors.Equals(err, errors.ErrNoPermission):
+			status = http.StatusBadRequest
+		}
+
+		return util.ErrorResponse(w, session.ID, err.Error(), status)
+	}
+
+	response := defs.APIKeyResponse{
+		ServerInfo: util.MakeServerInfo(session.ID),
+		Key:        text,
+		Item:       key,
+		Status:     http.StatusOK,
+	}
+
+	w.Header().Add(defs.ContentTypeHeader, defs.APIKeyMediaType)
+	w.WriteHeader(http.StatusOK)
+
+	b, _ := json.MarshalIndent(response, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+	_, _ = w.Write(b)
+	session.ResponseLength += len(b)
+
+	// The key is not written to the log.
+	if ui.IsActive(ui.RestLogger) {
+		response.Key = "********"
+		b, _ = json.MarshalIndent(response, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+
+		ui.WriteLog(ui.RestLogger, "rest.response.payload", ui.A{
+			"session": session.ID,
+			"body":    string(b)})
+	}
+
+	return http.StatusOK
+}
+
+// RevokeAPIKeyHandler is the handler for the DELETE method on the API keys endpoint
+// with a key ID provided in the path. The key is deleted so it can no longer be used.
+func RevokeAPIKeyHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	id := data.String(session.URLParts["id"])
+
+	revoked, err := auth.RevokeAPIKey(id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Equals(err, errors.ErrNoSuchAPIKey) {
+			status = http.StatusNotFound
+		}
+
+		return util.ErrorResponse(w, session.ID, err.Error(), status)
+	}
+
+	return writeAPIKeys(session, w, []defs.APIKey{revoked})
+}
+
+// writeAPIKeys writes the response containing a list of API keys.
+func writeAPIKeys(session *server.Session, w http.ResponseWriter, items []defs.APIKey) int {
+	result := defs.APIKeyCollection{
+		BaseCollection: util.MakeBaseCollection(session.ID),
+		Items:          items,
+	}
+
+	result.Count = len(items)
+	result.Status = http.StatusOK
+
+	w.Header().Add(defs.ContentTypeHeader, defs.APIKeysMediaType)
+	w.WriteHeader(http.StatusOK)
+
+	b, _ := json.MarshalIndent(result, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+	_, _ = w.Write(b)
+	session.ResponseLength += len(b)
+
+	if ui.IsActive(ui.RestLogger) {
+		ui.WriteLog(ui.RestLogger, "rest.response.payload", ui.A{
+			"session": session.ID,
+			"body":    string(b)})
+	}
+
+	return http.StatusOK
+}
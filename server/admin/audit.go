@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/egostrings"
+	"github.com/tucats/ego/server/audit"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/util"
+)
+
+// GetAuditHandler is the server endpoint handler for querying the audit log. The
+// entries can be filtered using the "event", "user", "session", "since", "until"
+// and "limit" parameters. The "since" and "until" times can be RFC 3339 times, or
+// durations such as "24h" measured back from the current time.
+func GetAuditHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	var (
+		filter audit.Filter
+		err    error
+	)
+
+	parameter := func(name string) string {
+		if v, found := session.Parameters[name]; found && len(v) > 0 {
+			return v[0]
+		}
+
+		return ""
+	}
+
+	filter.Event = parameter("event")
+	filter.User = parameter("user")
+
+	if v := parameter("session"); v != "" {
+		if filter.Session, err = egostrings.Atoi(v); err != nil {
+			return util.ErrorResponse(w, session.ID, "Invalid session value: "+v, http.StatusBadRequest)
+		}
+	}
+
+	if v := parameter("limit"); v != "" {
+		if filter.Limit, err = egostrings.Atoi(v); err != nil {
+			return util.ErrorResponse(w, session.ID, "Invalid limit value: "+v, http.StatusBadRequest)
+		}
+	}
+
+	if v := parameter("since"); v != "" {
+		if filter.Since, err = auditTime(v); err != nil {
+			return util.ErrorResponse(w, session.ID, "Invalid since value: "+v, http.StatusBadRequest)
+		}
+	}
+
+	if v := parameter("until"); v != "" {
+		if filter.Until, err = auditTime(v); err != nil {
+			return util.ErrorResponse(w, session.ID, "Invalid until value: "+v, http.StatusBadRequest)
+		}
+	}
+
+	result, err := audit.Query(filter)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	result.BaseCollection = util.MakeBaseCollection(session.ID)
+	result.Count = len(result.Items)
+	result.Status = http.StatusOK
+
+	w.Header().Add(defs.ContentTypeHeader, defs.AuditMediaType)
+
+	b, _ := json.MarshalIndent(result, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+	_, _ = w.Write(b)
+	session.ResponseLength += len(b)
+
+	if ui.IsActive(ui.RestLogger) {
+		ui.WriteLog(ui.RestLogger, "rest.response.payload", ui.A{
+			"session": session.ID,
+			"body":    string(b)})
+	}
+
+	return http.StatusOK
+}
+
+// auditTime converts the text of a time used to filter the audit log. This can be
+// an RFC 3339 time, or a duration measured back from the current time.
+func auditTime(text string) (time.Time, error) {
+	if duration, err := util.ParseDuration(text); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+
+	return time.Parse(time.RFC3339, text)
+}
@@ -3,7 +3,9 @@ package caches
 import (
 	"net/http"
 
+	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/server/assets"
+	"github.com/tucats/ego/server/audit"
 	"github.com/tucats/ego/server/server"
 	"github.com/tucats/ego/server/services"
 )
@@ -11,6 +13,10 @@ import (
 // PurgeCacheHandler is the cache endpoint handler that purges all entries in the cache,
 // and then returns the (revised) cache status.
 func PurgeCacheHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	audit.Record("cache.purge", ui.A{
+		"session": session.ID,
+		"user":    session.User})
+
 	// Release the entries in the asset cache.
 	assets.FlushAssetCache()
 
@@ -0,0 +1,159 @@
+package roles
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/server/audit"
+	"github.com/tucats/ego/server/auth"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/util"
+)
+
+// ListGroupsHandler is the handler for the GET method on the groups endpoint. It returns
+// a list of all groups.
+func ListGroupsHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	result := defs.GroupCollection{
+		BaseCollection: util.MakeBaseCollection(session.ID),
+		Items:          []defs.Group{},
+	}
+
+	for _, group := range auth.AuthService.ListGroups() {
+		result.Items = append(result.Items, group)
+	}
+
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].Name < result.Items[j].Name
+	})
+
+	result.Count = len(result.Items)
+	result.Status = http.StatusOK
+
+	return writeResponse(session, w, defs.GroupsMediaType, result)
+}
+
+// GetGroupHandler is the handler for the GET method on the groups endpoint with a group
+// name provided in the path.
+func GetGroupHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	name := data.String(session.URLParts["name"])
+
+	group, err := auth.AuthService.ReadGroup(name)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), errorStatus(err))
+	}
+
+	return writeGroup(session, w, group)
+}
+
+// CreateGroupHandler is the handler for the POST method on the groups endpoint. It creates
+// a new group using the JSON payload in the request.
+func CreateGroupHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	group := defs.Group{}
+	if err := readBody(session, r, &group); err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
+	}
+
+	if _, err := auth.AuthService.ReadGroup(group.Name); err == nil {
+		return util.ErrorResponse(w, session.ID, "group already exists: "+group.Name, http.StatusConflict)
+	}
+
+	if err := auth.SetGroup(group); err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), updateErrorStatus(err))
+	}
+
+	audit.Record("group.create", ui.A{
+		"session": session.ID,
+		"user":    session.User,
+		"name":    group.Name,
+		"members": group.Members,
+		"roles":   group.Roles})
+
+	return writeGroupByName(session, w, group.Name)
+}
+
+// UpdateGroupHandler is the handler for the PATCH method on the groups endpoint with a group
+// name provided in the path. The description is replaced if one is given. The members and
+// roles in the payload are added to the group, or removed from the group if the name starts
+// with a "-" character.
+func UpdateGroupHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	name := data.String(session.URLParts["name"])
+
+	group, err := auth.AuthService.ReadGroup(name)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), errorStatus(err))
+	}
+
+	changes := defs.Group{}
+	if err := readBody(session, r, &changes); err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
+	}
+
+	if changes.Name != "" && changes.Name != group.Name {
+		return util.ErrorResponse(w, session.ID, "cannot change group name", http.StatusBadRequest)
+	}
+
+	if changes.Description != "" {
+		group.Description = changes.Description
+	}
+
+	group.Members = applyChanges(group.Members, changes.Members)
+	group.Roles = applyChanges(group.Roles, changes.Roles)
+
+	if err := auth.SetGroup(group); err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), updateErrorStatus(err))
+	}
+
+	audit.Record("group.update", ui.A{
+		"session": session.ID,
+		"user":    session.User,
+		"name":    group.Name,
+		"members": group.Members,
+		"roles":   group.Roles})
+
+	return writeGroupByName(session, w, group.Name)
+}
+
+// DeleteGroupHandler is the handler for the DELETE method on the groups endpoint with a group
+// name provided in the path.
+func DeleteGroupHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	name := data.String(session.URLParts["name"])
+
+	group, err := auth.AuthService.ReadGroup(name)
+	if err == nil {
+		err = auth.RemoveGroup(name)
+	}
+
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), errorStatus(err))
+	}
+
+	audit.Record("group.delete", ui.A{
+		"session": session.ID,
+		"user":    session.User,
+		"name":    group.Name})
+
+	return writeGroup(session, w, group)
+}
+
+// writeGroupByName writes the response containing the named group, as it is currently
+// stored in the user database.
+func writeGroupByName(session *server.Session, w http.ResponseWriter, name string) int {
+	group, err := auth.AuthService.ReadGroup(name)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	return writeGroup(session, w, group)
+}
+
+// writeGroup writes the response containing a single group.
+func writeGroup(session *server.Session, w http.ResponseWriter, group defs.Group) int {
+	return writeResponse(session, w, defs.GroupMediaType, defs.GroupResponse{
+		ServerInfo: util.MakeServerInfo(session.ID),
+		Group:      group,
+		Status:     http.StatusOK,
+	})
+}
@@ -0,0 +1,264 @@
+// Package roles contains the handlers for the admin endpoints that manage the
+// roles and groups in the user database. A role is a named set of permissions
+// that can inherit the permissions of other roles, and a group is a named set
+// of users that are granted the permissions of the roles assigned to the group.
+package roles
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/audit"
+	"github.com/tucats/ego/server/auth"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/util"
+)
+
+// ListRolesHandler is the handler for the GET method on the roles endpoint. It returns
+// a list of all roles.
+func ListRolesHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	result := defs.RoleCollection{
+		BaseCollection: util.MakeBaseCollection(session.ID),
+		Items:          []defs.Role{},
+	}
+
+	for _, role := range auth.AuthService.ListRoles() {
+		result.Items = append(result.Items, role)
+	}
+
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].Name < result.Items[j].Name
+	})
+
+	result.Count = len(result.Items)
+	result.Status = http.StatusOK
+
+	return writeResponse(session, w, defs.RolesMediaType, result)
+}
+
+// GetRoleHandler is the handler for the GET method on the roles endpoint with a role
+// name provided in the path.
+func GetRoleHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	name := data.String(session.URLParts["name"])
+
+	role, err := auth.AuthService.ReadRole(name)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), errorStatus(err))
+	}
+
+	return writeRole(session, w, role)
+}
+
+// CreateRoleHandler is the handler for the POST method on the roles endpoint. It creates
+// a new role using the JSON payload in the request.
+func CreateRoleHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	role := defs.Role{}
+	if err := readBody(session, r, &role); err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
+	}
+
+	if _, err := auth.AuthService.ReadRole(role.Name); err == nil {
+		return util.ErrorResponse(w, session.ID, "role already exists: "+role.Name, http.StatusConflict)
+	}
+
+	if err := auth.SetRole(role); err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), updateErrorStatus(err))
+	}
+
+	audit.Record("role.create", ui.A{
+		"session":     session.ID,
+		"user":        session.User,
+		"name":        role.Name,
+		"permissions": role.Permissions,
+		"inherits":    role.Inherits})
+
+	return writeRoleByName(session, w, role.Name)
+}
+
+// UpdateRoleHandler is the handler for the PATCH method on the roles endpoint with a role
+// name provided in the path. The description is replaced if one is given. The permissions
+// and inherited roles in the payload are added to the role, or removed from the role if the
+// name starts with a "-" character.
+func UpdateRoleHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	name := data.String(session.URLParts["name"])
+
+	role, err := auth.AuthService.ReadRole(name)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), errorStatus(err))
+	}
+
+	changes := defs.Role{}
+	if err := readBody(session, r, &changes); err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
+	}
+
+	if changes.Name != "" && changes.Name != role.Name {
+		return util.ErrorResponse(w, session.ID, "cannot change role name", http.StatusBadRequest)
+	}
+
+	if changes.Description != "" {
+		role.Description = changes.Description
+	}
+
+	role.Permissions = applyChanges(role.Permissions, changes.Permissions)
+	role.Inherits = applyChanges(role.Inherits, changes.Inherits)
+
+	if err := auth.SetRole(role); err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), updateErrorStatus(err))
+	}
+
+	audit.Record("role.update", ui.A{
+		"session":     session.ID,
+		"user":        session.User,
+		"name":        role.Name,
+		"permissions": role.Permissions,
+		"inherits":    role.Inherits})
+
+	return writeRoleByName(session, w, role.Name)
+}
+
+// DeleteRoleHandler is the handler for the DELETE method on the roles endpoint with a role
+// name provided in the path. A role cannot be deleted while it is inherited by another role
+// or assigned to a group.
+func DeleteRoleHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	name := data.String(session.URLParts["name"])
+
+	role, err := auth.AuthService.ReadRole(name)
+	if err == nil {
+		err = auth.RemoveRole(name)
+	}
+
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), errorStatus(err))
+	}
+
+	audit.Record("role.delete", ui.A{
+		"session": session.ID,
+		"user":    session.User,
+		"name":    role.Name})
+
+	return writeRole(session, w, role)
+}
+
+// writeRoleByName writes the response containing the named role, as it is currently
+// stored in the user database.
+func writeRoleByName(session *server.Session, w http.ResponseWriter, name string) int {
+	role, err := auth.AuthService.ReadRole(name)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	return writeRole(session, w, role)
+}
+
+// writeRole writes the response containing a single role.
+func writeRole(session *server.Session, w http.ResponseWriter, role defs.Role) int {
+	return writeResponse(session, w, defs.RoleMediaType, defs.RoleResponse{
+		ServerInfo: util.MakeServerInfo(session.ID),
+		Role:       role,
+		Status:     http.StatusOK,
+	})
+}
+
+// writeResponse writes the JSON representation of the response object using the given
+// media type.
+func writeResponse(session *server.Session, w http.ResponseWriter, mediaType string, response interface{}) int {
+	w.Header().Add(defs.ContentTypeHeader, mediaType)
+	w.WriteHeader(http.StatusOK)
+
+	b, _ := json.MarshalIndent(response, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+	_, _ = w.Write(b)
+	session.ResponseLength += len(b)
+
+	if ui.IsActive(ui.RestLogger) {
+		ui.WriteLog(ui.RestLogger, "rest.response.payload", ui.A{
+			"session": session.ID,
+			"body":    string(b)})
+	}
+
+	return http.StatusOK
+}
+
+// readBody reads the JSON payload of the request into the given object.
+func readBody(session *server.Session, r *http.Request, item interface{}) error {
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(r.Body); err != nil {
+		return errors.New(err)
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), item); err != nil {
+		ui.Log(ui.RestLogger, "rest.bad.payload", ui.A{
+			"session": session.ID,
+			"error":   err})
+
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// applyChanges applies a list of changes to a list of names. Each name in the list of
+// changes is added to the list, unless it starts with a "-" character, in which case it
+// is removed from the list. A name can also start with a "+" character to show that it
+// is added.
+func applyChanges(list, changes []string) []string {
+	if len(changes) == 0 {
+		return list
+	}
+
+	set := map[string]bool{}
+	for _, name := range list {
+		set[name] = true
+	}
+
+	for _, name := range changes {
+		if strings.HasPrefix(name, "-") {
+			delete(set, strings.TrimPrefix(name, "-"))
+		} else {
+			set[strings.TrimPrefix(name, "+")] = true
+		}
+	}
+
+	result := make([]string, 0, len(set))
+	for name := range set {
+		result = append(result, name)
+	}
+
+	return result
+}
+
+// updateErrorStatus returns the HTTP status for an error creating or updating a role or
+// group. Because the role or group being changed is known to exist, a role that does not
+// exist must be one referenced by the payload, so the request is invalid.
+func updateErrorStatus(err error) int {
+	status := errorStatus(err)
+	if status == http.StatusNotFound {
+		status = http.StatusBadRequest
+	}
+
+	return status
+}
+
+// errorStatus returns the HTTP status for an error from the user database.
+func errorStatus(err error) int {
+	switch {
+	case errors.Equals(err, errors.ErrNoSuchRole), errors.Equals(err, errors.ErrNoSuchGroup):
+		return http.StatusNotFound
+
+	case errors.Equals(err, errors.ErrRoleInUse):
+		return http.StatusConflict
+
+	case errors.Equals(err, errors.ErrRoleCycle), errors.Equals(err, errors.ErrInvalidValue):
+		return http.StatusBadRequest
+
+	default:
+		return http.StatusInternalServerError
+	}
+}
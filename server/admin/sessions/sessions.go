@@ -0,0 +1,93 @@
+// Package sessions contains the handlers for the admin endpoints that manage the
+// sessions for the tokens issued by the server. A session can be revoked, so its
+// token is no longer accepted even though it has not expired.
+package sessions
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/auth"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/util"
+)
+
+// ListSessionsHandler is the handler for the GET method on the sessions endpoint. It
+// returns the active sessions, optionally only those for the user given by the "user"
+// parameter.
+func ListSessionsHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	user := ""
+	if v, found := session.Parameters["user"]; found && len(v) > 0 {
+		user = v[0]
+	}
+
+	return writeSessions(session, w, auth.Sessions(user))
+}
+
+// RevokeSessionHandler is the handler for the DELETE method on the sessions endpoint
+// with a session ID provided in the path. The token for the session is revoked.
+func RevokeSessionHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	id := data.String(session.URLParts["id"])
+
+	revoked, err := auth.RevokeSession(id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Equals(err, errors.ErrNoSuchSession) {
+			status = http.StatusNotFound
+		}
+
+		return util.ErrorResponse(w, session.ID, err.Error(), status)
+	}
+
+	return writeSessions(session, w, []defs.LogonSession{revoked})
+}
+
+// RevokeSessionsHandler is the handler for the DELETE method on the sessions endpoint.
+// All the active tokens for the user given by the "user" parameter are revoked.
+func RevokeSessionsHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	user := ""
+	if v, found := session.Parameters["user"]; found && len(v) > 0 {
+		user = v[0]
+	}
+
+	if user == "" {
+		return util.ErrorResponse(w, session.ID, errors.ErrInvalidRequest.Context("user").Error(), http.StatusBadRequest)
+	}
+
+	revoked, err := auth.RevokeSessions(user)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	return writeSessions(session, w, revoked)
+}
+
+// writeSessions writes the response containing a list of sessions.
+func writeSessions(session *server.Session, w http.ResponseWriter, items []defs.LogonSession) int {
+	result := defs.SessionCollection{
+		BaseCollection: util.MakeBaseCollection(session.ID),
+		Items:          items,
+	}
+
+	result.Count = len(items)
+	result.Status = http.StatusOK
+
+	w.Header().Add(defs.ContentTypeHeader, defs.SessionsMediaType)
+	w.WriteHeader(http.StatusOK)
+
+	b, _ := json.MarshalIndent(result, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+	_, _ = w.Write(b)
+	session.ResponseLength += len(b)
+
+	if ui.IsActive(ui.RestLogger) {
+		ui.WriteLog(ui.RestLogger, "rest.response.payload", ui.A{
+			"session": session.ID,
+			"body":    string(b)})
+	}
+
+	return http.StatusOK
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/server/audit"
 	"github.com/tucats/ego/server/auth"
 	"github.com/tucats/ego/server/server"
 	"github.com/tucats/ego/symbols"
@@ -46,6 +47,12 @@ func CreateUserHandler(session *server.Session, w http.ResponseWriter, r *http.R
 	// Call the SetUser function, passing in the structure that contains the User information.
 	if _, err := auth.SetUser(s, data.NewList(args)); err == nil {
 		if u, err := auth.AuthService.ReadUser(userInfo.Name, false); err == nil {
+			audit.Record("user.create", ui.A{
+				"session":     session.ID,
+				"user":        session.User,
+				"name":        u.Name,
+				"permissions": u.Permissions})
+
 			w.Header().Add(defs.ContentTypeHeader, defs.UserMediaType)
 			w.WriteHeader(http.StatusOK)
 
@@ -70,6 +77,11 @@ func CreateUserHandler(session *server.Session, w http.ResponseWriter, r *http.R
 			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
 		}
 	} else {
-		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if auth.IsPasswordPolicyError(err) {
+			status = http.StatusBadRequest
+		}
+
+		return util.ErrorResponse(w, session.ID, err.Error(), status)
 	}
 }
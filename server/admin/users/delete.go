@@ -8,6 +8,7 @@ import (
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/server/audit"
 	"github.com/tucats/ego/server/auth"
 	"github.com/tucats/ego/server/server"
 	"github.com/tucats/ego/symbols"
@@ -40,6 +41,11 @@ func DeleteUserHandler(session *server.Session, w http.ResponseWriter, r *http.R
 		return util.ErrorResponse(w, session.ID, msg, http.StatusNotFound)
 	}
 
+	audit.Record("user.delete", ui.A{
+		"session": session.ID,
+		"user":    session.User,
+		"name":    u.Name})
+
 	// Write the deleted user record back to the caller.
 	w.Header().Add(defs.ContentTypeHeader, defs.UserMediaType)
 
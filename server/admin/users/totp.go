@@ -0,0 +1,79 @@
+package users
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/server/auth"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/util"
+)
+
+// EnrollTOTPHandler is the handler for the POST method on the one-time code endpoint
+// for a user. The user is enrolled for one-time codes, and the response contains the
+// provisioning URI for an authenticator app and the recovery codes for the user.
+func EnrollTOTPHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	name := data.String(session.URLParts["name"])
+
+	if _, err := auth.AuthService.ReadUser(name, false); err != nil {
+		msg := fmt.Sprintf("No username entry for '%s'", name)
+
+		return util.ErrorResponse(w, session.ID, msg, http.StatusNotFound)
+	}
+
+	uri, codes, err := auth.EnrollTOTP(name)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	return writeEnrollment(session, w, defs.TOTPEnrollment{
+		Name:     name,
+		URI:      uri,
+		Recovery: codes,
+	})
+}
+
+// ResetTOTPHandler is the handler for the DELETE method on the one-time code endpoint
+// for a user. The enrollment for one-time codes is removed, so the user can log in with
+// only a password.
+func ResetTOTPHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	name := data.String(session.URLParts["name"])
+
+	if _, err := auth.AuthService.ReadUser(name, false); err != nil {
+		msg := fmt.Sprintf("No username entry for '%s'", name)
+
+		return util.ErrorResponse(w, session.ID, msg, http.StatusNotFound)
+	}
+
+	if err := auth.ResetTOTP(name); err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	return writeEnrollment(session, w, defs.TOTPEnrollment{Name: name})
+}
+
+// writeEnrollment writes the response for a change to the enrollment of a user for
+// one-time codes.
+func writeEnrollment(session *server.Session, w http.ResponseWriter, reply defs.TOTPEnrollment) int {
+	reply.ServerInfo = util.MakeServerInfo(session.ID)
+	reply.Status = http.StatusOK
+
+	w.Header().Add(defs.ContentTypeHeader, defs.TOTPMediaType)
+	w.WriteHeader(http.StatusOK)
+
+	b, _ := json.MarshalIndent(reply, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+	_, _ = w.Write(b)
+	session.ResponseLength += len(b)
+
+	if ui.IsActive(ui.RestLogger) {
+		ui.WriteLog(ui.RestLogger, "rest.response.payload", ui.A{
+			"session": session.ID,
+			"body":    string(b)})
+	}
+
+	return http.StatusOK
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/server/audit"
 	"github.com/tucats/ego/server/auth"
 	"github.com/tucats/ego/server/server"
 	"github.com/tucats/ego/util"
@@ -33,7 +34,15 @@ func UpdateUserHandler(session *server.Session, w http.ResponseWriter, r *http.R
 		}
 
 		if newUser.Password != "" {
-			u.Password = newUser.Password
+			if err := auth.SetPassword(&u, newUser.Password); err != nil {
+				status := http.StatusInternalServerError
+				if auth.IsPasswordPolicyError(err) {
+					status = http.StatusBadRequest
+				}
+
+				return util.ErrorResponse(w, session.ID, err.Error(), status)
+			}
+
 			changed = true
 		}
 
@@ -77,6 +86,13 @@ func UpdateUserHandler(session *server.Session, w http.ResponseWriter, r *http.R
 			if err := auth.AuthService.WriteUser(u); err != nil {
 				return util.ErrorResponse(w, session.ID, "error updating "+name+", "+err.Error(), http.StatusNotFound)
 			}
+
+			audit.Record("user.update", ui.A{
+				"session":     session.ID,
+				"user":        session.User,
+				"name":        u.Name,
+				"password":    newUser.Password != "",
+				"permissions": u.Permissions})
 		}
 
 		// Write the updated user info back to the caller. We do not return the
@@ -0,0 +1,250 @@
+// Package audit maintains the audit log of security-relevant actions taken by the
+// server, such as logons, changes to users and permissions, and SQL statements. The
+// audit log is an append-only file with one JSON entry per line. Each entry includes
+// the hash of the previous entry, so an entry that is changed or removed can be
+// detected. Each entry is also written to the AUDIT logger, if it is active.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+)
+
+// The name of the audit log file when the path is not given in the configuration.
+const defaultAuditFileName = "ego-server-audit.json"
+
+var (
+	// The open audit log file, if any. Entries are only written to the file once
+	// it has been opened by the server.
+	auditFile *os.File
+
+	// The path of the audit log file.
+	auditPath string
+
+	// The sequence number and hash of the last entry written.
+	sequence int
+	lastHash string
+
+	// auditMutex serializes writing entries, so the sequence numbers and hash
+	// chain are preserved.
+	auditMutex sync.Mutex
+)
+
+// Open opens the audit log file, creating it if needed. If the path is empty, the
+// default file name is used in the same directory as the server log. Entries that
+// are already in the file are read to continue the sequence numbers and hash chain.
+func Open(path string) error {
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	if path == "" {
+		path = filepath.Join(filepath.Dir(ui.CurrentLogFile()), defaultAuditFileName)
+	}
+
+	path, _ = filepath.Abs(path)
+
+	entries, verified, broken, err := readEntries(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.New(err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if auditFile != nil {
+		_ = auditFile.Close()
+	}
+
+	auditFile = file
+	auditPath = path
+	sequence = 0
+	lastHash = ""
+
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		sequence = last.Sequence
+		lastHash = last.Hash
+	}
+
+	ui.Log(ui.ServerLogger, "server.audit.file", ui.A{
+		"path":  path,
+		"count": len(entries)})
+
+	if !verified {
+		ui.WriteLog(ui.ServerLogger, "server.audit.broken", ui.A{
+			"path": path,
+			"seq":  broken})
+	}
+
+	return nil
+}
+
+// Close closes the audit log file. Entries are no longer written to the file.
+func Close() {
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	if auditFile != nil {
+		_ = auditFile.Close()
+		auditFile = nil
+	}
+}
+
+// Record records an action in the audit log. The event describes the kind of action,
+// and the arguments describe the action. The "user" argument is the name of the user
+// performing the action, and the "session" argument is the server session number of
+// the request. The entry is also written to the AUDIT logger, using the message with
+// the event name prefixed by "audit.".
+func Record(event string, args ui.A) {
+	ui.Log(ui.AuditLogger, "audit."+event, args)
+
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	if auditFile == nil {
+		return
+	}
+
+	entry := defs.AuditEntry{
+		Time:     time.Now().Format(time.RFC3339),
+		Sequence: sequence + 1,
+		Event:    event,
+		Previous: lastHash,
+	}
+
+	for key, value := range args {
+		switch key {
+		case "user":
+			entry.User = data.String(value)
+
+		case "session":
+			entry.Session, _ = data.Int(value)
+
+		default:
+			if entry.Args == nil {
+				entry.Args = map[string]interface{}{}
+			}
+
+			// Errors are stored as their text, since they have no JSON representation.
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+
+			entry.Args[key] = value
+		}
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		ui.WriteLog(ui.ServerLogger, "server.audit.error", ui.A{
+			"error": err})
+
+		return
+	}
+
+	entry.Hash = entryHash(b)
+
+	b, _ = json.Marshal(entry)
+	if _, err := auditFile.Write(append(b, '\n')); err != nil {
+		ui.WriteLog(ui.ServerLogger, "server.audit.error", ui.A{
+			"error": err})
+
+		return
+	}
+
+	sequence = entry.Sequence
+	lastHash = entry.Hash
+}
+
+// record is the form of an entry used to verify its hash. The arguments are kept in
+// the form they were written, so the hash does not depend on how the values of the
+// arguments are read.
+type record struct {
+	Time     string          `json:"time"`
+	Sequence int             `json:"seq"`
+	Event    string          `json:"event"`
+	Session  int             `json:"session,omitempty"`
+	User     string          `json:"user,omitempty"`
+	Args     json.RawMessage `json:"args,omitempty"`
+	Previous string          `json:"prev"`
+	Hash     string          `json:"hash"`
+}
+
+// readEntries reads the entries in an audit log file, and verifies the hash chain.
+// If the chain is broken, the sequence number of the first entry that is not valid
+// is also returned.
+func readEntries(path string) ([]defs.AuditEntry, bool, int, error) {
+	entries := []defs.AuditEntry{}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return entries, true, 0, err
+	}
+
+	defer file.Close()
+
+	var (
+		verified = true
+		broken   int
+		previous string
+	)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		entry := defs.AuditEntry{}
+		r := record{}
+
+		if json.Unmarshal(line, &entry) != nil || json.Unmarshal(line, &r) != nil {
+			if verified {
+				verified = false
+				broken = len(entries) + 1
+			}
+
+			continue
+		}
+
+		if verified {
+			hash := r.Hash
+			r.Hash = ""
+
+			b, _ := json.Marshal(r)
+			if r.Previous != previous || entryHash(b) != hash || entry.Sequence != len(entries)+1 {
+				verified = false
+				broken = entry.Sequence
+			}
+		}
+
+		previous = entry.Hash
+		entries = append(entries, entry)
+	}
+
+	return entries, verified, broken, scanner.Err()
+}
+
+// entryHash returns the hash of the JSON text of an entry, which already includes
+// the hash of the previous entry.
+func entryHash(b []byte) string {
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tucats/ego/app-cli/ui"
+)
+
+func TestRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.json")
+
+	if err := Open(path); err != nil {
+		t.Fatal(err)
+	}
+
+	Record("logon.success", ui.A{"user": "staff"})
+	Record("user.update", ui.A{"session": 12, "user": "admin", "name": "staff", "permissions": []string{"logon"}})
+	Record("logon.password", ui.A{"user": "bogus"})
+
+	// Reopening the file continues the sequence and the hash chain.
+	Close()
+
+	if err := Open(path); err != nil {
+		t.Fatal(err)
+	}
+
+	defer Close()
+
+	Record("sql.execute", ui.A{"session": 13, "user": "staff", "statements": []string{"select 1 <> 2"}})
+
+	tests := []struct {
+		name   string
+		filter Filter
+		seqs   []int
+	}{
+		{"all entries", Filter{}, []int{1, 2, 3, 4}},
+		{"event prefix", Filter{Event: "logon"}, []int{1, 3}},
+		{"event", Filter{Event: "logon.password"}, []int{3}},
+		{"partial event", Filter{Event: "log"}, nil},
+		{"user", Filter{User: "staff"}, []int{1, 4}},
+		{"session", Filter{Session: 12}, []int{2}},
+		{"limit", Filter{Limit: 2}, []int{3, 4}},
+		{"since", Filter{Since: time.Now().Add(time.Hour)}, nil},
+		{"until", Filter{Until: time.Now().Add(time.Hour)}, []int{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Query(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !result.Verified || len(result.Items) != len(tt.seqs) {
+				t.Fatalf("Query() = %v", result)
+			}
+
+			for i, entry := range result.Items {
+				if entry.Sequence != tt.seqs[i] {
+					t.Errorf("Query() entry %d has sequence %d, want %d", i, entry.Sequence, tt.seqs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.json")
+
+	if err := Open(path); err != nil {
+		t.Fatal(err)
+	}
+
+	defer Close()
+
+	Record("user.create", ui.A{"user": "admin", "name": "staff", "permissions": []string{"logon"}})
+	Record("user.update", ui.A{"user": "admin", "name": "staff", "permissions": []string{"logon", "root"}})
+	Record("cache.purge", ui.A{"user": "admin"})
+
+	b, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+
+	tests := []struct {
+		name   string
+		lines  []string
+		broken int
+	}{
+		{"changed entry", []string{lines[0], strings.Replace(lines[1], `"root"`, `"logon"`, 1), lines[2]}, 2},
+		{"removed entry", []string{lines[0], lines[2]}, 3},
+		{"reordered entries", []string{lines[1], lines[0], lines[2]}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(path, []byte(strings.Join(tt.lines, "\n")+"\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := Query(Filter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Verified || result.Broken != tt.broken {
+				t.Errorf("Query() verified %v, broken at %d, want %d", result.Verified, result.Broken, tt.broken)
+			}
+		})
+	}
+}
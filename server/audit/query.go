@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tucats/ego/defs"
+)
+
+// Filter selects the entries returned by a query of the audit log. Fields that are
+// not set do not filter the entries.
+type Filter struct {
+	// Only entries whose event is this event, or starts with this event followed
+	// by a period, such as "logon" for "logon.success".
+	Event string
+
+	// Only entries for actions performed by this user.
+	User string
+
+	// Only entries for this server session number.
+	Session int
+
+	// Only entries at or after this time.
+	Since time.Time
+
+	// Only entries at or before this time.
+	Until time.Time
+
+	// The maximum number of entries, which are the most recent matching entries.
+	Limit int
+}
+
+// Query reads the audit log and returns the entries that match the filter, in the
+// order they were written. The result also indicates if the hash chain of the
+// entire log is intact and, if not, the sequence number of the first entry that is
+// not valid.
+func Query(filter Filter) (defs.AuditCollection, error) {
+	auditMutex.Lock()
+	path := auditPath
+	auditMutex.Unlock()
+
+	result := defs.AuditCollection{Verified: true, Items: []defs.AuditEntry{}}
+	if path == "" {
+		return result, nil
+	}
+
+	entries, verified, broken, err := readEntries(path)
+	if err != nil {
+		return result, err
+	}
+
+	result.Verified = verified
+	result.Broken = broken
+
+	for _, entry := range entries {
+		if filter.matches(entry) {
+			result.Items = append(result.Items, entry)
+		}
+	}
+
+	if filter.Limit > 0 && len(result.Items) > filter.Limit {
+		result.Items = result.Items[len(result.Items)-filter.Limit:]
+	}
+
+	result.Count = len(result.Items)
+
+	return result, nil
+}
+
+// matches reports if an entry is selected by the filter.
+func (f Filter) matches(entry defs.AuditEntry) bool {
+	if f.Event != "" && entry.Event != f.Event && !strings.HasPrefix(entry.Event, f.Event+".") {
+		return false
+	}
+
+	if f.User != "" && entry.User != f.User {
+		return false
+	}
+
+	if f.Session != 0 && entry.Session != f.Session {
+		return false
+	}
+
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		t, err := time.Parse(time.RFC3339, entry.Time)
+		if err != nil || (!f.Since.IsZero() && t.Before(f.Since)) || (!f.Until.IsZero() && t.After(f.Until)) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/audit"
+	"github.com/tucats/ego/util"
+)
+
+// The prefix of the text of every API key. The text of a key is the prefix, the
+// key ID as 32 hexadecimal digits, an underscore, and the secret part of the key.
+const apiKeyPrefix = "ego_"
+
+// CreateAPIKey creates a new API key for a user, described by the user name,
+// description, permissions, expiration and allowed addresses in the key. Each
+// permission must be granted to the user, unless the user has root privileges.
+// The expiration can be a duration such as "720h" or an RFC 3339 time. The text
+// of the new key is returned along with the key; only the hash of the key is
+// stored, so the text cannot be retrieved again.
+func CreateAPIKey(key defs.APIKey) (string, defs.APIKey, error) {
+	now := time.Now()
+
+	if _, err := AuthService.ReadUser(key.User, false); err != nil {
+		return "", key, err
+	}
+
+	if len(key.Permissions) == 0 {
+		return "", key, errors.ErrInvalidRequest.Context("permissions")
+	}
+
+	isRoot := GetPermission(key.User, "root")
+
+	for i, permission := range key.Permissions {
+		key.Permissions[i] = strings.ToLower(strings.TrimSpace(permission))
+
+		if !isRoot && !GetPermission(key.User, key.Permissions[i]) {
+			return "", key, errors.ErrNoPermission.Context(permission)
+		}
+	}
+
+	if key.Expires != "" {
+		if duration, err := util.ParseDuration(key.Expires); err == nil {
+			key.Expires = now.Add(duration).Format(time.RFC3339)
+		} else if expires, err := time.Parse(time.RFC3339, key.Expires); err == nil {
+			key.Expires = expires.Format(time.RFC3339)
+		} else {
+			return "", key, errors.ErrInvalidRequest.Context(key.Expires)
+		}
+	}
+
+	for _, address := range key.Allow {
+		if _, _, err := net.ParseCIDR(address); err != nil && net.ParseIP(address) == nil {
+			return "", key, errors.ErrInvalidRequest.Context(address)
+		}
+	}
+
+	secret := randomString()
+
+	key.ID = uuid.New()
+	key.Hash = hashRefresh(secret)
+	key.Created = now.Format(time.RFC3339)
+
+	if err := AuthService.WriteAPIKey(key); err != nil {
+		return "", key, err
+	}
+
+	audit.Record("apikey.create", ui.A{
+		"id":   key.ID,
+		"user": key.User})
+
+	key.Hash = ""
+
+	return apiKeyPrefix + strings.ReplaceAll(key.ID.String(), "-", "") + "_" + secret, key, nil
+}
+
+// APIKeys returns the API keys, sorted by the time they were created. If a user
+// name is given, only the keys for that user are returned.
+func APIKeys(user string) []defs.APIKey {
+	result := []defs.APIKey{}
+
+	for _, key := range AuthService.ListAPIKeys() {
+		if user != "" && key.User != user {
+			continue
+		}
+
+		key.Hash = ""
+		result = append(result, key)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Created < result[j].Created
+	})
+
+	return result
+}
+
+// RevokeAPIKey deletes the API key with the given ID, so it can no longer be used.
+// The revoked key is returned.
+func RevokeAPIKey(id string) (defs.APIKey, error) {
+	key, err := AuthService.ReadAPIKey(id)
+	if err != nil {
+		return key, err
+	}
+
+	if err = AuthService.DeleteAPIKey(key.ID.String()); err != nil {
+		return key, err
+	}
+
+	audit.Record("apikey.revoke", ui.A{
+		"id":   key.ID,
+		"user": key.User})
+
+	key.Hash = ""
+
+	return key, nil
+}
+
+// ValidateAPIKey checks the text of an API key used by a request from the given
+// network address. If the key is valid, it is returned with only the permissions
+// that are still granted to the user.
+func ValidateAPIKey(text, address string) (defs.APIKey, bool) {
+	// The key text must have the prefix, the ID, an underscore and the secret.
+	idLength := len(apiKeyPrefix) + 32
+	if !strings.HasPrefix(text, apiKeyPrefix) || len(text) <= idLength+1 || text[idLength] != '_' {
+		return defs.APIKey{}, false
+	}
+
+	id, err := uuid.Parse(text[len(apiKeyPrefix):idLength])
+	if err != nil {
+		return defs.APIKey{}, false
+	}
+
+	key, err := AuthService.ReadAPIKey(id.String())
+	if err != nil || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashRefresh(text[idLength+1:]))) != 1 {
+		ui.Log(ui.AuthLogger, "auth.apikey.invalid", ui.A{
+			"id": id})
+
+		return key, false
+	}
+
+	if key.Expires != "" && sessionExpired(key.Expires, time.Now()) {
+		ui.Log(ui.AuthLogger, "auth.apikey.expired", ui.A{
+			"id":   id,
+			"user": key.User})
+
+		return key, false
+	}
+
+	if !addressAllowed(key.Allow, address) {
+		ui.Log(ui.AuthLogger, "auth.apikey.address", ui.A{
+			"id":      id,
+			"user":    key.User,
+			"address": address})
+
+		return key, false
+	}
+
+	if _, err := AuthService.ReadUser(key.User, false); err != nil {
+		return key, false
+	}
+
+	// A user who can no longer log in cannot use their keys either.
+	isRoot := GetPermission(key.User, "root")
+	if !isRoot && !GetPermission(key.User, "logon") {
+		ui.Log(ui.AuthLogger, "auth.apikey.logon", ui.A{
+			"id":   id,
+			"user": key.User})
+
+		return key, false
+	}
+
+	// The permissions of the user may have changed since the key was created.
+	permissions := []string{}
+
+	for _, permission := range key.Permissions {
+		if isRoot || GetPermission(key.User, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	key.Hash = ""
+	key.Permissions = permissions
+
+	return key, true
+}
+
+// addressAllowed reports if a network address, which may include a port number, is
+// one of the allowed addresses or within one of the allowed CIDR ranges. If there
+// are no allowed addresses, every address is allowed.
+func addressAllowed(allow []string, address string) bool {
+	if len(allow) == 0 {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, entry := range allow {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/util"
+)
+
+// readAPIKeys reads the API keys from the file stored alongside the user data
+// file. It is not an error if the file does not exist yet.
+func (f *fileService) readAPIKeys() error {
+	ext := filepath.Ext(f.path)
+	f.apiKeysPath = strings.TrimSuffix(f.path, ext) + "_apikeys" + ext
+
+	b, err := os.ReadFile(f.apiKeysPath)
+	if err != nil {
+		return nil
+	}
+
+	if key := settings.Get(defs.LogonUserdataKeySetting); key != "" {
+		r, err := util.Decrypt(string(b), key)
+		if err != nil {
+			return err
+		}
+
+		b = []byte(r)
+	}
+
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &f.apiKeys); err != nil {
+			return errors.New(err)
+		}
+	}
+
+	ui.Log(ui.AuthLogger, "auth.apikeys.file", ui.A{
+		"count": len(f.apiKeys)})
+
+	return nil
+}
+
+// writeAPIKeys writes the API keys to their file. Like the sessions, the keys are
+// written as soon as they change so a revoked key is never accepted after a restart.
+// The caller must hold the lock for the service.
+func (f *fileService) writeAPIKeys() error {
+	if f.apiKeysPath == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(f.apiKeys, "", "   ")
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if key := settings.Get(defs.LogonUserdataKeySetting); key != "" {
+		r, err := util.Encrypt(string(b), key)
+		if err != nil {
+			return err
+		}
+
+		b = []byte(r)
+	}
+
+	if err := os.WriteFile(f.apiKeysPath, b, 0600); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// ListAPIKeys returns a map of all API keys in the database, keyed by the key ID.
+func (f *fileService) ListAPIKeys() map[string]defs.APIKey {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	result := make(map[string]defs.APIKey, len(f.apiKeys))
+	for id, key := range f.apiKeys {
+		result[id] = key
+	}
+
+	return result
+}
+
+// ReadAPIKey returns an API key from the database.
+func (f *fileService) ReadAPIKey(id string) (defs.APIKey, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key, ok := f.apiKeys[id]
+	if !ok {
+		return key, errors.ErrNoSuchAPIKey.Context(id)
+	}
+
+	return key, nil
+}
+
+// WriteAPIKey adds or updates an API key in the database.
+func (f *fileService) WriteAPIKey(key defs.APIKey) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.apiKeys[key.ID.String()] = key
+
+	return f.writeAPIKeys()
+}
+
+// DeleteAPIKey removes an API key from the database.
+func (f *fileService) DeleteAPIKey(id string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, found := f.apiKeys[id]; !found {
+		return errors.ErrNoSuchAPIKey.Context(id)
+	}
+
+	delete(f.apiKeys, id)
+
+	return f.writeAPIKeys()
+}
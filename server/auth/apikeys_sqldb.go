@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/resources"
+)
+
+// openAPIKeys opens the resource handle for the API keys table, and creates the
+// table if it does not yet exist.
+func (pg *databaseService) openAPIKeys(connStr string) error {
+	var err error
+
+	if pg.apiKeyHandle, err = resources.Open(defs.APIKey{}, "apikeys", connStr); err != nil {
+		return err
+	}
+
+	return pg.apiKeyHandle.CreateIf()
+}
+
+// ListAPIKeys returns a map of all API keys in the database, keyed by the key ID.
+func (pg *databaseService) ListAPIKeys() map[string]defs.APIKey {
+	r := map[string]defs.APIKey{}
+
+	rowSet, err := pg.apiKeyHandle.Begin().Read()
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return r
+	}
+
+	for _, row := range rowSet {
+		key := row.(*defs.APIKey)
+		r[key.ID.String()] = *key
+	}
+
+	return r
+}
+
+// ReadAPIKey returns an API key from the database.
+func (pg *databaseService) ReadAPIKey(id string) (defs.APIKey, error) {
+	rowSet, err := pg.apiKeyHandle.Begin().Read(pg.apiKeyHandle.Equals("id", id))
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return defs.APIKey{}, errors.New(err)
+	}
+
+	if len(rowSet) == 0 {
+		return defs.APIKey{}, errors.ErrNoSuchAPIKey.Context(id)
+	}
+
+	return *rowSet[0].(*defs.APIKey), nil
+}
+
+// WriteAPIKey adds or updates an API key in the database.
+func (pg *databaseService) WriteAPIKey(key defs.APIKey) error {
+	var err error
+
+	if _, err = pg.ReadAPIKey(key.ID.String()); err == nil {
+		err = pg.apiKeyHandle.Begin().Update(key, pg.apiKeyHandle.Equals("id", key.ID.String()))
+	} else {
+		err = pg.apiKeyHandle.Begin().Insert(key)
+	}
+
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// DeleteAPIKey removes an API key from the database.
+func (pg *databaseService) DeleteAPIKey(id string) error {
+	count, err := pg.apiKeyHandle.Begin().Delete(pg.apiKeyHandle.Equals("id", id))
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return errors.New(err)
+	}
+
+	if count == 0 {
+		return errors.ErrNoSuchAPIKey.Context(id)
+	}
+
+	return nil
+}
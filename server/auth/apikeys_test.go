@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/symbols"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	tests := []struct {
+		name string
+		key  defs.APIKey
+		err  error
+	}{
+		{"unknown user", defs.APIKey{User: "nobody", Permissions: []string{"logon"}}, errors.ErrNoSuchUser},
+		{"no permissions", defs.APIKey{User: "staff"}, errors.ErrInvalidRequest},
+		{"permission not granted", defs.APIKey{User: "staff", Permissions: []string{"employees"}}, errors.ErrNoPermission},
+		{"invalid expiration", defs.APIKey{User: "staff", Permissions: []string{"tables"}, Expires: "soon"}, errors.ErrInvalidRequest},
+		{"invalid address", defs.APIKey{User: "staff", Permissions: []string{"tables"}, Allow: []string{"10.1.1"}}, errors.ErrInvalidRequest},
+		{"root user", defs.APIKey{User: "payroll", Permissions: []string{"anything"}}, nil},
+		{"valid key", defs.APIKey{User: "staff", Permissions: []string{"Tables"}, Expires: "24h", Allow: []string{"10.0.0.0/8"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, key, err := CreateAPIKey(tt.key)
+			if (tt.err == nil && err != nil) || (tt.err != nil && !errors.Equals(err, tt.err)) {
+				t.Fatalf("CreateAPIKey() = %v, want %v", err, tt.err)
+			}
+
+			if tt.err == nil && (!strings.HasPrefix(text, apiKeyPrefix) || key.Hash != "") {
+				t.Errorf("CreateAPIKey() = %s, %v", text, key)
+			}
+		})
+	}
+
+	if keys := APIKeys("staff"); len(keys) != 1 || keys[0].Permissions[0] != "tables" || keys[0].Expires == "" {
+		t.Errorf("APIKeys() = %v", keys)
+	}
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	text, created, err := CreateAPIKey(defs.APIKey{
+		User:        "staff",
+		Permissions: []string{"logon", "tables"},
+		Allow:       []string{"10.0.0.0/8", "192.168.1.5"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		address string
+		valid   bool
+	}{
+		{"valid key", text, "10.1.2.3:8080", true},
+		{"allowed address", text, "192.168.1.5", true},
+		{"address not allowed", text, "192.168.1.6:8080", false},
+		{"wrong secret", text[:len(text)-1] + "x", "10.1.2.3", false},
+		{"no prefix", strings.TrimPrefix(text, apiKeyPrefix), "10.1.2.3", false},
+		{"not a key", "ego_short", "10.1.2.3", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, valid := ValidateAPIKey(tt.text, tt.address); valid != tt.valid {
+				t.Errorf("ValidateAPIKey() = %v, want %v", valid, tt.valid)
+			}
+		})
+	}
+
+	// Permissions no longer granted to the user are not used by the key.
+	u, _ := AuthService.ReadUser("staff", false)
+	u.Permissions = []string{"logon"}
+	_ = AuthService.WriteUser(u)
+
+	if key, _ := ValidateAPIKey(text, "10.1.2.3"); len(key.Permissions) != 1 || key.Permissions[0] != "logon" {
+		t.Errorf("ValidateAPIKey() permissions = %v", key.Permissions)
+	}
+
+	if _, err := RevokeAPIKey(created.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, valid := ValidateAPIKey(text, "10.1.2.3"); valid {
+		t.Error("ValidateAPIKey() accepted a revoked key")
+	}
+
+	if _, err := RevokeAPIKey(created.ID.String()); !errors.Equals(err, errors.ErrNoSuchAPIKey) {
+		t.Errorf("RevokeAPIKey() of revoked key = %v", err)
+	}
+
+	// An expired key cannot be used.
+	text, _, _ = CreateAPIKey(defs.APIKey{User: "staff", Permissions: []string{"logon"}, Expires: "2000-01-01T00:00:00Z"})
+	if _, valid := ValidateAPIKey(text, "10.1.2.3"); valid {
+		t.Error("ValidateAPIKey() accepted an expired key")
+	}
+
+	// A key cannot be used once the user can no longer log in.
+	text, _, _ = CreateAPIKey(defs.APIKey{User: "staff", Permissions: []string{"logon"}})
+	u.Permissions = []string{"tables"}
+	_ = AuthService.WriteUser(u)
+
+	if _, valid := ValidateAPIKey(text, "10.1.2.3"); valid {
+		t.Error("ValidateAPIKey() accepted a key for a user without logon permission")
+	}
+}
+
+func TestPermissionAPIKeyScope(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	s := symbols.NewSymbolTable("service")
+	s.SetAlways("_user", "payroll")
+
+	permission := func(user, priv string) bool {
+		result, err := Permission(s, data.NewList(user, priv))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return data.BoolOrFalse(result)
+	}
+
+	if !permission("payroll", "root") {
+		t.Fatal("Permission() = false without an API key")
+	}
+
+	// The user of a request authenticated with an API key only has the permissions
+	// of the key, even if the user has root privileges. Other users are not affected.
+	s.SetAlways(defs.PermissionsVariable, []string{"checks"})
+
+	if permission("payroll", "root") || !permission("payroll", "checks") {
+		t.Error("Permission() did not use the permissions of the API key")
+	}
+
+	if !permission("staff", "tables") {
+		t.Error("Permission() used the API key for another user")
+	}
+}
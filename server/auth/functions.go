@@ -10,8 +10,11 @@ import (
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/jwt"
 	"github.com/tucats/ego/runtime"
+	"github.com/tucats/ego/runtime/cipher"
 	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
 )
 
 // Authenticated implements the authenticated(user,pass) function. This function is only
@@ -58,6 +61,16 @@ func Permission(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 	user = data.String(args.Get(0))
 	priv = strings.ToUpper(data.String(args.Get(1)))
 
+	// If the request was authenticated with an API key, the user of the request only
+	// has the permissions of the key.
+	if scope, found := s.Get(defs.PermissionsVariable); found {
+		if current, ok := s.Get("_user"); ok && data.String(current) == user {
+			if permissions, ok := scope.([]string); !ok || !util.InList(strings.ToLower(priv), permissions...) {
+				return false, nil
+			}
+		}
+	}
+
 	// If the user exists and the privilege exists, return it's status
 	return GetPermission(user, priv), nil
 }
@@ -105,7 +118,9 @@ func SetUser(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 		}
 
 		if n, ok, _ := u.Get("password"); ok {
-			r.Password = HashString(data.String(n))
+			if err := SetPassword(&r, data.String(n)); err != nil {
+				return false, err
+			}
 		}
 
 		if n, ok, _ := u.Get("permissions"); ok {
@@ -205,6 +220,12 @@ func GetUser(s *symbols.SymbolTable, args data.List) (interface{}, error) {
 // associated with the token. If the token is invalid or expired, an empty string
 // is returned.
 func TokenUser(t string) string {
+	if jwt.IsJWT(t) && OIDCEnabled() && !cipher.IsServerJWT(t) {
+		user, _ := OIDCTokenUser(t)
+
+		return user
+	}
+
 	// Are we an authority? If not, let's see who is.
 	authServer := settings.Get(defs.ServerAuthoritySetting)
 	if authServer != "" {
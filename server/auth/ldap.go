@@ -0,0 +1,297 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/caches"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/util"
+)
+
+// How long a user read from the directory is cached when the setting is not given.
+const defaultLDAPCacheDuration = 5 * time.Minute
+
+// ldapService is a user service that reads users from an LDAP directory. A user's
+// password is checked by binding to the directory as the user, and the user's
+// permissions are mapped from the groups the user is a member of. Users read from
+// the directory are cached, and removed from the cache by the credential aging
+// mechanism. Local users, and the roles, groups, sessions, password state and API
+// keys, are kept in a file service.
+type ldapService struct {
+	userIOService
+
+	// The URL of the directory server. The path of the URL is the base object
+	// of the search for users.
+	url *url.URL
+
+	lock  sync.Mutex
+	cache map[string]ldapUser
+}
+
+// ldapUser is a user read from the directory, along with the distinguished name
+// used to bind as the user.
+type ldapUser struct {
+	user defs.User
+	dn   string
+}
+
+// NewLDAPService creates a new user service that reads users from the LDAP directory
+// in an "ldap://" or "ldaps://" URL, such as "ldaps://ldap.example.com/dc=example,dc=com".
+// The default user and password are used to create a local user if the local store
+// has no users.
+func NewLDAPService(connStr, defaultUser, defaultPassword string) (userIOService, error) {
+	u, err := url.Parse(connStr)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	local, err := NewFileService(settings.Get(defs.ServerLDAPStoreSetting), defaultUser, defaultPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &ldapService{
+		userIOService: local,
+		url:           u,
+		cache:         map[string]ldapUser{},
+	}
+
+	ui.Log(ui.AuthLogger, "auth.ldap.service", ui.A{
+		"host": u.Host,
+		"base": svc.base()})
+
+	// Start the aging mechanism that removes cached users when they expire.
+	go ageCredentials()
+
+	return svc, nil
+}
+
+// ReadUser returns a user definition. A local user is returned if there is one. Otherwise
+// the cached user from the directory is returned, or the user is read from the directory
+// if it is not cached or the cached copy has expired.
+func (s *ldapService) ReadUser(name string, doNotLog bool) (defs.User, error) {
+	if user, err := s.userIOService.ReadUser(name, true); err == nil {
+		return user, nil
+	}
+
+	agingMutex.Lock()
+	expires, found := aging[name]
+	agingMutex.Unlock()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if cached, ok := s.cache[name]; ok && found && time.Now().Before(expires) {
+		return cached.user, nil
+	}
+
+	entry, err := s.lookup(name)
+	if err != nil {
+		return defs.User{}, err
+	}
+
+	user := defs.User{
+		Name:        name,
+		ID:          uuid.NewSHA1(uuid.NameSpaceX500, []byte(entry.DN)),
+		Permissions: mapPermissions(settings.Get(defs.ServerLDAPPermissionsSetting), ldapGroups(entry)),
+	}
+
+	s.cache[name] = ldapUser{user: user, dn: entry.DN}
+
+	// The groups of the user in the directory may have changed since the permissions
+	// of the user were cached.
+	caches.Delete(caches.PermissionCache, name)
+
+	duration, err := util.ParseDuration(settings.Get(defs.ServerLDAPCacheSetting))
+	if err != nil || duration <= 0 {
+		duration = defaultLDAPCacheDuration
+	}
+
+	agingMutex.Lock()
+	aging[name] = time.Now().Add(duration)
+	agingMutex.Unlock()
+
+	if !doNotLog {
+		ui.Log(ui.AuthLogger, "auth.ldap.user", ui.A{
+			"user":        name,
+			"dn":          entry.DN,
+			"permissions": strings.Join(user.Permissions, ",")})
+	}
+
+	return user, nil
+}
+
+// WriteUser updates the cached copy of a user read from the directory, or adds or
+// updates a local user. Changes to a user from the directory are discarded when the
+// user is read from the directory again.
+func (s *ldapService) WriteUser(user defs.User) error {
+	s.lock.Lock()
+
+	if cached, found := s.cache[user.Name]; found {
+		cached.user = user
+		s.cache[user.Name] = cached
+		s.lock.Unlock()
+
+		caches.Delete(caches.PermissionCache, user.Name)
+
+		return nil
+	}
+
+	s.lock.Unlock()
+
+	return s.userIOService.WriteUser(user)
+}
+
+// DeleteUser removes a user read from the directory from the cache, or deletes a
+// local user. Users are never deleted from the directory.
+func (s *ldapService) DeleteUser(name string) error {
+	s.lock.Lock()
+
+	if _, found := s.cache[name]; found {
+		delete(s.cache, name)
+		s.lock.Unlock()
+
+		caches.Delete(caches.PermissionCache, name)
+
+		return nil
+	}
+
+	s.lock.Unlock()
+
+	return s.userIOService.DeleteUser(name)
+}
+
+// ListUsers returns the local users and the users currently cached from the directory.
+func (s *ldapService) ListUsers() map[string]defs.User {
+	result := map[string]defs.User{}
+
+	for name, user := range s.userIOService.ListUsers() {
+		result[name] = user
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for name, cached := range s.cache {
+		result[name] = cached.user
+	}
+
+	return result
+}
+
+// authenticate checks the password of a user read from the directory by binding to
+// the directory as the user. The first result is false if the user is not from the
+// directory, so the password is checked as for a local user.
+func (s *ldapService) authenticate(name, password string) (bool, bool) {
+	s.lock.Lock()
+	cached, found := s.cache[name]
+	s.lock.Unlock()
+
+	if !found {
+		return false, false
+	}
+
+	// An empty password is an anonymous bind, which must not be accepted as a logon.
+	if password == "" {
+		return true, false
+	}
+
+	conn, err := dialLDAP(s.url)
+	if err != nil {
+		ui.Log(ui.AuthLogger, "auth.error", ui.A{
+			"error": err})
+
+		return true, false
+	}
+
+	defer conn.close()
+
+	ok, err := conn.bind(cached.dn, password)
+	if err != nil {
+		ui.Log(ui.AuthLogger, "auth.error", ui.A{
+			"error": err})
+	}
+
+	return true, ok
+}
+
+// lookup searches the directory for the entry of a user. The search uses the bind
+// distinguished name and password in the configuration, or an anonymous bind.
+func (s *ldapService) lookup(name string) (ldapEntry, error) {
+	conn, err := dialLDAP(s.url)
+	if err != nil {
+		return ldapEntry{}, err
+	}
+
+	defer conn.close()
+
+	if dn := settings.Get(defs.ServerLDAPBindDNSetting); dn != "" {
+		ok, err := conn.bind(dn, settings.Get(defs.ServerLDAPBindPasswordSetting))
+		if err != nil {
+			return ldapEntry{}, err
+		}
+
+		if !ok {
+			return ldapEntry{}, errors.ErrLDAP.Context(dn)
+		}
+	}
+
+	attribute := settings.Get(defs.ServerLDAPUserAttributeSetting)
+	if attribute == "" {
+		attribute = "uid"
+	}
+
+	entries, err := conn.search(s.base(), attribute, name, []string{groupAttribute()})
+	if err != nil {
+		return ldapEntry{}, err
+	}
+
+	if len(entries) != 1 {
+		return ldapEntry{}, errors.ErrNoSuchUser.Context(name)
+	}
+
+	return entries[0], nil
+}
+
+// base returns the base object of the search for users, from the path of the URL.
+func (s *ldapService) base() string {
+	base, err := url.PathUnescape(strings.TrimPrefix(s.url.Path, "/"))
+	if err != nil {
+		return strings.TrimPrefix(s.url.Path, "/")
+	}
+
+	return base
+}
+
+// ldapGroups returns the common names of the groups a directory entry is a member
+// of. Each group is a distinguished name, such as "cn=admins,ou=groups,dc=example".
+func ldapGroups(entry ldapEntry) []string {
+	result := []string{}
+
+	for _, dn := range entry.Attributes[strings.ToLower(groupAttribute())] {
+		rdn := strings.SplitN(dn, ",", 2)[0]
+		if parts := strings.SplitN(rdn, "=", 2); len(parts) == 2 {
+			result = append(result, strings.TrimSpace(parts[1]))
+		} else {
+			result = append(result, strings.TrimSpace(rdn))
+		}
+	}
+
+	return result
+}
+
+// groupAttribute returns the directory attribute that lists the groups of a user.
+func groupAttribute() string {
+	if attribute := settings.Get(defs.ServerLDAPGroupAttributeSetting); attribute != "" {
+		return attribute
+	}
+
+	return "memberOf"
+}
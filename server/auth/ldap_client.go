@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+)
+
+// How long to wait for the directory server to respond.
+const ldapTimeout = 10 * time.Second
+
+// ldapConn is a connection to an LDAP directory server.
+type ldapConn struct {
+	conn *ldap.Conn
+}
+
+// ldapEntry is an entry returned by a search of the directory. The attribute names
+// are in lower case.
+type ldapEntry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// dialLDAP connects to the directory server in an "ldap://" or "ldaps://" URL. The
+// default ports are 389 and 636.
+func dialLDAP(u *url.URL) (*ldapConn, error) {
+	options := []ldap.DialOpt{ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout})}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "ldaps" {
+		config, err := ldapTLSConfig(u.Hostname())
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, ldap.DialWithTLSConfig(config))
+	}
+
+	conn, err := ldap.DialURL(scheme+"://"+u.Host, options...)
+	if err != nil {
+		return nil, errors.ErrLDAP.Context(err.Error())
+	}
+
+	conn.SetTimeout(ldapTimeout)
+
+	return &ldapConn{conn: conn}, nil
+}
+
+// ldapTLSConfig returns the TLS configuration used to connect to a directory server.
+// The certificate of the server is verified unless the LDAP TLS verify setting is
+// "false". If the LDAP certificate authority setting names a PEM file, only the
+// certificate authorities in the file are trusted.
+func ldapTLSConfig(host string) (*tls.Config, error) {
+	config := &tls.Config{ServerName: host}
+
+	if verify := settings.Get(defs.ServerLDAPTLSVerifySetting); verify != "" && !settings.GetBool(defs.ServerLDAPTLSVerifySetting) {
+		config.InsecureSkipVerify = true
+	}
+
+	if filename := settings.Get(defs.ServerLDAPTLSCASetting); filename != "" {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, errors.New(err)
+		}
+
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(b) {
+			return nil, errors.ErrCertificateParseError.Context(filename)
+		}
+
+		config.RootCAs = roots
+	}
+
+	return config, nil
+}
+
+// close sends an unbind request and closes the connection.
+func (c *ldapConn) close() {
+	if err := c.conn.Unbind(); err != nil {
+		_ = c.conn.Close()
+	}
+}
+
+// bind authenticates the connection using a distinguished name and password. The
+// result is false if the credentials are not valid.
+func (c *ldapConn) bind(dn, password string) (bool, error) {
+	err := c.conn.Bind(dn, password)
+	if err == nil {
+		return true, nil
+	}
+
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return false, nil
+	}
+
+	return false, errors.ErrLDAP.Context(err.Error())
+}
+
+// search finds the entries below the base object whose attribute is equal to the
+// value, and returns the requested attributes of each entry.
+func (c *ldapConn) search(base, attribute, value string, attributes []string) ([]ldapEntry, error) {
+	request := ldap.NewSearchRequest(base,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, int(ldapTimeout/time.Second), false,
+		fmt.Sprintf("(%s=%s)", attribute, ldap.EscapeFilter(value)),
+		attributes, nil)
+
+	response, err := c.conn.Search(request)
+	if err != nil {
+		return nil, errors.ErrLDAP.Context(err.Error())
+	}
+
+	entries := make([]ldapEntry, 0, len(response.Entries))
+
+	for _, e := range response.Entries {
+		entry := ldapEntry{DN: e.DN, Attributes: map[string][]string{}}
+
+		for _, a := range e.Attributes {
+			name := strings.ToLower(a.Name)
+			entry.Attributes[name] = append(entry.Attributes[name], a.Values...)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
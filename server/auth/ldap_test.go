@@ -0,0 +1,368 @@
+package auth
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/defs"
+)
+
+// testDirectory is a small stand-in for an LDAP directory server. It supports simple
+// binds, equality searches and unbinds, which is all the LDAP user service uses.
+type testDirectory struct {
+	listener  net.Listener
+	lock      sync.Mutex
+	passwords map[string]string
+	users     map[string]ldapEntry
+	searches  int
+}
+
+func newTestDirectory(t *testing.T) *testDirectory {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &testDirectory{
+		listener: listener,
+		passwords: map[string]string{
+			"cn=search,dc=example,dc=com":          "searchpw",
+			"uid=jdoe,ou=people,dc=example,dc=com": "secret",
+			"uid=anne,ou=people,dc=example,dc=com": "hunter2",
+		},
+		users: map[string]ldapEntry{
+			"jdoe": {DN: "uid=jdoe,ou=people,dc=example,dc=com", Attributes: map[string][]string{
+				"memberof": {"cn=developers,ou=groups,dc=example,dc=com"}}},
+			"anne": {DN: "uid=anne,ou=people,dc=example,dc=com", Attributes: map[string][]string{
+				"memberof": {"cn=admins,ou=groups,dc=example,dc=com", "cn=developers,ou=groups,dc=example,dc=com"}}},
+		},
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go d.serve(conn)
+		}
+	}()
+
+	settings.SetDefault(defs.ServerLDAPBindDNSetting, "cn=search,dc=example,dc=com")
+	settings.SetDefault(defs.ServerLDAPBindPasswordSetting, "searchpw")
+	settings.SetDefault(defs.ServerLDAPPermissionsSetting, "*=logon; developers=tables; admins=root")
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+
+		settings.SetDefault(defs.ServerLDAPBindDNSetting, "")
+		settings.SetDefault(defs.ServerLDAPBindPasswordSetting, "")
+		settings.SetDefault(defs.ServerLDAPPermissionsSetting, "")
+	})
+
+	return d
+}
+
+// url returns the URL of the directory, with the base object of the user search.
+func (d *testDirectory) url() string {
+	return "ldap://" + d.listener.Addr().String() + "/dc=example,dc=com"
+}
+
+func (d *testDirectory) serve(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		message, err := ber.ReadPacket(conn)
+		if err != nil || len(message.Children) < 2 {
+			return
+		}
+
+		id := message.Children[0].Value
+		reply := func(op *ber.Packet) {
+			response := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+			response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+			response.AppendChild(op)
+
+			_, _ = conn.Write(response.Bytes())
+		}
+
+		op := message.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			code := ldap.LDAPResultInvalidCredentials
+
+			d.lock.Lock()
+			if password, found := d.passwords[op.Children[1].Data.String()]; found && password == op.Children[2].Data.String() {
+				code = ldap.LDAPResultSuccess
+			}
+			d.lock.Unlock()
+
+			reply(testResult(ldap.ApplicationBindResponse, code))
+
+		case ldap.ApplicationSearchRequest:
+			filter := op.Children[6]
+
+			d.lock.Lock()
+			d.searches++
+			entry, found := d.users[filter.Children[1].Data.String()]
+			d.lock.Unlock()
+
+			if found && filter.Children[0].Data.String() == "uid" {
+				groups := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+				for _, group := range entry.Attributes["memberof"] {
+					groups.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, group, ""))
+				}
+
+				attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+				attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "memberOf", ""))
+				attribute.AppendChild(groups)
+
+				attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+				attributes.AppendChild(attribute)
+
+				result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+				result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, ""))
+				result.AppendChild(attributes)
+
+				reply(result)
+			}
+
+			reply(testResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+// testResult returns an LDAP result message with the given tag and result code.
+func testResult(tag ber.Tag, code int) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, ""))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+
+	return result
+}
+
+func setupTestLDAPService(t *testing.T, d *testDirectory) {
+	var err error
+
+	savedAuthService = AuthService
+
+	AuthService, err = defineCredentialService(d.url(), "admin", "password")
+	if err != nil {
+		t.Fatalf("Failed to create LDAP service: %v", err)
+	}
+
+	if _, ok := AuthService.(*ldapService); !ok {
+		t.Fatalf("defineCredentialService() did not create an LDAP service for %s", d.url())
+	}
+
+	t.Cleanup(func() {
+		AuthService = savedAuthService
+	})
+}
+
+func TestIsLDAPURL(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"ldap://ldap.example.com/dc=example,dc=com", true},
+		{"LDAPS://ldap.example.com", true},
+		{"postgres://localhost/ego", false},
+		{"users.json", false},
+	}
+
+	for _, tt := range tests {
+		if got := isLDAPURL(tt.path); got != tt.want {
+			t.Errorf("isLDAPURL(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLDAPService_ValidatePassword(t *testing.T) {
+	d := newTestDirectory(t)
+	setupTestLDAPService(t, d)
+
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		want     bool
+	}{
+		{"valid directory user", "jdoe", "secret", true},
+		{"wrong password", "jdoe", "guess", false},
+		{"empty password", "anne", "", false},
+		{"unknown user", "nobody", "secret", false},
+		{"wildcard user", "*", "secret", false},
+		{"local default user", "admin", "password", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidatePassword(tt.user, tt.password); got != tt.want {
+				t.Errorf("ValidatePassword(%q) = %v, want %v", tt.user, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLDAPService_Permissions(t *testing.T) {
+	d := newTestDirectory(t)
+	setupTestLDAPService(t, d)
+
+	user, err := AuthService.ReadUser("anne", true)
+	if err != nil {
+		t.Fatalf("ReadUser() error = %v", err)
+	}
+
+	if strings.Join(user.Permissions, ",") != "logon,tables,root" {
+		t.Errorf("ReadUser() permissions = %v", user.Permissions)
+	}
+
+	if !GetPermission("anne", "root") || GetPermission("jdoe", "root") {
+		t.Errorf("GetPermission() did not use the directory groups")
+	}
+}
+
+func TestLDAPService_Cache(t *testing.T) {
+	d := newTestDirectory(t)
+	setupTestLDAPService(t, d)
+
+	for i := 0; i < 3; i++ {
+		if _, err := AuthService.ReadUser("jdoe", true); err != nil {
+			t.Fatalf("ReadUser() error = %v", err)
+		}
+	}
+
+	if d.searches != 1 {
+		t.Errorf("ReadUser() searched the directory %d times, want 1", d.searches)
+	}
+
+	if _, found := AuthService.ListUsers()["jdoe"]; !found {
+		t.Errorf("ListUsers() does not include the cached directory user")
+	}
+
+	// Expire the cached user, as the credential aging mechanism would.
+	agingMutex.Lock()
+	aging["jdoe"] = time.Now().Add(-time.Second)
+	agingMutex.Unlock()
+
+	if _, err := AuthService.ReadUser("jdoe", true); err != nil {
+		t.Fatalf("ReadUser() error = %v", err)
+	}
+
+	if d.searches != 2 {
+		t.Errorf("ReadUser() did not read the expired user from the directory again")
+	}
+
+	// Deleting a directory user only removes it from the cache.
+	if err := AuthService.DeleteUser("jdoe"); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	if !ValidatePassword("jdoe", "secret") {
+		t.Errorf("ValidatePassword() failed after the cached user was removed")
+	}
+}
+
+func TestLDAPTLSConfig(t *testing.T) {
+	defer settings.SetDefault(defs.ServerLDAPTLSVerifySetting, "")
+	defer settings.SetDefault(defs.ServerLDAPTLSCASetting, "")
+
+	// The certificate is verified unless verification is turned off. The setting
+	// that serves HTTP instead of HTTPS does not change this.
+	settings.SetDefault(defs.InsecureServerSetting, "true")
+	defer settings.SetDefault(defs.InsecureServerSetting, "false")
+
+	for _, tt := range []struct {
+		verify string
+		skip   bool
+	}{
+		{"", false},
+		{"true", false},
+		{"false", true},
+	} {
+		settings.SetDefault(defs.ServerLDAPTLSVerifySetting, tt.verify)
+
+		config, err := ldapTLSConfig("ldap.example.com")
+		if err != nil || config.InsecureSkipVerify != tt.skip || config.ServerName != "ldap.example.com" {
+			t.Errorf("ldapTLSConfig() with verify %q = %v, %v", tt.verify, config, err)
+		}
+	}
+
+	// A certificate authority file that cannot be parsed is an error.
+	filename := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(filename, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	settings.SetDefault(defs.ServerLDAPTLSCASetting, filename)
+
+	if _, err := ldapTLSConfig("ldap.example.com"); err == nil {
+		t.Error("ldapTLSConfig() accepted an invalid certificate authority file")
+	}
+}
+
+func TestLDAPService_PermissionCache(t *testing.T) {
+	d := newTestDirectory(t)
+	setupTestLDAPService(t, d)
+
+	if !GetPermission("anne", "root") {
+		t.Fatalf("GetPermission() did not use the directory groups")
+	}
+
+	// Changing the cached directory user changes the permissions of the user.
+	user, err := AuthService.ReadUser("anne", true)
+	if err != nil {
+		t.Fatalf("ReadUser() error = %v", err)
+	}
+
+	user.Permissions = []string{"logon"}
+	if err := AuthService.WriteUser(user); err != nil {
+		t.Fatalf("WriteUser() error = %v", err)
+	}
+
+	if GetPermission("anne", "root") {
+		t.Errorf("GetPermission() used the permissions from before WriteUser()")
+	}
+
+	// Deleting the cached user reads the user from the directory again.
+	if err := AuthService.DeleteUser("anne"); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	if !GetPermission("anne", "root") {
+		t.Errorf("GetPermission() used the permissions from before DeleteUser()")
+	}
+
+	// A user removed from a group in the directory loses its permissions when the
+	// cached user expires and is read from the directory again.
+	d.lock.Lock()
+	d.users["anne"].Attributes["memberof"] = []string{"cn=developers,ou=groups,dc=example,dc=com"}
+	d.lock.Unlock()
+
+	agingMutex.Lock()
+	aging["anne"] = time.Now().Add(-time.Second)
+	agingMutex.Unlock()
+
+	if _, err := AuthService.ReadUser("anne", true); err != nil {
+		t.Fatalf("ReadUser() error = %v", err)
+	}
+
+	if GetPermission("anne", "root") {
+		t.Errorf("GetPermission() used the permissions from before the directory was read")
+	}
+}
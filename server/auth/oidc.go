@@ -0,0 +1,506 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/caches"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/jwt"
+	"github.com/tucats/ego/util"
+)
+
+// oidcProvider is the configuration of an OpenID Connect identity provider, as
+// read from its discovery document, along with the signing keys it publishes.
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	configured            string
+	keys                  jwt.KeySet
+	keysFetched           time.Time
+}
+
+// oidcLogin is a login that has been started but not yet completed. It is found
+// using the state value passed through the identity provider.
+type oidcLogin struct {
+	verifier string
+	nonce    string
+	redirect string
+	expires  time.Time
+}
+
+// oidcTokenResponse is the response from the token endpoint of the provider.
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+const (
+	// How long a user has to complete a login at the identity provider.
+	oidcLoginTimeout = 10 * time.Minute
+
+	// The allowed difference between our clock and the identity provider clock.
+	oidcClockSkew = time.Minute
+
+	// The minimum time between reloading the provider keys when a token is signed
+	// with a key that we don't know about.
+	oidcKeyRefresh = 30 * time.Second
+
+	// The prefix of the value stored as the password of a user created by a login
+	// with the identity provider.
+	oidcPasswordPrefix = "$oidc$"
+)
+
+var (
+	oidcMutex  sync.Mutex
+	provider   *oidcProvider
+	oidcLogins = map[string]oidcLogin{}
+	oidcClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// OIDCEnabled reports if the server is configured to log in users with an
+// OpenID Connect identity provider.
+func OIDCEnabled() bool {
+	return settings.Get(defs.ServerOIDCIssuerSetting) != "" && settings.Get(defs.ServerOIDCClientSetting) != ""
+}
+
+// OIDCLoginURL starts a login using the identity provider. It returns the URL of
+// the provider's authorization endpoint that the user must be sent to. The request
+// uses the authorization code flow with PKCE, and the verifier and nonce for the
+// request are saved until the provider redirects back to the given redirect URL.
+func OIDCLoginURL(redirect string) (string, error) {
+	p, err := getProvider()
+	if err != nil {
+		return "", err
+	}
+
+	login := oidcLogin{
+		verifier: randomString(),
+		nonce:    randomString(),
+		redirect: redirect,
+		expires:  time.Now().Add(oidcLoginTimeout),
+	}
+
+	state := randomString()
+
+	oidcMutex.Lock()
+
+	for key, item := range oidcLogins {
+		if time.Now().After(item.expires) {
+			delete(oidcLogins, key)
+		}
+	}
+
+	oidcLogins[state] = login
+
+	oidcMutex.Unlock()
+
+	scopes := settings.Get(defs.ServerOIDCScopesSetting)
+	if scopes == "" {
+		scopes = "openid profile email"
+	}
+
+	challenge := sha256.Sum256([]byte(login.verifier))
+
+	parameters := url.Values{}
+	parameters.Set("response_type", "code")
+	parameters.Set("client_id", settings.Get(defs.ServerOIDCClientSetting))
+	parameters.Set("redirect_uri", redirect)
+	parameters.Set("scope", scopes)
+	parameters.Set("state", state)
+	parameters.Set("nonce", login.nonce)
+	parameters.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	parameters.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return p.AuthorizationEndpoint + separator + parameters.Encode(), nil
+}
+
+// OIDCLogin completes a login using the identity provider. The authorization code
+// returned by the provider is exchanged for an identity token, which is validated
+// and used to find or create the Ego user. The user name is returned.
+func OIDCLogin(code, state string) (string, error) {
+	oidcMutex.Lock()
+	login, found := oidcLogins[state]
+	delete(oidcLogins, state)
+	oidcMutex.Unlock()
+
+	if !found || time.Now().After(login.expires) {
+		return "", errors.ErrOIDCState
+	}
+
+	p, err := getProvider()
+	if err != nil {
+		return "", err
+	}
+
+	parameters := url.Values{}
+	parameters.Set("grant_type", "authorization_code")
+	parameters.Set("code", code)
+	parameters.Set("redirect_uri", login.redirect)
+	parameters.Set("client_id", settings.Get(defs.ServerOIDCClientSetting))
+	parameters.Set("code_verifier", login.verifier)
+
+	if secret := settings.Get(defs.ServerOIDCSecretSetting); secret != "" {
+		parameters.Set("client_secret", secret)
+	}
+
+	resp, err := oidcClient.PostForm(p.TokenEndpoint, parameters)
+	if err != nil {
+		return "", errors.ErrOIDCProvider.Context(err.Error())
+	}
+
+	defer resp.Body.Close()
+
+	tokens := oidcTokenResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil || resp.StatusCode != http.StatusOK {
+		reason := tokens.ErrorDescription
+		if reason == "" {
+			reason = tokens.Error
+		}
+
+		if reason == "" {
+			reason = resp.Status
+		}
+
+		return "", errors.ErrOIDCProvider.Context(reason)
+	}
+
+	token, err := validateProviderToken(p, tokens.IDToken)
+	if err != nil {
+		return "", err
+	}
+
+	if token.Claims.String("nonce") != login.nonce {
+		return "", errors.ErrJWTClaim.Context("nonce")
+	}
+
+	return oidcUser(token.Claims, true)
+}
+
+// OIDCTokenUser validates a token issued by the identity provider that is used as a
+// bearer token, and returns the Ego user name for the token. The token must have been
+// issued to this server, so it's audience must include the client ID. The user must
+// have logged in with the identity provider, and has the permissions mapped from the
+// provider groups when it last logged in.
+func OIDCTokenUser(text string) (string, error) {
+	p, err := getProvider()
+	if err != nil {
+		return "", err
+	}
+
+	token, err := validateProviderToken(p, text)
+	if err != nil {
+		return "", err
+	}
+
+	return oidcUser(token.Claims, false)
+}
+
+// validateProviderToken parses a token issued by the identity provider, verifies the
+// signature using the provider's published keys, and validates the standard claims.
+func validateProviderToken(p *oidcProvider, text string) (*jwt.Token, error) {
+	token, err := jwt.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := p.key(token.Header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = token.Verify(key); err == nil {
+		err = token.Validate(p.Issuer, settings.Get(defs.ServerOIDCClientSetting), oidcClockSkew)
+	}
+
+	if err != nil {
+		ui.Log(ui.AuthLogger, "auth.oidc.invalid", ui.A{
+			"error": err})
+
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// oidcUser maps the claims of a validated identity token to an Ego user. A local user
+// that was not created by a login from the same identity provider subject is never
+// used, so a provider user cannot take over a local account by choosing its name.
+//
+// When the user logs in, the user is created if it does not exist, without a usable
+// password, so it can only be used with the identity provider. The permissions mapped
+// from the provider groups are stored apart from the permissions granted to the user,
+// and replaced on each login, so removing a user from a group removes the permissions
+// it granted without removing other grants. A token used as a bearer token does not
+// change the user.
+func oidcUser(claims jwt.Claims, login bool) (string, error) {
+	name := userClaim(claims)
+	identity := oidcIdentity(claims)
+
+	if name == "" || identity == "" {
+		return "", errors.ErrOIDCUser
+	}
+
+	user, err := AuthService.ReadUser(name, true)
+	if err == nil && subtle.ConstantTimeCompare([]byte(user.Password), []byte(identity)) != 1 {
+		ui.Log(ui.AuthLogger, "auth.oidc.local.user", ui.A{
+			"user": name})
+
+		return "", errors.ErrOIDCLocalUser.Context(name)
+	}
+
+	if !login {
+		if err != nil {
+			return "", err
+		}
+
+		return name, nil
+	}
+
+	if err != nil {
+		user = defs.User{
+			Name:     name,
+			ID:       uuid.New(),
+			Password: identity,
+		}
+
+		if err := AuthService.WriteUser(user); err != nil {
+			return "", err
+		}
+
+		if err := AuthService.Flush(); err != nil {
+			return "", err
+		}
+	}
+
+	groupsClaim := settings.Get(defs.ServerOIDCGroupsClaimSetting)
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+
+	permissions := []string{"logon"}
+
+	for _, permission := range mapPermissions(settings.Get(defs.ServerOIDCPermissionsSetting), claims.Strings(groupsClaim)) {
+		if !util.InList(permission, permissions...) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	if err := setProviderPermissions(name, permissions); err != nil {
+		return "", err
+	}
+
+	ui.Log(ui.AuthLogger, "auth.oidc.user", ui.A{
+		"user":        name,
+		"permissions": strings.Join(permissions, ",")})
+
+	return name, nil
+}
+
+// setProviderPermissions stores the permissions mapped from the identity provider
+// groups of a user, if they have changed since the user last logged in.
+func setProviderPermissions(name string, permissions []string) error {
+	passwordMutex.Lock()
+	defer passwordMutex.Unlock()
+
+	state, err := AuthService.ReadPasswordState(name)
+	if err != nil {
+		return err
+	}
+
+	if strings.Join(state.Provider, ",") == strings.Join(permissions, ",") {
+		return nil
+	}
+
+	state.Provider = permissions
+
+	if err := AuthService.WritePasswordState(state); err != nil {
+		return err
+	}
+
+	caches.Delete(caches.PermissionCache, name)
+
+	return nil
+}
+
+// userClaim returns the Ego user name from the claims in a token. This is the "sub"
+// claim, unless another claim is configured.
+func userClaim(claims jwt.Claims) string {
+	name := settings.Get(defs.ServerOIDCUserClaimSetting)
+	if name == "" {
+		name = "sub"
+	}
+
+	return strings.ToLower(claims.String(name))
+}
+
+// oidcIdentity returns the value stored as the password of a user created by a login
+// with the identity provider. It is derived from the issuer and subject of the token,
+// which together identify the user at the provider, and is not a valid password hash
+// so the user cannot log in with a password.
+func oidcIdentity(claims jwt.Claims) string {
+	subject := claims.String("sub")
+	if subject == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(claims.String("iss") + "\n" + subject))
+
+	return oidcPasswordPrefix + hex.EncodeToString(sum[:])
+}
+
+// mapPermissions returns the Ego permissions for a list of identity provider or
+// directory groups, using a permission mapping setting. The mapping is a list of
+// entries separated by semicolons, each of the form "group=permission,permission".
+// A group of "*" applies to all users.
+func mapPermissions(mapping string, groups []string) []string {
+	result := []string{}
+
+	for _, entry := range strings.Split(mapping, ";") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		group, permissions := strings.TrimSpace(parts[0]), parts[1]
+		match := group == "*"
+
+		for _, name := range groups {
+			if name == group {
+				match = true
+
+				break
+			}
+		}
+
+		if !match {
+			continue
+		}
+
+		for _, permission := range strings.Split(permissions, ",") {
+			if permission = strings.ToLower(strings.TrimSpace(permission)); permission != "" {
+				result = append(result, permission)
+			}
+		}
+	}
+
+	return result
+}
+
+// getProvider returns the configuration of the identity provider, reading the
+// discovery document from the issuer the first time it is needed, or if the
+// issuer setting has changed.
+func getProvider() (*oidcProvider, error) {
+	if !OIDCEnabled() {
+		return nil, errors.ErrOIDCNotConfigured
+	}
+
+	issuer := strings.TrimSuffix(settings.Get(defs.ServerOIDCIssuerSetting), "/")
+
+	oidcMutex.Lock()
+	defer oidcMutex.Unlock()
+
+	if provider != nil && provider.configured == issuer {
+		return provider, nil
+	}
+
+	p := &oidcProvider{}
+	if err := getJSON(issuer+"/.well-known/openid-configuration", p); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(p.Issuer, "/") != issuer || p.AuthorizationEndpoint == "" || p.TokenEndpoint == "" || p.JWKSURI == "" {
+		return nil, errors.ErrOIDCProvider.Context(issuer)
+	}
+
+	p.configured = issuer
+
+	ui.Log(ui.AuthLogger, "auth.oidc.provider", ui.A{
+		"issuer": issuer})
+
+	provider = p
+
+	return provider, nil
+}
+
+// key returns the public key from the provider with the given key ID. If the key is
+// not known, the provider keys are read again in case they were rotated.
+func (p *oidcProvider) key(id string) (crypto.PublicKey, error) {
+	oidcMutex.Lock()
+	defer oidcMutex.Unlock()
+
+	key, found := p.keys.Find(id)
+	if !found && time.Since(p.keysFetched) > oidcKeyRefresh {
+		keys := jwt.KeySet{}
+		if err := getJSON(p.JWKSURI, &keys); err != nil {
+			return nil, err
+		}
+
+		p.keys = keys
+		p.keysFetched = time.Now()
+
+		ui.Log(ui.AuthLogger, "auth.oidc.keys", ui.A{
+			"count": len(keys.Keys)})
+
+		key, found = p.keys.Find(id)
+	}
+
+	if !found {
+		return nil, errors.ErrJWTKey.Context(id)
+	}
+
+	return key.PublicKey()
+}
+
+// getJSON reads a JSON document from the identity provider.
+func getJSON(url string, item interface{}) error {
+	resp, err := oidcClient.Get(url)
+	if err != nil {
+		return errors.ErrOIDCProvider.Context(err.Error())
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.ErrOIDCProvider.Context(url + ": " + resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(item); err != nil {
+		return errors.ErrOIDCProvider.Context(err.Error())
+	}
+
+	return nil
+}
+
+// randomString returns a random URL-safe string, used for the PKCE verifier, the
+// nonce and the state of a login.
+func randomString() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
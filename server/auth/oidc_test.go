@@ -0,0 +1,409 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/builtins"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/jwt"
+	"github.com/tucats/ego/runtime"
+	"github.com/tucats/ego/symbols"
+)
+
+// testIdP is a small stand-in for an OpenID Connect identity provider. It supports
+// discovery, the JWKS endpoint, and the authorization code flow with PKCE. Every
+// login is for the user and groups in the provider.
+type testIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	user   string
+	groups []string
+	codes  map[string]url.Values
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp := &testIdP{key: key, user: "Alice", groups: []string{"developers"}, codes: map[string]url.Values{}}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		jwk, _ := jwt.NewKey("test", "RS256", &key.PublicKey)
+		_ = json.NewEncoder(w).Encode(jwt.KeySet{Keys: []jwt.Key{jwk}})
+	})
+
+	// The user is always logged in, so the provider redirects right back with a code.
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+
+			return
+		}
+
+		code := randomString()
+		idp.codes[code] = q
+
+		http.Redirect(w, r, q.Get("redirect_uri")+"?code="+code+"&state="+q.Get("state"), http.StatusFound)
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		request, found := idp.codes[r.PostForm.Get("code")]
+		delete(idp.codes, r.PostForm.Get("code"))
+
+		challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if !found || base64.RawURLEncoding.EncodeToString(challenge[:]) != request.Get("code_challenge") ||
+			r.PostForm.Get("redirect_uri") != request.Get("redirect_uri") {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"token_type": "Bearer",
+			"id_token": idp.token(t, jwt.Claims{
+				"aud":   request.Get("client_id"),
+				"nonce": request.Get("nonce"),
+			}),
+		})
+	})
+
+	idp.server = httptest.NewServer(mux)
+
+	return idp
+}
+
+// token returns an identity token signed by the provider for the provider's user,
+// with the standard claims added to the given claims.
+func (idp *testIdP) token(t *testing.T, claims jwt.Claims) string {
+	defaults := jwt.Claims{
+		"iss":                idp.server.URL,
+		"sub":                "1234",
+		"aud":                "ego",
+		"preferred_username": idp.user,
+		"groups":             idp.groups,
+		"iat":                time.Now().Unix(),
+		"exp":                time.Now().Add(time.Hour).Unix(),
+	}
+
+	for name, value := range claims {
+		defaults[name] = value
+	}
+
+	text, err := jwt.Sign("RS256", "test", defaults, idp.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return text
+}
+
+// login validates an identity token from the provider for the provider's user, with
+// the standard claims added to the given claims, and logs the user in.
+func (idp *testIdP) login(t *testing.T, claims jwt.Claims) (string, error) {
+	p, err := getProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := validateProviderToken(p, idp.token(t, claims))
+	if err != nil {
+		return "", err
+	}
+
+	return oidcUser(token.Claims, true)
+}
+
+// setupTestIdP starts a stand-in identity provider and configures the server to use
+// it. The returned function stops the provider and resets the configuration.
+func setupTestIdP(t *testing.T) (*testIdP, func()) {
+	idp := newTestIdP(t)
+
+	settings.SetDefault(defs.ServerOIDCIssuerSetting, idp.server.URL)
+	settings.SetDefault(defs.ServerOIDCClientSetting, "ego")
+	settings.SetDefault(defs.ServerOIDCPermissionsSetting, "developers=tables, table_read; admins=root")
+	settings.SetDefault(defs.ServerOIDCUserClaimSetting, "preferred_username")
+
+	provider = nil
+
+	return idp, func() {
+		idp.server.Close()
+
+		provider = nil
+
+		settings.SetDefault(defs.ServerOIDCIssuerSetting, "")
+		settings.SetDefault(defs.ServerOIDCClientSetting, "")
+		settings.SetDefault(defs.ServerOIDCPermissionsSetting, "")
+		settings.SetDefault(defs.ServerOIDCUserClaimSetting, "")
+	}
+}
+
+func TestOIDCLogin(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	_, stop := setupTestIdP(t)
+	defer stop()
+
+	location, err := OIDCLoginURL("http://localhost/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Follow the redirect to the provider, which redirects back to the callback URL.
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := client.Get(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || callback.Path != "/callback" {
+		t.Fatalf("authorize redirect = %v, %v", resp.Header.Get("Location"), err)
+	}
+
+	code, state := callback.Query().Get("code"), callback.Query().Get("state")
+
+	user, err := OIDCLogin(code, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user != "alice" {
+		t.Errorf("OIDCLogin() user = %s", user)
+	}
+
+	if !GetPermission("alice", "logon") || !GetPermission("alice", "table_read") || GetPermission("alice", "root") {
+		t.Error("OIDCLogin() did not map the permissions")
+	}
+
+	// The user has no password that can be used to log in.
+	if ValidatePassword("alice", "") {
+		t.Error("ValidatePassword() succeeded for a provider user")
+	}
+
+	// A login state can only be used once.
+	if _, err := OIDCLogin(code, state); !errors.Equals(err, errors.ErrOIDCState) {
+		t.Errorf("OIDCLogin() with used state = %v", err)
+	}
+}
+
+func TestOIDCLogin_BadVerifier(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	idp, stop := setupTestIdP(t)
+	defer stop()
+
+	location, err := OIDCLoginURL("http://localhost/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(location)
+	state := u.Query().Get("state")
+
+	// Change the verifier for the login, so it does not match the challenge the
+	// provider was given.
+	oidcMutex.Lock()
+	login := oidcLogins[state]
+	login.verifier = randomString()
+	oidcLogins[state] = login
+	oidcMutex.Unlock()
+
+	idp.codes["forged"] = u.Query()
+
+	if _, err := OIDCLogin("forged", state); !errors.Equals(err, errors.ErrOIDCProvider) {
+		t.Errorf("OIDCLogin() with wrong verifier = %v", err)
+	}
+}
+
+func TestOIDCTokenUser(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	idp, stop := setupTestIdP(t)
+	defer stop()
+
+	idp.user = "Bob"
+	idp.groups = []string{"admins"}
+
+	// The token can only be used by a user that has logged in with the provider.
+	token := idp.token(t, nil)
+	if ValidateToken(token) || TokenUser(token) != "" {
+		t.Error("ValidateToken() accepted the provider token before the user logged in")
+	}
+
+	if _, err := idp.login(t, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !ValidateToken(token) || TokenUser(token) != "bob" || !GetPermission("bob", "root") {
+		t.Error("ValidateToken() did not accept the provider token")
+	}
+
+	tests := []struct {
+		name   string
+		claims jwt.Claims
+		err    error
+	}{
+		{"expired", jwt.Claims{"exp": time.Now().Add(-time.Hour).Unix()}, errors.ErrExpiredToken},
+		{"audience", jwt.Claims{"aud": "other"}, errors.ErrJWTClaim},
+		{"issuer", jwt.Claims{"iss": "https://evil.example.com"}, errors.ErrJWTClaim},
+		{"no user", jwt.Claims{"preferred_username": "", "sub": ""}, errors.ErrOIDCUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := OIDCTokenUser(idp.token(t, tt.claims)); !errors.Equals(err, tt.err) {
+				t.Errorf("OIDCTokenUser() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	// A token signed by another key is not accepted.
+	other := newTestIdP(t)
+	defer other.server.Close()
+
+	if ValidateToken(other.token(t, jwt.Claims{"iss": idp.server.URL})) {
+		t.Error("ValidateToken() accepted a token with an unknown signature")
+	}
+}
+
+func TestOIDCUser(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	idp, stop := setupTestIdP(t)
+	defer stop()
+
+	// Without a configured claim, the user is named by the subject, which the
+	// user cannot choose.
+	settings.SetDefault(defs.ServerOIDCUserClaimSetting, "")
+
+	idp.user = "bogus"
+	idp.groups = []string{"admins"}
+
+	if name, err := idp.login(t, jwt.Claims{"sub": "U-5678"}); err != nil || name != "u-5678" {
+		t.Errorf("oidcUser() = %v, %v, want subject", name, err)
+	}
+
+	// A provider user cannot log in as a local user with the same name.
+	settings.SetDefault(defs.ServerOIDCUserClaimSetting, "preferred_username")
+
+	if _, err := idp.login(t, nil); !errors.Equals(err, errors.ErrOIDCLocalUser) {
+		t.Errorf("oidcUser() for local user = %v, want %v", err, errors.ErrOIDCLocalUser)
+	}
+
+	if GetPermission("bogus", "root") || GetPermission("bogus", "logon") {
+		t.Error("oidcUser() changed the permissions of the local user")
+	}
+
+	// Nor can another subject at the provider use the name of a provider user.
+	idp.user = "carol"
+
+	if _, err := idp.login(t, nil); err != nil || !GetPermission("carol", "root") {
+		t.Fatalf("oidcUser() = %v", err)
+	}
+
+	if _, err := idp.login(t, jwt.Claims{"sub": "9999"}); !errors.Equals(err, errors.ErrOIDCLocalUser) {
+		t.Errorf("oidcUser() for another subject = %v, want %v", err, errors.ErrOIDCLocalUser)
+	}
+
+	if _, err := OIDCTokenUser(idp.token(t, jwt.Claims{"sub": "9999"})); !errors.Equals(err, errors.ErrOIDCLocalUser) {
+		t.Errorf("OIDCTokenUser() for another subject = %v, want %v", err, errors.ErrOIDCLocalUser)
+	}
+
+	// Permissions granted to the user are kept apart from the provider groups.
+	user, err := AuthService.ReadUser("carol", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user.Permissions = []string{"admin_secrets"}
+	if err := AuthService.WriteUser(user); err != nil {
+		t.Fatal(err)
+	}
+
+	// A token used as a bearer token does not change the permissions of the user.
+	idp.groups = []string{"developers"}
+
+	if _, err := OIDCTokenUser(idp.token(t, nil)); err != nil || !GetPermission("carol", "root") {
+		t.Errorf("OIDCTokenUser() = %v, changed the permissions", err)
+	}
+
+	// Removing the user from a group at the provider removes its permissions when
+	// the user logs in again, but not the permissions granted to the user.
+	if _, err := idp.login(t, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if GetPermission("carol", "root") || !GetPermission("carol", "table_read") || !GetPermission("carol", "admin_secrets") {
+		t.Error("oidcUser() did not replace the provider permissions")
+	}
+
+	if user, _ := AuthService.ReadUser("carol", true); strings.Join(user.Permissions, ",") != "admin_secrets" {
+		t.Errorf("oidcUser() changed the permissions granted to the user, %v", user.Permissions)
+	}
+}
+
+func TestValidateToken_ServerJWT(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	_, stop := setupTestIdP(t)
+	defer stop()
+
+	settings.SetDefault(defs.ServerTokenFormatSetting, "jwt")
+
+	defer func() {
+		settings.SetDefault(defs.ServerTokenFormatSetting, "")
+		settings.SetDefault(defs.ServerTokenKeysSetting, "")
+	}()
+
+	s := symbols.NewSymbolTable("test")
+	runtime.AddPackages(s)
+
+	// A JWT issued by the server is not mistaken for a provider token.
+	token, err := builtins.CallBuiltin(s, "cipher.New", "staff")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ValidateToken(data.String(token)) || TokenUser(data.String(token)) != "staff" {
+		t.Error("ValidateToken() did not accept the server JWT")
+	}
+}
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/audit"
+	"github.com/tucats/ego/util"
+)
+
+// How long an account is locked when the setting is not given.
+const defaultLockoutDuration = 15 * time.Minute
+
+// passwordMutex serializes changes to the password state, so concurrent failed
+// logons are all counted.
+var passwordMutex sync.Mutex
+
+// HashPassword returns the salted argon2id hash of a password, in the same form
+// as the cipher.HashPassword() function.
+func HashPassword(password string) (string, error) {
+	return util.HashPassword(password)
+}
+
+// SetPassword sets a new password for a user. The password must meet the password
+// policies in the server configuration. The password history for the user is
+// updated, and the account is unlocked. The caller is responsible for writing the
+// user to the database.
+func SetPassword(u *defs.User, password string) error {
+	passwordMutex.Lock()
+	defer passwordMutex.Unlock()
+
+	state, err := AuthService.ReadPasswordState(u.Name)
+	if err != nil {
+		return err
+	}
+
+	// The current password is the most recent password in the history.
+	history := state.History
+	if u.Password != "" {
+		history = append([]string{u.Password}, history...)
+	}
+
+	count := settings.GetInt(defs.ServerPasswordHistorySetting)
+	if count < len(history) {
+		history = history[:count]
+	}
+
+	if err := checkPasswordPolicy(password, history); err != nil {
+		return err
+	}
+
+	// Only the previous passwords are kept in the history, since the new password
+	// becomes the current password.
+	if count == 0 {
+		history = nil
+	} else if len(history) >= count {
+		history = history[:count-1]
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	u.Password = hash
+
+	state.History = history
+	state.Failures = 0
+	state.LockedUntil = ""
+
+	return AuthService.WritePasswordState(state)
+}
+
+// IsPasswordPolicyError reports if an error was caused by a password that does
+// not meet the password policies.
+func IsPasswordPolicyError(err error) bool {
+	return errors.Equals(err, errors.ErrPasswordLength) ||
+		errors.Equals(err, errors.ErrPasswordClasses) ||
+		errors.Equals(err, errors.ErrPasswordReused)
+}
+
+// checkPasswordPolicy verifies that a new password has the minimum length, contains
+// enough kinds of characters, and does not match any of the given previous hashes.
+func checkPasswordPolicy(password string, history []string) error {
+	if length := settings.GetInt(defs.ServerPasswordLengthSetting); utf8.RuneCountInString(password) < length {
+		return errors.ErrPasswordLength.Context(length)
+	}
+
+	if classes := settings.GetInt(defs.ServerPasswordClassesSetting); passwordClasses(password) < classes {
+		return errors.ErrPasswordClasses.Context(classes)
+	}
+
+	for _, previous := range history {
+		if ok, _ := checkPassword(previous, password); ok {
+			return errors.ErrPasswordReused
+		}
+	}
+
+	return nil
+}
+
+// passwordClasses returns the number of kinds of characters (lowercase letters,
+// uppercase letters, digits, and any other characters) in a password.
+func passwordClasses(password string) int {
+	var lower, upper, digit, other int
+
+	for _, ch := range password {
+		switch {
+		case unicode.IsLower(ch):
+			lower = 1
+		case unicode.IsUpper(ch):
+			upper = 1
+		case unicode.IsDigit(ch):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+
+	return lower + upper + digit + other
+}
+
+// checkPassword compares a password to the stored form of a password. The result
+// indicates if the password matches, and if it should be hashed again because it
+// does not use argon2id with the current parameters. Older user databases store an
+// unsalted SHA-256 hash, or a plain text password in braces.
+func checkPassword(stored, password string) (bool, bool) {
+	switch {
+	case util.IsArgon2Hash(stored):
+		ok := util.CheckPassword(password, stored)
+
+		return ok, ok && !util.PasswordHashCurrent(stored)
+
+	case strings.HasPrefix(stored, "{") && strings.HasSuffix(stored, "}"):
+		ok := subtle.ConstantTimeCompare([]byte(stored[1:len(stored)-1]), []byte(password)) == 1
+
+		return ok, ok
+
+	default:
+		ok := subtle.ConstantTimeCompare([]byte(HashString(password)), []byte(stored)) == 1
+
+		return ok, ok
+	}
+}
+
+// accountLocked reports if a user account is locked because of too many failed
+// logons, and returns the time the lock expires.
+func accountLocked(name string, now time.Time) (bool, string) {
+	passwordMutex.Lock()
+	defer passwordMutex.Unlock()
+
+	state, err := AuthService.ReadPasswordState(name)
+	if err != nil || state.LockedUntil == "" {
+		return false, ""
+	}
+
+	return !sessionExpired(state.LockedUntil, now), state.LockedUntil
+}
+
+// recordLogon updates the count of failed logons for a user. A successful logon
+// resets the count. When the count reaches the limit in the server configuration,
+// the account is locked for the configured duration.
+func recordLogon(name string, success bool, now time.Time) {
+	passwordMutex.Lock()
+	defer passwordMutex.Unlock()
+
+	state, err := AuthService.ReadPasswordState(name)
+	if err != nil {
+		return
+	}
+
+	if !countLogon(&state, success, now) {
+		return
+	}
+
+	if err := AuthService.WritePasswordState(state); err != nil {
+		ui.Log(ui.AuthLogger, "auth.error", ui.A{
+			"error": err})
+	}
+}
+
+// countLogon updates the count of failed logons in the password state, locking the
+// account if needed. The result is false if the state was not changed. The caller
+// must hold the password mutex.
+func countLogon(state *defs.PasswordState, success bool, now time.Time) bool {
+	if success {
+		if state.Failures == 0 && state.LockedUntil == "" {
+			return false
+		}
+
+		state.Failures = 0
+		state.LockedUntil = ""
+
+		return true
+	}
+
+	state.Failures++
+
+	if limit := settings.GetInt(defs.ServerLockoutCountSetting); limit > 0 && state.Failures >= limit {
+		duration, err := util.ParseDuration(settings.Get(defs.ServerLockoutDurationSetting))
+		if err != nil || duration <= 0 {
+			duration = defaultLockoutDuration
+		}
+
+		state.LockedUntil = now.Add(duration).Format(time.RFC3339)
+
+		audit.Record("logon.locked", ui.A{
+			"user":  state.Name,
+			"until": state.LockedUntil,
+			"count": state.Failures})
+
+		state.Failures = 0
+	}
+
+	return true
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/util"
+)
+
+// readPasswords reads the password state for the users from the file stored
+// alongside the user data file. It is not an error if the file does not exist yet.
+func (f *fileService) readPasswords() error {
+	ext := filepath.Ext(f.path)
+	f.passwordsPath = strings.TrimSuffix(f.path, ext) + "_passwords" + ext
+
+	b, err := os.ReadFile(f.passwordsPath)
+	if err != nil {
+		return nil
+	}
+
+	if key := settings.Get(defs.LogonUserdataKeySetting); key != "" {
+		r, err := util.Decrypt(string(b), key)
+		if err != nil {
+			return err
+		}
+
+		b = []byte(r)
+	}
+
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &f.passwords); err != nil {
+			return errors.New(err)
+		}
+	}
+
+	ui.Log(ui.AuthLogger, "auth.passwords.file", ui.A{
+		"count": len(f.passwords)})
+
+	return nil
+}
+
+// writePasswords writes the password state to its file. Like the sessions, the
+// state is written as soon as it changes so a locked account stays locked after
+// a restart. The caller must hold the lock for the service.
+func (f *fileService) writePasswords() error {
+	if f.passwordsPath == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(f.passwords, "", "   ")
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if key := settings.Get(defs.LogonUserdataKeySetting); key != "" {
+		r, err := util.Encrypt(string(b), key)
+		if err != nil {
+			return err
+		}
+
+		b = []byte(r)
+	}
+
+	if err := os.WriteFile(f.passwordsPath, b, 0600); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// ReadPasswordState returns the password state for a user. If there is no state
+// stored for the user, an empty state is returned.
+func (f *fileService) ReadPasswordState(name string) (defs.PasswordState, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	state, ok := f.passwords[name]
+	if !ok {
+		state = defs.PasswordState{Name: name}
+	}
+
+	return state, nil
+}
+
+// WritePasswordState adds or updates the password state for a user.
+func (f *fileService) WritePasswordState(state defs.PasswordState) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.passwords[state.Name] = state
+
+	return f.writePasswords()
+}
+
+// DeletePasswordState removes the password state for a user. It is not an error
+// if there is no state stored for the user.
+func (f *fileService) DeletePasswordState(name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, found := f.passwords[name]; !found {
+		return nil
+	}
+
+	delete(f.passwords, name)
+
+	return f.writePasswords()
+}
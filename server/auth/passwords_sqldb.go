@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/resources"
+)
+
+// openPasswords opens the resource handle for the passwords table, and creates
+// the table if it does not yet exist.
+func (pg *databaseService) openPasswords(connStr string) error {
+	var err error
+
+	if pg.passwordHandle, err = resources.Open(defs.PasswordState{}, "passwords", connStr); err != nil {
+		return err
+	}
+
+	return pg.passwordHandle.CreateIf()
+}
+
+// ReadPasswordState returns the password state for a user from the database. If
+// there is no state stored for the user, an empty state is returned.
+func (pg *databaseService) ReadPasswordState(name string) (defs.PasswordState, error) {
+	rowSet, err := pg.passwordHandle.Begin().Read(pg.passwordHandle.Equals("name", name))
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return defs.PasswordState{Name: name}, errors.New(err)
+	}
+
+	if len(rowSet) == 0 {
+		return defs.PasswordState{Name: name}, nil
+	}
+
+	return *rowSet[0].(*defs.PasswordState), nil
+}
+
+// WritePasswordState adds or updates the password state for a user in the database.
+func (pg *databaseService) WritePasswordState(state defs.PasswordState) error {
+	rowSet, err := pg.passwordHandle.Begin().Read(pg.passwordHandle.Equals("name", state.Name))
+	if err == nil {
+		if len(rowSet) > 0 {
+			err = pg.passwordHandle.Begin().Update(state, pg.passwordHandle.Equals("name", state.Name))
+		} else {
+			err = pg.passwordHandle.Begin().Insert(state)
+		}
+	}
+
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// DeletePasswordState removes the password state for a user from the database.
+// It is not an error if there is no state stored for the user.
+func (pg *databaseService) DeletePasswordState(name string) error {
+	if _, err := pg.passwordHandle.Begin().Delete(pg.passwordHandle.Equals("name", name)); err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return errors.New(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/util"
+)
+
+func TestCheckPassword(t *testing.T) {
+	current, _ := HashPassword("secret")
+
+	tests := []struct {
+		name     string
+		stored   string
+		password string
+		ok       bool
+		rehash   bool
+	}{
+		{"salted hash", current, "secret", true, false},
+		{"salted hash wrong password", current, "Secret", false, false},
+		{"older parameters", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", "password", true, true},
+		{"legacy hash", HashString("secret"), "secret", true, true},
+		{"legacy hash wrong password", HashString("secret"), "secrets", false, false},
+		{"plain text", "{secret}", "secret", true, true},
+		{"invalid hash", "$argon2id$x$y", "secret", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rehash := checkPassword(tt.stored, tt.password)
+			if ok != tt.ok || rehash != tt.rehash {
+				t.Errorf("checkPassword() = %v, %v, want %v, %v", ok, rehash, tt.ok, tt.rehash)
+			}
+		})
+	}
+
+	// Each hash of the same password uses a different salt.
+	second, _ := HashPassword("secret")
+	if current == second || !strings.HasPrefix(current, "$argon2id$v=19$m=65536,t=3,p=4$") {
+		t.Errorf("HashPassword() = %s, %s", current, second)
+	}
+}
+
+func TestSetPassword(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	settings.SetDefault(defs.ServerPasswordLengthSetting, "8")
+	settings.SetDefault(defs.ServerPasswordClassesSetting, "3")
+	settings.SetDefault(defs.ServerPasswordHistorySetting, "2")
+
+	defer func() {
+		settings.SetDefault(defs.ServerPasswordLengthSetting, "")
+		settings.SetDefault(defs.ServerPasswordClassesSetting, "")
+		settings.SetDefault(defs.ServerPasswordHistorySetting, "")
+	}()
+
+	u, _ := AuthService.ReadUser("staff", false)
+
+	tests := []struct {
+		password string
+		err      error
+	}{
+		{"Ab1", errors.ErrPasswordLength},
+		{"abcdefgh1", errors.ErrPasswordClasses},
+		{"quidditch", errors.ErrPasswordClasses},
+		{"Quidditch1", nil},
+		{"Quidditch1", errors.ErrPasswordReused},
+		{"Snitch-2024", nil},
+		{"Quidditch1", errors.ErrPasswordReused},
+		{"Bludger#3", nil},
+		{"Quidditch1", nil},
+	}
+
+	for _, tt := range tests {
+		err := SetPassword(&u, tt.password)
+		if (tt.err == nil && err != nil) || (tt.err != nil && !errors.Equals(err, tt.err)) {
+			t.Fatalf("SetPassword(%s) = %v, want %v", tt.password, err, tt.err)
+		}
+	}
+
+	if ok, _ := checkPassword(u.Password, "Quidditch1"); !ok {
+		t.Error("SetPassword() did not set the hash of the password")
+	}
+}
+
+func TestAccountLockout(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	settings.SetDefault(defs.ServerLockoutCountSetting, "3")
+	defer settings.SetDefault(defs.ServerLockoutCountSetting, "")
+
+	// A failed logon followed by a valid one resets the count of failures.
+	if ValidatePassword("staff", "wrong") || !ValidatePassword("staff", "quidditch") {
+		t.Fatal("ValidatePassword() did not check the password")
+	}
+
+	// The legacy hash is replaced after the first successful logon.
+	if u, _ := AuthService.ReadUser("staff", false); !util.PasswordHashCurrent(u.Password) {
+		t.Errorf("ValidatePassword() did not re-hash the password, %s", u.Password)
+	}
+
+	for i := 0; i < 3; i++ {
+		_ = ValidatePassword("staff", "wrong")
+	}
+
+	if ValidatePassword("staff", "quidditch") {
+		t.Fatal("ValidatePassword() accepted a logon for a locked account")
+	}
+
+	// Setting a new password unlocks the account.
+	u, _ := AuthService.ReadUser("staff", false)
+	if err := SetPassword(&u, "snitch"); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = AuthService.WriteUser(u)
+
+	if !ValidatePassword("staff", "snitch") {
+		t.Error("SetPassword() did not unlock the account")
+	}
+}
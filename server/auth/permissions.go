@@ -70,19 +70,15 @@ func setPermission(user, privilege string, enabled bool) error {
 }
 
 // GetPermission returns a boolean indicating if the given username and privilege are valid and
-// set. If the username or privilege does not exist, then the reply is always false.
+// set. The privilege can be granted to the user directly, or by a role assigned to a group the
+// user is a member of. If the username or privilege does not exist, then the reply is always false.
 func GetPermission(user, privilege string) bool {
 	// Normalize the privilege name to lowercase.
 	privname := strings.ToLower(privilege)
 
-	// Read the user definition. If the user does not exist, return false.
-	if u, err := AuthService.ReadUser(user, false); err == nil {
-		// Search for the permission in the user's permission list. If the
-		// permission is found, it has a positive position and we return true.
-		// if it was not found, the position value was -1 and we return false.
-		pn := findPermission(u, privname)
-
-		return (pn != PrivilegeNotFound)
+	// Get the effective permissions for the user. If the user does not exist, return false.
+	if perms, err := effectivePermissions(user); err == nil {
+		return perms[privname]
 	}
 
 	ui.Log(ui.AuthLogger, "auth.no.priv",
@@ -0,0 +1,300 @@
+package auth
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/caches"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/util"
+)
+
+// EffectivePermissions returns the sorted list of all permissions granted to a
+// user. This includes the permissions granted to the user directly, and the
+// permissions of each role assigned to a group the user is a member of, along
+// with the permissions of any roles those roles inherit.
+func EffectivePermissions(user string) ([]string, error) {
+	perms, err := effectivePermissions(user)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(perms))
+	for perm := range perms {
+		result = append(result, perm)
+	}
+
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// UserRoles returns the sorted list of the roles a user holds. These are the roles
+// assigned to each group the user is a member of, along with any roles those roles
+// inherit.
+func UserRoles(user string) []string {
+	roles := AuthService.ListRoles()
+	visited := map[string]bool{}
+
+	for _, group := range AuthService.ListGroups() {
+		if util.InList(user, group.Members...) {
+			for _, role := range group.Roles {
+				addInheritedRoles(roles, role, visited)
+			}
+		}
+	}
+
+	result := make([]string, 0, len(visited))
+	for role := range visited {
+		result = append(result, role)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// addInheritedRoles adds the named role, and all the roles it inherits, to the set
+// of roles.
+func addInheritedRoles(roles map[string]defs.Role, name string, visited map[string]bool) {
+	if visited[name] {
+		return
+	}
+
+	visited[name] = true
+
+	for _, parent := range roles[name].Inherits {
+		addInheritedRoles(roles, parent, visited)
+	}
+}
+
+// effectivePermissions returns the set of all permissions granted to a user. The
+// set is cached, so the roles and groups do not need to be read for each request.
+// The cache is purged whenever a user, role, or group is changed.
+func effectivePermissions(user string) (map[string]bool, error) {
+	if item, found := caches.Find(caches.PermissionCache, user); found {
+		if perms, ok := item.(map[string]bool); ok {
+			return perms, nil
+		}
+	}
+
+	u, err := AuthService.ReadUser(user, true)
+	if err != nil {
+		return nil, err
+	}
+
+	perms := map[string]bool{}
+
+	for _, perm := range u.Permissions {
+		perms[strings.ToLower(perm)] = true
+	}
+
+	if state, err := AuthService.ReadPasswordState(user); err == nil {
+		for _, perm := range state.Provider {
+			perms[strings.ToLower(perm)] = true
+		}
+	}
+
+	roles := AuthService.ListRoles()
+
+	for _, group := range AuthService.ListGroups() {
+		if !util.InList(user, group.Members...) {
+			continue
+		}
+
+		for _, role := range group.Roles {
+			addRolePermissions(roles, role, perms, map[string]bool{})
+		}
+	}
+
+	caches.Add(caches.PermissionCache, user, perms)
+
+	return perms, nil
+}
+
+// addRolePermissions adds the permissions of the named role, and of all the roles
+// it inherits, to the set of permissions. The visited set prevents a role from
+// being processed more than once.
+func addRolePermissions(roles map[string]defs.Role, name string, perms, visited map[string]bool) {
+	if visited[name] {
+		return
+	}
+
+	visited[name] = true
+
+	role, found := roles[name]
+	if !found {
+		return
+	}
+
+	for _, perm := range role.Permissions {
+		perms[strings.ToLower(perm)] = true
+	}
+
+	for _, parent := range role.Inherits {
+		addRolePermissions(roles, parent, perms, visited)
+	}
+}
+
+// SetRole creates or updates a role. Each role the role inherits must already
+// exist, and a role cannot inherit itself, either directly or through
+// another role.
+func SetRole(role defs.Role) error {
+	if role.Name == "" {
+		return errors.ErrInvalidValue.Context("name")
+	}
+
+	roles := AuthService.ListRoles()
+
+	if old, found := roles[role.Name]; found && role.ID == uuid.Nil {
+		role.ID = old.ID
+	}
+
+	if role.ID == uuid.Nil {
+		role.ID = uuid.New()
+	}
+
+	role.Permissions = normalizeNames(role.Permissions, true)
+	role.Inherits = normalizeNames(role.Inherits, false)
+	roles[role.Name] = role
+
+	for _, parent := range role.Inherits {
+		if _, found := roles[parent]; !found {
+			return errors.ErrNoSuchRole.Context(parent)
+		}
+	}
+
+	if inheritsRole(roles, role.Inherits, role.Name, map[string]bool{}) {
+		return errors.ErrRoleCycle.Context(role.Name)
+	}
+
+	return saveRoles(AuthService.WriteRole(role))
+}
+
+// inheritsRole reports if any of the list of roles, or any of the roles they
+// inherit, is the named role.
+func inheritsRole(roles map[string]defs.Role, list []string, name string, visited map[string]bool) bool {
+	for _, item := range list {
+		if item == name {
+			return true
+		}
+
+		if visited[item] {
+			continue
+		}
+
+		visited[item] = true
+
+		if inheritsRole(roles, roles[item].Inherits, name, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RemoveRole deletes a role. A role cannot be deleted while it is inherited by
+// another role or assigned to a group.
+func RemoveRole(name string) error {
+	if _, err := AuthService.ReadRole(name); err != nil {
+		return err
+	}
+
+	for _, role := range AuthService.ListRoles() {
+		if util.InList(name, role.Inherits...) {
+			return errors.ErrRoleInUse.Context(name)
+		}
+	}
+
+	for _, group := range AuthService.ListGroups() {
+		if util.InList(name, group.Roles...) {
+			return errors.ErrRoleInUse.Context(name)
+		}
+	}
+
+	return saveRoles(AuthService.DeleteRole(name))
+}
+
+// SetGroup creates or updates a group. Each role assigned to the group must
+// already exist. The members of the group do not need to exist yet.
+func SetGroup(group defs.Group) error {
+	if group.Name == "" {
+		return errors.ErrInvalidValue.Context("name")
+	}
+
+	if old, err := AuthService.ReadGroup(group.Name); err == nil && group.ID == uuid.Nil {
+		group.ID = old.ID
+	}
+
+	if group.ID == uuid.Nil {
+		group.ID = uuid.New()
+	}
+
+	group.Members = normalizeNames(group.Members, false)
+	group.Roles = normalizeNames(group.Roles, false)
+
+	for _, role := range group.Roles {
+		if _, err := AuthService.ReadRole(role); err != nil {
+			return err
+		}
+	}
+
+	return saveRoles(AuthService.WriteGroup(group))
+}
+
+// RemoveGroup deletes a group.
+func RemoveGroup(name string) error {
+	return saveRoles(AuthService.DeleteGroup(name))
+}
+
+// saveRoles is called after a role or group is changed. If the change was
+// successful, the cached permissions for all users are discarded, and the
+// changes are written to the backing store.
+func saveRoles(err error) error {
+	if err != nil {
+		return err
+	}
+
+	caches.Purge(caches.PermissionCache)
+
+	return AuthService.Flush()
+}
+
+// normalizeNames removes empty and duplicate names from a list, and sorts it.
+// If the lower flag is set, the names are also converted to lower case, as is
+// done for permission names.
+func normalizeNames(list []string, lower bool) []string {
+	set := map[string]bool{}
+	result := []string{}
+
+	for _, name := range list {
+		name = strings.TrimSpace(name)
+		if lower {
+			name = strings.ToLower(name)
+		}
+
+		if name != "" && !set[name] {
+			set[name] = true
+			result = append(result, name)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// logRoleChange logs the creation or update of a role or group. The prefix
+// identifies the kind of item changed.
+func logRoleChange(prefix, name string, found bool) {
+	if found {
+		ui.Log(ui.AuthLogger, prefix+".update", ui.A{
+			"name": name})
+	} else {
+		ui.Log(ui.AuthLogger, prefix+".create", ui.A{
+			"name": name})
+	}
+}
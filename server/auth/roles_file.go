@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/util"
+)
+
+// readRoles reads the roles and groups from the file stored alongside the
+// user data file. It is not an error if the file does not exist yet.
+func (f *fileService) readRoles() error {
+	ext := filepath.Ext(f.path)
+	f.rolesPath = strings.TrimSuffix(f.path, ext) + "_roles" + ext
+
+	b, err := os.ReadFile(f.rolesPath)
+	if err != nil {
+		return nil
+	}
+
+	if key := settings.Get(defs.LogonUserdataKeySetting); key != "" {
+		r, err := util.Decrypt(string(b), key)
+		if err != nil {
+			return err
+		}
+
+		b = []byte(r)
+	}
+
+	data := fileRoles{}
+
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &data); err != nil {
+			return errors.New(err)
+		}
+	}
+
+	if data.Roles != nil {
+		f.roles = data.Roles
+	}
+
+	if data.Groups != nil {
+		f.groups = data.Groups
+	}
+
+	ui.Log(ui.AuthLogger, "auth.roles.file", ui.A{
+		"roles":  len(f.roles),
+		"groups": len(f.groups)})
+
+	return nil
+}
+
+// writeRoles writes the roles and groups to their file. The caller must hold
+// the lock for the service.
+func (f *fileService) writeRoles() error {
+	if f.rolesPath == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(fileRoles{Roles: f.roles, Groups: f.groups}, "", "   ")
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if key := settings.Get(defs.LogonUserdataKeySetting); key != "" {
+		r, err := util.Encrypt(string(b), key)
+		if err != nil {
+			return err
+		}
+
+		b = []byte(r)
+	}
+
+	if err := os.WriteFile(f.rolesPath, b, 0600); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// ListRoles returns a map of all roles in the database.
+func (f *fileService) ListRoles() map[string]defs.Role {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	result := make(map[string]defs.Role, len(f.roles))
+	for name, role := range f.roles {
+		result[name] = role
+	}
+
+	return result
+}
+
+// ReadRole returns a role definition from the database.
+func (f *fileService) ReadRole(name string) (defs.Role, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	role, ok := f.roles[name]
+	if !ok {
+		return role, errors.ErrNoSuchRole.Context(name)
+	}
+
+	return role, nil
+}
+
+// WriteRole adds or updates a role definition in the database. The map is
+// marked as dirty so it will be written to disk.
+func (f *fileService) WriteRole(role defs.Role) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	_, found := f.roles[role.Name]
+	f.roles[role.Name] = role
+	f.dirty = true
+
+	logRoleChange("auth.role", role.Name, found)
+
+	return nil
+}
+
+// DeleteRole removes a role definition from the database. The map is marked
+// as dirty so it will be written to disk.
+func (f *fileService) DeleteRole(name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, found := f.roles[name]; !found {
+		return errors.ErrNoSuchRole.Context(name)
+	}
+
+	delete(f.roles, name)
+	f.dirty = true
+
+	ui.Log(ui.AuthLogger, "auth.role.delete", ui.A{
+		"name": name})
+
+	return nil
+}
+
+// ListGroups returns a map of all groups in the database.
+func (f *fileService) ListGroups() map[string]defs.Group {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	result := make(map[string]defs.Group, len(f.groups))
+	for name, group := range f.groups {
+		result[name] = group
+	}
+
+	return result
+}
+
+// ReadGroup returns a group definition from the database.
+func (f *fileService) ReadGroup(name string) (defs.Group, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	group, ok := f.groups[name]
+	if !ok {
+		return group, errors.ErrNoSuchGroup.Context(name)
+	}
+
+	return group, nil
+}
+
+// WriteGroup adds or updates a group definition in the database. The map is
+// marked as dirty so it will be written to disk.
+func (f *fileService) WriteGroup(group defs.Group) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	_, found := f.groups[group.Name]
+	f.groups[group.Name] = group
+	f.dirty = true
+
+	logRoleChange("auth.group", group.Name, found)
+
+	return nil
+}
+
+// DeleteGroup removes a group definition from the database. The map is marked
+// as dirty so it will be written to disk.
+func (f *fileService) DeleteGroup(name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, found := f.groups[name]; !found {
+		return errors.ErrNoSuchGroup.Context(name)
+	}
+
+	delete(f.groups, name)
+	f.dirty = true
+
+	ui.Log(ui.AuthLogger, "auth.group.delete", ui.A{
+		"name": name})
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/resources"
+)
+
+// openRoles opens the resource handles for the roles and groups tables, and
+// creates the tables if they do not yet exist.
+func (pg *databaseService) openRoles(connStr string) error {
+	var err error
+
+	if pg.roleHandle, err = resources.Open(defs.Role{}, "roles", connStr); err != nil {
+		return err
+	}
+
+	if pg.groupHandle, err = resources.Open(defs.Group{}, "usergroups", connStr); err != nil {
+		return err
+	}
+
+	if err = pg.roleHandle.CreateIf(); err != nil {
+		return err
+	}
+
+	return pg.groupHandle.CreateIf()
+}
+
+// ListRoles returns a map of all roles in the database.
+func (pg *databaseService) ListRoles() map[string]defs.Role {
+	r := map[string]defs.Role{}
+
+	rowSet, err := pg.roleHandle.Begin().Read()
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return r
+	}
+
+	for _, row := range rowSet {
+		role := row.(*defs.Role)
+		r[role.Name] = *role
+	}
+
+	return r
+}
+
+// ReadRole returns a role definition from the database.
+func (pg *databaseService) ReadRole(name string) (defs.Role, error) {
+	rowSet, err := pg.roleHandle.Begin().Read(pg.roleHandle.Equals("name", name))
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return defs.Role{}, errors.New(err)
+	}
+
+	if len(rowSet) == 0 {
+		return defs.Role{}, errors.ErrNoSuchRole.Context(name)
+	}
+
+	return *rowSet[0].(*defs.Role), nil
+}
+
+// WriteRole adds or updates a role definition in the database.
+func (pg *databaseService) WriteRole(role defs.Role) error {
+	_, err := pg.ReadRole(role.Name)
+	found := err == nil
+
+	if found {
+		err = pg.roleHandle.Begin().Update(role, pg.roleHandle.Equals("name", role.Name))
+	} else {
+		err = pg.roleHandle.Begin().Insert(role)
+	}
+
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return errors.New(err)
+	}
+
+	logRoleChange("auth.role", role.Name, found)
+
+	return nil
+}
+
+// DeleteRole removes a role definition from the database.
+func (pg *databaseService) DeleteRole(name string) error {
+	count, err := pg.roleHandle.Begin().Delete(pg.roleHandle.Equals("name", name))
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return errors.New(err)
+	}
+
+	if count == 0 {
+		return errors.ErrNoSuchRole.Context(name)
+	}
+
+	ui.Log(ui.AuthLogger, "auth.role.delete", ui.A{
+		"name": name})
+
+	return nil
+}
+
+// ListGroups returns a map of all groups in the database.
+func (pg *databaseService) ListGroups() map[string]defs.Group {
+	r := map[string]defs.Group{}
+
+	rowSet, err := pg.groupHandle.Begin().Read()
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return r
+	}
+
+	for _, row := range rowSet {
+		group := row.(*defs.Group)
+		r[group.Name] = *group
+	}
+
+	return r
+}
+
+// ReadGroup returns a group definition from the database.
+func (pg *databaseService) ReadGroup(name string) (defs.Group, error) {
+	rowSet, err := pg.groupHandle.Begin().Read(pg.groupHandle.Equals("name", name))
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return defs.Group{}, errors.New(err)
+	}
+
+	if len(rowSet) == 0 {
+		return defs.Group{}, errors.ErrNoSuchGroup.Context(name)
+	}
+
+	return *rowSet[0].(*defs.Group), nil
+}
+
+// WriteGroup adds or updates a group definition in the database.
+func (pg *databaseService) WriteGroup(group defs.Group) error {
+	_, err := pg.ReadGroup(group.Name)
+	found := err == nil
+
+	if found {
+		err = pg.groupHandle.Begin().Update(group, pg.groupHandle.Equals("name", group.Name))
+	} else {
+		err = pg.groupHandle.Begin().Insert(group)
+	}
+
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return errors.New(err)
+	}
+
+	logRoleChange("auth.group", group.Name, found)
+
+	return nil
+}
+
+// DeleteGroup removes a group definition from the database.
+func (pg *databaseService) DeleteGroup(name string) error {
+	count, err := pg.groupHandle.Begin().Delete(pg.groupHandle.Equals("name", name))
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return errors.New(err)
+	}
+
+	if count == 0 {
+		return errors.ErrNoSuchGroup.Context(name)
+	}
+
+	ui.Log(ui.AuthLogger, "auth.group.delete", ui.A{
+		"name": name})
+
+	return nil
+}
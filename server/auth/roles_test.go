@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tucats/ego/caches"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+)
+
+// setupRoles defines a set of roles and groups for the users created by
+// setupTestAuthService.
+func setupRoles(t *testing.T) {
+	for _, role := range []defs.Role{
+		{Name: "reader", Permissions: []string{"Table_Read"}},
+		{Name: "writer", Permissions: []string{"table_update"}, Inherits: []string{"reader"}},
+		{Name: "dba", Permissions: []string{"table_admin"}, Inherits: []string{"writer"}},
+	} {
+		if err := SetRole(role); err != nil {
+			t.Fatalf("SetRole(%s) failed: %v", role.Name, err)
+		}
+	}
+
+	if err := SetGroup(defs.Group{Name: "analysts", Members: []string{"staff", "bogus"}, Roles: []string{"writer"}}); err != nil {
+		t.Fatalf("SetGroup() failed: %v", err)
+	}
+}
+
+func TestEffectivePermissions(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	caches.Purge(caches.PermissionCache)
+	setupRoles(t)
+
+	perms, err := EffectivePermissions("staff")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"logon", "table_read", "table_update", "tables"}
+	if !reflect.DeepEqual(perms, want) {
+		t.Errorf("EffectivePermissions() = %v, want %v", perms, want)
+	}
+
+	if !GetPermission("staff", "TABLE_READ") || GetPermission("staff", "table_admin") {
+		t.Error("GetPermission() did not use the inherited role permissions")
+	}
+
+	// Changing a role is seen by the members of groups with the role.
+	if err := SetRole(defs.Role{Name: "reader", Permissions: []string{"table_read", "dsn_read"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !GetPermission("bogus", "dsn_read") {
+		t.Error("GetPermission() did not see the updated role")
+	}
+
+	// A role granted through a group can allow a user to log in.
+	if ValidatePassword("bogus", "zork") {
+		t.Error("ValidatePassword() succeeded without the logon permission")
+	}
+
+	if err := SetRole(defs.Role{Name: "reader", Permissions: []string{"logon"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !ValidatePassword("bogus", "zork") {
+		t.Error("ValidatePassword() did not use the role permissions")
+	}
+
+	if _, err := EffectivePermissions("nobody"); err == nil {
+		t.Error("EffectivePermissions() for a missing user did not fail")
+	}
+}
+
+func TestSetRole_Errors(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	setupRoles(t)
+
+	if err := SetRole(defs.Role{Name: "auditor", Inherits: []string{"missing"}}); !errors.Equals(err, errors.ErrNoSuchRole) {
+		t.Errorf("SetRole() with missing role = %v", err)
+	}
+
+	if err := SetRole(defs.Role{Name: "reader", Inherits: []string{"dba"}}); !errors.Equals(err, errors.ErrRoleCycle) {
+		t.Errorf("SetRole() with cycle = %v", err)
+	}
+
+	if err := SetRole(defs.Role{Name: "self", Inherits: []string{"self"}}); !errors.Equals(err, errors.ErrRoleCycle) {
+		t.Errorf("SetRole() inheriting itself = %v", err)
+	}
+
+	if err := SetGroup(defs.Group{Name: "ops", Roles: []string{"missing"}}); !errors.Equals(err, errors.ErrNoSuchRole) {
+		t.Errorf("SetGroup() with missing role = %v", err)
+	}
+
+	if err := RemoveRole("reader"); !errors.Equals(err, errors.ErrRoleInUse) {
+		t.Errorf("RemoveRole() of inherited role = %v", err)
+	}
+
+	if err := RemoveRole("writer"); !errors.Equals(err, errors.ErrRoleInUse) {
+		t.Errorf("RemoveRole() of group role = %v", err)
+	}
+
+	if err := RemoveGroup("analysts"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RemoveRole("dba"); err != nil {
+		t.Errorf("RemoveRole() = %v", err)
+	}
+
+	if err := RemoveRole("dba"); !errors.Equals(err, errors.ErrNoSuchRole) {
+		t.Errorf("RemoveRole() of deleted role = %v", err)
+	}
+}
+
+func TestRoles_Stores(t *testing.T) {
+	dir := t.TempDir()
+
+	services := map[string]func() (userIOService, error){
+		"file": func() (userIOService, error) {
+			return NewFileService(filepath.Join(dir, "users.json"), "admin", "password")
+		},
+		"database": func() (userIOService, error) {
+			return NewDatabaseService("sqlite3://"+filepath.Join(dir, "users.db"), "admin", "password")
+		},
+	}
+
+	for name, open := range services {
+		t.Run(name, func(t *testing.T) {
+			saved := AuthService
+			defer func() { AuthService = saved }()
+
+			var err error
+
+			if AuthService, err = open(); err != nil {
+				t.Fatal(err)
+			}
+
+			setupRoles(t)
+
+			// Read the roles and groups from a new instance of the service, to
+			// show they were stored.
+			if AuthService, err = open(); err != nil {
+				t.Fatal(err)
+			}
+
+			role, err := AuthService.ReadRole("writer")
+			if err != nil || !reflect.DeepEqual(role.Inherits, []string{"reader"}) || !reflect.DeepEqual(role.Permissions, []string{"table_update"}) {
+				t.Errorf("ReadRole() = %v, %v", role, err)
+			}
+
+			if len(AuthService.ListRoles()) != 3 {
+				t.Errorf("ListRoles() = %v", AuthService.ListRoles())
+			}
+
+			group, err := AuthService.ReadGroup("analysts")
+			if err != nil || !reflect.DeepEqual(group.Members, []string{"bogus", "staff"}) {
+				t.Errorf("ReadGroup() = %v, %v", group, err)
+			}
+
+			if err := RemoveGroup("analysts"); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := AuthService.ReadGroup("analysts"); !errors.Equals(err, errors.ErrNoSuchGroup) {
+				t.Errorf("ReadGroup() after delete = %v", err)
+			}
+		})
+	}
+}
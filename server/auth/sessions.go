@@ -0,0 +1,298 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/caches"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/runtime/cipher"
+	"github.com/tucats/ego/server/audit"
+	"github.com/tucats/ego/util"
+)
+
+// sessionMutex serializes changes to the sessions, so a refresh token cannot be
+// used twice by concurrent requests.
+var sessionMutex sync.Mutex
+
+// NewSession records a token issued by the server, so it can be listed and revoked
+// before it expires. The family is the session replaced by this one when a refresh
+// token is used, or uuid.Nil for a new logon. If the server issues refresh tokens,
+// a new refresh token is returned for the session; otherwise the result is empty.
+func NewSession(token, address string, family uuid.UUID) (string, error) {
+	id, user, expires, err := cipher.TokenInfo(token)
+	if err != nil {
+		return "", err
+	}
+
+	if family == uuid.Nil {
+		family = id
+	}
+
+	now := time.Now()
+	session := defs.LogonSession{
+		ID:      id,
+		Name:    user,
+		Family:  family,
+		Address: address,
+		Issued:  now.Format(time.RFC3339Nano),
+		Expires: expires.Format(time.RFC3339),
+	}
+
+	refresh := ""
+
+	if duration, err := util.ParseDuration(settings.Get(defs.ServerTokenRefreshSetting)); err == nil && duration > 0 {
+		refresh = randomString()
+		session.Refresh = hashRefresh(refresh)
+		session.RefreshExpires = now.Add(duration).Format(time.RFC3339)
+	}
+
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	pruneSessions(now)
+
+	if err := AuthService.WriteSession(session); err != nil {
+		return "", err
+	}
+
+	audit.Record("token.issue", ui.A{
+		"id":      id,
+		"user":    user,
+		"address": address,
+		"refresh": refresh != ""})
+
+	return refresh, nil
+}
+
+// Sessions returns the active sessions, sorted by the time they were issued. A
+// session is active if it has not been revoked, and either its token or its refresh
+// token can still be used. If a user name is given, only the sessions for that user
+// are returned.
+func Sessions(user string) []defs.LogonSession {
+	now := time.Now()
+	result := []defs.LogonSession{}
+
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	for _, session := range pruneSessions(now) {
+		if session.Revoked || (user != "" && session.Name != user) {
+			continue
+		}
+
+		if !sessionExpired(session.Expires, now) || refreshUsable(session, now) {
+			session.Refresh = ""
+			result = append(result, session)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return sessionBefore(result[i], result[j])
+	})
+
+	return result
+}
+
+// RevokeSession revokes the token with the given ID, and any refresh token issued
+// with it. The revoked session is returned.
+func RevokeSession(id string) (defs.LogonSession, error) {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	session, err := AuthService.ReadSession(id)
+	if err != nil {
+		return session, err
+	}
+
+	if err = revoke(session); err != nil {
+		return session, err
+	}
+
+	session.Refresh = ""
+	session.Revoked = true
+
+	return session, nil
+}
+
+// RevokeSessions revokes all the active tokens issued to a user, and returns the
+// sessions that were revoked.
+func RevokeSessions(user string) ([]defs.LogonSession, error) {
+	result := []defs.LogonSession{}
+
+	for _, session := range Sessions(user) {
+		revoked, err := RevokeSession(session.ID.String())
+		if err != nil {
+			return result, err
+		}
+
+		result = append(result, revoked)
+	}
+
+	return result, nil
+}
+
+// RefreshSession validates a refresh token, and returns the session it was issued
+// for so a new token can be issued to the same user. A refresh token can only be
+// used once. If a refresh token that was already used is presented again, it may
+// have been stolen, so every session in the family of sessions created from the
+// original logon is revoked.
+func RefreshSession(refresh string) (defs.LogonSession, error) {
+	now := time.Now()
+	hash := hashRefresh(refresh)
+
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	for _, session := range AuthService.ListSessions() {
+		if refresh == "" || session.Refresh != hash {
+			continue
+		}
+
+		if session.Refreshed {
+			ui.Log(ui.AuthLogger, "auth.refresh.reused", ui.A{
+				"id":   session.ID,
+				"user": session.Name})
+
+			revokeFamily(session.Family)
+
+			return session, errors.ErrInvalidRefreshToken
+		}
+
+		if session.Revoked || !refreshUsable(session, now) {
+			break
+		}
+
+		if !GetPermission(session.Name, "root") && !GetPermission(session.Name, "logon") {
+			return session, errors.ErrNoPermission
+		}
+
+		session.Refreshed = true
+		if err := AuthService.WriteSession(session); err != nil {
+			return session, err
+		}
+
+		session.Refresh = ""
+
+		return session, nil
+	}
+
+	return defs.LogonSession{}, errors.ErrInvalidRefreshToken
+}
+
+// TokenRevoked reports if the token has been revoked. A token that the server did
+// not record a session for, such as a token created by an Ego service, cannot be
+// revoked.
+func TokenRevoked(token string) bool {
+	id, user, _, err := cipher.TokenInfo(token)
+	if err != nil {
+		return false
+	}
+
+	session, err := AuthService.ReadSession(id.String())
+	if err != nil || !session.Revoked {
+		return false
+	}
+
+	ui.Log(ui.AuthLogger, "auth.session.revoked", ui.A{
+		"id":   id,
+		"user": user})
+
+	return true
+}
+
+// revoke marks a session as revoked. The cache of recently validated tokens is
+// purged so the revoked token is not accepted from the cache. The caller must hold
+// the session mutex.
+func revoke(session defs.LogonSession) error {
+	if session.Revoked {
+		return nil
+	}
+
+	session.Revoked = true
+	if err := AuthService.WriteSession(session); err != nil {
+		return err
+	}
+
+	caches.Purge(caches.TokenCache)
+
+	audit.Record("token.revoke", ui.A{
+		"id":   session.ID,
+		"user": session.Name})
+
+	return nil
+}
+
+// revokeFamily revokes every session created from the same logon. The caller must
+// hold the session mutex.
+func revokeFamily(family uuid.UUID) {
+	for _, session := range AuthService.ListSessions() {
+		if session.Family == family {
+			_ = revoke(session)
+		}
+	}
+}
+
+// pruneSessions removes the sessions that can no longer be used, because both the
+// token and any refresh token have expired, and returns the remaining sessions.
+// A session that was refreshed is kept while its refresh token has not expired, so
+// reuse of the refresh token can be detected. The caller must hold the session
+// mutex.
+func pruneSessions(now time.Time) map[string]defs.LogonSession {
+	sessions := AuthService.ListSessions()
+
+	for id, session := range sessions {
+		if !sessionExpired(session.Expires, now) || !sessionExpired(session.RefreshExpires, now) {
+			continue
+		}
+
+		if err := AuthService.DeleteSession(id); err == nil {
+			delete(sessions, id)
+		}
+	}
+
+	return sessions
+}
+
+// refreshUsable reports if the refresh token for a session can be used to get a
+// new token.
+func refreshUsable(session defs.LogonSession, now time.Time) bool {
+	return session.Refresh != "" && !session.Refreshed && !sessionExpired(session.RefreshExpires, now)
+}
+
+// sessionExpired reports if a session time has passed. An empty or invalid time
+// is treated as expired.
+func sessionExpired(text string, now time.Time) bool {
+	t, err := time.Parse(time.RFC3339, text)
+
+	return err != nil || now.After(t)
+}
+
+// sessionBefore reports if a session was issued before another. The issue times
+// have sub-second resolution, and sessions issued at the same time are ordered by
+// their ID, so the order of the list is always the same.
+func sessionBefore(a, b defs.LogonSession) bool {
+	ta, _ := time.Parse(time.RFC3339Nano, a.Issued)
+	tb, _ := time.Parse(time.RFC3339Nano, b.Issued)
+
+	if !ta.Equal(tb) {
+		return ta.Before(tb)
+	}
+
+	return a.ID.String() < b.ID.String()
+}
+
+// hashRefresh returns the hash of a refresh token. Only the hash is stored, so the
+// refresh tokens cannot be recovered from the user database.
+func hashRefresh(refresh string) string {
+	sum := sha256.Sum256([]byte(refresh))
+
+	return hex.EncodeToString(sum[:])
+}
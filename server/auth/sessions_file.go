@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/util"
+)
+
+// readSessions reads the sessions from the file stored alongside the user data
+// file. It is not an error if the file does not exist yet.
+func (f *fileService) readSessions() error {
+	ext := filepath.Ext(f.path)
+	f.sessionsPath = strings.TrimSuffix(f.path, ext) + "_sessions" + ext
+
+	b, err := os.ReadFile(f.sessionsPath)
+	if err != nil {
+		return nil
+	}
+
+	if key := settings.Get(defs.LogonUserdataKeySetting); key != "" {
+		r, err := util.Decrypt(string(b), key)
+		if err != nil {
+			return err
+		}
+
+		b = []byte(r)
+	}
+
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &f.sessions); err != nil {
+			return errors.New(err)
+		}
+	}
+
+	ui.Log(ui.AuthLogger, "auth.sessions.file", ui.A{
+		"count": len(f.sessions)})
+
+	return nil
+}
+
+// writeSessions writes the sessions to their file. Unlike the users, roles and
+// groups, the sessions are written as soon as they change so a revoked token is
+// never accepted after a restart. The caller must hold the lock for the service.
+func (f *fileService) writeSessions() error {
+	if f.sessionsPath == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(f.sessions, "", "   ")
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if key := settings.Get(defs.LogonUserdataKeySetting); key != "" {
+		r, err := util.Encrypt(string(b), key)
+		if err != nil {
+			return err
+		}
+
+		b = []byte(r)
+	}
+
+	if err := os.WriteFile(f.sessionsPath, b, 0600); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// ListSessions returns a map of all sessions in the database, keyed by the
+// token ID.
+func (f *fileService) ListSessions() map[string]defs.LogonSession {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	result := make(map[string]defs.LogonSession, len(f.sessions))
+	for id, session := range f.sessions {
+		result[id] = session
+	}
+
+	return result
+}
+
+// ReadSession returns a session from the database.
+func (f *fileService) ReadSession(id string) (defs.LogonSession, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	session, ok := f.sessions[id]
+	if !ok {
+		return session, errors.ErrNoSuchSession.Context(id)
+	}
+
+	return session, nil
+}
+
+// WriteSession adds or updates a session in the database.
+func (f *fileService) WriteSession(session defs.LogonSession) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.sessions[session.ID.String()] = session
+
+	return f.writeSessions()
+}
+
+// DeleteSession removes a session from the database.
+func (f *fileService) DeleteSession(id string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, found := f.sessions[id]; !found {
+		return errors.ErrNoSuchSession.Context(id)
+	}
+
+	delete(f.sessions, id)
+
+	return f.writeSessions()
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/resources"
+)
+
+// openSessions opens the resource handle for the sessions table, and creates the
+// table if it does not yet exist.
+func (pg *databaseService) openSessions(connStr string) error {
+	var err error
+
+	if pg.sessionHandle, err = resources.Open(defs.LogonSession{}, "sessions", connStr); err != nil {
+		return err
+	}
+
+	return pg.sessionHandle.CreateIf()
+}
+
+// ListSessions returns a map of all sessions in the database, keyed by the
+// token ID.
+func (pg *databaseService) ListSessions() map[string]defs.LogonSession {
+	r := map[string]defs.LogonSession{}
+
+	rowSet, err := pg.sessionHandle.Begin().Read()
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return r
+	}
+
+	for _, row := range rowSet {
+		session := row.(*defs.LogonSession)
+		r[session.ID.String()] = *session
+	}
+
+	return r
+}
+
+// ReadSession returns a session from the database.
+func (pg *databaseService) ReadSession(id string) (defs.LogonSession, error) {
+	rowSet, err := pg.sessionHandle.Begin().Read(pg.sessionHandle.Equals("id", id))
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return defs.LogonSession{}, errors.New(err)
+	}
+
+	if len(rowSet) == 0 {
+		return defs.LogonSession{}, errors.ErrNoSuchSession.Context(id)
+	}
+
+	return *rowSet[0].(*defs.LogonSession), nil
+}
+
+// WriteSession adds or updates a session in the database.
+func (pg *databaseService) WriteSession(session defs.LogonSession) error {
+	var err error
+
+	if _, err = pg.ReadSession(session.ID.String()); err == nil {
+		err = pg.sessionHandle.Begin().Update(session, pg.sessionHandle.Equals("id", session.ID.String()))
+	} else {
+		err = pg.sessionHandle.Begin().Insert(session)
+	}
+
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// DeleteSession removes a session from the database.
+func (pg *databaseService) DeleteSession(id string) error {
+	count, err := pg.sessionHandle.Begin().Delete(pg.sessionHandle.Equals("id", id))
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return errors.New(err)
+	}
+
+	if count == 0 {
+		return errors.ErrNoSuchSession.Context(id)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/builtins"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/runtime"
+	"github.com/tucats/ego/symbols"
+)
+
+// newTestToken issues a token for the user, and records a session for it.
+func newTestToken(t *testing.T, user string, family uuid.UUID) (string, string) {
+	s := symbols.NewSymbolTable("test")
+	runtime.AddPackages(s)
+
+	token, err := builtins.CallBuiltin(s, "cipher.New", user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refresh, err := NewSession(data.String(token), "127.0.0.1", family)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data.String(token), refresh
+}
+
+func TestRevokeSession(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	first, refresh := newTestToken(t, "staff", uuid.Nil)
+	second, _ := newTestToken(t, "staff", uuid.Nil)
+	other, _ := newTestToken(t, "payroll", uuid.Nil)
+
+	if refresh != "" {
+		t.Errorf("NewSession() issued a refresh token when they are not enabled")
+	}
+
+	if !ValidateToken(first) || !ValidateToken(second) {
+		t.Fatal("ValidateToken() did not accept the new tokens")
+	}
+
+	sessions := Sessions("staff")
+	if len(sessions) != 2 || len(Sessions("")) != 3 {
+		t.Fatalf("Sessions() = %v", sessions)
+	}
+
+	if _, err := RevokeSession(sessions[0].ID.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	if ValidateToken(first) || !ValidateToken(second) {
+		t.Error("RevokeSession() did not revoke only the given token")
+	}
+
+	if len(Sessions("staff")) != 1 {
+		t.Errorf("Sessions() includes the revoked session")
+	}
+
+	revoked, err := RevokeSessions("staff")
+	if err != nil || len(revoked) != 1 {
+		t.Fatalf("RevokeSessions() = %v, %v", revoked, err)
+	}
+
+	if ValidateToken(second) || !ValidateToken(other) {
+		t.Error("RevokeSessions() did not revoke only the tokens for the user")
+	}
+
+	if _, err := RevokeSession(uuid.New().String()); !errors.Equals(err, errors.ErrNoSuchSession) {
+		t.Errorf("RevokeSession() of unknown session = %v", err)
+	}
+}
+
+func TestRefreshSession(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	settings.SetDefault(defs.ServerTokenRefreshSetting, "1h")
+	defer settings.SetDefault(defs.ServerTokenRefreshSetting, "")
+
+	token, refresh := newTestToken(t, "staff", uuid.Nil)
+	if refresh == "" {
+		t.Fatal("NewSession() did not issue a refresh token")
+	}
+
+	session, err := RefreshSession(refresh)
+	if err != nil || session.Name != "staff" {
+		t.Fatalf("RefreshSession() = %v, %v", session, err)
+	}
+
+	// The new token is part of the same family as the original logon.
+	next, _ := newTestToken(t, "staff", session.Family)
+
+	if !ValidateToken(token) || !ValidateToken(next) {
+		t.Error("ValidateToken() did not accept the tokens")
+	}
+
+	// Using the refresh token again revokes every token from the logon.
+	if _, err := RefreshSession(refresh); !errors.Equals(err, errors.ErrInvalidRefreshToken) {
+		t.Errorf("RefreshSession() of used token = %v", err)
+	}
+
+	if ValidateToken(token) || ValidateToken(next) {
+		t.Error("reuse of the refresh token did not revoke the tokens")
+	}
+
+	if _, err := RefreshSession("bogus"); !errors.Equals(err, errors.ErrInvalidRefreshToken) {
+		t.Errorf("RefreshSession() of unknown token = %v", err)
+	}
+
+	// A user who cannot log on cannot use a refresh token.
+	_, refresh = newTestToken(t, "bogus", uuid.Nil)
+	if _, err := RefreshSession(refresh); !errors.Equals(err, errors.ErrNoPermission) {
+		t.Errorf("RefreshSession() for user without logon = %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/audit"
+)
+
+const (
+	// The issuer shown by authenticator apps for the one-time codes.
+	totpIssuer = "Ego"
+
+	// The number of seconds each one-time code is valid, and the number of
+	// digits in the code. These are the defaults used by authenticator apps.
+	totpPeriod = 30
+	totpDigits = 6
+
+	// The size in bytes of the secret used to generate the codes.
+	totpSecretSize = 20
+
+	// The number of recovery codes issued when a user is enrolled.
+	recoveryCodeCount = 10
+)
+
+// totpEncoding is the encoding of the secret in the provisioning URI.
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// EnrollTOTP enrolls a user for time-based one-time codes (RFC 6238) as a second
+// logon factor. A new secret is created, replacing any previous enrollment. The
+// result is the provisioning URI used to configure an authenticator app, and the
+// recovery codes that can each be used once instead of a one-time code.
+func EnrollTOTP(name string) (string, []string, error) {
+	if _, err := AuthService.ReadUser(name, false); err != nil {
+		return "", nil, err
+	}
+
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, errors.New(err)
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		b := make([]byte, 5)
+		_, _ = rand.Read(b)
+
+		text := strings.ToLower(totpEncoding.EncodeToString(b))
+		codes[i] = text[:4] + "-" + text[4:]
+		hashes[i] = hashRefresh(codes[i])
+	}
+
+	passwordMutex.Lock()
+	defer passwordMutex.Unlock()
+
+	state, err := AuthService.ReadPasswordState(name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	state.TOTP = totpEncoding.EncodeToString(secret)
+	state.TOTPStep = 0
+	state.Recovery = hashes
+
+	if err := AuthService.WritePasswordState(state); err != nil {
+		return "", nil, err
+	}
+
+	audit.Record("totp.enroll", ui.A{
+		"user": name})
+
+	uri := url.URL{
+		Scheme: "otpauth",
+		Host:   "totp",
+		Path:   "/" + totpIssuer + ":" + name,
+		RawQuery: url.Values{
+			"secret": []string{state.TOTP},
+			"issuer": []string{totpIssuer},
+		}.Encode(),
+	}
+
+	return uri.String(), codes, nil
+}
+
+// ResetTOTP removes the enrollment of a user for one-time codes, along with any
+// unused recovery codes. The user can then log in with only a password.
+func ResetTOTP(name string) error {
+	if _, err := AuthService.ReadUser(name, false); err != nil {
+		return err
+	}
+
+	passwordMutex.Lock()
+	defer passwordMutex.Unlock()
+
+	state, err := AuthService.ReadPasswordState(name)
+	if err != nil {
+		return err
+	}
+
+	state.TOTP = ""
+	state.TOTPStep = 0
+	state.Recovery = nil
+
+	if err := AuthService.WritePasswordState(state); err != nil {
+		return err
+	}
+
+	audit.Record("totp.reset", ui.A{
+		"user": name})
+
+	return nil
+}
+
+// TOTPEnrolled reports if a user must provide a one-time code to log in.
+func TOTPEnrolled(name string) bool {
+	passwordMutex.Lock()
+	defer passwordMutex.Unlock()
+
+	state, err := AuthService.ReadPasswordState(name)
+
+	return err == nil && state.TOTP != ""
+}
+
+// ValidateCode checks the one-time code for a user, which may also be one of the
+// user's unused recovery codes. A code cannot be used more than once. A code that
+// is not valid is counted as a failed logon, and no code is accepted while the
+// account is locked.
+func ValidateCode(name, code string) bool {
+	now := time.Now()
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+
+	passwordMutex.Lock()
+	defer passwordMutex.Unlock()
+
+	state, err := AuthService.ReadPasswordState(name)
+	if err != nil || state.TOTP == "" {
+		return false
+	}
+
+	if state.LockedUntil != "" && !sessionExpired(state.LockedUntil, now) {
+		audit.Record("logon.locked.out", ui.A{
+			"user":  name,
+			"until": state.LockedUntil})
+
+		return false
+	}
+
+	secret, err := totpEncoding.DecodeString(state.TOTP)
+	if err != nil {
+		return false
+	}
+
+	// Allow for a clock difference of one period between the client and server.
+	step := int(now.Unix() / totpPeriod)
+	for s := step - 1; s <= step+1; s++ {
+		if s > state.TOTPStep && subtle.ConstantTimeCompare([]byte(totpCode(secret, s)), []byte(code)) == 1 {
+			state.TOTPStep = s
+			countLogon(&state, true, now)
+
+			return writeCodeState(state)
+		}
+	}
+
+	hash := hashRefresh(code)
+	for i, recovery := range state.Recovery {
+		if subtle.ConstantTimeCompare([]byte(recovery), []byte(hash)) == 1 {
+			state.Recovery = append(state.Recovery[:i:i], state.Recovery[i+1:]...)
+			countLogon(&state, true, now)
+
+			audit.Record("totp.recovery", ui.A{
+				"user":  name,
+				"count": len(state.Recovery)})
+
+			return writeCodeState(state)
+		}
+	}
+
+	audit.Record("logon.code", ui.A{
+		"user": name})
+
+	countLogon(&state, false, now)
+	_ = writeCodeState(state)
+
+	return false
+}
+
+// writeCodeState writes the password state after a one-time code was used, and
+// returns true if it was written. The caller must hold the password mutex.
+func writeCodeState(state defs.PasswordState) bool {
+	if err := AuthService.WritePasswordState(state); err != nil {
+		ui.Log(ui.AuthLogger, "auth.error", ui.A{
+			"error": err})
+
+		return false
+	}
+
+	return true
+}
+
+// totpCode returns the one-time code for a secret and time step, using HMAC-SHA1
+// as described in RFC 4226.
+func totpCode(secret []byte, step int) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+
+	h := hmac.New(sha1.New, secret)
+	_, _ = h.Write(counter)
+	sum := h.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
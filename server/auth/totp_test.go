@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/defs"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// Test values from RFC 6238, truncated to six digits.
+	secret := []byte("12345678901234567890")
+
+	tests := []struct {
+		seconds int64
+		code    string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		if code := totpCode(secret, int(tt.seconds/totpPeriod)); code != tt.code {
+			t.Errorf("totpCode(%d) = %s, want %s", tt.seconds, code, tt.code)
+		}
+	}
+}
+
+func TestCodeLockout(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	settings.SetDefault(defs.ServerLockoutCountSetting, "3")
+	defer settings.SetDefault(defs.ServerLockoutCountSetting, "")
+
+	if _, _, err := EnrollTOTP("staff"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The correct password does not reset the count of wrong codes, so the account is
+	// locked after three of them.
+	for i := 0; i < 3; i++ {
+		if !ValidatePassword("staff", "quidditch") {
+			t.Fatalf("ValidatePassword() failed before the account was locked, attempt %d", i+1)
+		}
+
+		if ValidateCode("staff", "000000x") {
+			t.Fatal("ValidateCode() accepted an invalid code")
+		}
+	}
+
+	if ValidatePassword("staff", "quidditch") {
+		t.Error("ValidatePassword() accepted a logon for a locked account")
+	}
+
+	state, _ := AuthService.ReadPasswordState("staff")
+	secret, _ := totpEncoding.DecodeString(state.TOTP)
+
+	if ValidateCode("staff", totpCode(secret, int(time.Now().Unix()/totpPeriod))) {
+		t.Error("ValidateCode() accepted a code for a locked account")
+	}
+}
+
+func TestValidateCode(t *testing.T) {
+	setupTestAuthService(t)
+	defer teardownTestAuthService(t, true)
+
+	if TOTPEnrolled("staff") {
+		t.Fatal("TOTPEnrolled() is true before enrollment")
+	}
+
+	uri, recovery, err := EnrollTOTP("staff")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(uri, "otpauth://totp/Ego:staff?") || len(recovery) != recoveryCodeCount || !TOTPEnrolled("staff") {
+		t.Fatalf("EnrollTOTP() = %s, %v", uri, recovery)
+	}
+
+	state, _ := AuthService.ReadPasswordState("staff")
+	secret, _ := totpEncoding.DecodeString(state.TOTP)
+	code := totpCode(secret, int(time.Now().Unix()/totpPeriod))
+
+	if !ValidateCode("staff", code) {
+		t.Error("ValidateCode() did not accept the current code")
+	}
+
+	if ValidateCode("staff", code) {
+		t.Error("ValidateCode() accepted a code that was already used")
+	}
+
+	if ValidateCode("staff", "000000x") || ValidateCode("payroll", code) {
+		t.Error("ValidateCode() accepted an invalid code")
+	}
+
+	// Each recovery code can only be used once.
+	if !ValidateCode("staff", strings.ToUpper(recovery[3])) || ValidateCode("staff", recovery[3]) {
+		t.Error("ValidateCode() did not accept a recovery code only once")
+	}
+
+	if err := ResetTOTP("staff"); err != nil || TOTPEnrolled("staff") {
+		t.Errorf("ResetTOTP() did not remove the enrollment, %v", err)
+	}
+
+	if _, _, err := EnrollTOTP("nobody"); err == nil {
+		t.Error("EnrollTOTP() of unknown user did not fail")
+	}
+}
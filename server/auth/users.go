@@ -13,6 +13,7 @@ import (
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/egostrings"
+	"github.com/tucats/ego/runtime/cipher"
 )
 
 type userIOService interface {
@@ -20,6 +21,25 @@ type userIOService interface {
 	WriteUser(user defs.User) error
 	DeleteUser(name string) error
 	ListUsers() map[string]defs.User
+	ReadRole(name string) (defs.Role, error)
+	WriteRole(role defs.Role) error
+	DeleteRole(name string) error
+	ListRoles() map[string]defs.Role
+	ReadGroup(name string) (defs.Group, error)
+	WriteGroup(group defs.Group) error
+	DeleteGroup(name string) error
+	ListGroups() map[string]defs.Group
+	ReadSession(id string) (defs.LogonSession, error)
+	WriteSession(session defs.LogonSession) error
+	DeleteSession(id string) error
+	ListSessions() map[string]defs.LogonSession
+	ReadPasswordState(name string) (defs.PasswordState, error)
+	WritePasswordState(state defs.PasswordState) error
+	DeletePasswordState(name string) error
+	ReadAPIKey(id string) (defs.APIKey, error)
+	WriteAPIKey(key defs.APIKey) error
+	DeleteAPIKey(id string) error
+	ListAPIKeys() map[string]defs.APIKey
 	Flush() error
 }
 
@@ -32,7 +52,7 @@ var AuthService userIOService
 var (
 	userDatabaseFile = ""
 	agingMutex       sync.Mutex
-	aging            map[string]time.Time
+	aging            = map[string]time.Time{}
 )
 
 // Initialize uses command line options to locate and load the authorized users
@@ -92,6 +112,13 @@ func Initialize(c *cli.Context) error {
 
 	AuthService, err = defineCredentialService(userDatabaseFile, defaultUser, defaultPassword)
 
+	// JWT tokens issued by the server include the permissions of the user.
+	cipher.TokenPermissions = func(user string) []string {
+		permissions, _ := EffectivePermissions(user)
+
+		return permissions
+	}
+
 	// If there is a --superuser specified on the command line, or in the persistent profile data,
 	// mark that user as having ROOT privileges
 	su, ok := c.String("superuser")
@@ -111,7 +138,8 @@ func Initialize(c *cli.Context) error {
 
 // defineCredentialService creates a new instance of a credential service
 // based on the path provided. If the path is a database URL, a database
-// service is created. Otherwise, a file-based service is created.
+// service is created. If the path is an LDAP URL, a directory service is
+// created. Otherwise, a file-based service is created.
 func defineCredentialService(path, user, password string) (userIOService, error) {
 	var err error
 
@@ -119,6 +147,8 @@ func defineCredentialService(path, user, password string) (userIOService, error)
 
 	if isDatabaseURL(path) {
 		AuthService, err = NewDatabaseService(path, user, password)
+	} else if isLDAPURL(path) {
+		AuthService, err = NewLDAPService(path, user, password)
 	} else {
 		AuthService, err = NewFileService(path, user, password)
 	}
@@ -141,6 +171,13 @@ func isDatabaseURL(path string) bool {
 	return false
 }
 
+// Utility function to determine if a given path is an LDAP directory URL.
+func isLDAPURL(path string) bool {
+	path = strings.ToLower(path)
+
+	return strings.HasPrefix(path, "ldap://") || strings.HasPrefix(path, "ldaps://")
+}
+
 // Go routine that runs periodically to see if credentials should be
 // aged out of the user store. Runs every 180 seconds by default, but
 // this can be overridden with the "ego.server.auth.cache.scan" setting.
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/tucats/ego/app-cli/settings"
 	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/caches"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
 	"github.com/tucats/ego/util"
@@ -19,6 +20,34 @@ type fileService struct {
 	dirty bool
 	lock  sync.Mutex
 	data  map[string]defs.User
+
+	// The roles and groups are stored in a separate file, alongside the
+	// file containing the user data.
+	rolesPath string
+	roles     map[string]defs.Role
+	groups    map[string]defs.Group
+
+	// The sessions for the tokens issued by the server are stored in their
+	// own file, which is written whenever a session changes.
+	sessionsPath string
+	sessions     map[string]defs.LogonSession
+
+	// The password state for each user is stored in its own file, which is
+	// written whenever the state changes.
+	passwordsPath string
+	passwords     map[string]defs.PasswordState
+
+	// The API keys are stored in their own file, which is written whenever a
+	// key changes.
+	apiKeysPath string
+	apiKeys     map[string]defs.APIKey
+}
+
+// fileRoles is the representation of the roles and groups stored in the
+// file system.
+type fileRoles struct {
+	Roles  map[string]defs.Role  `json:"roles"`
+	Groups map[string]defs.Group `json:"groups"`
 }
 
 // NewFileService creates a new user service that uses a file-based
@@ -34,9 +63,14 @@ func NewFileService(userDatabaseFile, defaultUser, defaultPassword string) (user
 	}
 
 	svc := &fileService{
-		path:  userDatabaseFile,
-		dirty: false,
-		data:  map[string]defs.User{},
+		path:      userDatabaseFile,
+		dirty:     false,
+		data:      map[string]defs.User{},
+		roles:     map[string]defs.Role{},
+		groups:    map[string]defs.Group{},
+		sessions:  map[string]defs.LogonSession{},
+		passwords: map[string]defs.PasswordState{},
+		apiKeys:   map[string]defs.APIKey{},
 	}
 
 	// If there is a backing store file, attempt to read the data form it. If the data
@@ -67,17 +101,38 @@ func NewFileService(userDatabaseFile, defaultUser, defaultPassword string) (user
 			ui.Log(ui.AuthLogger, "auth.file.size", ui.A{
 				"size": len(svc.data)})
 		}
+
+		if err := svc.readRoles(); err != nil {
+			return svc, err
+		}
+
+		if err := svc.readSessions(); err != nil {
+			return svc, err
+		}
+
+		if err := svc.readPasswords(); err != nil {
+			return svc, err
+		}
+
+		if err := svc.readAPIKeys(); err != nil {
+			return svc, err
+		}
 	}
 
 	// Construct the map of user definitions in memory if not already read from
 	// the JSON file data. This includes creating an entry for the default user,
 	// so there is always at least one credential that can be used to log in.
 	if len(svc.data) == 0 {
+		hash, err := HashPassword(defaultPassword)
+		if err != nil {
+			return svc, err
+		}
+
 		svc.data = map[string]defs.User{
 			defaultUser: {
 				ID:          uuid.New(),
 				Name:        defaultUser,
-				Password:    HashString(defaultPassword),
+				Password:    hash,
 				Permissions: []string{"root", "logon"},
 			},
 		}
@@ -127,6 +182,8 @@ func (f *fileService) WriteUser(user defs.User) error {
 	f.data[user.Name] = user
 	f.dirty = true
 
+	caches.Delete(caches.PermissionCache, user.Name)
+
 	if found {
 		ui.Log(ui.AuthLogger, "auth.user.update", ui.A{
 			"user": user.Name})
@@ -149,6 +206,13 @@ func (f *fileService) DeleteUser(name string) error {
 		delete(f.data, u.Name)
 		f.dirty = true
 
+		if _, found := f.passwords[u.Name]; found {
+			delete(f.passwords, u.Name)
+			_ = f.writePasswords()
+		}
+
+		caches.Delete(caches.PermissionCache, u.Name)
+
 		ui.Log(ui.AuthLogger, "auth.user.delete", ui.A{
 			"user": u.Name})
 	}
@@ -190,14 +254,18 @@ func (f *fileService) Flush() error {
 
 	// Write to the database file. The file is created with 0600 permissions
 	// so that it is only readable by the owner.
-	err = os.WriteFile(userDatabaseFile, b, 0600)
-	if err == nil {
-		f.dirty = false
+	if err = os.WriteFile(f.path, b, 0600); err != nil {
+		return errors.New(err)
+	}
 
-		ui.Log(ui.AuthLogger, "auth.flush")
-	} else {
-		err = errors.New(err)
+	// The roles and groups are written to their own file.
+	if err = f.writeRoles(); err != nil {
+		return err
 	}
 
-	return err
+	f.dirty = false
+
+	ui.Log(ui.AuthLogger, "auth.flush")
+
+	return nil
 }
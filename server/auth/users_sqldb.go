@@ -12,8 +12,13 @@ import (
 )
 
 type databaseService struct {
-	constr     string
-	userHandle *resources.ResHandle
+	constr         string
+	userHandle     *resources.ResHandle
+	roleHandle     *resources.ResHandle
+	groupHandle    *resources.ResHandle
+	sessionHandle  *resources.ResHandle
+	passwordHandle *resources.ResHandle
+	apiKeyHandle   *resources.ResHandle
 }
 
 // NewDatabaseService creates a new user service that uses a database to store user information.
@@ -41,12 +46,50 @@ func NewDatabaseService(connStr, defaultUser, defaultPassword string) (userIOSer
 		return nil, errors.New(err)
 	}
 
+	// Create the tables for the roles and groups if they do not yet exist.
+	if err = svc.openRoles(connStr); err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return nil, errors.New(err)
+	}
+
+	// Create the table for the sessions if it does not yet exist.
+	if err = svc.openSessions(connStr); err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return nil, errors.New(err)
+	}
+
+	// Create the table for the password state if it does not yet exist.
+	if err = svc.openPasswords(connStr); err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return nil, errors.New(err)
+	}
+
+	// Create the table for the API keys if it does not yet exist.
+	if err = svc.openAPIKeys(connStr); err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return nil, errors.New(err)
+	}
+
 	// Does the default user already exist? If not, create it.
 	_, err = svc.ReadUser(defaultUser, true)
 	if err != nil {
+		var hash string
+
+		if hash, err = HashPassword(defaultPassword); err != nil {
+			return nil, err
+		}
+
 		user := defs.User{
 			Name:        defaultUser,
-			Password:    HashString(defaultPassword),
+			Password:    hash,
 			ID:          uuid.New(),
 			Permissions: []string{"root", "logon"},
 		}
@@ -146,6 +189,7 @@ func (pg *databaseService) WriteUser(user defs.User) error {
 	var update string
 
 	caches.Delete(caches.AuthCache, user.Name)
+	caches.Delete(caches.PermissionCache, user.Name)
 
 	_, err := pg.ReadUser(user.Name, false)
 	if err == nil {
@@ -175,6 +219,7 @@ func (pg *databaseService) DeleteUser(name string) error {
 
 	// Make sure the item no longer exists in the short-term cache.
 	caches.Delete(caches.AuthCache, name)
+	caches.Delete(caches.PermissionCache, name)
 
 	count, err := pg.userHandle.Begin().Delete(pg.userHandle.Equals("name", name))
 	if err != nil {
@@ -184,6 +229,8 @@ func (pg *databaseService) DeleteUser(name string) error {
 		err = errors.New(err)
 	} else {
 		if count > 0 {
+			_ = pg.DeletePasswordState(name)
+
 			ui.Log(ui.AuthLogger, "auth.user.delete", ui.A{
 				"user": name})
 		} else {
@@ -4,42 +4,103 @@ import (
 	"crypto/sha256"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tucats/ego/app-cli/settings"
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/builtins"
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/jwt"
 	"github.com/tucats/ego/runtime"
+	"github.com/tucats/ego/runtime/cipher"
+	"github.com/tucats/ego/server/audit"
 	"github.com/tucats/ego/symbols"
 )
 
 // ValidatePassword checks a username and password against the database and
-// returns true if the user exists and the password is valid.
+// returns true if the user exists and the password is valid. Each logon is
+// recorded in the audit log. Failed logons are counted so the account can be
+// locked, and a password stored in an older form is hashed again.
 func ValidatePassword(user, pass string) bool {
-	ok := false
+	u, err := AuthService.ReadUser(user, false)
+	if err != nil {
+		audit.Record("logon.unknown", ui.A{
+			"user": user})
+
+		return false
+	}
 
-	if u, userExists := AuthService.ReadUser(user, false); userExists == nil {
-		realPass := u.Password
-		// If the password in the database is quoted, do a local hash
-		if strings.HasPrefix(realPass, "{") && strings.HasSuffix(realPass, "}") {
-			realPass = HashString(realPass[1 : len(realPass)-1])
+	now := time.Now()
+	if locked, until := accountLocked(u.Name, now); locked {
+		audit.Record("logon.locked.out", ui.A{
+			"user":  u.Name,
+			"until": until})
+
+		return false
+	}
+
+	ok, rehash := checkPassword(u.Password, pass)
+
+	// A user read from an LDAP directory is validated by binding to the directory.
+	if directory, found := AuthService.(*ldapService); found {
+		if fromDirectory, valid := directory.authenticate(u.Name, pass); fromDirectory {
+			ok, rehash = valid, false
 		}
+	}
 
-		hashPass := HashString(pass)
-		ok = realPass == hashPass
+	if !ok {
+		recordLogon(u.Name, false, now)
+		audit.Record("logon.password", ui.A{
+			"user": u.Name})
+
+		return false
+	}
 
-		if findPermission(u, "root") < 0 && findPermission(u, "logon") < 0 {
-			ok = false
+	if !GetPermission(user, "root") && !GetPermission(user, "logon") {
+		audit.Record("logon.permission", ui.A{
+			"user": u.Name})
+
+		return false
+	}
+
+	// A user enrolled for one-time codes has not logged on until the code is checked,
+	// so the count of failed logons is only reset when ValidateCode() accepts it.
+	if !TOTPEnrolled(u.Name) {
+		recordLogon(u.Name, true, now)
+	}
+
+	if rehash {
+		if hash, err := HashPassword(pass); err == nil {
+			u.Password = hash
+			if err := AuthService.WriteUser(u); err == nil {
+				_ = AuthService.Flush()
+
+				ui.Log(ui.AuthLogger, "auth.password.rehash", ui.A{
+					"user": u.Name})
+			}
 		}
 	}
 
-	return ok
+	audit.Record("logon.success", ui.A{
+		"user": u.Name})
+
+	return true
 }
 
 // validateToken is a helper function that calls the builtin cipher.Validate().
 func ValidateToken(t string) bool {
-	// Are we an authority? If not, let's see who is.
+	// A token issued by the OpenID Connect identity provider is validated using
+	// the keys published by the provider. JWTs issued by this server are handled
+	// by the cipher package like any other Ego token.
+	if jwt.IsJWT(t) && OIDCEnabled() && !cipher.IsServerJWT(t) {
+		_, err := OIDCTokenUser(t)
+
+		return err == nil
+	}
+
+	// Are we an authority? If not, let's see who is. The authority rejects the
+	// token if it has been revoked.
 	authServer := settings.Get(defs.ServerAuthoritySetting)
 	if authServer != "" {
 		_, err := remoteUser(authServer, t)
@@ -59,11 +120,12 @@ func ValidateToken(t string) bool {
 		return false
 	}
 
-	if v == nil {
+	if v == nil || !data.BoolOrFalse(v) {
 		return false
 	}
 
-	return data.BoolOrFalse(v)
+	// The token is valid, but it may have been revoked before it expired.
+	return !TokenRevoked(t)
 }
 
 // HashString converts a given string to it's hash. This is used to manage
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -54,6 +55,13 @@ func setupTestAuthService(t *testing.T) {
 func teardownTestAuthService(t *testing.T, ignoreErrors bool) {
 	AuthService = savedAuthService
 
+	// Remove the files of roles, groups, sessions, password state and API keys written
+	// alongside the user data, if any.
+	_ = os.Remove(strings.TrimSuffix(testFile, ".json") + "_roles.json")
+	_ = os.Remove(strings.TrimSuffix(testFile, ".json") + "_sessions.json")
+	_ = os.Remove(strings.TrimSuffix(testFile, ".json") + "_passwords.json")
+	_ = os.Remove(strings.TrimSuffix(testFile, ".json") + "_apikeys.json")
+
 	err := os.Remove(testFile)
 	if !ignoreErrors && err != nil {
 		t.Fatalf("Failed to remove test file: %v", err)
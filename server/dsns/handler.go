@@ -12,6 +12,7 @@ import (
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/audit"
 	"github.com/tucats/ego/server/server"
 	"github.com/tucats/ego/util"
 )
@@ -443,6 +444,18 @@ func DSNPermissionsHandler(session *server.Session, w http.ResponseWriter, r *ht
 			if err := DSNService.GrantDSN(item.User, item.DSN, action, grant); err != nil {
 				return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
 			}
+
+			event := "dsn.grant"
+			if !grant {
+				event = "dsn.revoke"
+			}
+
+			audit.Record(event, ui.A{
+				"session": session.ID,
+				"user":    session.User,
+				"name":    item.User,
+				"dsn":     item.DSN,
+				"action":  strings.ToLower(actionName)})
 		}
 	}
 
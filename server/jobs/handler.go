@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/audit"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/util"
+)
+
+// ListJobsHandler is the handler for the GET method on the jobs endpoint. It returns
+// the schedule and state of each job, and its most recent run.
+func ListJobsHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	return writeJobs(session, w, List())
+}
+
+// GetJobHandler is the handler for the GET method on the jobs endpoint with a job
+// name provided in the path. The result includes the history of the job's most
+// recent runs.
+func GetJobHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	job, err := Get(data.String(session.URLParts["name"]))
+	if err != nil {
+		return jobError(session, w, err)
+	}
+
+	return writeJobs(session, w, []defs.Job{job})
+}
+
+// RunJobHandler is the handler for the POST method on the jobs endpoint with a job
+// name provided in the path. It starts the job now, even if it is paused. If the
+// "wait" parameter is given, the response is sent after the job ends.
+func RunJobHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	name := data.String(session.URLParts["name"])
+	_, wait := session.Parameters["wait"]
+
+	job, err := Run(name, session.User, wait)
+	if err != nil {
+		return jobError(session, w, err)
+	}
+
+	audit.Record("job.run", ui.A{
+		"session": session.ID,
+		"user":    session.User,
+		"name":    name})
+
+	return writeJobs(session, w, []defs.Job{job})
+}
+
+// UpdateJobHandler is the handler for the PATCH method on the jobs endpoint with a
+// job name provided in the path. The payload is a job whose "paused" field sets
+// whether the job is run on its schedule.
+func UpdateJobHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	request := defs.Job{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ui.Log(ui.RestLogger, "rest.bad.payload", ui.A{
+			"session": session.ID,
+			"error":   err})
+
+		return util.ErrorResponse(w, session.ID, errors.ErrInvalidRequest.Error(), http.StatusBadRequest)
+	}
+
+	name := data.String(session.URLParts["name"])
+
+	job, err := Pause(name, request.Paused)
+	if err != nil {
+		return jobError(session, w, err)
+	}
+
+	audit.Record("job.update", ui.A{
+		"session": session.ID,
+		"user":    session.User,
+		"name":    name,
+		"paused":  request.Paused})
+
+	return writeJobs(session, w, []defs.Job{job})
+}
+
+// jobError writes the response for an error from a job operation.
+func jobError(session *server.Session, w http.ResponseWriter, err error) int {
+	status := http.StatusInternalServerError
+	if errors.Equals(err, errors.ErrNoSuchJob) {
+		status = http.StatusNotFound
+	}
+
+	return util.ErrorResponse(w, session.ID, err.Error(), status)
+}
+
+// writeJobs writes the response containing a list of jobs.
+func writeJobs(session *server.Session, w http.ResponseWriter, items []defs.Job) int {
+	result := defs.JobCollection{
+		BaseCollection: util.MakeBaseCollection(session.ID),
+		Items:          items,
+	}
+
+	result.Count = len(items)
+	result.Status = http.StatusOK
+
+	w.Header().Add(defs.ContentTypeHeader, defs.JobsMediaType)
+	w.WriteHeader(http.StatusOK)
+
+	b, _ := json.MarshalIndent(result, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+	_, _ = w.Write(b)
+	session.ResponseLength += len(b)
+
+	if ui.IsActive(ui.RestLogger) {
+		ui.WriteLog(ui.RestLogger, "rest.response.payload", ui.A{
+			"session": session.ID,
+			"body":    string(b)})
+	}
+
+	return http.StatusOK
+}
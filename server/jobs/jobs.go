@@ -0,0 +1,338 @@
+// Package jobs runs Ego programs on a schedule inside the server. A job is a program
+// in the lib/jobs directory that starts with a @schedule directive giving a cron-style
+// schedule, such as "0 2 * * *" to run at 2:00 AM every day. Each run of a job has its
+// own symbol table, in the same way as a service. A job is not started again while
+// it is still running, and the most recent runs of each job are kept.
+package jobs
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/tokenizer"
+)
+
+// The number of runs kept in the history of each job.
+const maxHistory = 20
+
+// The trigger of a run started by the scheduler.
+const scheduleTrigger = "schedule"
+
+// job is the state of a single job.
+type job struct {
+	name     string
+	path     string
+	schedule *Schedule
+	paused   bool
+	running  bool
+	next     time.Time
+
+	// The most recent runs, newest first.
+	history []defs.JobRun
+}
+
+var (
+	jobs     = map[string]*job{}
+	jobsLock sync.Mutex
+
+	// The function that runs the program of a job. This is replaced when
+	// testing the scheduler.
+	runProgram = run
+)
+
+// Initialize finds the job programs in the given directory and starts the scheduler
+// that runs them. If the directory does not exist, there are no jobs. A program that
+// does not have a @schedule directive is not a job, and is ignored. A program with
+// a schedule that is not valid is logged and skipped, so the other jobs still run.
+func Initialize(path string) error {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.jobs.none", ui.A{
+			"path":  path,
+			"error": err.Error()})
+
+		return nil
+	}
+
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+
+	now := time.Now()
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != defs.EgoFilenameExtension {
+			continue
+		}
+
+		name := strings.TrimSuffix(file.Name(), defs.EgoFilenameExtension)
+		fileName := filepath.Join(path, file.Name())
+
+		text := getSchedule(fileName)
+		if text == "" {
+			ui.Log(ui.ServerLogger, "server.jobs.no.schedule", ui.A{
+				"path": fileName})
+
+			continue
+		}
+
+		schedule, err := ParseSchedule(text)
+		if err != nil {
+			ui.Log(ui.ServerLogger, "server.jobs.invalid.schedule", ui.A{
+				"path":  fileName,
+				"error": err.Error()})
+
+			continue
+		}
+
+		jobs[name] = &job{
+			name:     name,
+			path:     fileName,
+			schedule: schedule,
+			next:     schedule.Next(now),
+		}
+
+		ui.Log(ui.ServerLogger, "server.jobs.define", ui.A{
+			"name":     name,
+			"schedule": schedule.String()})
+	}
+
+	if len(jobs) > 0 {
+		go scheduler()
+	}
+
+	return nil
+}
+
+// List returns the state of each job, sorted by name.
+func List() []defs.Job {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+
+	names := make([]string, 0, len(jobs))
+	for name := range jobs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	result := make([]defs.Job, len(names))
+	for i, name := range names {
+		result[i] = jobs[name].info(false)
+	}
+
+	return result
+}
+
+// Get returns the state of a job, including the history of its most recent runs.
+func Get(name string) (defs.Job, error) {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+
+	j, found := jobs[name]
+	if !found {
+		return defs.Job{}, errors.ErrNoSuchJob.Context(name)
+	}
+
+	return j.info(true), nil
+}
+
+// Run starts a job now, on behalf of the named user. If wait is true, the result is
+// returned after the job ends. If the job is already running, it is not started again
+// and the run is recorded as skipped.
+func Run(name, user string, wait bool) (defs.Job, error) {
+	jobsLock.Lock()
+
+	j, found := jobs[name]
+	if !found {
+		jobsLock.Unlock()
+
+		return defs.Job{}, errors.ErrNoSuchJob.Context(name)
+	}
+
+	done := j.start(user, time.Now())
+
+	jobsLock.Unlock()
+
+	if wait && done != nil {
+		<-done
+	}
+
+	return Get(name)
+}
+
+// Pause stops a job from being run on its schedule, or resumes running it on its
+// schedule if paused is false. A job that is running is not stopped.
+func Pause(name string, paused bool) (defs.Job, error) {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+
+	j, found := jobs[name]
+	if !found {
+		return defs.Job{}, errors.ErrNoSuchJob.Context(name)
+	}
+
+	j.paused = paused
+
+	return j.info(true), nil
+}
+
+// scheduler runs until the server stops. At the start of each minute, it starts the
+// jobs that are scheduled to run.
+func scheduler() {
+	for {
+		now := time.Now()
+		time.Sleep(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+
+		startScheduledJobs(time.Now())
+	}
+}
+
+// startScheduledJobs starts each job that is not paused whose next scheduled time is
+// not after the given time, and sets the next time it is scheduled to run.
+func startScheduledJobs(now time.Time) {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+
+	for _, j := range jobs {
+		if j.next.IsZero() || j.next.After(now) {
+			continue
+		}
+
+		if !j.paused {
+			j.start(scheduleTrigger, now)
+		}
+
+		j.next = j.schedule.Next(now)
+	}
+}
+
+// start starts a run of the job in its own go routine. The result is a channel that
+// is closed when the run ends, or nil if the job was already running. The caller must
+// hold the jobs lock.
+func (j *job) start(trigger string, now time.Time) chan struct{} {
+	if j.running {
+		ui.Log(ui.ServerLogger, "server.jobs.skipped", ui.A{
+			"name":    j.name,
+			"trigger": trigger})
+
+		j.record(defs.JobRun{
+			Start:   now.Format(time.RFC3339),
+			Status:  defs.JobSkipped,
+			Trigger: trigger,
+		})
+
+		return nil
+	}
+
+	j.running = true
+	done := make(chan struct{})
+	program := runProgram
+
+	go func() {
+		ui.Log(ui.ServerLogger, "server.jobs.start", ui.A{
+			"name":    j.name,
+			"trigger": trigger})
+
+		startTime := time.Now()
+		err := program(j.name, j.path)
+		elapsed := time.Since(startTime)
+
+		result := defs.JobRun{
+			Start:    startTime.Format(time.RFC3339),
+			Duration: elapsed.String(),
+			Status:   defs.JobSuccess,
+			Trigger:  trigger,
+		}
+
+		if err != nil {
+			result.Status = defs.JobFailed
+			result.Error = err.Error()
+
+			ui.Log(ui.ServerLogger, "server.jobs.error", ui.A{
+				"name":     j.name,
+				"duration": result.Duration,
+				"error":    result.Error})
+		} else {
+			ui.Log(ui.ServerLogger, "server.jobs.end", ui.A{
+				"name":     j.name,
+				"duration": result.Duration})
+		}
+
+		jobsLock.Lock()
+		defer jobsLock.Unlock()
+
+		j.record(result)
+		j.running = false
+
+		close(done)
+	}()
+
+	return done
+}
+
+// record adds a run to the history of the job, discarding the oldest run if the
+// history is full. The caller must hold the jobs lock.
+func (j *job) record(entry defs.JobRun) {
+	j.history = append([]defs.JobRun{entry}, j.history...)
+	if len(j.history) > maxHistory {
+		j.history = j.history[:maxHistory]
+	}
+}
+
+// info returns the state of the job. The history is included if requested. The
+// caller must hold the jobs lock.
+func (j *job) info(history bool) defs.Job {
+	result := defs.Job{
+		Name:     j.name,
+		Schedule: j.schedule.String(),
+		Paused:   j.paused,
+		Running:  j.running,
+	}
+
+	if !j.next.IsZero() {
+		result.Next = j.next.Format(time.RFC3339)
+	}
+
+	if len(j.history) > 0 {
+		last := j.history[0]
+		result.Last = &last
+	}
+
+	if history {
+		result.History = append([]defs.JobRun{}, j.history...)
+	}
+
+	return result
+}
+
+// getSchedule returns the text of the @schedule directive in a program file, or an
+// empty string if there is none.
+func getSchedule(fileName string) string {
+	b, err := os.ReadFile(fileName)
+	if err != nil {
+		return ""
+	}
+
+	t := tokenizer.New(string(b), true)
+
+	for !t.IsNext(tokenizer.EndOfTokens) {
+		if t.IsNext(tokenizer.DirectiveToken) && t.NextText() == "schedule" {
+			if schedule := t.Peek(1); schedule.IsString() {
+				return schedule.Spelling()
+			}
+
+			return ""
+		}
+
+		t.Advance(1)
+	}
+
+	return ""
+}
@@ -0,0 +1,226 @@
+package jobs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+)
+
+// setupTestJobs creates a directory of job programs, and replaces the function that
+// runs a job program with one that waits for the release channel to be closed and
+// then returns the given error.
+func setupTestJobs(t *testing.T, release chan struct{}, err error) {
+	path := t.TempDir()
+
+	files := map[string]string{
+		"nightly.ego": "@schedule \"0 2 * * *\"\nfunc main() {}\n",
+		"hourly.ego":  "// Runs every hour.\n@schedule \"@hourly\"\n\nfunc main() {}\n",
+		"helper.ego":  "func main() {}\n",
+		"notes.txt":   "@schedule \"* * * * *\"\n",
+	}
+
+	for name, text := range files {
+		if e := os.WriteFile(filepath.Join(path, name), []byte(text), 0600); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	savedJobs, savedRun := jobs, runProgram
+
+	t.Cleanup(func() {
+		jobs, runProgram = savedJobs, savedRun
+	})
+
+	jobs = map[string]*job{}
+	runProgram = func(name, path string) error {
+		<-release
+
+		return err
+	}
+
+	if e := Initialize(path); e != nil {
+		t.Fatal(e)
+	}
+}
+
+// waitForJob waits for a job that is running to end.
+func waitForJob(t *testing.T, name string) {
+	for i := 0; i < 100; i++ {
+		if job, _ := Get(name); !job.Running {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("job %s did not end", name)
+}
+
+func TestInitialize(t *testing.T) {
+	release := make(chan struct{})
+	close(release)
+
+	setupTestJobs(t, release, nil)
+
+	list := List()
+	if len(list) != 2 {
+		t.Fatalf("List() returned %d jobs, want 2", len(list))
+	}
+
+	if list[0].Name != "hourly" || list[0].Schedule != "@hourly" {
+		t.Errorf("List()[0] = %+v, want hourly job", list[0])
+	}
+
+	if list[1].Name != "nightly" || list[1].Schedule != "0 2 * * *" || list[1].Next == "" {
+		t.Errorf("List()[1] = %+v, want nightly job", list[1])
+	}
+
+	if _, err := Get("helper"); !errors.Equals(err, errors.ErrNoSuchJob) {
+		t.Errorf("Get() of program without a schedule error = %v, want %v", err, errors.ErrNoSuchJob)
+	}
+}
+
+func TestInitializeInvalidSchedule(t *testing.T) {
+	path := t.TempDir()
+	if err := os.WriteFile(filepath.Join(path, "bad.ego"), []byte("@schedule \"0 25 * * *\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "good.ego"), []byte("@schedule \"@daily\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := jobs
+	jobs = map[string]*job{}
+
+	defer func() { jobs = saved }()
+
+	// The job with the invalid schedule is skipped, and the other jobs are defined.
+	if err := Initialize(path); err != nil {
+		t.Errorf("Initialize() unexpected error %v", err)
+	}
+
+	if _, found := jobs["bad"]; found {
+		t.Errorf("Initialize() defined the job with an invalid schedule")
+	}
+
+	if _, found := jobs["good"]; !found {
+		t.Errorf("Initialize() did not define the job with a valid schedule")
+	}
+}
+
+func TestRun(t *testing.T) {
+	release := make(chan struct{})
+	setupTestJobs(t, release, errors.ErrJobExit.Context(3))
+
+	// Start the job, and then try to start it again while it is still running.
+	job, err := Run("nightly", "admin", false)
+	if err != nil {
+		t.Fatalf("Run() unexpected error %v", err)
+	}
+
+	if !job.Running {
+		t.Errorf("Run() job is not running")
+	}
+
+	job, _ = Run("nightly", "admin", false)
+	if job.Last == nil || job.Last.Status != defs.JobSkipped {
+		t.Errorf("Run() of running job last = %+v, want skipped run", job.Last)
+	}
+
+	close(release)
+	waitForJob(t, "nightly")
+
+	job, err = Run("nightly", "admin", true)
+	if err != nil {
+		t.Fatalf("Run() unexpected error %v", err)
+	}
+
+	if job.Running {
+		t.Errorf("Run() with wait job is still running")
+	}
+
+	if job.Last == nil || job.Last.Status != defs.JobFailed || job.Last.Trigger != "admin" || job.Last.Error == "" {
+		t.Errorf("Run() last = %+v, want failed run", job.Last)
+	}
+
+	if _, err := Run("missing", "admin", false); !errors.Equals(err, errors.ErrNoSuchJob) {
+		t.Errorf("Run() of unknown job error = %v, want %v", err, errors.ErrNoSuchJob)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	release := make(chan struct{})
+	close(release)
+
+	setupTestJobs(t, release, nil)
+
+	for i := 0; i < maxHistory+5; i++ {
+		if _, err := Run("hourly", "admin", true); err != nil {
+			t.Fatalf("Run() unexpected error %v", err)
+		}
+	}
+
+	job, _ := Get("hourly")
+	if len(job.History) != maxHistory {
+		t.Errorf("Get() history has %d runs, want %d", len(job.History), maxHistory)
+	}
+
+	if job.Last == nil || job.Last.Status != defs.JobSuccess {
+		t.Errorf("Get() last = %+v, want successful run", job.Last)
+	}
+
+	if list := List(); len(list[0].History) != 0 {
+		t.Errorf("List() includes history")
+	}
+}
+
+func TestPause(t *testing.T) {
+	release := make(chan struct{})
+	close(release)
+
+	setupTestJobs(t, release, nil)
+
+	if _, err := Pause("nightly", true); err != nil {
+		t.Fatalf("Pause() unexpected error %v", err)
+	}
+
+	// A paused job is not started by the scheduler, but is still scheduled.
+	jobsLock.Lock()
+	due := jobs["nightly"].next
+	jobsLock.Unlock()
+
+	startScheduledJobs(due)
+
+	job, _ := Get("nightly")
+	if job.Running || job.Last != nil || job.Next == due.Format(time.RFC3339) {
+		t.Errorf("paused job = %+v, want not run and rescheduled", job)
+	}
+
+	// When resumed, it is started at its next scheduled time.
+	if _, err := Pause("nightly", false); err != nil {
+		t.Fatalf("Pause() unexpected error %v", err)
+	}
+
+	jobsLock.Lock()
+	due = jobs["nightly"].next
+	done := make(chan struct{})
+	runProgram = func(name, path string) error {
+		close(done)
+
+		return nil
+	}
+	jobsLock.Unlock()
+
+	startScheduledJobs(due)
+	<-done
+	waitForJob(t, "nightly")
+
+	if _, err := Pause("missing", true); !errors.Equals(err, errors.ErrNoSuchJob) {
+		t.Errorf("Pause() of unknown job error = %v, want %v", err, errors.ErrNoSuchJob)
+	}
+}
@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"os"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/bytecode"
+	"github.com/tucats/ego/compiler"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/server/services"
+	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/tokenizer"
+)
+
+// run compiles and runs the program of a job, by calling its main function. The
+// program is compiled each time it runs, so changes to the file are used by the
+// next run. A call to os.Exit() with a non-zero status is an error.
+func run(name, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	symbolTable := setupJobSymbols(name)
+
+	services.AddPackages(symbolTable)
+
+	tokens := tokenizer.New(string(b)+"\n@entrypoint "+defs.Main, true)
+	comp := compiler.New("job " + name).SetExtensionsEnabled(true).SetRoot(symbolTable)
+
+	_ = compiler.AddStandard(symbolTable)
+
+	if err := comp.AutoImport(settings.GetBool(defs.AutoImportSetting), symbolTable); err != nil {
+		ui.Log(ui.ServerLogger, "server.jobs.import.error", ui.A{
+			"name":  name,
+			"error": err.Error()})
+	}
+
+	code, err := comp.Compile(name, tokens)
+	_ = comp.Close()
+
+	if err != nil {
+		return err
+	}
+
+	err = bytecode.NewContext(symbolTable, code).Run()
+
+	if errors.Equals(err, errors.ErrExit) {
+		status := 0
+		if e, ok := err.(*errors.Error); ok {
+			status = data.IntOrZero(e.GetContext())
+		}
+
+		if status == 0 {
+			return nil
+		}
+
+		return errors.ErrJobExit.Context(status)
+	}
+
+	if errors.Equals(err, errors.ErrStop) {
+		return nil
+	}
+
+	return err
+}
+
+// setupJobSymbols creates the symbol table for a run of a job. This has the same
+// information about the server as a service, and the user is the one configured for
+// running jobs. The program runs in a child of a new root table, so it does not share
+// the symbols of the server, and the main function has a scope to be called from.
+func setupJobSymbols(name string) *symbols.SymbolTable {
+	symbolTable := symbols.NewChildSymbolTable("job "+name, symbols.NewRootSymbolTable("root for job "+name))
+
+	symbolTable.SetAlways(defs.PidVariable, os.Getpid())
+	symbolTable.SetAlways(defs.InstanceUUIDVariable, defs.InstanceID)
+	symbolTable.SetAlways(defs.ModeVariable, "server")
+	symbolTable.SetAlways(defs.VersionNameVariable, server.Version)
+	symbolTable.SetAlways(defs.StartTimeVariable, server.StartTime)
+	symbolTable.SetAlways("_user", settings.Get(defs.ServerJobsUserSetting))
+	symbolTable.SetAlways("_authenticated", false)
+	symbolTable.SetAlways(defs.SuperUserVariable, false)
+	symbolTable.SetAlways(defs.ExtensionsVariable,
+		settings.GetBool(defs.ExtensionsEnabledSetting))
+
+	if staticTypes := settings.GetUsingList(defs.StaticTypesSetting,
+		defs.Strict,
+		defs.Relaxed,
+		defs.Dynamic,
+	) - 1; staticTypes < defs.StrictTypeEnforcement {
+		symbolTable.SetAlways(defs.TypeCheckingVariable, defs.NoTypeEnforcement)
+	} else {
+		symbolTable.SetAlways(defs.TypeCheckingVariable, staticTypes)
+	}
+
+	return symbolTable
+}
@@ -0,0 +1,229 @@
+package jobs
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tucats/ego/errors"
+)
+
+// Schedule is a parsed cron-style schedule. Each field is a bit mask of the values
+// that match, so bit 5 of the minutes field is set if the job runs at five minutes
+// past the hour.
+type Schedule struct {
+	text     string
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// If either of the day-of-month or day-of-week fields is "*", a day must match
+	// both fields. Otherwise, a day that matches either field is used, in the same
+	// way as cron.
+	anyDay     bool
+	anyWeekday bool
+}
+
+// A schedule field has a range of valid values, and may allow names for values.
+type field struct {
+	name  string
+	min   int
+	max   int
+	names []string
+}
+
+var (
+	minuteField  = field{name: "minute", min: 0, max: 59}
+	hourField    = field{name: "hour", min: 0, max: 23}
+	dayField     = field{name: "day", min: 1, max: 31}
+	monthField   = field{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	weekdayField = field{name: "weekday", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// Shorthand schedules that can be used in place of the five fields.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// The furthest in the future that Next() searches for a matching time. A schedule
+// such as "0 0 30 2 *" never matches.
+const maxScheduleSearch = 5 * 366 * 24 * time.Hour
+
+// ParseSchedule parses a cron-style schedule. This is five fields separated by spaces,
+// giving the minute, hour, day of the month, month, and day of the week when the job
+// runs. Each field can be "*", a number, a range such as "1-5", or a list of these
+// separated by commas. A "/n" suffix on "*" or a range selects every n'th value. The
+// month and day of the week can also be given by the first three letters of their
+// names. The schedule can also be one of "@yearly", "@monthly", "@weekly", "@daily",
+// or "@hourly".
+func ParseSchedule(text string) (*Schedule, error) {
+	spec := strings.ToLower(strings.TrimSpace(text))
+	if macro, found := macros[spec]; found {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.ErrInvalidSchedule.Context(text)
+	}
+
+	s := &Schedule{
+		text:       strings.TrimSpace(text),
+		anyDay:     strings.HasPrefix(fields[2], "*"),
+		anyWeekday: strings.HasPrefix(fields[4], "*"),
+	}
+
+	var err error
+
+	for i, f := range []struct {
+		field *field
+		mask  *uint64
+	}{
+		{&minuteField, &s.minutes},
+		{&hourField, &s.hours},
+		{&dayField, &s.days},
+		{&monthField, &s.months},
+		{&weekdayField, &s.weekdays},
+	} {
+		if *f.mask, err = f.field.parse(fields[i]); err != nil {
+			return nil, errors.ErrInvalidSchedule.Context(text)
+		}
+	}
+
+	// Sunday can be given as 0 or 7.
+	if s.weekdays&(1<<7) != 0 {
+		s.weekdays |= 1
+	}
+
+	return s, nil
+}
+
+// String returns the text of the schedule.
+func (s *Schedule) String() string {
+	return s.text
+}
+
+// Next returns the first time after the given time that matches the schedule. The
+// result has no seconds. If no time within the next five years matches, the result
+// is the zero time.
+func (s *Schedule) Next(t time.Time) time.Time {
+	limit := t.Add(maxScheduleSearch)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for t.Before(limit) {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+
+			continue
+		}
+
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+
+			continue
+		}
+
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+
+			continue
+		}
+
+		if s.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches returns true if the day of the month and the day of the week of the
+// time match the schedule.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	day := s.days&(1<<uint(t.Day())) != 0
+	weekday := s.weekdays&(1<<uint(t.Weekday())) != 0
+
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+
+	return day || weekday
+}
+
+// parse returns the bit mask of the values given by the text of a field.
+func (f *field) parse(text string) (uint64, error) {
+	var mask uint64
+
+	for _, item := range strings.Split(text, ",") {
+		step := 1
+
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n < 1 {
+				return 0, errors.ErrInvalidSchedule.Context(f.name)
+			}
+
+			step = n
+			item = item[:i]
+		}
+
+		low, high := f.min, f.max
+
+		if item != "*" {
+			var err error
+
+			bounds := strings.SplitN(item, "-", 2)
+			if low, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+
+			high = low
+
+			if len(bounds) == 2 {
+				if high, err = f.value(bounds[1]); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				high = f.max
+			}
+
+			if high < low {
+				return 0, errors.ErrInvalidSchedule.Context(f.name)
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+
+	return mask, nil
+}
+
+// value returns the value of a single number or name in a field.
+func (f *field) value(text string) (int, error) {
+	for i, name := range f.names {
+		if text == name {
+			return i + f.min, nil
+		}
+	}
+
+	n, err := strconv.Atoi(text)
+	if err != nil || n < f.min || n > f.max {
+		return 0, errors.ErrInvalidSchedule.Context(f.name)
+	}
+
+	return n, nil
+}
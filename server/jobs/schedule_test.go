@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tucats/ego/errors"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{"every minute", "* * * * *", false},
+		{"daily", "0 2 * * *", false},
+		{"steps", "*/15 9-17/2 * * *", false},
+		{"lists", "0,30 8 1,15 * mon-fri", false},
+		{"names", "0 0 * jan,jul sun", false},
+		{"sunday as seven", "0 0 * * 7", false},
+		{"shorthand", "@hourly", false},
+		{"too few fields", "0 2 * *", true},
+		{"too many fields", "0 2 * * * *", true},
+		{"minute out of range", "60 * * * *", true},
+		{"day out of range", "0 0 0 * *", true},
+		{"bad range", "0 5-2 * * *", true},
+		{"bad step", "*/0 * * * *", true},
+		{"bad name", "0 0 * * someday", true},
+		{"not a number", "x * * * *", true},
+		{"unknown shorthand", "@fortnightly", true},
+		{"empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchedule(tt.text)
+			if tt.wantErr {
+				if !errors.Equals(err, errors.ErrInvalidSchedule) {
+					t.Errorf("ParseSchedule(%q) error = %v, want %v", tt.text, err, errors.ErrInvalidSchedule)
+				}
+			} else if err != nil {
+				t.Errorf("ParseSchedule(%q) unexpected error %v", tt.text, err)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Wednesday, March 15, 2023 at 10:07:30.
+	now := time.Date(2023, time.March, 15, 10, 7, 30, 0, time.Local)
+
+	tests := []struct {
+		name string
+		text string
+		want time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2023, time.March, 15, 10, 8, 0, 0, time.Local)},
+		{"daily tomorrow", "0 2 * * *", time.Date(2023, time.March, 16, 2, 0, 0, 0, time.Local)},
+		{"daily today", "30 22 * * *", time.Date(2023, time.March, 15, 22, 30, 0, 0, time.Local)},
+		{"step", "*/15 * * * *", time.Date(2023, time.March, 15, 10, 15, 0, 0, time.Local)},
+		{"hourly", "@hourly", time.Date(2023, time.March, 15, 11, 0, 0, 0, time.Local)},
+		{"weekday name", "0 9 * * mon", time.Date(2023, time.March, 20, 9, 0, 0, 0, time.Local)},
+		{"sunday as seven", "0 9 * * 7", time.Date(2023, time.March, 19, 9, 0, 0, 0, time.Local)},
+		{"month name", "0 0 1 jun *", time.Date(2023, time.June, 1, 0, 0, 0, 0, time.Local)},
+		{"next year", "0 0 1 1 *", time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local)},
+		{"day or weekday", "0 0 20 * fri", time.Date(2023, time.March, 17, 0, 0, 0, 0, time.Local)},
+		{"leap day", "0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.Local)},
+		{"never", "0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.text)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) unexpected error %v", tt.text, err)
+			}
+
+			if got := s.Next(now); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
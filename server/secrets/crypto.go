@@ -0,0 +1,38 @@
+package secrets
+
+import (
+	"encoding/hex"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/util"
+)
+
+const salt = "9c1e57a2"
+
+// encrypt encrypts the plaintext value of a secret into an encrypted hex string,
+// using the server's private encryption token.
+func encrypt(data string) (string, error) {
+	key := settings.Get(defs.ServerTokenKeySetting) + salt
+
+	b, err := util.Encrypt(data, key)
+	if err != nil {
+		return b, err
+	}
+
+	return hex.EncodeToString([]byte(b)), nil
+}
+
+// decrypt decrypts an encrypted hex string back to the plaintext value of a secret,
+// using the server's private encryption token.
+func decrypt(text string) (string, error) {
+	b, err := hex.DecodeString(text)
+	if err != nil {
+		return "", errors.New(err)
+	}
+
+	key := settings.Get(defs.ServerTokenKeySetting) + salt
+
+	return util.Decrypt(string(b), key)
+}
@@ -0,0 +1,105 @@
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/audit"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/util"
+)
+
+// ListSecretsHandler is the handler for the GET method on the secrets endpoint. It
+// returns the secrets and their access lists. The values are never returned.
+func ListSecretsHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	return writeSecrets(session, w, List())
+}
+
+// SetSecretHandler is the handler for the PUT method on the secrets endpoint with a
+// secret name provided in the path. The payload contains the value and access lists
+// of the secret, which replace any existing secret of the same name. The payload is
+// never written to the log.
+func SetSecretHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	request := defs.Secret{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		ui.Log(ui.RestLogger, "rest.bad.payload", ui.A{
+			"session": session.ID,
+			"error":   err})
+
+		return util.ErrorResponse(w, session.ID, errors.ErrInvalidRequest.Error(), http.StatusBadRequest)
+	}
+
+	request.Name = data.String(session.URLParts["name"])
+
+	secret, err := Set(request)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Equals(err, errors.ErrInvalidRequest) {
+			status = http.StatusBadRequest
+		}
+
+		return util.ErrorResponse(w, session.ID, err.Error(), status)
+	}
+
+	audit.Record("secret.set", ui.A{
+		"session": session.ID,
+		"user":    session.User,
+		"name":    secret.Name,
+		"users":   secret.Users,
+		"roles":   secret.Roles})
+
+	return writeSecrets(session, w, []defs.Secret{secret})
+}
+
+// DeleteSecretHandler is the handler for the DELETE method on the secrets endpoint
+// with a secret name provided in the path.
+func DeleteSecretHandler(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	name := data.String(session.URLParts["name"])
+
+	secret, err := Delete(name)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Equals(err, errors.ErrNoSuchSecret) {
+			status = http.StatusNotFound
+		}
+
+		return util.ErrorResponse(w, session.ID, err.Error(), status)
+	}
+
+	audit.Record("secret.delete", ui.A{
+		"session": session.ID,
+		"user":    session.User,
+		"name":    secret.Name})
+
+	return writeSecrets(session, w, []defs.Secret{secret})
+}
+
+// writeSecrets writes the response containing a list of secrets.
+func writeSecrets(session *server.Session, w http.ResponseWriter, items []defs.Secret) int {
+	result := defs.SecretCollection{
+		BaseCollection: util.MakeBaseCollection(session.ID),
+		Items:          items,
+	}
+
+	result.Count = len(items)
+	result.Status = http.StatusOK
+
+	w.Header().Add(defs.ContentTypeHeader, defs.SecretsMediaType)
+	w.WriteHeader(http.StatusOK)
+
+	b, _ := json.MarshalIndent(result, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+	_, _ = w.Write(b)
+	session.ResponseLength += len(b)
+
+	if ui.IsActive(ui.RestLogger) {
+		ui.WriteLog(ui.RestLogger, "rest.response.payload", ui.A{
+			"session": session.ID,
+			"body":    string(b)})
+	}
+
+	return http.StatusOK
+}
@@ -0,0 +1,272 @@
+// Package secrets manages the named secret values, such as passwords and API keys,
+// that services use to access other systems. The values are encrypted using the
+// server token key, and each secret can only be read by the users and roles in its
+// access list. Secrets are stored alongside the user data, either in a JSON file
+// or in the user database.
+package secrets
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tucats/ego/app-cli/cli"
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/runtime/secrets"
+	"github.com/tucats/ego/server/audit"
+	"github.com/tucats/ego/server/auth"
+	"github.com/tucats/ego/util"
+)
+
+// The secret service interface. This is the interface that must be implemented
+// by any secret service provider, to abstract the storage mechanism (file-based
+// versus database-based) for the secrets.
+type secretService interface {
+	ReadSecret(name string) (defs.Secret, error)
+	WriteSecret(secret defs.Secret) error
+	DeleteSecret(name string) error
+	ListSecrets() map[string]defs.Secret
+	Flush() error
+}
+
+// SecretService stores the specific instance of a service provider for secrets.
+var SecretService secretService
+
+// Initialize locates and loads the secrets, using the same user data location as
+// the authentication service. It also enables the secrets package for services.
+func Initialize(c *cli.Context) error {
+	var err error
+
+	userDatabaseFile, found := c.String("users")
+	if !found {
+		userDatabaseFile = settings.Get(defs.LogonUserdataSetting)
+
+		if userDatabaseFile == "" {
+			userDatabaseFile = defs.DefaultUserdataFileName
+		}
+	}
+
+	ui.Log(ui.AuthLogger, "auth.secrets.init")
+
+	if SecretService, err = defineSecretService(userDatabaseFile); err != nil {
+		return err
+	}
+
+	secrets.Reader = Get
+
+	return nil
+}
+
+// defineSecretService creates a new secret service provider based on the given
+// path. If the path is a database URL, a database service is created. Otherwise,
+// a file service is created, using a file named for the user data file. If the
+// user data is in an LDAP directory, the file is named for the local store.
+func defineSecretService(path string) (secretService, error) {
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "\""), "\"")
+
+	if isDatabaseURL(path) {
+		return NewDatabaseService(path)
+	}
+
+	if strings.Contains(path, "://") {
+		path = settings.Get(defs.ServerLDAPStoreSetting)
+	}
+
+	if path != "" && path != "memory" {
+		ext := filepath.Ext(path)
+		path = strings.TrimSuffix(path, ext) + "_secrets" + ext
+	}
+
+	return NewFileService(path)
+}
+
+// Utility function to determine if a given path is a database URL or
+// not.
+func isDatabaseURL(path string) bool {
+	path = strings.ToLower(path)
+	drivers := []string{"postgres://", "sqlite3://"}
+
+	for _, driver := range drivers {
+		if strings.HasPrefix(path, driver) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Set creates or replaces a secret. The value is encrypted before it is stored.
+// The result is the stored secret, without its value.
+func Set(secret defs.Secret) (defs.Secret, error) {
+	secret.Name = strings.TrimSpace(secret.Name)
+	if secret.Name == "" || strings.ContainsAny(secret.Name, "/?#") {
+		return defs.Secret{}, errors.ErrInvalidRequest.Context(secret.Name)
+	}
+
+	value, err := encrypt(secret.Value)
+	if err != nil {
+		return defs.Secret{}, err
+	}
+
+	secret.Value = value
+	secret.Users = normalize(secret.Users)
+	secret.Roles = normalize(secret.Roles)
+	secret.Modified = time.Now().Format(time.RFC3339)
+
+	if err := SecretService.WriteSecret(secret); err != nil {
+		return defs.Secret{}, err
+	}
+
+	secret.Value = ""
+
+	return secret, SecretService.Flush()
+}
+
+// Delete removes a secret. The result is the deleted secret, without its value.
+func Delete(name string) (defs.Secret, error) {
+	secret, err := SecretService.ReadSecret(name)
+	if err != nil {
+		return defs.Secret{}, err
+	}
+
+	if err := SecretService.DeleteSecret(name); err != nil {
+		return defs.Secret{}, err
+	}
+
+	secret.Value = ""
+
+	return secret, SecretService.Flush()
+}
+
+// List returns the secrets, sorted by name, without their values.
+func List() []defs.Secret {
+	result := []defs.Secret{}
+
+	for _, secret := range SecretService.ListSecrets() {
+		secret.Value = ""
+		result = append(result, secret)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// Get returns the decrypted value of a secret for a user. The user must be in the
+// access list of the secret, or hold one of its roles. Each attempt to read a
+// secret is recorded in the audit log.
+func Get(user, name string) (string, error) {
+	secret, err := SecretService.ReadSecret(name)
+	if err != nil {
+		return "", err
+	}
+
+	if !Authorized(user, secret) {
+		audit.Record("secret.denied", ui.A{
+			"user": user,
+			"name": name})
+
+		return "", errors.ErrNoPermission.Context(name)
+	}
+
+	value, err := decrypt(secret.Value)
+	if err != nil {
+		return "", err
+	}
+
+	audit.Record("secret.read", ui.A{
+		"user": user,
+		"name": name})
+
+	return value, nil
+}
+
+// Readable returns the decrypted values of the secrets a user can read, by name. A
+// service run as a child process cannot read the secrets store, so the server reads
+// the secrets for it before starting the child. Nothing is recorded in the audit log
+// here; the server calls Record for each secret the service asks for.
+func Readable(user string) map[string]string {
+	result := map[string]string{}
+
+	if SecretService == nil {
+		return result
+	}
+
+	for name, secret := range SecretService.ListSecrets() {
+		if !Authorized(user, secret) {
+			continue
+		}
+
+		if value, err := decrypt(secret.Value); err == nil {
+			result[name] = value
+		}
+	}
+
+	return result
+}
+
+// Record writes the audit log entry for a secret that a service run as a child
+// process asked for, in the same way as Get. If the secret was not one the user
+// can read, and it exists, the attempt is recorded as denied.
+func Record(user, name string, read bool) {
+	if read {
+		audit.Record("secret.read", ui.A{
+			"user": user,
+			"name": name})
+
+		return
+	}
+
+	if SecretService == nil {
+		return
+	}
+
+	if _, err := SecretService.ReadSecret(name); err == nil {
+		audit.Record("secret.denied", ui.A{
+			"user": user,
+			"name": name})
+	}
+}
+
+// Authorized returns true if the user can read the secret. A user can read the
+// secret if the user is in its list of users, or holds one of its roles. A user
+// name of "*" in the list allows every user to read the secret.
+func Authorized(user string, secret defs.Secret) bool {
+	if user == "" {
+		return false
+	}
+
+	if util.InList("*", secret.Users...) || util.InList(user, secret.Users...) {
+		return true
+	}
+
+	for _, role := range auth.UserRoles(user) {
+		if util.InList(role, secret.Roles...) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalize returns a sorted list of unique names with blank names removed.
+func normalize(list []string) []string {
+	result := []string{}
+
+	for _, name := range list {
+		name = strings.TrimSpace(name)
+		if name != "" && !util.InList(name, result...) {
+			result = append(result, name)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
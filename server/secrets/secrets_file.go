@@ -0,0 +1,174 @@
+package secrets
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/util"
+)
+
+type fileService struct {
+	path  string
+	dirty bool
+	lock  sync.Mutex
+	data  map[string]defs.Secret
+}
+
+// NewFileService creates a new file-based secret service. If the path is empty or
+// "memory" then the secrets are only kept in memory. Otherwise, the file is read if
+// it exists and the contents are used to initialize the service.
+func NewFileService(path string) (secretService, error) {
+	if path == "memory" {
+		path = ""
+	}
+
+	svc := &fileService{
+		path: path,
+		data: map[string]defs.Secret{},
+	}
+
+	if path == "" {
+		ui.Log(ui.AuthLogger, "auth.secrets.memory")
+
+		return svc, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		// It is not an error if the file does not exist yet.
+		if os.IsNotExist(err) {
+			return svc, nil
+		}
+
+		return nil, errors.New(err)
+	}
+
+	if key := settings.Get(defs.LogonUserdataKeySetting); key != "" {
+		r, err := util.Decrypt(string(b), key)
+		if err != nil {
+			return nil, err
+		}
+
+		b = []byte(r)
+	}
+
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &svc.data); err != nil {
+			return nil, errors.New(err)
+		}
+	}
+
+	ui.Log(ui.AuthLogger, "auth.secrets.file", ui.A{
+		"count": len(svc.data)})
+
+	return svc, nil
+}
+
+// ListSecrets returns a map of all secrets, keyed by name.
+func (f *fileService) ListSecrets() map[string]defs.Secret {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	result := make(map[string]defs.Secret, len(f.data))
+	for name, secret := range f.data {
+		result[name] = secret
+	}
+
+	return result
+}
+
+// ReadSecret returns a secret.
+func (f *fileService) ReadSecret(name string) (defs.Secret, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	secret, ok := f.data[name]
+	if !ok {
+		return secret, errors.ErrNoSuchSecret.Context(name)
+	}
+
+	return secret, nil
+}
+
+// WriteSecret adds or replaces a secret. The map is marked as dirty so it will be
+// written to disk.
+func (f *fileService) WriteSecret(secret defs.Secret) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	_, found := f.data[secret.Name]
+	f.data[secret.Name] = secret
+	f.dirty = true
+
+	logSecretChange(secret.Name, found)
+
+	return nil
+}
+
+// DeleteSecret removes a secret. The map is marked as dirty so it will be written
+// to disk.
+func (f *fileService) DeleteSecret(name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, found := f.data[name]; !found {
+		return errors.ErrNoSuchSecret.Context(name)
+	}
+
+	delete(f.data, name)
+	f.dirty = true
+
+	ui.Log(ui.AuthLogger, "auth.secret.delete", ui.A{
+		"name": name})
+
+	return nil
+}
+
+// Flush writes the secrets to the file. This operation is not done if there were
+// no changes, or there is no file name given.
+func (f *fileService) Flush() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.dirty || f.path == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(f.data, "", "   ")
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if key := settings.Get(defs.LogonUserdataKeySetting); key != "" {
+		r, err := util.Encrypt(string(b), key)
+		if err != nil {
+			return err
+		}
+
+		b = []byte(r)
+	}
+
+	if err = os.WriteFile(f.path, b, 0600); err != nil {
+		return errors.New(err)
+	}
+
+	f.dirty = false
+
+	return nil
+}
+
+// logSecretChange logs the creation or update of a secret.
+func logSecretChange(name string, found bool) {
+	if found {
+		ui.Log(ui.AuthLogger, "auth.secret.update", ui.A{
+			"name": name})
+	} else {
+		ui.Log(ui.AuthLogger, "auth.secret.create", ui.A{
+			"name": name})
+	}
+}
@@ -0,0 +1,138 @@
+package secrets
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/resources"
+)
+
+type databaseService struct {
+	handle *resources.ResHandle
+}
+
+// NewDatabaseService creates a new secret service that uses a database as the
+// persistent store. The table of secrets is created if it does not yet exist.
+func NewDatabaseService(connStr string) (secretService, error) {
+	svc := &databaseService{}
+
+	u, err := url.Parse(connStr)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	if svc.handle, err = resources.Open(defs.Secret{}, "secrets", connStr); err != nil {
+		return nil, err
+	}
+
+	svc.handle.SetPrimaryKey("name")
+
+	if err = svc.handle.CreateIf(); err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return nil, errors.New(err)
+	}
+
+	// If there was a password specified in the URL, blank it out before we log it.
+	constr := connStr
+	if pstr, found := u.User.Password(); found {
+		constr = strings.ReplaceAll(connStr, ":"+pstr+"@", ":"+strings.Repeat("*", len(pstr))+"@")
+	}
+
+	ui.Log(ui.AuthLogger, "auth.secrets.db", ui.A{
+		"constr": constr})
+
+	return svc, nil
+}
+
+// ListSecrets returns a map of all secrets in the database, keyed by name.
+func (pg *databaseService) ListSecrets() map[string]defs.Secret {
+	r := map[string]defs.Secret{}
+
+	rowSet, err := pg.handle.Begin().Read()
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return r
+	}
+
+	for _, row := range rowSet {
+		secret := row.(*defs.Secret)
+		r[secret.Name] = *secret
+	}
+
+	return r
+}
+
+// ReadSecret returns a secret from the database.
+func (pg *databaseService) ReadSecret(name string) (defs.Secret, error) {
+	rowSet, err := pg.handle.Begin().Read(pg.handle.Equals("name", name))
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return defs.Secret{}, errors.New(err)
+	}
+
+	if len(rowSet) == 0 {
+		return defs.Secret{}, errors.ErrNoSuchSecret.Context(name)
+	}
+
+	return *rowSet[0].(*defs.Secret), nil
+}
+
+// WriteSecret adds or replaces a secret in the database.
+func (pg *databaseService) WriteSecret(secret defs.Secret) error {
+	var err error
+
+	_, readErr := pg.ReadSecret(secret.Name)
+	found := readErr == nil
+
+	if found {
+		err = pg.handle.Begin().Update(secret, pg.handle.Equals("name", secret.Name))
+	} else {
+		err = pg.handle.Begin().Insert(secret)
+	}
+
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return errors.New(err)
+	}
+
+	logSecretChange(secret.Name, found)
+
+	return nil
+}
+
+// DeleteSecret removes a secret from the database.
+func (pg *databaseService) DeleteSecret(name string) error {
+	count, err := pg.handle.Begin().Delete(pg.handle.Equals("name", name))
+	if err != nil {
+		ui.Log(ui.ServerLogger, "server.db.error", ui.A{
+			"error": err})
+
+		return errors.New(err)
+	}
+
+	if count == 0 {
+		return errors.ErrNoSuchSecret.Context(name)
+	}
+
+	ui.Log(ui.AuthLogger, "auth.secret.delete", ui.A{
+		"name": name})
+
+	return nil
+}
+
+// Flush is a no-operation for the database service, since each change is written
+// to the database when it is made.
+func (pg *databaseService) Flush() error {
+	return nil
+}
@@ -0,0 +1,188 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/auth"
+)
+
+// setupTestSecrets creates a secrets store in a temporary file, and an in-memory
+// user store with a group of users that holds the "accounting" role.
+func setupTestSecrets(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "users_secrets.json")
+
+	savedSecrets, savedAuth := SecretService, auth.AuthService
+
+	t.Cleanup(func() {
+		SecretService, auth.AuthService = savedSecrets, savedAuth
+	})
+
+	var err error
+
+	if SecretService, err = NewFileService(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if auth.AuthService, err = auth.NewFileService("", "admin", "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = auth.SetRole(defs.Role{Name: "accounting", ID: uuid.New()}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = auth.SetRole(defs.Role{Name: "payroll", ID: uuid.New(), Inherits: []string{"accounting"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = auth.SetGroup(defs.Group{Name: "clerks", ID: uuid.New(), Members: []string{"mary"}, Roles: []string{"payroll"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestGet(t *testing.T) {
+	setupTestSecrets(t)
+
+	if _, err := Set(defs.Secret{Name: "db", Value: "hunter2", Users: []string{"joe", " "}, Roles: []string{"accounting"}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if _, err := Set(defs.Secret{Name: "public", Value: "open", Users: []string{"*"}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		user    string
+		secret  string
+		want    string
+		wantErr error
+	}{
+		{"listed user", "joe", "db", "hunter2", nil},
+		{"inherited role", "mary", "db", "hunter2", nil},
+		{"not authorized", "bob", "db", "", errors.ErrNoPermission},
+		{"no user", "", "public", "", errors.ErrNoPermission},
+		{"any user", "bob", "public", "open", nil},
+		{"unknown secret", "joe", "missing", "", errors.ErrNoSuchSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Get(tt.user, tt.secret)
+			if tt.wantErr != nil {
+				if !errors.Equals(err, tt.wantErr) {
+					t.Errorf("Get() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Errorf("Get() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadable(t *testing.T) {
+	setupTestSecrets(t)
+
+	if _, err := Set(defs.Secret{Name: "db", Value: "hunter2", Users: []string{"joe"}, Roles: []string{"accounting"}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if _, err := Set(defs.Secret{Name: "public", Value: "open", Users: []string{"*"}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	tests := []struct {
+		user string
+		want string
+	}{
+		{"joe", "db=hunter2,public=open"},
+		{"mary", "db=hunter2,public=open"},
+		{"bob", "public=open"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		values := Readable(tt.user)
+
+		names := []string{}
+		for name, value := range values {
+			names = append(names, name+"="+value)
+		}
+
+		sort.Strings(names)
+
+		if got := strings.Join(names, ","); got != tt.want {
+			t.Errorf("Readable(%q) = %v, want %v", tt.user, got, tt.want)
+		}
+	}
+}
+
+func TestSet_EncryptedAtRest(t *testing.T) {
+	path := setupTestSecrets(t)
+
+	secret, err := Set(defs.Secret{Name: "api", Value: "plaintext-value", Users: []string{"joe"}})
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if secret.Value != "" {
+		t.Errorf("Set() returned the value of the secret")
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("secrets file not written, %v", err)
+	}
+
+	if strings.Contains(string(b), "plaintext-value") {
+		t.Errorf("secrets file contains the plain text of the value")
+	}
+
+	// A new service reading the same file can decrypt the value.
+	if SecretService, err = NewFileService(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, err := Get("joe", "api"); err != nil || value != "plaintext-value" {
+		t.Errorf("Get() after reload = %q, %v", value, err)
+	}
+
+	for _, item := range List() {
+		if item.Value != "" {
+			t.Errorf("List() returned the value of secret %s", item.Name)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	setupTestSecrets(t)
+
+	if _, err := Set(defs.Secret{Name: "temp", Value: "x", Users: []string{"joe"}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if _, err := Delete("temp"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := Delete("temp"); !errors.Equals(err, errors.ErrNoSuchSecret) {
+		t.Errorf("Delete() of a deleted secret error = %v", err)
+	}
+
+	if len(List()) != 0 {
+		t.Errorf("List() = %v, want no secrets", List())
+	}
+}
@@ -6,12 +6,14 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/tucats/ego/app-cli/settings"
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/builtins"
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/egostrings"
+	"github.com/tucats/ego/errors"
 	"github.com/tucats/ego/runtime/cipher"
 	rutil "github.com/tucats/ego/runtime/util"
 	auth "github.com/tucats/ego/server/auth"
@@ -35,6 +37,50 @@ func LogonHandler(session *Session, w http.ResponseWriter, r *http.Request) int
 		return http.StatusMovedPermanently
 	}
 
+	return issueToken(session, w, r, uuid.Nil)
+}
+
+// RefreshHandler fields requests to the /services/admin/refresh endpoint. The request
+// payload contains a refresh token from an earlier logon, which is exchanged for a
+// new token and a new refresh token. The refresh token cannot be used again.
+func RefreshHandler(session *Session, w http.ResponseWriter, r *http.Request) int {
+	// Is there another auth server we should refer this to? If so, redirect.
+	if auth := settings.Get(defs.ServerAuthoritySetting); auth != "" {
+		http.Redirect(w, r, auth+defs.ServicesRefreshPath, http.StatusMovedPermanently)
+
+		return http.StatusMovedPermanently
+	}
+
+	credentials := defs.Credentials{}
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		return util.ErrorResponse(w, session.ID, errors.ErrInvalidRequest.Error(), http.StatusBadRequest)
+	}
+
+	previous, err := auth.RefreshSession(credentials.Refresh)
+	if err != nil {
+		ui.Log(ui.AuthLogger, "auth.error",
+			"session", session.ID,
+			"error", err)
+
+		status := http.StatusUnauthorized
+		if errors.Equals(err, errors.ErrNoPermission) {
+			status = http.StatusForbidden
+		}
+
+		return util.ErrorResponse(w, session.ID, err.Error(), status)
+	}
+
+	session.User = previous.Name
+	session.Expiration = credentials.Expiration
+
+	return issueToken(session, w, r, previous.Family)
+}
+
+// issueToken creates a new token for the user in the session and writes the logon
+// response. The token is recorded as a session so it can be revoked, along with the
+// refresh token issued with it, if any. The family is the session replaced by this
+// one when a refresh token is used.
+func issueToken(session *Session, w http.ResponseWriter, r *http.Request, family uuid.UUID) int {
 	// No redirect, so we'll be geenrating a token here. This involves calling an Ego
 	// function, so we need a new symbol table to support that function call. Then,
 	// initialize the cipher package in that symbol table, so the package functionality
@@ -67,6 +113,14 @@ func LogonHandler(session *Session, w http.ResponseWriter, r *http.Request) int
 	// intenral error.
 	if t, ok := v.(string); ok {
 		response.Token = data.String(t)
+
+		if response.Refresh, err = auth.NewSession(response.Token, r.RemoteAddr, family); err != nil {
+			ui.Log(ui.AuthLogger, "auth.error",
+				"session", session.ID,
+				"error", err)
+
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
 	} else {
 		msg := fmt.Sprintf("invalid internal token data type: %s", data.TypeOf(v).String())
 		ui.Log(ui.AuthLogger, "auth.error",
@@ -127,6 +181,21 @@ func LogonHandler(session *Session, w http.ResponseWriter, r *http.Request) int
 	return http.StatusOK
 }
 
+// JWKSHandler fields requests to the /.well-known/jwks.json endpoint. It returns the
+// set of public keys that can be used to validate the JWT tokens issued by this server,
+// so other services can validate the tokens without calling the server. The set is
+// empty if the server does not issue JWT tokens, or signs them with a secret key.
+func JWKSHandler(session *Session, w http.ResponseWriter, r *http.Request) int {
+	b, _ := json.MarshalIndent(cipher.PublicKeys(), ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+
+	w.Header().Set(defs.ContentTypeHeader, defs.JSONMediaType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(b)
+	session.ResponseLength += len(b)
+
+	return http.StatusOK
+}
+
 // DownHandler fields incoming requests to the /services/admin/down endpoint.
 // This endpoint is only used if the runtime library does not include an Ego service
 // that performs this operation. The idea is that you can use this default, or you can
@@ -349,9 +418,15 @@ func AuthenticateHandler(session *Session, w http.ResponseWriter, r *http.Reques
 		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
 	}
 
-	// Add the user permissions array to the response object.
+	// Add the user permissions array to the response object. This includes the permissions
+	// granted by the roles assigned to the groups the user is a member of, so a server that
+	// uses this server as its authority sees the same permissions.
 	reply.Permissions = user.Permissions
 
+	if perms, err := auth.EffectivePermissions(user.Name); err == nil {
+		reply.Permissions = perms
+	}
+
 	// Convert the response object to JSON, and write it to the resposne object and we're done.
 	b, err := json.MarshalIndent(reply, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
 	if err != nil {
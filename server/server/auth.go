@@ -1,13 +1,16 @@
 package server
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/tucats/ego/app-cli/settings"
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/caches"
 	"github.com/tucats/ego/data"
@@ -25,7 +28,8 @@ const (
 // Authenticate examaines the request for valid credentials. These can be a bearer
 // token or a Basic username/password specification. Finally, if the request is a
 // POST (create) and there are no credentials and the body contains a username/password
-// specification, then use those as the credentials.
+// specification, then use those as the credentials. If there is no Authorization
+// header, an API key can be given in the X-API-Key header instead.
 //
 // A handler can examine the session object to determine the status of authentication.
 func (s *Session) Authenticate(r *http.Request) *Session {
@@ -36,8 +40,10 @@ func (s *Session) Authenticate(r *http.Request) *Session {
 		user            string
 		expiration      string
 		pass            string
+		code            string
 		token           string
 		authHeader      string
+		apiKey          string
 	)
 
 	// Simplest case -- if there is an Authorization header, start with the information in the header.
@@ -45,15 +51,28 @@ func (s *Session) Authenticate(r *http.Request) *Session {
 		authHeader = r.Header.Get("Authorization")
 	}
 
+	// An API key is only used if there is no Authorization header.
+	if authHeader == "" {
+		apiKey = r.Header.Get(defs.APIKeyHeader)
+	}
+
 	// If there are no authentication credentials provided, but the method is PUT or POST with
 	// a payload containing credentials, use the payload credentials.
-	if authHeader == "" && (r.Method == http.MethodPut || r.Method == http.MethodPost) {
+	if authHeader == "" && apiKey == "" && (r.Method == http.MethodPut || r.Method == http.MethodPost) {
 		credentials := defs.Credentials{}
 
-		err := json.NewDecoder(r.Body).Decode(&credentials)
+		// Read the payload, and then restore it so it can also be read by the
+		// handler for the request.
+		b, err := io.ReadAll(r.Body)
+		if err == nil {
+			r.Body = io.NopCloser(bytes.NewReader(b))
+			err = json.Unmarshal(b, &credentials)
+		}
+
 		if err == nil && credentials.Username != "" && credentials.Password != "" {
 			authHeader = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials.Username+":"+credentials.Password))
 			expiration = credentials.Expiration
+			code = credentials.Code
 
 			if expiration != "" {
 				if _, err := util.ParseDuration(expiration); err != nil {
@@ -82,7 +101,23 @@ func (s *Session) Authenticate(r *http.Request) *Session {
 
 	// If there was no autheorization found, or the credentials payload was incorrectly formed,
 	// we don't really have any credentials to use.
-	if authHeader == "" {
+	if apiKey != "" {
+		// The request can only use the permissions of the API key that are still granted
+		// to the user. The user is only an administrator if the key has root privileges.
+		key, valid := auth.ValidateAPIKey(apiKey, r.RemoteAddr)
+		if valid {
+			isAuthenticated = true
+			user = key.User
+			s.Permissions = key.Permissions
+			isRoot = util.InList("root", key.Permissions...)
+		}
+
+		ui.Log(ui.AuthLogger, "auth.using.apikey", ui.A{
+			"session": s.ID,
+			"id":      key.ID,
+			"user":    key.User,
+			"valid":   valid})
+	} else if authHeader == "" {
 		isAuthenticated = false
 
 		ui.Log(ui.AuthLogger, "auth.no.creds", ui.A{
@@ -93,8 +128,13 @@ func (s *Session) Authenticate(r *http.Request) *Session {
 		token = strings.TrimSpace(authHeader[len(defs.AuthScheme):])
 
 		// Have we recently decoded this token? If so, we can continue to use it
-		// since the cache ages out after 60 seconds.
-		if userItem, found := caches.Find(caches.TokenCache, token); found {
+		// since the cache ages out after 60 seconds, unless it has been revoked
+		// since it was cached. A server that relays authentication to another
+		// server does not cache tokens, because a token revoked by the authority
+		// must stop working on every server at once.
+		relaying := settings.Get(defs.ServerAuthoritySetting) != ""
+
+		if userItem, found := caches.Find(caches.TokenCache, token); found && !relaying && !auth.TokenRevoked(token) {
 			isAuthenticated = true
 			user = data.String(userItem)
 		} else {
@@ -107,7 +147,7 @@ func (s *Session) Authenticate(r *http.Request) *Session {
 			if isAuthenticated {
 				user = auth.TokenUser(token)
 				// If there was a valid user name in the token, add it to the cache for future use.
-				if user != "" {
+				if user != "" && !relaying {
 					caches.Add(caches.TokenCache, token, user)
 				}
 			}
@@ -154,6 +194,21 @@ func (s *Session) Authenticate(r *http.Request) *Session {
 			isAuthenticated = auth.ValidatePassword(user, pass)
 		}
 
+		// If the user is enrolled for one-time codes, the password is not enough. The
+		// code is in the credentials payload, or in a header for Basic authentication.
+		if isAuthenticated && auth.TOTPEnrolled(user) {
+			if code == "" {
+				code = r.Header.Get(defs.OneTimeCodeHeader)
+			}
+
+			if code == "" {
+				isAuthenticated = false
+				s.CodeRequired = true
+			} else {
+				isAuthenticated = auth.ValidateCode(user, code)
+			}
+		}
+
 		// Form a logging suffix that indicates if the credentials are invalid, valid,
 		// or valid and represent a root user.
 		validStatusSuffix := credentialInvalidMessage
@@ -182,3 +237,14 @@ func (s *Session) Authenticate(r *http.Request) *Session {
 
 	return s
 }
+
+// HasPermission reports if the authenticated user of the session has a permission.
+// If the session was authenticated with an API key, the permission must also be one
+// of the permissions of the key.
+func (s *Session) HasPermission(permission string) bool {
+	if s.Permissions != nil && !util.InList(strings.ToLower(permission), s.Permissions...) {
+		return false
+	}
+
+	return auth.GetPermission(s.User, permission)
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/tucats/ego/builtins"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/runtime"
+	"github.com/tucats/ego/server/auth"
+	"github.com/tucats/ego/symbols"
+)
+
+func TestAuthenticateRevokedToken(t *testing.T) {
+	saved := auth.AuthService
+
+	defer func() { auth.AuthService = saved }()
+
+	var err error
+
+	if auth.AuthService, err = auth.NewFileService(filepath.Join(t.TempDir(), "users.json"), "admin", "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	s := symbols.NewSymbolTable("test")
+	runtime.AddPackages(s)
+
+	token, err := builtins.CallBuiltin(s, "cipher.New", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = auth.NewSession(data.String(token), "127.0.0.1", uuid.Nil); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticate := func() bool {
+		r := httptest.NewRequest("GET", "/services/test", nil)
+		r.Header.Set("Authorization", defs.AuthScheme+data.String(token))
+
+		return (&Session{}).Authenticate(r).Authenticated
+	}
+
+	// The first request caches the token, so the second request is accepted from
+	// the cache.
+	if !authenticate() || !authenticate() {
+		t.Fatal("Authenticate() did not accept the token")
+	}
+
+	sessions := auth.Sessions("admin")
+	if len(sessions) != 1 {
+		t.Fatalf("Sessions() = %v", sessions)
+	}
+
+	// Revoke the session directly in the store, as another server that shares the
+	// user database would. This does not purge the token cache of this server.
+	sessions[0].Revoked = true
+	if err = auth.AuthService.WriteSession(sessions[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if authenticate() {
+		t.Error("Authenticate() accepted a revoked token")
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	auth "github.com/tucats/ego/server/auth"
+	"github.com/tucats/ego/util"
+)
+
+// OIDCLoginHandler fields requests to the /services/admin/oidc/login endpoint. It
+// starts a login with the OpenID Connect identity provider by redirecting the caller
+// to the provider's authorization endpoint. When the user has logged in, the provider
+// redirects back to the callback endpoint.
+func OIDCLoginHandler(session *Session, w http.ResponseWriter, r *http.Request) int {
+	redirect := settings.Get(defs.ServerOIDCRedirectSetting)
+	if redirect == "" {
+		scheme := "https"
+		if r.TLS == nil {
+			scheme = "http"
+		}
+
+		redirect = scheme + "://" + r.Host + defs.ServicesOIDCCallbackPath
+	}
+
+	location, err := auth.OIDCLoginURL(redirect)
+	if err != nil {
+		ui.Log(ui.AuthLogger, "auth.error",
+			"session", session.ID,
+			"error", err)
+
+		return util.ErrorResponse(w, session.ID, err.Error(), oidcErrorStatus(err))
+	}
+
+	http.Redirect(w, r, location, http.StatusFound)
+
+	return http.StatusFound
+}
+
+// OIDCCallbackHandler fields requests to the /services/admin/oidc/callback endpoint,
+// which the identity provider redirects to after the user logs in. The authorization
+// code from the provider is exchanged for an identity token, and the user identified
+// by the token is issued an Ego token, exactly as if they had used the logon endpoint.
+func OIDCCallbackHandler(session *Session, w http.ResponseWriter, r *http.Request) int {
+	parameters := r.URL.Query()
+
+	if reason := parameters.Get("error"); reason != "" {
+		if description := parameters.Get("error_description"); description != "" {
+			reason = description
+		}
+
+		return util.ErrorResponse(w, session.ID, errors.ErrOIDCProvider.Context(reason).Error(), http.StatusUnauthorized)
+	}
+
+	user, err := auth.OIDCLogin(parameters.Get("code"), parameters.Get("state"))
+	if err != nil {
+		ui.Log(ui.AuthLogger, "auth.error",
+			"session", session.ID,
+			"error", err)
+
+		return util.ErrorResponse(w, session.ID, err.Error(), oidcErrorStatus(err))
+	}
+
+	session.User = user
+	session.Authenticated = true
+	session.Admin = auth.GetPermission(user, "root")
+	session.Expiration = ""
+
+	return LogonHandler(session, w, r)
+}
+
+// oidcErrorStatus returns the HTTP status for an error logging in with the identity
+// provider.
+func oidcErrorStatus(err error) int {
+	switch {
+	case errors.Equals(err, errors.ErrOIDCNotConfigured):
+		return http.StatusNotFound
+
+	case errors.Equals(err, errors.ErrOIDCState):
+		return http.StatusBadRequest
+
+	case errors.Equals(err, errors.ErrOIDCProvider):
+		return http.StatusBadGateway
+
+	case errors.Equals(err, errors.ErrOIDCLocalUser):
+		return http.StatusForbidden
+
+	default:
+		return http.StatusUnauthorized
+	}
+}
@@ -91,6 +91,15 @@ type Session struct {
 	// True if the user was successfully authenticated
 	Authenticated bool
 
+	// True if the password was valid, but the user is enrolled for one-time
+	// codes and no code was provided.
+	CodeRequired bool
+
+	// If the request was authenticated with an API key, the permissions the
+	// key can use. The user's permissions are limited to these. This is nil
+	// for any other kind of authentication.
+	Permissions []string
+
 	// True if the user has administrator privileges
 	Admin bool
 
@@ -14,7 +14,8 @@ import (
 
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/defs"
-	"github.com/tucats/ego/server/auth"
+	"github.com/tucats/ego/errors"
+	egohttp "github.com/tucats/ego/runtime/http"
 	"github.com/tucats/ego/util"
 )
 
@@ -181,7 +182,7 @@ func (m *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// with a Forbidden error.
 	if status == http.StatusOK && (route.requiredPermissions != nil && !session.Admin) {
 		for _, permission := range route.requiredPermissions {
-			if !auth.GetPermission(session.User, permission) {
+			if !session.HasPermission(permission) {
 				ui.Log(ui.RouteLogger, "route.perm.auth", ui.A{
 					"session":    session.ID,
 					"permission": permission,
@@ -215,7 +216,14 @@ func (m *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				"session": session.ID,
 			})
 
-			status = util.ErrorResponse(w, session.ID, "not authorized", http.StatusUnauthorized)
+			msg := "not authorized"
+			if session.CodeRequired {
+				w.Header().Set(defs.OneTimeCodeHeader, "required")
+
+				msg = errors.ErrCodeRequired.Error()
+			}
+
+			status = util.ErrorResponse(w, session.ID, msg, http.StatusUnauthorized)
 		} else if route.mustBeAdmin && !session.Admin {
 			ui.Log(ui.RouteLogger, "route.admin", ui.A{
 				"session": session.ID,
@@ -292,32 +300,5 @@ func (r *Route) parmMap(req *http.Request) map[string][]string {
 // pattern inforamtion to create a map describing each field
 // in the URL. If there is no pattern, this returns a nil map.
 func (r *Route) partsMap(path string) map[string]interface{} {
-	m := map[string]interface{}{}
-	path = strings.TrimPrefix(strings.TrimSuffix(path, "/"), "/")
-	segments := strings.Split(path, "?")
-	pathSegment := strings.TrimPrefix(strings.TrimSuffix(segments[0], "/"), "/")
-	pathParts := strings.Split(pathSegment, "/")
-	patternParts := strings.Split(strings.TrimPrefix(strings.TrimSuffix(r.endpoint, "/"), "/"), "/")
-
-	for index, part := range patternParts {
-		// if this part of the pattern is a named value, make it part
-		// of the result with a string value.
-		if strings.HasPrefix(part, "{{") && strings.HasSuffix(part, "}}") {
-			key := strings.TrimPrefix(strings.TrimSuffix(part, "}}"), "{{")
-
-			if index < len(pathParts) {
-				m[key] = pathParts[index]
-			} else {
-				m[key] = ""
-			}
-		} else {
-			if index >= len(pathParts) {
-				m[part] = false
-			} else {
-				m[part] = (part == pathParts[index])
-			}
-		}
-	}
-
-	return m
+	return egohttp.URLParts(r.endpoint, path)
 }
@@ -16,4 +16,8 @@ func setAuthSymbols(session *server.Session, symbolTable *symbols.SymbolTable) {
 	symbolTable.SetAlways("_authenticated", session.Authenticated)
 	symbolTable.SetAlways(defs.RestStatusVariable, http.StatusOK)
 	symbolTable.SetAlways(defs.SuperUserVariable, session.Admin)
+
+	if session.Permissions != nil {
+		symbolTable.SetAlways(defs.PermissionsVariable, session.Permissions)
+	}
 }
@@ -1,17 +1,22 @@
 package services
 
 import (
+	"bytes"
+	gocontext "context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	nativeruntime "runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/tucats/ego/app-cli/settings"
@@ -23,7 +28,11 @@ import (
 	"github.com/tucats/ego/errors"
 	"github.com/tucats/ego/fork"
 	"github.com/tucats/ego/runtime"
+	"github.com/tucats/ego/runtime/context"
+	egohttp "github.com/tucats/ego/runtime/http"
+	"github.com/tucats/ego/runtime/secrets"
 	"github.com/tucats/ego/server/auth"
+	serversecrets "github.com/tucats/ego/server/secrets"
 	"github.com/tucats/ego/server/server"
 	"github.com/tucats/ego/symbols"
 	"github.com/tucats/ego/tokenizer"
@@ -53,6 +62,14 @@ type ChildServiceRequest struct {
 	// Boolean indicating if the caller used a bearer token
 	Bearer bool `json:"bearer"`
 
+	// The permissions the caller can use, if it was authenticated with an API key
+	Permissions []string `json:"permissions,omitempty"`
+
+	// True if the values of the secrets the caller can read are sent to the child
+	// process on its standard input. They are not part of the request file, which
+	// can be retained on disk.
+	Secrets bool `json:"secrets,omitempty"`
+
 	// AcceptsJSON is true if the caller accepts JSON responses
 	AcceptsJSON bool `json:"json"`
 
@@ -100,6 +117,10 @@ type ChildServiceResponse struct {
 
 	// The body of the response
 	Body string `json:"body"`
+
+	// The names of the secrets the service asked for, so the parent process can
+	// record them in the audit log
+	Secrets []string `json:"secrets,omitempty"`
 }
 
 const maxChildProcesses = 128
@@ -142,6 +163,7 @@ func callChildServices(session *server.Session, w http.ResponseWriter, r *http.R
 		User:          session.User,
 		Authenticated: session.Authenticated,
 		Bearer:        session.Token != "",
+		Permissions:   session.Permissions,
 		AcceptsJSON:   session.AcceptsJSON,
 		AcceptsText:   session.AcceptsText,
 		Method:        r.Method,
@@ -176,6 +198,11 @@ func callChildServices(session *server.Session, w http.ResponseWriter, r *http.R
 
 	child.Body = string(body)
 
+	// The child process cannot read the secrets store, so the secrets the caller can
+	// read are found here, using the same access checks as a service run by the server.
+	readable := serversecrets.Readable(session.User)
+	child.Secrets = len(readable) > 0
+
 	// Generate a temporary file name in the /tmp directory and write the JSON for the request to
 	// that file.
 	requestFileName := filepath.Join(ChildTempDir, fmt.Sprintf(defs.ChildRequestFileFormat, child.ServerID, child.SessionID))
@@ -198,14 +225,20 @@ func callChildServices(session *server.Session, w http.ResponseWriter, r *http.R
 		return util.ErrorResponse(w, child.SessionID, err.Error(), http.StatusInternalServerError)
 	}
 
-	// Now, run the child process. This will block until the child process completes.
+	// Now, run the child process. This will block until the child process completes. If the
+	// client disconnects, the request context is cancelled and the child process is killed.
 	strArray := fork.MungeArguments(os.Args[0], "--log", ui.ActiveLoggers(), "--service", requestFileName)
 
 	ui.Log(ui.ChildLogger, "child.running", ui.A{
 		"session": child.SessionID,
 		"command": strings.Join(strArray, " ")})
 
-	cmd := exec.Command(strArray[0], strArray[1:]...)
+	cmd := exec.CommandContext(r.Context(), strArray[0], strArray[1:]...)
+
+	if child.Secrets {
+		b, _ = json.Marshal(readable)
+		cmd.Stdin = bytes.NewReader(b)
+	}
 
 	// Fetch any log lines generated by the child process and write them to the log.
 	b, err = cmd.Output()
@@ -248,6 +281,12 @@ func callChildServices(session *server.Session, w http.ResponseWriter, r *http.R
 		return util.ErrorResponse(w, child.SessionID, err.Error(), http.StatusInternalServerError)
 	}
 
+	// Record each secret the service asked for in the audit log.
+	for _, name := range response.Secrets {
+		_, found := readable[name]
+		serversecrets.Record(session.User, name, found)
+	}
+
 	// Gather the info from the response, and send it back to the calling client.
 	w.WriteHeader(response.Status)
 	_, _ = w.Write([]byte(response.Body))
@@ -284,6 +323,42 @@ func callChildServices(session *server.Session, w http.ResponseWriter, r *http.R
 	return status
 }
 
+// childSecrets holds the values of the secrets that the caller of a service run as a
+// child process can read, which are sent by the parent process. The names of the
+// secrets the service asks for are returned to the parent process.
+type childSecrets struct {
+	lock   sync.Mutex
+	user   string
+	values map[string]string
+	used   []string
+}
+
+// read is the secrets reader used by a service run as a child process. A secret that
+// was not sent by the parent process does not exist or cannot be read by the caller.
+func (c *childSecrets) read(user, name string) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !util.InList(name, c.used...) {
+		c.used = append(c.used, name)
+	}
+
+	value, found := c.values[name]
+	if !found || user != c.user {
+		return "", errors.ErrNoPermission.Context(name)
+	}
+
+	return value, nil
+}
+
+// names returns the names of the secrets the service asked for.
+func (c *childSecrets) names() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([]string{}, c.used...)
+}
+
 // ChildService is the pseudo-rest handler for services written
 // in Ego that are run as a child process. It doesn't actually use
 // an http response reader or writer. Instead, it reads the request
@@ -322,141 +397,26 @@ func ChildService(filename string) error {
 	// id informaiton, and log that we're here.
 	status := http.StatusOK
 
-	// Define information we know about our running session and the caller, independent of
-	// the service being invoked.
-	symbolTable := symbols.NewRootSymbolTable(r.Method + " " + data.SanitizeName(r.Path))
-
-	// Some globals must be set up as if this was a server instance.
-	defs.InstanceID = r.ServerID
-
-	symbolTable.SetAlways(defs.StartTimeVariable, r.StartTime)
-	symbolTable.SetAlways(defs.PidVariable, os.Getpid())
-	symbolTable.SetAlways(defs.InstanceUUIDVariable, defs.InstanceID)
-	symbolTable.SetAlways(defs.SessionVariable, r.SessionID)
-	symbolTable.SetAlways(defs.MethodVariable, r.Method)
-	symbolTable.SetAlways(defs.ModeVariable, "server")
-	symbolTable.SetAlways(defs.StartTimeVariable, r.StartTime)
-	symbolTable.SetAlways(defs.PidVariable, r.Pid)
-	symbolTable.SetAlways(defs.VersionNameVariable, r.Version)
-	symbolTable.SetAlways(defs.TokenVariable, "********")
-
-	// Make sure we have recorded the extensions status and type check setting.
-	symbolTable.Root().SetAlways(defs.ExtensionsVariable,
-		settings.GetBool(defs.ExtensionsEnabledSetting))
-
-	// Indicate that code can be running in this mode.
-	symbols.RootSymbolTable.SetAlways(defs.UserCodeRunningVariable, true)
-
-	if staticTypes := settings.GetUsingList(defs.StaticTypesSetting,
-		defs.Strict,
-		defs.Relaxed,
-		defs.Dynamic,
-	) - 1; staticTypes < defs.StrictTypeEnforcement {
-		symbolTable.SetAlways(defs.TypeCheckingVariable, defs.NoTypeEnforcement)
-	} else {
-		symbolTable.SetAlways(defs.TypeCheckingVariable, staticTypes)
-	}
-
-	// Get the query parameters and store as an Ego map value.
-	parameters := map[string]interface{}{}
-
-	for k, v := range r.Parameters {
-		values := make([]interface{}, 0)
-		for _, vs := range v {
-			values = append(values, vs)
+	// The parent process stops the child if the client disconnects, so the context of
+	// the request is cancelled if the child is interrupted or terminated.
+	requestContext, cancel := signal.NotifyContext(gocontext.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// The secrets store and the user roles that control access to it are only
+	// loaded by the server, so the parent process sends the secrets the caller can
+	// read on standard input.
+	childSecrets := &childSecrets{user: r.User, values: map[string]string{}}
+	if r.Secrets {
+		if err := json.NewDecoder(os.Stdin).Decode(&childSecrets.values); err != nil {
+			return errors.New(err)
 		}
-
-		parameters[k] = data.NewArrayFromInterfaces(data.InterfaceType, values...)
 	}
 
-	symbolTable.SetAlways(defs.ParametersVariable, data.NewMapFromMap(parameters))
-
-	// Put all the headers where they can be accessed as well. The authorization
-	// header is omitted.
-	headers := map[string]interface{}{}
-	isJSON := false
+	secrets.Reader = childSecrets.read
 
-	for name, values := range r.Headers {
-		if strings.ToLower(name) != "authorization" {
-			valueList := []interface{}{}
-
-			for _, value := range values {
-				valueList = append(valueList, value)
-
-				if strings.EqualFold(name, "Accept") && strings.Contains(value, defs.JSONMediaType) {
-					isJSON = true
-				}
-			}
-
-			headers[name] = valueList
-		}
-	}
-
-	symbolTable.SetAlways(defs.HeadersMapVariable, data.NewMapFromMap(headers))
-	symbolTable.SetAlways(defs.JSONMediaVariable, isJSON)
-
-	// Determine path and endpoint values for this request.
-	path := r.Path
-	if path[:1] == "/" {
-		path = path[1:]
-	}
-
-	// The endpoint might have trailing path stuff; if so we need to find
-	// the part of the path that is the actual endpoint, so we can locate
-	// the service program. Also, store the full path, the endpoint,
-	// and any suffix that the service might want to process.
+	symbolTable := childSymbolTable(requestContext, r)
 	endpoint := r.Path
-	pathSuffix := ""
-
-	if len(endpoint) < len(path) {
-		pathSuffix = path[len(endpoint):]
-	}
-
-	if pathSuffix != "" {
-		pathSuffix = "/" + pathSuffix
-	}
-
-	// Create symbols describing the URL we were given for this service call.
-	// Also, now is a good time to add the functions and other builtin info
-	// needed for a rest handler.
-	symbolTable.SetAlways("_url", r.Path)
-	symbolTable.SetAlways("_path_endpoint", endpoint)
-	symbolTable.SetAlways("_path", "/"+path)
-	symbolTable.SetAlways("_path_suffix", pathSuffix)
-	symbolTable.SetAlways("authenticated", auth.Authenticated)
-	symbolTable.SetAlways("permission", auth.Permission)
-	symbolTable.SetAlways("setuser", auth.SetUser)
-	symbolTable.SetAlways("getuser", auth.GetUser)
-	symbolTable.SetAlways("deleteuser", auth.DeleteUser)
-	symbolTable.SetAlways(defs.RestResponseName, nil)
-
-	// The child services need access to the suite of pseudo-global values
-	// we just set up for this request. So allow deep symbol scopes when
-	// running a service.
-	settings.SetDefault(defs.RuntimeDeepScopeSetting, "true")
-
-	// If there are URLParts (from an @endpoint directive) then store them
-	// as a struct in the local storage so the service can access them easily.
-	if r.URLParts != nil {
-		m := data.NewMapFromMap(r.URLParts)
-		symbolTable.SetAlways("_urlparts", m)
-	}
-
-	// If there was a decomposed URL generated by the router to this handler,
-	// make the symbols present in the symbol table as well.
-	msg := strings.Builder{}
-
-	for k, v := range r.URLParts {
-		if msg.Len() > 0 {
-			msg.WriteString(", ")
-		}
-
-		msg.WriteString(fmt.Sprintf("%s = %v", k, v))
-		symbolTable.SetAlways(k, v)
-	}
-
-	// Add the runtime packages to the symbol table.
-	runtime.AddPackages(symbolTable)
+	_, isJSON := egohttp.Headers(r.Headers)
 
 	// Time to either compile a service, or re-use one from the cache. The
 	// following items will be set to describe the service we run. If this
@@ -509,7 +469,7 @@ func ChildService(filename string) error {
 	// Run the service code in a new context created for this session. If debug mode is enabled,
 	// use the debugger to run the code, else just run from the context. In either case, if the
 	// result is the STOP return code, remap that to nil (no error).
-	ctx := bytecode.NewContext(symbolTable, serviceCode).EnableConsoleOutput(true)
+	ctx := bytecode.NewContext(symbolTable, serviceCode).EnableConsoleOutput(true).SetCancelContext(requestContext)
 
 	err = ctx.Run()
 
@@ -517,6 +477,7 @@ func ChildService(filename string) error {
 		Status:  http.StatusOK,
 		Message: "",
 		Headers: map[string]string{},
+		Secrets: childSecrets.names(),
 	}
 
 	if errors.Equals(err, errors.ErrStop) {
@@ -632,6 +593,122 @@ func ChildService(filename string) error {
 	return err
 }
 
+// childSymbolTable creates the symbol table used to run a service in a child process,
+// describing the request and the caller. The context is returned to the service by the
+// Context() method of the request.
+func childSymbolTable(ctx gocontext.Context, r ChildServiceRequest) *symbols.SymbolTable {
+	// Define information we know about our running session and the caller, independent of
+	// the service being invoked.
+	symbolTable := symbols.NewRootSymbolTable(r.Method + " " + data.SanitizeName(r.Path))
+	symbolTable.SetAlways(defs.RequestContextVariable, context.New(ctx))
+
+	// Some globals must be set up as if this was a server instance.
+	defs.InstanceID = r.ServerID
+
+	symbolTable.SetAlways(defs.StartTimeVariable, r.StartTime)
+	symbolTable.SetAlways(defs.PidVariable, os.Getpid())
+	symbolTable.SetAlways(defs.InstanceUUIDVariable, defs.InstanceID)
+	symbolTable.SetAlways(defs.SessionVariable, r.SessionID)
+	symbolTable.SetAlways(defs.MethodVariable, r.Method)
+	symbolTable.SetAlways(defs.ModeVariable, "server")
+	symbolTable.SetAlways(defs.StartTimeVariable, r.StartTime)
+	symbolTable.SetAlways(defs.PidVariable, r.Pid)
+	symbolTable.SetAlways(defs.VersionNameVariable, r.Version)
+	symbolTable.SetAlways(defs.TokenVariable, "********")
+
+	// Make sure we have recorded the extensions status and type check setting.
+	symbolTable.Root().SetAlways(defs.ExtensionsVariable,
+		settings.GetBool(defs.ExtensionsEnabledSetting))
+
+	// Indicate that code can be running in this mode.
+	symbols.RootSymbolTable.SetAlways(defs.UserCodeRunningVariable, true)
+
+	if staticTypes := settings.GetUsingList(defs.StaticTypesSetting,
+		defs.Strict,
+		defs.Relaxed,
+		defs.Dynamic,
+	) - 1; staticTypes < defs.StrictTypeEnforcement {
+		symbolTable.SetAlways(defs.TypeCheckingVariable, defs.NoTypeEnforcement)
+	} else {
+		symbolTable.SetAlways(defs.TypeCheckingVariable, staticTypes)
+	}
+
+	// Get the query parameters and store as an Ego map value.
+	symbolTable.SetAlways(defs.ParametersVariable, egohttp.Parameters(r.Parameters))
+
+	// Put all the headers where they can be accessed as well. The authorization
+	// header is omitted.
+	headers, isJSON := egohttp.Headers(r.Headers)
+
+	symbolTable.SetAlways(defs.HeadersMapVariable, headers)
+	symbolTable.SetAlways(defs.JSONMediaVariable, isJSON)
+
+	// Determine path and endpoint values for this request.
+	path := r.Path
+	if path[:1] == "/" {
+		path = path[1:]
+	}
+
+	// The endpoint might have trailing path stuff; if so we need to find
+	// the part of the path that is the actual endpoint, so we can locate
+	// the service program. Also, store the full path, the endpoint,
+	// and any suffix that the service might want to process.
+	endpoint := r.Path
+	pathSuffix := ""
+
+	if len(endpoint) < len(path) {
+		pathSuffix = path[len(endpoint):]
+	}
+
+	if pathSuffix != "" {
+		pathSuffix = "/" + pathSuffix
+	}
+
+	// Create symbols describing the URL we were given for this service call.
+	// Also, now is a good time to add the functions and other builtin info
+	// needed for a rest handler.
+	symbolTable.SetAlways("_url", r.Path)
+	symbolTable.SetAlways("_path_endpoint", endpoint)
+	symbolTable.SetAlways("_path", "/"+path)
+	symbolTable.SetAlways("_path_suffix", pathSuffix)
+	symbolTable.SetAlways("authenticated", auth.Authenticated)
+	symbolTable.SetAlways("permission", auth.Permission)
+	symbolTable.SetAlways("setuser", auth.SetUser)
+	symbolTable.SetAlways("getuser", auth.GetUser)
+	symbolTable.SetAlways("deleteuser", auth.DeleteUser)
+	symbolTable.SetAlways(defs.RestResponseName, nil)
+
+	// The child services need access to the suite of pseudo-global values
+	// we just set up for this request. So allow deep symbol scopes when
+	// running a service.
+	settings.SetDefault(defs.RuntimeDeepScopeSetting, "true")
+
+	// If there are URLParts (from an @endpoint directive) then store them
+	// as a struct in the local storage so the service can access them easily.
+	if r.URLParts != nil {
+		m := data.NewMapFromMap(r.URLParts)
+		symbolTable.SetAlways("_urlparts", m)
+	}
+
+	// If there was a decomposed URL generated by the router to this handler,
+	// make the symbols present in the symbol table as well.
+	msg := strings.Builder{}
+
+	for k, v := range r.URLParts {
+		if msg.Len() > 0 {
+			msg.WriteString(", ")
+		}
+
+		msg.WriteString(fmt.Sprintf("%s = %v", k, v))
+		symbolTable.SetAlways(k, v)
+	}
+
+	// Add the runtime packages to the symbol table.
+	runtime.AddPackages(symbolTable)
+
+	return symbolTable
+}
+
 // Compile the contents of the named file, and if it compiles successfully,
 // return the code, token stream, and compiler instance to the caller.
 func compileChildService(
@@ -690,6 +767,10 @@ func setChildAuthSymbols(r ChildServiceRequest, symbolTable *symbols.SymbolTable
 	symbolTable.SetAlways("_authenticated", r.Authenticated)
 	symbolTable.SetAlways(defs.RestStatusVariable, http.StatusOK)
 	symbolTable.SetAlways(defs.SuperUserVariable, r.Admin)
+
+	if r.Permissions != nil {
+		symbolTable.SetAlways(defs.PermissionsVariable, r.Permissions)
+	}
 }
 
 func childError(msg string, status int) *errors.Error {
@@ -0,0 +1,71 @@
+package services
+
+import (
+	gocontext "context"
+	"strings"
+	"testing"
+
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/runtime/context"
+)
+
+func TestChildSymbolTable(t *testing.T) {
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	defer cancel()
+
+	r := ChildServiceRequest{
+		SessionID: 42,
+		Method:    "GET",
+		Path:      "/services/sample",
+		Headers:   map[string][]string{"Accept": {defs.JSONMediaType}},
+		URLParts:  map[string]string{"id": "7"},
+	}
+
+	s := childSymbolTable(ctx, r)
+
+	// The Context() method of the request returns the request context.
+	v, found := s.Get(defs.RequestContextVariable)
+	if !found {
+		t.Fatal("childSymbolTable() did not set the request context")
+	}
+
+	if native, ok := context.Value(v); !ok || native != ctx {
+		t.Errorf("childSymbolTable() request context = %v", v)
+	}
+
+	if v, _ := s.Get(defs.SessionVariable); data.IntOrZero(v) != 42 {
+		t.Errorf("childSymbolTable() session = %v", v)
+	}
+
+	if v, _ := s.Get(defs.JSONMediaVariable); !data.BoolOrFalse(v) {
+		t.Errorf("childSymbolTable() json = %v", v)
+	}
+
+	if v, _ := s.Get("id"); data.String(v) != "7" {
+		t.Errorf("childSymbolTable() URL part = %v", v)
+	}
+}
+
+func TestChildSecrets(t *testing.T) {
+	c := &childSecrets{user: "joe", values: map[string]string{"db": "hunter2"}}
+
+	if value, err := c.read("joe", "db"); err != nil || value != "hunter2" {
+		t.Errorf("read() = %v, %v", value, err)
+	}
+
+	// A secret the parent did not send is reported as one the caller cannot read.
+	if _, err := c.read("joe", "payroll"); !errors.Equals(err, errors.ErrNoPermission) {
+		t.Errorf("read() of a missing secret error = %v", err)
+	}
+
+	// The values can only be read on behalf of the caller of the service.
+	if _, err := c.read("mary", "db"); !errors.Equals(err, errors.ErrNoPermission) {
+		t.Errorf("read() for another user error = %v", err)
+	}
+
+	if names := c.names(); strings.Join(names, ",") != "db,payroll" {
+		t.Errorf("names() = %v", names)
+	}
+}
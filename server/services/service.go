@@ -21,6 +21,8 @@ import (
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
 	"github.com/tucats/ego/runtime"
+	"github.com/tucats/ego/runtime/context"
+	egohttp "github.com/tucats/ego/runtime/http"
 	"github.com/tucats/ego/server/auth"
 	"github.com/tucats/ego/server/server"
 	"github.com/tucats/ego/symbols"
@@ -49,41 +51,13 @@ func ServiceHandler(session *server.Session, w http.ResponseWriter, r *http.Requ
 	symbolTable := setupServerSymbols(r, session, requestor)
 
 	// Get the query parameters and store as an Ego map value.
-	parameters := map[string]interface{}{}
-
-	for k, v := range r.URL.Query() {
-		values := make([]interface{}, 0)
-		for _, vs := range v {
-			values = append(values, vs)
-		}
-
-		parameters[k] = data.NewArrayFromInterfaces(data.InterfaceType, values...)
-	}
-
-	symbolTable.SetAlways(defs.ParametersVariable, data.NewMapFromMap(parameters))
+	symbolTable.SetAlways(defs.ParametersVariable, egohttp.Parameters(r.URL.Query()))
 
 	// Put all the headers where they can be accessed as well. The authorization
 	// header is omitted.
-	headers := map[string]interface{}{}
-	isJSON := false
-
-	for name, values := range r.Header {
-		if strings.ToLower(name) != "authorization" {
-			valueList := []interface{}{}
-
-			for _, value := range values {
-				valueList = append(valueList, value)
-
-				if strings.EqualFold(name, "Accept") && strings.Contains(value, defs.JSONMediaType) {
-					isJSON = true
-				}
-			}
+	headers, isJSON := egohttp.Headers(r.Header)
 
-			headers[name] = valueList
-		}
-	}
-
-	symbolTable.SetAlways(defs.HeadersMapVariable, data.NewMapFromMap(headers))
+	symbolTable.SetAlways(defs.HeadersMapVariable, headers)
 	symbolTable.SetAlways(defs.JSONMediaVariable, isJSON)
 
 	// Determine path and endpoint values for this request.
@@ -147,9 +121,7 @@ func ServiceHandler(session *server.Session, w http.ResponseWriter, r *http.Requ
 		"urlparts": session.URLParts})
 
 	// Add the runtime packages to the symbol table.
-	serviceConcurrancy.Lock()
-	runtime.AddPackages(symbolTable)
-	serviceConcurrancy.Unlock()
+	AddPackages(symbolTable)
 
 	// Now that we know the actual endpoint, see if this is the endpoint we are debugging?
 	debug := false
@@ -222,8 +194,9 @@ func ServiceHandler(session *server.Session, w http.ResponseWriter, r *http.Requ
 
 	// Run the service code in a new context created for this session. If debug mode is enabled,
 	// use the debugger to run the code, else just run from the context. In either case, if the
-	// result is the STOP return code, remap that to nil (no error).
-	ctx := bytecode.NewContext(symbolTable, serviceCode).SetDebug(debug)
+	// result is the STOP return code, remap that to nil (no error). The service stops running if
+	// the client disconnects, which cancels the request context.
+	ctx := bytecode.NewContext(symbolTable, serviceCode).SetDebug(debug).SetCancelContext(r.Context())
 	ctx.EnableConsoleOutput(true)
 
 	if debug {
@@ -357,6 +330,15 @@ func ServiceHandler(session *server.Session, w http.ResponseWriter, r *http.Requ
 	return status
 }
 
+// AddPackages adds the runtime packages to the symbol table used to run a service
+// or a job. This is serialized, since services and jobs can start at the same time.
+func AddPackages(symbolTable *symbols.SymbolTable) {
+	serviceConcurrancy.Lock()
+	defer serviceConcurrancy.Unlock()
+
+	runtime.AddPackages(symbolTable)
+}
+
 // Define the root symbol table for this REST request.
 func setupServerSymbols(r *http.Request, session *server.Session, requestor string) *symbols.SymbolTable {
 	// Create a new symbol table for this request. The symmbol table name is formed from the
@@ -373,6 +355,7 @@ func setupServerSymbols(r *http.Request, session *server.Session, requestor stri
 	symbolTable.SetAlways(defs.VersionNameVariable, server.Version)
 	symbolTable.SetAlways(defs.StartTimeVariable, server.StartTime)
 	symbolTable.SetAlways(defs.RequestorVariable, requestor)
+	symbolTable.SetAlways(defs.RequestContextVariable, context.New(r.Context()))
 
 	symbolTable.Root().SetAlways(defs.ExtensionsVariable,
 		settings.GetBool(defs.ExtensionsEnabledSetting))
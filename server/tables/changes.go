@@ -0,0 +1,219 @@
+package tables
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/server/tables/changes"
+	"github.com/tucats/ego/server/tables/database"
+	"github.com/tucats/ego/server/tables/parsing"
+	"github.com/tucats/ego/util"
+)
+
+const (
+	// The number of changes returned if the request does not specify a limit.
+	defaultChangesLimit = 100
+
+	// The most changes returned by a single request.
+	maxChangesLimit = 1000
+
+	// The longest time a request can wait for changes to be made.
+	maxChangesWait = 60 * time.Second
+
+	// How often a waiting request checks for changes if no notification is received.
+	// This catches changes made by other servers sharing the database.
+	changesPollInterval = time.Second
+)
+
+// ReadChanges returns the changes made to a table after the sequence number given by the
+// "since" parameter. If there are no changes and the "wait" parameter gives a number of
+// seconds, the request waits up to that long for a change to be made before returning.
+// This operation requires either ownership of the table or admin privileges.
+func ReadChanges(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	var (
+		since int64
+		limit = defaultChangesLimit
+		wait  time.Duration
+		err   error
+	)
+
+	q := r.URL.Query()
+
+	if v := q.Get(defs.SinceParameterName); v != "" {
+		if since, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return util.ErrorResponse(w, session.ID, "invalid since parameter: "+v, http.StatusBadRequest)
+		}
+	}
+
+	if v := q.Get(defs.LimitParameterName); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 {
+			return util.ErrorResponse(w, session.ID, "invalid limit parameter: "+v, http.StatusBadRequest)
+		}
+
+		if limit > maxChangesLimit {
+			limit = maxChangesLimit
+		}
+	}
+
+	if v := q.Get(defs.WaitParameterName); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 0 {
+			return util.ErrorResponse(w, session.ID, "invalid wait parameter: "+v, http.StatusBadRequest)
+		}
+
+		if wait = time.Duration(seconds) * time.Second; wait > maxChangesWait {
+			wait = maxChangesWait
+		}
+	}
+
+	db, table, status := openOwnedTable(session, w)
+	if status != http.StatusOK {
+		return status
+	}
+
+	defer db.Close()
+
+	enabled, err := changes.IsEnabled(db.Handle, db.Provider, table)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	if !enabled {
+		return util.ErrorResponse(w, session.ID, "table does not have a change log: "+parsing.StripQuotes(table), http.StatusNotFound)
+	}
+
+	if wait > 0 {
+		ui.Log(ui.TableLogger, "table.changes.wait", ui.A{
+			"session": session.ID,
+			"table":   parsing.StripQuotes(table),
+			"since":   since,
+			"wait":    wait.String()})
+	}
+
+	deadline := time.Now().Add(wait)
+
+	for {
+		// Get the channel before reading the changes, so a change recorded while
+		// they are read is not missed.
+		changed := changes.Changed()
+
+		list, err := changes.Read(db.Handle, db.Provider, table, since, limit)
+		if err != nil {
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
+		remaining := time.Until(deadline)
+		if len(list) > 0 || remaining <= 0 {
+			return writeChanges(session, w, table, list, since)
+		}
+
+		if remaining > changesPollInterval {
+			remaining = changesPollInterval
+		}
+
+		select {
+		case <-changed:
+		case <-time.After(remaining):
+		case <-r.Context().Done():
+			return writeChanges(session, w, table, list, since)
+		}
+	}
+}
+
+// EnableChanges starts recording the changes made to a table in its change log. This
+// operation requires either ownership of the table or admin privileges.
+func EnableChanges(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	db, table, status := openOwnedTable(session, w)
+	if status != http.StatusOK {
+		return status
+	}
+
+	defer db.Close()
+
+	if err := changes.Enable(db.Handle, db.Provider, table); err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	ui.Log(ui.TableLogger, "table.changes.enabled", ui.A{
+		"session": session.ID,
+		"table":   parsing.StripQuotes(table)})
+
+	last, err := changes.Last(db.Handle, db.Provider)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	return writeChanges(session, w, table, []defs.DBChange{}, last)
+}
+
+// DisableChanges stops recording the changes made to a table, and deletes its change log.
+// This operation requires either ownership of the table or admin privileges.
+func DisableChanges(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	db, table, status := openOwnedTable(session, w)
+	if status != http.StatusOK {
+		return status
+	}
+
+	defer db.Close()
+
+	if err := changes.Disable(db.Handle, db.Provider, table); err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	ui.Log(ui.TableLogger, "table.changes.disabled", ui.A{
+		"session": session.ID,
+		"table":   parsing.StripQuotes(table)})
+
+	return writeChanges(session, w, table, []defs.DBChange{}, 0)
+}
+
+// writeChanges writes the response containing a list of changes to the table. The next
+// sequence number is the sequence number of the last change, or the given sequence
+// number if there are no changes.
+func writeChanges(session *server.Session, w http.ResponseWriter, table string, list []defs.DBChange, next int64) int {
+	if len(list) > 0 {
+		next = list[len(list)-1].Sequence
+	}
+
+	reply := defs.DBChangeSet{
+		ServerInfo: util.MakeServerInfo(session.ID),
+		Table:      parsing.StripQuotes(table),
+		Changes:    list,
+		Count:      len(list),
+		Next:       next,
+		Status:     http.StatusOK,
+	}
+
+	w.Header().Add(defs.ContentTypeHeader, defs.ChangesMediaType)
+	w.WriteHeader(http.StatusOK)
+
+	b, _ := json.MarshalIndent(reply, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+	_, _ = w.Write(b)
+	session.ResponseLength += len(b)
+
+	if ui.IsActive(ui.RestLogger) {
+		ui.WriteLog(ui.RestLogger, "rest.response.payload", ui.A{
+			"session": session.ID,
+			"body":    string(b)})
+	}
+
+	return http.StatusOK
+}
+
+// tableChangeLog returns the change log used to record the changes made to a table in the
+// transaction. As with row-level security policies, change logs are only kept for tables in
+// the Tables database, and not for tables accessed using a data source name. The result is
+// nil if the table does not have a change log.
+func tableChangeLog(session *server.Session, db *database.Database, tx *sql.Tx, dsnName, table string) (*changes.Log, error) {
+	if dsnName != "" {
+		return nil, nil
+	}
+
+	return changes.Open(db.Handle, tx, db.Provider, session.User, table)
+}
@@ -0,0 +1,442 @@
+// Package changes manages the change logs for tables in the Tables database. When a
+// table has a change log, each insert, update or delete of a row in the table is
+// recorded in the same transaction as the change itself, along with the user that
+// made the change, the values of the row before and after the change, and the time
+// of the change. The changes can be read by clients of the server, and are sent to
+// any webhooks configured for the server.
+package changes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/tables/parsing"
+)
+
+const (
+	InsertOperation = "insert"
+	UpdateOperation = "update"
+	DeleteOperation = "delete"
+)
+
+const sqlite3Provider = "sqlite3"
+
+const (
+	tablesCreateQuery        = `CREATE TABLE IF NOT EXISTS admin.changelog_tables(tablename CHAR VARYING)`
+	changesCreateQuery       = `CREATE TABLE IF NOT EXISTS admin.changelog(seq BIGSERIAL PRIMARY KEY, tablename CHAR VARYING, operation CHAR VARYING, username CHAR VARYING, before_values CHAR VARYING, after_values CHAR VARYING, created CHAR VARYING)`
+	sqliteChangesCreateQuery = `CREATE TABLE IF NOT EXISTS admin.changelog(seq INTEGER PRIMARY KEY AUTOINCREMENT, tablename CHAR VARYING, operation CHAR VARYING, username CHAR VARYING, before_values CHAR VARYING, after_values CHAR VARYING, created CHAR VARYING)`
+	tablesSelectQuery        = `SELECT COUNT(*) FROM admin.changelog_tables WHERE tablename = $1`
+	tablesInsertQuery        = `INSERT INTO admin.changelog_tables (tablename) VALUES($1)`
+	tablesDeleteQuery        = `DELETE FROM admin.changelog_tables WHERE tablename = $1`
+	changesInsertQuery       = `INSERT INTO admin.changelog (tablename, operation, username, before_values, after_values, created) VALUES($1, $2, $3, $4, $5, $6)`
+	changesSelectQuery       = `SELECT seq, tablename, operation, username, before_values, after_values, created FROM admin.changelog WHERE tablename = $1 AND seq > $2 ORDER BY seq LIMIT $3`
+	changesSelectAllQuery    = `SELECT seq, tablename, operation, username, before_values, after_values, created FROM admin.changelog WHERE seq > $1 ORDER BY seq LIMIT $2`
+	changesDeleteQuery       = `DELETE FROM admin.changelog WHERE tablename = $1`
+	changesLastQuery         = `SELECT COALESCE(MAX(seq), 0) FROM admin.changelog`
+	changesLockQuery         = `SELECT pg_advisory_xact_lock($1)`
+)
+
+// The key of the transaction-level advisory lock held by every transaction that records
+// changes in a Postgres database. The lock serializes the transactions, so the changes
+// are committed in the order of their sequence numbers. Without it, a change with a
+// lower sequence number could be committed after a client has already read a change
+// with a higher one, and the client would never see it. A sqlite3 database only allows
+// one transaction to write at a time, so it does not need the lock.
+const changesLockKey = 0x45676f43
+
+// Querier is the part of a database connection used to read and write change logs. It
+// is satisfied by both a database handle and a transaction.
+type Querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Log records the changes made to a table within a single transaction. A nil Log is
+// used for a table that does not have a change log, and records nothing.
+type Log struct {
+	tx    Querier
+	name  string
+	table string
+	user  string
+	count int
+}
+
+var (
+	notifyLock    sync.Mutex
+	notifyChannel = make(chan struct{})
+
+	createLock sync.Mutex
+	created    bool
+)
+
+// Initialize creates the tables that hold the change logs and the webhook cursors if
+// they do not already exist. This is done when the server starts, so the tables are
+// not created again for every row operation. The sequence number column is defined
+// differently for sqlite3.
+func Initialize(db Querier, provider string) error {
+	createLock.Lock()
+	defer createLock.Unlock()
+
+	queries := []string{tablesCreateQuery, changesCreateQuery, cursorsCreateQuery}
+	if provider == sqlite3Provider {
+		queries[1] = sqliteChangesCreateQuery
+	}
+
+	for _, query := range queries {
+		if _, err := db.Exec(query); err != nil {
+			return errors.New(err)
+		}
+	}
+
+	created = true
+
+	return nil
+}
+
+// createTables creates the tables that hold the change logs if they were not created
+// when the server started, for example because the database could not be reached.
+func createTables(db Querier, provider string) error {
+	createLock.Lock()
+	done := created
+	createLock.Unlock()
+
+	if done {
+		return nil
+	}
+
+	return Initialize(db, provider)
+}
+
+// IsEnabled reports if the table has a change log. The table name must include
+// the schema.
+func IsEnabled(db Querier, provider, table string) (bool, error) {
+	if err := createTables(db, provider); err != nil {
+		return false, err
+	}
+
+	rows, err := db.Query(tablesSelectQuery, parsing.StripQuotes(table))
+	if err != nil {
+		return false, errors.New(err)
+	}
+
+	defer rows.Close()
+
+	count := 0
+
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return false, errors.New(err)
+		}
+	}
+
+	return count > 0, nil
+}
+
+// Enable starts recording the changes made to the table. It is not an error if the
+// table already has a change log.
+func Enable(db Querier, provider, table string) error {
+	enabled, err := IsEnabled(db, provider, table)
+	if err != nil || enabled {
+		return err
+	}
+
+	if _, err := db.Exec(tablesInsertQuery, parsing.StripQuotes(table)); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// Disable stops recording the changes made to the table, and deletes the changes
+// already recorded for it.
+func Disable(db Querier, provider, table string) error {
+	if err := createTables(db, provider); err != nil {
+		return err
+	}
+
+	table = parsing.StripQuotes(table)
+
+	if _, err := db.Exec(tablesDeleteQuery, table); err != nil {
+		return errors.New(err)
+	}
+
+	if _, err := db.Exec(changesDeleteQuery, table); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// Open returns the change log used to record changes made to the table by the user
+// in the transaction. If the table name does not include a schema, the user's schema
+// is used. The database handle is used to determine if the table has a
+// change log, so the transaction is not affected if the change log tables cannot be
+// read. If the table does not have a change log, the result is nil. Otherwise, for a
+// Postgres database, the transaction waits for other transactions recording changes
+// to finish, so the changes are committed in order.
+func Open(db Querier, tx Querier, provider, user, table string) (*Log, error) {
+	name, _ := parsing.FullName(user, table)
+
+	enabled, err := IsEnabled(db, provider, name)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	if provider != sqlite3Provider {
+		if _, err := tx.Exec(changesLockQuery, changesLockKey); err != nil {
+			return nil, errors.New(err)
+		}
+	}
+
+	return &Log{tx: tx, name: name, table: parsing.StripQuotes(name), user: user}, nil
+}
+
+// Enabled reports if the changes made to the table are being recorded.
+func (l *Log) Enabled() bool {
+	return l != nil
+}
+
+// Rows reads the rows of the table that match the WHERE clause in the transaction, and
+// returns them as maps of the column names to values. This is used to read the rows
+// before they are updated or deleted. If there is no change log, the rows are not read
+// and the result is nil.
+func (l *Log) Rows(where string) ([]map[string]interface{}, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	query := strings.TrimSpace("SELECT * FROM " + l.name + " " + where)
+
+	ui.Log(ui.SQLLogger, "sql.query", ui.A{
+		"query": query})
+
+	rows, err := l.tx.Query(query)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	defer rows.Close()
+
+	names, _ := rows.Columns()
+	result := []map[string]interface{}{}
+
+	for rows.Next() {
+		values := make([]interface{}, len(names))
+		pointers := make([]interface{}, len(names))
+
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, errors.New(err)
+		}
+
+		row := map[string]interface{}{}
+
+		for i, value := range values {
+			if b, ok := value.([]byte); ok {
+				value = string(b)
+			}
+
+			row[names[i]] = value
+		}
+
+		result = append(result, row)
+	}
+
+	return result, nil
+}
+
+// Record adds a change to the change log in the transaction. The before values are
+// nil for an insert, and the after values are nil for a delete. If there is no
+// change log, nothing is recorded.
+func (l *Log) Record(operation string, before, after map[string]interface{}) error {
+	if l == nil {
+		return nil
+	}
+
+	beforeText, err := encode(before)
+	if err != nil {
+		return err
+	}
+
+	afterText, err := encode(after)
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().UTC().Format(time.RFC3339Nano)
+
+	if _, err := l.tx.Exec(changesInsertQuery, l.table, operation, l.user, beforeText, afterText, timestamp); err != nil {
+		return errors.New(err)
+	}
+
+	l.count++
+
+	return nil
+}
+
+// Done is called after the transaction is committed. If any changes were recorded,
+// clients waiting for changes are notified.
+func (l *Log) Done() {
+	if l == nil || l.count == 0 {
+		return
+	}
+
+	ui.Log(ui.TableLogger, "table.changes", ui.A{
+		"table": l.table,
+		"count": l.count})
+
+	l.count = 0
+
+	Notify()
+}
+
+// Merge returns the values of a row after it is updated with the given values. The
+// row ID is never updated.
+func Merge(before, values map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(before))
+
+	for key, value := range before {
+		result[key] = value
+	}
+
+	for key, value := range values {
+		if key != defs.RowIDName {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// Read returns up to limit changes made to the table after the given sequence number.
+// If the table name is empty, the changes made to all tables are returned.
+func Read(db Querier, provider, table string, since int64, limit int) ([]defs.DBChange, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+
+	if err := createTables(db, provider); err != nil {
+		return nil, err
+	}
+
+	if table == "" {
+		rows, err = db.Query(changesSelectAllQuery, since, limit)
+	} else {
+		rows, err = db.Query(changesSelectQuery, parsing.StripQuotes(table), since, limit)
+	}
+
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	defer rows.Close()
+
+	result := []defs.DBChange{}
+
+	for rows.Next() {
+		var (
+			change        defs.DBChange
+			before, after sql.NullString
+		)
+
+		err := rows.Scan(&change.Sequence, &change.Table, &change.Operation, &change.User, &before, &after, &change.Timestamp)
+		if err != nil {
+			return nil, errors.New(err)
+		}
+
+		if change.Before, err = decode(before); err == nil {
+			change.After, err = decode(after)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, change)
+	}
+
+	return result, nil
+}
+
+// Last returns the sequence number of the most recent change recorded for any table,
+// or zero if there are no changes.
+func Last(db Querier, provider string) (int64, error) {
+	if err := createTables(db, provider); err != nil {
+		return 0, err
+	}
+
+	rows, err := db.Query(changesLastQuery)
+	if err != nil {
+		return 0, errors.New(err)
+	}
+
+	defer rows.Close()
+
+	var seq int64
+
+	if rows.Next() {
+		if err := rows.Scan(&seq); err != nil {
+			return 0, errors.New(err)
+		}
+	}
+
+	return seq, nil
+}
+
+// Notify wakes up everything waiting for changes to be recorded.
+func Notify() {
+	notifyLock.Lock()
+	defer notifyLock.Unlock()
+
+	close(notifyChannel)
+	notifyChannel = make(chan struct{})
+}
+
+// Changed returns a channel that is closed the next time changes are recorded for
+// any table.
+func Changed() <-chan struct{} {
+	notifyLock.Lock()
+	defer notifyLock.Unlock()
+
+	return notifyChannel
+}
+
+// encode converts the values of a row to the JSON text stored in the change log. A
+// nil map is stored as a null value.
+func encode(values map[string]interface{}) (interface{}, error) {
+	if values == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(values)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	return string(b), nil
+}
+
+// decode converts the JSON text stored in the change log back to the values of a row.
+func decode(text sql.NullString) (map[string]interface{}, error) {
+	if !text.Valid || text.String == "" {
+		return nil, nil
+	}
+
+	result := map[string]interface{}{}
+
+	if err := json.Unmarshal([]byte(text.String), &result); err != nil {
+		return nil, errors.New(err)
+	}
+
+	return result, nil
+}
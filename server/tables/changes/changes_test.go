@@ -0,0 +1,227 @@
+package changes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/tucats/ego/defs"
+)
+
+// openTestDatabase opens an in-memory database with the "admin" schema used for the change
+// logs, and a table "tom.orders" with two rows.
+func openTestDatabase(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each connection to an in-memory database is a new database, so use only one. The
+	// schemas are attached databases in sqlite.
+	db.SetMaxOpenConns(1)
+
+	for _, q := range []string{
+		`ATTACH DATABASE ':memory:' AS admin`,
+		`ATTACH DATABASE ':memory:' AS tom`,
+		`CREATE TABLE tom.orders(_row_id_ CHAR VARYING, item CHAR VARYING, qty INTEGER)`,
+		`INSERT INTO tom.orders VALUES('1', 'apple', 3), ('2', 'pear', 5)`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Initialize(db, sqlite3Provider); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// recorder is a Querier that records the statements executed, and runs the queries
+// using a database.
+type recorder struct {
+	*sql.DB
+	statements []string
+}
+
+func (r *recorder) Exec(query string, args ...interface{}) (sql.Result, error) {
+	r.statements = append(r.statements, query)
+
+	return r.DB.Exec(query, args...)
+}
+
+func TestOpenLocks(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+
+	if err := Enable(db, sqlite3Provider, "tom.orders"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tables are not created again once they exist. A Postgres transaction that
+	// records changes takes the lock that keeps the changes in order. The test database
+	// is sqlite3, which does not have the lock function, so Open() fails after trying.
+	handle := &recorder{DB: db}
+	tx := &recorder{DB: db}
+
+	if log, err := Open(handle, tx, "postgres", "tom", "orders"); err == nil || log != nil {
+		t.Fatalf("Open() = %v, %v, want the error from the lock", log, err)
+	}
+
+	if len(handle.statements) != 0 {
+		t.Errorf("Open() executed %v", handle.statements)
+	}
+
+	if len(tx.statements) != 1 || tx.statements[0] != changesLockQuery {
+		t.Errorf("Open() executed %v in the transaction", tx.statements)
+	}
+}
+
+func TestLog(t *testing.T) {
+	db := openTestDatabase(t)
+	defer db.Close()
+
+	// A table without a change log records nothing.
+	tx, _ := db.Begin()
+
+	log, err := Open(tx, tx, sqlite3Provider, "tom", "orders")
+	if err != nil || log.Enabled() {
+		t.Fatalf("Open() before Enable() = %v, %v", log, err)
+	}
+
+	if err := log.Record(InsertOperation, nil, map[string]interface{}{"item": "fig"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = tx.Rollback()
+
+	if err := Enable(db, sqlite3Provider, `"tom"."orders"`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Enabling the change log again is not an error.
+	if err := Enable(db, sqlite3Provider, "tom.orders"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes recorded in a transaction that is rolled back are discarded.
+	tx, _ = db.Begin()
+	log, _ = Open(tx, tx, sqlite3Provider, "tom", "orders")
+	_ = log.Record(InsertOperation, nil, map[string]interface{}{"item": "fig"})
+	_ = tx.Rollback()
+
+	// Record an update and a delete in a transaction that is committed.
+	tx, _ = db.Begin()
+
+	log, err = Open(tx, tx, sqlite3Provider, "tom", "orders")
+	if err != nil || !log.Enabled() {
+		t.Fatalf("Open() after Enable() = %v, %v", log, err)
+	}
+
+	before, err := log.Rows(`WHERE "item" = 'pear'`)
+	if err != nil || len(before) != 1 {
+		t.Fatalf("Rows() = %v, %v", before, err)
+	}
+
+	update := map[string]interface{}{"qty": 6, defs.RowIDName: "9"}
+	if err := log.Record(UpdateOperation, before[0], Merge(before[0], update)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := log.Record(DeleteOperation, before[0], nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := Changed()
+
+	log.Done()
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Error("Done() did not notify waiting clients")
+	}
+
+	list, err := Read(db, sqlite3Provider, "tom.orders", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 2 {
+		t.Fatalf("Read() = %v", list)
+	}
+
+	want := map[string]interface{}{defs.RowIDName: "2", "item": "pear", "qty": float64(6)}
+	if list[0].Operation != UpdateOperation || list[0].User != "tom" || !reflect.DeepEqual(list[0].After, want) {
+		t.Errorf("Read() update = %v", list[0])
+	}
+
+	if list[1].Operation != DeleteOperation || list[1].After != nil || list[1].Before["item"] != "pear" {
+		t.Errorf("Read() delete = %v", list[1])
+	}
+
+	// Only the changes after the given sequence number are read, for any table.
+	if list, _ := Read(db, sqlite3Provider, "", list[0].Sequence, 10); len(list) != 1 || list[0].Table != "tom.orders" {
+		t.Errorf("Read() since = %v", list)
+	}
+
+	if last, _ := Last(db, sqlite3Provider); last != list[1].Sequence {
+		t.Errorf("Last() = %d, want %d", last, list[1].Sequence)
+	}
+
+	if err := Disable(db, sqlite3Provider, "tom.orders"); err != nil {
+		t.Fatal(err)
+	}
+
+	if list, _ := Read(db, sqlite3Provider, "tom.orders", 0, 10); len(list) != 0 {
+		t.Errorf("Read() after Disable() = %v", list)
+	}
+}
+
+func TestPost(t *testing.T) {
+	var (
+		received defs.DBChangeSet
+		status   = http.StatusInternalServerError
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &received)
+
+		w.WriteHeader(status)
+	}))
+
+	defer server.Close()
+
+	payload := defs.DBChangeSet{
+		Changes: []defs.DBChange{{Sequence: 7, Table: "tom.orders", Operation: InsertOperation}},
+		Count:   1,
+		Next:    7,
+	}
+
+	// An error status from the webhook is an error, so the batch is retried.
+	if err := post(server.Client(), server.URL, payload); err == nil {
+		t.Error("post() with error status did not fail")
+	}
+
+	status = http.StatusNoContent
+
+	if err := post(server.Client(), server.URL, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if received.Next != 7 || len(received.Changes) != 1 || received.Changes[0].Table != "tom.orders" {
+		t.Errorf("post() sent %v", received)
+	}
+}
@@ -0,0 +1,267 @@
+package changes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/tables/database"
+	"github.com/tucats/ego/util"
+)
+
+const (
+	// The maximum number of changes sent to a webhook in a single request.
+	webhookBatchSize = 100
+
+	// How long to wait between checks for new changes if no notification is
+	// received. This catches changes made by other servers sharing the database.
+	webhookPollInterval = 5 * time.Second
+
+	// The delay before the first retry of a failed request, which doubles for each
+	// retry up to the maximum.
+	webhookRetryDelay    = time.Second
+	webhookMaxRetryDelay = time.Minute
+
+	// How long to wait for a webhook to respond.
+	webhookTimeout = 30 * time.Second
+)
+
+const (
+	cursorsCreateQuery = `CREATE TABLE IF NOT EXISTS admin.changelog_cursors(url CHAR VARYING, seq BIGINT)`
+	cursorsSelectQuery = `SELECT seq FROM admin.changelog_cursors WHERE url = $1`
+	cursorsDeleteQuery = `DELETE FROM admin.changelog_cursors WHERE url = $1`
+	cursorsInsertQuery = `INSERT INTO admin.changelog_cursors (url, seq) VALUES($1, $2)`
+)
+
+// Start creates the tables that hold the change logs in the Tables database, and starts
+// sending the changes to any webhooks in the server configuration. It is called when the
+// server starts. Nothing is done if the server does not have a Tables database.
+func Start() {
+	db, err := database.Open(nil, "", 0)
+	if err != nil {
+		if !errors.Equals(err, errors.ErrNoDatabase) {
+			ui.Log(ui.TableLogger, "table.changes.error", ui.A{
+				"error": err.Error()})
+		}
+
+		return
+	}
+
+	err = Initialize(db.Handle, db.Provider)
+
+	db.Close()
+
+	if err != nil {
+		ui.Log(ui.TableLogger, "table.changes.error", ui.A{
+			"error": err.Error()})
+	}
+
+	Webhooks()
+}
+
+// Webhooks starts sending the changes recorded for all tables to each of the webhook
+// URLs in the server configuration. Each webhook is sent the changes in batches, in
+// the order they were made. If a webhook cannot be reached or returns an error, the
+// batch is retried until it succeeds, so no changes are skipped. The last change sent
+// to each webhook is stored in the database, so delivery resumes where it left off
+// when the server is restarted. A webhook added to the configuration is sent only the
+// changes made after it is first started.
+func Webhooks() {
+	urls := []string{}
+
+	for _, url := range strings.Split(settings.Get(defs.TablesServerWebhooksSetting), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+
+	if len(urls) == 0 {
+		return
+	}
+
+	db, err := database.Open(nil, "", 0)
+	if err != nil {
+		ui.Log(ui.TableLogger, "table.webhook.error", ui.A{
+			"url":   strings.Join(urls, ", "),
+			"error": err.Error()})
+
+		return
+	}
+
+	for _, url := range urls {
+		go webhook(db, url)
+	}
+}
+
+// webhook sends the changes to a single webhook URL. This runs until the server stops.
+func webhook(db *database.Database, url string) {
+	cursor, err := readCursor(db, url)
+	if err != nil {
+		ui.Log(ui.TableLogger, "table.webhook.error", ui.A{
+			"url":   url,
+			"error": err.Error()})
+
+		return
+	}
+
+	ui.Log(ui.TableLogger, "table.webhook.start", ui.A{
+		"url":  url,
+		"next": cursor})
+
+	client := &http.Client{Timeout: webhookTimeout}
+
+	for {
+		// Get the channel before reading the changes, so a change recorded while
+		// they are read is not missed.
+		changed := Changed()
+
+		list, err := Read(db.Handle, db.Provider, "", cursor, webhookBatchSize)
+		if err != nil || len(list) == 0 {
+			if err != nil {
+				ui.Log(ui.TableLogger, "table.webhook.error", ui.A{
+					"url":   url,
+					"error": err.Error()})
+			}
+
+			select {
+			case <-changed:
+			case <-time.After(webhookPollInterval):
+			}
+
+			continue
+		}
+
+		next := list[len(list)-1].Sequence
+
+		payload := defs.DBChangeSet{
+			ServerInfo: util.MakeServerInfo(0),
+			Changes:    list,
+			Count:      len(list),
+			Next:       next,
+			Status:     http.StatusOK,
+		}
+
+		delay := webhookRetryDelay
+
+		for {
+			err := post(client, url, payload)
+			if err == nil {
+				break
+			}
+
+			ui.Log(ui.TableLogger, "table.webhook.retry", ui.A{
+				"url":   url,
+				"error": err.Error(),
+				"delay": delay.String()})
+
+			time.Sleep(delay)
+
+			if delay = delay * 2; delay > webhookMaxRetryDelay {
+				delay = webhookMaxRetryDelay
+			}
+		}
+
+		ui.Log(ui.TableLogger, "table.webhook.sent", ui.A{
+			"url":   url,
+			"count": len(list),
+			"next":  next})
+
+		cursor = next
+
+		if err := writeCursor(db, url, cursor); err != nil {
+			ui.Log(ui.TableLogger, "table.webhook.error", ui.A{
+				"url":   url,
+				"error": err.Error()})
+		}
+	}
+}
+
+// post sends a batch of changes to the webhook. Any response status other than a
+// success status is an error.
+func post(client *http.Client, url string, payload defs.DBChangeSet) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	resp, err := client.Post(url, defs.ChangesMediaType, bytes.NewReader(b))
+	if err != nil {
+		return errors.New(err)
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Message(resp.Status)
+	}
+
+	return nil
+}
+
+// readCursor returns the sequence number of the last change sent to the webhook. If
+// the webhook has never been started, it starts after the most recent change.
+func readCursor(db *database.Database, url string) (int64, error) {
+	if err := createTables(db.Handle, db.Provider); err != nil {
+		return 0, err
+	}
+
+	rows, err := db.Query(cursorsSelectQuery, url)
+	if err != nil {
+		return 0, errors.New(err)
+	}
+
+	found := false
+
+	var seq int64
+
+	if rows.Next() {
+		found = true
+		err = rows.Scan(&seq)
+	}
+
+	rows.Close()
+
+	if err != nil {
+		return 0, errors.New(err)
+	}
+
+	if found {
+		return seq, nil
+	}
+
+	if seq, err = Last(db.Handle, db.Provider); err != nil {
+		return 0, err
+	}
+
+	return seq, writeCursor(db, url, seq)
+}
+
+// writeCursor stores the sequence number of the last change sent to the webhook.
+func writeCursor(db *database.Database, url string, seq int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if _, err = tx.Exec(cursorsDeleteQuery, url); err == nil {
+		_, err = tx.Exec(cursorsInsertQuery, url, seq)
+	}
+
+	if err != nil {
+		_ = tx.Rollback()
+
+		return errors.New(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
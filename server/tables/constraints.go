@@ -0,0 +1,276 @@
+package tables
+
+import (
+	"strings"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/tables/database"
+	"github.com/tucats/ego/server/tables/parsing"
+)
+
+// tableConstraints is the constraint information read from the database for a
+// given table.
+type tableConstraints struct {
+	primaryKey  []string
+	indexes     []defs.DBIndex
+	foreignKeys []defs.DBForeignKey
+	defaults    map[string]string
+}
+
+// Map the Postgres foreign key delete action codes to the SQL action names.
+var postgresDeleteActions = map[string]string{
+	"a": "no action",
+	"r": "restrict",
+	"c": "cascade",
+	"n": "set null",
+	"d": "set default",
+}
+
+// getConstraintInfo reads the primary key, secondary indexes, foreign keys, and column
+// default values for the given table. The table name must already be fully qualified.
+func getConstraintInfo(db *database.Database, tableName string, sessionID int) (*tableConstraints, error) {
+	if db.Provider == sqlite3Provider {
+		return getSQLiteConstraintInfo(db, tableName, sessionID)
+	}
+
+	return getPostgresConstraintInfo(db, tableName, sessionID)
+}
+
+// getPostgresConstraintInfo reads the constraint information for a table from the
+// Postgres system catalogs.
+func getPostgresConstraintInfo(db *database.Database, tableName string, sessionID int) (*tableConstraints, error) {
+	result := &tableConstraints{defaults: map[string]string{}}
+
+	rows, err := queryConstraintRows(db, primaryKeyQuery, map[string]string{"table": tableName}, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result.primaryKey = append(result.primaryKey, data.String(row[0]))
+	}
+
+	// Each row is a single column of an index, so accumulate the columns of the
+	// same index together.
+	rows, err = queryConstraintRows(db, indexesQuery, map[string]string{"table": tableName}, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		name := data.String(row[0])
+		column := data.String(row[2])
+
+		if n := len(result.indexes); n > 0 && result.indexes[n-1].Name == name {
+			result.indexes[n-1].Columns = append(result.indexes[n-1].Columns, column)
+		} else {
+			result.indexes = append(result.indexes, defs.DBIndex{
+				Name:    name,
+				Unique:  data.BoolOrFalse(row[1]),
+				Columns: []string{column},
+			})
+		}
+	}
+
+	// Likewise, each row is a single column of a foreign key.
+	rows, err = queryConstraintRows(db, foreignKeysQuery, map[string]string{"table": tableName}, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		name := data.String(row[0])
+
+		if n := len(result.foreignKeys); n > 0 && result.foreignKeys[n-1].Name == name {
+			key := &result.foreignKeys[n-1]
+			key.Columns = append(key.Columns, data.String(row[1]))
+			key.References = append(key.References, data.String(row[3]))
+		} else {
+			result.foreignKeys = append(result.foreignKeys, defs.DBForeignKey{
+				Name:       name,
+				Columns:    []string{data.String(row[1])},
+				Table:      data.String(row[2]),
+				References: []string{data.String(row[3])},
+				OnDelete:   postgresDeleteActions[data.String(row[4])],
+			})
+		}
+	}
+
+	rows, err = queryConstraintRows(db, columnDefaultsQuery, map[string]string{"table": tableName, "quote": ""}, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result.defaults[data.String(row[0])] = data.String(row[1])
+	}
+
+	return result, nil
+}
+
+// getSQLiteConstraintInfo reads the constraint information for a table using the
+// SQLite pragma functions.
+func getSQLiteConstraintInfo(db *database.Database, tableName string, sessionID int) (*tableConstraints, error) {
+	result := &tableConstraints{defaults: map[string]string{}}
+
+	// The table info has a row for each column, including the default value and
+	// the (one-based) position of the column in the primary key.
+	rows, err := queryConstraintMaps(db, sqliteTableInfoQuery, map[string]string{"table": tableName}, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[int]string{}
+
+	for _, row := range rows {
+		name := data.String(row["name"])
+
+		if row["dflt_value"] != nil {
+			result.defaults[name] = data.String(row["dflt_value"])
+		}
+
+		if position := data.IntOrZero(row["pk"]); position > 0 {
+			keys[position] = name
+		}
+	}
+
+	for i := 1; i <= len(keys); i++ {
+		result.primaryKey = append(result.primaryKey, keys[i])
+	}
+
+	// Get the list of indexes, skipping the one that implements the primary key. The
+	// columns for each index must be read separately.
+	rows, err = queryConstraintMaps(db, sqliteIndexListQuery, map[string]string{"table": tableName}, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if data.String(row["origin"]) == "pk" {
+			continue
+		}
+
+		index := defs.DBIndex{
+			Name:   data.String(row["name"]),
+			Unique: data.BoolOrFalse(row["unique"]),
+		}
+
+		columns, err := queryConstraintMaps(db, sqliteIndexInfoQuery, map[string]string{"index": index.Name}, sessionID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, column := range columns {
+			index.Columns = append(index.Columns, data.String(column["name"]))
+		}
+
+		result.indexes = append(result.indexes, index)
+	}
+
+	// Foreign keys are not named in SQLite, but each key has an id value that is the
+	// same for each column in a composite key.
+	rows, err = queryConstraintMaps(db, sqliteForeignKeysQuery, map[string]string{"table": tableName}, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[int]int{}
+
+	for _, row := range rows {
+		id := data.IntOrZero(row["id"])
+
+		if n, found := ids[id]; found {
+			key := &result.foreignKeys[n]
+			key.Columns = append(key.Columns, data.String(row["from"]))
+			key.References = append(key.References, data.String(row["to"]))
+		} else {
+			ids[id] = len(result.foreignKeys)
+			result.foreignKeys = append(result.foreignKeys, defs.DBForeignKey{
+				Columns:    []string{data.String(row["from"])},
+				Table:      data.String(row["table"]),
+				References: []string{data.String(row["to"])},
+				OnDelete:   strings.ToLower(data.String(row["on_delete"])),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// queryConstraintRows executes a metadata query and returns the result as an array of
+// rows, each of which is an array of column values.
+func queryConstraintRows(db *database.Database, query string, parameters map[string]string, sessionID int) ([][]interface{}, error) {
+	_, result, err := queryConstraints(db, query, parameters, sessionID)
+
+	return result, err
+}
+
+// queryConstraintMaps executes a metadata query and returns the result as an array of
+// maps, keyed by the column name. This is used for the SQLite pragma functions, where
+// the number of columns returned varies by the version of SQLite.
+func queryConstraintMaps(db *database.Database, query string, parameters map[string]string, sessionID int) ([]map[string]interface{}, error) {
+	names, rows, err := queryConstraints(db, query, parameters, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]interface{}, len(rows))
+
+	for n, row := range rows {
+		result[n] = map[string]interface{}{}
+
+		for i, name := range names {
+			result[n][name] = row[i]
+		}
+	}
+
+	return result, nil
+}
+
+// queryConstraints executes a metadata query, and returns the column names and an array
+// of rows, each of which is an array of column values.
+func queryConstraints(db *database.Database, query string, parameters map[string]string, sessionID int) ([]string, [][]interface{}, error) {
+	result := [][]interface{}{}
+
+	q := parsing.QueryParameters(query, parameters)
+
+	ui.Log(ui.SQLLogger, "sql.query", ui.A{
+		"session": sessionID,
+		"query":   q})
+
+	rows, err := db.Query(q)
+	if err != nil {
+		return nil, nil, errors.New(err)
+	}
+
+	defer rows.Close()
+
+	names, _ := rows.Columns()
+
+	for rows.Next() {
+		row := make([]interface{}, len(names))
+		pointers := make([]interface{}, len(names))
+
+		for i := range row {
+			pointers[i] = &row[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, nil, errors.New(err)
+		}
+
+		// Some drivers return text values as byte arrays, so convert them to strings.
+		for i, value := range row {
+			if b, ok := value.([]byte); ok {
+				row[i] = string(b)
+			}
+		}
+
+		result = append(result, row)
+	}
+
+	return names, result, nil
+}
@@ -34,12 +34,61 @@ const (
 										table_name,
 										column_name; `
 
-	// Get a list of the table columns that have UNIQUEness constraints.
+	// Get a list of the table columns that have UNIQUEness constraints. Columns that are
+	// only part of a composite unique index are not themselves unique.
 	uniqueColumnsQuery = `SELECT a.attname 
 							FROM   pg_index i  
 							JOIN   pg_attribute a 
 								ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey) 
-							WHERE  i.indrelid = '{{schema}}.{{table}}'::regclass;   `
+							WHERE  i.indrelid = '{{schema}}.{{table}}'::regclass
+								AND i.indisunique
+								AND i.indnatts = 1;   `
+
+	// Get the columns of the primary key for the table, in key order.
+	primaryKeyQuery = `SELECT a.attname
+							FROM   pg_index i
+							JOIN   pg_attribute a
+								ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+							WHERE  i.indrelid = '{{schema}}.{{table}}'::regclass
+								AND i.indisprimary
+							ORDER BY array_position(i.indkey::int2[], a.attnum);`
+
+	// Get the secondary indexes for the table, with one row for each column in
+	// each index, in index column order.
+	indexesQuery = `SELECT ic.relname, i.indisunique, a.attname
+							FROM   pg_index i
+							JOIN   pg_class ic ON ic.oid = i.indexrelid
+							JOIN   pg_attribute a
+								ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+							WHERE  i.indrelid = '{{schema}}.{{table}}'::regclass
+								AND NOT i.indisprimary
+							ORDER BY ic.relname, array_position(i.indkey::int2[], a.attnum);`
+
+	// Get the foreign keys for the table, with one row for each column in each
+	// key, in key column order.
+	foreignKeysQuery = `SELECT c.conname, a.attname, fn.nspname || '.' || f.relname, fa.attname, c.confdeltype
+							FROM   pg_constraint c
+							JOIN   pg_class f ON f.oid = c.confrelid
+							JOIN   pg_namespace fn ON fn.oid = f.relnamespace
+							CROSS JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, fattnum, pos)
+							JOIN   pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+							JOIN   pg_attribute fa ON fa.attrelid = c.confrelid AND fa.attnum = k.fattnum
+							WHERE  c.contype = 'f'
+								AND c.conrelid = '{{schema}}.{{table}}'::regclass
+							ORDER BY c.conname, k.pos;`
+
+	// Get the default value expressions for the columns of the table.
+	columnDefaultsQuery = `SELECT column_name, column_default
+							FROM information_schema.columns
+							WHERE table_schema = '{{schema}}'
+								AND table_name = '{{table}}'
+								AND column_default IS NOT NULL;`
+
+	// SQLite stores the constraint information in pragma tables.
+	sqliteTableInfoQuery   = `PRAGMA table_info("{{table}}")`
+	sqliteIndexListQuery   = `PRAGMA index_list("{{table}}")`
+	sqliteIndexInfoQuery   = `PRAGMA index_info("{{index}}")`
+	sqliteForeignKeysQuery = `PRAGMA foreign_key_list("{{table}}")`
 
 	selectVerb = "SELECT"
 	deleteVerb = "DELETE"
@@ -60,9 +60,16 @@ func ReadTable(session *server.Session, w http.ResponseWriter, r *http.Request)
 		// provider.
 		columns, e2 := getColumnInfo(db, session.User, tableName, session.ID)
 		if e2 == nil {
+			// Get the primary key, indexes, foreign keys, and default values. This is done
+			// using provider-specific queries.
+			constraints, e3 := getConstraintInfo(db, tableName, session.ID)
+			if e3 != nil {
+				return util.ErrorResponse(w, session.ID, e3.Error(), http.StatusInternalServerError)
+			}
+
 			// If it succeeded, merge in the information we gleaned about nullable columns
 			// and send the response to the caller.
-			return sendColumnResponse(columns, nullableColumns, uniqueColumns, session, w)
+			return sendColumnResponse(columns, nullableColumns, uniqueColumns, constraints, session, w)
 		}
 
 		// Form an Ego error and get ready to report failure...
@@ -93,7 +100,7 @@ func ReadTable(session *server.Session, w http.ResponseWriter, r *http.Request)
 
 // For the array of column info, merge in the metadata from the database provider (if any) and generate
 // a response to the caller.
-func sendColumnResponse(columns []defs.DBColumn, nullableColumns map[string]bool, uniqueColumns map[string]bool, session *server.Session, w http.ResponseWriter) int {
+func sendColumnResponse(columns []defs.DBColumn, nullableColumns map[string]bool, uniqueColumns map[string]bool, constraints *tableConstraints, session *server.Session, w http.ResponseWriter) int {
 	for n, column := range columns {
 		columns[n].Nullable.Specified = true
 		columns[n].Nullable.Value = nullableColumns[column.Name]
@@ -108,19 +115,23 @@ func sendColumnResponse(columns []defs.DBColumn, nullableColumns map[string]bool
 		}
 	}
 
-	// Determine which columns are also unique
+	// Determine which columns are also unique, and which have default values.
 	for n, column := range columns {
 		columns[n].Unique = defs.BoolValue{Specified: true, Value: uniqueColumns[column.Name]}
+		columns[n].Default = constraints.defaults[column.Name]
 	}
 
 	// Construct a response object which contains the server info header, and the array of column
 	// information. The response includes the total count of columns in the table.
 	// The server info header is included in the response.
 	resp := defs.TableColumnsInfo{
-		ServerInfo: util.MakeServerInfo(session.ID),
-		Columns:    columns,
-		Count:      len(columns),
-		Status:     http.StatusOK,
+		ServerInfo:  util.MakeServerInfo(session.ID),
+		Columns:     columns,
+		PrimaryKey:  constraints.primaryKey,
+		Indexes:     constraints.indexes,
+		ForeignKeys: constraints.foreignKeys,
+		Count:       len(columns),
+		Status:      http.StatusOK,
 	}
 
 	// Set the return type to indicate it is JSON for table metadata.
@@ -0,0 +1,134 @@
+package tables
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/server/server"
+)
+
+// The number of rows written by an export before the output is flushed to the client.
+const exportFlushCount = 1000
+
+// exportMediaType returns the media type for a bulk export of rows, if the request
+// accepts CSV or JSON Lines data. Otherwise, an empty string is returned.
+func exportMediaType(r *http.Request) string {
+	for _, mediaType := range r.Header["Accept"] {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(mediaType, ";")[0]))
+		if mediaType == defs.CSVMediaType || mediaType == defs.NDJSONMediaType {
+			return mediaType
+		}
+	}
+
+	return ""
+}
+
+// exportRowData executes the query and writes the result to the response as a stream of
+// CSV or JSON Lines data, depending on the media type. The rows are written as they are
+// read from the database, so the result set is never held in memory.
+func exportRowData(db *sql.DB, q string, mediaType string, session *server.Session, w http.ResponseWriter) error {
+	rows, err := db.Query(q)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	columnNames, _ := rows.Columns()
+	columnCount := len(columnNames)
+	rowCount := 0
+
+	flusher, _ := w.(http.Flusher)
+
+	w.Header().Add(defs.ContentTypeHeader, mediaType)
+	w.WriteHeader(http.StatusOK)
+
+	csvWriter := csv.NewWriter(w)
+	jsonEncoder := json.NewEncoder(w)
+
+	if mediaType == defs.CSVMediaType {
+		_ = csvWriter.Write(columnNames)
+	}
+
+	for rows.Next() {
+		row := make([]interface{}, columnCount)
+		rowptrs := make([]interface{}, columnCount)
+
+		for i := range row {
+			rowptrs[i] = &row[i]
+		}
+
+		if err = rows.Scan(rowptrs...); err != nil {
+			break
+		}
+
+		if mediaType == defs.CSVMediaType {
+			record := make([]string, columnCount)
+			for i, v := range row {
+				record[i] = exportValueText(v)
+			}
+
+			err = csvWriter.Write(record)
+		} else {
+			item := map[string]interface{}{}
+			for i, v := range row {
+				if b, ok := v.([]byte); ok {
+					v = string(b)
+				}
+
+				item[columnNames[i]] = v
+			}
+
+			err = jsonEncoder.Encode(item)
+		}
+
+		if err != nil {
+			break
+		}
+
+		rowCount++
+
+		if rowCount%exportFlushCount == 0 {
+			csvWriter.Flush()
+
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+
+	csvWriter.Flush()
+
+	ui.Log(ui.TableLogger, "table.export.done", ui.A{
+		"session": session.ID,
+		"rows":    rowCount,
+		"columns": columnCount,
+		"media":   mediaType})
+
+	return err
+}
+
+// exportValueText converts a value read from the database to the text used in a CSV
+// export. Null values are written as empty strings.
+func exportValueText(v interface{}) string {
+	switch actual := v.(type) {
+	case nil:
+		return ""
+
+	case []byte:
+		return string(actual)
+
+	case time.Time:
+		return actual.Format(time.RFC3339Nano)
+
+	default:
+		return data.String(actual)
+	}
+}
@@ -0,0 +1,489 @@
+package tables
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/dsns"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/server/tables/changes"
+	"github.com/tucats/ego/server/tables/database"
+	"github.com/tucats/ego/server/tables/parsing"
+	"github.com/tucats/ego/util"
+)
+
+const (
+	defaultImportBatchSize = 1000
+	importSavepoint        = "ego_import_row"
+)
+
+// importSource is a reader for bulk import data. Each call to next returns the next
+// row as a map of column names to values. At the end of the data, io.EOF is returned.
+type importSource interface {
+	next() (map[string]interface{}, error)
+}
+
+// csvImportSource reads rows from CSV data. The first record of the data is the
+// header, which names the column for each field in the records that follow.
+type csvImportSource struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+// ndjsonImportSource reads rows from JSON Lines data, where each line is a JSON
+// object whose field names are mapped to column names.
+type ndjsonImportSource struct {
+	scanner *bufio.Scanner
+	mapping func(string) (string, error)
+}
+
+// isImportMediaType returns true if the request has a content type that is used for bulk
+// import of rows, as opposed to a JSON row set.
+func isImportMediaType(r *http.Request) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(r.Header.Get(defs.ContentTypeHeader), ";")[0]))
+
+	return mediaType == defs.CSVMediaType || mediaType == defs.NDJSONMediaType
+}
+
+// ImportRows inserts rows into a table from CSV or JSON Lines data in the request body. The
+// data is read as a stream, and the rows are committed in batches. The "batch" parameter sets
+// the number of rows per batch, the "map" parameter can map field names in the data to column
+// names in the table, and the "maxerrors" parameter sets how many rows that cannot be stored
+// are reported and skipped before the import is abandoned.
+func ImportRows(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	tableName := data.String(session.URLParts["table"])
+	dsnName := data.String(session.URLParts["dsn"])
+
+	db, err := database.Open(&session.User, dsnName, dsns.DSNWriteAction)
+	if err != nil || db == nil || db.Handle == nil {
+		if err == nil {
+			err = errors.Message(unexpectedNilPointerError)
+		}
+
+		status := http.StatusBadRequest
+		if strings.Contains(err.Error(), "no privilege") {
+			status = http.StatusForbidden
+		}
+
+		return util.ErrorResponse(w, session.ID, insertErrorPrefix+err.Error(), status)
+	}
+
+	defer db.Close()
+
+	if db.Provider != sqlite3Provider {
+		tableName, _ = parsing.FullName(session.User, tableName)
+	}
+
+	if !session.Admin && dsnName == "" && !Authorized(session.ID, db.Handle, session.User, tableName, updateOperation) {
+		return util.ErrorResponse(w, session.ID, "User does not have update permission", http.StatusForbidden)
+	}
+
+	columns, err := getColumnInfo(db, session.User, tableName, session.ID)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, "Unable to read table metadata, "+err.Error(), http.StatusBadRequest)
+	}
+
+	// Build the function that maps a field name in the import data to a column name, using
+	// any explicit mappings from the request followed by a case-insensitive match with the
+	// column names.
+	mapping, err := importColumnMapping(r, columns)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
+	}
+
+	var source importSource
+
+	if strings.HasPrefix(strings.ToLower(r.Header.Get(defs.ContentTypeHeader)), defs.CSVMediaType) {
+		source, err = newCSVImportSource(r.Body, mapping)
+	} else {
+		source = newNDJSONImportSource(r.Body, mapping)
+	}
+
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
+	}
+
+	batchSize := settings.GetInt(defs.TablesServerImportBatchSetting)
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+
+	if v := data.IntOrZero(r.URL.Query().Get(defs.BatchParameterName)); v > 0 {
+		batchSize = v
+	}
+
+	maxErrors := data.IntOrZero(r.URL.Query().Get(defs.MaxErrorsParameterName))
+
+	result := importRows(db, session, dsnName, tableName, source, columns, batchSize, maxErrors)
+
+	w.Header().Add(defs.ContentTypeHeader, defs.ImportMediaType)
+	w.WriteHeader(result.Status)
+
+	b, _ := json.MarshalIndent(result, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+	_, _ = w.Write(b)
+	session.ResponseLength += len(b)
+
+	if ui.IsActive(ui.RestLogger) {
+		ui.WriteLog(ui.RestLogger, "rest.response.payload", ui.A{
+			"session": session.ID,
+			"body":    string(b)})
+	}
+
+	ui.Log(ui.TableLogger, "table.import.done", ui.A{
+		"session": session.ID,
+		"count":   result.Count,
+		"batches": result.Batches,
+		"errors":  len(result.Errors),
+		"status":  result.Status})
+
+	return result.Status
+}
+
+// importRows reads all the rows from the import source, and inserts them into the table. A
+// transaction is committed after each batch of rows. If more than maxErrors rows cannot be
+// stored, the current batch is rolled back and the import stops.
+func importRows(db *database.Database, session *server.Session, dsnName, tableName string, source importSource, columns []defs.DBColumn, batchSize, maxErrors int) defs.DBImportResponse {
+	var (
+		tx        *sql.Tx
+		changeLog *changes.Log
+		err       error
+		pending   int
+	)
+
+	result := defs.DBImportResponse{
+		ServerInfo: util.MakeServerInfo(session.ID),
+		Status:     http.StatusOK,
+	}
+
+	types := map[string]defs.DBColumn{}
+	for _, column := range columns {
+		types[column.Name] = column
+	}
+
+	// Local function that records an error for a given row, and reports if the import
+	// must be abandoned because there are too many errors.
+	fail := func(row int, err error) bool {
+		result.Errors = append(result.Errors, defs.DBImportError{Row: row, Message: filterErrorMessage(err.Error())})
+
+		return len(result.Errors) > maxErrors
+	}
+
+	for rowNumber := 1; ; rowNumber++ {
+		row, err := source.next()
+		if err == io.EOF {
+			break
+		}
+
+		if err == nil {
+			err = coerceImportRow(row, types)
+		}
+
+		if err != nil {
+			if fail(rowNumber, err) {
+				break
+			}
+
+			continue
+		}
+
+		if tx == nil {
+			if tx, err = db.Begin(); err != nil {
+				fail(rowNumber, err)
+
+				break
+			}
+
+			if changeLog, err = tableChangeLog(session, db, tx, dsnName, tableName); err != nil {
+				_ = tx.Rollback()
+				tx = nil
+
+				fail(rowNumber, err)
+
+				break
+			}
+		}
+
+		// Row IDs are always assigned on input.
+		row[defs.RowIDName] = uuid.New().String()
+
+		if err = insertImportRow(tx, db.Provider, session, row, maxErrors > 0, changeLog); err != nil {
+			if fail(rowNumber, err) {
+				break
+			}
+
+			continue
+		}
+
+		result.Count++
+		pending++
+
+		if pending >= batchSize {
+			if err = tx.Commit(); err != nil {
+				fail(rowNumber, err)
+
+				tx = nil
+
+				break
+			}
+
+			changeLog.Done()
+
+			result.Batches++
+			tx = nil
+			pending = 0
+
+			ui.Log(ui.TableLogger, "table.import.batch", ui.A{
+				"session": session.ID,
+				"batch":   result.Batches,
+				"count":   result.Count})
+		}
+	}
+
+	// If there were too many errors, the uncommitted rows are discarded. Otherwise,
+	// commit any remaining rows.
+	if len(result.Errors) > maxErrors {
+		if tx != nil {
+			_ = tx.Rollback()
+			result.Count -= pending
+		}
+
+		result.Status = http.StatusBadRequest
+		result.Message = fmt.Sprintf("import stopped after %d errors", len(result.Errors))
+	} else if tx != nil {
+		if err = tx.Commit(); err != nil {
+			result.Count -= pending
+			result.Status = http.StatusConflict
+			result.Message = insertErrorPrefix + filterErrorMessage(err.Error())
+		} else {
+			changeLog.Done()
+
+			result.Batches++
+		}
+	}
+
+	return result
+}
+
+// insertImportRow inserts a single row in the current transaction, and records it in the
+// change log if there is one. If errors are allowed, the insert is done within a savepoint
+// so a failure does not invalidate the rest of the transaction.
+func insertImportRow(tx *sql.Tx, provider string, session *server.Session, row map[string]interface{}, useSavepoint bool, changeLog *changes.Log) error {
+	tableName := data.String(session.URLParts["table"])
+
+	q, values := parsing.FormInsertQuery(tableName, session.User, provider, row)
+
+	ui.Log(ui.SQLLogger, "sql.exec", ui.A{
+		"session": session.ID,
+		"query":   q})
+
+	if useSavepoint {
+		if _, err := tx.Exec("SAVEPOINT " + importSavepoint); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(q, values...)
+	if err == nil {
+		err = changeLog.Record(changes.InsertOperation, nil, row)
+	}
+
+	if err != nil {
+		if useSavepoint {
+			_, _ = tx.Exec("ROLLBACK TO SAVEPOINT " + importSavepoint)
+		}
+
+		return err
+	}
+
+	if useSavepoint {
+		_, _ = tx.Exec("RELEASE SAVEPOINT " + importSavepoint)
+	}
+
+	return nil
+}
+
+// coerceImportRow converts each value in the row to the type of the column it will be
+// stored in. An empty string is stored as a null value for columns that are not strings.
+func coerceImportRow(row map[string]interface{}, columns map[string]defs.DBColumn) error {
+	for name, value := range row {
+		column, found := columns[name]
+		if !found {
+			return errors.ErrInvalidColumnName.Context(name)
+		}
+
+		v, err := coerceImportValue(value, column)
+		if err != nil {
+			return errors.New(err).Context(name)
+		}
+
+		row[name] = v
+	}
+
+	return nil
+}
+
+// coerceImportValue converts a value to the Go type that corresponds to the database
+// type of the column.
+func coerceImportValue(value interface{}, column defs.DBColumn) (interface{}, error) {
+	nativeType := parsing.MapColumnType(column.Type)
+
+	if text, ok := value.(string); value == nil || (ok && text == "" && nativeType != parsing.MapColumnType(data.StringTypeName)) {
+		return nil, nil
+	}
+
+	switch nativeType {
+	case "INT", "INT32", "int64", "int16", "int8", "integer", "bigint", "smallint":
+		return data.Int64(value)
+
+	case "REAL", "DOUBLE PRECISION", "float", "double", "numeric", "decimal":
+		return data.Float64(value)
+
+	case "BOOLEAN":
+		return data.Bool(value)
+
+	default:
+		return data.String(value), nil
+	}
+}
+
+// importColumnMapping returns a function that maps a field name in the import data to a
+// column name in the table. The "map" parameter can contain a list of "field=column" values
+// that explicitly map a field name. Otherwise, the field must have the same name as a column,
+// ignoring case. The row ID field is always mapped to an empty string, indicating it is
+// ignored, since row IDs are assigned when the row is inserted.
+func importColumnMapping(r *http.Request, columns []defs.DBColumn) (func(string) (string, error), error) {
+	explicit := map[string]string{}
+
+	for _, item := range r.URL.Query()[defs.MapParameterName] {
+		for _, pair := range strings.Split(item, ",") {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) != 2 {
+				return nil, errors.ErrInvalidColumnDefinition.Context(pair)
+			}
+
+			explicit[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	return func(field string) (string, error) {
+		if field == defs.RowIDName {
+			return "", nil
+		}
+
+		if name, found := explicit[field]; found {
+			field = name
+		}
+
+		for _, column := range columns {
+			if strings.EqualFold(column.Name, field) {
+				return column.Name, nil
+			}
+		}
+
+		return "", errors.ErrInvalidColumnName.Context(field)
+	}, nil
+}
+
+// newCSVImportSource creates a source that reads CSV data. The header record is read
+// immediately, so an invalid field name is reported before any rows are read.
+func newCSVImportSource(body io.Reader, mapping func(string) (string, error)) (*csvImportSource, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	columns := make([]string, len(header))
+
+	for i, field := range header {
+		if columns[i], err = mapping(strings.TrimSpace(field)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &csvImportSource{reader: reader, columns: columns}, nil
+}
+
+func (s *csvImportSource) next() (map[string]interface{}, error) {
+	record, err := s.reader.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	if len(record) != len(s.columns) {
+		return nil, errors.ErrInvalidFieldCount.Context(len(record))
+	}
+
+	row := map[string]interface{}{}
+
+	for i, value := range record {
+		if s.columns[i] != "" {
+			row[s.columns[i]] = value
+		}
+	}
+
+	return row, nil
+}
+
+// newNDJSONImportSource creates a source that reads JSON Lines data.
+func newNDJSONImportSource(body io.Reader, mapping func(string) (string, error)) *ndjsonImportSource {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	return &ndjsonImportSource{scanner: scanner, mapping: mapping}
+}
+
+func (s *ndjsonImportSource) next() (map[string]interface{}, error) {
+	var line string
+
+	// Skip over any blank lines.
+	for line == "" {
+		if !s.scanner.Scan() {
+			if err := s.scanner.Err(); err != nil {
+				return nil, errors.New(err)
+			}
+
+			return nil, io.EOF
+		}
+
+		line = strings.TrimSpace(s.scanner.Text())
+	}
+
+	item := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(line), &item); err != nil {
+		return nil, errors.New(err)
+	}
+
+	row := map[string]interface{}{}
+
+	for field, value := range item {
+		name, err := s.mapping(field)
+		if err != nil {
+			return nil, err
+		}
+
+		if name != "" {
+			row[name] = value
+		}
+	}
+
+	return row, nil
+}
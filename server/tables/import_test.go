@@ -0,0 +1,137 @@
+package tables
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tucats/ego/defs"
+)
+
+func Test_importSources(t *testing.T) {
+	columns := []defs.DBColumn{
+		{Name: "id", Type: "int"},
+		{Name: "name", Type: "string"},
+		{Name: "score", Type: "float64"},
+	}
+
+	tests := []struct {
+		name    string
+		csv     bool
+		mapping string
+		body    string
+		want    []map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "csv with matching header",
+			csv:  true,
+			body: "ID,Name,Score\n1,Tom,3.5\n2,Mary,\n",
+			want: []map[string]interface{}{
+				{"id": int64(1), "name": "Tom", "score": 3.5},
+				{"id": int64(2), "name": "Mary", "score": nil},
+			},
+		},
+		{
+			name:    "csv with mapped header",
+			csv:     true,
+			mapping: "who=name,n=id",
+			body:    "n,who\n5,Sue\n",
+			want: []map[string]interface{}{
+				{"id": int64(5), "name": "Sue"},
+			},
+		},
+		{
+			name: "csv with row id ignored",
+			csv:  true,
+			body: "_row_id_,name\nabc,Bob\n",
+			want: []map[string]interface{}{
+				{"name": "Bob"},
+			},
+		},
+		{
+			name:    "csv with unknown column",
+			csv:     true,
+			body:    "id,age\n1,2\n",
+			wantErr: true,
+		},
+		{
+			name: "json lines",
+			body: "{\"id\": 7, \"name\": \"Ann\"}\n\n{\"score\": \"2.25\"}\n",
+			want: []map[string]interface{}{
+				{"id": int64(7), "name": "Ann"},
+				{"score": 2.25},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest(http.MethodPut, "/tables/t/rows", nil)
+			if tt.mapping != "" {
+				q := r.URL.Query()
+				q.Set(defs.MapParameterName, tt.mapping)
+				r.URL.RawQuery = q.Encode()
+			}
+
+			mapping, err := importColumnMapping(r, columns)
+			if err != nil {
+				t.Fatalf("unexpected mapping error: %v", err)
+			}
+
+			var source importSource
+
+			if tt.csv {
+				source, err = newCSVImportSource(strings.NewReader(tt.body), mapping)
+			} else {
+				source = newNDJSONImportSource(strings.NewReader(tt.body), mapping)
+			}
+
+			if err != nil {
+				if !tt.wantErr {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			columnMap := map[string]defs.DBColumn{}
+			for _, column := range columns {
+				columnMap[column.Name] = column
+			}
+
+			got := []map[string]interface{}{}
+
+			for {
+				row, err := source.next()
+				if err == io.EOF {
+					break
+				}
+
+				if err == nil {
+					err = coerceImportRow(row, columnMap)
+				}
+
+				if err != nil {
+					if !tt.wantErr {
+						t.Errorf("unexpected error: %v", err)
+					}
+
+					return
+				}
+
+				got = append(got, row)
+			}
+
+			if tt.wantErr {
+				t.Errorf("expected error not returned")
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
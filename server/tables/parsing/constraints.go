@@ -0,0 +1,110 @@
+package parsing
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/tucats/ego/defs"
+)
+
+// ForeignKeyActions is the list of valid actions that can be taken when a row
+// referenced by a foreign key is deleted.
+var ForeignKeyActions = []string{"no action", "restrict", "cascade", "set null", "set default"}
+
+// FormIndexQueries generates the CREATE INDEX statements for each of the secondary
+// indexes in the list. The table name must already be fully qualified if the
+// provider requires it. If an index has no name, one is generated from the table
+// and column names.
+func FormIndexQueries(table string, provider string, indexes []defs.DBIndex) []string {
+	result := make([]string, 0, len(indexes))
+
+	for _, index := range indexes {
+		var q strings.Builder
+
+		name := index.Name
+		if name == "" {
+			name = IndexName(table, index.Columns)
+		}
+
+		q.WriteString("CREATE ")
+
+		if index.Unique {
+			q.WriteString("UNIQUE ")
+		}
+
+		q.WriteString("INDEX \"" + name + "\" ON " + table + " " + quotedColumnList(index.Columns))
+
+		result = append(result, q.String())
+	}
+
+	return result
+}
+
+// IndexName generates a default index name for an index on the given table
+// and columns. Any schema portion of the table name is not included.
+func IndexName(table string, columns []string) string {
+	if dot := strings.LastIndex(table, "."); dot >= 0 {
+		table = table[dot+1:]
+	}
+
+	return StripQuotes(table) + "_" + strings.Join(columns, "_") + "_idx"
+}
+
+// DefaultValue converts a column default value into the SQL text used in a column
+// definition. Numbers, booleans, NULL and the current date/time keywords are used
+// as-is; anything else is treated as a string constant and quoted.
+func DefaultValue(value string) string {
+	text := strings.TrimSpace(value)
+
+	if KeywordMatch(text, "null", "true", "false", "current_date", "current_time", "current_timestamp") {
+		return strings.ToUpper(text)
+	}
+
+	if _, err := strconv.ParseFloat(text, 64); err == nil {
+		return text
+	}
+
+	// If the value is already a single-quoted SQL string, remove the quotes so
+	// they can be added back with the embedded quotes escaped.
+	if len(text) > 1 && strings.HasPrefix(text, "'") && strings.HasSuffix(text, "'") {
+		text = text[1 : len(text)-1]
+	}
+
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
+
+// quotedColumnList generates a parenthesized list of quoted column names.
+func quotedColumnList(columns []string) string {
+	names := make([]string, len(columns))
+
+	for i, column := range columns {
+		names[i] = "\"" + column + "\""
+	}
+
+	return "(" + strings.Join(names, ",") + ")"
+}
+
+// foreignKeyClause generates the table constraint clause that defines a foreign
+// key. If the referenced table name does not include a schema, it is assumed to
+// be in the same schema as the table being created.
+func foreignKeyClause(key defs.DBForeignKey, user, provider string) string {
+	var result strings.Builder
+
+	if key.Name != "" {
+		result.WriteString("CONSTRAINT \"" + key.Name + "\" ")
+	}
+
+	table := key.Table
+	if provider != sqliteProvider {
+		table, _ = FullName(user, table)
+	}
+
+	result.WriteString("FOREIGN KEY " + quotedColumnList(key.Columns))
+	result.WriteString(" REFERENCES " + table + " " + quotedColumnList(key.References))
+
+	if key.OnDelete != "" {
+		result.WriteString(" ON DELETE " + strings.ToUpper(key.OnDelete))
+	}
+
+	return result.String()
+}
@@ -0,0 +1,100 @@
+package parsing
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/tucats/ego/defs"
+)
+
+func TestFormCreateQueryConstraints(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition defs.DBTableDefinition
+		provider   string
+		want       string
+	}{
+		{
+			name: "column with default values",
+			definition: defs.DBTableDefinition{
+				Columns: []defs.DBColumn{
+					{Name: "id", Type: "int", Default: "0"},
+					{Name: "name", Type: "string", Default: "it's"},
+				},
+			},
+			provider: "sqlite3",
+			want:     `CREATE TABLE data("id" INT DEFAULT 0, "name" CHAR VARYING DEFAULT 'it''s', "_row_id_" CHAR VARYING)`,
+		},
+		{
+			name: "composite primary key",
+			definition: defs.DBTableDefinition{
+				Columns: []defs.DBColumn{
+					{Name: "a", Type: "int"},
+					{Name: "b", Type: "int"},
+				},
+				PrimaryKey: []string{"a", "b"},
+			},
+			provider: "sqlite3",
+			want:     `CREATE TABLE data("a" INT, "b" INT, "_row_id_" CHAR VARYING, PRIMARY KEY ("a","b"))`,
+		},
+		{
+			name: "foreign key with delete action",
+			definition: defs.DBTableDefinition{
+				Columns: []defs.DBColumn{
+					{Name: "owner", Type: "int"},
+				},
+				ForeignKeys: []defs.DBForeignKey{
+					{Name: "fk_owner", Columns: []string{"owner"}, Table: "owners", References: []string{"id"}, OnDelete: "cascade"},
+				},
+			},
+			provider: "postgres",
+			want:     `CREATE TABLE "admin"."data"("owner" INT, "_row_id_" CHAR VARYING, CONSTRAINT "fk_owner" FOREIGN KEY ("owner") REFERENCES "admin"."owners" ("id") ON DELETE CASCADE)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := url.Parse("http://example.com/tables/data")
+
+			got := FormCreateQuery(u, "admin", true, tt.definition, 0, nil, tt.provider)
+			if got != tt.want {
+				t.Errorf("FormCreateQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormIndexQueries(t *testing.T) {
+	indexes := []defs.DBIndex{
+		{Name: "by_name", Columns: []string{"last", "first"}},
+		{Columns: []string{"email"}, Unique: true},
+	}
+
+	want := []string{
+		`CREATE INDEX "by_name" ON "admin"."people" ("last","first")`,
+		`CREATE UNIQUE INDEX "people_email_idx" ON "admin"."people" ("email")`,
+	}
+
+	if got := FormIndexQueries(`"admin"."people"`, "postgres", indexes); !reflect.DeepEqual(got, want) {
+		t.Errorf("FormIndexQueries() = %v, want %v", got, want)
+	}
+}
+
+func TestDefaultValue(t *testing.T) {
+	tests := map[string]string{
+		"42":                "42",
+		"-1.5":              "-1.5",
+		"true":              "TRUE",
+		"current_timestamp": "CURRENT_TIMESTAMP",
+		"hello":             "'hello'",
+		"'quoted'":          "'quoted'",
+		"x'; DROP TABLE t":  "'x''; DROP TABLE t'",
+	}
+
+	for value, want := range tests {
+		if got := DefaultValue(value); got != want {
+			t.Errorf("DefaultValue(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
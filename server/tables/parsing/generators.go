@@ -56,7 +56,10 @@ func FormSelectorDeleteQuery(u *url.URL, filter []string, columns string, table
 	return result.String(), nil
 }
 
-func FormUpdateQuery(u *url.URL, user, provider string, items map[string]interface{}) (string, []interface{}, error) {
+// FormUpdateQuery creates the UPDATE statement for the table in the URL, setting the
+// columns in the items map. The filters from the URL are combined with any additional
+// filters given, such as the row-level security policies for the table.
+func FormUpdateQuery(u *url.URL, user, provider string, items map[string]interface{}, filters ...string) (string, []interface{}, error) {
 	var result strings.Builder
 
 	if u == nil {
@@ -118,11 +121,33 @@ func FormUpdateQuery(u *url.URL, user, provider string, items map[string]interfa
 		result.WriteString(fmt.Sprintf("\"%s\"=$%d", key, filterCount))
 	}
 
-	where, err := WhereClause(FiltersFromURL(u))
+	where, err := UpdateWhereClause(u, items, filters...)
 	if err != nil {
 		return "", nil, err
 	}
 
+	if where == "" && settings.GetBool(defs.TablesServerEmptyFilterError) {
+		return "", nil, errors.Message("operation invalid with empty filter")
+	}
+
+	// If we have a filter string now, add it to the query.
+	if where != "" {
+		writeSpaceString(&result, where)
+	}
+
+	return result.String(), values, nil
+}
+
+// UpdateWhereClause creates the WHERE clause used to update the table in the URL with the
+// items map. This is the filters from the URL combined with any additional filters given,
+// and a test for the row ID if the items include one. The result is an empty string if
+// there are no filters.
+func UpdateWhereClause(u *url.URL, items map[string]interface{}, filters ...string) (string, error) {
+	where, err := WhereClause(append(FiltersFromURL(u), filters...))
+	if err != nil {
+		return "", err
+	}
+
 	// If the items we are updating includes a non-empty rowID, then graft it onto
 	// the filter string.
 	if id, found := items[defs.RowIDName]; found {
@@ -131,21 +156,12 @@ func FormUpdateQuery(u *url.URL, user, provider string, items map[string]interfa
 			if where == "" {
 				where = "WHERE " + defs.RowIDName + " = '" + idString + "'"
 			} else {
-				where = where + " " + defs.RowIDName + " = '" + idString + "'"
+				where = where + " AND " + defs.RowIDName + " = '" + idString + "'"
 			}
 		}
 	}
 
-	if where == "" && settings.GetBool(defs.TablesServerEmptyFilterError) {
-		return "", nil, errors.Message("operation invalid with empty filter")
-	}
-
-	// If we have a filter string now, add it to the query.
-	if where != "" {
-		writeSpaceString(&result, where)
-	}
-
-	return result.String(), values, nil
+	return where, nil
 }
 
 func writeSpaceString(b *strings.Builder, s string) {
@@ -199,9 +215,14 @@ func FormInsertQuery(table string, user string, provider string, items map[strin
 	return result.String(), values
 }
 
-func FormCreateQuery(u *url.URL, user string, hasAdminPrivileges bool, items []defs.DBColumn, sessionID int, w http.ResponseWriter, provider string) string {
+// FormCreateQuery generates the CREATE TABLE statement for the table named in the URL, using
+// the column definitions and constraints in the table definition. Secondary indexes are not
+// part of this statement; use FormIndexQueries to generate those.
+func FormCreateQuery(u *url.URL, user string, hasAdminPrivileges bool, definition defs.DBTableDefinition, sessionID int, w http.ResponseWriter, provider string) string {
 	var result strings.Builder
 
+	items := definition.Columns
+
 	if u == nil {
 		return ""
 	}
@@ -283,6 +304,18 @@ func FormCreateQuery(u *url.URL, user string, hasAdminPrivileges bool, items []d
 				result.WriteString(" NULL ")
 			}
 		}
+
+		if column.Default != "" {
+			writeSpaceString(&result, "DEFAULT "+DefaultValue(column.Default))
+		}
+	}
+
+	if len(definition.PrimaryKey) > 0 {
+		result.WriteString(", PRIMARY KEY " + quotedColumnList(definition.PrimaryKey))
+	}
+
+	for _, key := range definition.ForeignKeys {
+		result.WriteString(", " + foreignKeyClause(key, user, provider))
 	}
 
 	result.WriteRune(')')
@@ -293,13 +326,13 @@ func FormCreateQuery(u *url.URL, user string, hasAdminPrivileges bool, items []d
 func formWhereExpressions(filters []string) (string, error) {
 	var result strings.Builder
 
-	for i, clause := range filters {
+	for _, clause := range filters {
 		tokens := tokenizer.New(clause, true)
 		if tokens.AtEnd() {
 			continue
 		}
 
-		if i > 0 {
+		if result.Len() > 0 {
 			result.WriteString(" AND ")
 		}
 
@@ -489,6 +522,12 @@ func filterClause(tokens *tokenizer.Tokenizer, dialect int) (string, error) {
 			return "", errors.ErrMissingParenthesis
 		}
 
+		// If there is more than one value, the terms must be grouped so the conjunction
+		// does not change the meaning of any clause it is combined with.
+		if valueCount > 1 {
+			return "(" + result.String() + ")", nil
+		}
+
 		return result.String(), nil
 	}
 
@@ -121,12 +121,12 @@ func Test_filterList(t *testing.T) {
 		{
 			name: "compound contains list",
 			arg:  "https://localhost:8500/tables/data?filter=contains(foo, 'abc', 'def')",
-			want: `WHERE POSITION('abc' IN "foo") > 0 OR POSITION('def' IN "foo") > 0`,
+			want: `WHERE (POSITION('abc' IN "foo") > 0 OR POSITION('def' IN "foo") > 0)`,
 		},
 		{
 			name: "compound hasall list",
 			arg:  "https://localhost:8500/tables/data?filter=hasall(foo, 'abc', 'def')",
-			want: `WHERE POSITION('abc' IN "foo") > 0 AND POSITION('def' IN "foo") > 0`,
+			want: `WHERE (POSITION('abc' IN "foo") > 0 AND POSITION('def' IN "foo") > 0)`,
 		},
 		{
 			name: "compound list",
@@ -11,7 +11,7 @@ import (
 	"github.com/tucats/ego/server/tables/parsing"
 )
 
-func formAbstractUpdateQuery(u *url.URL, user string, items []string, values []interface{}) (string, error) {
+func formAbstractUpdateQuery(u *url.URL, user string, items []string, values []interface{}, rules []string) (string, error) {
 	var (
 		result      strings.Builder
 		filterCount int
@@ -62,22 +62,16 @@ func formAbstractUpdateQuery(u *url.URL, user string, items []string, values []i
 		result.WriteString(fmt.Sprintf(" = $%d", filterCount))
 	}
 
-	where, err := parsing.WhereClause(parsing.FiltersFromURL(u))
-	if err != nil {
-		return "", err
-	}
-
-	// If the items we are updating includes a non-empty rowID, then graft it onto
+	// If the items we are updating includes a non-empty rowID, it is added to
 	// the filter string.
+	rowID := map[string]interface{}{}
 	if hasRowID >= 0 {
-		idString := data.String(values[hasRowID])
-		if idString != "" {
-			if where == "" {
-				where = "WHERE " + defs.RowIDName + " = '" + idString + "'"
-			} else {
-				where = where + " " + defs.RowIDName + " = '" + idString + "'"
-			}
-		}
+		rowID[defs.RowIDName] = values[hasRowID]
+	}
+
+	where, err := parsing.UpdateWhereClause(u, rowID, rules...)
+	if err != nil {
+		return "", err
 	}
 
 	// If we have a filter string now, add it to the query.
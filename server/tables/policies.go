@@ -0,0 +1,185 @@
+package tables
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/server/tables/database"
+	"github.com/tucats/ego/server/tables/parsing"
+	"github.com/tucats/ego/server/tables/policies"
+	"github.com/tucats/ego/util"
+)
+
+// ReadPolicies returns the row-level security policies for a table. This operation requires
+// either ownership of the table or admin privileges.
+func ReadPolicies(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	db, table, status := openOwnedTable(session, w)
+	if status != http.StatusOK {
+		return status
+	}
+
+	defer db.Close()
+
+	return writePolicies(session, w, db, table)
+}
+
+// SetPolicies creates or replaces row-level security policies for a table. The request body
+// is a single policy object or an array of them. A policy replaces any existing policy for the
+// table with the same name. The response is the complete list of policies for the table.
+func SetPolicies(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	db, table, status := openOwnedTable(session, w)
+	if status != http.StatusOK {
+		return status
+	}
+
+	defer db.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
+	}
+
+	list := []defs.DBPolicy{}
+
+	if text := strings.TrimSpace(string(b)); strings.HasPrefix(text, "[") {
+		err = json.Unmarshal(b, &list)
+	} else {
+		item := defs.DBPolicy{}
+		if err = json.Unmarshal(b, &item); err == nil {
+			list = append(list, item)
+		}
+	}
+
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, "invalid policy payload: "+err.Error(), http.StatusBadRequest)
+	}
+
+	for n := range list {
+		if err := policies.Validate(&list[n]); err != nil {
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	for _, policy := range list {
+		if err := policies.Set(tx, table, policy); err != nil {
+			_ = tx.Rollback()
+
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
+		ui.Log(ui.TableLogger, "table.policy.set", ui.A{
+			"session": session.ID,
+			"table":   parsing.StripQuotes(table),
+			"name":    policy.Name,
+			"filter":  policy.Filter})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	return writePolicies(session, w, db, table)
+}
+
+// DeletePolicies deletes the row-level security policy named by the "name" parameter from a
+// table. If no name is given, all the policies for the table are deleted.
+func DeletePolicies(session *server.Session, w http.ResponseWriter, r *http.Request) int {
+	db, table, status := openOwnedTable(session, w)
+	if status != http.StatusOK {
+		return status
+	}
+
+	defer db.Close()
+
+	name := r.URL.Query().Get(defs.NameParameterName)
+
+	count, err := policies.Delete(db.Handle, table, name)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	if name != "" && count == 0 {
+		return util.ErrorResponse(w, session.ID, "no such policy: "+name, http.StatusNotFound)
+	}
+
+	ui.Log(ui.TableLogger, "table.policy.deleted", ui.A{
+		"session": session.ID,
+		"table":   parsing.StripQuotes(table),
+		"count":   count})
+
+	return writePolicies(session, w, db, table)
+}
+
+// openOwnedTable opens the Tables database and determines the fully qualified name of the
+// table in the request. The user must be an administrator or own the table (that is, the table
+// must be in the user's schema). If the result status is not http.StatusOK, the error response
+// has already been written.
+func openOwnedTable(session *server.Session, w http.ResponseWriter) (*database.Database, string, int) {
+	db, err := database.Open(&session.User, "", 0)
+	if err != nil {
+		return nil, "", util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	table, _ := parsing.FullName(session.User, data.String(session.URLParts["table"]))
+
+	if !session.Admin && parsing.TableNameParts(session.User, table)[0] != session.User {
+		db.Close()
+
+		return nil, "", util.ErrorResponse(w, session.ID, "Not authorized to manage table", http.StatusForbidden)
+	}
+
+	return db, table, http.StatusOK
+}
+
+// writePolicies writes the response containing the list of policies for the table.
+func writePolicies(session *server.Session, w http.ResponseWriter, db *database.Database, table string) int {
+	list, err := policies.List(db.Handle, table)
+	if err != nil {
+		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+	}
+
+	reply := defs.DBPolicyList{
+		ServerInfo: util.MakeServerInfo(session.ID),
+		Table:      parsing.StripQuotes(table),
+		Policies:   list,
+		Count:      len(list),
+		Status:     http.StatusOK,
+	}
+
+	w.Header().Add(defs.ContentTypeHeader, defs.PoliciesMediaType)
+	w.WriteHeader(http.StatusOK)
+
+	b, _ := json.MarshalIndent(reply, ui.JSONIndentPrefix, ui.JSONIndentSpacer)
+	_, _ = w.Write(b)
+	session.ResponseLength += len(b)
+
+	if ui.IsActive(ui.RestLogger) {
+		ui.WriteLog(ui.RestLogger, "rest.response.payload", ui.A{
+			"session": session.ID,
+			"body":    string(b)})
+	}
+
+	return http.StatusOK
+}
+
+// policyFilters returns the row-level security policy filters that apply to an operation on
+// a table. As with table permissions, policies only apply to tables in the Tables database, and
+// not to tables accessed using a data source name.
+func policyFilters(session *server.Session, db *database.Database, dsnName, table, operation string) ([]string, error) {
+	if dsnName != "" {
+		return nil, nil
+	}
+
+	return policies.ForSession(session, db.Handle, table, operation)
+}
@@ -0,0 +1,302 @@
+// Package policies manages the row-level security policies for tables in the
+// Tables database. A policy is a filter expression that is added to every read,
+// update or delete of the table by a user who is not an administrator, so the
+// user can only see or change the rows that match the filter.
+package policies
+
+import (
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/auth"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/server/tables/database"
+	"github.com/tucats/ego/server/tables/parsing"
+	"github.com/tucats/ego/util"
+)
+
+const (
+	ReadOperation   = "read"
+	UpdateOperation = "update"
+	DeleteOperation = "delete"
+)
+
+const (
+	policiesCreateTableQuery = `CREATE TABLE IF NOT EXISTS admin.policies(tablename CHAR VARYING, name CHAR VARYING, operations CHAR VARYING, filter CHAR VARYING)`
+	policiesSelectQuery      = `SELECT name, operations, filter FROM admin.policies WHERE tablename = $1 ORDER BY name`
+	policiesDeleteQuery      = `DELETE FROM admin.policies WHERE tablename = $1 AND name = $2`
+	policiesDeleteAllQuery   = `DELETE FROM admin.policies WHERE tablename = $1`
+	policiesInsertQuery      = `INSERT INTO admin.policies (tablename, name, operations, filter) VALUES($1, $2, $3, $4)`
+)
+
+// Querier is the part of a database connection used to read and write policies. It
+// is satisfied by both a database handle and a transaction.
+type Querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+var (
+	createLock sync.Mutex
+	created    bool
+)
+
+// Start creates the table that holds the policies when the server starts. If the
+// server does not have a tables database, there is nothing to do.
+func Start() {
+	db, err := database.Open(nil, "", 0)
+	if err != nil {
+		if !errors.Equals(err, errors.ErrNoDatabase) {
+			ui.Log(ui.TableLogger, "table.policies.error", ui.A{
+				"error": err.Error()})
+		}
+
+		return
+	}
+
+	err = Initialize(db.Handle)
+
+	db.Close()
+
+	if err != nil {
+		ui.Log(ui.TableLogger, "table.policies.error", ui.A{
+			"error": err.Error()})
+	}
+}
+
+// Initialize creates the table that holds the policies if it does not already exist.
+// This is done when the server starts, so the table is not created again for every
+// request that reads or changes the policies.
+func Initialize(db Querier) error {
+	createLock.Lock()
+	defer createLock.Unlock()
+
+	if _, err := db.Exec(policiesCreateTableQuery); err != nil {
+		return errors.New(err)
+	}
+
+	created = true
+
+	return nil
+}
+
+// createTable creates the table that holds the policies if it was not created when
+// the server started, for example because the database could not be reached.
+func createTable(db Querier) error {
+	createLock.Lock()
+	done := created
+	createLock.Unlock()
+
+	if done {
+		return nil
+	}
+
+	return Initialize(db)
+}
+
+// List returns the policies defined for the table, sorted by name. The table name
+// must include the schema.
+func List(db Querier, table string) ([]defs.DBPolicy, error) {
+	if err := createTable(db); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(policiesSelectQuery, parsing.StripQuotes(table))
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	defer rows.Close()
+
+	result := []defs.DBPolicy{}
+
+	for rows.Next() {
+		var name, operations, filter string
+
+		if err := rows.Scan(&name, &operations, &filter); err != nil {
+			return nil, errors.New(err)
+		}
+
+		policy := defs.DBPolicy{Name: name, Filter: filter}
+
+		for _, operation := range strings.Split(operations, ",") {
+			if operation = strings.TrimSpace(operation); operation != "" {
+				policy.Operations = append(policy.Operations, operation)
+			}
+		}
+
+		result = append(result, policy)
+	}
+
+	return result, nil
+}
+
+// Set stores a policy for the table, replacing any existing policy with the same
+// name. The policy must already have been validated.
+func Set(db Querier, table string, policy defs.DBPolicy) error {
+	if err := createTable(db); err != nil {
+		return err
+	}
+
+	table = parsing.StripQuotes(table)
+
+	// Upsert isn't always available, so delete any existing policy before
+	// adding in the new one.
+	if _, err := db.Exec(policiesDeleteQuery, table, policy.Name); err != nil {
+		return errors.New(err)
+	}
+
+	_, err := db.Exec(policiesInsertQuery, table, policy.Name, strings.Join(policy.Operations, ","), policy.Filter)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// Delete removes the named policy from the table, or all policies for the table if
+// the name is empty. The result is the number of policies deleted.
+func Delete(db Querier, table, name string) (int, error) {
+	var (
+		result sql.Result
+		err    error
+	)
+
+	if err = createTable(db); err != nil {
+		return 0, err
+	}
+
+	table = parsing.StripQuotes(table)
+
+	if name == "" {
+		result, err = db.Exec(policiesDeleteAllQuery, table)
+	} else {
+		result, err = db.Exec(policiesDeleteQuery, table, name)
+	}
+
+	if err != nil {
+		return 0, errors.New(err)
+	}
+
+	count, _ := result.RowsAffected()
+
+	return int(count), nil
+}
+
+// Validate checks that a policy has a name, a list of known operations, and a filter
+// that can be converted to a SQL expression. The operation names are normalized to
+// lower case and sorted, and an empty list is replaced with all operations.
+func Validate(policy *defs.DBPolicy) error {
+	policy.Name = strings.TrimSpace(policy.Name)
+	if policy.Name == "" {
+		return errors.ErrMissingPolicyName
+	}
+
+	operations := []string{}
+
+	for _, operation := range policy.Operations {
+		operation = strings.ToLower(strings.TrimSpace(operation))
+		if !util.InList(operation, ReadOperation, UpdateOperation, DeleteOperation) {
+			return errors.ErrInvalidPolicy.Context(operation)
+		}
+
+		if !util.InList(operation, operations...) {
+			operations = append(operations, operation)
+		}
+	}
+
+	if len(operations) == 0 {
+		operations = []string{ReadOperation, UpdateOperation, DeleteOperation}
+	}
+
+	sort.Strings(operations)
+	policy.Operations = operations
+
+	// The filter must be valid no matter who the user is, so check it using a sample user.
+	filter := Expand(policy.Filter, defs.User{Name: "user"})
+	if strings.TrimSpace(filter) == "" {
+		return errors.ErrInvalidPolicy.Context(policy.Name)
+	}
+
+	if _, err := parsing.WhereClause([]string{filter}); err != nil {
+		return errors.ErrInvalidPolicy.Context(policy.Name + ": " + err.Error())
+	}
+
+	return nil
+}
+
+// Expand replaces the references to the user in a policy filter with the values for
+// the given user. The values are substituted as quoted strings.
+func Expand(filter string, user defs.User) string {
+	id := ""
+	if user.ID != uuid.Nil {
+		id = user.ID.String()
+	}
+
+	return strings.NewReplacer(
+		"{{user}}", strconv.Quote(user.Name),
+		"{{id}}", strconv.Quote(id),
+		"{{permissions}}", strconv.Quote(strings.Join(user.Permissions, ",")),
+	).Replace(filter)
+}
+
+// Filters returns the filter expressions of the policies for the table that apply to
+// the given operation, with the references to the user replaced by the values for the
+// given user. The table name must include the schema.
+func Filters(db Querier, user defs.User, table, operation string) ([]string, error) {
+	policies, err := List(db, table)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+
+	for _, policy := range policies {
+		if len(policy.Operations) == 0 || util.InList(operation, policy.Operations...) {
+			result = append(result, Expand(policy.Filter, user))
+		}
+	}
+
+	return result, nil
+}
+
+// ForSession returns the policy filters that apply to an operation on the table by the
+// user of the session. Administrators are not subject to policies, so the result is
+// always empty for them.
+func ForSession(session *server.Session, db Querier, table, operation string) ([]string, error) {
+	if session.Admin {
+		return nil, nil
+	}
+
+	user := defs.User{Name: session.User}
+
+	if auth.AuthService != nil {
+		if u, err := auth.AuthService.ReadUser(session.User, true); err == nil {
+			user = u
+		}
+	}
+
+	table, _ = parsing.FullName(session.User, table)
+
+	result, err := Filters(db, user, table, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) > 0 {
+		ui.Log(ui.TableLogger, "table.policies", ui.A{
+			"session":   session.ID,
+			"table":     parsing.StripQuotes(table),
+			"operation": operation,
+			"count":     len(result)})
+	}
+
+	return result, nil
+}
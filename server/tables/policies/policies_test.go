@@ -0,0 +1,163 @@
+package policies
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/server/tables/parsing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     defs.DBPolicy
+		operations []string
+		wantErr    bool
+	}{
+		{
+			name:       "default operations",
+			policy:     defs.DBPolicy{Name: "tenant", Filter: "EQ(owner,{{user}})"},
+			operations: []string{"delete", "read", "update"},
+		},
+		{
+			name:       "normalized operations",
+			policy:     defs.DBPolicy{Name: "tenant", Operations: []string{" Read", "DELETE", "read"}, Filter: "EQ(owner,{{user}})"},
+			operations: []string{"delete", "read"},
+		},
+		{
+			name:    "missing name",
+			policy:  defs.DBPolicy{Filter: "EQ(owner,{{user}})"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid operation",
+			policy:  defs.DBPolicy{Name: "tenant", Operations: []string{"insert"}, Filter: "EQ(owner,{{user}})"},
+			wantErr: true,
+		},
+		{
+			name:    "missing filter",
+			policy:  defs.DBPolicy{Name: "tenant"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid filter",
+			policy:  defs.DBPolicy{Name: "tenant", Filter: "SAME(owner,{{user}})"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && !reflect.DeepEqual(tt.policy.Operations, tt.operations) {
+				t.Errorf("Validate() operations = %v, want %v", tt.policy.Operations, tt.operations)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	id := uuid.New()
+	user := defs.User{Name: "tom", ID: id, Permissions: []string{"ego.logon", "tenant_a"}}
+
+	got := Expand("OR(EQ(owner,{{user}}),EQ(owner_id,{{id}}),CONTAINS({{permissions}},\"tenant_all\"))", user)
+	want := `OR(EQ(owner,"tom"),EQ(owner_id,"` + id.String() + `"),CONTAINS("ego.logon,tenant_a","tenant_all"))`
+
+	if got != want {
+		t.Errorf("Expand() = %v, want %v", got, want)
+	}
+
+	if got := Expand("EQ(owner_id,{{id}})", defs.User{Name: "tom"}); got != `EQ(owner_id,"")` {
+		t.Errorf("Expand() with no ID = %v", got)
+	}
+}
+
+func TestFilters(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	// Each connection to an in-memory database is a new database, so use only one. The
+	// policies are stored in the "admin" schema, which is an attached database in sqlite.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(`ATTACH DATABASE ':memory:' AS admin`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Initialize(db); err != nil {
+		t.Fatal(err)
+	}
+
+	tenant := defs.DBPolicy{Name: "tenant", Filter: "EQ(tenant,{{user}})"}
+	open := defs.DBPolicy{Name: "open", Operations: []string{"read"}, Filter: "EQ(hidden,false)"}
+
+	for _, policy := range []defs.DBPolicy{tenant, open} {
+		if err := Validate(&policy); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Set(db, `"tom"."orders"`, policy); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Replacing a policy does not create a second one with the same name.
+	tenant.Filter = "EQ(tenant_name,{{user}})"
+	_ = Validate(&tenant)
+
+	if err := Set(db, "tom.orders", tenant); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := List(db, "tom.orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 2 || list[0].Name != "open" || list[1].Filter != "EQ(tenant_name,{{user}})" {
+		t.Errorf("List() = %v", list)
+	}
+
+	user := defs.User{Name: "mary"}
+
+	got, _ := Filters(db, user, "tom.orders", ReadOperation)
+	if want := []string{"EQ(hidden,false)", `EQ(tenant_name,"mary")`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Filters(read) = %v, want %v", got, want)
+	}
+
+	got, _ = Filters(db, user, "tom.orders", DeleteOperation)
+	if want := []string{`EQ(tenant_name,"mary")`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Filters(delete) = %v, want %v", got, want)
+	}
+
+	// The policy filters are combined with the filters from the request, so a request
+	// filter with alternatives cannot widen the rows selected by a policy.
+	where, _ := parsing.WhereClause(append([]string{"CONTAINS(name,'a','b')"}, got...))
+	if want := `WHERE (POSITION('a' IN "name") > 0 OR POSITION('b' IN "name") > 0) AND ("tenant_name" = 'mary')`; where != want {
+		t.Errorf("WhereClause() = %v, want %v", where, want)
+	}
+
+	if count, _ := Delete(db, "tom.orders", "open"); count != 1 {
+		t.Errorf("Delete() count = %d, want 1", count)
+	}
+
+	if count, _ := Delete(db, "tom.orders", ""); count != 1 {
+		t.Errorf("Delete() all count = %d, want 1", count)
+	}
+
+	if got, _ := Filters(db, user, "tom.orders", ReadOperation); len(got) != 0 {
+		t.Errorf("Filters() after delete = %v", got)
+	}
+}
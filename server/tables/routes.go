@@ -57,7 +57,7 @@ func AddStaticRoutes(router *server.Router) {
 		Parameter(defs.AbstractParameterName, data.BoolTypeName).
 		Parameter(defs.FilterParameterName, defs.Any).
 		Parameter(defs.UserParameterName, data.StringTypeName).
-		AcceptMedia(defs.RowSetMediaType, defs.AbstractRowSetMediaType).
+		AcceptMedia(defs.RowSetMediaType, defs.AbstractRowSetMediaType, defs.CSVMediaType, defs.NDJSONMediaType).
 		Class(server.TableRequestCounter)
 
 	// Read rows from a table via a DSN
@@ -71,7 +71,7 @@ func AddStaticRoutes(router *server.Router) {
 		Parameter(defs.AbstractParameterName, data.BoolTypeName).
 		Parameter(defs.FilterParameterName, defs.Any).
 		Parameter(defs.UserParameterName, data.StringTypeName).
-		AcceptMedia(defs.RowSetMediaType, defs.AbstractRowSetMediaType).
+		AcceptMedia(defs.RowSetMediaType, defs.AbstractRowSetMediaType, defs.CSVMediaType, defs.NDJSONMediaType).
 		Class(server.TableRequestCounter)
 
 	// Insert rows into a table.
@@ -80,7 +80,10 @@ func AddStaticRoutes(router *server.Router) {
 		Permissions("table_modify").
 		Parameter(defs.AbstractParameterName, data.BoolTypeName).
 		Parameter(defs.UserParameterName, data.StringTypeName).
-		AcceptMedia(defs.RowSetMediaType, defs.AbstractRowSetMediaType).
+		Parameter(defs.BatchParameterName, data.IntTypeName).
+		Parameter(defs.MaxErrorsParameterName, data.IntTypeName).
+		Parameter(defs.MapParameterName, "list").
+		AcceptMedia(defs.RowSetMediaType, defs.AbstractRowSetMediaType, defs.ImportMediaType).
 		Class(server.TableRequestCounter)
 
 	// Insert rows into a table via a DSN
@@ -89,7 +92,10 @@ func AddStaticRoutes(router *server.Router) {
 		Permissions("table_modify").
 		Parameter(defs.AbstractParameterName, data.BoolTypeName).
 		Parameter(defs.UserParameterName, data.StringTypeName).
-		AcceptMedia(defs.RowSetMediaType, defs.AbstractRowSetMediaType).
+		Parameter(defs.BatchParameterName, data.IntTypeName).
+		Parameter(defs.MaxErrorsParameterName, data.IntTypeName).
+		Parameter(defs.MapParameterName, "list").
+		AcceptMedia(defs.RowSetMediaType, defs.AbstractRowSetMediaType, defs.ImportMediaType).
 		Class(server.TableRequestCounter)
 
 	// Delete rows from a table.
@@ -153,6 +159,52 @@ func AddStaticRoutes(router *server.Router) {
 		Parameter(defs.UserParameterName, data.StringTypeName).
 		Class(server.TableRequestCounter)
 
+	// Read the row-level security policies for a table
+	router.New(defs.TablesNamePoliciesPath, ReadPolicies, http.MethodGet).
+		Authentication(true, false).
+		Permissions("table_admin").
+		AcceptMedia(defs.PoliciesMediaType).
+		Class(server.TableRequestCounter)
+
+	// Create or replace row-level security policies for a table
+	router.New(defs.TablesNamePoliciesPath, SetPolicies, http.MethodPut).
+		Authentication(true, false).
+		Permissions("table_admin").
+		AcceptMedia(defs.PoliciesMediaType).
+		Class(server.TableRequestCounter)
+
+	// Delete row-level security policies from a table
+	router.New(defs.TablesNamePoliciesPath, DeletePolicies, http.MethodDelete).
+		Authentication(true, false).
+		Permissions("table_admin").
+		Parameter(defs.NameParameterName, data.StringTypeName).
+		AcceptMedia(defs.PoliciesMediaType).
+		Class(server.TableRequestCounter)
+
+	// Read the changes made to a table, waiting for changes if requested
+	router.New(defs.TablesNameChangesPath, ReadChanges, http.MethodGet).
+		Authentication(true, false).
+		Permissions("table_read").
+		Parameter(defs.SinceParameterName, data.IntTypeName).
+		Parameter(defs.LimitParameterName, data.IntTypeName).
+		Parameter(defs.WaitParameterName, data.IntTypeName).
+		AcceptMedia(defs.ChangesMediaType).
+		Class(server.TableRequestCounter)
+
+	// Start recording the changes made to a table
+	router.New(defs.TablesNameChangesPath, EnableChanges, http.MethodPut).
+		Authentication(true, false).
+		Permissions("table_admin").
+		AcceptMedia(defs.ChangesMediaType).
+		Class(server.TableRequestCounter)
+
+	// Stop recording the changes made to a table, and delete its change log
+	router.New(defs.TablesNameChangesPath, DisableChanges, http.MethodDelete).
+		Authentication(true, false).
+		Permissions("table_admin").
+		AcceptMedia(defs.ChangesMediaType).
+		Class(server.TableRequestCounter)
+
 	// Get metadata for a table
 	router.New(defs.TablesPath+tableParameter, ReadTable, http.MethodGet).
 		Authentication(true, false).
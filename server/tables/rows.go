@@ -16,6 +16,7 @@ import (
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/server/dsns"
 	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/server/tables/changes"
 	"github.com/tucats/ego/server/tables/database"
 	"github.com/tucats/ego/server/tables/parsing"
 	"github.com/tucats/ego/util"
@@ -55,7 +56,12 @@ func DeleteRows(session *server.Session, w http.ResponseWriter, r *http.Request)
 		columns := parsing.ColumnsFromURL(r.URL)
 		filters := parsing.FiltersFromURL(r.URL)
 
-		q, err := parsing.FormSelectorDeleteQuery(r.URL, filters, columns, tableName, session.User, deleteVerb, db.Provider)
+		rules, err := policyFilters(session, db, dsnName, tableName, deleteOperation)
+		if err != nil {
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
+		q, err := parsing.FormSelectorDeleteQuery(r.URL, append(filters, rules...), columns, tableName, session.User, deleteVerb, db.Provider)
 		if err != nil {
 			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
 		}
@@ -64,12 +70,54 @@ func DeleteRows(session *server.Session, w http.ResponseWriter, r *http.Request)
 			return util.ErrorResponse(w, session.ID, filterErrorMessage(q), http.StatusBadRequest)
 		}
 
+		// The delete is done in a transaction, so the rows deleted can be recorded in the
+		// change log for the table, if there is one.
+		tx, err := db.Begin()
+		if err != nil {
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
+		changeLog, err := tableChangeLog(session, db, tx, dsnName, tableName)
+		if err != nil {
+			_ = tx.Rollback()
+
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
+		var before []map[string]interface{}
+
+		if changeLog.Enabled() {
+			where, _ := parsing.WhereClause(append(filters, rules...))
+
+			if before, err = changeLog.Rows(where); err != nil {
+				_ = tx.Rollback()
+
+				return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+			}
+		}
+
 		ui.Log(ui.SQLLogger, "sq.exec", ui.A{
 			"session": session.ID,
 			"sql":     q})
 
-		rows, err := db.Exec(q)
+		rows, err := tx.Exec(q)
 		if err == nil {
+			for _, row := range before {
+				if err = changeLog.Record(changes.DeleteOperation, row, nil); err != nil {
+					break
+				}
+			}
+		}
+
+		if err == nil {
+			err = tx.Commit()
+		} else {
+			_ = tx.Rollback()
+		}
+
+		if err == nil {
+			changeLog.Done()
+
 			rowCount, _ := rows.RowsAffected()
 
 			if rowCount == 0 && settings.GetBool(defs.TablesServerEmptyRowsetError) {
@@ -123,6 +171,11 @@ func InsertRows(session *server.Session, w http.ResponseWriter, r *http.Request)
 		return InsertAbstractRows(session.User, session.Admin, tableName, session, w, r)
 	}
 
+	// If the payload is CSV or JSON Lines data, this is a bulk import of rows.
+	if isImportMediaType(r) {
+		return ImportRows(session, w, r)
+	}
+
 	db, err := database.Open(&session.User, dsnName, dsns.DSNWriteAction)
 	if err == nil && db != nil && db.Handle != nil {
 		defer db.Close()
@@ -173,7 +226,15 @@ func InsertRows(session *server.Session, w http.ResponseWriter, r *http.Request)
 		tx, _ := db.Begin()
 		count := 0
 
-		count, httpStatus = insertRowSet(rowSet, columns, w, session, r, db, count, tx)
+		var changeLog *changes.Log
+
+		if changeLog, err = tableChangeLog(session, db, tx, dsnName, tableName); err != nil {
+			_ = tx.Rollback()
+
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
+		count, httpStatus = insertRowSet(rowSet, columns, w, session, r, db, count, tx, changeLog)
 		if httpStatus > http.StatusOK {
 			return httpStatus
 		}
@@ -202,6 +263,8 @@ func InsertRows(session *server.Session, w http.ResponseWriter, r *http.Request)
 
 		err = tx.Commit()
 		if err == nil {
+			changeLog.Done()
+
 			status := http.StatusOK
 			ui.Log(ui.TableLogger, "table.inserted.rows", ui.A{
 				"session": session.ID,
@@ -230,8 +293,11 @@ func InsertRows(session *server.Session, w http.ResponseWriter, r *http.Request)
 
 // insertRowSet does the actual work of inserting the rows from the row set object into the database, and reporting any errors. The
 // result is the count of rows inserted, and the HTTP status code if an error occurred.
-func insertRowSet(rowSet defs.DBRowSet, columns []defs.DBColumn, w http.ResponseWriter, session *server.Session, r *http.Request, db *database.Database, count int, tx *sql.Tx) (int, int) {
+func insertRowSet(rowSet defs.DBRowSet, columns []defs.DBColumn, w http.ResponseWriter, session *server.Session, r *http.Request, db *database.Database, count int, tx *sql.Tx, changeLog *changes.Log) (int, int) {
 	for _, row := range rowSet.Rows {
+		// Save the values before any date/time values are quoted, to record in the change log.
+		after := changes.Merge(row, nil)
+
 		for _, column := range columns {
 			v, ok := row[column.Name]
 			if !ok && settings.GetBool(defs.TableServerPartialInsertError) {
@@ -276,7 +342,7 @@ func insertRowSet(rowSet defs.DBRowSet, columns []defs.DBColumn, w http.Response
 			"session": session.ID,
 			"query":   q})
 
-		_, err := db.Exec(q, values...)
+		_, err := tx.Exec(q, values...)
 		if err == nil {
 			count++
 		} else {
@@ -284,6 +350,12 @@ func insertRowSet(rowSet defs.DBRowSet, columns []defs.DBColumn, w http.Response
 
 			return 0, util.ErrorResponse(w, session.ID, err.Error(), http.StatusConflict)
 		}
+
+		if err = changeLog.Record(changes.InsertOperation, nil, after); err != nil {
+			_ = tx.Rollback()
+
+			return 0, util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
 	}
 
 	return count, http.StatusOK
@@ -353,7 +425,14 @@ func ReadRows(session *server.Session, w http.ResponseWriter, r *http.Request) i
 			return util.ErrorResponse(w, session.ID, "User does not have read permission", http.StatusForbidden)
 		}
 
-		queryText, err = parsing.FormSelectorDeleteQuery(r.URL, parsing.FiltersFromURL(r.URL), parsing.ColumnsFromURL(r.URL), tableName, session.User, selectVerb, db.Provider)
+		var rules []string
+
+		rules, err = policyFilters(session, db, dsnName, tableName, readOperation)
+		if err != nil {
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
+		queryText, err = parsing.FormSelectorDeleteQuery(r.URL, append(parsing.FiltersFromURL(r.URL), rules...), parsing.ColumnsFromURL(r.URL), tableName, session.User, selectVerb, db.Provider)
 		if err != nil {
 			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
 		}
@@ -366,6 +445,17 @@ func ReadRows(session *server.Session, w http.ResponseWriter, r *http.Request) i
 			"session": session.ID,
 			"query":   queryText})
 
+		// If the client asked for CSV or JSON Lines data, stream the rows as a bulk export.
+		if mediaType := exportMediaType(r); mediaType != "" {
+			if err = exportRowData(db.Handle, queryText, mediaType, session, w); err != nil {
+				ui.Log(ui.TableLogger, "table.read.error", ui.A{
+					"session": session.ID,
+					"error":   err.Error()})
+			}
+
+			return http.StatusOK
+		}
+
 		if err = readRowData(db.Handle, queryText, session, w); err == nil {
 			return http.StatusOK
 		}
@@ -490,6 +580,13 @@ func UpdateRows(session *server.Session, w http.ResponseWriter, r *http.Request)
 			return httpStatus
 		}
 
+		var rules []string
+
+		rules, err = policyFilters(session, db, dsnName, tableName, updateOperation)
+		if err != nil {
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
 		// Get the rowset specification from the payload for what is to be updated.
 		rowSet, err, httpStatus = getUpdateRows(r, session, err, w, excludeList)
 		if httpStatus > http.StatusOK {
@@ -499,15 +596,25 @@ func UpdateRows(session *server.Session, w http.ResponseWriter, r *http.Request)
 		// Start a transaction to ensure atomicity of the entire update
 		tx, _ := db.Begin()
 
+		var changeLog *changes.Log
+
+		if changeLog, err = tableChangeLog(session, db, tx, dsnName, tableName); err != nil {
+			_ = tx.Rollback()
+
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
 		// Loop over the row set doing the update
 
-		count, httpStatus = updateRowSet(rowSet, excludeList, session, r, db, tx, w, count)
+		count, httpStatus = updateRowSet(rowSet, excludeList, rules, session, r, db, tx, w, count, changeLog)
 		if httpStatus > http.StatusOK {
 			return httpStatus
 		}
 
 		if err == nil {
-			err = tx.Commit()
+			if err = tx.Commit(); err == nil {
+				changeLog.Done()
+			}
 		} else {
 			_ = tx.Rollback()
 		}
@@ -550,7 +657,7 @@ func UpdateRows(session *server.Session, w http.ResponseWriter, r *http.Request)
 	return http.StatusOK
 }
 
-func updateRowSet(rowSet defs.DBRowSet, excludeList map[string]bool, session *server.Session, r *http.Request, db *database.Database, tx *sql.Tx, w http.ResponseWriter, count int) (int, int) {
+func updateRowSet(rowSet defs.DBRowSet, excludeList map[string]bool, rules []string, session *server.Session, r *http.Request, db *database.Database, tx *sql.Tx, w http.ResponseWriter, count int, changeLog *changes.Log) (int, int) {
 	for _, rowData := range rowSet.Rows {
 		hasRowID := false
 
@@ -574,7 +681,7 @@ func updateRowSet(rowSet defs.DBRowSet, excludeList map[string]bool, session *se
 			"session": session.ID,
 			"data":    rowData})
 
-		q, values, err := parsing.FormUpdateQuery(r.URL, session.User, db.Provider, rowData)
+		q, values, err := parsing.FormUpdateQuery(r.URL, session.User, db.Provider, rowData, rules...)
 		if err != nil {
 			ui.Log(ui.SQLLogger, "sql.query.error", ui.A{
 				"session": session.ID,
@@ -586,11 +693,24 @@ func updateRowSet(rowSet defs.DBRowSet, excludeList map[string]bool, session *se
 			return 0, util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
 		}
 
+		// If the table has a change log, read the rows that are about to be updated.
+		var before []map[string]interface{}
+
+		if changeLog.Enabled() {
+			where, _ := parsing.UpdateWhereClause(r.URL, rowData, rules...)
+
+			if before, err = changeLog.Rows(where); err != nil {
+				_ = tx.Rollback()
+
+				return 0, util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+			}
+		}
+
 		ui.Log(ui.SQLLogger, "sql.query", ui.A{
 			"session": session.ID,
 			"query":   q})
 
-		counts, err := db.Exec(q, values...)
+		counts, err := tx.Exec(q, values...)
 		if err == nil {
 			rowsAffected, _ := counts.RowsAffected()
 			count = count + int(rowsAffected)
@@ -599,6 +719,14 @@ func updateRowSet(rowSet defs.DBRowSet, excludeList map[string]bool, session *se
 
 			return 0, util.ErrorResponse(w, session.ID, err.Error(), http.StatusConflict)
 		}
+
+		for _, row := range before {
+			if err = changeLog.Record(changes.UpdateOperation, row, changes.Merge(row, rowData)); err != nil {
+				_ = tx.Rollback()
+
+				return 0, util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+			}
+		}
 	}
 
 	return count, http.StatusOK
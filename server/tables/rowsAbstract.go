@@ -14,6 +14,7 @@ import (
 	"github.com/tucats/ego/errors"
 	"github.com/tucats/ego/server/dsns"
 	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/server/tables/changes"
 	"github.com/tucats/ego/server/tables/database"
 	"github.com/tucats/ego/server/tables/parsing"
 	"github.com/tucats/ego/util"
@@ -127,6 +128,14 @@ func InsertAbstractRows(user string, isAdmin bool, tableName string, session *se
 		tx, _ := db.Begin()
 		count := 0
 
+		var changeLog *changes.Log
+
+		if changeLog, err = tableChangeLog(session, db, tx, dsnName, tableName); err != nil {
+			_ = tx.Rollback()
+
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
 		for _, row := range rowSet.Rows {
 			columnNames := make([]string, len(rowSet.Columns))
 			for i, c := range rowSet.Columns {
@@ -135,7 +144,7 @@ func InsertAbstractRows(user string, isAdmin bool, tableName string, session *se
 
 			q, values := formAbstractInsertQuery(r.URL, user, columnNames, row)
 
-			_, err := db.Exec(q, values...)
+			_, err := tx.Exec(q, values...)
 			if err == nil {
 				count++
 			} else {
@@ -143,6 +152,12 @@ func InsertAbstractRows(user string, isAdmin bool, tableName string, session *se
 
 				return util.ErrorResponse(w, session.ID, err.Error(), http.StatusConflict)
 			}
+
+			if err = changeLog.Record(changes.InsertOperation, nil, abstractRow(columnNames, row)); err != nil {
+				_ = tx.Rollback()
+
+				return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+			}
 		}
 
 		if err == nil {
@@ -166,6 +181,8 @@ func InsertAbstractRows(user string, isAdmin bool, tableName string, session *se
 
 			err = tx.Commit()
 			if err == nil {
+				changeLog.Done()
+
 				ui.Log(ui.TableLogger, "table.inserted.rows", ui.A{
 					"session": session.ID,
 					"count":   count})
@@ -204,9 +221,17 @@ func ReadAbstractRows(user string, isAdmin bool, tableName string, session *serv
 			return util.ErrorResponse(w, session.ID, "User does not have read permission", http.StatusForbidden)
 		}
 
-		var q string
+		var (
+			q     string
+			rules []string
+		)
 
-		q, err = parsing.FormSelectorDeleteQuery(r.URL, parsing.FiltersFromURL(r.URL), parsing.ColumnsFromURL(r.URL), tableName, user, selectVerb, db.Provider)
+		rules, err = policyFilters(session, db, dsnName, tableName, readOperation)
+		if err != nil {
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
+		q, err = parsing.FormSelectorDeleteQuery(r.URL, append(parsing.FiltersFromURL(r.URL), rules...), parsing.ColumnsFromURL(r.URL), tableName, user, selectVerb, db.Provider)
 		if err != nil {
 			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusBadRequest)
 		}
@@ -373,6 +398,13 @@ func UpdateAbstractRows(user string, isAdmin bool, tableName string, session *se
 			return util.ErrorResponse(w, session.ID, "User does not have update permission", http.StatusForbidden)
 		}
 
+		var rules []string
+
+		rules, err = policyFilters(session, db, dsnName, tableName, updateOperation)
+		if err != nil {
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
 		// Get the payload in a string.
 		buf := new(strings.Builder)
 		_, _ = io.Copy(buf, r.Body)
@@ -401,6 +433,14 @@ func UpdateAbstractRows(user string, isAdmin bool, tableName string, session *se
 		// Start a transaction to ensure atomicity of the entire update
 		tx, _ := db.Begin()
 
+		var changeLog *changes.Log
+
+		if changeLog, err = tableChangeLog(session, db, tx, dsnName, tableName); err != nil {
+			_ = tx.Rollback()
+
+			return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+		}
+
 		// Loop over the row set doing the updates
 		for _, data := range rowSet.Rows {
 			ui.Log(ui.TableLogger, "table.values", ui.A{
@@ -413,16 +453,31 @@ func UpdateAbstractRows(user string, isAdmin bool, tableName string, session *se
 				columns[i] = c.Name
 			}
 
-			q, err := formAbstractUpdateQuery(r.URL, user, columns, data)
+			q, err := formAbstractUpdateQuery(r.URL, user, columns, data, rules)
 			if err != nil {
 				return util.ErrorResponse(w, session.ID, filterErrorMessage(q), http.StatusBadRequest)
 			}
 
+			// If the table has a change log, read the rows that are about to be updated.
+			values := abstractRow(columns, data)
+
+			var before []map[string]interface{}
+
+			if changeLog.Enabled() {
+				where, _ := parsing.UpdateWhereClause(r.URL, values, rules...)
+
+				if before, err = changeLog.Rows(where); err != nil {
+					_ = tx.Rollback()
+
+					return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+				}
+			}
+
 			ui.Log(ui.TableLogger, "sql.query", ui.A{
 				"session": session.ID,
 				"query":   q})
 
-			counts, err := db.Exec(q, data...)
+			counts, err := tx.Exec(q, data...)
 			if err == nil {
 				rowsAffected, _ := counts.RowsAffected()
 				count = count + int(rowsAffected)
@@ -431,10 +486,20 @@ func UpdateAbstractRows(user string, isAdmin bool, tableName string, session *se
 
 				return util.ErrorResponse(w, session.ID, err.Error(), http.StatusConflict)
 			}
+
+			for _, row := range before {
+				if err = changeLog.Record(changes.UpdateOperation, row, changes.Merge(row, values)); err != nil {
+					_ = tx.Rollback()
+
+					return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
+				}
+			}
 		}
 
 		if err == nil {
-			err = tx.Commit()
+			if err = tx.Commit(); err == nil {
+				changeLog.Done()
+			}
 		} else {
 			_ = tx.Rollback()
 		}
@@ -469,3 +534,17 @@ func UpdateAbstractRows(user string, isAdmin bool, tableName string, session *se
 
 	return http.StatusOK
 }
+
+// abstractRow converts a row of values from an abstract row set to a map of the column
+// names to values.
+func abstractRow(columns []string, values []interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(columns))
+
+	for i, name := range columns {
+		if i < len(values) {
+			result[name] = values[i]
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,32 @@
+package scripting
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/server/tables/changes"
+	"github.com/tucats/ego/server/tables/database"
+	"github.com/tucats/ego/server/tables/parsing"
+	"github.com/tucats/ego/util"
+)
+
+// taskChangeLog returns the change log used to record the rows inserted, updated or
+// deleted by a task. The result is nil if the task does not change rows, or if the
+// table does not have a change log. SQL statements supplied with the task are not
+// recorded.
+func taskChangeLog(session *server.Session, db *database.Database, tx *sql.Tx, task txOperation, syms *symbolTable) (*changes.Log, error) {
+	if task.SQL != "" || !util.InList(strings.ToLower(task.Opcode), insertOpcode, updateOpcode, upsertOpcode, deleteOpcode) {
+		return nil, nil
+	}
+
+	// The table name can be a symbol reference, so resolve it before opening the change log.
+	table, err := applySymbolsToString(session.ID, task.Table, syms, "Table name")
+	if err != nil {
+		return nil, err
+	}
+
+	table, _ = parsing.FullName(session.User, table)
+
+	return changes.Open(db.Handle, tx, db.Provider, session.User, table)
+}
@@ -0,0 +1,111 @@
+package scripting
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/errors"
+)
+
+// doIf evaluates the condition of an "if" task, and runs the "then" tasks if it is true
+// or the "else" tasks if it is false.
+func (t *txRunner) doIf(task txOperation, step int) (int, error) {
+	if task.Table != "" || len(task.Filters) > 0 || len(task.Columns) > 0 || len(task.Data) > 0 {
+		return http.StatusBadRequest, errors.Message(fmt.Sprintf("transaction rollback at operation %d; only a condition and tasks are supported for IF task", step))
+	}
+
+	result, err := t.condition(task.Condition)
+	if err != nil {
+		return http.StatusBadRequest, errors.Message(fmt.Sprintf("Invalid condition in task %d, %v", step, err.Error()))
+	}
+
+	ui.Log(ui.TableLogger, "table.tx.if", ui.A{
+		"session":   t.session.ID,
+		"condition": task.Condition,
+		"result":    result})
+
+	if result {
+		return t.run(task.Then)
+	}
+
+	return t.run(task.Else)
+}
+
+// doForeach runs the tasks of a "foreach" task once for each row of the result set read
+// by the most recent "readrows" task. Before the tasks are run, the column values of the
+// row are stored in the symbol table, as they are for a "select" task, and the "_row_"
+// symbol is set to the row number, starting at one.
+func (t *txRunner) doForeach(task txOperation, step int) (int, error) {
+	if task.Table != "" || len(task.Filters) > 0 || len(task.Columns) > 0 || len(task.Data) > 0 {
+		return http.StatusBadRequest, errors.Message(fmt.Sprintf("transaction rollback at operation %d; only tasks are supported for FOREACH task", step))
+	}
+
+	result, found := t.dictionary.symbols[resultSetSymbolName].([]map[string]interface{})
+	if !found {
+		return http.StatusBadRequest, errors.Message(fmt.Sprintf("transaction rollback at operation %d; no result set for FOREACH task", step))
+	}
+
+	// Copy the list of rows, so a "readrows" task in the loop does not change it.
+	rows := append([]map[string]interface{}(nil), result...)
+
+	ui.Log(ui.TableLogger, "table.tx.foreach", ui.A{
+		"session": t.session.ID,
+		"rows":    len(rows)})
+
+	for n, row := range rows {
+		for column, value := range row {
+			t.dictionary.symbols[column] = value
+		}
+
+		t.dictionary.symbols[rowNumberSymbolName] = n + 1
+
+		if status, err := t.run(task.Tasks); err != nil {
+			return status, err
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// doAbort stops the transaction, so all of the work done by the transaction is rolled
+// back. If the task has a condition, the transaction is only stopped if the condition
+// is true. The task can specify the HTTP status and message returned to the client;
+// the default status is 409 (Conflict).
+func (t *txRunner) doAbort(task txOperation, step int) (int, error) {
+	if strings.TrimSpace(task.Condition) != "" {
+		result, err := t.condition(task.Condition)
+		if err != nil {
+			return http.StatusBadRequest, errors.Message(fmt.Sprintf("Invalid condition in task %d, %v", step, err.Error()))
+		}
+
+		if !result {
+			return http.StatusOK, nil
+		}
+	}
+
+	status := task.Status
+	if status == 0 {
+		status = http.StatusConflict
+	}
+
+	if status < 400 || status > 599 {
+		return http.StatusBadRequest, errors.Message(fmt.Sprintf("transaction rollback at operation %d; invalid status for ABORT task: %d", step, task.Status))
+	}
+
+	msg, err := applySymbolsToString(t.session.ID, task.Message, t.dictionary, "Message")
+	if err != nil {
+		return http.StatusBadRequest, errors.Message(fmt.Sprintf("transaction rollback at operation %d; %s", step, err.Error()))
+	}
+
+	if msg == "" {
+		msg = fmt.Sprintf("transaction aborted at operation %d", step)
+	}
+
+	ui.Log(ui.TableLogger, "table.tx.rollback", ui.A{
+		"session": t.session.ID,
+		"count":   step})
+
+	return status, errors.Message(msg)
+}
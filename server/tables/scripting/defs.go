@@ -1,5 +1,7 @@
 package scripting
 
+import "github.com/tucats/ego/server/tables/changes"
+
 type txError struct {
 	Condition string `json:"condition"`
 	Status    int    `json:"status"`
@@ -16,6 +18,49 @@ type txOperation struct {
 	Data       map[string]interface{} `json:"data,omitempty"`
 	Errors     []txError              `json:"errors,omitempty"`
 	SQL        string                 `json:"sql,omitempty"`
+
+	// The condition tested by an "if" or "abort" task, and the tasks run by an "if"
+	// task when the condition is true or false.
+	Condition string        `json:"condition,omitempty"`
+	Then      []txOperation `json:"then,omitempty"`
+	Else      []txOperation `json:"else,omitempty"`
+
+	// The tasks run by a "foreach" task for each row of the result set.
+	Tasks []txOperation `json:"tasks,omitempty"`
+
+	// The HTTP status and message returned when an "abort" task stops the
+	// transaction.
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"msg,omitempty"`
+
+	// The filters from the row-level security policies for the table, which are
+	// added to the task filters. These are never part of the request payload.
+	Policies []string `json:"-"`
+
+	// The change log used to record the rows changed by the task, or nil if the
+	// table does not have a change log.
+	Changes *changes.Log `json:"-"`
+}
+
+// clone returns a copy of the task that can be changed by substituting symbols without
+// changing the original. This allows the same task to be run more than once, such as
+// in a "foreach" task. The nested lists of tasks are cloned when they are run.
+func (t txOperation) clone() txOperation {
+	result := t
+
+	result.Filters = append([]string(nil), t.Filters...)
+	result.Columns = append([]string(nil), t.Columns...)
+	result.Errors = append([]txError(nil), t.Errors...)
+
+	if t.Data != nil {
+		result.Data = make(map[string]interface{}, len(t.Data))
+
+		for key, value := range t.Data {
+			result.Data[key] = value
+		}
+	}
+
+	return result
 }
 
 type symbolTable struct {
@@ -27,10 +72,14 @@ const (
 	insertOpcode  = "insert"
 	deleteOpcode  = "delete"
 	updateOpcode  = "update"
+	upsertOpcode  = "upsert"
 	dropOpCode    = "drop"
 	selectOpcode  = "select"
 	rowsOpcode    = "readrows"
 	sqlOpcode     = "sql"
+	ifOpcode      = "if"
+	foreachOpcode = "foreach"
+	abortOpcode   = "abort"
 
 	selectVerb = "SELECT"
 	deleteVerb = "DELETE"
@@ -40,4 +89,5 @@ const (
 	tableMetadataQuery = `SELECT * FROM {{schema}}.{{table}} WHERE 1=0`
 
 	resultSetSymbolName = "$$RESULT$$SET$$"
+	rowNumberSymbolName = "_row_"
 )
@@ -10,6 +10,7 @@ import (
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/tables/changes"
 	"github.com/tucats/ego/server/tables/parsing"
 )
 
@@ -34,11 +35,22 @@ func doDelete(sessionID int, user string, tx *sql.Tx, task txOperation, id int,
 
 	fakeURL, _ := url.Parse(fmt.Sprintf("http://localhost/tables/%s/rows", task.Table))
 
-	q, err := parsing.FormSelectorDeleteQuery(fakeURL, task.Filters, "", tableName, user, deleteVerb, provider)
+	q, err := parsing.FormSelectorDeleteQuery(fakeURL, append(task.Filters, task.Policies...), "", tableName, user, deleteVerb, provider)
 	if err != nil {
 		return 0, http.StatusBadRequest, errors.Message(filterErrorMessage(q))
 	}
 
+	// If the table has a change log, read the rows that are about to be deleted.
+	var before []map[string]interface{}
+
+	if task.Changes.Enabled() {
+		where, _ := parsing.WhereClause(append(task.Filters, task.Policies...))
+
+		if before, err = task.Changes.Rows(where); err != nil {
+			return 0, http.StatusInternalServerError, err
+		}
+	}
+
 	ui.Log(ui.SQLLogger, "sql.exec", ui.A{
 		"session": sessionID,
 		"query":   q})
@@ -47,6 +59,12 @@ func doDelete(sessionID int, user string, tx *sql.Tx, task txOperation, id int,
 	if err == nil {
 		count, _ := rows.RowsAffected()
 
+		for _, row := range before {
+			if err := task.Changes.Record(changes.DeleteOperation, row, nil); err != nil {
+				return 0, http.StatusInternalServerError, err
+			}
+		}
+
 		if count == 0 && task.EmptyError {
 			return 0, http.StatusNotFound, errors.Message("delete did not modify any rows")
 		}
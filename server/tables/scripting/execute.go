@@ -0,0 +1,300 @@
+package scripting
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/ui"
+	"github.com/tucats/ego/data"
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/expressions"
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/server/tables/changes"
+	"github.com/tucats/ego/server/tables/database"
+	"github.com/tucats/ego/server/tables/parsing"
+	"github.com/tucats/ego/symbols"
+	"github.com/tucats/ego/util"
+)
+
+// txRunner holds the state of a transaction while its tasks are executed.
+type txRunner struct {
+	session    *server.Session
+	db         *database.Database
+	tx         *sql.Tx
+	dsnName    string
+	dictionary *symbolTable
+
+	// The change logs used by the tasks, which are notified when the transaction
+	// is committed.
+	changeLogs []*changes.Log
+
+	// The number of tasks executed so far, used to identify a task in error messages.
+	step int
+
+	// The number of rows read or modified by the most recent task, and by all tasks.
+	rows         int
+	rowsAffected int
+}
+
+// validateTasks checks that each task in the list, and in any lists nested within it,
+// has a valid opcode and the items required by the opcode. The result is an empty
+// string if the tasks are valid, or the error message. A task with no opcode but
+// a SQL statement is assumed to be a "sql" task.
+func validateTasks(tasks []txOperation, prefix string) string {
+	for n := range tasks {
+		task := &tasks[n]
+		label := prefix + strconv.Itoa(n)
+
+		// Is the opcode missing, but there's a SQL string? If so, assume a "sql" operation
+		if task.Opcode == "" && task.SQL != "" {
+			task.Opcode = sqlOpcode
+		}
+
+		// Validate that the opcode is legit...
+		opcode := strings.ToLower(task.Opcode)
+		if !util.InList(opcode,
+			deleteOpcode,
+			updateOpcode,
+			insertOpcode,
+			upsertOpcode,
+			dropOpCode,
+			symbolsOpcode,
+			selectOpcode,
+			rowsOpcode,
+			sqlOpcode,
+			ifOpcode,
+			foreachOpcode,
+			abortOpcode,
+		) {
+			return fmt.Sprintf("transaction operation %s has invalid opcode: %s", label, task.Opcode)
+		}
+
+		switch opcode {
+		case ifOpcode:
+			if strings.TrimSpace(task.Condition) == "" {
+				return fmt.Sprintf("transaction operation %s is missing a condition", label)
+			}
+
+			if msg := validateTasks(task.Then, label+".then."); msg != "" {
+				return msg
+			}
+
+			if msg := validateTasks(task.Else, label+".else."); msg != "" {
+				return msg
+			}
+
+		case foreachOpcode:
+			if len(task.Tasks) == 0 {
+				return fmt.Sprintf("transaction operation %s is missing a list of tasks", label)
+			}
+
+			if msg := validateTasks(task.Tasks, label+".tasks."); msg != "" {
+				return msg
+			}
+		}
+	}
+
+	return ""
+}
+
+// run executes a list of tasks. If a task fails, the result is the HTTP status and
+// an error containing the message to return to the client. The caller is responsible
+// for rolling back the transaction when an error is returned.
+func (t *txRunner) run(tasks []txOperation) (int, error) {
+	for _, task := range tasks {
+		if status, err := t.runTask(task.clone()); err != nil {
+			return status, err
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// runTask executes a single task, including any error conditions for the task.
+func (t *txRunner) runTask(task txOperation) (int, error) {
+	var (
+		operationErr error
+		err          error
+		httpStatus   = http.StatusOK
+		count        int
+	)
+
+	t.step++
+	step := t.step
+	session := t.session
+	tableName, _ := parsing.FullName(session.User, task.Table)
+
+	if ui.IsActive(ui.TableLogger) {
+		if util.InList(strings.ToLower(task.Opcode), symbolsOpcode, sqlOpcode, rowsOpcode, ifOpcode, foreachOpcode, abortOpcode) {
+			ui.WriteLog(ui.TableLogger, "table.op", ui.A{
+				"session": session.ID,
+				"op":      strings.ToUpper(task.Opcode)})
+		} else {
+			ui.WriteLog(ui.TableLogger, "table.op.table", ui.A{
+				"session": session.ID,
+				"op":      strings.ToUpper(task.Opcode),
+				"table":   tableName})
+		}
+	}
+
+	// If this is a SQL opcode that is a select operation, convert it to
+	// a rows opcode
+	if strings.EqualFold(task.Opcode, sqlOpcode) {
+		if strings.HasPrefix(strings.TrimSpace(strings.ToLower(task.SQL)), "select ") {
+			task.Opcode = rowsOpcode
+		}
+	}
+
+	// Operations on tables in the Tables database that read, update or delete rows
+	// are subject to the row-level security policies for the table, and the rows
+	// changed are recorded if the table has a change log.
+	if t.dsnName == "" {
+		if task.Policies, err = taskPolicies(session, t.db.Handle, task, t.dictionary); err == nil {
+			task.Changes, err = taskChangeLog(session, t.db, t.tx, task, t.dictionary)
+		}
+
+		if err != nil {
+			return http.StatusInternalServerError, errors.Message(fmt.Sprintf("transaction rollback at operation %d; %s", step, err.Error()))
+		}
+
+		if task.Changes != nil {
+			t.changeLogs = append(t.changeLogs, task.Changes)
+		}
+	}
+
+	// Based on the opcode, dispatch the appropriate function to do the
+	// specific task. The control operations run their own lists of tasks,
+	// and report any errors from those tasks directly.
+	switch strings.ToLower(task.Opcode) {
+	case ifOpcode:
+		return t.doIf(task, step)
+
+	case foreachOpcode:
+		return t.doForeach(task, step)
+
+	case abortOpcode:
+		return t.doAbort(task, step)
+
+	case sqlOpcode:
+		count, httpStatus, operationErr = doSQL(session.ID, t.tx, task, step, t.dictionary)
+		t.rowsAffected += count
+
+	case symbolsOpcode:
+		httpStatus, operationErr = doSymbols(session.ID, task, step, t.dictionary)
+
+	case selectOpcode:
+		count, httpStatus, operationErr = doSelect(session.ID, session.User, t.db.Handle, t.tx, task, step, t.dictionary, t.db.Provider)
+		t.rowsAffected += count
+
+	case rowsOpcode:
+		count, httpStatus, operationErr = doRows(session.ID, session.User, t.tx, task, step, t.dictionary, t.db.Provider)
+		t.rowsAffected += count
+
+	case updateOpcode:
+		count, httpStatus, operationErr = doUpdate(session.ID, session.User, t.db, t.tx, task, step, t.dictionary)
+		t.rowsAffected += count
+
+	case upsertOpcode:
+		count, httpStatus, operationErr = doUpsert(session.ID, session.User, t.db, t.tx, task, step, t.dictionary)
+		t.rowsAffected += count
+
+	case deleteOpcode:
+		count, httpStatus, operationErr = doDelete(session.ID, session.User, t.tx, task, step, t.dictionary, t.db.Provider)
+		t.rowsAffected += count
+
+	case insertOpcode:
+		httpStatus, operationErr = doInsert(session.ID, session.User, t.db, t.tx, task, step, t.dictionary)
+		t.rowsAffected++
+
+	case dropOpCode:
+		httpStatus, operationErr = doDrop(session.ID, session.User, t.db.Handle, task, step, t.dictionary)
+	}
+
+	t.rows = count
+
+	// See if there are any error triggers we need to look at, assuming what
+	// has already been done was successful.
+	if operationErr == nil && task.Errors != nil {
+		for errorNumber, errorCondition := range task.Errors {
+			// Evaluation the condition. Skip if it it's empty.
+			if strings.TrimSpace(errorCondition.Condition) == "" {
+				continue
+			}
+
+			triggered, err := t.condition(errorCondition.Condition)
+			if err != nil {
+				msg := fmt.Sprintf("Invalid error condition in task %d, %v", step, err.Error())
+
+				return http.StatusBadRequest, errors.Message(msg)
+			}
+
+			if triggered {
+				ui.Log(ui.TableLogger, "table.tx.rollback", ui.A{
+					"session": session.ID,
+					"count":   step})
+
+				msg := fmt.Sprintf("Error condition %d aborts transaction at operation %d", errorNumber+1, step)
+				httpStatus = http.StatusInternalServerError
+
+				if errorCondition.Status > 0 {
+					httpStatus = errorCondition.Status
+				}
+
+				if errorCondition.Message != "" {
+					msg = errorCondition.Message
+				}
+
+				return httpStatus, errors.Message(msg)
+			}
+		}
+	}
+
+	// After we've processed any error triggers that might change the
+	// value, if we still have an error report it so the work is rolled back.
+	if operationErr != nil {
+		msg := fmt.Sprintf("transaction rollback at operation %d; %s", step, operationErr.Error())
+
+		return httpStatus, errors.Message(msg)
+	}
+
+	return http.StatusOK, nil
+}
+
+// condition evaluates a condition expression, written in the same form as a filter, after
+// substituting any symbol references. The symbols in the transaction are available to the
+// expression as variables, along with "_rows_" (the number of rows read or modified by
+// the most recent task) and "_all_rows_" (the number of rows read or modified by all the
+// tasks so far).
+func (t *txRunner) condition(text string) (bool, error) {
+	text, err := applySymbolsToString(t.session.ID, text, t.dictionary, "Condition")
+	if err != nil {
+		return false, err
+	}
+
+	// Convert from filter syntax to Ego syntax.
+	condition := parsing.FormCondition(text)
+	if strings.HasPrefix(condition, parsing.SyntaxErrorPrefix) {
+		return false, errors.Message(strings.TrimPrefix(condition, parsing.SyntaxErrorPrefix))
+	}
+
+	// Build a temporary symbol table for the expression evaluator. Fill it with the symbols
+	// being managed for this transaction.
+	evalSymbols := symbols.NewRootSymbolTable("transaction task condition")
+
+	for k, v := range t.dictionary.symbols {
+		evalSymbols.SetAlways(k, v)
+	}
+
+	evalSymbols.SetAlways("_rows_", t.rows)
+	evalSymbols.SetAlways("_all_rows_", t.rowsAffected)
+
+	result, err := expressions.New().WithText(condition).Eval(evalSymbols)
+	if err != nil {
+		return false, err
+	}
+
+	return data.BoolOrFalse(result), nil
+}
@@ -0,0 +1,198 @@
+package scripting
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/tucats/ego/server/server"
+)
+
+func Test_validateTasks(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []txOperation
+		want  string
+	}{
+		{
+			name:  "implicit sql opcode",
+			tasks: []txOperation{{SQL: "DELETE FROM t"}},
+			want:  "",
+		},
+		{
+			name:  "invalid opcode",
+			tasks: []txOperation{{Opcode: "merge"}},
+			want:  "transaction operation 0 has invalid opcode: merge",
+		},
+		{
+			name:  "if without condition",
+			tasks: []txOperation{{Opcode: "symbols"}, {Opcode: "if"}},
+			want:  "transaction operation 1 is missing a condition",
+		},
+		{
+			name: "nested invalid opcode",
+			tasks: []txOperation{{Opcode: "if", Condition: "EQ(_rows_,0)", Else: []txOperation{
+				{Opcode: "symbols"},
+				{Opcode: "foreach", Tasks: []txOperation{{Opcode: "bogus"}}},
+			}}},
+			want: "transaction operation 0.else.1.tasks.0 has invalid opcode: bogus",
+		},
+		{
+			name:  "foreach without tasks",
+			tasks: []txOperation{{Opcode: "FOREACH"}},
+			want:  "transaction operation 0 is missing a list of tasks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateTasks(tt.tasks, ""); got != tt.want {
+				t.Errorf("validateTasks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	tasks := []txOperation{{SQL: "DELETE FROM t"}}
+	if validateTasks(tasks, ""); tasks[0].Opcode != sqlOpcode {
+		t.Errorf("validateTasks() opcode = %q, want %q", tasks[0].Opcode, sqlOpcode)
+	}
+}
+
+func Test_txRunner_control(t *testing.T) {
+	// A task that copies the symbol "value" to the symbol named by the key.
+	copyTo := func(name string) txOperation {
+		return txOperation{Opcode: symbolsOpcode, Data: map[string]interface{}{name: "{{value}}"}}
+	}
+
+	tests := []struct {
+		name    string
+		tasks   []txOperation
+		symbols map[string]interface{}
+		status  int
+		msg     string
+	}{
+		{
+			name: "if true",
+			tasks: []txOperation{
+				{Opcode: symbolsOpcode, Data: map[string]interface{}{"value": 5}},
+				{Opcode: ifOpcode, Condition: "GT(value,3)", Then: []txOperation{copyTo("then")}, Else: []txOperation{copyTo("else")}},
+			},
+			symbols: map[string]interface{}{"value": 5, "then": 5},
+			status:  http.StatusOK,
+		},
+		{
+			name: "if false",
+			tasks: []txOperation{
+				{Opcode: symbolsOpcode, Data: map[string]interface{}{"value": 2}},
+				{Opcode: ifOpcode, Condition: "GT(value,3)", Then: []txOperation{copyTo("then")}, Else: []txOperation{copyTo("else")}},
+			},
+			symbols: map[string]interface{}{"value": 2, "else": 2},
+			status:  http.StatusOK,
+		},
+		{
+			name: "abort with defaults",
+			tasks: []txOperation{
+				{Opcode: symbolsOpcode, Data: map[string]interface{}{"value": 2}},
+				{Opcode: abortOpcode},
+				copyTo("after"),
+			},
+			status: http.StatusConflict,
+			msg:    "transaction aborted at operation 2",
+		},
+		{
+			name: "abort with status and message",
+			tasks: []txOperation{
+				{Opcode: symbolsOpcode, Data: map[string]interface{}{"value": 2}},
+				{Opcode: abortOpcode, Condition: "EQ(value,2)", Status: http.StatusPaymentRequired, Message: "value is {{value}}"},
+			},
+			status: http.StatusPaymentRequired,
+			msg:    "value is 2",
+		},
+		{
+			name: "abort condition false",
+			tasks: []txOperation{
+				{Opcode: symbolsOpcode, Data: map[string]interface{}{"value": 2}},
+				{Opcode: abortOpcode, Condition: "EQ(value,3)"},
+			},
+			symbols: map[string]interface{}{"value": 2},
+			status:  http.StatusOK,
+		},
+		{
+			name: "abort invalid status",
+			tasks: []txOperation{
+				{Opcode: abortOpcode, Status: http.StatusOK},
+			},
+			status: http.StatusBadRequest,
+			msg:    "transaction rollback at operation 1; invalid status for ABORT task: 200",
+		},
+		{
+			name: "foreach without result set",
+			tasks: []txOperation{
+				{Opcode: foreachOpcode, Tasks: []txOperation{copyTo("row")}},
+			},
+			status: http.StatusBadRequest,
+			msg:    "transaction rollback at operation 1; no result set for FOREACH task",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &txRunner{
+				session:    &server.Session{ID: 100, User: "tom"},
+				dsnName:    "test",
+				dictionary: &symbolTable{symbols: map[string]interface{}{}},
+			}
+
+			status, err := runner.run(tt.tasks)
+			if status != tt.status {
+				t.Errorf("run() status = %d, want %d", status, tt.status)
+			}
+
+			if tt.msg == "" {
+				if err != nil {
+					t.Fatalf("run() unexpected error %v", err)
+				}
+
+				for key, want := range tt.symbols {
+					if got := runner.dictionary.symbols[key]; got != want {
+						t.Errorf("run() symbol %s = %v, want %v", key, got, want)
+					}
+				}
+
+				if len(runner.dictionary.symbols) != len(tt.symbols) {
+					t.Errorf("run() symbols = %v, want %v", runner.dictionary.symbols, tt.symbols)
+				}
+			} else if err == nil || err.Error() != tt.msg {
+				t.Errorf("run() error = %v, want %s", err, tt.msg)
+			}
+		})
+	}
+}
+
+func Test_txRunner_foreach(t *testing.T) {
+	runner := &txRunner{
+		session: &server.Session{ID: 100, User: "tom"},
+		dsnName: "test",
+		dictionary: &symbolTable{symbols: map[string]interface{}{
+			resultSetSymbolName: []map[string]interface{}{
+				{"name": "apple", "qty": 3},
+				{"name": "pear", "qty": 5},
+			},
+		}},
+	}
+
+	// Each time the task runs, the symbols from the row are substituted in the original
+	// task, not the task changed by the previous row.
+	tasks := []txOperation{{Opcode: foreachOpcode, Tasks: []txOperation{
+		{Opcode: symbolsOpcode, Data: map[string]interface{}{"last": "{{name}}", "number": "{{_row_}}"}},
+		{Opcode: abortOpcode, Condition: "GT(qty,4)", Message: "{{name}} is row {{_row_}}"},
+	}}}
+
+	status, err := runner.run(tasks)
+	if status != http.StatusConflict || err == nil || err.Error() != "pear is row 2" {
+		t.Errorf("run() = %d, %v", status, err)
+	}
+
+	if runner.dictionary.symbols["last"] != "pear" || runner.dictionary.symbols["number"] != 2 {
+		t.Errorf("run() symbols = %v", runner.dictionary.symbols)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/tables/changes"
 	"github.com/tucats/ego/server/tables/database"
 	"github.com/tucats/ego/server/tables/parsing"
 )
@@ -42,6 +43,9 @@ func doInsert(sessionID int, user string, db *database.Database, tx *sql.Tx, tas
 	// for _row_id_ or creates it if not found. Row IDs are always assigned on insert only.
 	task.Data[defs.RowIDName] = uuid.New().String()
 
+	// Save the values before any date/time values are quoted, to record in the change log.
+	after := changes.Merge(task.Data, nil)
+
 	for _, column := range columns {
 		v, ok := task.Data[column.Name]
 		if !ok && settings.GetBool(defs.TableServerPartialInsertError) {
@@ -86,5 +90,9 @@ func doInsert(sessionID int, user string, db *database.Database, tx *sql.Tx, tas
 		return status, errors.Message("error inserting row; " + e.Error())
 	}
 
+	if err := task.Changes.Record(changes.InsertOperation, nil, after); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
 	return http.StatusOK, nil
 }
@@ -0,0 +1,42 @@
+package scripting
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/server/tables/policies"
+)
+
+// taskPolicies returns the row-level security policy filters that apply to a task. Tasks
+// that read, update or delete rows using generated SQL are subject to the policies for
+// the table; SQL statements supplied with the task are not changed.
+func taskPolicies(session *server.Session, db *sql.DB, task txOperation, syms *symbolTable) ([]string, error) {
+	var operation string
+
+	switch strings.ToLower(task.Opcode) {
+	case selectOpcode, rowsOpcode:
+		operation = policies.ReadOperation
+
+	case updateOpcode, upsertOpcode:
+		operation = policies.UpdateOperation
+
+	case deleteOpcode:
+		operation = policies.DeleteOperation
+
+	default:
+		return nil, nil
+	}
+
+	if task.SQL != "" {
+		return nil, nil
+	}
+
+	// The table name can be a symbol reference, so resolve it before looking up the policies.
+	table, err := applySymbolsToString(session.ID, task.Table, syms, "Table name")
+	if err != nil {
+		return nil, err
+	}
+
+	return policies.ForSession(session, db, table, operation)
+}
@@ -27,7 +27,7 @@ func doRows(sessionID int, user string, tx *sql.Tx, task txOperation, id int, sy
 	fakeURL, _ := url.Parse("http://localhost/tables/" + task.Table + "/rows?limit=1")
 
 	if q == "" {
-		q, err = parsing.FormSelectorDeleteQuery(fakeURL, task.Filters, strings.Join(task.Columns, ","), tableName, user, selectVerb, provider)
+		q, err = parsing.FormSelectorDeleteQuery(fakeURL, append(task.Filters, task.Policies...), strings.Join(task.Columns, ","), tableName, user, selectVerb, provider)
 		if err != nil {
 			return count, http.StatusBadRequest, errors.Message(filterErrorMessage(q))
 		}
@@ -26,7 +26,7 @@ func doSelect(sessionID int, user string, db *sql.DB, tx *sql.Tx, task txOperati
 	tableName, _ := parsing.FullName(user, task.Table)
 	fakeURL, _ := url.Parse("http://localhost/tables/" + task.Table + "/rows?limit=1")
 
-	q, err := parsing.FormSelectorDeleteQuery(fakeURL, task.Filters, strings.Join(task.Columns, ","), tableName, user, selectVerb, provider)
+	q, err := parsing.FormSelectorDeleteQuery(fakeURL, append(task.Filters, task.Policies...), strings.Join(task.Columns, ","), tableName, user, selectVerb, provider)
 	if err != nil {
 		return count, http.StatusBadRequest, errors.Message(filterErrorMessage(q))
 	}
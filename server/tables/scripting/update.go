@@ -11,6 +11,7 @@ import (
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/tables/changes"
 	"github.com/tucats/ego/server/tables/database"
 	"github.com/tucats/ego/server/tables/parsing"
 )
@@ -137,17 +138,27 @@ func doUpdate(sessionID int, user string, db *database.Database, tx *sql.Tx, tas
 	}
 
 	// If there is a filter, then add that as well. And fail if there
-	// isn't a filter but must be
-	if filter, err := parsing.WhereClause(task.Filters); filter != "" {
+	// isn't a filter but must be. The policy filters don't count as a
+	// filter for this test, but are always added to the query.
+	if where, err := parsing.WhereClause(task.Filters); where == "" && err == nil && settings.GetBool(defs.TablesServerEmptyFilterError) {
+		return 0, http.StatusBadRequest, errors.Message("update without filter is not allowed")
+	}
+
+	filter, err := parsing.WhereClause(append(task.Filters, task.Policies...))
+	if filter != "" {
 		if p := strings.Index(filter, parsing.SyntaxErrorPrefix); p >= 0 {
 			return 0, http.StatusBadRequest, errors.Message(filterErrorMessage(filter))
 		}
 
-		result.WriteString(filter)
+		result.WriteString(" " + filter)
 	} else if err != nil {
 		return 0, http.StatusBadRequest, errors.New(err)
-	} else if settings.GetBool(defs.TablesServerEmptyFilterError) {
-		return 0, http.StatusBadRequest, errors.Message("update without filter is not allowed")
+	}
+
+	// If the table has a change log, read the rows that are about to be updated.
+	before, err := task.Changes.Rows(filter)
+	if err != nil {
+		return 0, http.StatusInternalServerError, err
 	}
 
 	ui.Log(ui.SQLLogger, "sql.exec", ui.A{
@@ -157,6 +168,13 @@ func doUpdate(sessionID int, user string, db *database.Database, tx *sql.Tx, tas
 	queryResult, updateErr := tx.Exec(result.String(), values...)
 	if updateErr == nil {
 		count, _ = queryResult.RowsAffected()
+
+		for _, row := range before {
+			if err := task.Changes.Record(changes.UpdateOperation, row, changes.Merge(row, task.Data)); err != nil {
+				return 0, http.StatusInternalServerError, err
+			}
+		}
+
 		if count == 0 && task.EmptyError {
 			status = http.StatusNotFound
 			updateErr = errors.Message("update did not modify any rows")
@@ -0,0 +1,52 @@
+package scripting
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/tables/database"
+)
+
+// doUpsert updates the rows that match the filters of the task with the values in the
+// task data. If no rows match, a new row is inserted with the task data instead. The
+// filters are required, and usually test the key columns of the table. The columns list,
+// if given, limits which values are updated in an existing row; all the values are used
+// when a new row is inserted. If row-level security policies apply to the task, a row
+// hidden by a policy cannot be told apart from a missing row, so the task fails instead
+// of inserting a row.
+func doUpsert(sessionID int, user string, db *database.Database, tx *sql.Tx, task txOperation, id int, syms *symbolTable) (int, int, error) {
+	if len(task.Filters) == 0 {
+		return 0, http.StatusBadRequest, errors.Message("filters required for UPSERT task")
+	}
+
+	if len(task.Data) == 0 {
+		return 0, http.StatusBadRequest, errors.Message("data required for UPSERT task")
+	}
+
+	// Try the update first. A task can be run more than once, so work on a copy.
+	update := task.clone()
+	update.EmptyError = false
+
+	count, status, err := doUpdate(sessionID, user, db, tx, update, id, syms)
+	if err != nil || count > 0 {
+		return count, status, err
+	}
+
+	// Nothing was updated. A policy may have hidden the matching row, and inserting a
+	// new row would replace it, so the row is only inserted when no policies apply.
+	if len(task.Policies) > 0 {
+		return 0, http.StatusForbidden, errors.Message("upsert did not match any rows allowed by the row-level security policies")
+	}
+
+	// Insert the row instead.
+	insert := task.clone()
+	insert.Filters = nil
+	insert.Columns = nil
+
+	if status, err = doInsert(sessionID, user, db, tx, insert, id, syms); err != nil {
+		return 0, status, err
+	}
+
+	return 1, http.StatusOK, nil
+}
@@ -0,0 +1,88 @@
+package scripting
+
+import (
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/tucats/ego/server/tables/database"
+)
+
+func TestDoUpsertPolicies(t *testing.T) {
+	// The table metadata is read using a second connection while the transaction is
+	// open, so the database is a file. The user is "main", so the schema of the table
+	// is the main database.
+	handle, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "tables.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer handle.Close()
+
+	for _, q := range []string{
+		`CREATE TABLE orders(_row_id_ CHAR VARYING, owner CHAR VARYING, item CHAR VARYING, qty INTEGER)`,
+		`INSERT INTO orders VALUES('1', 'mary', 'apple', 3)`,
+	} {
+		if _, err := handle.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db := &database.Database{Handle: handle, Provider: "sqlite3"}
+	syms := &symbolTable{symbols: map[string]interface{}{}}
+
+	upsert := func(item string, policies []string) (int, int, error) {
+		tx, err := handle.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer func() { _ = tx.Commit() }()
+
+		task := txOperation{
+			Opcode:   upsertOpcode,
+			Table:    "orders",
+			Filters:  []string{"EQ(item,\"" + item + "\")"},
+			Data:     map[string]interface{}{"owner": "main", "item": item, "qty": 9},
+			Policies: policies,
+		}
+
+		return doUpsert(0, "main", db, tx, task, 0, syms)
+	}
+
+	// The row owned by another user is hidden by the policy, so it is neither updated
+	// nor replaced by a new row.
+	if _, status, err := upsert("apple", []string{"EQ(owner,\"main\")"}); err == nil || status != http.StatusForbidden {
+		t.Errorf("doUpsert() of hidden row = %d, %v", status, err)
+	}
+
+	// Without a policy, a row is inserted when none matches.
+	if count, _, err := upsert("pear", nil); err != nil || count != 1 {
+		t.Errorf("doUpsert() of new row = %d, %v", count, err)
+	}
+
+	rows, err := handle.Query(`SELECT item, qty FROM orders ORDER BY item`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer rows.Close()
+
+	result := map[string]int{}
+
+	for rows.Next() {
+		var (
+			item string
+			qty  int
+		)
+
+		_ = rows.Scan(&item, &qty)
+		result[item] = qty
+	}
+
+	if len(result) != 2 || result["apple"] != 3 || result["pear"] != 9 {
+		t.Errorf("rows after doUpsert() = %v", result)
+	}
+}
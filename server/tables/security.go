@@ -12,6 +12,7 @@ import (
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
 	"github.com/tucats/ego/errors"
+	"github.com/tucats/ego/server/audit"
 	"github.com/tucats/ego/server/server"
 	"github.com/tucats/ego/server/tables/database"
 	"github.com/tucats/ego/server/tables/parsing"
@@ -273,6 +274,13 @@ func GrantPermissions(session *server.Session, w http.ResponseWriter, r *http.Re
 		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
 	}
 
+	audit.Record("table.grant", ui.A{
+		"session":     session.ID,
+		"user":        session.User,
+		"name":        user,
+		"table":       table,
+		"permissions": buff.String()})
+
 	return ReadPermissions(session, w, r)
 }
 
@@ -288,15 +296,24 @@ func DeletePermissions(session *server.Session, w http.ResponseWriter, r *http.R
 
 	tableName := data.String(session.URLParts["table"])
 
+	// The permissions deleted are those of the user making the request.
+	user := session.User
+
 	table, fullyQualified := parsing.FullName(session.User, tableName)
 	if !session.Admin && !fullyQualified {
 		return util.ErrorResponse(w, session.ID, "Not authorized to delete permissions", http.StatusForbidden)
 	}
 
-	if _, err = db.Exec(permissionsDeleteQuery, session.User, table); err != nil {
+	if _, err = db.Exec(permissionsDeleteQuery, user, table); err != nil {
 		return util.ErrorResponse(w, session.ID, err.Error(), http.StatusInternalServerError)
 	}
 
+	audit.Record("table.revoke", ui.A{
+		"session": session.ID,
+		"user":    session.User,
+		"name":    user,
+		"table":   table})
+
 	w.WriteHeader(http.StatusOK)
 
 	return http.StatusOK
@@ -11,6 +11,7 @@ import (
 	"github.com/tucats/ego/app-cli/ui"
 	"github.com/tucats/ego/data"
 	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/server/audit"
 	"github.com/tucats/ego/server/dsns"
 	"github.com/tucats/ego/server/server"
 	"github.com/tucats/ego/server/tables/database"
@@ -73,6 +74,12 @@ func SQLTransaction(session *server.Session, w http.ResponseWriter, r *http.Requ
 		return util.ErrorResponse(w, sessionID, err.Error(), http.StatusInternalServerError)
 	}
 
+	audit.Record("sql.execute", ui.A{
+		"session":    sessionID,
+		"user":       session.User,
+		"dsn":        data.String(session.URLParts["dsn"]),
+		"statements": statements})
+
 	// Now execute each statement from the array of strings.
 	err, httpStatus = executeStatements(statements, sessionID, tx, session, w, rows, err)
 	if httpStatus > http.StatusOK {
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -13,8 +14,10 @@ import (
 	"github.com/tucats/ego/errors"
 	"github.com/tucats/ego/server/dsns"
 	"github.com/tucats/ego/server/server"
+	"github.com/tucats/ego/server/tables/changes"
 	"github.com/tucats/ego/server/tables/database"
 	"github.com/tucats/ego/server/tables/parsing"
+	"github.com/tucats/ego/server/tables/policies"
 	"github.com/tucats/ego/util"
 )
 
@@ -45,15 +48,14 @@ func TableCreate(session *server.Session, w http.ResponseWriter, r *http.Request
 			return util.ErrorResponse(w, sessionID, "User does not have update permission", http.StatusForbidden)
 		}
 
-		// Create an array of column definitions which will receive the JSON payload from the
-		// request.
-		columns, httpStatus := getColumnPayload(r, w, sessionID)
+		// Create the table definition which will receive the JSON payload from the request.
+		definition, httpStatus := getColumnPayload(r, w, sessionID)
 		if httpStatus > 200 {
 			return httpStatus
 		}
 
 		// Geenerate the SQL string that will create the table.
-		q := parsing.FormCreateQuery(r.URL, user, session.Admin, columns, sessionID, w, db.Provider)
+		q := parsing.FormCreateQuery(r.URL, user, session.Admin, definition, sessionID, w, db.Provider)
 		if q == "" {
 			return http.StatusOK
 		}
@@ -66,12 +68,9 @@ func TableCreate(session *server.Session, w http.ResponseWriter, r *http.Request
 			}
 		}
 
-		// Execute the SQL that creates the table. Also writte to the log when SQLLogger is active.
-		ui.Log(ui.SQLLogger, "sql.exec", ui.A{
-			"session": sessionID,
-			"query":   q})
-
-		counts, err := db.Exec(q)
+		// Execute the SQL that creates the table and any secondary indexes. Also write to the
+		// log when SQLLogger is active.
+		counts, err := createTableAndIndexes(db, sessionID, q, parsing.FormIndexQueries(tableName, db.Provider, definition.Indexes))
 		if err == nil {
 			// If the table create was successful, construct a response object to send back to the
 			// client. For a table create, the response is a DBRowCount object.
@@ -125,31 +124,143 @@ func TableCreate(session *server.Session, w http.ResponseWriter, r *http.Request
 	return util.ErrorResponse(w, sessionID, err.Error(), http.StatusBadRequest)
 }
 
-func getColumnPayload(r *http.Request, w http.ResponseWriter, sessionID int) ([]defs.DBColumn, int) {
-	columns := []defs.DBColumn{}
+// getColumnPayload reads the table definition from the request body. The payload can be
+// either an array of DBColumn objects, or a DBTableDefinition object that also describes the
+// primary key, secondary indexes, and foreign keys for the table.
+func getColumnPayload(r *http.Request, w http.ResponseWriter, sessionID int) (defs.DBTableDefinition, int) {
+	definition := defs.DBTableDefinition{}
 
-	// Read the body of the request and decode the JSON as an array of DBColumn objects.
-	// If the payload has an ill-formed JSON string, return the error.
-	if err := json.NewDecoder(r.Body).Decode(&columns); err != nil {
-		return nil, util.ErrorResponse(w, sessionID, "Invalid table create payload: "+err.Error(), http.StatusBadRequest)
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return definition, util.ErrorResponse(w, sessionID, "Invalid table create payload: "+err.Error(), http.StatusBadRequest)
+	}
+
+	// Decode the JSON as an array of DBColumn objects if it looks like an array, else as
+	// a table definition object. If the payload has an ill-formed JSON string, return the error.
+	if strings.HasPrefix(strings.TrimSpace(string(b)), "[") {
+		err = json.Unmarshal(b, &definition.Columns)
+	} else {
+		err = json.Unmarshal(b, &definition)
+	}
+
+	if err != nil {
+		return definition, util.ErrorResponse(w, sessionID, "Invalid table create payload: "+err.Error(), http.StatusBadRequest)
 	}
 
 	// Validate the column definitions, which must have a name and valid type.
-	for _, column := range columns {
+	names := map[string]bool{}
+
+	for _, column := range definition.Columns {
 		if column.Name == "" {
-			return nil, util.ErrorResponse(w, sessionID, "Missing or empty column name", http.StatusBadRequest)
+			return definition, util.ErrorResponse(w, sessionID, "Missing or empty column name", http.StatusBadRequest)
 		}
 
 		if column.Type == "" {
-			return nil, util.ErrorResponse(w, sessionID, "Missing or empty type name", http.StatusBadRequest)
+			return definition, util.ErrorResponse(w, sessionID, "Missing or empty type name", http.StatusBadRequest)
 		}
 
 		if !parsing.KeywordMatch(column.Type, defs.TableColumnTypeNames...) {
-			return nil, util.ErrorResponse(w, sessionID, "Invalid type name: "+column.Type, http.StatusBadRequest)
+			return definition, util.ErrorResponse(w, sessionID, "Invalid type name: "+column.Type, http.StatusBadRequest)
+		}
+
+		names[column.Name] = true
+	}
+
+	// The row ID column is always part of the table, even if not explicitly listed.
+	names[defs.RowIDName] = true
+
+	if msg := validateConstraints(definition, names); msg != "" {
+		return definition, util.ErrorResponse(w, sessionID, msg, http.StatusBadRequest)
+	}
+
+	return definition, 0
+}
+
+// validateConstraints verifies that the primary key, indexes, and foreign keys in the table
+// definition only refer to columns in the table. If there is an error, the text of the error
+// message is returned, else an empty string.
+func validateConstraints(definition defs.DBTableDefinition, names map[string]bool) string {
+	for _, name := range definition.PrimaryKey {
+		if !names[name] {
+			return "Invalid primary key column: " + name
+		}
+	}
+
+	for _, index := range definition.Indexes {
+		if len(index.Columns) == 0 {
+			return "Missing index columns: " + index.Name
+		}
+
+		if strings.ContainsAny(index.Name, "\"'; ") {
+			return "Invalid index name: " + index.Name
+		}
+
+		for _, name := range index.Columns {
+			if !names[name] {
+				return "Invalid index column: " + name
+			}
+		}
+	}
+
+	for _, key := range definition.ForeignKeys {
+		if key.Table == "" {
+			return "Missing foreign key table name"
+		}
+
+		if len(key.Columns) == 0 || len(key.Columns) != len(key.References) {
+			return "Foreign key columns and references do not match: " + key.Table
+		}
+
+		if strings.ContainsAny(key.Name+key.Table+strings.Join(key.References, ""), "\"'; ") {
+			return "Invalid foreign key name: " + key.Table
+		}
+
+		for _, name := range key.Columns {
+			if !names[name] {
+				return "Invalid foreign key column: " + name
+			}
+		}
+
+		if key.OnDelete != "" && !parsing.KeywordMatch(key.OnDelete, parsing.ForeignKeyActions...) {
+			return "Invalid foreign key delete action: " + key.OnDelete
+		}
+	}
+
+	return ""
+}
+
+// createTableAndIndexes executes the table create statement and the statements to create any
+// secondary indexes as a single transaction. The result is the result of the table create.
+func createTableAndIndexes(db *database.Database, sessionID int, q string, indexes []string) (sql.Result, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	ui.Log(ui.SQLLogger, "sql.exec", ui.A{
+		"session": sessionID,
+		"query":   q})
+
+	result, err := tx.Exec(q)
+	if err != nil {
+		_ = tx.Rollback()
+
+		return nil, err
+	}
+
+	for _, index := range indexes {
+		ui.Log(ui.SQLLogger, "sql.exec", ui.A{
+			"session": sessionID,
+			"query":   index})
+
+		if _, err = tx.Exec(index); err != nil {
+			_ = tx.Rollback()
+
+			return nil, err
 		}
 	}
 
-	return columns, 0
+	return result, tx.Commit()
 }
 
 // Verify that the schema exists for this user, and create it if not found. This is required for
@@ -296,6 +407,9 @@ func DeleteTable(session *server.Session, w http.ResponseWriter, r *http.Request
 		if err == nil {
 			if dsnName == "" {
 				RemoveTablePermissions(sessionID, db.Handle, tableName)
+
+				_, _ = policies.Delete(db.Handle, tableName, "")
+				_ = changes.Disable(db.Handle, db.Provider, tableName)
 			}
 
 			return util.ErrorResponse(w, sessionID, "Table "+tableName+" successfully deleted", http.StatusOK)
@@ -0,0 +1,118 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/tucats/ego/errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The parameters used for argon2id password hashes. These are the values recommended
+// by RFC 9106 for systems with limited memory. The parameters are stored in the hash,
+// so they can be changed without invalidating existing hashes.
+const (
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+	argon2KeySize = 32
+	argon2Salt    = 16
+	argon2Prefix  = "$argon2id$"
+)
+
+// The largest argon2id parameters accepted in a stored hash. The cost of checking a
+// password is set by the hash, so a hash with very large values could be used to
+// exhaust the memory or processor time of the server.
+const (
+	maxArgon2Time    = 16
+	maxArgon2Memory  = 1024 * 1024
+	maxArgon2Threads = 64
+	maxArgon2Bytes   = 64
+)
+
+// HashPassword returns the argon2id hash of a password in the standard encoded form,
+// "$argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>". A random salt is
+// used, so hashing the same password twice gives different results.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argon2Salt)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.New(err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeySize)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2Prefix, argon2.Version,
+		argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// CheckPassword returns true if a password matches an argon2id or bcrypt hash. The
+// algorithm and its parameters are found from the hash. A hash that is not valid
+// never matches.
+func CheckPassword(password, hash string) bool {
+	if IsArgon2Hash(hash) {
+		return checkArgon2([]byte(password), hash)
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// IsArgon2Hash returns true if a string is an argon2id password hash.
+func IsArgon2Hash(hash string) bool {
+	return strings.HasPrefix(hash, argon2Prefix)
+}
+
+// PasswordHashCurrent returns true if a password hash is an argon2id hash that uses
+// the current parameters, so it does not need to be hashed again.
+func PasswordHashCurrent(hash string) bool {
+	return strings.HasPrefix(hash, fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$", argon2Prefix,
+		argon2.Version, argon2Memory, argon2Time, argon2Threads))
+}
+
+// checkArgon2 returns true if the password matches an argon2id hash in the standard
+// encoded form, using the parameters stored in the hash. A hash with parameters
+// outside the accepted limits never matches.
+func checkArgon2(password []byte, hash string) bool {
+	var (
+		version               int
+		memory, time, threads uint32
+	)
+
+	fields := strings.Split(hash, "$")
+	if len(fields) != 6 {
+		return false
+	}
+
+	if _, err := fmt.Sscanf(fields[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false
+	}
+
+	if _, err := fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false
+	}
+
+	if time == 0 || time > maxArgon2Time ||
+		memory == 0 || memory > maxArgon2Memory ||
+		threads == 0 || threads > maxArgon2Threads {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(fields[4])
+	if err != nil || len(salt) > maxArgon2Bytes {
+		return false
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(fields[5])
+	if err != nil || len(key) == 0 || len(key) > maxArgon2Bytes {
+		return false
+	}
+
+	actual := argon2.IDKey(password, salt, time, memory, uint8(threads), uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, actual) == 1
+}
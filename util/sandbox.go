@@ -0,0 +1,49 @@
+package util
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/defs"
+	"github.com/tucats/ego/errors"
+)
+
+// SandboxPath returns the path of a file used by an Ego program, relative to the
+// sandbox path if there is one. A path that is already in the sandbox is returned
+// as-is, and any other path is made relative to the sandbox. The path is cleaned,
+// and an error is returned if it names a file outside the sandbox, such as a path
+// that uses ".." to leave it.
+func SandboxPath(path string) (string, error) {
+	sandboxPrefix := settings.Get(defs.SandboxPathSetting)
+	if sandboxPrefix == "" {
+		return path, nil
+	}
+
+	sandboxPrefix = filepath.Clean(sandboxPrefix)
+
+	if !inSandbox(sandboxPrefix, filepath.Clean(path)) {
+		path = filepath.Join(sandboxPrefix, path)
+	}
+
+	path = filepath.Clean(path)
+	if !inSandbox(sandboxPrefix, path) {
+		return "", errors.ErrInvalidSandboxPath.Context(path)
+	}
+
+	return path, nil
+}
+
+// inSandbox returns true if the cleaned path is the sandbox directory, or a file
+// within it.
+func inSandbox(sandboxPrefix, path string) bool {
+	if path == sandboxPrefix {
+		return true
+	}
+
+	if !strings.HasSuffix(sandboxPrefix, string(filepath.Separator)) {
+		sandboxPrefix += string(filepath.Separator)
+	}
+
+	return strings.HasPrefix(path, sandboxPrefix)
+}
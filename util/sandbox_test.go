@@ -0,0 +1,102 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/tucats/ego/app-cli/settings"
+	"github.com/tucats/ego/defs"
+)
+
+func TestSandboxPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		sandbox string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "/barn/foo",
+			sandbox: "/bar",
+			want:    "/bar/barn/foo",
+		},
+		{
+			name:    "tmp/../foo",
+			sandbox: "/bar/",
+			want:    "/bar/foo",
+		},
+		{
+			name:    "../etc/passwd",
+			sandbox: "/bar",
+			wantErr: true,
+		},
+		{
+			name:    "tmp/../../etc/passwd",
+			sandbox: "/bar",
+			wantErr: true,
+		},
+		{
+			name:    "/bar/../etc/passwd",
+			sandbox: "/bar",
+			want:    "/bar/etc/passwd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings.Set(defs.SandboxPathSetting, tt.sandbox)
+
+			got, err := SandboxPath(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SandboxPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("SandboxPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	settings.Set(defs.SandboxPathSetting, "")
+}
+
+// The cases of the sandbox test for the io package, which had its own copy of the
+// sandbox function before it used SandboxPath.
+func Test_sandboxName(t *testing.T) {
+	tests := []struct {
+		name    string
+		sandbox string
+		want    string
+	}{
+		{
+			name:    "/tmp/foo",
+			sandbox: "",
+			want:    "/tmp/foo",
+		},
+		{
+			name:    "/tmp/foo",
+			sandbox: "/tmp",
+			want:    "/tmp/foo",
+		},
+		{
+			name:    "/tmp/foo",
+			sandbox: "/bar",
+			want:    "/bar/tmp/foo",
+		},
+		{
+			name:    "tmp/foo",
+			sandbox: "/bar",
+			want:    "/bar/tmp/foo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings.Set(defs.SandboxPathSetting, tt.sandbox)
+
+			if got, err := SandboxPath(tt.name); err != nil || got != tt.want {
+				t.Errorf("SandboxPath() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	settings.Set(defs.SandboxPathSetting, "")
+}